@@ -283,7 +283,7 @@ export function useArticleDetail() {
         if (cacheEnabled && content) {
           // Use feed URL as referer for anti-hotlinking (more reliable than article URL)
           const feedUrl = data.feed_url || article.value.url;
-          content = proxyImagesInHtml(content, feedUrl);
+          content = proxyImagesInHtml(content, feedUrl, article.value.feed_id);
         }
 
         articleContent.value = content;
@@ -6,6 +6,7 @@ interface TranslationSettings {
   enabled: boolean;
   targetLang: string;
   translationOnlyMode: boolean;
+  fullArticle: boolean;
 }
 
 export function useArticleTranslation() {
@@ -14,6 +15,7 @@ export function useArticleTranslation() {
     enabled: false,
     targetLang: 'en',
     translationOnlyMode: false,
+    fullArticle: false,
   });
   const translatingArticles: Ref<Set<number>> = ref(new Set());
   let observer: IntersectionObserver | null = null;
@@ -27,6 +29,7 @@ export function useArticleTranslation() {
         enabled: data.translation_enabled === 'true',
         targetLang: data.target_language || 'en',
         translationOnlyMode: data.translation_only_mode === 'true',
+        fullArticle: data.translation_full_article === 'true',
       };
     } catch (e) {
       console.error('Error loading translation settings:', e);
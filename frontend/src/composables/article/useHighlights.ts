@@ -0,0 +1,123 @@
+import { ref, type Ref } from 'vue';
+import type { Highlight } from '@/types/models';
+
+// Characters of surrounding text stored with a highlight to re-anchor its quote
+const ANCHOR_CONTEXT_LENGTH = 32;
+
+export interface HighlightAnchor {
+  quote: string;
+  prefix: string;
+  suffix: string;
+  start_offset: number;
+  end_offset: number;
+}
+
+/**
+ * Build a highlight anchor from the current selection inside a container.
+ * Offsets are character positions in the container's plain text.
+ */
+export function anchorFromSelection(container: HTMLElement): HighlightAnchor | null {
+  const selection = window.getSelection();
+  if (!selection || selection.rangeCount === 0 || selection.isCollapsed) return null;
+
+  const range = selection.getRangeAt(0);
+  if (!container.contains(range.commonAncestorContainer)) return null;
+
+  const quote = range.toString().trim();
+  if (!quote) return null;
+
+  const before = document.createRange();
+  before.selectNodeContents(container);
+  before.setEnd(range.startContainer, range.startOffset);
+  const start = before.toString().length;
+  const end = start + range.toString().length;
+
+  const text = container.textContent || '';
+  return {
+    quote,
+    prefix: text.slice(Math.max(0, start - ANCHOR_CONTEXT_LENGTH), start),
+    suffix: text.slice(end, end + ANCHOR_CONTEXT_LENGTH),
+    start_offset: start,
+    end_offset: end,
+  };
+}
+
+export function useHighlights(articleId: Ref<number>) {
+  const highlights = ref<Highlight[]>([]);
+  const isLoading = ref(false);
+
+  async function fetchHighlights(): Promise<void> {
+    isLoading.value = true;
+    try {
+      const res = await fetch(`/api/highlights?article_id=${articleId.value}`);
+      if (res.ok) {
+        highlights.value = await res.json();
+      }
+    } catch (e) {
+      console.error('Error loading highlights:', e);
+    } finally {
+      isLoading.value = false;
+    }
+  }
+
+  async function createHighlight(anchor: HighlightAnchor, note = ''): Promise<boolean> {
+    try {
+      const res = await fetch('/api/highlights', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ article_id: articleId.value, note, ...anchor }),
+      });
+      if (!res.ok) return false;
+      const created: Highlight = await res.json();
+      highlights.value = [...highlights.value, created].sort(
+        (a, b) => a.start_offset - b.start_offset || a.id - b.id
+      );
+      return true;
+    } catch (e) {
+      console.error('Error creating highlight:', e);
+      return false;
+    }
+  }
+
+  async function updateHighlight(highlight: Highlight, note: string): Promise<boolean> {
+    try {
+      const res = await fetch('/api/highlights/update', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ id: highlight.id, note, color: highlight.color || '' }),
+      });
+      if (!res.ok) return false;
+      const updated: Highlight = await res.json();
+      highlights.value = highlights.value.map((h) => (h.id === updated.id ? updated : h));
+      return true;
+    } catch (e) {
+      console.error('Error updating highlight:', e);
+      return false;
+    }
+  }
+
+  async function deleteHighlight(highlight: Highlight): Promise<boolean> {
+    try {
+      const res = await fetch('/api/highlights/delete', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ id: highlight.id }),
+      });
+      if (!res.ok) return false;
+      highlights.value = highlights.value.filter((h) => h.id !== highlight.id);
+      return true;
+    } catch (e) {
+      console.error('Error deleting highlight:', e);
+      return false;
+    }
+  }
+
+  return {
+    highlights,
+    isLoading,
+    fetchHighlights,
+    createHighlight,
+    updateHighlight,
+    deleteHighlight,
+  };
+}
@@ -17,6 +17,10 @@ export function generateInitialSettings(): SettingsData {
     ai_chat_enabled: settingsDefaults.ai_chat_enabled,
     ai_chat_profile_id: settingsDefaults.ai_chat_profile_id,
     ai_custom_headers: settingsDefaults.ai_custom_headers,
+    ai_digest_profile_id: settingsDefaults.ai_digest_profile_id,
+    ai_embedding_enabled: settingsDefaults.ai_embedding_enabled,
+    ai_embedding_model: settingsDefaults.ai_embedding_model,
+    ai_embedding_profile_id: settingsDefaults.ai_embedding_profile_id,
     ai_endpoint: settingsDefaults.ai_endpoint,
     ai_model: settingsDefaults.ai_model,
     ai_search_enabled: settingsDefaults.ai_search_enabled,
@@ -48,8 +52,15 @@ export function generateInitialSettings(): SettingsData {
     deepl_api_key: settingsDefaults.deepl_api_key,
     deepl_endpoint: settingsDefaults.deepl_endpoint,
     default_view_mode: settingsDefaults.default_view_mode,
+    duplicate_action: settingsDefaults.duplicate_action,
+    duplicate_detection_enabled: settingsDefaults.duplicate_detection_enabled,
     feed_drawer_expanded: settingsDefaults.feed_drawer_expanded,
     feed_drawer_pinned: settingsDefaults.feed_drawer_pinned,
+    feed_health_backoff_failures: settingsDefaults.feed_health_backoff_failures,
+    feed_health_dead_days: settingsDefaults.feed_health_dead_days,
+    feed_health_pause_days: settingsDefaults.feed_health_pause_days,
+    feed_url_auto_migrate: settingsDefaults.feed_url_auto_migrate,
+    feed_url_migrate_other_sites: settingsDefaults.feed_url_migrate_other_sites,
     freshrss_api_password: settingsDefaults.freshrss_api_password,
     freshrss_auto_sync_interval: settingsDefaults.freshrss_auto_sync_interval,
     freshrss_enabled: settingsDefaults.freshrss_enabled,
@@ -81,6 +92,8 @@ export function generateInitialSettings(): SettingsData {
     obsidian_enabled: settingsDefaults.obsidian_enabled,
     obsidian_vault: settingsDefaults.obsidian_vault,
     obsidian_vault_path: settingsDefaults.obsidian_vault_path,
+    podcast_download_concurrency: settingsDefaults.podcast_download_concurrency,
+    podcast_storage_quota_mb: settingsDefaults.podcast_storage_quota_mb,
     proxy_enabled: settingsDefaults.proxy_enabled,
     proxy_host: settingsDefaults.proxy_host,
     proxy_password: settingsDefaults.proxy_password,
@@ -105,6 +118,9 @@ export function generateInitialSettings(): SettingsData {
     target_language: settingsDefaults.target_language,
     theme: settingsDefaults.theme,
     translation_enabled: settingsDefaults.translation_enabled,
+    translation_fallback_providers: settingsDefaults.translation_fallback_providers,
+    translation_full_article: settingsDefaults.translation_full_article,
+    translation_monthly_char_budgets: settingsDefaults.translation_monthly_char_budgets,
     translation_only_mode: settingsDefaults.translation_only_mode,
     translation_provider: settingsDefaults.translation_provider,
     update_interval: settingsDefaults.update_interval,
@@ -126,6 +142,11 @@ export function parseSettingsData(data: Record<string, string>): SettingsData {
     ai_chat_enabled: data.ai_chat_enabled === 'true',
     ai_chat_profile_id: data.ai_chat_profile_id || settingsDefaults.ai_chat_profile_id,
     ai_custom_headers: data.ai_custom_headers || settingsDefaults.ai_custom_headers,
+    ai_digest_profile_id: data.ai_digest_profile_id || settingsDefaults.ai_digest_profile_id,
+    ai_embedding_enabled: data.ai_embedding_enabled === 'true',
+    ai_embedding_model: data.ai_embedding_model || settingsDefaults.ai_embedding_model,
+    ai_embedding_profile_id:
+      data.ai_embedding_profile_id || settingsDefaults.ai_embedding_profile_id,
     ai_endpoint: data.ai_endpoint || settingsDefaults.ai_endpoint,
     ai_model: data.ai_model || settingsDefaults.ai_model,
     ai_search_enabled: data.ai_search_enabled === 'true',
@@ -166,8 +187,18 @@ export function parseSettingsData(data: Record<string, string>): SettingsData {
     deepl_api_key: data.deepl_api_key || settingsDefaults.deepl_api_key,
     deepl_endpoint: data.deepl_endpoint || settingsDefaults.deepl_endpoint,
     default_view_mode: data.default_view_mode || settingsDefaults.default_view_mode,
+    duplicate_action: data.duplicate_action || settingsDefaults.duplicate_action,
+    duplicate_detection_enabled: data.duplicate_detection_enabled === 'true',
     feed_drawer_expanded: data.feed_drawer_expanded === 'true',
     feed_drawer_pinned: data.feed_drawer_pinned === 'true',
+    feed_health_backoff_failures:
+      parseInt(data.feed_health_backoff_failures) || settingsDefaults.feed_health_backoff_failures,
+    feed_health_dead_days:
+      parseInt(data.feed_health_dead_days) || settingsDefaults.feed_health_dead_days,
+    feed_health_pause_days:
+      parseInt(data.feed_health_pause_days) || settingsDefaults.feed_health_pause_days,
+    feed_url_auto_migrate: data.feed_url_auto_migrate === 'true',
+    feed_url_migrate_other_sites: data.feed_url_migrate_other_sites === 'true',
     freshrss_api_password: data.freshrss_api_password || settingsDefaults.freshrss_api_password,
     freshrss_auto_sync_interval:
       parseInt(data.freshrss_auto_sync_interval) || settingsDefaults.freshrss_auto_sync_interval,
@@ -206,6 +237,10 @@ export function parseSettingsData(data: Record<string, string>): SettingsData {
     obsidian_enabled: data.obsidian_enabled === 'true',
     obsidian_vault: data.obsidian_vault || settingsDefaults.obsidian_vault,
     obsidian_vault_path: data.obsidian_vault_path || settingsDefaults.obsidian_vault_path,
+    podcast_download_concurrency:
+      parseInt(data.podcast_download_concurrency) || settingsDefaults.podcast_download_concurrency,
+    podcast_storage_quota_mb:
+      parseInt(data.podcast_storage_quota_mb) || settingsDefaults.podcast_storage_quota_mb,
     proxy_enabled: data.proxy_enabled === 'true',
     proxy_host: data.proxy_host || settingsDefaults.proxy_host,
     proxy_password: data.proxy_password || settingsDefaults.proxy_password,
@@ -231,6 +266,11 @@ export function parseSettingsData(data: Record<string, string>): SettingsData {
     target_language: data.target_language || settingsDefaults.target_language,
     theme: data.theme || settingsDefaults.theme,
     translation_enabled: data.translation_enabled === 'true',
+    translation_fallback_providers:
+      data.translation_fallback_providers || settingsDefaults.translation_fallback_providers,
+    translation_full_article: data.translation_full_article === 'true',
+    translation_monthly_char_budgets:
+      data.translation_monthly_char_budgets || settingsDefaults.translation_monthly_char_budgets,
     translation_only_mode: data.translation_only_mode === 'true',
     translation_provider: data.translation_provider || settingsDefaults.translation_provider,
     update_interval: parseInt(data.update_interval) || settingsDefaults.update_interval,
@@ -254,6 +294,14 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     ).toString(),
     ai_chat_profile_id: settingsRef.value.ai_chat_profile_id ?? settingsDefaults.ai_chat_profile_id,
     ai_custom_headers: settingsRef.value.ai_custom_headers ?? settingsDefaults.ai_custom_headers,
+    ai_digest_profile_id:
+      settingsRef.value.ai_digest_profile_id ?? settingsDefaults.ai_digest_profile_id,
+    ai_embedding_enabled: (
+      settingsRef.value.ai_embedding_enabled ?? settingsDefaults.ai_embedding_enabled
+    ).toString(),
+    ai_embedding_model: settingsRef.value.ai_embedding_model ?? settingsDefaults.ai_embedding_model,
+    ai_embedding_profile_id:
+      settingsRef.value.ai_embedding_profile_id ?? settingsDefaults.ai_embedding_profile_id,
     ai_endpoint: settingsRef.value.ai_endpoint ?? settingsDefaults.ai_endpoint,
     ai_model: settingsRef.value.ai_model ?? settingsDefaults.ai_model,
     ai_search_enabled: (
@@ -313,6 +361,27 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     deepl_api_key: settingsRef.value.deepl_api_key ?? settingsDefaults.deepl_api_key,
     deepl_endpoint: settingsRef.value.deepl_endpoint ?? settingsDefaults.deepl_endpoint,
     default_view_mode: settingsRef.value.default_view_mode ?? settingsDefaults.default_view_mode,
+    duplicate_action: settingsRef.value.duplicate_action ?? settingsDefaults.duplicate_action,
+    duplicate_detection_enabled: (
+      settingsRef.value.duplicate_detection_enabled ?? settingsDefaults.duplicate_detection_enabled
+    ).toString(),
+    feed_health_backoff_failures: (
+      settingsRef.value.feed_health_backoff_failures ??
+      settingsDefaults.feed_health_backoff_failures
+    ).toString(),
+    feed_health_dead_days: (
+      settingsRef.value.feed_health_dead_days ?? settingsDefaults.feed_health_dead_days
+    ).toString(),
+    feed_health_pause_days: (
+      settingsRef.value.feed_health_pause_days ?? settingsDefaults.feed_health_pause_days
+    ).toString(),
+    feed_url_auto_migrate: (
+      settingsRef.value.feed_url_auto_migrate ?? settingsDefaults.feed_url_auto_migrate
+    ).toString(),
+    feed_url_migrate_other_sites: (
+      settingsRef.value.feed_url_migrate_other_sites ??
+      settingsDefaults.feed_url_migrate_other_sites
+    ).toString(),
     freshrss_api_password:
       settingsRef.value.freshrss_api_password ?? settingsDefaults.freshrss_api_password,
     freshrss_auto_sync_interval: (
@@ -378,6 +447,13 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     obsidian_vault: settingsRef.value.obsidian_vault ?? settingsDefaults.obsidian_vault,
     obsidian_vault_path:
       settingsRef.value.obsidian_vault_path ?? settingsDefaults.obsidian_vault_path,
+    podcast_download_concurrency: (
+      settingsRef.value.podcast_download_concurrency ??
+      settingsDefaults.podcast_download_concurrency
+    ).toString(),
+    podcast_storage_quota_mb: (
+      settingsRef.value.podcast_storage_quota_mb ?? settingsDefaults.podcast_storage_quota_mb
+    ).toString(),
     proxy_enabled: (settingsRef.value.proxy_enabled ?? settingsDefaults.proxy_enabled).toString(),
     proxy_host: settingsRef.value.proxy_host ?? settingsDefaults.proxy_host,
     proxy_password: settingsRef.value.proxy_password ?? settingsDefaults.proxy_password,
@@ -419,6 +495,15 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     translation_enabled: (
       settingsRef.value.translation_enabled ?? settingsDefaults.translation_enabled
     ).toString(),
+    translation_fallback_providers:
+      settingsRef.value.translation_fallback_providers ??
+      settingsDefaults.translation_fallback_providers,
+    translation_full_article: (
+      settingsRef.value.translation_full_article ?? settingsDefaults.translation_full_article
+    ).toString(),
+    translation_monthly_char_budgets:
+      settingsRef.value.translation_monthly_char_budgets ??
+      settingsDefaults.translation_monthly_char_budgets,
     translation_only_mode: (
       settingsRef.value.translation_only_mode ?? settingsDefaults.translation_only_mode
     ).toString(),
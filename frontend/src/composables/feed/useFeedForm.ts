@@ -5,6 +5,7 @@ import { useAppStore } from '@/stores/app';
 
 export type FeedType = 'url' | 'script' | 'xpath' | 'email';
 export type ProxyMode = 'global' | 'custom' | 'none';
+export type XPathType = 'HTML+XPath' | 'XML+XPath' | 'json';
 export type RefreshMode = 'global' | 'fixed' | 'intelligent' | 'custom' | 'never';
 
 export function useFeedForm(feed?: Feed) {
@@ -24,8 +25,8 @@ export function useFeedForm(feed?: Feed) {
   const hideFromTimeline = ref(false);
   const isImageMode = ref(false);
 
-  // XPath fields
-  const xpathType = ref<'HTML+XPath' | 'XML+XPath'>('HTML+XPath');
+  // XPath fields, also used for the JSONPath mapping of JSON API feeds
+  const xpathType = ref<XPathType>('HTML+XPath');
   const xpathItem = ref('');
   const xpathItemTitle = ref('');
   const xpathItemContent = ref('');
@@ -37,6 +38,12 @@ export function useFeedForm(feed?: Feed) {
   const xpathItemCategories = ref('');
   const xpathItemUid = ref('');
 
+  // JSON API request and pagination options
+  const jsonHeaders = ref('');
+  const jsonNextPagePath = ref('');
+  const jsonPageParam = ref('');
+  const jsonMaxPages = ref(1);
+
   // Email/Newsletter fields
   const emailAddress = ref('');
   const imapServer = ref('');
@@ -130,6 +137,21 @@ export function useFeedForm(feed?: Feed) {
     }
   }
 
+  async function loadJSONFeedOptions(feedId: number) {
+    try {
+      const res = await fetch(`/api/feeds/json/options?feed_id=${feedId}`);
+      if (res.ok) {
+        const data = await res.json();
+        jsonHeaders.value = data.headers || '';
+        jsonNextPagePath.value = data.next_page_path || '';
+        jsonPageParam.value = data.page_param || '';
+        jsonMaxPages.value = data.max_pages || 1;
+      }
+    } catch (e) {
+      console.error('Failed to load JSON feed options:', e);
+    }
+  }
+
   async function loadScripts() {
     try {
       const res = await fetch('/api/scripts/list');
@@ -217,8 +239,8 @@ export function useFeedForm(feed?: Feed) {
 
     // Initialize XPath fields
     xpathType.value =
-      feed.type === 'HTML+XPath' || feed.type === 'XML+XPath'
-        ? (feed.type as 'HTML+XPath' | 'XML+XPath')
+      feed.type === 'HTML+XPath' || feed.type === 'XML+XPath' || feed.type === 'json'
+        ? (feed.type as XPathType)
         : 'HTML+XPath';
     xpathItem.value = feed.xpath_item || '';
     xpathItemTitle.value = feed.xpath_item_title || '';
@@ -244,6 +266,9 @@ export function useFeedForm(feed?: Feed) {
       feedType.value = 'script';
     } else if (feed.xpath_item) {
       feedType.value = 'xpath';
+      if (feed.type === 'json') {
+        loadJSONFeedOptions(feed.id);
+      }
     } else if (feed.type === 'email') {
       feedType.value = 'email';
       // Initialize email fields
@@ -324,6 +349,10 @@ export function useFeedForm(feed?: Feed) {
     xpathItemThumbnail.value = '';
     xpathItemCategories.value = '';
     xpathItemUid.value = '';
+    jsonHeaders.value = '';
+    jsonNextPagePath.value = '';
+    jsonPageParam.value = '';
+    jsonMaxPages.value = 1;
     // Reset email fields
     emailAddress.value = '';
     imapServer.value = '';
@@ -390,6 +419,10 @@ export function useFeedForm(feed?: Feed) {
     xpathItemThumbnail,
     xpathItemCategories,
     xpathItemUid,
+    jsonHeaders,
+    jsonNextPagePath,
+    jsonPageParam,
+    jsonMaxPages,
     // Email fields
     emailAddress,
     imapServer,
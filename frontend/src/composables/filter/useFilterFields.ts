@@ -17,6 +17,7 @@ export function useFilterFields() {
     { value: 'feed_name', labelKey: 'modal.feed.feedName', multiSelect: true },
     { value: 'feed_category', labelKey: 'modal.feed.feedCategory', multiSelect: true },
     { value: 'feed_tags', labelKey: 'modal.feed.feedTags', multiSelect: true },
+    { value: 'article_tags', labelKey: 'modal.filter.articleTags', multiSelect: true },
     { value: 'article_title', labelKey: 'article.parts.articleTitle', multiSelect: false },
     { value: 'article_content', labelKey: 'modal.filter.articleContent', multiSelect: false },
     { value: 'author', labelKey: 'modal.filter.author', multiSelect: false },
@@ -101,6 +102,12 @@ export function useFilterFields() {
       multiSelect: false,
       booleanField: true,
     },
+    {
+      value: 'is_youtube_short',
+      labelKey: 'modal.filter.isYouTubeShort',
+      multiSelect: false,
+      booleanField: true,
+    },
   ];
 
   /**
@@ -149,7 +156,7 @@ export function useFilterFields() {
 
   /**
    * Get available feed types (as type codes, not translated text)
-   * Type codes: "regular", "freshrss", "rsshub", "script", "xpath", "email"
+   * Type codes: "regular", "freshrss", "rsshub", "script", "xpath", "email", "youtube", "mastodon", "json"
    */
   const feedTypes: ComputedRef<string[]> = computed(() => {
     const typeSet = new Set<string>();
@@ -166,6 +173,12 @@ export function useFilterFields() {
         typeCode = 'email';
       } else if (f.type === 'HTML+XPath' || f.type === 'XML+XPath') {
         typeCode = 'xpath';
+      } else if (f.type === 'youtube') {
+        typeCode = 'youtube';
+      } else if (f.type === 'mastodon') {
+        typeCode = 'mastodon';
+      } else if (f.type === 'json') {
+        typeCode = 'json';
       } else {
         // Default: regular RSS/Atom feed
         typeCode = 'regular';
@@ -202,7 +215,8 @@ export function useFilterFields() {
       field === 'feed_name' ||
       field === 'feed_category' ||
       field === 'feed_type' ||
-      field === 'feed_tags'
+      field === 'feed_tags' ||
+      field === 'article_tags'
     );
   }
 
@@ -219,7 +233,8 @@ export function useFilterFields() {
       field === 'has_translation' ||
       field === 'has_image' ||
       field === 'has_audio' ||
-      field === 'has_video'
+      field === 'has_video' ||
+      field === 'is_youtube_short'
     );
   }
 
@@ -1,27 +1,33 @@
 import { type Ref } from 'vue';
-import type { ActionOption } from './useRuleOptions';
+import {
+  defaultActionParams,
+  type ActionOption,
+  type RuleActionItem,
+} from './useRuleOptions';
 
 export function useRuleActions(actionOptions: ActionOption[]) {
-  function addAction(actions: Ref<string[]>): void {
-    const selectedActions = new Set(actions.value);
-    const available = actionOptions.find((opt) => !selectedActions.has(opt.value));
+  function addAction(actions: Ref<RuleActionItem[]>): void {
+    const available = getAvailableActions(actions, '')[0];
     if (available) {
-      actions.value.push(available.value);
+      actions.value.push({ type: available.value, params: defaultActionParams(available.value) });
     }
   }
 
-  function removeAction(actions: Ref<string[]>, index: number): void {
+  function removeAction(actions: Ref<RuleActionItem[]>, index: number): void {
     actions.value.splice(index, 1);
   }
 
-  function updateAction(actions: Ref<string[]>, index: number, value: string): void {
+  function updateAction(actions: Ref<RuleActionItem[]>, index: number, value: RuleActionItem): void {
     actions.value[index] = value;
   }
 
-  function getAvailableActions(actions: Ref<string[]>, currentValue: string): ActionOption[] {
-    const selectedActions = new Set(actions.value);
+  function getAvailableActions(
+    actions: Ref<RuleActionItem[]>,
+    currentValue: string
+  ): ActionOption[] {
+    const selectedActions = new Set(actions.value.map((a) => a.type));
     return actionOptions.filter(
-      (opt) => !selectedActions.has(opt.value) || opt.value === currentValue
+      (opt) => opt.repeatable || !selectedActions.has(opt.value) || opt.value === currentValue
     );
   }
 
@@ -21,6 +21,12 @@ export interface FieldOption {
 export interface ActionOption {
   value: string;
   labelKey: string;
+  repeatable?: boolean; // Parameterized actions can be added more than once
+}
+
+export interface RuleActionItem {
+  type: string;
+  params?: Record<string, string>;
 }
 
 export function useRuleOptions() {
@@ -31,6 +37,7 @@ export function useRuleOptions() {
     { value: 'feed_name', labelKey: 'modal.feed.feedName', multiSelect: true },
     { value: 'feed_category', labelKey: 'modal.feed.feedCategory', multiSelect: true },
     { value: 'feed_tags', labelKey: 'modal.feed.feedTags', multiSelect: true },
+    { value: 'article_tags', labelKey: 'modal.filter.articleTags', multiSelect: true },
     { value: 'article_title', labelKey: 'article.parts.articleTitle', multiSelect: false },
     { value: 'article_content', labelKey: 'modal.filter.articleContent', multiSelect: false },
     { value: 'author', labelKey: 'modal.filter.author', multiSelect: false },
@@ -121,6 +128,12 @@ export function useRuleOptions() {
       multiSelect: false,
       booleanField: true,
     },
+    {
+      value: 'is_youtube_short',
+      labelKey: 'modal.filter.isYouTubeShort',
+      multiSelect: false,
+      booleanField: true,
+    },
   ];
 
   // Operator options for article title
@@ -146,6 +159,13 @@ export function useRuleOptions() {
     { value: 'mark_unread', labelKey: 'setting.rule.actionMarkUnread' },
     { value: 'read_later', labelKey: 'setting.rule.actionReadLater' },
     { value: 'remove_read_later', labelKey: 'setting.rule.actionRemoveReadLater' },
+    { value: 'add_tag', labelKey: 'setting.rule.actionAddTag', repeatable: true },
+    { value: 'move', labelKey: 'setting.rule.actionMove' },
+    { value: 'fetch_content', labelKey: 'setting.rule.actionFetchContent' },
+    { value: 'summarize', labelKey: 'setting.rule.actionSummarize' },
+    { value: 'translate_title', labelKey: 'setting.rule.actionTranslateTitle' },
+    { value: 'export', labelKey: 'setting.rule.actionExport', repeatable: true },
+    { value: 'webhook', labelKey: 'setting.rule.actionWebhook', repeatable: true },
   ];
 
   // Feed names for multi-select
@@ -165,7 +185,7 @@ export function useRuleOptions() {
   });
 
   // Feed types for multi-select (as type codes, not translated text)
-  // Type codes: "regular", "freshrss", "rsshub", "script", "xpath", "email"
+  // Type codes: "regular", "freshrss", "rsshub", "script", "xpath", "email", "youtube", "mastodon", "json"
   const feedTypes: ComputedRef<string[]> = computed(() => {
     const typeSet = new Set<string>();
     store.feeds.forEach((f) => {
@@ -181,6 +201,12 @@ export function useRuleOptions() {
         typeCode = 'email';
       } else if (f.type === 'HTML+XPath' || f.type === 'XML+XPath') {
         typeCode = 'xpath';
+      } else if (f.type === 'youtube') {
+        typeCode = 'youtube';
+      } else if (f.type === 'mastodon') {
+        typeCode = 'mastodon';
+      } else if (f.type === 'json') {
+        typeCode = 'json';
       } else {
         // Default: regular RSS/Atom feed
         typeCode = 'regular';
@@ -200,6 +226,9 @@ export function useRuleOptions() {
     return Array.from(tagSet);
   });
 
+  // Article tags for multi-select
+  const articleTags: ComputedRef<string[]> = computed(() => store.tags.map((t) => t.name));
+
   return {
     fieldOptions,
     textOperatorOptions,
@@ -209,9 +238,31 @@ export function useRuleOptions() {
     feedCategories,
     feedTypes,
     feedTags,
+    articleTags,
   };
 }
 
+// Convert a legacy string action ("favorite") to an action object
+export function normalizeAction(action: string | RuleActionItem): RuleActionItem {
+  return typeof action === 'string' ? { type: action } : action;
+}
+
+// Default parameters for a newly selected action type
+export function defaultActionParams(type: string): Record<string, string> | undefined {
+  switch (type) {
+    case 'summarize':
+      return { length: 'medium' };
+    case 'export':
+      return { target: 'obsidian' };
+    case 'add_tag':
+    case 'move':
+    case 'webhook':
+      return {};
+    default:
+      return undefined;
+  }
+}
+
 // Helper functions for field types
 export function isDateField(field: string): boolean {
   return field === 'published_after' || field === 'published_before';
@@ -222,7 +273,8 @@ export function isMultiSelectField(field: string): boolean {
     field === 'feed_name' ||
     field === 'feed_category' ||
     field === 'feed_type' ||
-    field === 'feed_tags'
+    field === 'feed_tags' ||
+    field === 'article_tags'
   );
 }
 
@@ -237,7 +289,8 @@ export function isBooleanField(field: string): boolean {
     field === 'has_translation' ||
     field === 'has_image' ||
     field === 'has_audio' ||
-    field === 'has_video'
+    field === 'has_video' ||
+    field === 'is_youtube_short'
   );
 }
 
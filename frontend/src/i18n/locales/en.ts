@@ -52,12 +52,24 @@ const en: TranslationMessages = {
     audioPlayer: {
       audioPlaybackError:
         'Failed to play audio. The file may be unavailable or in an unsupported format.',
+      cancelDownload: 'Cancel download',
+      chapters: 'Chapters ({count})',
+      deleteDownload: 'Delete Download',
+      deleteDownloadConfirm: 'Delete the downloaded file of this episode? It can still be streamed.',
+      deleteDownloadFailed: 'Failed to delete download',
+      downloaded: 'Available offline',
+      downloadEpisode: 'Download for offline',
+      downloadFailed: 'Failed to download episode',
+      downloadingEpisode: 'Downloading...',
+      downloadQueued: 'Waiting to download...',
       pause: 'Pause',
       play: 'Play',
       playbackSpeed: 'Playback Speed',
       podcastAudio: 'Podcast Audio',
+      retryDownload: 'Download failed, retry',
       skipBackward: 'Backward 10s',
       skipForward: 'Forward 10s',
+      transcript: 'Transcript',
       volume: 'Volume',
     },
     chat: {
@@ -81,6 +93,14 @@ const en: TranslationMessages = {
       renderContent: 'Render Content',
       selectArticle: 'Select an article to start reading',
     },
+    highlights: {
+      add: 'Highlight Selection',
+      delete: 'Delete Highlight',
+      notePlaceholder: 'Add a note...',
+      saveFailed: 'Failed to save highlight',
+      selectTextFirst: 'Select text in the article to highlight it',
+      title: 'Highlights',
+    },
     imageGallery: {
       actionFavorite: 'Add to Favorites',
       actionUnfavorite: 'Remove from Favorites',
@@ -101,6 +121,15 @@ const en: TranslationMessages = {
     progress: {
       activeTasks: 'Active Tasks',
     },
+    related: {
+      loading: 'Finding related articles...',
+      title: 'Related Articles',
+    },
+    story: {
+      otherSources: 'Also reported by {count} other feeds',
+      primary: 'First reported',
+      title: 'Also Reported By',
+    },
     summary: {
       aiLimitReached: 'AI usage limit reached. Using free alternatives.',
       aiSummaryFallback: 'AI summarization failed. Using built-in algorithm.',
@@ -117,6 +146,9 @@ const en: TranslationMessages = {
     },
     videoPlayer: {
       openInYouTube: 'Open in YouTube',
+      openVideo: 'Open Video',
+      video: 'Video',
+      videoFileLoadError: 'Failed to load video.',
       videoLoadError: 'Failed to load video. Please try opening it in YouTube.',
       youtubeVideo: 'YouTube Video',
     },
@@ -126,9 +158,11 @@ const en: TranslationMessages = {
     buttonTitle: 'Use AI to search articles with natural language',
     clearResults: 'Clear',
     foundResults: 'Found {count} articles',
+    keywordMode: 'Keyword search: click to search by meaning',
     noResults: 'No articles found matching your search',
     placeholder: 'Describe what you want to find...',
     searchFailed: 'AI search failed. Please check your AI settings.',
+    semanticMode: 'Semantic search: click to search by keywords',
     showingResults: 'Showing AI search results',
   },
   common: {
@@ -356,6 +390,21 @@ const en: TranslationMessages = {
       feedsSubscribedPartial: 'Partially subscribed: {succeeded}/{total} feeds',
       feedsSubscribedSuccess: 'Successfully subscribed to {count} feeds',
       feedUpdatedSuccess: 'Feed updated successfully',
+      httpAuth: 'Authentication',
+      httpAuthNone: 'None',
+      httpAuthPassword: 'Password',
+      httpAuthToken: 'Token',
+      httpAuthUsername: 'Username',
+      httpCookies: 'Cookies',
+      httpHeaders: 'Headers (one "Name: value" per line)',
+      httpMethod: 'Request Method',
+      httpOptions: 'HTTP Options',
+      httpOptionsDesc: 'Headers, cookies and login for paywalled or members-only feeds',
+      httpOptionsHelp:
+        'Used for the feed, full-text fetching and images on the same site. Headers, cookies and secrets are stored encrypted, are not shown again and are not included in OPML exports. Leave a value empty to keep the saved one.',
+      httpOptionsSaveFailed: 'Failed to save the HTTP options',
+      httpUserAgent: 'User Agent',
+      httpUserAgentPlaceholder: 'Default',
       imageModeSetSuccess: 'Image mode enabled for selected feeds',
       imageModeUnsetSuccess: 'Image mode disabled for selected feeds',
       selectTagsToAdd: 'Select tags to add:',
@@ -364,8 +413,42 @@ const en: TranslationMessages = {
       tagsAddedSuccess: 'Tags added successfully',
       unsetImageModeMessage: 'Disable image mode for {count} selected feed(s)?',
       unsetImageModeTitle: 'Unset Image Mode',
+      urlChangeNewFeedURL: 'New feed URL announced',
+      urlChangeRedirect: 'Permanent redirect',
+      urlChangeSelfLink: 'Self link changed',
+      urlHistory: 'URL History',
+      urlMergedFrom: 'merged from {title}',
+      jsonApi: 'JSON API + JSONPath',
+      jsonHeaders: 'Request Headers',
+      jsonHeadersHelp: 'One "Name: value" per line, e.g. for API keys. Stored encrypted.',
+      jsonItem: 'Item JSONPath',
+      jsonItemAuthor: 'Author Path',
+      jsonItemCategories: 'Categories Path',
+      jsonItemContent: 'Content Path',
+      jsonItemHelp: 'JSONPath of the items in the response. Field paths are relative to an item.',
+      jsonItemThumbnail: 'Thumbnail Path',
+      jsonItemTimeFormat: 'Time Format',
+      jsonItemTimestamp: 'Date Path',
+      jsonItemTitle: 'Title Path',
+      jsonItemUid: 'UID Path',
+      jsonItemUri: 'Link Path',
+      jsonMaxPages: 'Max Pages',
+      jsonNextPagePath: 'Next Page Path',
+      jsonPageParam: 'Page Parameter',
+      jsonPaginationHelp:
+        'The next page path selects a URL, or a cursor passed in the page parameter. With only a page parameter, it is used as page number.',
+      jsonPreview: 'Preview Items',
+      jsonPreviewCount: '{count} items found',
+      jsonPreviewing: 'Loading preview...',
+      jsonPreviewNoTitle: '(No title)',
       manageFeeds: 'Manage Feeds',
       noFeeds: 'No feeds yet',
+      podcastAutoDownload: 'Download New Episodes',
+      podcastAutoDownloadDesc: 'Automatically download the newest episodes after each refresh',
+      podcastKeepEpisodes: 'Keep downloaded episodes',
+      podcastRetentionDays: 'Delete downloads after (days)',
+      podcastRetentionDesc: '0 keeps all downloads. Older downloads are deleted first.',
+      podcastSettingsSaveFailed: 'Failed to save podcast settings',
       proxy: 'Feed Proxy',
       proxyDesc: 'Configure proxy settings for this feed',
       proxyHost: 'Proxy Host',
@@ -381,17 +464,48 @@ const en: TranslationMessages = {
       refreshModeDesc: 'How this feed should be refreshed',
       refreshSettings: 'Feed Refresh Settings',
       rssUrl: 'RSS URL',
+      scriptArgs: 'Arguments',
+      scriptArgsHelp: 'One command line argument per line',
+      scriptEnv: 'Environment Variables',
+      scriptEnvHelp: 'One "NAME=value" per line',
+      scriptNoRuns: 'The script has not run yet',
+      scriptRunDuration: '{ms} ms',
+      scriptRunExitCode: 'Exit code {code}',
+      scriptRunItems: '{count} items',
+      scriptRuns: 'Recent Runs',
+      scriptSecretAdd: 'Add Secret',
+      scriptSecretKeep: 'Unchanged',
+      scriptSecrets: 'Secrets',
+      scriptSecretsHelp:
+        'Passed as environment variables and stored encrypted. Leave a value empty to keep the saved one.',
+      scriptSettings: 'Script Options',
+      scriptStateHelp:
+        'The script gets the state of its last run on stdin and in the file named by MRRSS_STATE_FILE, and can overwrite the file to update it.',
+      scriptTimeout: 'Timeout (seconds)',
+      scriptTimeoutHelp: '0 uses the default of 30 seconds, at most 600',
       sourceUrl: 'Source URL',
       sourceUrlPlaceholder: 'https://example.com/blog',
       syncFeed: 'Sync Feed',
       syncFeedStarted: 'Feed sync started',
       titlePlaceholder: 'Custom feed title',
+      transform: 'Item Transform',
+      transformDesc: 'Change or drop items with JavaScript before they are saved',
+      transformDropped: 'dropped',
+      transformHelp:
+        'Define transform(item, feed). Change the fields of item (title, link, content, description, author, image, categories, published), return a new object, or return false to drop it. queryParam(url, name) reads a URL parameter, console.log output is shown by the test.',
+      transformSaveFailed: 'Failed to save the item transform',
+      transformTest: 'Test on Current Items',
+      transformTestCount: '{kept} kept, {dropped} dropped',
+      transformTesting: 'Testing...',
       typeCustomScript: 'Custom Script',
       typeEmail: 'Email Feed',
+      typeJSON: 'JSON API',
+      typeMastodon: 'Mastodon',
       typeFreshRSS: 'FreshRSS Feed',
       typeRegular: 'Regular Feed',
       typeRSSHub: 'RSSHub Feed',
       typeXPath: 'XPath',
+      typeYouTube: 'YouTube',
       xpath: 'XPath Support',
       xpathDocumentation: 'XPath Documentation',
       xpathHtml: 'HTML + XPath',
@@ -462,11 +576,13 @@ const en: TranslationMessages = {
       author: 'Author',
       url: 'URL',
       articleContent: 'Article Content',
+      articleTags: 'Article Tags',
       hasSummary: 'Has Summary',
       hasTranslation: 'Has Translation',
       hasImage: 'Has Image',
       hasAudio: 'Has Audio',
       hasVideo: 'Has Video',
+      isYouTubeShort: 'Is YouTube Short',
       feedArticlesPerMonth: 'Feed Articles Per Month',
       feedLastUpdateStatus: 'Feed Update Status',
       updateSuccess: 'Success',
@@ -559,6 +675,7 @@ const en: TranslationMessages = {
       selectProfileForSummary: 'Select which AI profile to use for summary generation',
       selectProfileForChat: 'Select which AI profile to use for AI chat',
       selectProfileForSearch: 'Select which AI profile to use for AI search',
+      selectProfileForEmbedding: 'Select which AI profile to use for embeddings',
       model: 'Model',
       aiTestFailed: 'AI configuration test failed',
       aiUsage: 'AI Usage',
@@ -575,6 +692,17 @@ const en: TranslationMessages = {
       clearAllChatsDesc: 'Delete all AI chat sessions',
       clearAllChatsFailed: 'Failed to clear chat history',
       clearAllChatsSuccess: 'Chat history cleared successfully',
+      clearEmbeddings: 'Rebuild Embeddings',
+      clearEmbeddingsButton: 'Rebuild',
+      clearEmbeddingsConfirm:
+        'Delete all stored embeddings and compute them again? This consumes AI tokens.',
+      clearEmbeddingsFailed: 'Failed to clear embeddings',
+      embeddingEnabled: 'Semantic Search & Related Articles',
+      embeddingEnabledDesc:
+        'Compute embeddings of articles in the background to search by meaning and find related articles',
+      embeddingModel: 'Embedding Model',
+      embeddingModelDesc: 'Model used to compute embeddings, overrides the model of the AI profile',
+      embeddingProgress: '{embedded} of {total} articles embedded',
       configValid: 'Config Valid',
       connectionSuccess: 'Connection',
       isBeta:
@@ -588,6 +716,25 @@ const en: TranslationMessages = {
       testing: 'Testing...',
       tokens: 'tokens',
     },
+    backup: {
+      export: 'Export Backup',
+      exportFailed: 'Failed to export backup',
+      import: 'Import Backup',
+      importFailed: 'Failed to import backup',
+      importSuccess: 'Backup restored: {added} feeds added, {updated} updated',
+      includeSecrets: 'Include Secrets',
+      includeSecretsDesc:
+        'Store passwords, API keys, request headers and script secrets in the backup file',
+      mode: 'Import Mode',
+      modeDesc: 'How to handle feeds and settings that already exist',
+      modeMerge: 'Merge',
+      modeReplace: 'Replace',
+      replaceConfirm:
+        'Feeds, tags, saved filters and AI profiles that are not in the backup will be deleted. Continue?',
+      title: 'Full Backup',
+      titleDesc:
+        'Back up feeds with all their settings, tags, saved filters, rules, AI profiles and favorites',
+    },
     content: {
       addHeader: 'Add Header',
       addLangMapping: 'Add Mapping',
@@ -667,6 +814,9 @@ const en: TranslationMessages = {
       translatingContent: 'Translating content...',
       translation: 'Translation',
       translationCredentialsRequired: 'Translation service requires API key or credentials',
+      translationFullArticle: 'Translate Whole Article',
+      translationFullArticleDesc:
+        'Translate the full article on the server in chunks and keep the result for later visits',
       translationOnlyMode: 'Translation Only Mode',
       translationOnlyModeDesc: 'Show only translated text, hide original content',
       translationProvider: 'Translation Provider',
@@ -701,6 +851,22 @@ const en: TranslationMessages = {
         timeoutDesc: 'Request timeout in seconds',
         title: 'Custom API',
       },
+      fallback: {
+        budgetExhausted: 'budget used up',
+        budgets: 'Monthly Character Budgets',
+        budgetsDesc:
+          'Characters each provider may translate per month, as JSON by provider. Leave empty for no limit',
+        notConfigured: 'not configured',
+        providers: 'Fallback Providers',
+        providersDesc:
+          'Comma-separated providers tried in order when the main provider fails (google, deepl, baidu, ai, custom)',
+        refresh: 'Refresh',
+        stateClosed: 'healthy',
+        stateHalfOpen: 'retrying',
+        stateOpen: 'paused until {time}',
+        status: 'Provider Status',
+        statusDesc: 'Characters translated this month and health of each provider in the chain',
+      },
     },
     typography: {
       layoutMode: 'Article List Layout',
@@ -777,6 +943,35 @@ const en: TranslationMessages = {
       clearMediaCacheConfirm:
         'Are you sure you want to clear all media cache? This action cannot be undone.',
     },
+    digest: {
+      add: 'Add Digest',
+      articleCount: '{count} articles',
+      daily: 'Daily',
+      dailyAt: 'Daily at {time}',
+      delete: 'Delete Digest',
+      deleteConfirm: 'Delete digest "{name}" and its history?',
+      failed: 'Failed',
+      generate: 'Generate now',
+      generateFailed: 'Failed to generate digest',
+      generated: 'Digest generated',
+      history: 'History',
+      lastHours: 'last {hours}h',
+      manual: 'Manual only',
+      name: 'Name',
+      noHistory: 'No digests generated yet',
+      publishToFeed: 'Publish to Digests feed',
+      saveFailed: 'Failed to save digest',
+      selectProfile: 'AI profile used to write digests',
+      sourceCategory: 'Category',
+      sourceFilter: 'Saved filter',
+      sourceTag: 'Tag',
+      title: 'AI Digests',
+      titleDesc:
+        'Summarize recent articles of a saved filter, category or tag into a briefing grouped by topic, with links to the sources',
+      weekly: 'Weekly',
+      weeklyAt: '{day} at {time}',
+      window: 'Hours',
+    },
     feed: {
       addFeed: 'Add Feed',
       articleViewMode: 'Article View Mode',
@@ -801,6 +996,35 @@ const en: TranslationMessages = {
       useGlobalSettings: 'Use Global Settings',
       useIntelligentInterval: 'Intelligent Interval',
     },
+    feedHealth: {
+      allHealthy: 'All feeds are refreshing normally',
+      autoMigrate: 'Follow Moved Feeds',
+      autoMigrateDesc:
+        'Update the URL of feeds that permanently redirect or announce a new address on the same site, merging them into an existing subscription there',
+      migrateOtherSites: 'Follow Announced Addresses on Other Sites',
+      migrateOtherSitesDesc:
+        'Also move feeds whose self link or itunes:new-feed-url points to another site. Feeds with custom HTTP options are never moved to another site',
+      backoffFailures: 'Back Off After Failures',
+      backoffFailuresDesc:
+        'Consecutive failed refreshes after which a feed is refreshed less often, waiting up to a day between attempts',
+      backoffUntil: 'Backing off until {time}',
+      dead: 'Dead',
+      deadDays: 'Flag As Dead After',
+      deadDaysDesc: 'Flag a feed as dead when it has published no new articles for this long',
+      details: '{rate}% successful, {p50} ms median, {p90} ms p90',
+      failing: 'Failing',
+      failingCount: '{count} failed refreshes in a row',
+      healthy: 'Healthy',
+      movedTo: 'Permanently moved to {url}',
+      noNewFor: 'No new articles for {count} days',
+      paused: 'Paused',
+      pausedCount: 'Paused after {count} failed refreshes',
+      pauseDays: 'Pause After',
+      pauseDaysDesc:
+        'Stop refreshing a feed that has failed for this long; a manual refresh resumes it',
+      refresh: 'Refresh',
+      title: 'Feed Health',
+    },
     general: {
       application: 'Application',
       auto: 'Auto (Follow System)',
@@ -886,6 +1110,20 @@ const en: TranslationMessages = {
       usernameDesc: 'The FreshRSS username',
       usernamePlaceholder: 'Enter your username',
     },
+    podcast: {
+      concurrency: 'Parallel Downloads',
+      concurrencyDesc: 'Number of episodes downloaded at the same time',
+      deleteDownload: 'Delete Download',
+      deleteDownloadConfirm: 'Delete the downloaded file of "{title}"?',
+      downloads: 'Downloaded Episodes',
+      failed: 'Failed',
+      noDownloads: 'No downloaded episodes',
+      storageQuota: 'Storage Quota',
+      storageQuotaDesc:
+        'Maximum space used by downloaded episodes, 0 for unlimited. Played and oldest downloads are deleted first.',
+      storageUsed: 'Storage used',
+      title: 'Podcasts',
+    },
     plugins: {
       notion: {
         apiKey: 'API Key',
@@ -927,6 +1165,15 @@ const en: TranslationMessages = {
         'Automatically display the full content of all articles when viewed as rendered content (may increase loading time)',
       defaultViewMode: 'Article View Mode',
       defaultViewModeDesc: 'Choose how articles should be displayed',
+      duplicateAction: 'Duplicates',
+      duplicateActionDesc:
+        'What to do with later articles about a story another feed already reported',
+      duplicateActionHide: 'Hide',
+      duplicateActionMarkRead: 'Mark as read',
+      duplicateActionNone: 'Keep',
+      duplicateDetection: 'Group Duplicate Stories',
+      duplicateDetectionDesc:
+        'Detect articles from different feeds that report the same story by their link, title and content',
       hideAdvancedSettings: 'Hide Advanced Settings',
       hideFromTimeline: 'Hide from Timeline',
       hideFromTimelineDesc: 'Hide this feed\'s articles from "All Articles" and "Unread" views',
@@ -970,21 +1217,36 @@ const en: TranslationMessages = {
       urlPlaceholder: 'RSS route (supporting rsshub:// protocol)',
     },
     rule: {
+      actionAddTag: 'Add Tag',
+      actionExport: 'Export To',
       actionFavorite: 'Add to Favorites',
+      actionFetchContent: 'Fetch Full Content',
       actionHide: 'Hide Article',
       actionMarkRead: 'Mark as Read',
       actionMarkUnread: 'Mark as Unread',
+      actionMove: 'Move Feed to Category',
       actionReadLater: 'Add to Read Later',
       actionRemoveReadLater: 'Remove from Read Later',
+      actionSummarize: 'Summarize with AI',
+      actionTranslateTitle: 'Translate Title',
       actionUnfavorite: 'Remove from Favorites',
       actionUnhide: 'Unhide Article',
+      actionWebhook: 'Send to Webhook',
       addRule: 'Add Rule',
       applyRuleNow: 'Apply Now',
+      categoryPlaceholder: 'Category, e.g. Tech/AI',
+      defaultProfile: 'Default AI profile',
+      missingActionParams: 'Please fill in the parameters of every action',
       noActionsSelected: 'Please select at least one action',
       noRules: 'No rules defined',
       noRulesHint: 'Create a rule to automatically process articles',
       removeAction: 'Remove Action',
       removeCondition: 'Remove',
+      selectTag: 'Select a tag',
+      summaryLong: 'Long',
+      summaryMedium: 'Medium',
+      summaryShort: 'Short',
+      targetLanguagePlaceholder: 'Target language (default from settings)',
     },
     shortcut: {
       addFeedShortcut: 'Add Feed',
@@ -1054,6 +1316,20 @@ const en: TranslationMessages = {
       updateWillRestart: 'The application will restart to install the update',
       upToDate: 'You are using the latest version',
     },
+    webhook: {
+      add: 'Add Webhook',
+      delete: 'Delete Webhook',
+      deleteConfirm: 'Delete webhook "{name}"?',
+      name: 'Name',
+      saveFailed: 'Failed to save webhook',
+      secret: 'Signing secret (optional)',
+      test: 'Send test event',
+      testFailed: 'Test delivery failed',
+      testSuccessful: 'Test delivery succeeded',
+      title: 'Webhooks',
+      titleDesc:
+        'Send signed HTTP requests when new articles arrive, articles are starred or saved for later, or a feed starts failing',
+    },
   },
   sidebar: {
     activity: {
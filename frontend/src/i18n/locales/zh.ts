@@ -49,12 +49,24 @@ const zh: TranslationMessages = {
     },
     audioPlayer: {
       audioPlaybackError: '无法播放音频。文件可能不可用或格式不受支持。',
+      cancelDownload: '取消下载',
+      chapters: '章节（{count}）',
+      deleteDownload: '删除下载',
+      deleteDownloadConfirm: '删除此单集的下载文件？仍可在线播放。',
+      deleteDownloadFailed: '删除下载失败',
+      downloaded: '可离线播放',
+      downloadEpisode: '下载以离线收听',
+      downloadFailed: '单集下载失败',
+      downloadingEpisode: '下载中...',
+      downloadQueued: '等待下载...',
       pause: '暂停',
       play: '播放',
       playbackSpeed: '播放速度',
       podcastAudio: '播客音频',
+      retryDownload: '下载失败，重试',
       skipBackward: '后退 10 秒',
       skipForward: '前进 10 秒',
+      transcript: '文字稿',
       volume: '音量',
     },
     chat: {
@@ -78,6 +90,14 @@ const zh: TranslationMessages = {
       renderContent: '渲染内容',
       selectArticle: '选择一篇文章开始阅读',
     },
+    highlights: {
+      add: '高亮选中内容',
+      delete: '删除高亮',
+      notePlaceholder: '添加笔记...',
+      saveFailed: '保存高亮失败',
+      selectTextFirst: '请先在文章中选择要高亮的文本',
+      title: '高亮',
+    },
     imageGallery: {
       actionFavorite: '添加到收藏',
       actionUnfavorite: '取消收藏',
@@ -98,6 +118,15 @@ const zh: TranslationMessages = {
     progress: {
       activeTasks: '进行中任务',
     },
+    related: {
+      loading: '正在查找相关文章...',
+      title: '相关文章',
+    },
+    story: {
+      otherSources: '另有 {count} 个订阅源报道了此内容',
+      primary: '最早报道',
+      title: '其他报道',
+    },
     summary: {
       aiLimitReached: 'AI 使用量已达上限，正在使用免费替代方案。',
       aiSummaryFallback: 'AI 摘要生成失败，正在使用内置算法。',
@@ -114,6 +143,9 @@ const zh: TranslationMessages = {
     },
     videoPlayer: {
       openInYouTube: '在 YouTube 中打开',
+      openVideo: '打开视频',
+      video: '视频',
+      videoFileLoadError: '加载视频失败。',
       videoLoadError: '加载视频失败，请尝试在 YouTube 中打开。',
       youtubeVideo: 'YouTube 视频',
     },
@@ -123,9 +155,11 @@ const zh: TranslationMessages = {
     buttonTitle: '使用 AI 通过自然语言搜索文章',
     clearResults: '清除',
     foundResults: '找到 {count} 篇文章',
+    keywordMode: '关键词搜索：点击切换为语义搜索',
     noResults: '没有找到符合搜索条件的文章',
     placeholder: '描述你想要查找的内容...',
     searchFailed: 'AI 搜索失败，请检查 AI 设置。',
+    semanticMode: '语义搜索：点击切换为关键词搜索',
     showingResults: '正在显示 AI 搜索结果',
   },
   common: {
@@ -350,6 +384,21 @@ const zh: TranslationMessages = {
       feedsSubscribedPartial: '部分订阅：{succeeded}/{total} 个订阅源',
       feedsSubscribedSuccess: '成功订阅 {count} 个订阅源',
       feedUpdatedSuccess: '订阅更新成功',
+      httpAuth: '身份验证',
+      httpAuthNone: '无',
+      httpAuthPassword: '密码',
+      httpAuthToken: '令牌',
+      httpAuthUsername: '用户名',
+      httpCookies: 'Cookies',
+      httpHeaders: '请求头（每行一个 "Name: value"）',
+      httpMethod: '请求方法',
+      httpOptions: 'HTTP 选项',
+      httpOptionsDesc: '用于付费或会员订阅源的请求头、Cookies 和登录信息',
+      httpOptionsHelp:
+        '用于订阅源、全文获取和同一站点的图片。请求头、Cookies 和密钥会加密保存，不会再次显示，也不会包含在 OPML 导出中。留空则保留已保存的值。',
+      httpOptionsSaveFailed: '保存 HTTP 选项失败',
+      httpUserAgent: 'User Agent',
+      httpUserAgentPlaceholder: '默认',
       imageModeSetSuccess: '已为选中的订阅源启用图片模式',
       imageModeUnsetSuccess: '已为选中的订阅源禁用图片模式',
       selectTagsToAdd: '选择要添加的标签：',
@@ -358,8 +407,42 @@ const zh: TranslationMessages = {
       tagsAddedSuccess: '标签添加成功',
       unsetImageModeMessage: '为 {count} 个选中的订阅源禁用图片模式？',
       unsetImageModeTitle: '取消图片模式',
+      urlChangeNewFeedURL: '声明了新的订阅地址',
+      urlChangeRedirect: '永久重定向',
+      urlChangeSelfLink: '自身链接已变更',
+      urlHistory: 'URL 历史',
+      urlMergedFrom: '由 {title} 合并',
+      jsonApi: 'JSON API + JSONPath',
+      jsonHeaders: '请求头',
+      jsonHeadersHelp: '每行一个 "Name: value"，例如 API 密钥。加密存储。',
+      jsonItem: '条目 JSONPath',
+      jsonItemAuthor: '作者路径',
+      jsonItemCategories: '分类路径',
+      jsonItemContent: '内容路径',
+      jsonItemHelp: '响应中条目的 JSONPath。字段路径相对于单个条目。',
+      jsonItemThumbnail: '缩略图路径',
+      jsonItemTimeFormat: '时间格式',
+      jsonItemTimestamp: '日期路径',
+      jsonItemTitle: '标题路径',
+      jsonItemUid: 'UID 路径',
+      jsonItemUri: '链接路径',
+      jsonMaxPages: '最大页数',
+      jsonNextPagePath: '下一页路径',
+      jsonPageParam: '分页参数',
+      jsonPaginationHelp:
+        '下一页路径选取一个 URL，或通过分页参数传递的游标。仅设置分页参数时，将其作为页码。',
+      jsonPreview: '预览条目',
+      jsonPreviewCount: '找到 {count} 个条目',
+      jsonPreviewing: '正在加载预览...',
+      jsonPreviewNoTitle: '（无标题）',
       manageFeeds: '管理订阅',
       noFeeds: '暂无订阅',
+      podcastAutoDownload: '下载新单集',
+      podcastAutoDownloadDesc: '每次刷新后自动下载最新的单集',
+      podcastKeepEpisodes: '保留的下载单集数',
+      podcastRetentionDays: '下载保留天数',
+      podcastRetentionDesc: '0 表示全部保留。优先删除较早的下载。',
+      podcastSettingsSaveFailed: '保存播客设置失败',
       proxy: '订阅代理',
       proxyDesc: '为此订阅配置代理设置',
       proxyHost: '代理主机',
@@ -375,17 +458,47 @@ const zh: TranslationMessages = {
       refreshModeDesc: '此订阅的刷新方式',
       refreshSettings: '订阅源刷新设置',
       rssUrl: 'RSS 链接',
+      scriptArgs: '参数',
+      scriptArgsHelp: '每行一个命令行参数',
+      scriptEnv: '环境变量',
+      scriptEnvHelp: '每行一个 "NAME=value"',
+      scriptNoRuns: '脚本尚未运行',
+      scriptRunDuration: '{ms} 毫秒',
+      scriptRunExitCode: '退出码 {code}',
+      scriptRunItems: '{count} 个条目',
+      scriptRuns: '最近运行',
+      scriptSecretAdd: '添加密钥',
+      scriptSecretKeep: '保持不变',
+      scriptSecrets: '密钥',
+      scriptSecretsHelp: '作为环境变量传递并加密存储。留空则保留已保存的值。',
+      scriptSettings: '脚本选项',
+      scriptStateHelp:
+        '脚本会从标准输入以及 MRRSS_STATE_FILE 指定的文件中获得上次运行的状态，覆盖该文件即可更新状态。',
+      scriptTimeout: '超时（秒）',
+      scriptTimeoutHelp: '0 表示默认的 30 秒，最多 600 秒',
       sourceUrl: '来源链接',
       sourceUrlPlaceholder: 'https://example.com/blog',
       syncFeed: '同步订阅',
       syncFeedStarted: '订阅同步已开始',
       titlePlaceholder: '自定义订阅标题',
+      transform: '条目转换',
+      transformDesc: '在保存前用 JavaScript 修改或丢弃条目',
+      transformDropped: '已丢弃',
+      transformHelp:
+        '定义 transform(item, feed)。修改 item 的字段（title、link、content、description、author、image、categories、published），返回新对象，或返回 false 丢弃该条目。queryParam(url, name) 可读取 URL 参数，测试时会显示 console.log 的输出。',
+      transformSaveFailed: '保存条目转换失败',
+      transformTest: '在当前条目上测试',
+      transformTestCount: '保留 {kept} 个，丢弃 {dropped} 个',
+      transformTesting: '测试中...',
       typeCustomScript: '自定义脚本',
       typeEmail: '邮件订阅',
+      typeJSON: 'JSON API',
+      typeMastodon: 'Mastodon',
       typeFreshRSS: 'FreshRSS 订阅',
       typeRegular: '常规订阅',
       typeRSSHub: 'RSSHub 订阅',
       typeXPath: 'XPath',
+      typeYouTube: 'YouTube',
       xpath: 'XPath 支持',
       xpathDocumentation: 'XPath 文档',
       xpathHtml: 'HTML + XPath',
@@ -456,11 +569,13 @@ const zh: TranslationMessages = {
       author: '作者',
       url: '链接',
       articleContent: '文章内容',
+      articleTags: '文章标签',
       hasSummary: '有摘要',
       hasTranslation: '有翻译',
       hasImage: '有图片',
       hasAudio: '有音频',
       hasVideo: '有视频',
+      isYouTubeShort: '是 YouTube 短视频',
       feedArticlesPerMonth: '订阅源每月文章数',
       feedLastUpdateStatus: '订阅源更新状态',
       updateSuccess: '成功',
@@ -552,6 +667,7 @@ const zh: TranslationMessages = {
       selectProfileForSummary: '选择用于生成摘要的 AI 配置',
       selectProfileForChat: '选择用于 AI 聊天的配置',
       selectProfileForSearch: '选择用于 AI 搜索的配置',
+      selectProfileForEmbedding: '选择用于计算向量嵌入的配置',
       model: '模型',
       aiTestFailed: 'AI 配置测试失败',
       aiUsage: 'AI 使用量',
@@ -567,6 +683,15 @@ const zh: TranslationMessages = {
       clearAllChatsDesc: '删除所有 AI 对话记录',
       clearAllChatsFailed: '清空对话记录失败',
       clearAllChatsSuccess: '对话记录已清空',
+      clearEmbeddings: '重建向量嵌入',
+      clearEmbeddingsButton: '重建',
+      clearEmbeddingsConfirm: '删除所有已存储的向量嵌入并重新计算？这会消耗 AI 令牌。',
+      clearEmbeddingsFailed: '清除向量嵌入失败',
+      embeddingEnabled: '语义搜索与相关文章',
+      embeddingEnabledDesc: '在后台计算文章的向量嵌入，用于按语义搜索和查找相关文章',
+      embeddingModel: '嵌入模型',
+      embeddingModelDesc: '用于计算向量嵌入的模型，会覆盖 AI 配置中的模型',
+      embeddingProgress: '已嵌入 {embedded} / {total} 篇文章',
       configValid: '配置有效',
       connectionSuccess: '连接状态',
       isBeta: '该功能仍处于测试阶段，可能存在问题或不稳定，请谨慎使用。',
@@ -579,6 +704,22 @@ const zh: TranslationMessages = {
       testing: '测试中...',
       tokens: 'Token',
     },
+    backup: {
+      export: '导出备份',
+      exportFailed: '导出备份失败',
+      import: '导入备份',
+      importFailed: '导入备份失败',
+      importSuccess: '备份已恢复：新增 {added} 个订阅，更新 {updated} 个',
+      includeSecrets: '包含密钥',
+      includeSecretsDesc: '在备份文件中保存密码、API 密钥、请求头和脚本密钥',
+      mode: '导入模式',
+      modeDesc: '如何处理已存在的订阅和设置',
+      modeMerge: '合并',
+      modeReplace: '替换',
+      replaceConfirm: '不在备份中的订阅、标签、已保存筛选器和 AI 配置将被删除。是否继续？',
+      title: '完整备份',
+      titleDesc: '备份订阅及其全部设置、标签、已保存筛选器、规则、AI 配置和收藏',
+    },
     content: {
       addHeader: '添加请求头',
       addLangMapping: '添加映射',
@@ -656,6 +797,8 @@ const zh: TranslationMessages = {
       translatingContent: '正在翻译内容...',
       translation: '翻译',
       translationCredentialsRequired: '翻译服务需要 API 密钥或凭据',
+      translationFullArticle: '整篇翻译',
+      translationFullArticleDesc: '在服务端分段翻译全文，并保存结果供下次阅读',
       translationOnlyMode: '仅翻译模式',
       translationOnlyModeDesc: '仅显示翻译后的文本，隐藏原文',
       translationProvider: '翻译服务',
@@ -690,6 +833,21 @@ const zh: TranslationMessages = {
         timeoutDesc: '请求超时时间（秒）',
         title: '自定义 API',
       },
+      fallback: {
+        budgetExhausted: '额度已用完',
+        budgets: '每月字符额度',
+        budgetsDesc: '每个提供商每月可翻译的字符数，按提供商以 JSON 填写。留空表示不限制',
+        notConfigured: '未配置',
+        providers: '备用提供商',
+        providersDesc:
+          '主提供商失败时按顺序尝试的提供商，用逗号分隔（google、deepl、baidu、ai、custom）',
+        refresh: '刷新',
+        stateClosed: '正常',
+        stateHalfOpen: '重试中',
+        stateOpen: '暂停至 {time}',
+        status: '提供商状态',
+        statusDesc: '链中每个提供商本月已翻译的字符数和健康状态',
+      },
     },
     customization: {
       css: '自定义文章 CSS',
@@ -763,6 +921,34 @@ const zh: TranslationMessages = {
       clearArticleContentCacheConfirm: '确定要清空所有文章内容缓存吗？此操作不可撤销。',
       clearMediaCacheConfirm: '确定要清空所有媒体缓存吗？此操作不可撤销。',
     },
+    digest: {
+      add: '添加摘要',
+      articleCount: '{count} 篇文章',
+      daily: '每天',
+      dailyAt: '每天 {time}',
+      delete: '删除摘要',
+      deleteConfirm: '删除摘要“{name}”及其历史记录？',
+      failed: '失败',
+      generate: '立即生成',
+      generateFailed: '生成摘要失败',
+      generated: '摘要已生成',
+      history: '历史记录',
+      lastHours: '最近 {hours} 小时',
+      manual: '仅手动',
+      name: '名称',
+      noHistory: '尚未生成摘要',
+      publishToFeed: '发布到摘要订阅源',
+      saveFailed: '保存摘要失败',
+      selectProfile: '用于撰写摘要的 AI 配置',
+      sourceCategory: '分类',
+      sourceFilter: '已保存的筛选器',
+      sourceTag: '标签',
+      title: 'AI 摘要',
+      titleDesc: '将已保存筛选器、分类或标签中的近期文章按主题汇总为简报，并附带原文链接',
+      weekly: '每周',
+      weeklyAt: '{day} {time}',
+      window: '小时',
+    },
     feed: {
       addFeed: '添加订阅',
       articleViewMode: '文章查看模式',
@@ -785,6 +971,33 @@ const zh: TranslationMessages = {
       useGlobalSettings: '使用全局设置',
       useIntelligentInterval: '智能间隔',
     },
+    feedHealth: {
+      allHealthy: '所有订阅源均刷新正常',
+      autoMigrate: '跟随迁移的订阅源',
+      autoMigrateDesc:
+        '订阅源永久重定向或声明同一站点的新地址时自动更新其 URL，若新地址已订阅则合并到该订阅源',
+      migrateOtherSites: '跟随其他站点的新地址',
+      migrateOtherSitesDesc:
+        '订阅源的 self 链接或 itunes:new-feed-url 指向其他站点时也迁移。设置了自定义 HTTP 选项的订阅源不会迁移到其他站点',
+      backoffFailures: '连续失败后退避',
+      backoffFailuresDesc: '订阅源连续刷新失败达到此次数后降低刷新频率，两次尝试之间最长间隔一天',
+      backoffUntil: '退避中，下次尝试于 {time}',
+      dead: '已失效',
+      deadDays: '标记为失效',
+      deadDaysDesc: '订阅源在此期间内没有发布新文章时标记为失效',
+      details: '成功率 {rate}%，中位耗时 {p50} 毫秒，P90 {p90} 毫秒',
+      failing: '失败中',
+      failingCount: '连续 {count} 次刷新失败',
+      healthy: '正常',
+      movedTo: '已永久迁移至 {url}',
+      noNewFor: '已 {count} 天没有新文章',
+      paused: '已暂停',
+      pausedCount: '连续 {count} 次刷新失败后已暂停',
+      pauseDays: '暂停刷新',
+      pauseDaysDesc: '订阅源持续失败达到此时长后停止刷新，手动刷新可恢复',
+      refresh: '刷新',
+      title: '订阅源健康',
+    },
     general: {
       application: '应用',
       auto: '自动（跟随系统）',
@@ -869,6 +1082,19 @@ const zh: TranslationMessages = {
       usernameDesc: 'FreshRSS 用户名',
       usernamePlaceholder: '输入用户名',
     },
+    podcast: {
+      concurrency: '同时下载数',
+      concurrencyDesc: '同时下载的单集数量',
+      deleteDownload: '删除下载',
+      deleteDownloadConfirm: '删除“{title}”的下载文件？',
+      downloads: '已下载的单集',
+      failed: '失败',
+      noDownloads: '没有已下载的单集',
+      storageQuota: '存储配额',
+      storageQuotaDesc: '下载单集可使用的最大空间，0 表示不限制。优先删除已播放和最早的下载。',
+      storageUsed: '已用空间',
+      title: '播客',
+    },
     plugins: {
       notion: {
         apiKey: 'API 密钥',
@@ -909,6 +1135,13 @@ const zh: TranslationMessages = {
         '作为渲染内容查看时，自动显示所有文章的完整内容（可能会增加加载时间）',
       defaultViewMode: '文章查看模式',
       defaultViewModeDesc: '选择文章应如何显示',
+      duplicateAction: '重复文章',
+      duplicateActionDesc: '其他订阅源已报道过的内容，之后到达的文章如何处理',
+      duplicateActionHide: '隐藏',
+      duplicateActionMarkRead: '标记为已读',
+      duplicateActionNone: '保留',
+      duplicateDetection: '合并重复报道',
+      duplicateDetectionDesc: '根据链接、标题和内容识别不同订阅源中报道同一内容的文章',
       hideAdvancedSettings: '隐藏高级设置',
       hideFromTimeline: '从时间线中隐藏',
       hideFromTimelineDesc: '在"全部文章"和"未读"视图中隐藏此订阅源的文章',
@@ -950,21 +1183,36 @@ const zh: TranslationMessages = {
       urlPlaceholder: 'RSS 路由（支持 rsshub:// 协议）',
     },
     rule: {
+      actionAddTag: '添加标签',
+      actionExport: '导出到',
       actionFavorite: '添加到收藏',
+      actionFetchContent: '获取全文',
       actionHide: '隐藏文章',
       actionMarkRead: '标记为已读',
       actionMarkUnread: '标记为未读',
+      actionMove: '将订阅源移动到分类',
       actionReadLater: '添加到稍后阅读',
       actionRemoveReadLater: '从稍后阅读中移除',
+      actionSummarize: 'AI 摘要',
+      actionTranslateTitle: '翻译标题',
       actionUnfavorite: '取消收藏',
       actionUnhide: '取消隐藏',
+      actionWebhook: '发送到 Webhook',
       addRule: '添加规则',
       applyRuleNow: '立即应用',
+      categoryPlaceholder: '分类，例如 科技/人工智能',
+      defaultProfile: '默认 AI 配置',
+      missingActionParams: '请填写每个操作的参数',
       noActionsSelected: '请至少选择一个操作',
       noRules: '暂无规则',
       noRulesHint: '创建规则以自动处理文章',
       removeAction: '删除操作',
       removeCondition: '删除',
+      selectTag: '选择标签',
+      summaryLong: '长',
+      summaryMedium: '中',
+      summaryShort: '短',
+      targetLanguagePlaceholder: '目标语言（默认使用设置中的语言）',
     },
     shortcut: {
       addFeedShortcut: '添加订阅',
@@ -1032,6 +1280,20 @@ const zh: TranslationMessages = {
       updateWillRestart: '应用程序将重启以安装更新',
       upToDate: '您正在使用最新版本',
     },
+    webhook: {
+      add: '添加 Webhook',
+      delete: '删除 Webhook',
+      deleteConfirm: '确定删除 Webhook "{name}" 吗？',
+      name: '名称',
+      saveFailed: '保存 Webhook 失败',
+      secret: '签名密钥（可选）',
+      test: '发送测试事件',
+      testFailed: '测试投递失败',
+      testSuccessful: '测试投递成功',
+      title: 'Webhooks',
+      titleDesc:
+        '在有新文章、文章被收藏或稍后阅读、订阅源开始失败时发送带签名的 HTTP 请求',
+    },
   },
   sidebar: {
     activity: {
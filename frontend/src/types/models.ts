@@ -27,6 +27,235 @@ export interface Article {
   author?: string; // Article author
   summary?: string; // Cached AI-generated summary
   freshrss_item_id?: string; // FreshRSS/Google Reader item ID
+  tags?: Tag[]; // Tags assigned to this article
+  story_id?: number; // Primary article of the story, if other feeds report the same story
+  story_siblings?: StorySibling[]; // Articles of other feeds about the same story
+}
+
+export interface StorySibling {
+  id: number;
+  feed_id: number;
+  feed_title: string;
+  title: string;
+  url: string;
+  is_primary: boolean; // First article of the story that was fetched
+  is_read: boolean;
+  published_at: string;
+}
+
+export interface Highlight {
+  id: number;
+  article_id: number;
+  quote: string;
+  prefix?: string; // Text just before the quote
+  suffix?: string; // Text just after the quote
+  start_offset: number; // Start of the quote in the article's plain text (-1 if unknown)
+  end_offset: number;
+  note?: string;
+  color?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface Webhook {
+  id: number;
+  name: string;
+  url: string;
+  has_secret: boolean;
+  events: string[]; // e.g. "articles.new", "article.starred"
+  conditions: string; // JSON string of saved-filter conditions
+  enabled: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface Digest {
+  id: number;
+  name: string;
+  source_type: 'filter' | 'category' | 'tag';
+  source_id: number; // Saved filter or tag ID
+  category: string;
+  window_hours: number;
+  schedule: 'manual' | 'daily' | 'weekly';
+  hour: number; // Local hour of day, 0-23
+  weekday: number; // 0 = Sunday, used by weekly digests
+  publish_to_feed: boolean;
+  enabled: boolean;
+  last_run_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface DigestCitation {
+  article_id: number;
+  title: string;
+  url: string;
+  feed_title: string;
+}
+
+export interface DigestRun {
+  id: number;
+  digest_id: number;
+  title: string;
+  content: string; // Markdown with [article_id] citations
+  citations: DigestCitation[];
+  article_count: number;
+  status: 'success' | 'failed';
+  error?: string;
+  article_id?: number; // Article in the Digests feed, if published
+  html?: string;
+  period_start: string;
+  period_end: string;
+  created_at: string;
+}
+
+export type PodcastDownloadStatus = '' | 'queued' | 'downloading' | 'done' | 'failed';
+
+export interface PodcastChapter {
+  start: number; // Seconds from the start of the episode
+  title: string;
+  url?: string;
+}
+
+export interface PodcastEpisode {
+  article_id: number;
+  feed_id: number;
+  enclosure_url: string;
+  enclosure_type: string;
+  enclosure_length: number;
+  duration: number; // Seconds, 0 if unknown
+  season?: number;
+  episode?: number;
+  episode_type?: string;
+  chapters?: PodcastChapter[];
+  chapters_url?: string;
+  transcript_url?: string;
+  transcript_type?: string;
+  position: number; // Playback position in seconds
+  played: boolean;
+  download_status?: PodcastDownloadStatus;
+  downloaded_bytes: number;
+  download_error?: string;
+  downloaded_at?: string;
+  title?: string;
+  feed_title?: string;
+  published_at: string;
+}
+
+export interface PodcastDownloads {
+  episodes: PodcastEpisode[];
+  used_bytes: number;
+  quota_bytes: number; // 0 if unlimited
+}
+
+export interface PodcastFeedSettings {
+  feed_id: number;
+  auto_download: boolean;
+  keep_episodes: number; // 0 = keep all
+  retention_days: number; // 0 = never delete
+}
+
+export interface ScriptFeedSettings {
+  feed_id: number;
+  args: string[];
+  env: Record<string, string>;
+  secrets: Record<string, string>; // Values are empty when loaded, empty values keep the saved ones
+  timeout_seconds: number; // 0 = 30 seconds
+}
+
+export interface ScriptRun {
+  id: number;
+  feed_id: number;
+  started_at: string;
+  duration_ms: number;
+  exit_code: number; // -1 if the script could not be started or was stopped
+  stderr: string;
+  error: string;
+  item_count: number;
+}
+
+export interface FeedHTTPOptions {
+  feed_id: number;
+  method: '' | 'GET' | 'POST';
+  user_agent: string;
+  headers: string; // One "Name: value" per line
+  cookies: string; // "name=value; name2=value2"
+  auth_type: '' | 'basic' | 'bearer';
+  auth_username: string;
+  auth_secret: string; // Basic auth password or Bearer token
+}
+
+export type FeedErrorClass = 'timeout' | 'network' | 'tls' | 'http' | 'parse' | 'other';
+
+export interface FeedFetchLog {
+  id: number;
+  feed_id: number;
+  fetched_at: string;
+  attempt: number; // 1 for the first attempt of a refresh, 2 for the retry
+  duration_ms: number;
+  http_status: number; // 0 without an HTTP response
+  bytes: number;
+  item_count: number;
+  new_articles: number;
+  error_class?: FeedErrorClass;
+  error?: string;
+  redirect_url?: string;
+}
+
+export type FeedHealthStatus = 'healthy' | 'failing' | 'backoff' | 'paused' | 'dead' | 'unknown';
+
+export interface FeedHealthReport {
+  feed_id: number;
+  title: string;
+  url: string;
+  status: FeedHealthStatus;
+  attempts: number;
+  success_rate: number; // 0 to 1
+  latency_p50_ms: number;
+  latency_p90_ms: number;
+  latency_p99_ms: number;
+  consecutive_failures: number;
+  failing_since?: string;
+  last_success_at?: string;
+  last_new_articles_at?: string;
+  days_without_new: number; // -1 if unknown
+  next_attempt_at?: string;
+  last_http_status: number;
+  last_error_class?: FeedErrorClass;
+  last_error?: string;
+  redirect_url?: string; // New URL reported by a permanent redirect
+}
+
+export type FeedURLChangeReason = 'redirect' | 'self_link' | 'new_feed_url';
+
+export interface FeedURLChange {
+  id: number;
+  feed_id: number;
+  old_url: string;
+  new_url: string;
+  reason: FeedURLChangeReason;
+  merged_title?: string; // Title of the feed that was merged into this one
+  changed_at: string;
+}
+
+export interface ItemTransform {
+  feed_id: number;
+  script: string; // Defines transform(item, feed)
+  enabled: boolean;
+}
+
+export interface ItemTransformTestFields {
+  title: string;
+  link: string;
+  author?: string;
+  published?: string;
+  categories?: string[];
+}
+
+export interface ItemTransformTestResult {
+  items: { before: ItemTransformTestFields; after: ItemTransformTestFields | null }[];
+  logs: string[] | null;
+  error?: string;
 }
 
 export interface Feed {
@@ -156,6 +385,7 @@ export interface FilterCondition {
     | 'feed_name'
     | 'feed_category'
     | 'feed_tags'
+    | 'article_tags'
     | 'article_title'
     | 'is_read'
     | 'is_favorite'
@@ -174,7 +404,14 @@ export type RuleAction =
   | { type: 'mark_read' }
   | { type: 'mark_unread' }
   | { type: 'read_later' }
-  | { type: 'remove_read_later' };
+  | { type: 'remove_read_later' }
+  | { type: 'add_tag'; params: { tag_id: string } }
+  | { type: 'move'; params: { category: string } }
+  | { type: 'fetch_content' }
+  | { type: 'summarize'; params?: { profile_id?: string; length?: 'short' | 'medium' | 'long' } }
+  | { type: 'translate_title'; params?: { target_language?: string } }
+  | { type: 'export'; params: { target: 'obsidian' | 'notion' } }
+  | { type: 'webhook'; params: { url: string; method?: 'POST' | 'PUT' } };
 
 export interface KeyboardShortcut {
   action: string;
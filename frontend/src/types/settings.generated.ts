@@ -9,6 +9,10 @@ export interface SettingsData {
   ai_chat_enabled: boolean;
   ai_chat_profile_id: string;
   ai_custom_headers: string;
+  ai_digest_profile_id: string;
+  ai_embedding_enabled: boolean;
+  ai_embedding_model: string;
+  ai_embedding_profile_id: string;
   ai_endpoint: string;
   ai_model: string;
   ai_search_enabled: boolean;
@@ -40,8 +44,15 @@ export interface SettingsData {
   deepl_api_key: string;
   deepl_endpoint: string;
   default_view_mode: string;
+  duplicate_action: string;
+  duplicate_detection_enabled: boolean;
   feed_drawer_expanded: boolean;
   feed_drawer_pinned: boolean;
+  feed_health_backoff_failures: number;
+  feed_health_dead_days: number;
+  feed_health_pause_days: number;
+  feed_url_auto_migrate: boolean;
+  feed_url_migrate_other_sites: boolean;
   freshrss_api_password: string;
   freshrss_auto_sync_interval: number;
   freshrss_enabled: boolean;
@@ -73,6 +84,8 @@ export interface SettingsData {
   obsidian_enabled: boolean;
   obsidian_vault: string;
   obsidian_vault_path: string;
+  podcast_download_concurrency: number;
+  podcast_storage_quota_mb: number;
   proxy_enabled: boolean;
   proxy_host: string;
   proxy_password: string;
@@ -97,6 +110,9 @@ export interface SettingsData {
   target_language: string;
   theme: string;
   translation_enabled: boolean;
+  translation_fallback_providers: string;
+  translation_full_article: boolean;
+  translation_monthly_char_budgets: string;
   translation_only_mode: boolean;
   translation_provider: string;
   update_interval: number;
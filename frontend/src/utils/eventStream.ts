@@ -0,0 +1,51 @@
+/**
+ * Server-Sent Events utilities for MrRSS
+ * Reads event streams from POST responses, which EventSource can't send
+ */
+
+/**
+ * Read a text/event-stream response, calling onEvent for each event with its parsed JSON data
+ * @param response - Fetch response with an event stream body
+ * @param onEvent - Called with the event name and data of each event
+ */
+export async function readEventStream(
+  response: Response,
+  onEvent: (event: string, data: any) => void
+): Promise<void> {
+  if (!response.body) return;
+
+  const reader = response.body.getReader();
+  const decoder = new TextDecoder();
+  let buffer = '';
+
+  const dispatch = (block: string) => {
+    let event = 'message';
+    const data: string[] = [];
+    for (const line of block.split('\n')) {
+      if (line.startsWith('event:')) {
+        event = line.slice(6).trim();
+      } else if (line.startsWith('data:')) {
+        data.push(line.slice(5).trimStart());
+      }
+    }
+    if (data.length > 0) {
+      onEvent(event, JSON.parse(data.join('\n')));
+    }
+  };
+
+  for (;;) {
+    const { done, value } = await reader.read();
+    if (done) break;
+    buffer += decoder.decode(value, { stream: true }).replace(/\r/g, '');
+
+    let end = buffer.indexOf('\n\n');
+    while (end !== -1) {
+      dispatch(buffer.slice(0, end));
+      buffer = buffer.slice(end + 2);
+      end = buffer.indexOf('\n\n');
+    }
+  }
+  if (buffer.trim()) {
+    dispatch(buffer);
+  }
+}
@@ -11,9 +11,15 @@ let mediaCachePromise: Promise<boolean> | null = null;
  * @param url Original media URL
  * @param referer Optional referer URL for anti-hotlinking and resolving relative URLs
  * @param forceCache Force caching even if globally disabled (e.g., for image mode feeds)
+ * @param feedId Optional feed ID, so that the HTTP options of the feed apply to its own site
  * @returns Proxied URL
  */
-export function getProxiedMediaUrl(url: string, referer?: string, forceCache?: boolean): string {
+export function getProxiedMediaUrl(
+  url: string,
+  referer?: string,
+  forceCache?: boolean,
+  feedId?: number
+): string {
   if (!url) return '';
 
   // Don't proxy data URLs or blob URLs
@@ -60,6 +66,10 @@ export function getProxiedMediaUrl(url: string, referer?: string, forceCache?: b
     proxyUrl += `&force_cache=true`;
   }
 
+  if (feedId) {
+    proxyUrl += `&feed_id=${feedId}`;
+  }
+
   return proxyUrl;
 }
 
@@ -111,10 +121,11 @@ export function clearMediaCacheEnabledCache(): void {
  * Process HTML content to proxy image URLs
  * @param html HTML content
  * @param referer Optional referer URL
+ * @param feedId Optional feed ID of the content
  * @returns HTML with proxied image URLs
  * @note Unquoted src attributes are supported but must not contain spaces (per HTML spec)
  */
-export function proxyImagesInHtml(html: string, referer?: string): string {
+export function proxyImagesInHtml(html: string, referer?: string, feedId?: number): string {
   if (!html) return html;
 
   // First, convert lazy-loaded images to normal images
@@ -122,7 +133,7 @@ export function proxyImagesInHtml(html: string, referer?: string): string {
   let processed = convertLazyImages(html);
 
   // Then proxy the src attributes
-  processed = proxyImgAttribute(processed, 'src', referer);
+  processed = proxyImgAttribute(processed, 'src', referer, feedId);
 
   return processed;
 }
@@ -188,9 +199,15 @@ function convertLazyImages(html: string): string {
  * @param html HTML content
  * @param attrName Attribute name to proxy (e.g., 'src', 'data-original', 'data-src')
  * @param referer Optional referer URL
+ * @param feedId Optional feed ID of the content
  * @returns HTML with proxied attribute
  */
-function proxyImgAttribute(html: string, attrName: string, referer?: string): string {
+function proxyImgAttribute(
+  html: string,
+  attrName: string,
+  referer?: string,
+  feedId?: number
+): string {
   // Enhanced regex to handle img attributes with better pattern matching
   // Handles double quotes, single quotes, and unquoted values
   // Note: Unquoted values cannot contain spaces per HTML specification
@@ -201,7 +218,7 @@ function proxyImgAttribute(html: string, attrName: string, referer?: string): st
     // HTML attributes contain &amp; which should be decoded to & before URL encoding
     // For example: &amp; becomes &, then gets properly URL-encoded as %26
     const decodedSrc = decodeHTMLEntities(src);
-    const proxiedUrl = getProxiedMediaUrl(decodedSrc, referer);
+    const proxiedUrl = getProxiedMediaUrl(decodedSrc, referer, false, feedId);
 
     // If proxying failed or returned the same URL, keep original
     if (!proxiedUrl || proxiedUrl === decodedSrc) {
@@ -4,6 +4,7 @@ package ai
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 )
 
@@ -166,6 +167,62 @@ func (h *AnthropicHandler) ParseResponse(body []byte) (ResponseResult, error) {
 	return result, nil
 }
 
+// BuildStreamRequest constructs a streaming request body for Anthropic Claude API
+func (h *AnthropicHandler) BuildStreamRequest(config RequestConfig) (map[string]interface{}, error) {
+	request, err := h.BuildRequest(config)
+	if err != nil {
+		return nil, err
+	}
+	request["stream"] = true
+	return request, nil
+}
+
+// ParseStream parses the Server-Sent Events of an Anthropic streamed response
+func (h *AnthropicHandler) ParseStream(body io.Reader, onDelta StreamCallback) (ResponseResult, error) {
+	acc := newStreamAccumulator(onDelta)
+
+	err := readSSE(body, func(event, data string) error {
+		var chunk struct {
+			Type  string `json:"type"`
+			Delta struct {
+				Type     string `json:"type"`
+				Text     string `json:"text"`
+				Thinking string `json:"thinking"`
+			} `json:"delta"`
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse Anthropic stream event: %w", err)
+		}
+
+		switch chunk.Type {
+		case "error":
+			return fmt.Errorf("anthropic API error (%s): %s", chunk.Error.Type, chunk.Error.Message)
+		case "message_stop":
+			return io.EOF
+		case "content_block_delta":
+			switch chunk.Delta.Type {
+			case "text_delta":
+				return acc.add(StreamDelta{Content: chunk.Delta.Text})
+			case "thinking_delta":
+				return acc.add(StreamDelta{Thinking: chunk.Delta.Thinking})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ResponseResult{}, err
+	}
+
+	if !acc.emitted {
+		return ResponseResult{}, fmt.Errorf("no content in response")
+	}
+	return acc.result(FormatTypeAnthropic), nil
+}
+
 // ValidateResponse checks if the response is valid
 func (h *AnthropicHandler) ValidateResponse(statusCode int, body []byte) error {
 	var response struct {
@@ -3,6 +3,7 @@ package ai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -84,62 +85,78 @@ func (c *Client) RequestWithMessages(messages []map[string]string) (ResponseResu
 
 // RequestWithConfig makes an AI request with full configuration
 func (c *Client) RequestWithConfig(config RequestConfig) (ResponseResult, error) {
-	provider := DetectAPIProvider(c.config.Endpoint)
-
-	// Try provider-specific format first based on endpoint detection
-	switch provider {
-	case "gemini":
-		result, err := c.tryFormat(NewGeminiHandler(), config)
+	for _, handler := range formatHandlers(DetectAPIProvider(c.config.Endpoint)) {
+		result, err := c.tryFormat(handler, config)
 		if err == nil {
 			return result, nil
 		}
-		// Fall through to other formats
+	}
 
-	case "anthropic":
-		result, err := c.tryFormat(&AnthropicHandler{}, config)
-		if err == nil {
-			return result, nil
-		}
-		// Fall through to other formats
+	// All formats failed
+	return ResponseResult{}, fmt.Errorf("all API formats failed")
+}
 
-	case "deepseek":
-		result, err := c.tryFormat(&DeepSeekHandler{}, config)
+// RequestWithMessagesStream makes a streamed AI request using messages format
+func (c *Client) RequestWithMessagesStream(ctx context.Context, messages []map[string]string, onDelta StreamCallback) (ResponseResult, error) {
+	config := RequestConfig{
+		Model:       c.config.Model,
+		Messages:    messages,
+		Temperature: 0.3,
+		MaxTokens:   2048,
+	}
+
+	return c.RequestStream(ctx, config, onDelta)
+}
+
+// RequestStream makes a streamed AI request, calling onDelta for each piece of content
+// or thinking as it arrives, and returns the complete response. Formats are tried in the
+// same order as RequestWithConfig, but only until the first delta has been delivered.
+// Thinking inlined in <think> tags is delivered as thinking.
+func (c *Client) RequestStream(ctx context.Context, config RequestConfig, onDelta StreamCallback) (ResponseResult, error) {
+	var lastErr error
+	for _, handler := range formatHandlers(DetectAPIProvider(c.config.Endpoint)) {
+		result, emitted, err := c.tryStream(ctx, handler, config, onDelta)
 		if err == nil {
 			return result, nil
 		}
-		// Fall through to other formats
+		// Once output was delivered or the caller gave up, another format can't take over
+		if emitted || ctx.Err() != nil {
+			return ResponseResult{}, err
+		}
+		lastErr = err
+	}
+
+	return ResponseResult{}, fmt.Errorf("all API formats failed: %w", lastErr)
+}
+
+// formatHandlers returns the format handlers to try for a provider, in order
+func formatHandlers(provider string) []FormatHandler {
+	var handlers []FormatHandler
 
+	// Try provider-specific format first based on endpoint detection
+	switch provider {
+	case "gemini":
+		handlers = append(handlers, NewGeminiHandler())
+	case "anthropic":
+		handlers = append(handlers, &AnthropicHandler{})
+	case "deepseek":
+		handlers = append(handlers, &DeepSeekHandler{})
 	case "ollama":
-		result, err := c.tryFormat(NewOllamaHandler(), config)
-		if err == nil {
-			return result, nil
-		}
-		// Fall through to other formats
+		handlers = append(handlers, NewOllamaHandler())
 	}
 
 	// Try OpenAI format (most common, good fallback)
-	result, err := c.tryFormat(NewOpenAIHandler(), config)
-	if err == nil {
-		return result, nil
-	}
+	handlers = append(handlers, NewOpenAIHandler())
 
 	// Try other formats as fallback
 	if provider != "gemini" {
-		result, err = c.tryFormat(NewGeminiHandler(), config)
-		if err == nil {
-			return result, nil
-		}
+		handlers = append(handlers, NewGeminiHandler())
 	}
-
 	if provider != "ollama" {
-		result, err = c.tryFormat(NewOllamaHandler(), config)
-		if err == nil {
-			return result, nil
-		}
+		handlers = append(handlers, NewOllamaHandler())
 	}
 
-	// All formats failed
-	return ResponseResult{}, fmt.Errorf("all API formats failed")
+	return handlers
 }
 
 // tryFormat attempts to make a request using a specific format handler
@@ -165,7 +182,7 @@ func (c *Client) tryFormat(handler FormatHandler, config RequestConfig) (Respons
 	}
 
 	// Send request with formatted endpoint and handler
-	resp, err := c.sendRequestToEndpointWithHandler(jsonBody, formattedEndpoint, handler)
+	resp, err := c.sendRequestToEndpointWithHandler(context.Background(), jsonBody, formattedEndpoint, handler)
 	if err != nil {
 		return ResponseResult{}, fmt.Errorf("request failed: %w", err)
 	}
@@ -189,8 +206,61 @@ func (c *Client) tryFormat(handler FormatHandler, config RequestConfig) (Respons
 	return result, nil
 }
 
+// tryStream attempts a streamed request using a specific format handler.
+// It also reports whether any delta was delivered before a failure.
+func (c *Client) tryStream(ctx context.Context, handler FormatHandler, config RequestConfig, onDelta StreamCallback) (ResponseResult, bool, error) {
+	requestBody, err := handler.BuildStreamRequest(config)
+	if err != nil {
+		return ResponseResult{}, false, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return ResponseResult{}, false, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	formattedEndpoint := handler.FormatEndpoint(c.config.Endpoint, c.config.Model)
+	switch handler.(type) {
+	case *OllamaHandler:
+		// Replace /api/generate with /api/chat for message-based requests
+		if len(config.Messages) > 0 {
+			formattedEndpoint = strings.Replace(formattedEndpoint, "/api/generate", "/api/chat", 1)
+		}
+	case *GeminiHandler:
+		formattedEndpoint = FormatGeminiStreamEndpoint(formattedEndpoint)
+	}
+
+	resp, err := c.sendRequestToEndpointWithHandler(ctx, jsonBody, formattedEndpoint, handler)
+	if err != nil {
+		return ResponseResult{}, false, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		if err := handler.ValidateResponse(resp.StatusCode, bodyBytes); err != nil {
+			return ResponseResult{}, false, err
+		}
+		return ResponseResult{}, false, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	acc := newStreamAccumulator(onDelta)
+	splitter := &thinkTagSplitter{}
+	parsed, err := handler.ParseStream(resp.Body, func(delta StreamDelta) error {
+		return acc.add(splitter.split(delta))
+	})
+	if err != nil {
+		return ResponseResult{}, acc.emitted, fmt.Errorf("failed to parse stream: %w", err)
+	}
+	if err := acc.add(splitter.flush()); err != nil {
+		return ResponseResult{}, true, err
+	}
+
+	return acc.result(parsed.FormatUsed), true, nil
+}
+
 // sendRequestToEndpointWithHandler sends the HTTP request to a specific endpoint with handler-specific headers
-func (c *Client) sendRequestToEndpointWithHandler(jsonBody []byte, apiURL string, handler FormatHandler) (*http.Response, error) {
+func (c *Client) sendRequestToEndpointWithHandler(ctx context.Context, jsonBody []byte, apiURL string, handler FormatHandler) (*http.Response, error) {
 	// Validate endpoint URL to prevent SSRF attacks
 	parsedURL, err := url.Parse(apiURL)
 	if err != nil {
@@ -214,7 +284,7 @@ func (c *Client) sendRequestToEndpointWithHandler(jsonBody []byte, apiURL string
 		apiURL = parsedURL.String()
 	}
 
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -4,6 +4,7 @@ package ai
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 )
 
@@ -90,7 +91,7 @@ func (h *DeepSeekHandler) BuildRequest(config RequestConfig) (map[string]interfa
 		request["response_format"] = config.ResponseFormat
 	}
 
-	// Stream (always false for now)
+	// Streaming is enabled by BuildStreamRequest
 	request["stream"] = false
 
 	return request, nil
@@ -152,6 +153,29 @@ func (h *DeepSeekHandler) ParseResponse(body []byte) (ResponseResult, error) {
 	return result, nil
 }
 
+// BuildStreamRequest constructs a streaming request body for DeepSeek API
+func (h *DeepSeekHandler) BuildStreamRequest(config RequestConfig) (map[string]interface{}, error) {
+	request, err := h.BuildRequest(config)
+	if err != nil {
+		return nil, err
+	}
+	request["stream"] = true
+	return request, nil
+}
+
+// ParseStream parses a DeepSeek streamed response
+// The chunks are OpenAI-compatible, with the reasoning of deepseek-reasoner in reasoning_content
+func (h *DeepSeekHandler) ParseStream(body io.Reader, onDelta StreamCallback) (ResponseResult, error) {
+	acc := newStreamAccumulator(onDelta)
+	if err := parseOpenAIStream(body, acc); err != nil {
+		return ResponseResult{}, err
+	}
+	if !acc.emitted {
+		return ResponseResult{}, fmt.Errorf("empty content in DeepSeek stream")
+	}
+	return acc.result(FormatTypeDeepSeek), nil
+}
+
 // ValidateResponse checks if the response is valid
 func (h *DeepSeekHandler) ValidateResponse(statusCode int, body []byte) error {
 	var response struct {
@@ -0,0 +1,139 @@
+// Package ai provides embeddings requests for OpenAI-compatible and Ollama APIs
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// EmbeddingEndpoint derives the embeddings endpoint from a profile endpoint.
+// OpenAI-compatible APIs serve /embeddings next to /chat/completions, while Ollama
+// serves /api/embeddings next to /api/generate and /api/chat.
+// Endpoints that already point at an embeddings API are returned as-is.
+func EmbeddingEndpoint(endpoint string) string {
+	endpoint = strings.TrimSuffix(strings.TrimSpace(endpoint), "/")
+	lower := strings.ToLower(endpoint)
+
+	switch {
+	case strings.HasSuffix(lower, "/embeddings"), strings.HasSuffix(lower, "/api/embed"):
+		return endpoint
+	case strings.HasSuffix(lower, "/api/generate"), strings.HasSuffix(lower, "/api/chat"):
+		return endpoint[:strings.LastIndex(lower, "/api/")] + "/api/embeddings"
+	case strings.HasSuffix(lower, "/chat/completions"):
+		return endpoint[:len(endpoint)-len("/chat/completions")] + "/embeddings"
+	case strings.HasSuffix(lower, "/completions"):
+		return endpoint[:len(endpoint)-len("/completions")] + "/embeddings"
+	case DetectAPIProvider(endpoint) == "ollama" && !strings.Contains(lower, "/v1"):
+		return endpoint + "/api/embeddings"
+	}
+	return endpoint + "/embeddings"
+}
+
+// Embed computes an embedding vector for each text, in order.
+// Ollama's legacy /api/embeddings endpoint accepts one text per request, so texts are sent one by one there.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	endpoint := EmbeddingEndpoint(c.config.Endpoint)
+	lower := strings.ToLower(endpoint)
+
+	switch {
+	case strings.HasSuffix(lower, "/api/embeddings"):
+		vectors := make([][]float32, len(texts))
+		for i, text := range texts {
+			var resp struct {
+				Embedding []float32 `json:"embedding"`
+			}
+			request := map[string]interface{}{"model": c.config.Model, "prompt": text}
+			if err := c.postEmbeddings(ctx, endpoint, request, &resp); err != nil {
+				return nil, err
+			}
+			vectors[i] = resp.Embedding
+		}
+		return checkEmbeddings(vectors)
+
+	case strings.HasSuffix(lower, "/api/embed"):
+		var resp struct {
+			Embeddings [][]float32 `json:"embeddings"`
+		}
+		request := map[string]interface{}{"model": c.config.Model, "input": texts}
+		if err := c.postEmbeddings(ctx, endpoint, request, &resp); err != nil {
+			return nil, err
+		}
+		if len(resp.Embeddings) != len(texts) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(resp.Embeddings))
+		}
+		return checkEmbeddings(resp.Embeddings)
+	}
+
+	// OpenAI-compatible format
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	request := map[string]interface{}{"model": c.config.Model, "input": texts}
+	if err := c.postEmbeddings(ctx, endpoint, request, &resp); err != nil {
+		return nil, err
+	}
+	vectors := make([][]float32, len(texts))
+	for _, item := range resp.Data {
+		if item.Index >= 0 && item.Index < len(vectors) {
+			vectors[item.Index] = item.Embedding
+		}
+	}
+	return checkEmbeddings(vectors)
+}
+
+// postEmbeddings sends an embeddings request and decodes the JSON response into out
+func (c *Client) postEmbeddings(ctx context.Context, endpoint string, request map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.sendRequestToEndpointWithHandler(ctx, body, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("embeddings API returned status %d: %s", resp.StatusCode, truncateBody(respBody, 300))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// checkEmbeddings rejects responses with missing vectors or vectors of different sizes
+func checkEmbeddings(vectors [][]float32) ([][]float32, error) {
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("embeddings response is missing vector %d", i)
+		}
+		if len(v) != len(vectors[0]) {
+			return nil, fmt.Errorf("embeddings response has vectors of different sizes")
+		}
+	}
+	return vectors, nil
+}
+
+func truncateBody(body []byte, n int) string {
+	if len(body) <= n {
+		return string(body)
+	}
+	return string(body[:n]) + "..."
+}
@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEmbeddingEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"https://api.openai.com/v1/chat/completions":   "https://api.openai.com/v1/embeddings",
+		"https://api.example.com/v1/":                  "https://api.example.com/v1/embeddings",
+		"https://api.example.com/v1/embeddings":        "https://api.example.com/v1/embeddings",
+		"http://localhost:11434/api/generate":          "http://localhost:11434/api/embeddings",
+		"http://localhost:11434/api/chat":              "http://localhost:11434/api/embeddings",
+		"http://localhost:11434":                       "http://localhost:11434/api/embeddings",
+		"http://localhost:11434/v1/chat/completions":   "http://localhost:11434/v1/embeddings",
+		"http://localhost:11434/api/embed":             "http://localhost:11434/api/embed",
+		"https://api.deepseek.com/v1/text/completions": "https://api.deepseek.com/v1/text/embeddings",
+	}
+	for endpoint, want := range tests {
+		if got := EmbeddingEndpoint(endpoint); got != want {
+			t.Errorf("EmbeddingEndpoint(%q) = %q, want %q", endpoint, got, want)
+		}
+	}
+}
+
+func TestClientEmbed(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+
+		switch r.URL.Path {
+		case "/v1/embeddings":
+			// Items may come back in any order
+			w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+		case "/api/embeddings":
+			w.Write([]byte(`{"embedding":[0.5,0.5]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{Endpoint: server.URL + "/v1/chat/completions", Model: "text-embedding-3-small"})
+	vectors, err := client.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("Unexpected vectors: %v", vectors)
+	}
+	if len(requests) != 1 || requests[0]["model"] != "text-embedding-3-small" {
+		t.Errorf("Expected one batched request, got %v", requests)
+	}
+
+	requests = nil
+	client = NewClient(ClientConfig{Endpoint: server.URL + "/api/generate", Model: "nomic-embed-text"})
+	vectors, err = client.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 2 || len(requests) != 2 || requests[1]["prompt"] != "b" {
+		t.Errorf("Expected one Ollama request per text, got %v", requests)
+	}
+
+	client = NewClient(ClientConfig{Endpoint: server.URL + "/missing/chat/completions", Model: "m"})
+	if _, err := client.Embed(context.Background(), []string{"a"}); err == nil {
+		t.Error("Expected an error for a failed request")
+	}
+}
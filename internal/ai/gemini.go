@@ -4,6 +4,7 @@ package ai
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 )
@@ -184,6 +185,75 @@ func (h *GeminiHandler) ParseResponse(body []byte) (ResponseResult, error) {
 	}, nil
 }
 
+// BuildStreamRequest builds a Gemini API request for the streamGenerateContent method
+// Gemini selects streaming through the endpoint rather than the body
+func (h *GeminiHandler) BuildStreamRequest(config RequestConfig) (map[string]interface{}, error) {
+	return h.BuildRequest(config)
+}
+
+// ParseStream parses the Server-Sent Events of a Gemini streamed response
+// Parts flagged as thought carry the model's thinking
+func (h *GeminiHandler) ParseStream(body io.Reader, onDelta StreamCallback) (ResponseResult, error) {
+	acc := newStreamAccumulator(onDelta)
+
+	err := readSSE(body, func(event, data string) error {
+		var chunk struct {
+			Candidates []struct {
+				Content struct {
+					Parts []struct {
+						Text    string `json:"text"`
+						Thought bool   `json:"thought"`
+					} `json:"parts"`
+				} `json:"content"`
+				FinishReason string `json:"finishReason"`
+			} `json:"candidates"`
+			PromptFeedback struct {
+				BlockReason string `json:"blockReason,omitempty"`
+			} `json:"promptFeedback"`
+			Error struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to decode Gemini stream chunk: %w", err)
+		}
+		if chunk.Error.Code != 0 {
+			return fmt.Errorf("gemini API error (code %d): %s", chunk.Error.Code, chunk.Error.Message)
+		}
+		if chunk.PromptFeedback.BlockReason != "" {
+			return fmt.Errorf("prompt blocked: %s", chunk.PromptFeedback.BlockReason)
+		}
+		if len(chunk.Candidates) == 0 {
+			return nil
+		}
+
+		candidate := chunk.Candidates[0]
+		switch candidate.FinishReason {
+		case "SAFETY", "RECITATION", "IMAGE_SAFETY":
+			return fmt.Errorf("response blocked for %s reasons", strings.ToLower(strings.ReplaceAll(candidate.FinishReason, "_", " ")))
+		}
+		for _, part := range candidate.Content.Parts {
+			delta := StreamDelta{Content: part.Text}
+			if part.Thought {
+				delta = StreamDelta{Thinking: part.Text}
+			}
+			if err := acc.add(delta); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ResponseResult{}, err
+	}
+
+	if !acc.emitted {
+		return ResponseResult{}, fmt.Errorf("no candidates in Gemini response")
+	}
+	return acc.result(FormatTypeGemini), nil
+}
+
 // ValidateResponse validates the HTTP response status
 func (h *GeminiHandler) ValidateResponse(statusCode int, body []byte) error {
 	switch statusCode {
@@ -280,3 +350,15 @@ func FormatGeminiEndpoint(baseEndpoint, model string) string {
 	// Use endpoint as-is (user should provide full API path)
 	return strings.TrimSuffix(baseEndpoint, "/")
 }
+
+// FormatGeminiStreamEndpoint turns a generateContent endpoint into its streaming equivalent
+func FormatGeminiStreamEndpoint(endpoint string) string {
+	endpoint = strings.Replace(endpoint, ":generateContent", ":streamGenerateContent", 1)
+	if strings.Contains(endpoint, "alt=sse") {
+		return endpoint
+	}
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&alt=sse"
+	}
+	return endpoint + "?alt=sse"
+}
@@ -4,6 +4,7 @@ package ai
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 )
@@ -149,6 +150,66 @@ func (h *OllamaHandler) ParseResponse(body []byte) (ResponseResult, error) {
 	}, nil
 }
 
+// BuildStreamRequest builds an Ollama API request with streaming enabled
+func (h *OllamaHandler) BuildStreamRequest(config RequestConfig) (map[string]interface{}, error) {
+	request, err := h.BuildRequest(config)
+	if err != nil {
+		return nil, err
+	}
+	request["stream"] = true
+	return request, nil
+}
+
+// ParseStream parses an Ollama streamed response
+// Ollama streams newline-delimited JSON objects in the /api/chat or /api/generate format
+func (h *OllamaHandler) ParseStream(body io.Reader, onDelta StreamCallback) (ResponseResult, error) {
+	acc := newStreamAccumulator(onDelta)
+	done := false
+
+	err := readNDJSON(body, func(line []byte) error {
+		var chunk struct {
+			Message struct {
+				Content  string `json:"content"`
+				Thinking string `json:"thinking"`
+			} `json:"message"`
+			Response string `json:"response"`
+			Thinking string `json:"thinking"`
+			Done     bool   `json:"done"`
+			Error    string `json:"error,omitempty"`
+		}
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("failed to decode Ollama stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("ollama API error: %s", chunk.Error)
+		}
+
+		delta := StreamDelta{
+			Content:  chunk.Message.Content + chunk.Response,
+			Thinking: chunk.Message.Thinking + chunk.Thinking,
+		}
+		if err := acc.add(delta); err != nil {
+			return err
+		}
+		if chunk.Done {
+			done = true
+			return io.EOF
+		}
+		return nil
+	})
+	if err != nil {
+		return ResponseResult{}, err
+	}
+
+	if !done {
+		return ResponseResult{}, fmt.Errorf("ollama response incomplete (done=false)")
+	}
+	if !acc.emitted {
+		return ResponseResult{}, fmt.Errorf("empty response from ollama")
+	}
+	return acc.result(FormatTypeOllama), nil
+}
+
 // ValidateResponse validates the HTTP response status
 func (h *OllamaHandler) ValidateResponse(statusCode int, body []byte) error {
 	switch statusCode {
@@ -4,6 +4,7 @@ package ai
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 )
@@ -127,6 +128,68 @@ func (h *OpenAIHandler) ParseResponse(body []byte) (ResponseResult, error) {
 	}, nil
 }
 
+// BuildStreamRequest builds an OpenAI-compatible API request with streaming enabled
+func (h *OpenAIHandler) BuildStreamRequest(config RequestConfig) (map[string]interface{}, error) {
+	request, err := h.BuildRequest(config)
+	if err != nil {
+		return nil, err
+	}
+	request["stream"] = true
+	return request, nil
+}
+
+// ParseStream parses the Server-Sent Events of an OpenAI-compatible streamed response
+func (h *OpenAIHandler) ParseStream(body io.Reader, onDelta StreamCallback) (ResponseResult, error) {
+	acc := newStreamAccumulator(onDelta)
+	if err := parseOpenAIStream(body, acc); err != nil {
+		return ResponseResult{}, err
+	}
+	if !acc.emitted {
+		return ResponseResult{}, fmt.Errorf("empty content in OpenAI stream")
+	}
+	return acc.result(FormatTypeOpenAI), nil
+}
+
+// parseOpenAIStream reads chat completion chunks, shared by the OpenAI-compatible formats.
+// Reasoning models report their thinking as reasoning_content or reasoning.
+func parseOpenAIStream(body io.Reader, acc *streamAccumulator) error {
+	return readSSE(body, func(event, data string) error {
+		if data == "[DONE]" {
+			return io.EOF
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content          string `json:"content"`
+					ReasoningContent string `json:"reasoning_content"`
+					Reasoning        string `json:"reasoning"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Error *struct {
+				Message string `json:"message"`
+				Type    string `json:"type"`
+			} `json:"error,omitempty"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("API error: %s (type: %s)", chunk.Error.Message, chunk.Error.Type)
+		}
+		if len(chunk.Choices) == 0 {
+			return nil
+		}
+
+		delta := chunk.Choices[0].Delta
+		thinking := delta.ReasoningContent
+		if thinking == "" {
+			thinking = delta.Reasoning
+		}
+		return acc.add(StreamDelta{Content: delta.Content, Thinking: thinking})
+	})
+}
+
 // ValidateResponse validates the HTTP response status
 func (h *OpenAIHandler) ValidateResponse(statusCode int, body []byte) error {
 	switch statusCode {
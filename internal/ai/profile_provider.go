@@ -32,6 +32,8 @@ const (
 	FeatureSummary     FeatureType = "summary"
 	FeatureChat        FeatureType = "chat"
 	FeatureSearch      FeatureType = "search"
+	FeatureEmbedding   FeatureType = "embedding"
+	FeatureDigest      FeatureType = "digest"
 )
 
 // GetProfileForFeature returns the AI profile configured for a specific feature
@@ -67,6 +69,10 @@ func (p *ProfileProvider) getSettingKeyForFeature(feature FeatureType) string {
 		return "ai_chat_profile_id"
 	case FeatureSearch:
 		return "ai_search_profile_id"
+	case FeatureEmbedding:
+		return "ai_embedding_profile_id"
+	case FeatureDigest:
+		return "ai_digest_profile_id"
 	default:
 		return ""
 	}
@@ -0,0 +1,213 @@
+// Package ai provides streaming support shared by the API format handlers
+package ai
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// maxStreamLineSize bounds the size of a single SSE or NDJSON line
+const maxStreamLineSize = 1024 * 1024
+
+// StreamDelta is an incremental piece of a streamed response
+type StreamDelta struct {
+	Content  string `json:"content,omitempty"`
+	Thinking string `json:"thinking,omitempty"`
+}
+
+// StreamCallback receives the deltas of a streamed response as they arrive.
+// Returning an error aborts the stream.
+type StreamCallback func(delta StreamDelta) error
+
+// streamAccumulator forwards deltas to a callback while collecting the full response
+type streamAccumulator struct {
+	onDelta  StreamCallback
+	content  strings.Builder
+	thinking strings.Builder
+	emitted  bool
+}
+
+func newStreamAccumulator(onDelta StreamCallback) *streamAccumulator {
+	return &streamAccumulator{onDelta: onDelta}
+}
+
+// add records a delta and forwards it when it is not empty
+func (a *streamAccumulator) add(delta StreamDelta) error {
+	if delta.Content == "" && delta.Thinking == "" {
+		return nil
+	}
+	a.content.WriteString(delta.Content)
+	a.thinking.WriteString(delta.Thinking)
+	a.emitted = true
+	if a.onDelta == nil {
+		return nil
+	}
+	return a.onDelta(delta)
+}
+
+// result returns the collected response
+func (a *streamAccumulator) result(format FormatType) ResponseResult {
+	return ResponseResult{
+		Content:    strings.TrimSpace(a.content.String()),
+		Thinking:   strings.TrimSpace(a.thinking.String()),
+		FormatUsed: format,
+	}
+}
+
+// readSSE calls fn with the event name and data of each Server-Sent Event in body.
+// Reading stops without error at the end of the body or when fn returns io.EOF.
+func readSSE(body io.Reader, fn func(event, data string) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLineSize)
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		err := fn(event, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		var err error
+		switch {
+		case line == "":
+			err = dispatch()
+		case strings.HasPrefix(line, ":"):
+			// Comment, used by some servers as keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := dispatch(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// readNDJSON calls fn with each non-empty line of a newline-delimited JSON body.
+// Reading stops without error at the end of the body or when fn returns io.EOF.
+func readNDJSON(body io.Reader, fn func(line []byte) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLineSize)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// thinkTagSplitter moves content wrapped in <think> or <thinking> tags into the
+// thinking part of streamed deltas, for models that inline their reasoning.
+// Tags split across deltas are held back until they can be recognized.
+type thinkTagSplitter struct {
+	inThink bool
+	pending string
+}
+
+var (
+	thinkOpenTags  = []string{"<thinking>", "<think>"}
+	thinkCloseTags = []string{"</thinking>", "</think>"}
+)
+
+// split processes the content of a delta and returns the delta to emit
+func (s *thinkTagSplitter) split(delta StreamDelta) StreamDelta {
+	if delta.Content == "" {
+		return delta
+	}
+
+	text := s.pending + delta.Content
+	s.pending = ""
+	out := StreamDelta{Thinking: delta.Thinking}
+
+	for text != "" {
+		tags := thinkOpenTags
+		if s.inThink {
+			tags = thinkCloseTags
+		}
+
+		index, tagLen := findTag(text, tags)
+		if index == -1 {
+			// Hold back a trailing fragment that may be the start of a tag
+			keep := partialTagSuffix(text, tags)
+			s.emit(&out, text[:len(text)-keep])
+			s.pending = text[len(text)-keep:]
+			break
+		}
+
+		s.emit(&out, text[:index])
+		s.inThink = !s.inThink
+		text = text[index+tagLen:]
+	}
+
+	return out
+}
+
+// flush returns any content held back at the end of the stream
+func (s *thinkTagSplitter) flush() StreamDelta {
+	var out StreamDelta
+	s.emit(&out, s.pending)
+	s.pending = ""
+	return out
+}
+
+func (s *thinkTagSplitter) emit(out *StreamDelta, text string) {
+	if s.inThink {
+		out.Thinking += text
+	} else {
+		out.Content += text
+	}
+}
+
+// findTag returns the position and length of the first of the tags in text (case-insensitive)
+func findTag(text string, tags []string) (int, int) {
+	lower := strings.ToLower(text)
+	index, length := -1, 0
+	for _, tag := range tags {
+		if i := strings.Index(lower, tag); i != -1 && (index == -1 || i < index) {
+			index, length = i, len(tag)
+		}
+	}
+	return index, length
+}
+
+// partialTagSuffix returns the length of the longest suffix of text that is a prefix of one of the tags
+func partialTagSuffix(text string, tags []string) int {
+	start := strings.LastIndex(text, "<")
+	if start == -1 {
+		return 0
+	}
+	suffix := strings.ToLower(text[start:])
+	for _, tag := range tags {
+		if len(suffix) < len(tag) && strings.HasPrefix(tag, suffix) {
+			return len(text) - start
+		}
+	}
+	return 0
+}
@@ -0,0 +1,186 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// streamServer serves a canned streamed response and records the request
+func streamServer(t *testing.T, body string, gotPath *string, gotRequest *map[string]interface{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if gotPath != nil {
+			*gotPath = r.URL.Path + "?" + r.URL.RawQuery
+		}
+		if gotRequest != nil {
+			json.NewDecoder(r.Body).Decode(gotRequest)
+		}
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// collect streams a request and returns the deltas joined by "|"
+func collect(t *testing.T, client *Client) (string, string, ResponseResult) {
+	t.Helper()
+	var content, thinking []string
+	result, err := client.RequestWithMessagesStream(context.Background(),
+		[]map[string]string{{"role": "user", "content": "Hi"}},
+		func(delta StreamDelta) error {
+			if delta.Content != "" {
+				content = append(content, delta.Content)
+			}
+			if delta.Thinking != "" {
+				thinking = append(thinking, delta.Thinking)
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("RequestWithMessagesStream failed: %v", err)
+	}
+	return strings.Join(content, "|"), strings.Join(thinking, "|"), result
+}
+
+func TestRequestStream_OpenAI(t *testing.T) {
+	var request map[string]interface{}
+	server := streamServer(t, `data: {"choices":[{"delta":{"role":"assistant"}}]}
+
+data: {"choices":[{"delta":{"reasoning_content":"Hmm"}}]}
+
+data: {"choices":[{"delta":{"content":"Hello"}}]}
+
+data: {"choices":[{"delta":{"content":" world"}}]}
+
+data: [DONE]
+
+`, nil, &request)
+
+	// The local endpoint is detected as Ollama, which fails on the SSE body before falling back
+	content, thinking, result := collect(t, NewClient(ClientConfig{Endpoint: server.URL + "/v1/chat/completions", Model: "gpt"}))
+	if request["stream"] != true {
+		t.Errorf("Expected stream to be requested, got %v", request["stream"])
+	}
+	if content != "Hello| world" || thinking != "Hmm" {
+		t.Errorf("Unexpected deltas: %q / %q", content, thinking)
+	}
+	if result.Content != "Hello world" || result.Thinking != "Hmm" || result.FormatUsed != FormatTypeOpenAI {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestRequestStream_InlineThinkTags(t *testing.T) {
+	server := streamServer(t, `data: {"choices":[{"delta":{"content":"<thi"}}]}
+
+data: {"choices":[{"delta":{"content":"nk>Let me see</th"}}]}
+
+data: {"choices":[{"delta":{"content":"ink>Answer"}}]}
+
+data: [DONE]
+
+`, nil, nil)
+
+	_, _, result := collect(t, NewClient(ClientConfig{Endpoint: server.URL + "/v1/chat/completions", Model: "r1"}))
+	if result.Content != "Answer" || result.Thinking != "Let me see" {
+		t.Errorf("Expected the think block to be moved to thinking, got %+v", result)
+	}
+}
+
+func TestRequestStream_Ollama(t *testing.T) {
+	var path string
+	server := streamServer(t, `{"message":{"role":"assistant","content":"","thinking":"Plan"},"done":false}
+{"message":{"role":"assistant","content":"Hi"},"done":false}
+{"message":{"role":"assistant","content":" there"},"done":false}
+{"message":{"role":"assistant","content":""},"done":true}
+`, &path, nil)
+
+	// httptest listens on 127.0.0.1, which is detected as Ollama
+	content, thinking, result := collect(t, NewClient(ClientConfig{Endpoint: server.URL + "/api/generate", Model: "llama"}))
+	if !strings.HasPrefix(path, "/api/chat") {
+		t.Errorf("Expected the chat endpoint for messages, got %s", path)
+	}
+	if content != "Hi| there" || thinking != "Plan" || result.FormatUsed != FormatTypeOllama {
+		t.Errorf("Unexpected stream: %q / %q / %+v", content, thinking, result)
+	}
+}
+
+func TestParseStream_Anthropic(t *testing.T) {
+	body := `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Consider"}}
+
+event: ping
+data: {"type": "ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Done"}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+	result, err := (&AnthropicHandler{}).ParseStream(strings.NewReader(body), nil)
+	if err != nil {
+		t.Fatalf("ParseStream failed: %v", err)
+	}
+	if result.Content != "Done" || result.Thinking != "Consider" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	_, err = (&AnthropicHandler{}).ParseStream(strings.NewReader(`event: error
+data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}
+
+`), nil)
+	if err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Errorf("Expected the stream error to be reported, got %v", err)
+	}
+}
+
+func TestParseStream_Gemini(t *testing.T) {
+	body := `data: {"candidates":[{"content":{"parts":[{"text":"Reasoning","thought":true}],"role":"model"}}]}
+
+data: {"candidates":[{"content":{"parts":[{"text":"Bonjour"}],"role":"model"}}]}
+
+data: {"candidates":[{"content":{"parts":[{"text":"!"}],"role":"model"},"finishReason":"STOP"}]}
+
+`
+	result, err := NewGeminiHandler().ParseStream(strings.NewReader(body), nil)
+	if err != nil {
+		t.Fatalf("ParseStream failed: %v", err)
+	}
+	if result.Content != "Bonjour!" || result.Thinking != "Reasoning" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	endpoint := FormatGeminiStreamEndpoint("https://generativelanguage.googleapis.com/v1beta/models/gemini:generateContent")
+	if endpoint != "https://generativelanguage.googleapis.com/v1beta/models/gemini:streamGenerateContent?alt=sse" {
+		t.Errorf("Unexpected stream endpoint: %s", endpoint)
+	}
+}
+
+func TestRequestStream_NoFallbackAfterOutput(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Partial\"}}]}\n\ndata: {broken\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{Endpoint: server.URL + "/v1/chat/completions", Model: "gpt"})
+	_, err := client.RequestStream(context.Background(), RequestConfig{Model: "gpt", UserPrompt: "Hi"}, nil)
+	if err == nil {
+		t.Fatal("Expected the broken stream to fail")
+	}
+	// Ollama is tried first for local endpoints and fails on the SSE body before any output,
+	// then OpenAI delivers output and must not be followed by another format
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
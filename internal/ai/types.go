@@ -3,6 +3,7 @@ package ai
 
 import (
 	"encoding/json"
+	"io"
 	"strings"
 )
 
@@ -51,6 +52,13 @@ type FormatHandler interface {
 	// ParseResponse parses the response body for this format
 	ParseResponse(body []byte) (ResponseResult, error)
 
+	// BuildStreamRequest builds the request body asking for a streamed response
+	BuildStreamRequest(config RequestConfig) (map[string]interface{}, error)
+
+	// ParseStream reads a streamed response body, calling onDelta for each increment,
+	// and returns the complete response
+	ParseStream(body io.Reader, onDelta StreamCallback) (ResponseResult, error)
+
 	// FormatEndpoint formats the endpoint URL if needed (can return as-is)
 	FormatEndpoint(endpoint, model string) string
 
@@ -112,6 +112,21 @@ func (t *UsageTracker) IsLimitReached() bool {
 	return usage >= limit
 }
 
+// HasBudgetFor checks if a request of the given number of tokens fits within the usage limit.
+func (t *UsageTracker) HasBudgetFor(tokens int64) bool {
+	limit, err := t.GetUsageLimit()
+	if err != nil || limit == 0 {
+		return true
+	}
+
+	usage, err := t.GetCurrentUsage()
+	if err != nil {
+		return true
+	}
+
+	return usage+tokens <= limit
+}
+
 // AddUsage adds tokens to the usage counter.
 func (t *UsageTracker) AddUsage(tokens int64) error {
 	t.mu.Lock()
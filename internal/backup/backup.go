@@ -0,0 +1,741 @@
+// Package backup creates and restores full backups of an instance: the local feeds with all
+// their settings, tags, saved filters, rules, AI profiles and the favorite, read-later, tag
+// and podcast playback state of articles. Backups are versioned JSON documents that can be restored on
+// another machine, either merged into the existing configuration or replacing it.
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/feed/httpoptions"
+	"MrRSS/internal/models"
+	"MrRSS/internal/version"
+)
+
+const (
+	// Format identifies MrRSS backup documents
+	Format = "mrrss-backup"
+	// Version is the current backup format version
+	Version = 1
+)
+
+// Restore modes
+const (
+	// ModeMerge adds what is missing and keeps the existing configuration on conflicts
+	ModeMerge = "merge"
+	// ModeReplace makes the configuration match the backup, overwriting conflicts and
+	// removing feeds, tags, saved filters and AI profiles that are not in the backup
+	ModeReplace = "replace"
+)
+
+// defaultTagColor is used for tags that only appear by name in the backup
+const defaultTagColor = "#3B82F6"
+
+// profileSettings are the settings that select an AI profile per feature
+var profileSettings = []string{
+	"ai_translation_profile_id",
+	"ai_summary_profile_id",
+	"ai_chat_profile_id",
+	"ai_search_profile_id",
+}
+
+// Backup is a full backup document.
+// The feeds key matches the jsonimport format, so a backup can also be imported as a feed list.
+type Backup struct {
+	Format          string                `json:"format"`
+	Version         int                   `json:"version"`
+	AppVersion      string                `json:"app_version,omitempty"`
+	CreatedAt       time.Time             `json:"created_at"`
+	IncludesSecrets bool                  `json:"includes_secrets"`
+	Feeds           []models.Feed         `json:"feeds"`
+	Tags            []models.Tag          `json:"tags"`
+	SavedFilters    []models.SavedFilter  `json:"saved_filters"`
+	Rules           json.RawMessage       `json:"rules,omitempty"`
+	AIProfiles      []models.AIProfile    `json:"ai_profiles"`
+	ProfileSettings map[string]int64      `json:"profile_settings,omitempty"` // AI profile ID per feature setting
+	ArticleStates   []models.ArticleState `json:"article_states"`
+}
+
+// Result summarizes a restore
+type Result struct {
+	Mode          string  `json:"mode"`
+	FeedsAdded    int     `json:"feeds_added"`
+	FeedsUpdated  int     `json:"feeds_updated"`
+	FeedsRemoved  int     `json:"feeds_removed"`
+	Tags          int     `json:"tags"`
+	SavedFilters  int     `json:"saved_filters"`
+	Rules         int     `json:"rules"`
+	AIProfiles    int     `json:"ai_profiles"`
+	ArticleStates int     `json:"article_states"`
+	NewFeedIDs    []int64 `json:"-"` // Feeds to fetch after the restore
+}
+
+// Create builds a backup of the local feeds and the configuration.
+// Email passwords, AI API keys, script secrets and the secrets of feed HTTP options and JSON
+// feed headers are only included with includeSecrets; otherwise only their names are kept.
+func Create(db *database.DB, includeSecrets bool) (*Backup, error) {
+	b := &Backup{
+		Format:          Format,
+		Version:         Version,
+		AppVersion:      version.Version,
+		CreatedAt:       time.Now().UTC(),
+		IncludesSecrets: includeSecrets,
+		Feeds:           []models.Feed{},
+		ProfileSettings: map[string]int64{},
+	}
+
+	feeds, err := db.GetFeeds()
+	if err != nil {
+		return nil, fmt.Errorf("get feeds: %w", err)
+	}
+	for _, f := range feeds {
+		if f.IsFreshRSSSource {
+			// Synced from FreshRSS, restored by syncing again
+			continue
+		}
+		if f.Tags, err = db.GetFeedTags(f.ID); err != nil {
+			return nil, fmt.Errorf("get tags of feed %d: %w", f.ID, err)
+		}
+		if !includeSecrets {
+			f.EmailPassword = ""
+		}
+		options, err := db.GetFeedHTTPOptions(f.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get http options of feed %d: %w", f.ID, err)
+		}
+		if !httpoptions.IsEmpty(options) {
+			if includeSecrets {
+				options.FeedID = 0
+				f.HTTPOptions = options
+			} else {
+				f.HTTPOptions = httpoptions.Metadata(options)
+			}
+		}
+		jsonOptions, err := db.GetJSONFeedOptions(f.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get json options of feed %d: %w", f.ID, err)
+		}
+		if *jsonOptions != (models.JSONFeedOptions{FeedID: f.ID}) {
+			jsonOptions.FeedID = 0
+			if !includeSecrets {
+				jsonOptions.Headers = headerNamesOnly(jsonOptions.Headers)
+			}
+			f.JSONOptions = jsonOptions
+		}
+		if f.ScriptPath != "" {
+			script, err := db.GetScriptFeedSettings(f.ID)
+			if err != nil {
+				return nil, fmt.Errorf("get script settings of feed %d: %w", f.ID, err)
+			}
+			script.FeedID = 0
+			if !includeSecrets {
+				for name := range script.Secrets {
+					script.Secrets[name] = ""
+				}
+			}
+			f.ScriptSettings = script
+			state, err := db.GetScriptState(f.ID)
+			if err != nil {
+				return nil, fmt.Errorf("get script state of feed %d: %w", f.ID, err)
+			}
+			f.ScriptState = string(state)
+		}
+		transform, err := db.GetItemTransform(f.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get item transform of feed %d: %w", f.ID, err)
+		}
+		if transform.Script != "" {
+			transform.FeedID = 0
+			f.ItemTransform = transform
+		}
+		podcast, err := db.GetPodcastFeedSettings(f.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get podcast settings of feed %d: %w", f.ID, err)
+		}
+		if podcast.AutoDownload || podcast.KeepEpisodes > 0 || podcast.RetentionDays > 0 {
+			podcast.FeedID = 0
+			f.PodcastSettings = podcast
+		}
+		// Runtime state, rebuilt by fetching
+		f.ID = 0
+		f.LastUpdated = time.Time{}
+		f.LastError = ""
+		f.LatestArticleTime = nil
+		f.ArticlesPerMonth = 0
+		f.LastUpdateStatus = ""
+		b.Feeds = append(b.Feeds, f)
+	}
+
+	if b.Tags, err = db.GetTags(); err != nil {
+		return nil, fmt.Errorf("get tags: %w", err)
+	}
+	if b.SavedFilters, err = db.GetSavedFilters(); err != nil {
+		return nil, fmt.Errorf("get saved filters: %w", err)
+	}
+
+	rules, err := db.GetSetting("rules")
+	if err != nil {
+		return nil, fmt.Errorf("get rules: %w", err)
+	}
+	if json.Valid([]byte(rules)) {
+		b.Rules = json.RawMessage(rules)
+	}
+
+	if b.AIProfiles, err = db.GetAllAIProfiles(); err != nil {
+		return nil, fmt.Errorf("get ai profiles: %w", err)
+	}
+	if !includeSecrets {
+		for i := range b.AIProfiles {
+			b.AIProfiles[i].APIKey = ""
+		}
+	}
+	for _, key := range profileSettings {
+		value, _ := db.GetSetting(key)
+		if id, err := strconv.ParseInt(value, 10, 64); err == nil && id > 0 {
+			b.ProfileSettings[key] = id
+		}
+	}
+
+	if b.ArticleStates, err = db.GetArticleStates(); err != nil {
+		return nil, fmt.Errorf("get article states: %w", err)
+	}
+
+	return b, nil
+}
+
+// Parse reads and validates a backup document
+func Parse(r io.Reader) (*Backup, error) {
+	var b Backup
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("invalid backup: %w", err)
+	}
+	if b.Format != Format {
+		return nil, errors.New("not a MrRSS backup")
+	}
+	if b.Version < 1 || b.Version > Version {
+		return nil, fmt.Errorf("unsupported backup version %d", b.Version)
+	}
+	return &b, nil
+}
+
+// Restore applies a backup with the given mode.
+// Secrets missing from the backup never clear the secrets of existing feeds and profiles.
+func Restore(db *database.DB, b *Backup, mode string) (*Result, error) {
+	if mode != ModeMerge && mode != ModeReplace {
+		return nil, fmt.Errorf("invalid restore mode %q", mode)
+	}
+	r := &restorer{db: db, backup: b, replace: mode == ModeReplace, result: &Result{Mode: mode}}
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"tags", r.restoreTags},
+		{"ai profiles", r.restoreAIProfiles},
+		{"feeds", r.restoreFeeds},
+		{"saved filters", r.restoreSavedFilters},
+		{"rules", r.restoreRules},
+		{"article states", r.restoreArticleStates},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			return r.result, fmt.Errorf("restore %s: %w", step.name, err)
+		}
+	}
+	return r.result, nil
+}
+
+// restorer holds the state of a restore, mapping the IDs in the backup to local IDs
+type restorer struct {
+	db      *database.DB
+	backup  *Backup
+	replace bool
+	result  *Result
+
+	tagIDs     map[int64]int64  // backup tag ID -> local tag ID
+	tagsByName map[string]int64 // lowercased tag name -> local tag ID
+	profileIDs map[int64]int64  // backup profile ID -> local profile ID
+}
+
+func (r *restorer) restoreTags() error {
+	existing, err := r.db.GetTags()
+	if err != nil {
+		return err
+	}
+	r.tagIDs = map[int64]int64{}
+	r.tagsByName = map[string]int64{}
+	for _, tag := range existing {
+		r.tagsByName[strings.ToLower(tag.Name)] = tag.ID
+	}
+
+	keep := map[int64]bool{}
+	for _, tag := range r.backup.Tags {
+		id, found := r.tagsByName[strings.ToLower(tag.Name)]
+		switch {
+		case !found:
+			if id, err = r.db.AddTag(&models.Tag{Name: tag.Name, Color: tag.Color, Position: tag.Position}); err != nil {
+				return err
+			}
+			r.tagsByName[strings.ToLower(tag.Name)] = id
+			r.result.Tags++
+		case r.replace:
+			if err := r.db.UpdateTag(id, tag.Name, tag.Color, tag.Position); err != nil {
+				return err
+			}
+			r.result.Tags++
+		}
+		r.tagIDs[tag.ID] = id
+		keep[id] = true
+	}
+
+	if r.replace {
+		for _, tag := range existing {
+			if !keep[tag.ID] {
+				if err := r.db.DeleteTag(tag.ID); err != nil {
+					return err
+				}
+				delete(r.tagsByName, strings.ToLower(tag.Name))
+			}
+		}
+	}
+	return nil
+}
+
+// tagIDsByName resolves tag names to local tag IDs, creating missing tags
+func (r *restorer) tagIDsByName(tags []models.Tag) ([]int64, error) {
+	var ids []int64
+	for _, tag := range tags {
+		id, ok := r.tagsByName[strings.ToLower(tag.Name)]
+		if !ok {
+			color := tag.Color
+			if color == "" {
+				color = defaultTagColor
+			}
+			var err error
+			if id, err = r.db.AddTag(&models.Tag{Name: tag.Name, Color: color}); err != nil {
+				return nil, err
+			}
+			r.tagsByName[strings.ToLower(tag.Name)] = id
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (r *restorer) restoreAIProfiles() error {
+	existing, err := r.db.GetAllAIProfiles()
+	if err != nil {
+		return err
+	}
+	byName := map[string]models.AIProfile{}
+	for _, p := range existing {
+		byName[p.Name] = p
+	}
+
+	r.profileIDs = map[int64]int64{}
+	keep := map[int64]bool{}
+	for _, p := range r.backup.AIProfiles {
+		local, found := byName[p.Name]
+		switch {
+		case !found:
+			backupID := p.ID
+			if p.ID, err = r.db.CreateAIProfile(&p); err != nil {
+				return err
+			}
+			r.profileIDs[backupID] = p.ID
+			r.result.AIProfiles++
+		case r.replace:
+			if p.APIKey == "" {
+				p.APIKey = local.APIKey
+			}
+			r.profileIDs[p.ID] = local.ID
+			p.ID = local.ID
+			if err := r.db.UpdateAIProfile(&p); err != nil {
+				return err
+			}
+			r.result.AIProfiles++
+		default:
+			r.profileIDs[p.ID] = local.ID
+		}
+		keep[r.profileIDs[p.ID]] = true
+	}
+
+	if r.replace {
+		for _, p := range existing {
+			if !keep[p.ID] {
+				if err := r.db.DeleteAIProfile(p.ID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, key := range profileSettings {
+		id, ok := r.profileIDs[r.backup.ProfileSettings[key]]
+		if !ok {
+			if r.replace {
+				// The feature falls back to the default profile
+				if err := r.db.SetSetting(key, ""); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if current, _ := r.db.GetSetting(key); current != "" && !r.replace {
+			continue
+		}
+		if err := r.db.SetSetting(key, strconv.FormatInt(id, 10)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *restorer) restoreFeeds() error {
+	feeds, err := r.db.GetFeeds()
+	if err != nil {
+		return err
+	}
+	existing := map[string]models.Feed{}
+	for _, f := range feeds {
+		if !f.IsFreshRSSSource {
+			existing[f.URL] = f
+		}
+	}
+
+	inBackup := map[string]bool{}
+	for _, f := range r.backup.Feeds {
+		if f.URL == "" || f.IsFreshRSSSource {
+			continue
+		}
+		inBackup[f.URL] = true
+
+		tagIDs, err := r.tagIDsByName(f.Tags)
+		if err != nil {
+			return err
+		}
+
+		local, found := existing[f.URL]
+		if found && !r.replace {
+			// Keep the local settings, only add the tags from the backup
+			current, err := r.db.GetFeedTags(local.ID)
+			if err != nil {
+				return err
+			}
+			for _, tag := range current {
+				tagIDs = append(tagIDs, tag.ID)
+			}
+			if err := r.db.SetFeedTags(local.ID, uniqueIDs(tagIDs)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !r.replace {
+			// Append to the local category order
+			f.Position = 0
+		}
+		if found && f.EmailPassword == "" {
+			f.EmailPassword = local.EmailPassword
+		}
+		if found && f.EmailLastUID < local.EmailLastUID {
+			f.EmailLastUID = local.EmailLastUID
+		}
+		id, err := r.db.AddFeed(&f)
+		if err != nil {
+			return fmt.Errorf("feed %s: %w", f.URL, err)
+		}
+		if err := r.db.SetFeedTags(id, uniqueIDs(tagIDs)); err != nil {
+			return err
+		}
+		if err := r.restoreHTTPOptions(id, f.HTTPOptions); err != nil {
+			return fmt.Errorf("feed %s: %w", f.URL, err)
+		}
+		if err := r.restoreJSONOptions(id, f.JSONOptions); err != nil {
+			return fmt.Errorf("feed %s: %w", f.URL, err)
+		}
+		if err := r.restoreScriptSettings(id, &f); err != nil {
+			return fmt.Errorf("feed %s: %w", f.URL, err)
+		}
+		if f.ItemTransform != nil {
+			transform := *f.ItemTransform
+			transform.FeedID = id
+			if err := r.db.SaveItemTransform(&transform); err != nil {
+				return fmt.Errorf("feed %s: %w", f.URL, err)
+			}
+		}
+		if f.PodcastSettings != nil {
+			settings := *f.PodcastSettings
+			settings.FeedID = id
+			if err := r.db.SavePodcastFeedSettings(&settings); err != nil {
+				return fmt.Errorf("feed %s: %w", f.URL, err)
+			}
+		}
+		if found {
+			r.result.FeedsUpdated++
+		} else {
+			r.result.FeedsAdded++
+			r.result.NewFeedIDs = append(r.result.NewFeedIDs, id)
+		}
+	}
+
+	if r.replace {
+		for url, f := range existing {
+			if !inBackup[url] {
+				if err := r.db.DeleteFeed(f.ID); err != nil {
+					return err
+				}
+				r.result.FeedsRemoved++
+			}
+		}
+	}
+	return nil
+}
+
+// restoreHTTPOptions saves the HTTP options of a restored feed. Secrets missing from
+// the backup are kept from the existing options of the feed.
+func (r *restorer) restoreHTTPOptions(feedID int64, options *models.FeedHTTPOptions) error {
+	if options == nil {
+		return nil
+	}
+	if err := httpoptions.Validate(options); err != nil {
+		return err
+	}
+	stored, err := r.db.GetFeedHTTPOptions(feedID)
+	if err != nil {
+		return err
+	}
+	httpoptions.MergeSecrets(options, stored)
+	options.FeedID = feedID
+	return r.db.SaveFeedHTTPOptions(options)
+}
+
+// restoreJSONOptions saves the options of a restored JSON API feed. Header values missing
+// from the backup are kept from the existing options of the feed.
+func (r *restorer) restoreJSONOptions(feedID int64, options *models.JSONFeedOptions) error {
+	if options == nil {
+		return nil
+	}
+	stored, err := r.db.GetJSONFeedOptions(feedID)
+	if err != nil {
+		return err
+	}
+	headers := &models.FeedHTTPOptions{Headers: options.Headers}
+	httpoptions.MergeSecrets(headers, &models.FeedHTTPOptions{Headers: stored.Headers})
+	options.Headers = headers.Headers
+	options.FeedID = feedID
+	return r.db.SaveJSONFeedOptions(options)
+}
+
+// restoreScriptSettings saves the run options and state of a restored script feed. Secret
+// values missing from the backup are kept from the existing settings of the feed, and a
+// local state is kept as it is newer than the one in the backup.
+func (r *restorer) restoreScriptSettings(feedID int64, f *models.Feed) error {
+	if f.ScriptSettings == nil {
+		return nil
+	}
+	stored, err := r.db.GetScriptFeedSettings(feedID)
+	if err != nil {
+		return err
+	}
+	settings := *f.ScriptSettings
+	for name, value := range settings.Secrets {
+		if value == "" {
+			settings.Secrets[name] = stored.Secrets[name]
+		}
+	}
+	settings.FeedID = feedID
+	if err := r.db.SaveScriptFeedSettings(&settings); err != nil {
+		return err
+	}
+
+	if f.ScriptState == "" {
+		return nil
+	}
+	state, err := r.db.GetScriptState(feedID)
+	if err != nil || state != nil {
+		return err
+	}
+	return r.db.SaveScriptState(feedID, []byte(f.ScriptState))
+}
+
+func (r *restorer) restoreSavedFilters() error {
+	existing, err := r.db.GetSavedFilters()
+	if err != nil {
+		return err
+	}
+	byName := map[string]models.SavedFilter{}
+	for _, f := range existing {
+		byName[f.Name] = f
+	}
+
+	keep := map[int64]bool{}
+	for _, f := range r.backup.SavedFilters {
+		local, found := byName[f.Name]
+		switch {
+		case !found:
+			filter := models.SavedFilter{Name: f.Name, Conditions: f.Conditions}
+			if r.replace {
+				filter.Position = f.Position
+			}
+			id, err := r.db.AddSavedFilter(&filter)
+			if err != nil {
+				return err
+			}
+			keep[id] = true
+			r.result.SavedFilters++
+		case r.replace:
+			local.Conditions = f.Conditions
+			if err := r.db.UpdateSavedFilter(&local); err != nil {
+				return err
+			}
+			keep[local.ID] = true
+			r.result.SavedFilters++
+		default:
+			keep[local.ID] = true
+		}
+	}
+
+	if r.replace {
+		for _, f := range existing {
+			if !keep[f.ID] {
+				if err := r.db.DeleteSavedFilter(f.ID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// restoreRules restores the rules, pointing the tag and AI profile parameters of their
+// actions to the local IDs. Rules are kept as generic JSON so that no field is lost.
+func (r *restorer) restoreRules() error {
+	if len(r.backup.Rules) == 0 {
+		return nil
+	}
+	var rules []map[string]interface{}
+	if err := json.Unmarshal(r.backup.Rules, &rules); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		actions, _ := rule["actions"].([]interface{})
+		for _, a := range actions {
+			action, _ := a.(map[string]interface{})
+			params, _ := action["params"].(map[string]interface{})
+			remapParam(params, "tag_id", r.tagIDs)
+			remapParam(params, "profile_id", r.profileIDs)
+		}
+	}
+
+	if !r.replace {
+		var existing []map[string]interface{}
+		if current, _ := r.db.GetSetting("rules"); current != "" {
+			if err := json.Unmarshal([]byte(current), &existing); err != nil {
+				return fmt.Errorf("invalid existing rules: %w", err)
+			}
+		}
+		names := map[interface{}]bool{}
+		for _, rule := range existing {
+			names[rule["name"]] = true
+		}
+		added := 0
+		for _, rule := range rules {
+			if !names[rule["name"]] {
+				rule["position"] = len(existing)
+				existing = append(existing, rule)
+				added++
+			}
+		}
+		rules = existing
+		r.result.Rules = added
+	} else {
+		r.result.Rules = len(rules)
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	return r.db.SetSetting("rules", string(data))
+}
+
+// remapParam replaces an ID parameter with its local ID
+func remapParam(params map[string]interface{}, key string, ids map[int64]int64) {
+	value, ok := params[key].(string)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return
+	}
+	if local, ok := ids[id]; ok {
+		params[key] = strconv.FormatInt(local, 10)
+	}
+}
+
+func (r *restorer) restoreArticleStates() error {
+	if r.replace {
+		if err := r.db.ClearArticleStates(); err != nil {
+			return err
+		}
+	}
+
+	feeds, err := r.db.GetFeeds()
+	if err != nil {
+		return err
+	}
+	feedIDs := map[string]int64{}
+	for _, f := range feeds {
+		if !f.IsFreshRSSSource {
+			feedIDs[f.URL] = f.ID
+		}
+	}
+
+	for _, state := range r.backup.ArticleStates {
+		feedID, ok := feedIDs[state.FeedURL]
+		if !ok {
+			continue
+		}
+		id, err := r.db.RestoreArticleState(feedID, &state, r.replace)
+		if err != nil {
+			return err
+		}
+		for _, name := range state.Tags {
+			tagIDs, err := r.tagIDsByName([]models.Tag{{Name: name}})
+			if err != nil {
+				return err
+			}
+			if err := r.db.AddArticleTag(id, tagIDs[0]); err != nil {
+				return err
+			}
+		}
+		r.result.ArticleStates++
+	}
+	return nil
+}
+
+// headerNamesOnly returns headers given as one "Name: value" per line with empty values
+func headerNamesOnly(headers string) string {
+	return httpoptions.FromNames(httpoptions.HeaderNames(&models.FeedHTTPOptions{Headers: headers}), nil).Headers
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	seen := map[int64]bool{}
+	var result []int64
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
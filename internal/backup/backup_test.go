@@ -0,0 +1,463 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.DB.Close() })
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	return db
+}
+
+// seedSource fills a database with a configuration to back up
+func seedSource(t *testing.T, db *database.DB) {
+	t.Helper()
+
+	tagID, _ := db.AddTag(&models.Tag{Name: "Tech", Color: "#FF0000"})
+	feedID, err := db.AddFeed(&models.Feed{
+		Title:           "Scraped",
+		URL:             "https://example.com/news",
+		Category:        "News",
+		Type:            "HTML+XPath",
+		XPathItem:       "//article",
+		XPathItemTitle:  ".//h2",
+		RefreshInterval: 30,
+		ProxyEnabled:    true,
+		ProxyURL:        "http://proxy:8080",
+		ArticleViewMode: "rendered",
+		EmailPassword:   "imap-secret",
+	})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	db.SetFeedTags(feedID, []int64{tagID})
+
+	db.SaveArticles(context.Background(), []*models.Article{
+		{FeedID: feedID, Title: "Kept", URL: "https://example.com/news/1", PublishedAt: time.Now()},
+		{FeedID: feedID, Title: "Plain", URL: "https://example.com/news/2", PublishedAt: time.Now()},
+	})
+	var articleID int64
+	db.QueryRow(`SELECT id FROM articles WHERE title = 'Kept'`).Scan(&articleID)
+	db.SetArticleFavorite(articleID, true)
+	db.AddArticleTag(articleID, tagID)
+
+	db.AddSavedFilter(&models.SavedFilter{Name: "Go", Conditions: `[{"field":"article_title","value":"go"}]`})
+
+	profileID, _ := db.CreateAIProfile(&models.AIProfile{Name: "OpenAI", APIKey: "sk-secret", Endpoint: "https://api.openai.com/v1", Model: "gpt-4o-mini"})
+	db.SetSetting("ai_summary_profile_id", strconv.FormatInt(profileID, 10))
+
+	rules := `[{"id":1,"name":"Tag tech","enabled":true,"conditions":[],"actions":[{"type":"add_tag","params":{"tag_id":"` +
+		strconv.FormatInt(tagID, 10) + `"}},{"type":"summarize","params":{"profile_id":"` + strconv.FormatInt(profileID, 10) + `"}}],"position":0}]`
+	db.SetSetting("rules", rules)
+}
+
+// roundTrip serializes a backup the way the export endpoint does and parses it back
+func roundTrip(t *testing.T, b *Backup) *Backup {
+	t.Helper()
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	parsed, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return parsed
+}
+
+func TestCreate_OmitsSecretsByDefault(t *testing.T) {
+	db := newTestDB(t)
+	seedSource(t, db)
+
+	b, err := Create(db, false)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if b.Feeds[0].EmailPassword != "" || b.AIProfiles[0].APIKey != "" {
+		t.Errorf("Expected secrets to be omitted, got %q and %q", b.Feeds[0].EmailPassword, b.AIProfiles[0].APIKey)
+	}
+
+	b, _ = Create(db, true)
+	if b.Feeds[0].EmailPassword != "imap-secret" || b.AIProfiles[0].APIKey != "sk-secret" || !b.IncludesSecrets {
+		t.Errorf("Expected secrets to be included, got %+v", b)
+	}
+}
+
+func TestRestore_IntoEmptyInstance(t *testing.T) {
+	source := newTestDB(t)
+	seedSource(t, source)
+	b, err := Create(source, true)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	target := newTestDB(t)
+	// Occupy the IDs used by the source so that remapping is exercised
+	target.AddTag(&models.Tag{Name: "Other", Color: "#000000"})
+	target.CreateAIProfile(&models.AIProfile{Name: "Local", Endpoint: "http://localhost", Model: "llama"})
+
+	result, err := Restore(target, roundTrip(t, b), ModeMerge)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result.FeedsAdded != 1 || len(result.NewFeedIDs) != 1 || result.ArticleStates != 1 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	feeds, _ := target.GetFeeds()
+	f := feeds[0]
+	if f.Type != "HTML+XPath" || f.XPathItem != "//article" || f.RefreshInterval != 30 || !f.ProxyEnabled ||
+		f.ProxyURL != "http://proxy:8080" || f.ArticleViewMode != "rendered" || f.EmailPassword != "imap-secret" {
+		t.Errorf("Feed settings not restored: %+v", f)
+	}
+
+	tags, _ := target.GetFeedTags(f.ID)
+	if len(tags) != 1 || tags[0].Name != "Tech" || tags[0].Color != "#FF0000" {
+		t.Errorf("Feed tags not restored: %+v", tags)
+	}
+
+	favorites, _ := target.GetArticles("favorites", 0, "", true, 10, 0)
+	if len(favorites) != 1 || favorites[0].URL != "https://example.com/news/1" {
+		t.Fatalf("Favorite not restored: %+v", favorites)
+	}
+	if articleTags, _ := target.GetArticleTags(favorites[0].ID); len(articleTags) != 1 || articleTags[0].Name != "Tech" {
+		t.Errorf("Article tags not restored: %+v", articleTags)
+	}
+
+	profiles, _ := target.GetAllAIProfiles()
+	var restored models.AIProfile
+	for _, p := range profiles {
+		if p.Name == "OpenAI" {
+			restored = p
+		}
+	}
+	if restored.APIKey != "sk-secret" {
+		t.Errorf("AI profile not restored: %+v", profiles)
+	}
+	if id, _ := target.GetSetting("ai_summary_profile_id"); id != strconv.FormatInt(restored.ID, 10) {
+		t.Errorf("Expected the summary profile to point to %d, got %s", restored.ID, id)
+	}
+
+	var rules []struct {
+		Actions []struct {
+			Params map[string]string `json:"params"`
+		} `json:"actions"`
+	}
+	rulesJSON, _ := target.GetSetting("rules")
+	json.Unmarshal([]byte(rulesJSON), &rules)
+	if len(rules) != 1 {
+		t.Fatalf("Rules not restored: %s", rulesJSON)
+	}
+	if rules[0].Actions[0].Params["tag_id"] != strconv.FormatInt(tags[0].ID, 10) ||
+		rules[0].Actions[1].Params["profile_id"] != strconv.FormatInt(restored.ID, 10) {
+		t.Errorf("Rule parameters not remapped: %s", rulesJSON)
+	}
+
+	if filters, _ := target.GetSavedFilters(); len(filters) != 1 || filters[0].Name != "Go" {
+		t.Errorf("Saved filters not restored: %+v", filters)
+	}
+}
+
+func TestRestore_MergeKeepsExistingConfiguration(t *testing.T) {
+	source := newTestDB(t)
+	seedSource(t, source)
+	b, _ := Create(source, false)
+
+	target := newTestDB(t)
+	target.AddFeed(&models.Feed{Title: "Mine", URL: "https://example.com/news", RefreshInterval: 5})
+	target.AddSavedFilter(&models.SavedFilter{Name: "Go", Conditions: `[]`})
+	target.SetSetting("rules", `[{"id":7,"name":"Local rule","actions":[]}]`)
+
+	result, err := Restore(target, roundTrip(t, b), ModeMerge)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result.FeedsAdded != 0 || result.SavedFilters != 0 || result.Rules != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	feeds, _ := target.GetFeeds()
+	if len(feeds) != 1 || feeds[0].Title != "Mine" || feeds[0].RefreshInterval != 5 {
+		t.Errorf("Expected the local feed settings to be kept, got %+v", feeds)
+	}
+	if tags, _ := target.GetFeedTags(feeds[0].ID); len(tags) != 1 || tags[0].Name != "Tech" {
+		t.Errorf("Expected the backup tags to be added, got %+v", tags)
+	}
+	if filters, _ := target.GetSavedFilters(); len(filters) != 1 || filters[0].Conditions != `[]` {
+		t.Errorf("Expected the local saved filter to be kept, got %+v", filters)
+	}
+	var rules []map[string]interface{}
+	rulesJSON, _ := target.GetSetting("rules")
+	json.Unmarshal([]byte(rulesJSON), &rules)
+	if len(rules) != 2 || rules[0]["name"] != "Local rule" {
+		t.Errorf("Expected the backup rule to be appended, got %s", rulesJSON)
+	}
+}
+
+func TestRestore_ReplaceMatchesBackup(t *testing.T) {
+	source := newTestDB(t)
+	seedSource(t, source)
+	b, _ := Create(source, false)
+
+	target := newTestDB(t)
+	target.AddFeed(&models.Feed{Title: "Mine", URL: "https://example.com/news", RefreshInterval: 5, EmailPassword: "local-secret"})
+	target.AddFeed(&models.Feed{Title: "Obsolete", URL: "https://example.com/old"})
+	target.AddSavedFilter(&models.SavedFilter{Name: "Obsolete", Conditions: `[]`})
+	target.CreateAIProfile(&models.AIProfile{Name: "OpenAI", APIKey: "sk-local", Endpoint: "https://old", Model: "old"})
+
+	result, err := Restore(target, roundTrip(t, b), ModeReplace)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result.FeedsUpdated != 1 || result.FeedsRemoved != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	feeds, _ := target.GetFeeds()
+	if len(feeds) != 1 || feeds[0].Title != "Scraped" || feeds[0].RefreshInterval != 30 {
+		t.Fatalf("Expected the feed to match the backup, got %+v", feeds)
+	}
+	if feeds[0].EmailPassword != "local-secret" {
+		t.Errorf("Expected the local secret to be kept when the backup has none, got %q", feeds[0].EmailPassword)
+	}
+	if filters, _ := target.GetSavedFilters(); len(filters) != 1 || filters[0].Name != "Go" {
+		t.Errorf("Expected only the backup saved filters, got %+v", filters)
+	}
+	profiles, _ := target.GetAllAIProfiles()
+	if len(profiles) != 1 || profiles[0].Endpoint != "https://api.openai.com/v1" || profiles[0].APIKey != "sk-local" {
+		t.Errorf("Expected the profile to be updated with the local key kept, got %+v", profiles)
+	}
+}
+
+func TestRestore_HTTPOptionsWithoutSecrets(t *testing.T) {
+	source := newTestDB(t)
+	feedID, _ := source.AddFeed(&models.Feed{Title: "Members", URL: "https://example.com/members.xml"})
+	source.SaveFeedHTTPOptions(&models.FeedHTTPOptions{
+		FeedID:       feedID,
+		Method:       "POST",
+		Headers:      "X-Api-Key: source-key\nX-Plan: gold",
+		Cookies:      "session=source-session",
+		AuthType:     "basic",
+		AuthUsername: "reader",
+		AuthSecret:   "source-password",
+	})
+
+	b, err := Create(source, false)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	exported := b.Feeds[0].HTTPOptions
+	if exported == nil || exported.Headers != "X-Api-Key:\nX-Plan:" || exported.Cookies != "session=" ||
+		exported.AuthSecret != "" || exported.AuthUsername != "reader" || exported.Method != "POST" {
+		t.Fatalf("Expected the options without secrets, got %+v", exported)
+	}
+
+	target := newTestDB(t)
+	localID, _ := target.AddFeed(&models.Feed{Title: "Members", URL: "https://example.com/members.xml"})
+	target.SaveFeedHTTPOptions(&models.FeedHTTPOptions{
+		FeedID:       localID,
+		Headers:      "X-Api-Key: local-key",
+		Cookies:      "session=local-session",
+		AuthType:     "basic",
+		AuthUsername: "reader",
+		AuthSecret:   "local-password",
+	})
+	if _, err := Restore(target, roundTrip(t, b), ModeReplace); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	feeds, _ := target.GetFeeds()
+	options, _ := target.GetFeedHTTPOptions(feeds[0].ID)
+	if options.Method != "POST" || options.Headers != "X-Api-Key: local-key\nX-Plan:" ||
+		options.Cookies != "session=local-session" || options.AuthSecret != "local-password" {
+		t.Errorf("Expected the backup options with the local secrets, got %+v", options)
+	}
+}
+
+func TestRestore_JSONFeedOptionsWithoutSecrets(t *testing.T) {
+	source := newTestDB(t)
+	feedID, _ := source.AddFeed(&models.Feed{Title: "API", URL: "https://api.example.com/items", Type: "json", XPathItem: "$.items"})
+	source.SaveJSONFeedOptions(&models.JSONFeedOptions{
+		FeedID:       feedID,
+		Headers:      "Authorization: Bearer source-token\nAccept: application/json",
+		NextPagePath: "$.next",
+		PageParam:    "cursor",
+		MaxPages:     3,
+	})
+
+	b, err := Create(source, false)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	exported := b.Feeds[0].JSONOptions
+	if exported == nil || exported.Headers != "Authorization:\nAccept:" || exported.NextPagePath != "$.next" ||
+		exported.PageParam != "cursor" || exported.MaxPages != 3 {
+		t.Fatalf("Expected the options without header values, got %+v", exported)
+	}
+
+	target := newTestDB(t)
+	localID, _ := target.AddFeed(&models.Feed{Title: "API", URL: "https://api.example.com/items", Type: "json"})
+	target.SaveJSONFeedOptions(&models.JSONFeedOptions{FeedID: localID, Headers: "Authorization: Bearer local-token"})
+	if _, err := Restore(target, roundTrip(t, b), ModeReplace); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	feeds, _ := target.GetFeeds()
+	options, _ := target.GetJSONFeedOptions(feeds[0].ID)
+	if options.Headers != "Authorization: Bearer local-token\nAccept:" || options.NextPagePath != "$.next" ||
+		options.PageParam != "cursor" || options.MaxPages != 3 {
+		t.Errorf("Expected the backup options with the local header values, got %+v", options)
+	}
+
+	b, _ = Create(source, true)
+	if b.Feeds[0].JSONOptions.Headers != "Authorization: Bearer source-token\nAccept: application/json" {
+		t.Errorf("Expected the header values with secrets, got %q", b.Feeds[0].JSONOptions.Headers)
+	}
+}
+
+func TestRestore_ScriptSettingsWithoutSecrets(t *testing.T) {
+	source := newTestDB(t)
+	feedID, _ := source.AddFeed(&models.Feed{Title: "Script", URL: "script://members", ScriptPath: "members.py"})
+	source.SaveScriptFeedSettings(&models.ScriptFeedSettings{
+		FeedID:         feedID,
+		Args:           []string{"--since", "7d"},
+		Env:            map[string]string{"SITE": "members"},
+		Secrets:        map[string]string{"TOKEN": "source-token", "PASSWORD": "source-password"},
+		TimeoutSeconds: 120,
+	})
+	source.SaveScriptState(feedID, []byte(`{"cursor":42}`))
+
+	b, err := Create(source, false)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	exported := b.Feeds[0].ScriptSettings
+	if exported == nil || exported.Secrets["TOKEN"] != "" || len(exported.Secrets) != 2 ||
+		exported.TimeoutSeconds != 120 || b.Feeds[0].ScriptState != `{"cursor":42}` {
+		t.Fatalf("Expected the settings and state without secret values, got %+v %q", exported, b.Feeds[0].ScriptState)
+	}
+
+	target := newTestDB(t)
+	localID, _ := target.AddFeed(&models.Feed{Title: "Script", URL: "script://members", ScriptPath: "members.py"})
+	target.SaveScriptFeedSettings(&models.ScriptFeedSettings{FeedID: localID, Secrets: map[string]string{"TOKEN": "local-token"}})
+	if _, err := Restore(target, roundTrip(t, b), ModeReplace); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	feeds, _ := target.GetFeeds()
+	settings, _ := target.GetScriptFeedSettings(feeds[0].ID)
+	if len(settings.Args) != 2 || settings.Env["SITE"] != "members" || settings.TimeoutSeconds != 120 ||
+		settings.Secrets["TOKEN"] != "local-token" || settings.Secrets["PASSWORD"] != "" {
+		t.Errorf("Expected the backup settings with the local secrets, got %+v", settings)
+	}
+	if state, _ := target.GetScriptState(feeds[0].ID); string(state) != `{"cursor":42}` {
+		t.Errorf("Expected the script state to be restored, got %q", state)
+	}
+
+	b, _ = Create(source, true)
+	if b.Feeds[0].ScriptSettings.Secrets["TOKEN"] != "source-token" {
+		t.Errorf("Expected the secret values with secrets, got %+v", b.Feeds[0].ScriptSettings.Secrets)
+	}
+}
+
+func TestRestore_ItemTransform(t *testing.T) {
+	source := newTestDB(t)
+	feedID, _ := source.AddFeed(&models.Feed{Title: "Transformed", URL: "https://example.com/feed.xml"})
+	source.AddFeed(&models.Feed{Title: "Plain", URL: "https://example.com/plain.xml"})
+	script := `function transform(item, feed) { item.title = item.title.trim(); return item; }`
+	source.SaveItemTransform(&models.ItemTransform{FeedID: feedID, Script: script, Enabled: true})
+
+	b, err := Create(source, false)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	target := newTestDB(t)
+	if _, err := Restore(target, roundTrip(t, b), ModeMerge); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	feeds, _ := target.GetFeeds()
+	for _, f := range feeds {
+		transform, _ := target.GetItemTransform(f.ID)
+		switch f.URL {
+		case "https://example.com/feed.xml":
+			if transform.Script != script || !transform.Enabled {
+				t.Errorf("Item transform not restored: %+v", transform)
+			}
+		default:
+			if transform.Script != "" || transform.Enabled {
+				t.Errorf("Expected no item transform for %s, got %+v", f.URL, transform)
+			}
+		}
+	}
+}
+
+func TestRestore_PodcastSettingsAndPositions(t *testing.T) {
+	source := newTestDB(t)
+	feedID, _ := source.AddFeed(&models.Feed{Title: "Podcast", URL: "https://example.com/podcast.xml"})
+	source.SavePodcastFeedSettings(&models.PodcastFeedSettings{FeedID: feedID, AutoDownload: true, KeepEpisodes: 3, RetentionDays: 14})
+	source.SaveArticles(context.Background(), []*models.Article{
+		{FeedID: feedID, Title: "Started", URL: "https://example.com/ep1", AudioURL: "https://cdn.example.com/ep1.mp3", PublishedAt: time.Now()},
+		{FeedID: feedID, Title: "Untouched", URL: "https://example.com/ep2", AudioURL: "https://cdn.example.com/ep2.mp3", PublishedAt: time.Now()},
+	})
+	var startedID, untouchedID int64
+	source.QueryRow(`SELECT id FROM articles WHERE title = 'Started'`).Scan(&startedID)
+	source.QueryRow(`SELECT id FROM articles WHERE title = 'Untouched'`).Scan(&untouchedID)
+	source.EnsurePodcastEpisode(startedID)
+	source.EnsurePodcastEpisode(untouchedID)
+	source.UpdatePodcastProgress(startedID, 125.5, 0, false)
+
+	b, err := Create(source, false)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(b.ArticleStates) != 1 || b.ArticleStates[0].EnclosureURL != "https://cdn.example.com/ep1.mp3" {
+		t.Fatalf("Expected only the started episode, got %+v", b.ArticleStates)
+	}
+
+	target := newTestDB(t)
+	if _, err := Restore(target, roundTrip(t, b), ModeMerge); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	feeds, _ := target.GetFeeds()
+	settings, _ := target.GetPodcastFeedSettings(feeds[0].ID)
+	if !settings.AutoDownload || settings.KeepEpisodes != 3 || settings.RetentionDays != 14 {
+		t.Errorf("Podcast settings not restored: %+v", settings)
+	}
+	var articleID int64
+	target.QueryRow(`SELECT id FROM articles WHERE url = 'https://example.com/ep1'`).Scan(&articleID)
+	episode, _ := target.GetPodcastEpisode(articleID)
+	if episode == nil || episode.Position != 125.5 || episode.EnclosureURL != "https://cdn.example.com/ep1.mp3" {
+		t.Errorf("Playback position not restored: %+v", episode)
+	}
+}
+
+func TestParse_RejectsOtherDocuments(t *testing.T) {
+	for _, doc := range []string{
+		`{"version":1,"feeds":[]}`,
+		`{"format":"mrrss-backup","version":99}`,
+		`not json`,
+	} {
+		if _, err := Parse(bytes.NewReader([]byte(doc))); err == nil {
+			t.Errorf("Expected an error for %s", doc)
+		}
+	}
+}
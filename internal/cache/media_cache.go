@@ -73,6 +73,12 @@ func (mc *MediaCache) Exists(url string) bool {
 
 // Get retrieves cached media or downloads it if not cached
 func (mc *MediaCache) Get(url, referer string) ([]byte, string, error) {
+	return mc.GetWithTransport(url, referer, nil)
+}
+
+// GetWithTransport is like Get but downloads with the given transport, e.g. one that adds
+// credentials to the requests to a site. A nil transport uses the default transport.
+func (mc *MediaCache) GetWithTransport(url, referer string, transport http.RoundTripper) ([]byte, string, error) {
 	// Check if already cached
 	cachedPath, found := mc.findCachedFile(url)
 	if found {
@@ -85,7 +91,7 @@ func (mc *MediaCache) Get(url, referer string) ([]byte, string, error) {
 	}
 
 	// Download and cache
-	data, contentType, err := mc.download(url, referer)
+	data, contentType, err := mc.download(url, referer, transport)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to download media: %w", err)
 	}
@@ -108,9 +114,10 @@ func (mc *MediaCache) Get(url, referer string) ([]byte, string, error) {
 }
 
 // download fetches media from the given URL with proper headers
-func (mc *MediaCache) download(url, referer string) ([]byte, string, error) {
+func (mc *MediaCache) download(url, referer string, transport http.RoundTripper) ([]byte, string, error) {
 	client := &http.Client{
-		Timeout: 30 * time.Second,
+		Timeout:   30 * time.Second,
+		Transport: transport,
 	}
 
 	req, err := http.NewRequest("GET", url, nil)
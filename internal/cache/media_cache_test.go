@@ -1,10 +1,16 @@
 package cache
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"MrRSS/internal/feed/httpoptions"
+	"MrRSS/internal/models"
 )
 
 func TestMediaCache_BasicOperations(t *testing.T) {
@@ -70,3 +76,43 @@ func TestGetExtensionAndContentTypeHelpers(t *testing.T) {
 		t.Fatalf("unexpected ext: %s", ext)
 	}
 }
+
+func TestMediaCache_GetWithTransport_RedirectToOtherSite(t *testing.T) {
+	var cdnKey string
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cdnKey = r.Header.Get("X-Api-Key")
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	}))
+	defer cdn.Close()
+	// The CDN is reached by another host name, so it is a different site
+	cdnURL := strings.Replace(cdn.URL, "127.0.0.1", "localhost", 1)
+
+	var siteKey string
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siteKey = r.Header.Get("X-Api-Key")
+		http.Redirect(w, r, cdnURL+"/image.png", http.StatusFound)
+	}))
+	defer site.Close()
+
+	mc, err := NewMediaCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewMediaCache failed: %v", err)
+	}
+	options := &models.FeedHTTPOptions{Headers: "X-Api-Key: secret"}
+	transport := httpoptions.Transport(http.DefaultTransport, options, site.URL+"/feed.xml")
+
+	data, _, err := mc.GetWithTransport(site.URL+"/image.png", "", transport)
+	if err != nil {
+		t.Fatalf("GetWithTransport failed: %v", err)
+	}
+	if string(data) != "png" {
+		t.Errorf("unexpected data: %q", data)
+	}
+	if siteKey != "secret" {
+		t.Errorf("expected the header to be sent to the site of the feed, got %q", siteKey)
+	}
+	if cdnKey != "" {
+		t.Errorf("expected the header not to be sent to the redirect target, got %q", cdnKey)
+	}
+}
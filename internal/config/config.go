@@ -22,6 +22,10 @@ type Defaults struct {
 	AIChatEnabled                 bool   `json:"ai_chat_enabled"`
 	AIChatProfileId               string `json:"ai_chat_profile_id"`
 	AICustomHeaders               string `json:"ai_custom_headers"`
+	AIDigestProfileId             string `json:"ai_digest_profile_id"`
+	AIEmbeddingEnabled            bool   `json:"ai_embedding_enabled"`
+	AIEmbeddingModel              string `json:"ai_embedding_model"`
+	AIEmbeddingProfileId          string `json:"ai_embedding_profile_id"`
 	AIEndpoint                    string `json:"ai_endpoint"`
 	AIModel                       string `json:"ai_model"`
 	AISearchEnabled               bool   `json:"ai_search_enabled"`
@@ -53,8 +57,15 @@ type Defaults struct {
 	DeeplAPIKey                   string `json:"deepl_api_key"`
 	DeeplEndpoint                 string `json:"deepl_endpoint"`
 	DefaultViewMode               string `json:"default_view_mode"`
+	DuplicateAction               string `json:"duplicate_action"`
+	DuplicateDetectionEnabled     bool   `json:"duplicate_detection_enabled"`
 	FeedDrawerExpanded            bool   `json:"feed_drawer_expanded"`
 	FeedDrawerPinned              bool   `json:"feed_drawer_pinned"`
+	FeedHealthBackoffFailures     int    `json:"feed_health_backoff_failures"`
+	FeedHealthDeadDays            int    `json:"feed_health_dead_days"`
+	FeedHealthPauseDays           int    `json:"feed_health_pause_days"`
+	FeedUrlAutoMigrate            bool   `json:"feed_url_auto_migrate"`
+	FeedUrlMigrateOtherSites      bool   `json:"feed_url_migrate_other_sites"`
 	FreshRSSAPIPassword           string `json:"freshrss_api_password"`
 	FreshRSSAutoSyncInterval      int    `json:"freshrss_auto_sync_interval"`
 	FreshRSSEnabled               bool   `json:"freshrss_enabled"`
@@ -86,6 +97,8 @@ type Defaults struct {
 	ObsidianEnabled               bool   `json:"obsidian_enabled"`
 	ObsidianVault                 string `json:"obsidian_vault"`
 	ObsidianVaultPath             string `json:"obsidian_vault_path"`
+	PodcastDownloadConcurrency    int    `json:"podcast_download_concurrency"`
+	PodcastStorageQuotaMb         int    `json:"podcast_storage_quota_mb"`
 	ProxyEnabled                  bool   `json:"proxy_enabled"`
 	ProxyHost                     string `json:"proxy_host"`
 	ProxyPassword                 string `json:"proxy_password"`
@@ -110,6 +123,9 @@ type Defaults struct {
 	TargetLanguage                string `json:"target_language"`
 	Theme                         string `json:"theme"`
 	TranslationEnabled            bool   `json:"translation_enabled"`
+	TranslationFallbackProviders  string `json:"translation_fallback_providers"`
+	TranslationFullArticle        bool   `json:"translation_full_article"`
+	TranslationMonthlyCharBudgets string `json:"translation_monthly_char_budgets"`
 	TranslationOnlyMode           bool   `json:"translation_only_mode"`
 	TranslationProvider           string `json:"translation_provider"`
 	UpdateInterval                int    `json:"update_interval"`
@@ -144,6 +160,14 @@ func GetString(key string) string {
 		return defaults.AIChatProfileId
 	case "ai_custom_headers":
 		return defaults.AICustomHeaders
+	case "ai_digest_profile_id":
+		return defaults.AIDigestProfileId
+	case "ai_embedding_enabled":
+		return strconv.FormatBool(defaults.AIEmbeddingEnabled)
+	case "ai_embedding_model":
+		return defaults.AIEmbeddingModel
+	case "ai_embedding_profile_id":
+		return defaults.AIEmbeddingProfileId
 	case "ai_endpoint":
 		return defaults.AIEndpoint
 	case "ai_model":
@@ -206,10 +230,24 @@ func GetString(key string) string {
 		return defaults.DeeplEndpoint
 	case "default_view_mode":
 		return defaults.DefaultViewMode
+	case "duplicate_action":
+		return defaults.DuplicateAction
+	case "duplicate_detection_enabled":
+		return strconv.FormatBool(defaults.DuplicateDetectionEnabled)
 	case "feed_drawer_expanded":
 		return strconv.FormatBool(defaults.FeedDrawerExpanded)
 	case "feed_drawer_pinned":
 		return strconv.FormatBool(defaults.FeedDrawerPinned)
+	case "feed_health_backoff_failures":
+		return strconv.Itoa(defaults.FeedHealthBackoffFailures)
+	case "feed_health_dead_days":
+		return strconv.Itoa(defaults.FeedHealthDeadDays)
+	case "feed_health_pause_days":
+		return strconv.Itoa(defaults.FeedHealthPauseDays)
+	case "feed_url_auto_migrate":
+		return strconv.FormatBool(defaults.FeedUrlAutoMigrate)
+	case "feed_url_migrate_other_sites":
+		return strconv.FormatBool(defaults.FeedUrlMigrateOtherSites)
 	case "freshrss_api_password":
 		return defaults.FreshRSSAPIPassword
 	case "freshrss_auto_sync_interval":
@@ -272,6 +310,10 @@ func GetString(key string) string {
 		return defaults.ObsidianVault
 	case "obsidian_vault_path":
 		return defaults.ObsidianVaultPath
+	case "podcast_download_concurrency":
+		return strconv.Itoa(defaults.PodcastDownloadConcurrency)
+	case "podcast_storage_quota_mb":
+		return strconv.Itoa(defaults.PodcastStorageQuotaMb)
 	case "proxy_enabled":
 		return strconv.FormatBool(defaults.ProxyEnabled)
 	case "proxy_host":
@@ -320,6 +362,12 @@ func GetString(key string) string {
 		return defaults.Theme
 	case "translation_enabled":
 		return strconv.FormatBool(defaults.TranslationEnabled)
+	case "translation_fallback_providers":
+		return defaults.TranslationFallbackProviders
+	case "translation_full_article":
+		return strconv.FormatBool(defaults.TranslationFullArticle)
+	case "translation_monthly_char_budgets":
+		return defaults.TranslationMonthlyCharBudgets
 	case "translation_only_mode":
 		return strconv.FormatBool(defaults.TranslationOnlyMode)
 	case "translation_provider":
@@ -1,6 +1,9 @@
 package database
 
-import "database/sql"
+import (
+	"database/sql"
+	"strings"
+)
 
 // ArticleContent represents a cached article content entry
 type ArticleContent struct {
@@ -36,7 +39,10 @@ func (db *DB) SetArticleContent(articleID int64, content string) error {
 		 VALUES (?, ?, CURRENT_TIMESTAMP)`,
 		articleID, content,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	return db.updateArticleFTSContent(articleID, content)
 }
 
 // DeleteArticleContent removes cached content for an article
@@ -49,16 +55,20 @@ func (db *DB) DeleteArticleContent(articleID int64) error {
 	return err
 }
 
-// CleanupOldArticleContents removes article content cache entries older than maxAgeDays
+// CleanupOldArticleContents removes article content cache entries older than maxAgeDays.
+// Content of annotated articles is kept so their highlights can still be shown.
 func (db *DB) CleanupOldArticleContents(maxAgeDays int) (int64, error) {
 	db.WaitForReady()
 	result, err := db.Exec(
-		`DELETE FROM article_contents WHERE fetched_at < datetime('now', '-' || ? || ' days')`,
+		`DELETE FROM article_contents
+		WHERE fetched_at < datetime('now', '-' || ? || ' days')
+		AND article_id NOT IN (SELECT article_id FROM highlights)`,
 		maxAgeDays,
 	)
 	if err != nil {
 		return 0, err
 	}
+	db.deleteOrphanArticleTranslations()
 	return result.RowsAffected()
 }
 
@@ -72,3 +82,39 @@ func (db *DB) GetArticleContentCount() (int64, error) {
 	}
 	return count, nil
 }
+
+// GetArticleContents retrieves cached content for several articles at once.
+// Articles without cached content are absent from the returned map.
+func (db *DB) GetArticleContents(articleIDs []int64) (map[int64]string, error) {
+	db.WaitForReady()
+	contents := make(map[int64]string, len(articleIDs))
+	if len(articleIDs) == 0 {
+		return contents, nil
+	}
+
+	placeholders := make([]string, len(articleIDs))
+	args := make([]interface{}, len(articleIDs))
+	for i, id := range articleIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	rows, err := db.Query(
+		`SELECT article_id, content FROM article_contents WHERE article_id IN (`+strings.Join(placeholders, ",")+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var content string
+		if err := rows.Scan(&id, &content); err != nil {
+			return nil, err
+		}
+		contents[id] = content
+	}
+	return contents, rows.Err()
+}
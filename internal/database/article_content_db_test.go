@@ -1,7 +1,12 @@
 package database
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
+
+	"MrRSS/internal/models"
 )
 
 func TestArticleContentCache(t *testing.T) {
@@ -130,3 +135,114 @@ func TestArticleContentCache(t *testing.T) {
 		}
 	})
 }
+
+func TestArticleTranslations(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.DB.Close()
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	if got, err := db.GetArticleTranslation(1, "zh"); err != nil || got != nil {
+		t.Fatalf("Expected no translation, got %+v, %v", got, err)
+	}
+
+	if err := db.SetArticleContent(1, "<p>Hello</p><p>World</p>"); err != nil {
+		t.Fatalf("Failed to set article content: %v", err)
+	}
+	saved := &ArticleTranslation{ArticleID: 1, TargetLanguage: "zh", SourceHash: "abc", Blocks: []string{"你好", ""}}
+	if err := db.SaveArticleTranslation(saved); err != nil {
+		t.Fatalf("Failed to save translation: %v", err)
+	}
+
+	got, err := db.GetArticleTranslation(1, "zh")
+	if err != nil || got == nil {
+		t.Fatalf("Failed to get translation: %v", err)
+	}
+	if got.SourceHash != "abc" || len(got.Blocks) != 2 || got.Blocks[0] != "你好" || got.Complete {
+		t.Errorf("Unexpected translation: %+v", got)
+	}
+
+	// Translations are removed together with the cached content
+	if _, err := db.CleanupAllArticleContents(); err != nil {
+		t.Fatalf("Failed to clean up contents: %v", err)
+	}
+	if got, _ := db.GetArticleTranslation(1, "zh"); got != nil {
+		t.Errorf("Expected the translation to be removed with the content")
+	}
+}
+
+func TestArticleTranslations_Cleanup(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.DB.Close()
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	published := time.Now()
+	articles := []*models.Article{
+		{FeedID: feedID, Title: "Deleted", URL: "https://example.com/deleted", PublishedAt: published},
+		{FeedID: feedID, Title: "Favorite", URL: "https://example.com/favorite", PublishedAt: published, IsFavorite: true},
+		{FeedID: feedID, Title: "Annotated", URL: "https://example.com/annotated", PublishedAt: published, IsFavorite: true},
+	}
+	if err := db.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatalf("SaveArticles failed: %v", err)
+	}
+	ids := make(map[string]int64)
+	rows, _ := db.Query(`SELECT id, title FROM articles`)
+	for rows.Next() {
+		var id int64
+		var title string
+		rows.Scan(&id, &title)
+		ids[title] = id
+	}
+	rows.Close()
+	if _, err := db.CreateHighlight(&models.Highlight{ArticleID: ids["Annotated"], Quote: "quote"}); err != nil {
+		t.Fatalf("CreateHighlight failed: %v", err)
+	}
+
+	// A large translation for each article, so the database exceeds a 1 MB limit
+	block := strings.Repeat("翻译", 200*1024)
+	for _, id := range ids {
+		if err := db.SaveArticleTranslation(&ArticleTranslation{ArticleID: id, TargetLanguage: "zh", SourceHash: "abc", Blocks: []string{block}}); err != nil {
+			t.Fatalf("Failed to save translation: %v", err)
+		}
+	}
+
+	t.Run("Deleting an article removes its translations", func(t *testing.T) {
+		if _, err := db.Exec(`DELETE FROM articles WHERE id = ?`, ids["Deleted"]); err != nil {
+			t.Fatalf("Failed to delete article: %v", err)
+		}
+		if got, _ := db.GetArticleTranslation(ids["Deleted"], "zh"); got != nil {
+			t.Error("Expected the translation to be removed with the article")
+		}
+	})
+
+	t.Run("Size-based cleanup removes translations", func(t *testing.T) {
+		if err := db.SetSetting("max_cache_size_mb", "1"); err != nil {
+			t.Fatalf("SetSetting failed: %v", err)
+		}
+		if _, err := db.CleanupBySize(); err != nil {
+			t.Fatalf("CleanupBySize failed: %v", err)
+		}
+		if got, _ := db.GetArticleTranslation(ids["Favorite"], "zh"); got != nil {
+			t.Error("Expected the translation of the favorite article to be removed")
+		}
+		if got, _ := db.GetArticleTranslation(ids["Annotated"], "zh"); got == nil {
+			t.Error("Expected the translation of the annotated article to be kept")
+		}
+		if _, err := db.GetArticleByID(ids["Favorite"]); err != nil {
+			t.Errorf("Expected the favorite article to be kept: %v", err)
+		}
+	})
+}
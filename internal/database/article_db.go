@@ -19,13 +19,36 @@ func (db *DB) SaveArticle(article *models.Article) error {
 	// Generate unique_id for deduplication
 	uniqueID := urlutil.GenerateArticleUniqueID(article.Title, article.FeedID, article.PublishedAt, article.HasValidPublishedTime)
 	query := `INSERT OR IGNORE INTO articles (feed_id, title, url, image_url, audio_url, video_url, published_at, translated_title, is_read, is_favorite, is_hidden, is_read_later, summary, unique_id, author) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	_, err := db.Exec(query, article.FeedID, article.Title, article.URL, article.ImageURL, article.AudioURL, article.VideoURL, article.PublishedAt, article.TranslatedTitle, article.IsRead, article.IsFavorite, article.IsHidden, article.IsReadLater, article.Summary, uniqueID, article.Author)
-	return err
+	result, err := db.Exec(query, article.FeedID, article.Title, article.URL, article.ImageURL, article.AudioURL, article.VideoURL, article.PublishedAt, article.TranslatedTitle, article.IsRead, article.IsFavorite, article.IsHidden, article.IsReadLater, article.Summary, uniqueID, article.Author)
+	if err != nil {
+		return err
+	}
+
+	if id, ok := insertedID(result); ok {
+		db.publish(Event{Type: EventArticlesNew, ArticleIDs: []int64{id}})
+	}
+	return nil
+}
+
+// insertedID returns the ID of the row inserted by an INSERT OR IGNORE, or false if it was ignored
+func insertedID(result sql.Result) (int64, bool) {
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return 0, false
+	}
+	id, err := result.LastInsertId()
+	return id, err == nil
 }
 
 // SaveArticles saves multiple articles in a transaction.
 // Includes progressive cleanup check to prevent database from exceeding size limit during refresh.
 func (db *DB) SaveArticles(ctx context.Context, articles []*models.Article) error {
+	_, err := db.SaveNewArticles(ctx, articles)
+	return err
+}
+
+// SaveNewArticles saves multiple articles like SaveArticles and returns how many of
+// them were new, i.e. not already stored.
+func (db *DB) SaveNewArticles(ctx context.Context, articles []*models.Article) (int, error) {
 	db.WaitForReady()
 
 	// Progressive cleanup: check if we need to clean up before saving
@@ -47,34 +70,46 @@ func (db *DB) SaveArticles(ctx context.Context, articles []*models.Article) erro
 
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer tx.Rollback()
 
 	stmt, err := tx.PrepareContext(ctx, `INSERT OR IGNORE INTO articles (feed_id, title, url, image_url, audio_url, video_url, published_at, translated_title, is_read, is_favorite, is_hidden, is_read_later, summary, unique_id, author) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer stmt.Close()
 
+	var newIDs []int64
 	for _, article := range articles {
 		// Check context before each insert
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return 0, ctx.Err()
 		default:
 		}
 
 		// Generate unique_id for deduplication
 		uniqueID := urlutil.GenerateArticleUniqueID(article.Title, article.FeedID, article.PublishedAt, article.HasValidPublishedTime)
-		_, err := stmt.ExecContext(ctx, article.FeedID, article.Title, article.URL, article.ImageURL, article.AudioURL, article.VideoURL, article.PublishedAt, article.TranslatedTitle, article.IsRead, article.IsFavorite, article.IsHidden, article.IsReadLater, article.Summary, uniqueID, article.Author)
+		result, err := stmt.ExecContext(ctx, article.FeedID, article.Title, article.URL, article.ImageURL, article.AudioURL, article.VideoURL, article.PublishedAt, article.TranslatedTitle, article.IsRead, article.IsFavorite, article.IsHidden, article.IsReadLater, article.Summary, uniqueID, article.Author)
 		if err != nil {
 			log.Println("Error saving article in batch:", err)
 			// Continue even if one fails
+			continue
+		}
+		if id, ok := insertedID(result); ok {
+			newIDs = append(newIDs, id)
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	if len(newIDs) > 0 {
+		db.publish(Event{Type: EventArticlesNew, ArticleIDs: newIDs})
+	}
+	return len(newIDs), nil
 }
 
 // GetArticles retrieves articles with filtering, pagination, and sorting.
@@ -184,7 +219,30 @@ func (db *DB) GetArticles(filter string, feedID int64, category string, showHidd
 		return nil, err
 	}
 	defer rows.Close()
+	return scanArticleRows(rows), nil
+}
+
+// GetArticlesMatching retrieves the newest visible articles matching a SQL condition on
+// the articles (a) and their feeds (f), e.g. one built from saved filter conditions.
+func (db *DB) GetArticlesMatching(condition string, args []interface{}, limit int) ([]models.Article, error) {
+	db.WaitForReady()
+
+	query := `
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, a.author
+		FROM articles a
+		JOIN feeds f ON a.feed_id = f.id
+		WHERE a.is_hidden = 0 AND (` + condition + `)
+		ORDER BY a.published_at DESC LIMIT ?`
+	rows, err := db.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanArticleRows(rows), nil
+}
 
+// scanArticleRows scans the articles selected by GetArticles, skipping rows that fail
+func scanArticleRows(rows *sql.Rows) []models.Article {
 	var articles []models.Article
 	for rows.Next() {
 		var a models.Article
@@ -208,7 +266,7 @@ func (db *DB) GetArticles(filter string, feedID int64, category string, showHidd
 		a.Author = author.String
 		articles = append(articles, a)
 	}
-	return articles, nil
+	return articles
 }
 
 // GetArticleByID retrieves a single article by its ID.
@@ -0,0 +1,306 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils/textutil"
+)
+
+// Snippet highlight markers used inside SQLite. They are replaced with <mark> tags
+// after the snippet has been HTML-escaped, so article text can never inject markup.
+const (
+	ftsHighlightStart = "\x02"
+	ftsHighlightEnd   = "\x03"
+)
+
+// ftsMinQueryLength is the shortest term the trigram tokenizer can match.
+// Shorter queries fall back to a substring scan of the index table.
+const ftsMinQueryLength = 3
+
+// ErrInvalidSearchQuery is returned when a search query is not valid FTS5 syntax
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// ArticleSearchOptions holds the parameters for a full-text article search
+type ArticleSearchOptions struct {
+	Query      string    // FTS5 query: "phrase", prefix*, AND/OR/NOT, column:term
+	FeedID     int64     // Restrict to a single feed (0 for all feeds)
+	Category   string    // Restrict to a category and its subcategories ("\x00" for uncategorized)
+	After      time.Time // Only articles published at or after this time
+	Before     time.Time // Only articles published before this time
+	ShowHidden bool      // Include hidden articles
+	Limit      int
+	Offset     int
+}
+
+// ArticleSearchResult is an article matched by full-text search
+type ArticleSearchResult struct {
+	models.Article
+	Snippet string  `json:"snippet"` // HTML-escaped excerpt with matches wrapped in <mark>
+	Rank    float64 `json:"rank"`    // BM25 score, lower is more relevant
+}
+
+// migrateArticlesFTS creates the articles_fts full-text index and the triggers that
+// keep it in sync with the articles table. Cached article content is written to the
+// index as plain text by SetArticleContent, so markup is not searchable.
+// The trigram tokenizer is used because it supports substring matching for CJK text.
+func migrateArticlesFTS(db *sql.DB) error {
+	var exists int
+	_ = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='articles_fts'`).Scan(&exists)
+
+	if exists == 0 {
+		_, err := db.Exec(`CREATE VIRTUAL TABLE articles_fts USING fts5(
+			title, translated_title, author, summary, content,
+			tokenize='trigram'
+		)`)
+		if err != nil {
+			log.Printf("Warning: Failed to create full-text search index: %v", err)
+			return nil
+		}
+	}
+
+	triggers := []string{
+		`CREATE TRIGGER IF NOT EXISTS articles_fts_insert AFTER INSERT ON articles BEGIN
+			INSERT INTO articles_fts (rowid, title, translated_title, author, summary, content)
+			VALUES (new.id, COALESCE(new.title, ''), COALESCE(new.translated_title, ''), COALESCE(new.author, ''), COALESCE(new.summary, ''), '');
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS articles_fts_update AFTER UPDATE OF title, translated_title, author, summary ON articles BEGIN
+			UPDATE articles_fts SET
+				title = COALESCE(new.title, ''),
+				translated_title = COALESCE(new.translated_title, ''),
+				author = COALESCE(new.author, ''),
+				summary = COALESCE(new.summary, '')
+			WHERE rowid = new.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS articles_fts_delete AFTER DELETE ON articles BEGIN
+			DELETE FROM articles_fts WHERE rowid = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS article_contents_fts_delete AFTER DELETE ON article_contents BEGIN
+			UPDATE articles_fts SET content = '' WHERE rowid = old.article_id;
+		END`,
+	}
+	for _, trigger := range triggers {
+		if _, err := db.Exec(trigger); err != nil {
+			log.Printf("Warning: Failed to create full-text search trigger: %v", err)
+		}
+	}
+
+	if exists == 0 {
+		if err := rebuildArticlesFTS(db); err != nil {
+			log.Printf("Warning: Failed to build full-text search index: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// rebuildArticlesFTS fills the full-text index from the articles and article_contents tables
+func rebuildArticlesFTS(db *sql.DB) error {
+	if _, err := db.Exec(`DELETE FROM articles_fts`); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO articles_fts (rowid, title, translated_title, author, summary, content)
+		SELECT id, COALESCE(title, ''), COALESCE(translated_title, ''), COALESCE(author, ''), COALESCE(summary, ''), ''
+		FROM articles
+	`)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query(`SELECT article_id, content FROM article_contents`)
+	if err != nil {
+		return err
+	}
+	contents := make(map[int64]string)
+	for rows.Next() {
+		var articleID int64
+		var content string
+		if err := rows.Scan(&articleID, &content); err == nil {
+			contents[articleID] = content
+		}
+	}
+	rows.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`UPDATE articles_fts SET content = ? WHERE rowid = ?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for articleID, content := range contents {
+		if _, err := stmt.Exec(textutil.StripHTML(content), articleID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// updateArticleFTSContent stores the plain-text form of cached article content in the index
+func (db *DB) updateArticleFTSContent(articleID int64, content string) error {
+	_, err := db.Exec(`UPDATE articles_fts SET content = ? WHERE rowid = ?`, textutil.StripHTML(content), articleID)
+	return err
+}
+
+// SearchArticles runs a ranked full-text search over article titles, authors,
+// summaries and cached content. It returns the matching page of results and the
+// total number of matches.
+func (db *DB) SearchArticles(opts ArticleSearchOptions) ([]ArticleSearchResult, int, error) {
+	db.WaitForReady()
+
+	query := strings.TrimSpace(opts.Query)
+	if query == "" {
+		return nil, 0, fmt.Errorf("empty search query")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 50
+	}
+	if opts.Limit > 500 {
+		opts.Limit = 500
+	}
+
+	var where []string
+	var args []interface{}
+
+	// Queries shorter than a trigram cannot use the index, so scan it with LIKE instead
+	substringMode := utf8.RuneCountInString(query) < ftsMinQueryLength
+	if substringMode {
+		pattern := "%" + query + "%"
+		where = append(where, `(articles_fts.title LIKE ? OR articles_fts.translated_title LIKE ? OR articles_fts.author LIKE ? OR articles_fts.summary LIKE ? OR articles_fts.content LIKE ?)`)
+		args = append(args, pattern, pattern, pattern, pattern, pattern)
+	} else {
+		where = append(where, `articles_fts MATCH ?`)
+		args = append(args, query)
+	}
+
+	if !opts.ShowHidden {
+		where = append(where, `a.is_hidden = 0`)
+	}
+	if opts.FeedID > 0 {
+		where = append(where, `a.feed_id = ?`)
+		args = append(args, opts.FeedID)
+	} else if opts.Category == "\x00" {
+		where = append(where, `(f.category IS NULL OR f.category = '')`)
+	} else if opts.Category != "" {
+		where = append(where, `(f.category = ? OR f.category LIKE ?)`)
+		args = append(args, opts.Category, opts.Category+"/%")
+	}
+	if !opts.After.IsZero() {
+		where = append(where, `a.published_at >= ?`)
+		args = append(args, opts.After)
+	}
+	if !opts.Before.IsZero() {
+		where = append(where, `a.published_at < ?`)
+		args = append(args, opts.Before)
+	}
+
+	fromClause := `
+		FROM articles_fts
+		JOIN articles a ON a.id = articles_fts.rowid
+		JOIN feeds f ON a.feed_id = f.id
+		WHERE ` + strings.Join(where, " AND ")
+
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) `+fromClause, args...).Scan(&total); err != nil {
+		return nil, 0, wrapSearchError(err)
+	}
+
+	// Column weights follow the index column order: title, translated_title, author, summary, content
+	rankExpr := `bm25(articles_fts, 10.0, 8.0, 3.0, 4.0, 1.0)`
+	snippetExpr := fmt.Sprintf(`snippet(articles_fts, -1, '%s', '%s', '…', 48)`, ftsHighlightStart, ftsHighlightEnd)
+	orderBy := `relevance, a.published_at DESC`
+	if substringMode {
+		rankExpr = `0`
+		snippetExpr = `''`
+		orderBy = `a.published_at DESC`
+	}
+
+	selectQuery := `
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url,
+			   a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later,
+			   a.translated_title, a.summary, a.freshrss_item_id, f.title, a.author,
+			   ` + rankExpr + ` AS relevance, ` + snippetExpr + ` AS snippet` +
+		fromClause + `
+		ORDER BY ` + orderBy + `
+		LIMIT ? OFFSET ?`
+	args = append(args, opts.Limit, opts.Offset)
+
+	rows, err := db.Query(selectQuery, args...)
+	if err != nil {
+		return nil, 0, wrapSearchError(err)
+	}
+	defer rows.Close()
+
+	results := make([]ArticleSearchResult, 0)
+	for rows.Next() {
+		var r ArticleSearchResult
+		var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID, author sql.NullString
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&r.ID, &r.FeedID, &r.Title, &r.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &r.IsRead, &r.IsFavorite, &r.IsHidden, &r.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &r.FeedTitle, &author, &r.Rank, &r.Snippet); err != nil {
+			log.Println("Error scanning article in full-text search:", err)
+			continue
+		}
+		r.ImageURL = imageURL.String
+		r.AudioURL = audioURL.String
+		r.VideoURL = videoURL.String
+		if publishedAt.Valid {
+			r.PublishedAt = publishedAt.Time
+		}
+		r.TranslatedTitle = translatedTitle.String
+		r.Summary = summary.String
+		r.FreshRSSItemID = freshrssItemID.String
+		r.Author = author.String
+		r.Snippet = highlightSnippet(r.Snippet)
+		results = append(results, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("row iteration failed: %w", err)
+	}
+
+	return results, total, nil
+}
+
+// ftsQueryErrors are the messages of errors raised by the FTS5 query parser
+var ftsQueryErrors = []string{
+	"fts5: syntax error",
+	"unterminated string",
+	"unknown special query",
+	"expected integer", // NEAR(... , n)
+}
+
+// wrapSearchError marks FTS5 query parse errors so callers can report them as bad input.
+// SQLite reports these as generic SQL logic errors (e.g. "unterminated string"), like
+// real faults such as a missing table, which are returned as search failures.
+func wrapSearchError(err error) error {
+	msg := err.Error()
+	for _, queryErr := range ftsQueryErrors {
+		if strings.Contains(msg, queryErr) {
+			return fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+		}
+	}
+	return fmt.Errorf("search failed: %w", err)
+}
+
+// highlightSnippet escapes a raw FTS snippet and converts the highlight markers to <mark> tags
+func highlightSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, ftsHighlightStart, "<mark>")
+	snippet = strings.ReplaceAll(snippet, ftsHighlightEnd, "</mark>")
+	return snippet
+}
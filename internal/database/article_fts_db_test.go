@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+func TestSearchArticles(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.DB.Close()
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	techID, err := db.AddFeed(&models.Feed{Title: "Tech", URL: "https://tech.example.com/feed", Category: "Tech/Go"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	newsID, err := db.AddFeed(&models.Feed{Title: "News", URL: "https://news.example.com/feed", Category: "News"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+
+	now := time.Now()
+	articles := []*models.Article{
+		{FeedID: techID, Title: "Generics in Golang", URL: "https://tech.example.com/1", Author: "Gopher", PublishedAt: now},
+		{FeedID: techID, Title: "Weekly notes", URL: "https://tech.example.com/2", PublishedAt: now.AddDate(0, 0, -10)},
+		{FeedID: newsID, Title: "大语言模型详解", URL: "https://news.example.com/1", PublishedAt: now},
+	}
+	if err := db.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatalf("SaveArticles error: %v", err)
+	}
+
+	all, err := db.GetArticles("", 0, "", true, 10, 0)
+	if err != nil {
+		t.Fatalf("GetArticles error: %v", err)
+	}
+	ids := make(map[string]int64)
+	for _, a := range all {
+		ids[a.Title] = a.ID
+	}
+
+	search := func(opts ArticleSearchOptions) []ArticleSearchResult {
+		t.Helper()
+		results, total, err := db.SearchArticles(opts)
+		if err != nil {
+			t.Fatalf("SearchArticles(%q) error: %v", opts.Query, err)
+		}
+		if total < len(results) {
+			t.Fatalf("total %d is less than returned results %d", total, len(results))
+		}
+		return results
+	}
+
+	t.Run("title and author are indexed on insert", func(t *testing.T) {
+		if got := search(ArticleSearchOptions{Query: "golang"}); len(got) != 1 || got[0].Title != "Generics in Golang" {
+			t.Fatalf("unexpected results for title search: %+v", got)
+		}
+		if got := search(ArticleSearchOptions{Query: "author:gopher"}); len(got) != 1 {
+			t.Fatalf("expected 1 result for author search, got %d", len(got))
+		}
+		if got := search(ArticleSearchOptions{Query: "语言模型"}); len(got) != 1 {
+			t.Fatalf("expected 1 result for CJK search, got %d", len(got))
+		}
+	})
+
+	t.Run("cached content is indexed as plain text", func(t *testing.T) {
+		id := ids["Weekly notes"]
+		if err := db.SetArticleContent(id, `<div class="body"><p>Benchmarks for the <b>scheduler</b></p></div>`); err != nil {
+			t.Fatalf("SetArticleContent error: %v", err)
+		}
+		got := search(ArticleSearchOptions{Query: "scheduler"})
+		if len(got) != 1 || got[0].ID != id {
+			t.Fatalf("expected content match, got %+v", got)
+		}
+		if !strings.Contains(got[0].Snippet, "<mark>") || strings.Contains(got[0].Snippet, "<b>") {
+			t.Errorf("unexpected snippet: %q", got[0].Snippet)
+		}
+		if got := search(ArticleSearchOptions{Query: "class"}); len(got) != 0 {
+			t.Errorf("markup should not be searchable, got %d results", len(got))
+		}
+
+		if err := db.DeleteArticleContent(id); err != nil {
+			t.Fatalf("DeleteArticleContent error: %v", err)
+		}
+		if got := search(ArticleSearchOptions{Query: "scheduler"}); len(got) != 0 {
+			t.Errorf("expected no results after content deletion, got %d", len(got))
+		}
+	})
+
+	t.Run("updates and deletes keep the index in sync", func(t *testing.T) {
+		id := ids["Generics in Golang"]
+		if _, err := db.Exec(`UPDATE articles SET translated_title = ? WHERE id = ?`, "Génériques en Go", id); err != nil {
+			t.Fatalf("update error: %v", err)
+		}
+		if got := search(ArticleSearchOptions{Query: "riques"}); len(got) != 1 {
+			t.Errorf("expected translated title match, got %d", len(got))
+		}
+
+		if _, err := db.Exec(`DELETE FROM articles WHERE id = ?`, id); err != nil {
+			t.Fatalf("delete error: %v", err)
+		}
+		if got := search(ArticleSearchOptions{Query: "golang"}); len(got) != 0 {
+			t.Errorf("expected no results after article deletion, got %d", len(got))
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		if got := search(ArticleSearchOptions{Query: "notes OR 语言模型", Category: "Tech"}); len(got) != 1 || got[0].FeedID != techID {
+			t.Errorf("category filter failed: %+v", got)
+		}
+		if got := search(ArticleSearchOptions{Query: "notes OR 语言模型", FeedID: newsID}); len(got) != 1 || got[0].FeedID != newsID {
+			t.Errorf("feed filter failed: %+v", got)
+		}
+		if got := search(ArticleSearchOptions{Query: "notes OR 语言模型", After: now.AddDate(0, 0, -1)}); len(got) != 1 || got[0].FeedID != newsID {
+			t.Errorf("date filter failed: %+v", got)
+		}
+	})
+
+	t.Run("short queries fall back to substring search", func(t *testing.T) {
+		if got := search(ArticleSearchOptions{Query: "模型"}); len(got) != 1 {
+			t.Errorf("expected 1 result for short query, got %d", len(got))
+		}
+	})
+
+	t.Run("invalid syntax", func(t *testing.T) {
+		for _, query := range []string{`"unterminated`, `model AND`} {
+			_, _, err := db.SearchArticles(ArticleSearchOptions{Query: query})
+			if !errors.Is(err, ErrInvalidSearchQuery) {
+				t.Errorf("expected ErrInvalidSearchQuery for %q, got %v", query, err)
+			}
+		}
+	})
+
+	t.Run("database errors are not invalid queries", func(t *testing.T) {
+		if _, err := db.Exec(`DROP TABLE articles_fts`); err != nil {
+			t.Fatalf("drop failed: %v", err)
+		}
+		_, _, err := db.SearchArticles(ArticleSearchOptions{Query: "model"})
+		if err == nil || errors.Is(err, ErrInvalidSearchQuery) {
+			t.Errorf("expected a search failure, got %v", err)
+		}
+	})
+}
@@ -145,50 +145,3 @@ func (db *DB) SearchArticlesWithAI(whereClause string, limit int) ([]models.Arti
 
 	return articles, nil
 }
-
-// SearchArticlesWithSQL executes a complete SQL query for AI search with content and relevance scoring.
-// The query should include all necessary SELECT fields and be pre-validated.
-func (db *DB) SearchArticlesWithSQL(query string) ([]models.Article, error) {
-	db.WaitForReady()
-
-	if query == "" {
-		return nil, fmt.Errorf("empty query")
-	}
-
-	rows, err := db.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("query execution failed: %w", err)
-	}
-	defer rows.Close()
-
-	var articles []models.Article
-	for rows.Next() {
-		var a models.Article
-		var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID, author sql.NullString
-		var publishedAt sql.NullTime
-		var relevanceScore float64
-		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &a.FeedTitle, &author, &relevanceScore); err != nil {
-			log.Println("Error scanning article in AI search with SQL:", err)
-			continue
-		}
-		a.ImageURL = imageURL.String
-		a.AudioURL = audioURL.String
-		a.VideoURL = videoURL.String
-		if publishedAt.Valid {
-			a.PublishedAt = publishedAt.Time
-		} else {
-			a.PublishedAt = time.Time{}
-		}
-		a.TranslatedTitle = translatedTitle.String
-		a.Summary = summary.String
-		a.FreshRSSItemID = freshrssItemID.String
-		a.Author = author.String
-		articles = append(articles, a)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("row iteration failed: %w", err)
-	}
-
-	return articles, nil
-}
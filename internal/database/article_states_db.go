@@ -0,0 +1,158 @@
+package database
+
+import (
+	"database/sql"
+
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils/urlutil"
+)
+
+// GetArticleStates returns the articles of local feeds that are favorites, saved for later,
+// tagged or podcast episodes that were started or played, with the feed URL and tag names
+// that identify them across instances.
+func (db *DB) GetArticleStates() ([]models.ArticleState, error) {
+	db.WaitForReady()
+
+	rows, err := db.Query(`
+		SELECT a.id, f.url, a.url, a.title, a.published_at, a.is_favorite, a.is_read_later,
+			COALESCE(e.enclosure_url, ''), COALESCE(e.position, 0), COALESCE(e.played, 0)
+		FROM articles a
+		JOIN feeds f ON a.feed_id = f.id
+		LEFT JOIN podcast_episodes e ON e.article_id = a.id
+		WHERE f.is_freshrss_source = 0 AND (
+			a.is_favorite = 1 OR a.is_read_later = 1
+			OR a.id IN (SELECT article_id FROM article_tags)
+			OR e.position > 0 OR e.played = 1
+		)
+		ORDER BY a.published_at ASC, a.id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	var states []models.ArticleState
+	for rows.Next() {
+		var id int64
+		var s models.ArticleState
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&id, &s.FeedURL, &s.URL, &s.Title, &publishedAt, &s.IsFavorite, &s.IsReadLater,
+			&s.EnclosureURL, &s.PodcastPosition, &s.PodcastPlayed); err != nil {
+			return nil, err
+		}
+		if s.PodcastPosition == 0 && !s.PodcastPlayed {
+			s.EnclosureURL = ""
+		}
+		s.PublishedAt = publishedAt.Time
+		ids = append(ids, id)
+		states = append(states, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tags, err := db.GetTagsForArticles(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range ids {
+		for _, tag := range tags[id] {
+			states[i].Tags = append(states[i].Tags, tag.Name)
+		}
+	}
+
+	return states, nil
+}
+
+// RestoreArticleState applies a favorite and read-later state to the article of a feed with
+// the state's URL, or the same title when it has no URL. Missing articles are inserted so
+// the state survives until the feed is fetched. With overwrite the flags and the podcast
+// playback state are replaced, otherwise they are only ever set. Returns the article ID.
+func (db *DB) RestoreArticleState(feedID int64, state *models.ArticleState, overwrite bool) (int64, error) {
+	db.WaitForReady()
+
+	var id int64
+	var err error
+	if state.URL != "" {
+		err = db.QueryRow(`SELECT id FROM articles WHERE feed_id = ? AND url = ? ORDER BY id LIMIT 1`, feedID, state.URL).Scan(&id)
+	} else {
+		err = db.QueryRow(`SELECT id FROM articles WHERE feed_id = ? AND title = ? ORDER BY id LIMIT 1`, feedID, state.Title).Scan(&id)
+	}
+
+	if err == sql.ErrNoRows {
+		uniqueID := urlutil.GenerateArticleUniqueID(state.Title, feedID, state.PublishedAt, !state.PublishedAt.IsZero())
+		// Not published as a new article event, these are not new to the user
+		_, err = db.Exec(`INSERT OR IGNORE INTO articles (feed_id, title, url, audio_url, published_at, is_read, is_favorite, is_read_later, unique_id)
+			VALUES (?, ?, ?, ?, ?, 1, ?, ?, ?)`,
+			feedID, state.Title, state.URL, state.EnclosureURL, state.PublishedAt, state.IsFavorite, state.IsReadLater, uniqueID)
+		if err != nil {
+			return 0, err
+		}
+		err = db.QueryRow(`SELECT id FROM articles WHERE unique_id = ?`, uniqueID).Scan(&id)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if overwrite {
+		_, err = db.Exec(`UPDATE articles SET is_favorite = ?, is_read_later = ? WHERE id = ?`, state.IsFavorite, state.IsReadLater, id)
+	} else {
+		_, err = db.Exec(`UPDATE articles SET is_favorite = MAX(is_favorite, ?), is_read_later = MAX(is_read_later, ?) WHERE id = ?`, state.IsFavorite, state.IsReadLater, id)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if state.EnclosureURL != "" {
+		if err := db.restorePodcastProgress(id, feedID, state, overwrite); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+// restorePodcastProgress applies the playback state of an episode, creating the episode if
+// the article has none yet. Without overwrite a local position is kept.
+func (db *DB) restorePodcastProgress(articleID, feedID int64, state *models.ArticleState, overwrite bool) error {
+	_, err := db.Exec(`INSERT OR IGNORE INTO podcast_episodes (article_id, feed_id, enclosure_url) VALUES (?, ?, ?)`,
+		articleID, feedID, state.EnclosureURL)
+	if err != nil {
+		return err
+	}
+	if overwrite {
+		_, err = db.Exec(`UPDATE podcast_episodes SET position = ?, played = ? WHERE article_id = ?`,
+			state.PodcastPosition, state.PodcastPlayed, articleID)
+	} else {
+		_, err = db.Exec(`
+			UPDATE podcast_episodes SET position = CASE WHEN COALESCE(position, 0) > 0 THEN position ELSE ? END,
+				played = MAX(COALESCE(played, 0), ?)
+			WHERE article_id = ?`,
+			state.PodcastPosition, state.PodcastPlayed, articleID)
+	}
+	return err
+}
+
+// ClearArticleStates removes the favorite and read-later flags, the tags and the podcast
+// playback positions of all articles of local feeds.
+func (db *DB) ClearArticleStates() error {
+	db.WaitForReady()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	local := `SELECT id FROM feeds WHERE is_freshrss_source = 0`
+	if _, err := tx.Exec(`UPDATE articles SET is_favorite = 0, is_read_later = 0 WHERE feed_id IN (` + local + `)`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM article_tags WHERE article_id IN (SELECT id FROM articles WHERE feed_id IN (` + local + `))`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE podcast_episodes SET position = 0, played = 0 WHERE feed_id IN (` + local + `)`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
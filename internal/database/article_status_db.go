@@ -25,14 +25,24 @@ func (db *DB) ToggleFavorite(id int64) error {
 		return err
 	}
 	_, err = db.Exec("UPDATE articles SET is_favorite = ? WHERE id = ?", !isFav, id)
+	if err == nil && !isFav {
+		db.publish(Event{Type: EventArticleStarred, ArticleIDs: []int64{id}})
+	}
 	return err
 }
 
 // SetArticleFavorite sets the favorite status of an article.
 func (db *DB) SetArticleFavorite(id int64, favorite bool) error {
 	db.WaitForReady()
-	_, err := db.Exec("UPDATE articles SET is_favorite = ? WHERE id = ?", favorite, id)
-	return err
+	// Only the change to favorite publishes an event, so setting it twice does not notify twice
+	result, err := db.Exec("UPDATE articles SET is_favorite = ? WHERE id = ? AND is_favorite != ?", favorite, id, favorite)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n > 0 && favorite {
+		db.publish(Event{Type: EventArticleStarred, ArticleIDs: []int64{id}})
+	}
+	return nil
 }
 
 // ToggleArticleHidden toggles the is_hidden status of an article.
@@ -69,6 +79,9 @@ func (db *DB) ToggleReadLater(id int64) error {
 	// If adding to read later, also mark as unread
 	if newState {
 		_, err = db.Exec("UPDATE articles SET is_read_later = 1, is_read = 0 WHERE id = ?", id)
+		if err == nil {
+			db.publish(Event{Type: EventArticleReadLater, ArticleIDs: []int64{id}})
+		}
 	} else {
 		_, err = db.Exec("UPDATE articles SET is_read_later = 0 WHERE id = ?", id)
 	}
@@ -81,7 +94,12 @@ func (db *DB) SetArticleReadLater(id int64, readLater bool) error {
 	db.WaitForReady()
 	// If adding to read later, also mark as unread
 	if readLater {
+		var wasReadLater bool
+		_ = db.QueryRow("SELECT is_read_later FROM articles WHERE id = ?", id).Scan(&wasReadLater)
 		_, err := db.Exec("UPDATE articles SET is_read_later = 1, is_read = 0 WHERE id = ?", id)
+		if err == nil && !wasReadLater {
+			db.publish(Event{Type: EventArticleReadLater, ArticleIDs: []int64{id}})
+		}
 		return err
 	}
 	_, err := db.Exec("UPDATE articles SET is_read_later = 0 WHERE id = ?", id)
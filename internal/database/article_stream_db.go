@@ -0,0 +1,198 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// ArticleStreamQuery selects articles for the sync APIs (Google Reader and Fever).
+// Zero values mean "no restriction". Hidden articles are never included.
+type ArticleStreamQuery struct {
+	FeedID   int64
+	Category string // Exact category match, "\x00" for uncategorized
+	IDs      []int64
+	Unread   bool // Only unread articles
+	Read     bool // Only read articles
+	Starred  bool // Only favorite articles
+	Since    time.Time
+	Until    time.Time
+	SinceID  int64 // Only articles with a greater ID
+	MaxID    int64 // Only articles with a smaller ID
+	// Results are ordered newest first by published time. OrderByID orders by
+	// ID instead, and OldestFirst reverses the direction.
+	OldestFirst bool
+	OrderByID   bool
+	Limit       int // 0 means no limit
+	Offset      int
+}
+
+// where builds the WHERE clause (without the keyword) for the query
+func (q ArticleStreamQuery) where() (string, []interface{}) {
+	clauses := []string{"a.is_hidden = 0"}
+	var args []interface{}
+
+	if q.FeedID > 0 {
+		clauses = append(clauses, "a.feed_id = ?")
+		args = append(args, q.FeedID)
+	}
+	if q.Category == "\x00" {
+		clauses = append(clauses, "a.feed_id IN (SELECT id FROM feeds WHERE category IS NULL OR category = '')")
+	} else if q.Category != "" {
+		clauses = append(clauses, "a.feed_id IN (SELECT id FROM feeds WHERE category = ?)")
+		args = append(args, q.Category)
+	}
+	if len(q.IDs) > 0 {
+		placeholders := make([]string, len(q.IDs))
+		for i, id := range q.IDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		clauses = append(clauses, "a.id IN ("+strings.Join(placeholders, ",")+")")
+	}
+	if q.Unread {
+		clauses = append(clauses, "a.is_read = 0")
+	}
+	if q.Read {
+		clauses = append(clauses, "a.is_read = 1")
+	}
+	if q.Starred {
+		clauses = append(clauses, "a.is_favorite = 1")
+	}
+	if !q.Since.IsZero() {
+		clauses = append(clauses, "a.published_at >= ?")
+		args = append(args, q.Since)
+	}
+	if !q.Until.IsZero() {
+		clauses = append(clauses, "a.published_at <= ?")
+		args = append(args, q.Until)
+	}
+	if q.SinceID > 0 {
+		clauses = append(clauses, "a.id > ?")
+		args = append(args, q.SinceID)
+	}
+	if q.MaxID > 0 {
+		clauses = append(clauses, "a.id < ?")
+		args = append(args, q.MaxID)
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
+
+// orderBy builds the ORDER BY clause for the query
+func (q ArticleStreamQuery) orderBy() string {
+	direction := "DESC"
+	if q.OldestFirst {
+		direction = "ASC"
+	}
+	if q.OrderByID {
+		return " ORDER BY a.id " + direction
+	}
+	return " ORDER BY a.published_at " + direction + ", a.id " + direction
+}
+
+// GetArticleStream retrieves the articles matching the query.
+func (db *DB) GetArticleStream(q ArticleStreamQuery) ([]models.Article, error) {
+	db.WaitForReady()
+
+	where, args := q.where()
+	query := `
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, a.author
+		FROM articles a
+		JOIN feeds f ON a.feed_id = f.id
+		WHERE ` + where
+
+	query += q.orderBy()
+	if q.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, q.Limit, q.Offset)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStreamArticles(rows)
+}
+
+// scanStreamArticles scans rows selected with the column list used by GetArticleStream
+func scanStreamArticles(rows *sql.Rows) ([]models.Article, error) {
+	articles := []models.Article{}
+	for rows.Next() {
+		var a models.Article
+		var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID, author sql.NullString
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &a.FeedTitle, &author); err != nil {
+			return nil, err
+		}
+		a.ImageURL = imageURL.String
+		a.AudioURL = audioURL.String
+		a.VideoURL = videoURL.String
+		if publishedAt.Valid {
+			a.PublishedAt = publishedAt.Time
+		}
+		a.TranslatedTitle = translatedTitle.String
+		a.Summary = summary.String
+		a.FreshRSSItemID = freshrssItemID.String
+		a.Author = author.String
+		articles = append(articles, a)
+	}
+	return articles, rows.Err()
+}
+
+// GetArticleStreamIDs retrieves only the IDs and published times of the articles matching the query.
+func (db *DB) GetArticleStreamIDs(q ArticleStreamQuery) ([]int64, []time.Time, error) {
+	db.WaitForReady()
+
+	where, args := q.where()
+	query := `SELECT a.id, a.published_at FROM articles a WHERE ` + where
+	query += q.orderBy()
+	if q.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, q.Limit, q.Offset)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	times := []time.Time{}
+	for rows.Next() {
+		var id int64
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&id, &publishedAt); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		times = append(times, publishedAt.Time)
+	}
+	return ids, times, rows.Err()
+}
+
+// CountArticleStream returns the number of articles matching the query, ignoring Limit and Offset.
+func (db *DB) CountArticleStream(q ArticleStreamQuery) (int, error) {
+	db.WaitForReady()
+	where, args := q.where()
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM articles a WHERE `+where, args...).Scan(&count)
+	return count, err
+}
+
+// MarkArticleStreamRead marks all articles matching the query as read and returns the number of changed articles.
+func (db *DB) MarkArticleStreamRead(q ArticleStreamQuery) (int64, error) {
+	db.WaitForReady()
+	q.Unread = true
+	where, args := q.where()
+	result, err := db.Exec(`UPDATE articles AS a SET is_read = 1 WHERE `+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
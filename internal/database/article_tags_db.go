@@ -0,0 +1,219 @@
+package database
+
+import (
+	"strings"
+
+	"MrRSS/internal/models"
+)
+
+// maxQueryParams keeps IN (...) lists below SQLite's host parameter limit
+const maxQueryParams = 500
+
+// AddArticleTag assigns a tag to an article. Assigning a tag twice has no effect.
+func (db *DB) AddArticleTag(articleID, tagID int64) error {
+	db.WaitForReady()
+
+	_, err := db.Exec(`INSERT OR IGNORE INTO article_tags (article_id, tag_id) VALUES (?, ?)`, articleID, tagID)
+	return err
+}
+
+// RemoveArticleTag removes a tag from an article.
+func (db *DB) RemoveArticleTag(articleID, tagID int64) error {
+	db.WaitForReady()
+
+	_, err := db.Exec(`DELETE FROM article_tags WHERE article_id = ? AND tag_id = ?`, articleID, tagID)
+	return err
+}
+
+// GetArticleTags retrieves all tags of an article ordered by position.
+func (db *DB) GetArticleTags(articleID int64) ([]models.Tag, error) {
+	db.WaitForReady()
+
+	query := `
+		SELECT t.id, t.name, t.color, t.position
+		FROM tags t
+		INNER JOIN article_tags at ON t.id = at.tag_id
+		WHERE at.article_id = ?
+		ORDER BY t.position ASC, t.id ASC
+	`
+	rows, err := db.Query(query, articleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color, &tag.Position); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// SetArticleTags replaces all tags of an article with the provided tag IDs.
+func (db *DB) SetArticleTags(articleID int64, tagIDs []int64) error {
+	db.WaitForReady()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM article_tags WHERE article_id = ?`, articleID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO article_tags (article_id, tag_id) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, tagID := range tagIDs {
+		if _, err := stmt.Exec(articleID, tagID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// BulkUpdateArticleTags adds and removes tags on many articles in one transaction.
+// Tags in both lists end up removed.
+func (db *DB) BulkUpdateArticleTags(articleIDs, addTagIDs, removeTagIDs []int64) error {
+	db.WaitForReady()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	addStmt, err := tx.Prepare(`INSERT OR IGNORE INTO article_tags (article_id, tag_id) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer addStmt.Close()
+
+	removeStmt, err := tx.Prepare(`DELETE FROM article_tags WHERE article_id = ? AND tag_id = ?`)
+	if err != nil {
+		return err
+	}
+	defer removeStmt.Close()
+
+	for _, articleID := range articleIDs {
+		for _, tagID := range addTagIDs {
+			if _, err := addStmt.Exec(articleID, tagID); err != nil {
+				return err
+			}
+		}
+		for _, tagID := range removeTagIDs {
+			if _, err := removeStmt.Exec(articleID, tagID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetTagsForArticles returns the tags of the given articles, keyed by article ID.
+// Articles without tags are not included in the map.
+func (db *DB) GetTagsForArticles(articleIDs []int64) (map[int64][]models.Tag, error) {
+	db.WaitForReady()
+
+	result := make(map[int64][]models.Tag)
+	for start := 0; start < len(articleIDs); start += maxQueryParams {
+		end := start + maxQueryParams
+		if end > len(articleIDs) {
+			end = len(articleIDs)
+		}
+		batch := articleIDs[start:end]
+
+		placeholders := make([]string, len(batch))
+		args := make([]interface{}, len(batch))
+		for i, id := range batch {
+			placeholders[i] = "?"
+			args[i] = id
+		}
+
+		rows, err := db.Query(`
+			SELECT at.article_id, t.id, t.name, t.color, t.position
+			FROM article_tags at
+			INNER JOIN tags t ON t.id = at.tag_id
+			WHERE at.article_id IN (`+strings.Join(placeholders, ",")+`)
+			ORDER BY t.position ASC, t.id ASC
+		`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var articleID int64
+			var tag models.Tag
+			if err := rows.Scan(&articleID, &tag.ID, &tag.Name, &tag.Color, &tag.Position); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			result[articleID] = append(result[articleID], tag)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// GetArticlesByTag retrieves the visible articles with a tag, newest first.
+func (db *DB) GetArticlesByTag(tagID int64, limit, offset int) ([]models.Article, error) {
+	db.WaitForReady()
+
+	query := `
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, a.author
+		FROM articles a
+		JOIN feeds f ON a.feed_id = f.id
+		INNER JOIN article_tags at ON at.article_id = a.id
+		WHERE at.tag_id = ? AND a.is_hidden = 0
+		ORDER BY a.published_at DESC, a.id DESC
+		LIMIT ? OFFSET ?
+	`
+	rows, err := db.Query(query, tagID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStreamArticles(rows)
+}
+
+// GetArticlesWithTag retrieves the visible articles that have a tag themselves or belong
+// to a feed with the tag, newest first.
+func (db *DB) GetArticlesWithTag(tagID int64, limit, offset int) ([]models.Article, error) {
+	db.WaitForReady()
+
+	query := `
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, a.author
+		FROM articles a
+		JOIN feeds f ON a.feed_id = f.id
+		WHERE a.is_hidden = 0 AND (
+			a.id IN (SELECT article_id FROM article_tags WHERE tag_id = ?)
+			OR a.feed_id IN (SELECT feed_id FROM feed_tags WHERE tag_id = ?)
+		)
+		ORDER BY a.published_at DESC, a.id DESC
+		LIMIT ? OFFSET ?
+	`
+	rows, err := db.Query(query, tagID, tagID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStreamArticles(rows)
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+func TestArticleTags(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.DB.Close()
+
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	articles := []*models.Article{
+		{FeedID: feedID, Title: "First", URL: "https://example.com/1", PublishedAt: time.Now()},
+		{FeedID: feedID, Title: "Second", URL: "https://example.com/2", PublishedAt: time.Now()},
+	}
+	if err := db.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatalf("SaveArticles failed: %v", err)
+	}
+
+	var ids []int64
+	rows, err := db.Query(`SELECT id FROM articles ORDER BY url`)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	for rows.Next() {
+		var id int64
+		rows.Scan(&id)
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if len(ids) != 2 {
+		t.Fatalf("Expected 2 articles, got %d", len(ids))
+	}
+
+	work, _ := db.AddTag(&models.Tag{Name: "Work", Color: "#ff0000"})
+	later, _ := db.AddTag(&models.Tag{Name: "Later", Color: "#00ff00"})
+
+	t.Run("Add and remove", func(t *testing.T) {
+		if err := db.AddArticleTag(ids[0], work); err != nil {
+			t.Fatalf("AddArticleTag failed: %v", err)
+		}
+		if err := db.AddArticleTag(ids[0], work); err != nil {
+			t.Fatalf("AddArticleTag twice failed: %v", err)
+		}
+		tags, err := db.GetArticleTags(ids[0])
+		if err != nil {
+			t.Fatalf("GetArticleTags failed: %v", err)
+		}
+		if len(tags) != 1 || tags[0].Name != "Work" {
+			t.Errorf("Unexpected tags: %+v", tags)
+		}
+
+		if err := db.RemoveArticleTag(ids[0], work); err != nil {
+			t.Fatalf("RemoveArticleTag failed: %v", err)
+		}
+		tags, _ = db.GetArticleTags(ids[0])
+		if len(tags) != 0 {
+			t.Errorf("Expected no tags, got %+v", tags)
+		}
+	})
+
+	t.Run("Bulk update", func(t *testing.T) {
+		if err := db.BulkUpdateArticleTags(ids, []int64{work, later}, nil); err != nil {
+			t.Fatalf("BulkUpdateArticleTags add failed: %v", err)
+		}
+		if err := db.BulkUpdateArticleTags(ids[1:], nil, []int64{work}); err != nil {
+			t.Fatalf("BulkUpdateArticleTags remove failed: %v", err)
+		}
+
+		byArticle, err := db.GetTagsForArticles(ids)
+		if err != nil {
+			t.Fatalf("GetTagsForArticles failed: %v", err)
+		}
+		if len(byArticle[ids[0]]) != 2 {
+			t.Errorf("Expected 2 tags on first article, got %+v", byArticle[ids[0]])
+		}
+		if len(byArticle[ids[1]]) != 1 || byArticle[ids[1]][0].ID != later {
+			t.Errorf("Expected only Later on second article, got %+v", byArticle[ids[1]])
+		}
+
+		tagged, err := db.GetArticlesByTag(work, 10, 0)
+		if err != nil {
+			t.Fatalf("GetArticlesByTag failed: %v", err)
+		}
+		if len(tagged) != 1 || tagged[0].ID != ids[0] {
+			t.Errorf("Unexpected articles for tag: %+v", tagged)
+		}
+	})
+
+	t.Run("Cleanup on delete", func(t *testing.T) {
+		if err := db.DeleteTag(later); err != nil {
+			t.Fatalf("DeleteTag failed: %v", err)
+		}
+		if _, err := db.Exec(`DELETE FROM articles WHERE id = ?`, ids[0]); err != nil {
+			t.Fatalf("Delete article failed: %v", err)
+		}
+
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM article_tags`).Scan(&count); err != nil {
+			t.Fatalf("Count failed: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("Expected article tags to be cleaned up, %d left", count)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// ArticleTranslation is a stored full-article translation
+type ArticleTranslation struct {
+	ArticleID      int64
+	TargetLanguage string
+	SourceHash     string   // Hash of the content the blocks were translated from
+	Blocks         []string // Translated HTML of each block, empty for blocks not translated yet
+	Complete       bool
+	UpdatedAt      time.Time
+}
+
+// GetArticleTranslation retrieves the translation of an article's content into a language
+func (db *DB) GetArticleTranslation(articleID int64, targetLang string) (*ArticleTranslation, error) {
+	db.WaitForReady()
+	t := &ArticleTranslation{ArticleID: articleID, TargetLanguage: targetLang}
+	var blocks string
+	err := db.QueryRow(
+		`SELECT source_hash, blocks, complete, updated_at FROM article_translations
+		WHERE article_id = ? AND target_language = ?`,
+		articleID, targetLang,
+	).Scan(&t.SourceHash, &blocks, &t.Complete, &t.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(blocks), &t.Blocks); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SaveArticleTranslation stores or replaces the translation of an article's content
+func (db *DB) SaveArticleTranslation(t *ArticleTranslation) error {
+	db.WaitForReady()
+	blocks, err := json.Marshal(t.Blocks)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(
+		`INSERT OR REPLACE INTO article_translations (article_id, target_language, source_hash, blocks, complete, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		t.ArticleID, t.TargetLanguage, t.SourceHash, string(blocks), t.Complete,
+	)
+	return err
+}
+
+// ClearArticleTranslations removes all full-article translations
+func (db *DB) ClearArticleTranslations() error {
+	db.WaitForReady()
+	_, err := db.Exec(`DELETE FROM article_translations`)
+	return err
+}
+
+// deleteOrphanArticleTranslations removes translations whose article content is no longer cached
+func (db *DB) deleteOrphanArticleTranslations() {
+	_, _ = db.Exec(`DELETE FROM article_translations WHERE article_id NOT IN (SELECT article_id FROM article_contents)`)
+}
@@ -56,3 +56,28 @@ func (db *DB) CleanupTranslationCache(maxAgeDays int) (int64, error) {
 	}
 	return result.RowsAffected()
 }
+
+// AddTranslationUsage adds to the characters translated by a provider in a month ("2006-01")
+func (db *DB) AddTranslationUsage(provider, month string, characters int) error {
+	db.WaitForReady()
+	_, err := db.Exec(
+		`INSERT INTO translation_usage (provider, month, characters) VALUES (?, ?, ?)
+		 ON CONFLICT(provider, month) DO UPDATE SET characters = characters + excluded.characters`,
+		provider, month, characters,
+	)
+	return err
+}
+
+// GetTranslationUsage returns the characters translated by a provider in a month ("2006-01")
+func (db *DB) GetTranslationUsage(provider, month string) (int64, error) {
+	db.WaitForReady()
+	var characters int64
+	err := db.QueryRow(
+		`SELECT characters FROM translation_usage WHERE provider = ? AND month = ?`,
+		provider, month,
+	).Scan(&characters)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return characters, err
+}
@@ -7,7 +7,7 @@ import (
 )
 
 // CleanupOldArticles removes articles based on age and status.
-// - Articles older than configured days: delete except favorited or read later
+// - Articles older than configured days: delete except favorited, read later or annotated
 // - Also checks database size against max_cache_size_mb setting
 func (db *DB) CleanupOldArticles() (int64, error) {
 	db.WaitForReady()
@@ -25,12 +25,13 @@ func (db *DB) CleanupOldArticles() (int64, error) {
 
 	cutoffDate := time.Now().AddDate(0, 0, -maxAgeDays)
 
-	// Delete articles older than configured age that are not favorited or in read later
+	// Delete articles older than configured age that are not favorited, in read later or annotated
 	result, err := db.Exec(`
 		DELETE FROM articles
 		WHERE published_at < ?
 		AND is_favorite = 0
 		AND is_read_later = 0
+		AND id NOT IN (SELECT article_id FROM highlights)
 	`, cutoffDate)
 	if err != nil {
 		return 0, err
@@ -64,6 +65,7 @@ func (db *DB) CleanupAllArticleContents() (int64, error) {
 	if err != nil {
 		return 0, err
 	}
+	db.deleteOrphanArticleTranslations()
 	return result.RowsAffected()
 }
 
@@ -78,7 +80,7 @@ func (db *DB) DeleteAllArticles() (int64, error) {
 	return result.RowsAffected()
 }
 
-// CleanupUnimportantArticles removes all articles except read, favorited, and read later ones.
+// CleanupUnimportantArticles removes all articles except read, favorited, read later and annotated ones.
 func (db *DB) CleanupUnimportantArticles() (int64, error) {
 	db.WaitForReady()
 
@@ -87,6 +89,7 @@ func (db *DB) CleanupUnimportantArticles() (int64, error) {
 		WHERE is_read = 0
 		AND is_favorite = 0
 		AND is_read_later = 0
+		AND id NOT IN (SELECT article_id FROM highlights)
 	`)
 	if err != nil {
 		return 0, err
@@ -151,8 +154,9 @@ func (db *DB) ShouldCleanupBeforeSave() (bool, error) {
 }
 
 // CleanupBySize removes oldest articles to keep database under max_cache_size_mb limit.
-// Protects favorited and read later articles.
-// Uses priority order: oldest read articles first, then older unread articles.
+// Protects favorited, read later and annotated articles.
+// Uses priority order: oldest full-article translations first, which can be translated
+// again, then oldest read articles, then older unread articles.
 func (db *DB) CleanupBySize() (int64, error) {
 	db.WaitForReady()
 
@@ -181,7 +185,31 @@ func (db *DB) CleanupBySize() (int64, error) {
 	totalDeleted := int64(0)
 	targetSizeMB := float64(maxSizeMB) * 0.95 // Aim for 95% of limit
 
-	// Step 1: Delete oldest read articles (not favorited, not read later)
+	// Step 1: Delete oldest full-article translations (not of annotated articles)
+	for currentSizeMB > targetSizeMB {
+		result, err := db.Exec(`
+			DELETE FROM article_translations
+			WHERE rowid IN (
+				SELECT rowid FROM article_translations
+				WHERE article_id NOT IN (SELECT article_id FROM highlights)
+				ORDER BY updated_at ASC
+				LIMIT 100
+			)
+		`)
+		if err != nil {
+			break
+		}
+
+		count, _ := result.RowsAffected()
+		if count == 0 {
+			break // No more translations to delete
+		}
+
+		currentSizeMB, _ = db.GetDatabaseSizeMB()
+		log.Printf("Deleted %d article translations, current size: %.2f MB", count, currentSizeMB)
+	}
+
+	// Step 2: Delete oldest read articles (not favorited, not read later, not annotated)
 	for currentSizeMB > targetSizeMB {
 		result, err := db.Exec(`
 			DELETE FROM articles
@@ -190,6 +218,7 @@ func (db *DB) CleanupBySize() (int64, error) {
 				WHERE is_read = 1
 				AND is_favorite = 0
 				AND is_read_later = 0
+				AND id NOT IN (SELECT article_id FROM highlights)
 				ORDER BY published_at ASC
 				LIMIT 100
 			)
@@ -208,7 +237,7 @@ func (db *DB) CleanupBySize() (int64, error) {
 		log.Printf("Deleted %d read articles, current size: %.2f MB", count, currentSizeMB)
 	}
 
-	// Step 2: If still over limit, delete oldest unread articles (not favorited, not read later)
+	// Step 3: If still over limit, delete oldest unread articles (not favorited, not read later, not annotated)
 	for currentSizeMB > targetSizeMB {
 		result, err := db.Exec(`
 			DELETE FROM articles
@@ -216,6 +245,7 @@ func (db *DB) CleanupBySize() (int64, error) {
 				SELECT id FROM articles
 				WHERE is_favorite = 0
 				AND is_read_later = 0
+				AND id NOT IN (SELECT article_id FROM highlights)
 				ORDER BY published_at ASC
 				LIMIT 100
 			)
@@ -242,21 +272,24 @@ func (db *DB) CleanupBySize() (int64, error) {
 }
 
 // CleanupArticleContentsByAge removes article content cache entries older than maxAgeDays
-// This only deletes content, not article metadata
+// This only deletes content, not article metadata. Content of annotated articles is kept.
 func (db *DB) CleanupArticleContentsByAge(maxAgeDays int) (int64, error) {
 	db.WaitForReady()
 	result, err := db.Exec(
-		`DELETE FROM article_contents WHERE fetched_at < datetime('now', '-' || ? || ' days')`,
+		`DELETE FROM article_contents
+		WHERE fetched_at < datetime('now', '-' || ? || ' days')
+		AND article_id NOT IN (SELECT article_id FROM highlights)`,
 		maxAgeDays,
 	)
 	if err != nil {
 		return 0, err
 	}
+	db.deleteOrphanArticleTranslations()
 	return result.RowsAffected()
 }
 
 // CleanupArticleContentsBySize removes oldest article contents to reduce database size
-// This only deletes content, not article metadata
+// This only deletes content, not article metadata. Content of annotated articles is kept.
 func (db *DB) CleanupArticleContentsBySize() (int64, error) {
 	db.WaitForReady()
 
@@ -289,6 +322,7 @@ func (db *DB) CleanupArticleContentsBySize() (int64, error) {
 			DELETE FROM article_contents
 			WHERE article_id IN (
 				SELECT article_id FROM article_contents
+				WHERE article_id NOT IN (SELECT article_id FROM highlights)
 				ORDER BY fetched_at ASC
 				LIMIT 100
 			)
@@ -306,14 +340,18 @@ func (db *DB) CleanupArticleContentsBySize() (int64, error) {
 		currentSizeMB, _ = db.GetDatabaseSizeMB()
 	}
 
+	if totalDeleted > 0 {
+		db.deleteOrphanArticleTranslations()
+	}
+
 	return totalDeleted, nil
 }
 
 // CleanupOldArticlesLayered removes articles in layers:
-// Layer 1: Read articles older than 30 days (not favorited/read later)
-// Layer 2: Read articles older than 14 days (not favorited/read later)
-// Layer 3: Unread articles older than 90 days (not favorited/read later)
-// Layer 4: Unread articles older than 60 days (not favorited/read later)
+// Layer 1: Read articles older than 30 days (not favorited/read later/annotated)
+// Layer 2: Read articles older than 14 days (not favorited/read later/annotated)
+// Layer 3: Unread articles older than 90 days (not favorited/read later/annotated)
+// Layer 4: Unread articles older than 60 days (not favorited/read later/annotated)
 func (db *DB) CleanupOldArticlesLayered() (int64, error) {
 	db.WaitForReady()
 
@@ -336,6 +374,7 @@ func (db *DB) CleanupOldArticlesLayered() (int64, error) {
 		AND is_read = 1
 		AND is_favorite = 0
 		AND is_read_later = 0
+		AND id NOT IN (SELECT article_id FROM highlights)
 	`, cutoffDate)
 	if err == nil {
 		count, _ := result.RowsAffected()
@@ -353,6 +392,7 @@ func (db *DB) CleanupOldArticlesLayered() (int64, error) {
 		AND is_read = 1
 		AND is_favorite = 0
 		AND is_read_later = 0
+		AND id NOT IN (SELECT article_id FROM highlights)
 	`, cutoffDate)
 	if err == nil {
 		count, _ := result.RowsAffected()
@@ -370,6 +410,7 @@ func (db *DB) CleanupOldArticlesLayered() (int64, error) {
 		AND is_read = 0
 		AND is_favorite = 0
 		AND is_read_later = 0
+		AND id NOT IN (SELECT article_id FROM highlights)
 	`, cutoffDate)
 	if err == nil {
 		count, _ := result.RowsAffected()
@@ -387,6 +428,7 @@ func (db *DB) CleanupOldArticlesLayered() (int64, error) {
 		AND is_read = 0
 		AND is_favorite = 0
 		AND is_read_later = 0
+		AND id NOT IN (SELECT article_id FROM highlights)
 	`, cutoffDate)
 	if err == nil {
 		count, _ := result.RowsAffected()
@@ -405,7 +447,7 @@ func (db *DB) CleanupOldArticlesLayered() (int64, error) {
 }
 
 // CleanupOldReadArticles removes read articles older than specified days
-// Protects favorited and read later articles
+// Protects favorited, read later and annotated articles
 func (db *DB) CleanupOldReadArticles(maxAgeDays int) (int64, error) {
 	db.WaitForReady()
 
@@ -416,6 +458,7 @@ func (db *DB) CleanupOldReadArticles(maxAgeDays int) (int64, error) {
 		AND is_read = 1
 		AND is_favorite = 0
 		AND is_read_later = 0
+		AND id NOT IN (SELECT article_id FROM highlights)
 	`, cutoffDate)
 	if err != nil {
 		return 0, err
@@ -426,7 +469,7 @@ func (db *DB) CleanupOldReadArticles(maxAgeDays int) (int64, error) {
 }
 
 // CleanupOldUnreadArticles removes unread articles older than specified days
-// Protects favorited and read later articles
+// Protects favorited, read later and annotated articles
 func (db *DB) CleanupOldUnreadArticles(maxAgeDays int) (int64, error) {
 	db.WaitForReady()
 
@@ -437,6 +480,7 @@ func (db *DB) CleanupOldUnreadArticles(maxAgeDays int) (int64, error) {
 		AND is_read = 0
 		AND is_favorite = 0
 		AND is_read_later = 0
+		AND id NOT IN (SELECT article_id FROM highlights)
 	`, cutoffDate)
 	if err != nil {
 		return 0, err
@@ -2,18 +2,58 @@ package database
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
 )
 
+// regexpCache holds the patterns compiled by the REGEXP function
+var regexpCache sync.Map
+
+func init() {
+	// SQLite has no built-in REGEXP function; "X REGEXP Y" calls regexp(Y, X)
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		pattern, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("regexp: pattern must be text")
+		}
+		var value string
+		switch v := args[1].(type) {
+		case nil:
+			return false, nil
+		case string:
+			value = v
+		case []byte:
+			value = string(v)
+		default:
+			value = fmt.Sprint(v)
+		}
+
+		re, ok := regexpCache.Load(pattern)
+		if !ok {
+			compiled, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+			re, _ = regexpCache.LoadOrStore(pattern, compiled)
+		}
+		return re.(*regexp.Regexp).MatchString(value), nil
+	})
+}
+
 // DB wraps sql.DB with initialization state tracking.
 type DB struct {
 	*sql.DB
 	ready chan struct{}
 	once  sync.Once
+
+	listenerMu    sync.RWMutex
+	eventListener func(Event)
 }
 
 // NewDB creates a new database connection with optimized settings.
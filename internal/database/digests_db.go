@@ -0,0 +1,190 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// Digest run statuses
+const (
+	DigestRunSuccess = "success"
+	DigestRunFailed  = "failed"
+)
+
+const digestColumns = `id, name, source_type, COALESCE(source_id, 0), COALESCE(category, ''), window_hours, schedule, hour, weekday, publish_to_feed, enabled, last_run_at, created_at, updated_at`
+
+const digestRunColumns = `id, digest_id, COALESCE(title, ''), COALESCE(content, ''), citations, article_count, status, COALESCE(error, ''), COALESCE(article_id, 0), period_start, period_end, created_at`
+
+// GetDigests retrieves all digests
+func (db *DB) GetDigests() ([]models.Digest, error) {
+	db.WaitForReady()
+
+	rows, err := db.Query(`SELECT ` + digestColumns + ` FROM digests ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	digests := make([]models.Digest, 0)
+	for rows.Next() {
+		d, err := scanDigest(rows)
+		if err != nil {
+			return nil, err
+		}
+		digests = append(digests, *d)
+	}
+	return digests, rows.Err()
+}
+
+// GetDigest retrieves a digest by ID, or nil if it does not exist
+func (db *DB) GetDigest(id int64) (*models.Digest, error) {
+	db.WaitForReady()
+
+	d, err := scanDigest(db.QueryRow(`SELECT `+digestColumns+` FROM digests WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return d, err
+}
+
+// AddDigest stores a new digest and returns its ID
+func (db *DB) AddDigest(d *models.Digest) (int64, error) {
+	db.WaitForReady()
+
+	result, err := db.Exec(`
+		INSERT INTO digests (name, source_type, source_id, category, window_hours, schedule, hour, weekday, publish_to_feed, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, d.Name, d.SourceType, d.SourceID, d.Category, d.WindowHours, d.Schedule, d.Hour, d.Weekday, d.PublishToFeed, d.Enabled)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateDigest updates a digest's configuration
+func (db *DB) UpdateDigest(d *models.Digest) error {
+	db.WaitForReady()
+
+	_, err := db.Exec(`
+		UPDATE digests SET name = ?, source_type = ?, source_id = ?, category = ?, window_hours = ?, schedule = ?, hour = ?, weekday = ?,
+			publish_to_feed = ?, enabled = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, d.Name, d.SourceType, d.SourceID, d.Category, d.WindowHours, d.Schedule, d.Hour, d.Weekday, d.PublishToFeed, d.Enabled, d.ID)
+	return err
+}
+
+// DeleteDigest removes a digest and its history. Articles published to the Digests feed are kept.
+func (db *DB) DeleteDigest(id int64) error {
+	db.WaitForReady()
+
+	if _, err := db.Exec(`DELETE FROM digest_runs WHERE digest_id = ?`, id); err != nil {
+		return err
+	}
+	_, err := db.Exec(`DELETE FROM digests WHERE id = ?`, id)
+	return err
+}
+
+// UpdateDigestLastRun records when a digest was last generated
+func (db *DB) UpdateDigestLastRun(id int64, lastRun time.Time) error {
+	db.WaitForReady()
+
+	_, err := db.Exec(`UPDATE digests SET last_run_at = ? WHERE id = ?`, lastRun.UTC(), id)
+	return err
+}
+
+// AddDigestRun stores a generated digest and returns its ID
+func (db *DB) AddDigestRun(run *models.DigestRun) (int64, error) {
+	db.WaitForReady()
+
+	citations, err := json.Marshal(run.Citations)
+	if err != nil {
+		return 0, err
+	}
+	if run.Citations == nil {
+		citations = []byte("[]")
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO digest_runs (digest_id, title, content, citations, article_count, status, error, article_id, period_start, period_end, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.DigestID, run.Title, run.Content, string(citations), run.ArticleCount, run.Status, run.Error, run.ArticleID,
+		run.PeriodStart.UTC(), run.PeriodEnd.UTC(), time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateDigestRunArticle records the article a digest run was published as
+func (db *DB) UpdateDigestRunArticle(runID, articleID int64) error {
+	db.WaitForReady()
+
+	_, err := db.Exec(`UPDATE digest_runs SET article_id = ? WHERE id = ?`, articleID, runID)
+	return err
+}
+
+// GetDigestRuns retrieves the most recent runs of a digest, newest first
+func (db *DB) GetDigestRuns(digestID int64, limit int) ([]models.DigestRun, error) {
+	db.WaitForReady()
+
+	rows, err := db.Query(`SELECT `+digestRunColumns+` FROM digest_runs WHERE digest_id = ? ORDER BY id DESC LIMIT ?`, digestID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make([]models.DigestRun, 0)
+	for rows.Next() {
+		run, err := scanDigestRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+// GetDigestRun retrieves a digest run by ID, or nil if it does not exist
+func (db *DB) GetDigestRun(id int64) (*models.DigestRun, error) {
+	db.WaitForReady()
+
+	run, err := scanDigestRun(db.QueryRow(`SELECT `+digestRunColumns+` FROM digest_runs WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return run, err
+}
+
+// scanDigest scans a digest row selected with digestColumns
+func scanDigest(row interface{ Scan(...interface{}) error }) (*models.Digest, error) {
+	var d models.Digest
+	var lastRun sql.NullTime
+	if err := row.Scan(&d.ID, &d.Name, &d.SourceType, &d.SourceID, &d.Category, &d.WindowHours, &d.Schedule, &d.Hour, &d.Weekday,
+		&d.PublishToFeed, &d.Enabled, &lastRun, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if lastRun.Valid {
+		d.LastRunAt = &lastRun.Time
+	}
+	return &d, nil
+}
+
+// scanDigestRun scans a digest run row selected with digestRunColumns
+func scanDigestRun(row interface{ Scan(...interface{}) error }) (*models.DigestRun, error) {
+	var run models.DigestRun
+	var citations string
+	var periodStart, periodEnd sql.NullTime
+	if err := row.Scan(&run.ID, &run.DigestID, &run.Title, &run.Content, &citations, &run.ArticleCount, &run.Status, &run.Error,
+		&run.ArticleID, &periodStart, &periodEnd, &run.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(citations), &run.Citations); err != nil || run.Citations == nil {
+		run.Citations = []models.DigestCitation{}
+	}
+	run.PeriodStart = periodStart.Time
+	run.PeriodEnd = periodEnd.Time
+	return &run, nil
+}
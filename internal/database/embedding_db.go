@@ -0,0 +1,161 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// EmbeddingSource is the text of an article that is sent to the embeddings API
+type EmbeddingSource struct {
+	ArticleID int64
+	Title     string
+	Summary   string
+	Content   string // Plain text of the cached content, empty if it was never loaded
+}
+
+const embeddingSourceQuery = `
+	SELECT a.id, COALESCE(a.title, ''), COALESCE(a.summary, ''), COALESCE(f.content, '')
+	FROM articles a
+	LEFT JOIN articles_fts f ON f.rowid = a.id`
+
+// GetArticlesWithoutEmbedding returns the newest articles that have no embedding from the given model
+func (db *DB) GetArticlesWithoutEmbedding(model string, limit int) ([]EmbeddingSource, error) {
+	db.WaitForReady()
+	rows, err := db.Query(embeddingSourceQuery+`
+		LEFT JOIN article_embeddings e ON e.article_id = a.id AND e.model = ?
+		WHERE e.article_id IS NULL
+		ORDER BY a.published_at DESC
+		LIMIT ?`,
+		model, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources []EmbeddingSource
+	for rows.Next() {
+		var s EmbeddingSource
+		if err := rows.Scan(&s.ArticleID, &s.Title, &s.Summary, &s.Content); err != nil {
+			return nil, err
+		}
+		sources = append(sources, s)
+	}
+	return sources, rows.Err()
+}
+
+// GetEmbeddingSource returns the text of an article to embed, or nil if the article does not exist
+func (db *DB) GetEmbeddingSource(articleID int64) (*EmbeddingSource, error) {
+	db.WaitForReady()
+	var s EmbeddingSource
+	err := db.QueryRow(embeddingSourceQuery+` WHERE a.id = ?`, articleID).
+		Scan(&s.ArticleID, &s.Title, &s.Summary, &s.Content)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SaveArticleEmbedding stores or replaces the embedding of an article
+func (db *DB) SaveArticleEmbedding(articleID int64, model string, vector []byte) error {
+	db.WaitForReady()
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO article_embeddings (article_id, model, vector, created_at) VALUES (?, ?, ?, CURRENT_TIMESTAMP)`,
+		articleID, model, vector,
+	)
+	return err
+}
+
+// GetArticleEmbedding returns the embedding of an article from the given model, or nil if there is none
+func (db *DB) GetArticleEmbedding(articleID int64, model string) ([]byte, error) {
+	db.WaitForReady()
+	var vector []byte
+	err := db.QueryRow(
+		`SELECT vector FROM article_embeddings WHERE article_id = ? AND model = ?`,
+		articleID, model,
+	).Scan(&vector)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return vector, err
+}
+
+// GetArticleEmbeddings returns the embeddings from the given model of the articles that have one
+func (db *DB) GetArticleEmbeddings(articleIDs []int64, model string) (map[int64][]byte, error) {
+	db.WaitForReady()
+	vectors := make(map[int64][]byte, len(articleIDs))
+	if len(articleIDs) == 0 {
+		return vectors, nil
+	}
+
+	placeholders := make([]string, len(articleIDs))
+	args := make([]interface{}, 0, len(articleIDs)+1)
+	args = append(args, model)
+	for i, id := range articleIDs {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+	rows, err := db.Query(
+		`SELECT article_id, vector FROM article_embeddings WHERE model = ? AND article_id IN (`+strings.Join(placeholders, ",")+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var vector []byte
+		if err := rows.Scan(&id, &vector); err != nil {
+			return nil, err
+		}
+		vectors[id] = vector
+	}
+	return vectors, rows.Err()
+}
+
+// ForEachArticleEmbedding calls fn with the embedding of every visible article from the given model
+func (db *DB) ForEachArticleEmbedding(model string, fn func(articleID int64, vector []byte)) error {
+	db.WaitForReady()
+	rows, err := db.Query(
+		`SELECT e.article_id, e.vector FROM article_embeddings e
+		JOIN articles a ON a.id = e.article_id
+		WHERE e.model = ? AND a.is_hidden = 0`,
+		model,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var vector []byte
+		if err := rows.Scan(&id, &vector); err != nil {
+			return err
+		}
+		fn(id, vector)
+	}
+	return rows.Err()
+}
+
+// CountArticleEmbeddings returns how many articles have an embedding from the given model, and how many articles there are
+func (db *DB) CountArticleEmbeddings(model string) (embedded, total int, err error) {
+	db.WaitForReady()
+	err = db.QueryRow(
+		`SELECT (SELECT COUNT(*) FROM article_embeddings WHERE model = ?), (SELECT COUNT(*) FROM articles)`,
+		model,
+	).Scan(&embedded, &total)
+	return embedded, total, err
+}
+
+// ClearArticleEmbeddings removes all stored embeddings
+func (db *DB) ClearArticleEmbeddings() error {
+	db.WaitForReady()
+	_, err := db.Exec(`DELETE FROM article_embeddings`)
+	return err
+}
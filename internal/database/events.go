@@ -0,0 +1,45 @@
+package database
+
+import "log"
+
+// Event types published by the database
+const (
+	EventArticlesNew      = "articles.new"       // Articles were inserted by a feed refresh or sync
+	EventArticleStarred   = "article.starred"    // An article was added to favorites
+	EventArticleReadLater = "article.read_later" // An article was added to read later
+	EventFeedFetchFailed  = "feed.fetch_failed"  // A feed that was fetched successfully started failing
+)
+
+// Event describes a change in the database that outbound integrations react to
+type Event struct {
+	Type       string
+	ArticleIDs []int64 // Affected articles, for article events
+	FeedID     int64   // Affected feed, for feed events
+	Message    string  // Error message, for feed.fetch_failed
+}
+
+// SetEventListener registers the function that receives database events.
+// The listener is called synchronously from the write path, so it must not block.
+func (db *DB) SetEventListener(listener func(Event)) {
+	db.listenerMu.Lock()
+	defer db.listenerMu.Unlock()
+	db.eventListener = listener
+}
+
+// publish sends an event to the registered listener, if any
+func (db *DB) publish(event Event) {
+	db.listenerMu.RLock()
+	listener := db.eventListener
+	db.listenerMu.RUnlock()
+
+	if listener == nil {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Event listener panicked on %s: %v", event.Type, r)
+		}
+	}()
+	listener(event)
+}
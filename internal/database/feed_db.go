@@ -345,6 +345,18 @@ func (db *DB) GetFeedByID(id int64) (*models.Feed, error) {
 	return &f, nil
 }
 
+// GetFeedIDByType returns the ID of the first feed of a type, or 0 if there is none
+func (db *DB) GetFeedIDByType(feedType string) (int64, error) {
+	db.WaitForReady()
+
+	var id int64
+	err := db.QueryRow(`SELECT id FROM feeds WHERE type = ? ORDER BY id LIMIT 1`, feedType).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
 // GetAllFeedURLs returns a set of all subscribed RSS feed URLs for deduplication.
 func (db *DB) GetAllFeedURLs() (map[string]bool, error) {
 	db.WaitForReady()
@@ -368,14 +380,14 @@ func (db *DB) GetAllFeedURLs() (map[string]bool, error) {
 // UpdateFeed updates feed title, URL, category, script_path, hide_from_timeline, proxy settings, refresh_interval, is_image_mode, XPath fields, article_view_mode, auto_expand_content, and email settings.
 func (db *DB) UpdateFeed(id int64, title, url, category, scriptPath string, hideFromTimeline bool, proxyURL string, proxyEnabled bool, refreshInterval int, isImageMode bool, feedType string, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailUsername, emailPassword, emailFolder string, emailIMAPPort int) error {
 	db.WaitForReady()
-	_, err := db.Exec("UPDATE feeds SET title = ?, url = ?, category = ?, script_path = ?, hide_from_timeline = ?, proxy_url = ?, proxy_enabled = ?, refresh_interval = ?, is_image_mode = ?, type = ?, xpath_item = ?, xpath_item_title = ?, xpath_item_content = ?, xpath_item_uri = ?, xpath_item_author = ?, xpath_item_timestamp = ?, xpath_item_time_format = ?, xpath_item_thumbnail = ?, xpath_item_categories = ?, xpath_item_uid = ?, article_view_mode = ?, auto_expand_content = ?, email_address = ?, email_imap_server = ?, email_imap_port = ?, email_username = ?, email_password = ?, email_folder = ? WHERE id = ?", title, url, category, scriptPath, hideFromTimeline, proxyURL, proxyEnabled, refreshInterval, isImageMode, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailIMAPPort, emailUsername, emailPassword, emailFolder, id)
+	_, err := db.Exec("UPDATE feeds SET etag = CASE WHEN url = ? THEN etag ELSE '' END, last_modified = CASE WHEN url = ? THEN last_modified ELSE '' END, title = ?, url = ?, category = ?, script_path = ?, hide_from_timeline = ?, proxy_url = ?, proxy_enabled = ?, refresh_interval = ?, is_image_mode = ?, type = ?, xpath_item = ?, xpath_item_title = ?, xpath_item_content = ?, xpath_item_uri = ?, xpath_item_author = ?, xpath_item_timestamp = ?, xpath_item_time_format = ?, xpath_item_thumbnail = ?, xpath_item_categories = ?, xpath_item_uid = ?, article_view_mode = ?, auto_expand_content = ?, email_address = ?, email_imap_server = ?, email_imap_port = ?, email_username = ?, email_password = ?, email_folder = ? WHERE id = ?", url, url, title, url, category, scriptPath, hideFromTimeline, proxyURL, proxyEnabled, refreshInterval, isImageMode, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailIMAPPort, emailUsername, emailPassword, emailFolder, id)
 	return err
 }
 
 // UpdateFeedWithPosition updates a feed including its position field.
 func (db *DB) UpdateFeedWithPosition(id int64, title, url, category, scriptPath string, position int, hideFromTimeline bool, proxyURL string, proxyEnabled bool, refreshInterval int, isImageMode bool, feedType string, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailUsername, emailPassword, emailFolder string, emailIMAPPort int) error {
 	db.WaitForReady()
-	_, err := db.Exec("UPDATE feeds SET title = ?, url = ?, category = ?, script_path = ?, position = ?, hide_from_timeline = ?, proxy_url = ?, proxy_enabled = ?, refresh_interval = ?, is_image_mode = ?, type = ?, xpath_item = ?, xpath_item_title = ?, xpath_item_content = ?, xpath_item_uri = ?, xpath_item_author = ?, xpath_item_timestamp = ?, xpath_item_time_format = ?, xpath_item_thumbnail = ?, xpath_item_categories = ?, xpath_item_uid = ?, article_view_mode = ?, auto_expand_content = ?, email_address = ?, email_imap_server = ?, email_imap_port = ?, email_username = ?, email_password = ?, email_folder = ? WHERE id = ?", title, url, category, scriptPath, position, hideFromTimeline, proxyURL, proxyEnabled, refreshInterval, isImageMode, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailIMAPPort, emailUsername, emailPassword, emailFolder, id)
+	_, err := db.Exec("UPDATE feeds SET etag = CASE WHEN url = ? THEN etag ELSE '' END, last_modified = CASE WHEN url = ? THEN last_modified ELSE '' END, title = ?, url = ?, category = ?, script_path = ?, position = ?, hide_from_timeline = ?, proxy_url = ?, proxy_enabled = ?, refresh_interval = ?, is_image_mode = ?, type = ?, xpath_item = ?, xpath_item_title = ?, xpath_item_content = ?, xpath_item_uri = ?, xpath_item_author = ?, xpath_item_timestamp = ?, xpath_item_time_format = ?, xpath_item_thumbnail = ?, xpath_item_categories = ?, xpath_item_uid = ?, article_view_mode = ?, auto_expand_content = ?, email_address = ?, email_imap_server = ?, email_imap_port = ?, email_username = ?, email_password = ?, email_folder = ? WHERE id = ?", url, url, title, url, category, scriptPath, position, hideFromTimeline, proxyURL, proxyEnabled, refreshInterval, isImageMode, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailIMAPPort, emailUsername, emailPassword, emailFolder, id)
 	return err
 }
 
@@ -401,9 +413,17 @@ func (db *DB) UpdateFeedLink(id int64, link string) error {
 }
 
 // UpdateFeedError updates a feed's error message.
+// A feed.fetch_failed event is published when a feed without an error starts failing.
 func (db *DB) UpdateFeedError(id int64, errorMsg string) error {
 	db.WaitForReady()
+	var previous sql.NullString
+	if errorMsg != "" {
+		_ = db.QueryRow("SELECT last_error FROM feeds WHERE id = ?", id).Scan(&previous)
+	}
 	_, err := db.Exec("UPDATE feeds SET last_error = ? WHERE id = ?", errorMsg, id)
+	if err == nil && errorMsg != "" && previous.String == "" {
+		db.publish(Event{Type: EventFeedFetchFailed, FeedID: id, Message: errorMsg})
+	}
 	return err
 }
 
@@ -421,6 +441,22 @@ func (db *DB) UpdateFeedLastUpdated(id int64) error {
 	return err
 }
 
+// GetFeedHTTPValidators returns the ETag and Last-Modified values stored from the
+// feed's last successful fetch. Both are empty if the feed has never been fetched.
+func (db *DB) GetFeedHTTPValidators(id int64) (string, string, error) {
+	db.WaitForReady()
+	var etag, lastModified string
+	err := db.QueryRow("SELECT COALESCE(etag, ''), COALESCE(last_modified, '') FROM feeds WHERE id = ?", id).Scan(&etag, &lastModified)
+	return etag, lastModified, err
+}
+
+// UpdateFeedHTTPValidators stores the ETag and Last-Modified values returned by the feed server.
+func (db *DB) UpdateFeedHTTPValidators(id int64, etag, lastModified string) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE feeds SET etag = ?, last_modified = ? WHERE id = ?", etag, lastModified, id)
+	return err
+}
+
 // UpdateFeedEmailLastUID updates a newsletter feed's last processed email UID.
 func (db *DB) UpdateFeedEmailLastUID(id int64, lastUID int) error {
 	db.WaitForReady()
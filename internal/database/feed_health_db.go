@@ -0,0 +1,168 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+const feedFetchLogColumns = `id, feed_id, fetched_at, attempt, duration_ms, http_status, bytes, item_count,
+	new_articles, COALESCE(error_class, ''), COALESCE(error, ''), COALESCE(redirect_url, '')`
+
+const feedHealthColumns = `feed_id, COALESCE(consecutive_failures, 0), failing_since, last_attempt_at, last_success_at,
+	last_new_articles_at, COALESCE(redirect_url, '')`
+
+// AddFeedFetchLog records a feed fetch attempt
+func (db *DB) AddFeedFetchLog(entry *models.FeedFetchLog) error {
+	db.WaitForReady()
+	result, err := db.Exec(`
+		INSERT INTO feed_fetch_log (feed_id, fetched_at, attempt, duration_ms, http_status, bytes, item_count,
+			new_articles, error_class, error, redirect_url)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.FeedID, entry.FetchedAt.UTC(), entry.Attempt, entry.DurationMs, entry.HTTPStatus, entry.Bytes,
+		entry.ItemCount, entry.NewArticles, entry.ErrorClass, entry.Error, entry.RedirectURL,
+	)
+	if err != nil {
+		return err
+	}
+	entry.ID, err = result.LastInsertId()
+	return err
+}
+
+// GetFeedFetchLog returns the latest fetch attempts of a feed, newest first
+func (db *DB) GetFeedFetchLog(feedID int64, limit int) ([]models.FeedFetchLog, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT `+feedFetchLogColumns+` FROM feed_fetch_log
+		WHERE feed_id = ? ORDER BY fetched_at DESC, id DESC LIMIT ?`, feedID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanFeedFetchLog(rows)
+}
+
+// GetFeedFetchLogSince returns the fetch attempts of all feeds since a time, oldest first
+func (db *DB) GetFeedFetchLogSince(since time.Time) ([]models.FeedFetchLog, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT `+feedFetchLogColumns+` FROM feed_fetch_log
+		WHERE fetched_at >= ? ORDER BY fetched_at, id`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanFeedFetchLog(rows)
+}
+
+// CleanupFeedFetchLog removes fetch attempts older than maxAgeDays
+func (db *DB) CleanupFeedFetchLog(maxAgeDays int) (int64, error) {
+	db.WaitForReady()
+	result, err := db.Exec(`DELETE FROM feed_fetch_log WHERE fetched_at < ?`, time.Now().UTC().AddDate(0, 0, -maxAgeDays))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanFeedFetchLog(rows *sql.Rows) ([]models.FeedFetchLog, error) {
+	entries := make([]models.FeedFetchLog, 0)
+	for rows.Next() {
+		var e models.FeedFetchLog
+		if err := rows.Scan(&e.ID, &e.FeedID, &e.FetchedAt, &e.Attempt, &e.DurationMs, &e.HTTPStatus, &e.Bytes,
+			&e.ItemCount, &e.NewArticles, &e.ErrorClass, &e.Error, &e.RedirectURL); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// RecordFeedRefresh updates the health state of a feed with the result of a refresh.
+// A success resets the failures; redirectURL is the permanent redirect it followed, if any.
+func (db *DB) RecordFeedRefresh(feedID int64, at time.Time, success bool, newArticles int, redirectURL string) error {
+	db.WaitForReady()
+	at = at.UTC()
+	if !success {
+		_, err := db.Exec(`
+			INSERT INTO feed_health (feed_id, consecutive_failures, failing_since, last_attempt_at)
+			VALUES (?, 1, ?, ?)
+			ON CONFLICT(feed_id) DO UPDATE SET
+				consecutive_failures = consecutive_failures + 1,
+				failing_since = COALESCE(failing_since, excluded.failing_since),
+				last_attempt_at = excluded.last_attempt_at`,
+			feedID, at, at,
+		)
+		return err
+	}
+
+	var lastNewArticles interface{}
+	if newArticles > 0 {
+		lastNewArticles = at
+	}
+	_, err := db.Exec(`
+		INSERT INTO feed_health (feed_id, consecutive_failures, failing_since, last_attempt_at, last_success_at,
+			last_new_articles_at, redirect_url)
+		VALUES (?, 0, NULL, ?, ?, ?, ?)
+		ON CONFLICT(feed_id) DO UPDATE SET
+			consecutive_failures = 0,
+			failing_since = NULL,
+			last_attempt_at = excluded.last_attempt_at,
+			last_success_at = excluded.last_success_at,
+			last_new_articles_at = COALESCE(excluded.last_new_articles_at, last_new_articles_at),
+			redirect_url = excluded.redirect_url`,
+		feedID, at, at, lastNewArticles, redirectURL,
+	)
+	return err
+}
+
+// GetFeedHealth returns the health state of a feed, empty if it was never refreshed
+func (db *DB) GetFeedHealth(feedID int64) (*models.FeedHealth, error) {
+	db.WaitForReady()
+	h, err := scanFeedHealth(db.QueryRow(`SELECT `+feedHealthColumns+` FROM feed_health WHERE feed_id = ?`, feedID))
+	if err == sql.ErrNoRows {
+		return &models.FeedHealth{FeedID: feedID}, nil
+	}
+	return h, err
+}
+
+// GetAllFeedHealth returns the health state of all refreshed feeds by feed ID
+func (db *DB) GetAllFeedHealth() (map[int64]*models.FeedHealth, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT ` + feedHealthColumns + ` FROM feed_health`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	health := make(map[int64]*models.FeedHealth)
+	for rows.Next() {
+		h, err := scanFeedHealth(rows)
+		if err != nil {
+			return nil, err
+		}
+		health[h.FeedID] = h
+	}
+	return health, rows.Err()
+}
+
+func scanFeedHealth(row interface{ Scan(...interface{}) error }) (*models.FeedHealth, error) {
+	var h models.FeedHealth
+	var failingSince, lastAttempt, lastSuccess, lastNewArticles sql.NullTime
+	if err := row.Scan(&h.FeedID, &h.ConsecutiveFailures, &failingSince, &lastAttempt, &lastSuccess,
+		&lastNewArticles, &h.RedirectURL); err != nil {
+		return nil, err
+	}
+	h.FailingSince = timePtr(failingSince)
+	h.LastAttemptAt = timePtr(lastAttempt)
+	h.LastSuccessAt = timePtr(lastSuccess)
+	h.LastNewArticlesAt = timePtr(lastNewArticles)
+	return &h, nil
+}
+
+// timePtr returns the time of a nullable column, nil for NULL
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
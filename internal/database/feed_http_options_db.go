@@ -0,0 +1,61 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/models"
+)
+
+// GetFeedHTTPOptions returns the HTTP options of a feed, empty if none were saved.
+// Headers, cookies and the auth secret are decrypted.
+func (db *DB) GetFeedHTTPOptions(feedID int64) (*models.FeedHTTPOptions, error) {
+	db.WaitForReady()
+	o := &models.FeedHTTPOptions{FeedID: feedID}
+	err := db.QueryRow(`
+		SELECT COALESCE(method, ''), COALESCE(user_agent, ''), COALESCE(headers, ''), COALESCE(cookies, ''),
+			COALESCE(auth_type, ''), COALESCE(auth_username, ''), COALESCE(auth_secret, '')
+		FROM feed_http_options WHERE feed_id = ?`,
+		feedID,
+	).Scan(&o.Method, &o.UserAgent, &o.Headers, &o.Cookies, &o.AuthType, &o.AuthUsername, &o.AuthSecret)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	for _, secret := range []*string{&o.Headers, &o.Cookies, &o.AuthSecret} {
+		if !crypto.IsEncrypted(*secret) {
+			continue
+		}
+		decrypted, err := crypto.Decrypt(*secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt feed HTTP options: %w", err)
+		}
+		*secret = decrypted
+	}
+	return o, nil
+}
+
+// SaveFeedHTTPOptions stores the HTTP options of a feed. Headers, cookies and the auth
+// secret carry credentials, so they are encrypted.
+func (db *DB) SaveFeedHTTPOptions(o *models.FeedHTTPOptions) error {
+	db.WaitForReady()
+	secrets := []string{o.Headers, o.Cookies, o.AuthSecret}
+	for i, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		encrypted, err := crypto.Encrypt(secret)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt feed HTTP options: %w", err)
+		}
+		secrets[i] = encrypted
+	}
+
+	_, err := db.Exec(`
+		INSERT OR REPLACE INTO feed_http_options (feed_id, method, user_agent, headers, cookies, auth_type, auth_username, auth_secret)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		o.FeedID, o.Method, o.UserAgent, secrets[0], secrets[1], o.AuthType, o.AuthUsername, secrets[2],
+	)
+	return err
+}
@@ -0,0 +1,242 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils/urlutil"
+)
+
+// GetLocalFeedIDByURL returns the ID of a local feed, i.e. not synced from FreshRSS,
+// with a URL other than excludeID, or 0 if there is none
+func (db *DB) GetLocalFeedIDByURL(url string, excludeID int64) (int64, error) {
+	db.WaitForReady()
+
+	var id int64
+	err := db.QueryRow(`SELECT id FROM feeds WHERE url = ? AND id != ? AND COALESCE(is_freshrss_source, 0) = 0
+		ORDER BY id LIMIT 1`, url, excludeID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// MoveFeedURL changes the URL of a feed and records the move in its URL history.
+// The HTTP cache validators of the old URL are cleared.
+func (db *DB) MoveFeedURL(feedID int64, newURL, reason string) error {
+	db.WaitForReady()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldURL string
+	if err := tx.QueryRow(`SELECT url FROM feeds WHERE id = ?`, feedID).Scan(&oldURL); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE feeds SET url = ?, etag = '', last_modified = '' WHERE id = ?`, newURL, feedID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO feed_url_history (feed_id, old_url, new_url, reason, changed_at) VALUES (?, ?, ?, ?, ?)`,
+		feedID, oldURL, newURL, reason, time.Now().UTC()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// mergedArticle is an article of a feed that is merged into another
+type mergedArticle struct {
+	id          int64
+	title       string
+	url         string
+	publishedAt time.Time
+	uniqueID    string
+	isRead      bool
+	isFavorite  bool
+	isReadLater bool
+}
+
+// MergeFeed merges a feed into the feed that is subscribed at its new URL and deletes it.
+// Its articles move to the other feed unless that already has them, in which case only
+// their read, favorite and read later states are kept. Its tags and URL history move
+// too, and the merge is recorded in the URL history of the other feed.
+// It returns the number of articles moved.
+func (db *DB) MergeFeed(fromID, intoID int64, reason string) (int, error) {
+	db.WaitForReady()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var fromURL, fromTitle, intoURL string
+	if err := tx.QueryRow(`SELECT url, title FROM feeds WHERE id = ?`, fromID).Scan(&fromURL, &fromTitle); err != nil {
+		return 0, err
+	}
+	if err := tx.QueryRow(`SELECT url FROM feeds WHERE id = ?`, intoID).Scan(&intoURL); err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(`SELECT id, COALESCE(title, ''), COALESCE(url, ''), published_at, COALESCE(unique_id, ''),
+		COALESCE(is_read, 0), COALESCE(is_favorite, 0), COALESCE(is_read_later, 0)
+		FROM articles WHERE feed_id = ?`, fromID)
+	if err != nil {
+		return 0, err
+	}
+	var articles []mergedArticle
+	for rows.Next() {
+		var a mergedArticle
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&a.id, &a.title, &a.url, &publishedAt, &a.uniqueID, &a.isRead, &a.isFavorite, &a.isReadLater); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		a.publishedAt = publishedAt.Time
+		articles = append(articles, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, a := range articles {
+		// The unique ID includes the feed, so it is generated again for the other feed.
+		// Whether it was generated with the publish date is told by the current one.
+		withDate := a.uniqueID == urlutil.GenerateArticleUniqueID(a.title, fromID, a.publishedAt, true)
+		uniqueID := urlutil.GenerateArticleUniqueID(a.title, intoID, a.publishedAt, withDate)
+
+		var existingID int64
+		err := tx.QueryRow(`SELECT id FROM articles WHERE feed_id = ? AND (unique_id = ? OR (url = ? AND url != ''))
+			LIMIT 1`, intoID, uniqueID, a.url).Scan(&existingID)
+		switch {
+		case err == sql.ErrNoRows:
+			if _, err := tx.Exec(`UPDATE articles SET feed_id = ?, unique_id = ? WHERE id = ?`, intoID, uniqueID, a.id); err != nil {
+				return 0, err
+			}
+			moved++
+		case err != nil:
+			return 0, err
+		default:
+			if err := mergeDuplicateArticle(tx, a, existingID, intoID); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	statements := []string{
+		`UPDATE podcast_episodes SET feed_id = ? WHERE feed_id = ?`,
+		`INSERT OR IGNORE INTO feed_tags (feed_id, tag_id) SELECT ?, tag_id FROM feed_tags WHERE feed_id = ?`,
+		`UPDATE feed_url_history SET feed_id = ? WHERE feed_id = ?`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement, intoID, fromID); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO feed_url_history (feed_id, old_url, new_url, reason, merged_title, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?)`, intoID, fromURL, intoURL, reason, fromTitle, time.Now().UTC()); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`DELETE FROM feeds WHERE id = ?`, fromID); err != nil {
+		return 0, err
+	}
+	return moved, tx.Commit()
+}
+
+// mergeDuplicateArticle moves the state, tags, highlights and podcast playback of an article
+// to its duplicate in the feed it is merged into, and deletes it
+func mergeDuplicateArticle(tx *sql.Tx, a mergedArticle, intoArticleID, intoFeedID int64) error {
+	if _, err := tx.Exec(`UPDATE articles SET is_read = MAX(is_read, ?), is_favorite = MAX(is_favorite, ?),
+		is_read_later = MAX(is_read_later, ?) WHERE id = ?`, a.isRead, a.isFavorite, a.isReadLater, intoArticleID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO article_tags (article_id, tag_id, created_at)
+		SELECT ?, tag_id, created_at FROM article_tags WHERE article_id = ?`, intoArticleID, a.id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE highlights SET article_id = ? WHERE article_id = ?`, intoArticleID, a.id); err != nil {
+		return err
+	}
+	if err := mergePodcastEpisode(tx, a.id, intoArticleID, intoFeedID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`DELETE FROM articles WHERE id = ?`, a.id)
+	return err
+}
+
+// mergePodcastEpisode moves the playback position and the download of an episode to the
+// episode of the duplicate article. A download the duplicate already has is kept; the other
+// one is left with the deleted article and removed with the orphaned episodes.
+func mergePodcastEpisode(tx *sql.Tx, fromArticleID, intoArticleID, intoFeedID int64) error {
+	result, err := tx.Exec(`UPDATE podcast_episodes SET article_id = ?, feed_id = ? WHERE article_id = ?
+		AND NOT EXISTS (SELECT 1 FROM podcast_episodes WHERE article_id = ?)`,
+		intoArticleID, intoFeedID, fromArticleID, intoArticleID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	var position float64
+	var played bool
+	var status, path string
+	var size int64
+	var downloadedAt sql.NullTime
+	err = tx.QueryRow(`SELECT COALESCE(position, 0), COALESCE(played, 0), COALESCE(download_status, ''),
+		COALESCE(download_path, ''), COALESCE(downloaded_bytes, 0), downloaded_at
+		FROM podcast_episodes WHERE article_id = ?`, fromArticleID).Scan(&position, &played, &status, &path, &size, &downloadedAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE podcast_episodes SET position = CASE WHEN COALESCE(position, 0) > 0 THEN position ELSE ? END,
+		played = MAX(COALESCE(played, 0), ?) WHERE article_id = ?`, position, played, intoArticleID); err != nil {
+		return err
+	}
+	if status != "done" {
+		return nil
+	}
+	result, err = tx.Exec(`UPDATE podcast_episodes SET download_status = 'done', download_path = ?, downloaded_bytes = ?,
+		download_error = '', downloaded_at = ? WHERE article_id = ? AND COALESCE(download_status, '') != 'done'`,
+		path, size, downloadedAt, intoArticleID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil
+	}
+	// The file now belongs to the other episode
+	_, err = tx.Exec(`UPDATE podcast_episodes SET download_status = '', download_path = '', downloaded_bytes = 0
+		WHERE article_id = ?`, fromArticleID)
+	return err
+}
+
+// GetFeedURLHistory returns the URL changes of a feed, newest first
+func (db *DB) GetFeedURLHistory(feedID int64) ([]models.FeedURLChange, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT id, feed_id, old_url, new_url, COALESCE(reason, ''), COALESCE(merged_title, ''), changed_at
+		FROM feed_url_history WHERE feed_id = ? ORDER BY changed_at DESC, id DESC`, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.FeedURLChange{}
+	for rows.Next() {
+		var c models.FeedURLChange
+		if err := rows.Scan(&c.ID, &c.FeedID, &c.OldURL, &c.NewURL, &c.Reason, &c.MergedTitle, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
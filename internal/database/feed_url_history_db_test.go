@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils/urlutil"
+)
+
+func TestFeedURLMigration(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.DB.Close()
+
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	oldID, err := db.AddFeed(&models.Feed{Title: "Old", URL: "https://old.example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	newID, err := db.AddFeed(&models.Feed{Title: "New", URL: "https://new.example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+
+	t.Run("Look up local feed by URL", func(t *testing.T) {
+		id, err := db.GetLocalFeedIDByURL("https://new.example.com/feed", oldID)
+		if err != nil || id != newID {
+			t.Errorf("Expected feed %d, got %d (%v)", newID, id, err)
+		}
+		if id, _ := db.GetLocalFeedIDByURL("https://new.example.com/feed", newID); id != 0 {
+			t.Errorf("Expected the excluded feed not to be found, got %d", id)
+		}
+		if id, _ := db.GetLocalFeedIDByURL("https://other.example.com/feed", 0); id != 0 {
+			t.Errorf("Expected no feed, got %d", id)
+		}
+	})
+
+	t.Run("Move URL", func(t *testing.T) {
+		if err := db.MoveFeedURL(oldID, "https://old.example.com/rss", "redirect"); err != nil {
+			t.Fatalf("MoveFeedURL failed: %v", err)
+		}
+		feed, err := db.GetFeedByID(oldID)
+		if err != nil {
+			t.Fatalf("GetFeedByID failed: %v", err)
+		}
+		if feed.URL != "https://old.example.com/rss" {
+			t.Errorf("Expected the URL to be moved, got %s", feed.URL)
+		}
+		history, err := db.GetFeedURLHistory(oldID)
+		if err != nil {
+			t.Fatalf("GetFeedURLHistory failed: %v", err)
+		}
+		if len(history) != 1 || history[0].OldURL != "https://old.example.com/feed" ||
+			history[0].NewURL != "https://old.example.com/rss" || history[0].Reason != "redirect" {
+			t.Errorf("Unexpected history: %+v", history)
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		published := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		articles := []*models.Article{
+			{FeedID: oldID, Title: "Shared", URL: "https://example.com/shared", PublishedAt: published, HasValidPublishedTime: true, IsFavorite: true},
+			{FeedID: oldID, Title: "Only old", URL: "https://example.com/only-old", PublishedAt: published, HasValidPublishedTime: true, IsRead: true},
+			{FeedID: newID, Title: "Shared", URL: "https://example.com/shared", PublishedAt: published, HasValidPublishedTime: true},
+		}
+		if err := db.SaveArticles(context.Background(), articles); err != nil {
+			t.Fatalf("SaveArticles failed: %v", err)
+		}
+		work, _ := db.AddTag(&models.Tag{Name: "Work", Color: "#ff0000"})
+		news, _ := db.AddTag(&models.Tag{Name: "News", Color: "#00ff00"})
+		if err := db.SetFeedTags(oldID, []int64{work, news}); err != nil {
+			t.Fatalf("SetFeedTags failed: %v", err)
+		}
+		if err := db.SetFeedTags(newID, []int64{news}); err != nil {
+			t.Fatalf("SetFeedTags failed: %v", err)
+		}
+
+		// The duplicate in the old feed has a highlight, a tag, a playback position and a download
+		var oldShared, newShared int64
+		db.QueryRow(`SELECT id FROM articles WHERE feed_id = ? AND title = 'Shared'`, oldID).Scan(&oldShared)
+		db.QueryRow(`SELECT id FROM articles WHERE feed_id = ? AND title = 'Shared'`, newID).Scan(&newShared)
+		if _, err := db.CreateHighlight(&models.Highlight{ArticleID: oldShared, Quote: "shared quote"}); err != nil {
+			t.Fatalf("CreateHighlight failed: %v", err)
+		}
+		if err := db.AddArticleTag(oldShared, work); err != nil {
+			t.Fatalf("AddArticleTag failed: %v", err)
+		}
+		for _, ep := range []*models.PodcastEpisode{
+			{ArticleID: oldShared, FeedID: oldID, EnclosureURL: "https://example.com/shared.mp3"},
+			{ArticleID: newShared, FeedID: newID, EnclosureURL: "https://example.com/shared.mp3"},
+		} {
+			if _, err := db.SavePodcastEpisode(ep); err != nil {
+				t.Fatalf("SavePodcastEpisode failed: %v", err)
+			}
+		}
+		db.UpdatePodcastProgress(oldShared, 120, 0, false)
+		db.CompletePodcastDownload(oldShared, "/podcasts/shared.mp3", 1024)
+
+		moved, err := db.MergeFeed(oldID, newID, "self_link")
+		if err != nil {
+			t.Fatalf("MergeFeed failed: %v", err)
+		}
+		if moved != 1 {
+			t.Errorf("Expected 1 article to be moved, got %d", moved)
+		}
+		if _, err := db.GetFeedByID(oldID); err == nil {
+			t.Error("Expected the merged feed to be deleted")
+		}
+
+		rows, err := db.Query(`SELECT title, unique_id, is_read, is_favorite FROM articles WHERE feed_id = ? ORDER BY title`, newID)
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		type article struct {
+			title, uniqueID string
+			read, favorite  bool
+		}
+		var got []article
+		for rows.Next() {
+			var a article
+			rows.Scan(&a.title, &a.uniqueID, &a.read, &a.favorite)
+			got = append(got, a)
+		}
+		rows.Close()
+		if len(got) != 2 {
+			t.Fatalf("Expected 2 articles, got %+v", got)
+		}
+		if got[0].title != "Only old" || !got[0].read ||
+			got[0].uniqueID != urlutil.GenerateArticleUniqueID("Only old", newID, published, true) {
+			t.Errorf("Expected the moved article to keep its state with a new unique ID, got %+v", got[0])
+		}
+		if got[1].title != "Shared" || !got[1].favorite {
+			t.Errorf("Expected the duplicate to be favorite, got %+v", got[1])
+		}
+
+		highlights, err := db.GetHighlightsByArticle(newShared)
+		if err != nil {
+			t.Fatalf("GetHighlightsByArticle failed: %v", err)
+		}
+		if len(highlights) != 1 || highlights[0].Quote != "shared quote" {
+			t.Errorf("Expected the highlight to move to the duplicate, got %+v", highlights)
+		}
+		articleTags, err := db.GetArticleTags(newShared)
+		if err != nil {
+			t.Fatalf("GetArticleTags failed: %v", err)
+		}
+		if len(articleTags) != 1 || articleTags[0].Name != "Work" {
+			t.Errorf("Expected the tag to move to the duplicate, got %+v", articleTags)
+		}
+		episode, err := db.GetPodcastEpisode(newShared)
+		if err != nil || episode == nil {
+			t.Fatalf("GetPodcastEpisode failed: %v", err)
+		}
+		if episode.Position != 120 || episode.DownloadStatus != "done" || episode.DownloadPath != "/podcasts/shared.mp3" {
+			t.Errorf("Expected the playback and download to move to the duplicate, got %+v", episode)
+		}
+		orphaned, err := db.GetOrphanedPodcastEpisodes()
+		if err != nil {
+			t.Fatalf("GetOrphanedPodcastEpisodes failed: %v", err)
+		}
+		if len(orphaned) != 1 || orphaned[0].DownloadPath != "" {
+			t.Errorf("Expected the old episode to be left without its download, got %+v", orphaned)
+		}
+
+		tags, err := db.GetFeedTags(newID)
+		if err != nil {
+			t.Fatalf("GetFeedTags failed: %v", err)
+		}
+		if len(tags) != 2 {
+			t.Errorf("Expected 2 tags, got %+v", tags)
+		}
+
+		history, err := db.GetFeedURLHistory(newID)
+		if err != nil {
+			t.Fatalf("GetFeedURLHistory failed: %v", err)
+		}
+		if len(history) != 2 {
+			t.Fatalf("Expected the merge and the history of the merged feed, got %+v", history)
+		}
+		if history[0].OldURL != "https://old.example.com/rss" || history[0].NewURL != "https://new.example.com/feed" ||
+			history[0].MergedTitle != "Old" || history[0].Reason != "self_link" {
+			t.Errorf("Unexpected merge record: %+v", history[0])
+		}
+		if history[1].Reason != "redirect" {
+			t.Errorf("Expected the moved history of the merged feed, got %+v", history[1])
+		}
+	})
+}
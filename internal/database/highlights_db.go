@@ -0,0 +1,224 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"MrRSS/internal/models"
+)
+
+// HighlightSearchResult is a highlight matched by full-text search
+type HighlightSearchResult struct {
+	models.Highlight
+	ArticleTitle string `json:"article_title"`
+	FeedTitle    string `json:"feed_title"`
+	Snippet      string `json:"snippet"` // HTML-escaped excerpt with matches wrapped in <mark>
+}
+
+// migrateHighlightsFTS creates the highlights_fts full-text index over quotes and
+// notes and the triggers that keep it in sync with the highlights table.
+func migrateHighlightsFTS(db *sql.DB) error {
+	var exists int
+	_ = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='highlights_fts'`).Scan(&exists)
+
+	if exists == 0 {
+		_, err := db.Exec(`CREATE VIRTUAL TABLE highlights_fts USING fts5(quote, note, tokenize='trigram')`)
+		if err != nil {
+			log.Printf("Warning: Failed to create highlight search index: %v", err)
+			return nil
+		}
+		_, _ = db.Exec(`INSERT INTO highlights_fts (rowid, quote, note) SELECT id, quote, COALESCE(note, '') FROM highlights`)
+	}
+
+	triggers := []string{
+		`CREATE TRIGGER IF NOT EXISTS highlights_fts_insert AFTER INSERT ON highlights BEGIN
+			INSERT INTO highlights_fts (rowid, quote, note) VALUES (new.id, new.quote, COALESCE(new.note, ''));
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS highlights_fts_update AFTER UPDATE OF quote, note ON highlights BEGIN
+			UPDATE highlights_fts SET quote = new.quote, note = COALESCE(new.note, '') WHERE rowid = new.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS highlights_fts_delete AFTER DELETE ON highlights BEGIN
+			DELETE FROM highlights_fts WHERE rowid = old.id;
+		END`,
+	}
+	for _, trigger := range triggers {
+		if _, err := db.Exec(trigger); err != nil {
+			log.Printf("Warning: Failed to create highlight search trigger: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// CreateHighlight stores a new highlight and returns its ID
+func (db *DB) CreateHighlight(h *models.Highlight) (int64, error) {
+	db.WaitForReady()
+
+	result, err := db.Exec(`
+		INSERT INTO highlights (article_id, quote, prefix, suffix, start_offset, end_offset, note, color, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, h.ArticleID, h.Quote, h.Prefix, h.Suffix, h.StartOffset, h.EndOffset, h.Note, h.Color)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create highlight: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// GetHighlight retrieves a highlight by ID, or nil if it does not exist
+func (db *DB) GetHighlight(id int64) (*models.Highlight, error) {
+	db.WaitForReady()
+
+	row := db.QueryRow(`
+		SELECT id, article_id, quote, prefix, suffix, start_offset, end_offset, note, color, created_at, updated_at
+		FROM highlights WHERE id = ?
+	`, id)
+
+	h, err := scanHighlight(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get highlight: %w", err)
+	}
+	return h, nil
+}
+
+// GetHighlightsByArticle retrieves the highlights of an article in reading order
+func (db *DB) GetHighlightsByArticle(articleID int64) ([]models.Highlight, error) {
+	db.WaitForReady()
+
+	rows, err := db.Query(`
+		SELECT id, article_id, quote, prefix, suffix, start_offset, end_offset, note, color, created_at, updated_at
+		FROM highlights
+		WHERE article_id = ?
+		ORDER BY start_offset ASC, id ASC
+	`, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get highlights: %w", err)
+	}
+	defer rows.Close()
+
+	highlights := make([]models.Highlight, 0)
+	for rows.Next() {
+		h, err := scanHighlight(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan highlight: %w", err)
+		}
+		highlights = append(highlights, *h)
+	}
+	return highlights, rows.Err()
+}
+
+// UpdateHighlight changes the note and color of a highlight
+func (db *DB) UpdateHighlight(id int64, note, color string) error {
+	db.WaitForReady()
+
+	_, err := db.Exec(
+		`UPDATE highlights SET note = ?, color = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		note, color, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update highlight: %w", err)
+	}
+	return nil
+}
+
+// DeleteHighlight removes a highlight
+func (db *DB) DeleteHighlight(id int64) error {
+	db.WaitForReady()
+
+	_, err := db.Exec(`DELETE FROM highlights WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete highlight: %w", err)
+	}
+	return nil
+}
+
+// SearchHighlights runs a full-text search over highlight quotes and notes, newest first.
+// It returns the matching page of results and the total number of matches.
+func (db *DB) SearchHighlights(query string, limit, offset int) ([]HighlightSearchResult, int, error) {
+	db.WaitForReady()
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, 0, fmt.Errorf("empty search query")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	where := `highlights_fts MATCH ?`
+	args := []interface{}{query}
+	snippetExpr := fmt.Sprintf(`snippet(highlights_fts, -1, '%s', '%s', '…', 32)`, ftsHighlightStart, ftsHighlightEnd)
+
+	// Queries shorter than a trigram cannot use the index, so scan it with LIKE instead
+	if utf8.RuneCountInString(query) < ftsMinQueryLength {
+		pattern := "%" + query + "%"
+		where = `(highlights_fts.quote LIKE ? OR highlights_fts.note LIKE ?)`
+		args = []interface{}{pattern, pattern}
+		snippetExpr = `''`
+	}
+
+	fromClause := `
+		FROM highlights_fts
+		JOIN highlights h ON h.id = highlights_fts.rowid
+		JOIN articles a ON a.id = h.article_id
+		JOIN feeds f ON f.id = a.feed_id
+		WHERE ` + where
+
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) `+fromClause, args...).Scan(&total); err != nil {
+		return nil, 0, wrapSearchError(err)
+	}
+
+	rows, err := db.Query(`
+		SELECT h.id, h.article_id, h.quote, h.prefix, h.suffix, h.start_offset, h.end_offset, h.note, h.color, h.created_at, h.updated_at,
+			   a.title, f.title, `+snippetExpr+`
+		`+fromClause+`
+		ORDER BY h.created_at DESC, h.id DESC
+		LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, wrapSearchError(err)
+	}
+	defer rows.Close()
+
+	results := make([]HighlightSearchResult, 0)
+	for rows.Next() {
+		var r HighlightSearchResult
+		var prefix, suffix, note, color sql.NullString
+		if err := rows.Scan(&r.ID, &r.ArticleID, &r.Quote, &prefix, &suffix, &r.StartOffset, &r.EndOffset, &note, &color, &r.CreatedAt, &r.UpdatedAt,
+			&r.ArticleTitle, &r.FeedTitle, &r.Snippet); err != nil {
+			log.Println("Error scanning highlight in search:", err)
+			continue
+		}
+		r.Prefix = prefix.String
+		r.Suffix = suffix.String
+		r.Note = note.String
+		r.Color = color.String
+		r.Snippet = highlightSnippet(r.Snippet)
+		results = append(results, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("row iteration failed: %w", err)
+	}
+
+	return results, total, nil
+}
+
+// scanHighlight scans a highlight row selected with the standard column list
+func scanHighlight(row interface{ Scan(...interface{}) error }) (*models.Highlight, error) {
+	var h models.Highlight
+	var prefix, suffix, note, color sql.NullString
+	if err := row.Scan(&h.ID, &h.ArticleID, &h.Quote, &prefix, &suffix, &h.StartOffset, &h.EndOffset, &note, &color, &h.CreatedAt, &h.UpdatedAt); err != nil {
+		return nil, err
+	}
+	h.Prefix = prefix.String
+	h.Suffix = suffix.String
+	h.Note = note.String
+	h.Color = color.String
+	return &h, nil
+}
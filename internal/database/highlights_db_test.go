@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+func setupHighlightsTestDB(t *testing.T) (*DB, []int64) {
+	t.Helper()
+
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.DB.Close() })
+
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	old := time.Now().AddDate(-1, 0, 0)
+	articles := []*models.Article{
+		{FeedID: feedID, Title: "Annotated", URL: "https://example.com/1", PublishedAt: old},
+		{FeedID: feedID, Title: "Plain", URL: "https://example.com/2", PublishedAt: old},
+	}
+	if err := db.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatalf("SaveArticles failed: %v", err)
+	}
+
+	var ids []int64
+	rows, err := db.Query(`SELECT id FROM articles ORDER BY url`)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	for rows.Next() {
+		var id int64
+		rows.Scan(&id)
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if len(ids) != 2 {
+		t.Fatalf("Expected 2 articles, got %d", len(ids))
+	}
+	return db, ids
+}
+
+func TestHighlights(t *testing.T) {
+	db, ids := setupHighlightsTestDB(t)
+
+	id, err := db.CreateHighlight(&models.Highlight{
+		ArticleID:   ids[0],
+		Quote:       "the quick brown fox",
+		Prefix:      "Once upon a time ",
+		StartOffset: 17,
+		EndOffset:   36,
+		Note:        "classic pangram",
+	})
+	if err != nil {
+		t.Fatalf("CreateHighlight failed: %v", err)
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		h, err := db.GetHighlight(id)
+		if err != nil || h == nil {
+			t.Fatalf("GetHighlight failed: %v", err)
+		}
+		if h.Quote != "the quick brown fox" || h.Prefix != "Once upon a time " || h.StartOffset != 17 || h.EndOffset != 36 {
+			t.Errorf("Unexpected highlight: %+v", h)
+		}
+
+		missing, err := db.GetHighlight(9999)
+		if err != nil || missing != nil {
+			t.Errorf("Expected nil for missing highlight, got %+v, %v", missing, err)
+		}
+	})
+
+	t.Run("Update and search", func(t *testing.T) {
+		if err := db.UpdateHighlight(id, "remember for the typography talk", "yellow"); err != nil {
+			t.Fatalf("UpdateHighlight failed: %v", err)
+		}
+
+		results, total, err := db.SearchHighlights("typography", 10, 0)
+		if err != nil {
+			t.Fatalf("SearchHighlights failed: %v", err)
+		}
+		if total != 1 || len(results) != 1 || results[0].ID != id {
+			t.Fatalf("Expected the updated highlight, got %d results", total)
+		}
+		if results[0].ArticleTitle != "Annotated" || !strings.Contains(results[0].Snippet, "<mark>") {
+			t.Errorf("Unexpected search result: %+v", results[0])
+		}
+
+		// The old note is no longer indexed
+		if _, total, _ := db.SearchHighlights("pangram", 10, 0); total != 0 {
+			t.Errorf("Expected stale note not to match, got %d results", total)
+		}
+	})
+
+	t.Run("List by article", func(t *testing.T) {
+		if _, err := db.CreateHighlight(&models.Highlight{ArticleID: ids[0], Quote: "earlier", StartOffset: 2, EndOffset: 9}); err != nil {
+			t.Fatalf("CreateHighlight failed: %v", err)
+		}
+		highlights, err := db.GetHighlightsByArticle(ids[0])
+		if err != nil {
+			t.Fatalf("GetHighlightsByArticle failed: %v", err)
+		}
+		if len(highlights) != 2 || highlights[0].Quote != "earlier" {
+			t.Errorf("Expected highlights in reading order, got %+v", highlights)
+		}
+	})
+
+	t.Run("Delete with article", func(t *testing.T) {
+		if err := db.DeleteHighlight(id); err != nil {
+			t.Fatalf("DeleteHighlight failed: %v", err)
+		}
+		if _, err := db.Exec(`DELETE FROM articles WHERE id = ?`, ids[0]); err != nil {
+			t.Fatalf("Delete article failed: %v", err)
+		}
+
+		var count int
+		db.QueryRow(`SELECT COUNT(*) FROM highlights`).Scan(&count)
+		if count != 0 {
+			t.Errorf("Expected highlights to be removed, %d left", count)
+		}
+		db.QueryRow(`SELECT COUNT(*) FROM highlights_fts`).Scan(&count)
+		if count != 0 {
+			t.Errorf("Expected highlight index to be empty, %d left", count)
+		}
+	})
+}
+
+func TestHighlights_PinnedDuringCleanup(t *testing.T) {
+	db, ids := setupHighlightsTestDB(t)
+
+	if _, err := db.CreateHighlight(&models.Highlight{ArticleID: ids[0], Quote: "keep me", StartOffset: -1, EndOffset: -1}); err != nil {
+		t.Fatalf("CreateHighlight failed: %v", err)
+	}
+
+	// Large enough to push the database over a 1 MB cache limit
+	content := strings.Repeat("<p>Lorem ipsum dolor sit amet.</p>", 40000)
+	for _, id := range ids {
+		if err := db.SetArticleContent(id, content); err != nil {
+			t.Fatalf("SetArticleContent failed: %v", err)
+		}
+	}
+	if err := db.SetSetting("max_cache_size_mb", "1"); err != nil {
+		t.Fatalf("SetSetting failed: %v", err)
+	}
+
+	deleted, err := db.CleanupArticleContentsBySize()
+	if err != nil {
+		t.Fatalf("CleanupArticleContentsBySize failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected only the plain article's content to be deleted, deleted %d", deleted)
+	}
+	if _, found, _ := db.GetArticleContent(ids[0]); !found {
+		t.Error("Expected the annotated article's content to be kept")
+	}
+
+	// Old annotated articles are also kept by age-based cleanup
+	if _, err := db.CleanupOldArticles(); err != nil {
+		t.Fatalf("CleanupOldArticles failed: %v", err)
+	}
+	if _, err := db.GetArticleByID(ids[0]); err != nil {
+		t.Errorf("Expected the annotated article to be kept: %v", err)
+	}
+	if _, err := db.GetArticleByID(ids[1]); err == nil {
+		t.Error("Expected the plain article to be removed")
+	}
+}
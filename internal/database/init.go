@@ -109,5 +109,109 @@ func applyAdditionalMigrations(db *DB) error {
 		return err
 	}
 
+	// The migrations below add feeds columns and must run after the feeds table
+	// rebuild above, which only copies the columns it knows about.
+
+	// Migration: Add HTTP cache validators to feeds table for conditional fetching
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN etag TEXT DEFAULT ''`)
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN last_modified TEXT DEFAULT ''`)
+
+	// Migration: Fetch YouTube channel and playlist feeds with the YouTube source,
+	// which adds the video transcripts
+	_, _ = db.Exec(`UPDATE feeds SET type = 'youtube'
+		WHERE COALESCE(type, '') = '' AND COALESCE(script_path, '') = '' AND COALESCE(is_freshrss_source, 0) = 0
+		AND (url LIKE 'https://www.youtube.com/feeds/videos.xml?%' OR url LIKE 'https://youtube.com/feeds/videos.xml?%')`)
+
+	// Migration: Add full-text search index over articles and cached content.
+	// Must run after the articles table rebuild, which would drop its triggers.
+	if err := migrateArticlesFTS(db.DB); err != nil {
+		return err
+	}
+
+	// Migration: Remove article tag assignments together with their articles.
+	// Must run after the articles table rebuild, which would drop the trigger.
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS article_tags_article_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM article_tags WHERE article_id = old.id;
+	END`)
+
+	// Migration: Remove highlights together with their articles.
+	// Must run after the articles table rebuild, which would drop the trigger.
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS highlights_article_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM highlights WHERE article_id = old.id;
+	END`)
+
+	// Migration: Remove embeddings together with their articles.
+	// Must run after the articles table rebuild, which would drop the trigger.
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS article_embeddings_article_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM article_embeddings WHERE article_id = old.id;
+	END`)
+
+	// Migration: Remove duplicate detection fingerprints together with their articles.
+	// Must run after the articles table rebuild, which would drop the trigger.
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS article_fingerprints_article_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM article_fingerprints WHERE article_id = old.id;
+	END`)
+
+	// Migration: Remove full-article translations together with their articles,
+	// and the ones left behind by articles deleted before the trigger existed.
+	// Must run after the articles table rebuild, which would drop the trigger.
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS article_translations_article_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM article_translations WHERE article_id = old.id;
+	END`)
+	_, _ = db.Exec(`DELETE FROM article_translations WHERE article_id NOT IN (SELECT id FROM articles)`)
+
+	// Migration: Remove podcast download settings together with their feeds.
+	// Must run after the feeds table rebuild, which would drop the trigger.
+	// Episodes are removed by the podcast cleanup, which also deletes their downloaded files.
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS podcast_feed_settings_feed_delete AFTER DELETE ON feeds BEGIN
+		DELETE FROM podcast_feed_settings WHERE feed_id = old.id;
+	END`)
+
+	// Migration: Remove JSON API feed options together with their feeds.
+	// Must run after the feeds table rebuild, which would drop the trigger.
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS json_feed_options_feed_delete AFTER DELETE ON feeds BEGIN
+		DELETE FROM json_feed_options WHERE feed_id = old.id;
+	END`)
+
+	// Migration: Remove script feed settings, state and runs together with their feeds.
+	// Must run after the feeds table rebuild, which would drop the trigger.
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS script_feed_settings_feed_delete AFTER DELETE ON feeds BEGIN
+		DELETE FROM script_feed_settings WHERE feed_id = old.id;
+		DELETE FROM script_runs WHERE feed_id = old.id;
+	END`)
+
+	// Migration: Remove item transforms together with their feeds.
+	// Must run after the feeds table rebuild, which would drop the trigger.
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS item_transforms_feed_delete AFTER DELETE ON feeds BEGIN
+		DELETE FROM item_transforms WHERE feed_id = old.id;
+	END`)
+
+	// Migration: Remove feed HTTP options together with their feeds.
+	// Must run after the feeds table rebuild, which would drop the trigger.
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS feed_http_options_feed_delete AFTER DELETE ON feeds BEGIN
+		DELETE FROM feed_http_options WHERE feed_id = old.id;
+	END`)
+
+	// Migration: Remove the fetch log and health state of feeds together with their feeds.
+	// Must run after the feeds table rebuild, which would drop the triggers.
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS feed_fetch_log_feed_delete AFTER DELETE ON feeds BEGIN
+		DELETE FROM feed_fetch_log WHERE feed_id = old.id;
+	END`)
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS feed_health_feed_delete AFTER DELETE ON feeds BEGIN
+		DELETE FROM feed_health WHERE feed_id = old.id;
+	END`)
+
+	// Migration: Remove the URL history of feeds together with their feeds.
+	// Must run after the feeds table rebuild, which would drop the trigger.
+	_, _ = db.Exec(`CREATE TRIGGER IF NOT EXISTS feed_url_history_feed_delete AFTER DELETE ON feeds BEGIN
+		DELETE FROM feed_url_history WHERE feed_id = old.id;
+	END`)
+
+	// Migration: Convert rule actions from plain strings to objects with parameters.
+	// Must run after the settings table is created.
+	if err := migrateRuleActions(db.DB); err != nil {
+		return err
+	}
+
 	return nil
 }
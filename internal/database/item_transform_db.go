@@ -0,0 +1,31 @@
+package database
+
+import (
+	"database/sql"
+
+	"MrRSS/internal/models"
+)
+
+// GetItemTransform returns the item transform of a feed, disabled and empty if none was saved
+func (db *DB) GetItemTransform(feedID int64) (*models.ItemTransform, error) {
+	db.WaitForReady()
+	t := &models.ItemTransform{FeedID: feedID}
+	err := db.QueryRow(
+		`SELECT COALESCE(script, ''), COALESCE(enabled, 0) FROM item_transforms WHERE feed_id = ?`,
+		feedID,
+	).Scan(&t.Script, &t.Enabled)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SaveItemTransform stores the item transform of a feed
+func (db *DB) SaveItemTransform(t *models.ItemTransform) error {
+	db.WaitForReady()
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO item_transforms (feed_id, script, enabled) VALUES (?, ?, ?)`,
+		t.FeedID, t.Script, t.Enabled,
+	)
+	return err
+}
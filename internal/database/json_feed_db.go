@@ -0,0 +1,51 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/models"
+)
+
+// GetJSONFeedOptions returns the options of a JSON API feed, with defaults if none were saved
+func (db *DB) GetJSONFeedOptions(feedID int64) (*models.JSONFeedOptions, error) {
+	db.WaitForReady()
+	o := &models.JSONFeedOptions{FeedID: feedID}
+	err := db.QueryRow(
+		`SELECT COALESCE(headers, ''), COALESCE(next_page_path, ''), COALESCE(page_param, ''), COALESCE(max_pages, 0) FROM json_feed_options WHERE feed_id = ?`,
+		feedID,
+	).Scan(&o.Headers, &o.NextPagePath, &o.PageParam, &o.MaxPages)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if crypto.IsEncrypted(o.Headers) {
+		headers, err := crypto.Decrypt(o.Headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt JSON feed headers: %w", err)
+		}
+		o.Headers = headers
+	}
+	return o, nil
+}
+
+// SaveJSONFeedOptions stores the options of a JSON API feed. Headers often carry
+// API keys, so they are encrypted.
+func (db *DB) SaveJSONFeedOptions(o *models.JSONFeedOptions) error {
+	db.WaitForReady()
+	headers := o.Headers
+	if headers != "" {
+		var err error
+		headers, err = crypto.Encrypt(headers)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt JSON feed headers: %w", err)
+		}
+	}
+
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO json_feed_options (feed_id, headers, next_page_path, page_param, max_pages) VALUES (?, ?, ?, ?, ?)`,
+		o.FeedID, headers, o.NextPagePath, o.PageParam, o.MaxPages,
+	)
+	return err
+}
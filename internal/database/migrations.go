@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"log"
 	"strings"
 )
@@ -135,9 +136,344 @@ func runMigrations(db *sql.DB) error {
 	)`)
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_ai_profiles_is_default ON ai_profiles(is_default)`)
 
+	// Migration: Add users and user_sessions tables for server mode authentication
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		password_hash TEXT NOT NULL,
+		is_admin BOOLEAN DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		last_login_at DATETIME
+	)`)
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS user_sessions (
+		token_hash TEXT PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		created_at INTEGER NOT NULL,
+		expires_at INTEGER NOT NULL,
+		FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id)`)
+
+	// Migration: Add fever_api_key_hash column to users for the Fever API
+	_, _ = db.Exec(`ALTER TABLE users ADD COLUMN fever_api_key_hash TEXT`)
+
+	// Migration: Add feed_token_hash column to users for token-authenticated output feeds
+	_, _ = db.Exec(`ALTER TABLE users ADD COLUMN feed_token_hash TEXT`)
+
+	// Migration: Add article_tags junction table for tagging individual articles
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS article_tags (
+		article_id INTEGER NOT NULL,
+		tag_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (article_id, tag_id),
+		FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE,
+		FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_article_tags_tag_id ON article_tags(tag_id)`)
+
+	// Migration: Add highlights table for quotes and notes on article content
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS highlights (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		article_id INTEGER NOT NULL,
+		quote TEXT NOT NULL,
+		prefix TEXT DEFAULT '',
+		suffix TEXT DEFAULT '',
+		start_offset INTEGER DEFAULT -1,
+		end_offset INTEGER DEFAULT -1,
+		note TEXT DEFAULT '',
+		color TEXT DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_highlights_article_id ON highlights(article_id)`)
+	if err := migrateHighlightsFTS(db); err != nil {
+		return err
+	}
+
+	// Migration: Add outbound webhooks and their delivery queue
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS webhooks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL DEFAULT '',
+		url TEXT NOT NULL,
+		secret TEXT DEFAULT '',
+		events TEXT NOT NULL DEFAULT '[]',
+		conditions TEXT DEFAULT '',
+		enabled BOOLEAN DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		webhook_id INTEGER NOT NULL,
+		event TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER DEFAULT 0,
+		response_status INTEGER DEFAULT 0,
+		error TEXT DEFAULT '',
+		next_attempt_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		delivered_at DATETIME,
+		FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(status, next_attempt_at)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at)`)
+
+	// Migration: Track characters translated per provider and month for translation budgets
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS translation_usage (
+		provider TEXT NOT NULL,
+		month TEXT NOT NULL,
+		characters INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (provider, month)
+	)`)
+
+	// Migration: Store full-article translations separately from the cached content.
+	// Blocks holds the translated HTML of each block, so partial translations can be resumed.
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS article_translations (
+		article_id INTEGER NOT NULL,
+		target_language TEXT NOT NULL,
+		source_hash TEXT NOT NULL,
+		blocks TEXT NOT NULL,
+		complete INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (article_id, target_language)
+	)`)
+
+	// Migration: Store article embeddings for semantic search and related articles.
+	// Vectors are normalized little-endian float32s, so cosine similarity is a dot product.
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS article_embeddings (
+		article_id INTEGER PRIMARY KEY,
+		model TEXT NOT NULL,
+		vector BLOB NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_article_embeddings_model ON article_embeddings(model)`)
+
+	// Migration: Add AI digests and the history of generated digests
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS digests (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL DEFAULT '',
+		source_type TEXT NOT NULL,
+		source_id INTEGER DEFAULT 0,
+		category TEXT DEFAULT '',
+		window_hours INTEGER DEFAULT 24,
+		schedule TEXT NOT NULL DEFAULT 'daily',
+		hour INTEGER DEFAULT 8,
+		weekday INTEGER DEFAULT 1,
+		publish_to_feed BOOLEAN DEFAULT 0,
+		enabled BOOLEAN DEFAULT 1,
+		last_run_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS digest_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		digest_id INTEGER NOT NULL,
+		title TEXT DEFAULT '',
+		content TEXT DEFAULT '',
+		citations TEXT NOT NULL DEFAULT '[]',
+		article_count INTEGER DEFAULT 0,
+		status TEXT NOT NULL,
+		error TEXT DEFAULT '',
+		article_id INTEGER DEFAULT 0,
+		period_start DATETIME,
+		period_end DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (digest_id) REFERENCES digests(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_digest_runs_digest ON digest_runs(digest_id, created_at)`)
+
+	// Migration: Store fingerprints for near-duplicate detection across feeds.
+	// Articles about the same story share the story_id of the first of them that was fetched.
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS article_fingerprints (
+		article_id INTEGER PRIMARY KEY,
+		story_id INTEGER NOT NULL DEFAULT 0,
+		canonical_url TEXT DEFAULT '',
+		title_hash INTEGER NOT NULL DEFAULT 0,
+		title_terms INTEGER NOT NULL DEFAULT 0,
+		content_hash BLOB,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_article_fingerprints_story ON article_fingerprints(story_id)`)
+
+	// Migration: Add podcast episode metadata, playback positions and downloads.
+	// Chapters holds the inline chapter marks as JSON.
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS podcast_episodes (
+		article_id INTEGER PRIMARY KEY,
+		feed_id INTEGER NOT NULL,
+		enclosure_url TEXT NOT NULL,
+		enclosure_type TEXT DEFAULT '',
+		enclosure_length INTEGER DEFAULT 0,
+		duration REAL DEFAULT 0,
+		season INTEGER DEFAULT 0,
+		episode INTEGER DEFAULT 0,
+		episode_type TEXT DEFAULT '',
+		chapters TEXT DEFAULT '',
+		chapters_url TEXT DEFAULT '',
+		transcript_url TEXT DEFAULT '',
+		transcript_type TEXT DEFAULT '',
+		position REAL DEFAULT 0,
+		played BOOLEAN DEFAULT 0,
+		download_status TEXT DEFAULT '',
+		download_path TEXT DEFAULT '',
+		downloaded_bytes INTEGER DEFAULT 0,
+		download_error TEXT DEFAULT '',
+		queued_at DATETIME,
+		downloaded_at DATETIME,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_podcast_episodes_download ON podcast_episodes(download_status, queued_at)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_podcast_episodes_feed ON podcast_episodes(feed_id)`)
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS podcast_feed_settings (
+		feed_id INTEGER PRIMARY KEY,
+		auto_download BOOLEAN DEFAULT 0,
+		keep_episodes INTEGER DEFAULT 0,
+		retention_days INTEGER DEFAULT 0,
+		FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS script_feed_settings (
+		feed_id INTEGER PRIMARY KEY,
+		args TEXT DEFAULT '',
+		env TEXT DEFAULT '',
+		secrets TEXT DEFAULT '',
+		timeout_seconds INTEGER DEFAULT 0,
+		state TEXT DEFAULT '',
+		FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS script_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		feed_id INTEGER NOT NULL,
+		started_at DATETIME NOT NULL,
+		duration_ms INTEGER DEFAULT 0,
+		exit_code INTEGER DEFAULT 0,
+		stderr TEXT DEFAULT '',
+		error TEXT DEFAULT '',
+		item_count INTEGER DEFAULT 0,
+		FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_script_runs_feed ON script_runs(feed_id, started_at)`)
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS item_transforms (
+		feed_id INTEGER PRIMARY KEY,
+		script TEXT DEFAULT '',
+		enabled INTEGER DEFAULT 0,
+		FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS feed_http_options (
+		feed_id INTEGER PRIMARY KEY,
+		method TEXT DEFAULT '',
+		user_agent TEXT DEFAULT '',
+		headers TEXT DEFAULT '',
+		cookies TEXT DEFAULT '',
+		auth_type TEXT DEFAULT '',
+		auth_username TEXT DEFAULT '',
+		auth_secret TEXT DEFAULT '',
+		FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS feed_fetch_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		feed_id INTEGER NOT NULL,
+		fetched_at DATETIME NOT NULL,
+		attempt INTEGER DEFAULT 1,
+		duration_ms INTEGER DEFAULT 0,
+		http_status INTEGER DEFAULT 0,
+		bytes INTEGER DEFAULT 0,
+		item_count INTEGER DEFAULT 0,
+		new_articles INTEGER DEFAULT 0,
+		error_class TEXT DEFAULT '',
+		error TEXT DEFAULT '',
+		redirect_url TEXT DEFAULT '',
+		FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_feed_fetch_log_feed ON feed_fetch_log(feed_id, fetched_at)`)
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS feed_health (
+		feed_id INTEGER PRIMARY KEY,
+		consecutive_failures INTEGER DEFAULT 0,
+		failing_since DATETIME,
+		last_attempt_at DATETIME,
+		last_success_at DATETIME,
+		last_new_articles_at DATETIME,
+		redirect_url TEXT DEFAULT '',
+		FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS feed_url_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		feed_id INTEGER NOT NULL,
+		old_url TEXT NOT NULL,
+		new_url TEXT NOT NULL,
+		reason TEXT DEFAULT '',
+		merged_title TEXT DEFAULT '',
+		changed_at DATETIME NOT NULL,
+		FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+	)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_feed_url_history_feed ON feed_url_history(feed_id, changed_at)`)
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS json_feed_options (
+		feed_id INTEGER PRIMARY KEY,
+		headers TEXT DEFAULT '',
+		next_page_path TEXT DEFAULT '',
+		page_param TEXT DEFAULT '',
+		max_pages INTEGER DEFAULT 0,
+		FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+	)`)
+
 	return nil
 }
 
+// migrateRuleActions rewrites rule actions stored as plain strings ("favorite")
+// into action objects ({"type": "favorite"}) in the rules setting.
+func migrateRuleActions(db *sql.DB) error {
+	var rulesJSON string
+	err := db.QueryRow(`SELECT value FROM settings WHERE key = 'rules'`).Scan(&rulesJSON)
+	if err != nil || rulesJSON == "" {
+		return nil
+	}
+
+	var rules []map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(rulesJSON), &rules); err != nil {
+		log.Printf("Skipping rule actions migration, invalid rules setting: %v", err)
+		return nil
+	}
+
+	changed := false
+	for _, rule := range rules {
+		var actions []json.RawMessage
+		if err := json.Unmarshal(rule["actions"], &actions); err != nil {
+			continue
+		}
+		ruleChanged := false
+		for i, action := range actions {
+			var name string
+			if json.Unmarshal(action, &name) != nil {
+				continue
+			}
+			converted, err := json.Marshal(map[string]string{"type": name})
+			if err != nil {
+				return err
+			}
+			actions[i] = converted
+			ruleChanged = true
+		}
+		if ruleChanged {
+			encoded, err := json.Marshal(actions)
+			if err != nil {
+				return err
+			}
+			rule["actions"] = encoded
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`UPDATE settings SET value = ? WHERE key = 'rules'`, string(encoded))
+	return err
+}
+
 // migrateUniqueIDOnArticles adds unique_id column and generates values for existing articles.
 // This replaces URL-based deduplication with title+feed_id+published_date based deduplication.
 func migrateUniqueIDOnArticles(db *sql.DB) error {
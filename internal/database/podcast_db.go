@@ -0,0 +1,344 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+const podcastEpisodeColumns = `
+	e.article_id, e.feed_id, e.enclosure_url, COALESCE(e.enclosure_type, ''), COALESCE(e.enclosure_length, 0),
+	COALESCE(e.duration, 0), COALESCE(e.season, 0), COALESCE(e.episode, 0), COALESCE(e.episode_type, ''),
+	COALESCE(e.chapters, ''), COALESCE(e.chapters_url, ''), COALESCE(e.transcript_url, ''), COALESCE(e.transcript_type, ''),
+	COALESCE(e.position, 0), COALESCE(e.played, 0), COALESCE(e.download_status, ''), COALESCE(e.download_path, ''),
+	COALESCE(e.downloaded_bytes, 0), COALESCE(e.download_error, ''), e.downloaded_at,
+	COALESCE(a.title, ''), COALESCE(f.title, ''), a.published_at`
+
+const podcastEpisodeJoins = `
+	FROM podcast_episodes e
+	LEFT JOIN articles a ON a.id = e.article_id
+	LEFT JOIN feeds f ON f.id = e.feed_id`
+
+// SavePodcastEpisode stores the feed metadata of an episode. The playback position and
+// download state of an existing episode are kept. It reports whether the episode is new.
+func (db *DB) SavePodcastEpisode(ep *models.PodcastEpisode) (bool, error) {
+	db.WaitForReady()
+
+	chapters := ""
+	if len(ep.Chapters) > 0 {
+		data, err := json.Marshal(ep.Chapters)
+		if err != nil {
+			return false, err
+		}
+		chapters = string(data)
+	}
+
+	result, err := db.Exec(`
+		INSERT OR IGNORE INTO podcast_episodes (
+			article_id, feed_id, enclosure_url, enclosure_type, enclosure_length, duration, season, episode,
+			episode_type, chapters, chapters_url, transcript_url, transcript_type
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		ep.ArticleID, ep.FeedID, ep.EnclosureURL, ep.EnclosureType, ep.EnclosureLength, ep.Duration, ep.Season, ep.Episode,
+		ep.EpisodeType, chapters, ep.ChaptersURL, ep.TranscriptURL, ep.TranscriptType,
+	)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		return true, nil
+	}
+
+	// Feeds often omit the duration, keep the one reported by the player in that case.
+	// Chapters loaded from the chapters URL are kept while the URL does not change.
+	_, err = db.Exec(`
+		UPDATE podcast_episodes SET
+			enclosure_url = ?, enclosure_type = ?, enclosure_length = ?,
+			duration = CASE WHEN ? > 0 THEN ? ELSE duration END,
+			season = ?, episode = ?, episode_type = ?,
+			chapters = CASE WHEN ? != '' OR chapters_url != ? THEN ? ELSE chapters END, chapters_url = ?,
+			transcript_url = ?, transcript_type = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE article_id = ?`,
+		ep.EnclosureURL, ep.EnclosureType, ep.EnclosureLength,
+		ep.Duration, ep.Duration,
+		ep.Season, ep.Episode, ep.EpisodeType,
+		chapters, ep.ChaptersURL, chapters, ep.ChaptersURL,
+		ep.TranscriptURL, ep.TranscriptType, ep.ArticleID,
+	)
+	return false, err
+}
+
+// EnsurePodcastEpisode returns the episode of an article, creating it from the article's
+// audio URL for articles saved before podcast support. It returns nil if the article has no audio.
+func (db *DB) EnsurePodcastEpisode(articleID int64) (*models.PodcastEpisode, error) {
+	db.WaitForReady()
+	_, err := db.Exec(`
+		INSERT OR IGNORE INTO podcast_episodes (article_id, feed_id, enclosure_url)
+		SELECT id, feed_id, audio_url FROM articles WHERE id = ? AND COALESCE(audio_url, '') != ''`,
+		articleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetPodcastEpisode(articleID)
+}
+
+// GetPodcastEpisode returns the episode of an article, or nil if it has none
+func (db *DB) GetPodcastEpisode(articleID int64) (*models.PodcastEpisode, error) {
+	db.WaitForReady()
+	row := db.QueryRow(`SELECT `+podcastEpisodeColumns+podcastEpisodeJoins+` WHERE e.article_id = ?`, articleID)
+	ep, err := scanPodcastEpisode(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return ep, err
+}
+
+// GetPodcastDownloads returns the episodes that are downloaded or queued for download,
+// newest first. A feedID of 0 returns the episodes of all feeds.
+func (db *DB) GetPodcastDownloads(feedID int64) ([]models.PodcastEpisode, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT `+podcastEpisodeColumns+podcastEpisodeJoins+`
+		WHERE e.download_status != '' AND (? = 0 OR e.feed_id = ?)
+		ORDER BY a.published_at DESC, e.article_id DESC`,
+		feedID, feedID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPodcastEpisodes(rows)
+}
+
+// GetEvictablePodcastDownloads returns the completed downloads in the order they should be
+// deleted to free space: played episodes first, then the oldest downloads
+func (db *DB) GetEvictablePodcastDownloads() ([]models.PodcastEpisode, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT ` + podcastEpisodeColumns + podcastEpisodeJoins + `
+		WHERE e.download_status = 'done'
+		ORDER BY e.played DESC, e.downloaded_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPodcastEpisodes(rows)
+}
+
+// GetOrphanedPodcastEpisodes returns the episodes whose article was deleted
+func (db *DB) GetOrphanedPodcastEpisodes() ([]models.PodcastEpisode, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT ` + podcastEpisodeColumns + podcastEpisodeJoins + ` WHERE a.id IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPodcastEpisodes(rows)
+}
+
+// DeletePodcastEpisode removes the episode of an article
+func (db *DB) DeletePodcastEpisode(articleID int64) error {
+	db.WaitForReady()
+	_, err := db.Exec(`DELETE FROM podcast_episodes WHERE article_id = ?`, articleID)
+	return err
+}
+
+// SetPodcastChapters stores the chapters of an episode
+func (db *DB) SetPodcastChapters(articleID int64, chapters []models.PodcastChapter) error {
+	db.WaitForReady()
+	data, err := json.Marshal(chapters)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`UPDATE podcast_episodes SET chapters = ? WHERE article_id = ?`, string(data), articleID)
+	return err
+}
+
+// UpdatePodcastProgress stores the playback position of an episode. A duration of 0 keeps
+// the known duration.
+func (db *DB) UpdatePodcastProgress(articleID int64, position, duration float64, played bool) error {
+	db.WaitForReady()
+	_, err := db.Exec(`
+		UPDATE podcast_episodes SET position = ?, duration = CASE WHEN ? > 0 THEN ? ELSE duration END,
+			played = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE article_id = ?`,
+		position, duration, duration, played, articleID,
+	)
+	return err
+}
+
+// QueuePodcastDownload queues an episode for download unless it is already downloaded or queued.
+// It reports whether the episode was queued.
+func (db *DB) QueuePodcastDownload(articleID int64) (bool, error) {
+	db.WaitForReady()
+	result, err := db.Exec(`
+		UPDATE podcast_episodes SET download_status = 'queued', download_error = '', queued_at = CURRENT_TIMESTAMP
+		WHERE article_id = ? AND download_status NOT IN ('queued', 'downloading', 'done')`,
+		articleID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// ClaimPodcastDownload marks the oldest queued episode as downloading and returns it,
+// or nil if the queue is empty
+func (db *DB) ClaimPodcastDownload() (*models.PodcastEpisode, error) {
+	db.WaitForReady()
+	for {
+		var articleID int64
+		err := db.QueryRow(`SELECT article_id FROM podcast_episodes WHERE download_status = 'queued' ORDER BY queued_at, article_id LIMIT 1`).Scan(&articleID)
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := db.Exec(`UPDATE podcast_episodes SET download_status = 'downloading' WHERE article_id = ? AND download_status = 'queued'`, articleID)
+		if err != nil {
+			return nil, err
+		}
+		// Another worker may have claimed the episode in the meantime
+		if n, _ := result.RowsAffected(); n > 0 {
+			return db.GetPodcastEpisode(articleID)
+		}
+	}
+}
+
+// CompletePodcastDownload records a finished download
+func (db *DB) CompletePodcastDownload(articleID int64, path string, size int64) error {
+	db.WaitForReady()
+	_, err := db.Exec(`
+		UPDATE podcast_episodes SET download_status = 'done', download_path = ?, downloaded_bytes = ?,
+			download_error = '', downloaded_at = ?
+		WHERE article_id = ?`,
+		path, size, time.Now(), articleID,
+	)
+	return err
+}
+
+// FailPodcastDownload records a failed download
+func (db *DB) FailPodcastDownload(articleID int64, message string) error {
+	db.WaitForReady()
+	_, err := db.Exec(`
+		UPDATE podcast_episodes SET download_status = 'failed', download_path = '', downloaded_bytes = 0, download_error = ?
+		WHERE article_id = ?`,
+		message, articleID,
+	)
+	return err
+}
+
+// ClearPodcastDownload forgets the download of an episode, after its file was deleted
+func (db *DB) ClearPodcastDownload(articleID int64) error {
+	db.WaitForReady()
+	_, err := db.Exec(`
+		UPDATE podcast_episodes SET download_status = '', download_path = '', downloaded_bytes = 0,
+			download_error = '', queued_at = NULL, downloaded_at = NULL
+		WHERE article_id = ?`,
+		articleID,
+	)
+	return err
+}
+
+// RequeueInterruptedPodcastDownloads queues the downloads that were running when the app stopped
+func (db *DB) RequeueInterruptedPodcastDownloads() error {
+	db.WaitForReady()
+	_, err := db.Exec(`UPDATE podcast_episodes SET download_status = 'queued' WHERE download_status = 'downloading'`)
+	return err
+}
+
+// GetPodcastDownloadedBytes returns the total size of the downloaded episodes
+func (db *DB) GetPodcastDownloadedBytes() (int64, error) {
+	db.WaitForReady()
+	var total int64
+	err := db.QueryRow(`SELECT COALESCE(SUM(downloaded_bytes), 0) FROM podcast_episodes WHERE download_status = 'done'`).Scan(&total)
+	return total, err
+}
+
+// GetPodcastFeedSettings returns the podcast settings of a feed, with defaults if none were saved
+func (db *DB) GetPodcastFeedSettings(feedID int64) (*models.PodcastFeedSettings, error) {
+	db.WaitForReady()
+	s := &models.PodcastFeedSettings{FeedID: feedID}
+	err := db.QueryRow(
+		`SELECT COALESCE(auto_download, 0), COALESCE(keep_episodes, 0), COALESCE(retention_days, 0) FROM podcast_feed_settings WHERE feed_id = ?`,
+		feedID,
+	).Scan(&s.AutoDownload, &s.KeepEpisodes, &s.RetentionDays)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetAllPodcastFeedSettings returns the saved podcast settings of all feeds
+func (db *DB) GetAllPodcastFeedSettings() ([]models.PodcastFeedSettings, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT feed_id, COALESCE(auto_download, 0), COALESCE(keep_episodes, 0), COALESCE(retention_days, 0) FROM podcast_feed_settings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var settings []models.PodcastFeedSettings
+	for rows.Next() {
+		var s models.PodcastFeedSettings
+		if err := rows.Scan(&s.FeedID, &s.AutoDownload, &s.KeepEpisodes, &s.RetentionDays); err != nil {
+			return nil, err
+		}
+		settings = append(settings, s)
+	}
+	return settings, rows.Err()
+}
+
+// SavePodcastFeedSettings stores the podcast settings of a feed
+func (db *DB) SavePodcastFeedSettings(s *models.PodcastFeedSettings) error {
+	db.WaitForReady()
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO podcast_feed_settings (feed_id, auto_download, keep_episodes, retention_days) VALUES (?, ?, ?, ?)`,
+		s.FeedID, s.AutoDownload, s.KeepEpisodes, s.RetentionDays,
+	)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPodcastEpisode(row rowScanner) (*models.PodcastEpisode, error) {
+	var ep models.PodcastEpisode
+	var chapters string
+	var downloadedAt, publishedAt sql.NullTime
+	err := row.Scan(
+		&ep.ArticleID, &ep.FeedID, &ep.EnclosureURL, &ep.EnclosureType, &ep.EnclosureLength,
+		&ep.Duration, &ep.Season, &ep.Episode, &ep.EpisodeType,
+		&chapters, &ep.ChaptersURL, &ep.TranscriptURL, &ep.TranscriptType,
+		&ep.Position, &ep.Played, &ep.DownloadStatus, &ep.DownloadPath,
+		&ep.DownloadedBytes, &ep.DownloadError, &downloadedAt,
+		&ep.Title, &ep.FeedTitle, &publishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if chapters != "" {
+		_ = json.Unmarshal([]byte(chapters), &ep.Chapters)
+	}
+	if downloadedAt.Valid {
+		ep.DownloadedAt = &downloadedAt.Time
+	}
+	ep.PublishedAt = publishedAt.Time
+	return &ep, nil
+}
+
+func scanPodcastEpisodes(rows *sql.Rows) ([]models.PodcastEpisode, error) {
+	var episodes []models.PodcastEpisode
+	for rows.Next() {
+		ep, err := scanPodcastEpisode(rows)
+		if err != nil {
+			return nil, err
+		}
+		episodes = append(episodes, *ep)
+	}
+	return episodes, rows.Err()
+}
@@ -40,6 +40,29 @@ func (db *DB) GetSavedFilters() ([]models.SavedFilter, error) {
 	return filters, nil
 }
 
+// GetSavedFilter retrieves a saved filter by ID, or nil if it does not exist
+func (db *DB) GetSavedFilter(id int64) (*models.SavedFilter, error) {
+	db.WaitForReady()
+
+	var f models.SavedFilter
+	var createdAt, updatedAt string
+	err := db.QueryRow(`
+		SELECT id, name, conditions, position, created_at, updated_at
+		FROM saved_filters
+		WHERE id = ?
+	`, id).Scan(&f.ID, &f.Name, &f.Conditions, &f.Position, &createdAt, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	f.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return &f, nil
+}
+
 // AddSavedFilter creates a new saved filter
 func (db *DB) AddSavedFilter(filter *models.SavedFilter) (int64, error) {
 	db.WaitForReady()
@@ -0,0 +1,160 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/models"
+)
+
+// maxScriptRunsPerFeed is the number of script runs kept per feed
+const maxScriptRunsPerFeed = 20
+
+// GetScriptFeedSettings returns the run options of a script feed, with defaults if none were
+// saved. Secrets are decrypted.
+func (db *DB) GetScriptFeedSettings(feedID int64) (*models.ScriptFeedSettings, error) {
+	db.WaitForReady()
+	s := &models.ScriptFeedSettings{FeedID: feedID, Args: []string{}, Env: map[string]string{}, Secrets: map[string]string{}}
+	var args, env, secrets string
+	err := db.QueryRow(
+		`SELECT COALESCE(args, ''), COALESCE(env, ''), COALESCE(secrets, ''), COALESCE(timeout_seconds, 0) FROM script_feed_settings WHERE feed_id = ?`,
+		feedID,
+	).Scan(&args, &env, &secrets, &s.TimeoutSeconds)
+	if err == sql.ErrNoRows {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if args != "" {
+		if err := json.Unmarshal([]byte(args), &s.Args); err != nil {
+			return nil, fmt.Errorf("invalid script arguments: %w", err)
+		}
+	}
+	if env != "" {
+		if err := json.Unmarshal([]byte(env), &s.Env); err != nil {
+			return nil, fmt.Errorf("invalid script environment: %w", err)
+		}
+	}
+	if secrets != "" {
+		decrypted, err := crypto.Decrypt(secrets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt script secrets: %w", err)
+		}
+		if err := json.Unmarshal([]byte(decrypted), &s.Secrets); err != nil {
+			return nil, fmt.Errorf("invalid script secrets: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// SaveScriptFeedSettings stores the run options of a script feed. The script state is kept.
+func (db *DB) SaveScriptFeedSettings(s *models.ScriptFeedSettings) error {
+	db.WaitForReady()
+	args, err := json.Marshal(s.Args)
+	if err != nil {
+		return err
+	}
+	env, err := json.Marshal(s.Env)
+	if err != nil {
+		return err
+	}
+	secrets := ""
+	if len(s.Secrets) > 0 {
+		data, err := json.Marshal(s.Secrets)
+		if err != nil {
+			return err
+		}
+		secrets, err = crypto.Encrypt(string(data))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt script secrets: %w", err)
+		}
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO script_feed_settings (feed_id, args, env, secrets, timeout_seconds) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(feed_id) DO UPDATE SET
+			args = excluded.args, env = excluded.env, secrets = excluded.secrets, timeout_seconds = excluded.timeout_seconds`,
+		s.FeedID, string(args), string(env), secrets, s.TimeoutSeconds,
+	)
+	return err
+}
+
+// GetScriptState returns the state a feed script saved in its last run, or nil
+func (db *DB) GetScriptState(feedID int64) ([]byte, error) {
+	db.WaitForReady()
+	var state string
+	err := db.QueryRow(`SELECT COALESCE(state, '') FROM script_feed_settings WHERE feed_id = ?`, feedID).Scan(&state)
+	if err == sql.ErrNoRows || state == "" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// SaveScriptState stores the state of a feed script for its next run
+func (db *DB) SaveScriptState(feedID int64, state []byte) error {
+	db.WaitForReady()
+	_, err := db.Exec(
+		`INSERT INTO script_feed_settings (feed_id, state) VALUES (?, ?) ON CONFLICT(feed_id) DO UPDATE SET state = excluded.state`,
+		feedID, string(state),
+	)
+	return err
+}
+
+// AddScriptRun records a run of a feed script and drops the oldest runs of the feed
+func (db *DB) AddScriptRun(run *models.ScriptRun) error {
+	db.WaitForReady()
+	result, err := db.Exec(
+		`INSERT INTO script_runs (feed_id, started_at, duration_ms, exit_code, stderr, error, item_count) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		run.FeedID, run.StartedAt.UTC(), run.DurationMs, run.ExitCode, run.Stderr, run.Error, run.ItemCount,
+	)
+	if err != nil {
+		return err
+	}
+	run.ID, _ = result.LastInsertId()
+
+	_, err = db.Exec(`
+		DELETE FROM script_runs WHERE feed_id = ? AND id NOT IN (
+			SELECT id FROM script_runs WHERE feed_id = ? ORDER BY started_at DESC, id DESC LIMIT ?
+		)`,
+		run.FeedID, run.FeedID, maxScriptRunsPerFeed,
+	)
+	return err
+}
+
+// GetScriptRuns returns the recent runs of a feed script, newest first
+func (db *DB) GetScriptRuns(feedID int64, limit int) ([]models.ScriptRun, error) {
+	db.WaitForReady()
+	if limit <= 0 || limit > maxScriptRunsPerFeed {
+		limit = maxScriptRunsPerFeed
+	}
+	rows, err := db.Query(`
+		SELECT id, feed_id, started_at, COALESCE(duration_ms, 0), COALESCE(exit_code, 0),
+			COALESCE(stderr, ''), COALESCE(error, ''), COALESCE(item_count, 0)
+		FROM script_runs WHERE feed_id = ? ORDER BY started_at DESC, id DESC LIMIT ?`,
+		feedID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make([]models.ScriptRun, 0)
+	for rows.Next() {
+		var run models.ScriptRun
+		var startedAt time.Time
+		if err := rows.Scan(&run.ID, &run.FeedID, &startedAt, &run.DurationMs, &run.ExitCode, &run.Stderr, &run.Error, &run.ItemCount); err != nil {
+			return nil, err
+		}
+		run.StartedAt = startedAt
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
@@ -1,6 +1,8 @@
 package database_test
 
 import (
+	"encoding/json"
+	"path/filepath"
 	"testing"
 
 	"MrRSS/internal/config"
@@ -113,3 +115,51 @@ func TestInitInsertsDefaults(t *testing.T) {
 		t.Fatalf("default setting %s = %q, want %q", key, got, want)
 	}
 }
+
+func TestMigrateRuleActions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.db")
+
+	db, err := dbpkg.NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	legacy := `[{"id":1,"name":"r","enabled":true,"conditions":[],"actions":["favorite",{"type":"add_tag","params":{"tag_id":"2"}}]}]`
+	if err := db.SetSetting("rules", legacy); err != nil {
+		t.Fatalf("SetSetting() error = %v", err)
+	}
+	db.Close()
+
+	// Reopening runs the migrations again
+	db, err = dbpkg.NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	defer db.Close()
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	got, err := db.GetSetting("rules")
+	if err != nil {
+		t.Fatalf("GetSetting() error = %v", err)
+	}
+	var rules []struct {
+		Name    string `json:"name"`
+		Actions []struct {
+			Type   string            `json:"type"`
+			Params map[string]string `json:"params"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(got), &rules); err != nil {
+		t.Fatalf("rules setting is not valid after migration: %v (%s)", err, got)
+	}
+	if len(rules) != 1 || rules[0].Name != "r" || len(rules[0].Actions) != 2 {
+		t.Fatalf("unexpected rules after migration: %s", got)
+	}
+	if rules[0].Actions[0].Type != "favorite" || rules[0].Actions[1].Type != "add_tag" || rules[0].Actions[1].Params["tag_id"] != "2" {
+		t.Errorf("unexpected actions after migration: %s", got)
+	}
+}
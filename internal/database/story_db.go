@@ -0,0 +1,184 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// ArticleFingerprint is what near-duplicate detection stores about an article
+type ArticleFingerprint struct {
+	ArticleID    int64
+	FeedID       int64
+	StoryID      int64 // ID of the primary article of the story, 0 if no duplicate was found yet
+	CanonicalURL string
+	TitleHash    uint64 // SimHash of the title words
+	TitleTerms   int    // Number of title words, short titles are not compared
+	ContentHash  []byte // MinHash signature of the content, empty if the content is too short
+	PublishedAt  time.Time
+}
+
+// GetFingerprintedArticleIDs returns which of the given articles already have a fingerprint
+func (db *DB) GetFingerprintedArticleIDs(articleIDs []int64) (map[int64]bool, error) {
+	db.WaitForReady()
+
+	result := make(map[int64]bool)
+	for start := 0; start < len(articleIDs); start += maxQueryParams {
+		end := min(start+maxQueryParams, len(articleIDs))
+		placeholders, args := idPlaceholders(articleIDs[start:end])
+
+		rows, err := db.Query(`SELECT article_id FROM article_fingerprints WHERE article_id IN (`+placeholders+`)`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			result[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// GetFingerprintsSince returns the fingerprints of the articles published since the given time
+func (db *DB) GetFingerprintsSince(since time.Time) ([]ArticleFingerprint, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`
+		SELECT fp.article_id, a.feed_id, fp.story_id, COALESCE(fp.canonical_url, ''), fp.title_hash, fp.title_terms, fp.content_hash, a.published_at
+		FROM article_fingerprints fp
+		INNER JOIN articles a ON a.id = fp.article_id
+		WHERE a.published_at >= ?
+		ORDER BY fp.article_id`,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fingerprints []ArticleFingerprint
+	for rows.Next() {
+		var fp ArticleFingerprint
+		var titleHash int64
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&fp.ArticleID, &fp.FeedID, &fp.StoryID, &fp.CanonicalURL, &titleHash, &fp.TitleTerms, &fp.ContentHash, &publishedAt); err != nil {
+			return nil, err
+		}
+		fp.TitleHash = uint64(titleHash)
+		fp.PublishedAt = publishedAt.Time
+		fingerprints = append(fingerprints, fp)
+	}
+	return fingerprints, rows.Err()
+}
+
+// SaveArticleFingerprint stores or replaces the fingerprint of an article
+func (db *DB) SaveArticleFingerprint(fp *ArticleFingerprint) error {
+	db.WaitForReady()
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO article_fingerprints (article_id, story_id, canonical_url, title_hash, title_terms, content_hash, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		fp.ArticleID, fp.StoryID, fp.CanonicalURL, int64(fp.TitleHash), fp.TitleTerms, fp.ContentHash,
+	)
+	return err
+}
+
+// SetArticleStory assigns an article to a story
+func (db *DB) SetArticleStory(articleID, storyID int64) error {
+	db.WaitForReady()
+	_, err := db.Exec(`UPDATE article_fingerprints SET story_id = ? WHERE article_id = ?`, storyID, articleID)
+	return err
+}
+
+// GetStoryArticleIDs returns the IDs of the articles of the story an article belongs to,
+// oldest first. It returns nil if the article has no near-duplicates.
+func (db *DB) GetStoryArticleIDs(articleID int64) ([]int64, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`
+		SELECT m.article_id
+		FROM article_fingerprints fp
+		INNER JOIN article_fingerprints m ON m.story_id = fp.story_id
+		INNER JOIN articles a ON a.id = m.article_id
+		WHERE fp.article_id = ? AND fp.story_id != 0
+		ORDER BY a.published_at ASC, a.id ASC`,
+		articleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetStorySiblings returns the story of each of the given articles that has near-duplicates,
+// as the story ID and the other articles of the story, keyed by article ID
+func (db *DB) GetStorySiblings(articleIDs []int64) (map[int64]int64, map[int64][]models.StorySibling, error) {
+	db.WaitForReady()
+
+	stories := make(map[int64]int64)
+	siblings := make(map[int64][]models.StorySibling)
+	for start := 0; start < len(articleIDs); start += maxQueryParams {
+		end := min(start+maxQueryParams, len(articleIDs))
+		placeholders, args := idPlaceholders(articleIDs[start:end])
+
+		rows, err := db.Query(`
+			SELECT fp.article_id, fp.story_id, a.id, a.feed_id, COALESCE(f.title, ''), a.title, a.url, a.is_read, a.published_at
+			FROM article_fingerprints fp
+			INNER JOIN article_fingerprints m ON m.story_id = fp.story_id AND m.article_id != fp.article_id
+			INNER JOIN articles a ON a.id = m.article_id
+			LEFT JOIN feeds f ON f.id = a.feed_id
+			WHERE fp.article_id IN (`+placeholders+`) AND fp.story_id != 0
+			ORDER BY a.published_at ASC, a.id ASC`,
+			args...,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		for rows.Next() {
+			var articleID, storyID int64
+			var s models.StorySibling
+			var publishedAt sql.NullTime
+			if err := rows.Scan(&articleID, &storyID, &s.ID, &s.FeedID, &s.FeedTitle, &s.Title, &s.URL, &s.IsRead, &publishedAt); err != nil {
+				rows.Close()
+				return nil, nil, err
+			}
+			s.IsPrimary = s.ID == storyID
+			s.PublishedAt = publishedAt.Time
+			stories[articleID] = storyID
+			siblings[articleID] = append(siblings[articleID], s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return stories, siblings, nil
+}
+
+// idPlaceholders returns the "?" placeholders and arguments of an IN clause over IDs
+func idPlaceholders(ids []int64) (string, []interface{}) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return strings.Join(placeholders, ","), args
+}
@@ -86,9 +86,14 @@ func (db *DB) UpdateTag(id int64, name, color string, position int) error {
 
 // DeleteTag deletes a tag by ID.
 // Note: ON DELETE CASCADE will automatically remove feed_tags associations.
+// Article associations are removed explicitly.
 func (db *DB) DeleteTag(id int64) error {
 	db.WaitForReady()
 
+	if _, err := db.Exec(`DELETE FROM article_tags WHERE tag_id = ?`, id); err != nil {
+		return err
+	}
+
 	query := `DELETE FROM tags WHERE id = ?`
 	_, err := db.Exec(query, id)
 	return err
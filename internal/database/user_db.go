@@ -0,0 +1,195 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// CreateUser inserts a new user with an already hashed password and returns its ID
+func (db *DB) CreateUser(username, passwordHash string, isAdmin bool) (int64, error) {
+	db.WaitForReady()
+	result, err := db.Exec(
+		`INSERT INTO users (username, password_hash, is_admin, created_at) VALUES (?, ?, ?, ?)`,
+		username, passwordHash, isAdmin, time.Now(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert user: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// scanUser scans a user row selected with userColumns
+func scanUser(row interface{ Scan(...any) error }) (*models.User, error) {
+	var u models.User
+	var lastLogin sql.NullTime
+	if err := row.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.IsAdmin, &u.CreatedAt, &lastLogin); err != nil {
+		return nil, err
+	}
+	if lastLogin.Valid {
+		u.LastLoginAt = &lastLogin.Time
+	}
+	return &u, nil
+}
+
+const userColumns = `id, username, password_hash, is_admin, created_at, last_login_at`
+
+// GetUserByUsername retrieves a user by username (case-insensitive). Returns nil if not found.
+func (db *DB) GetUserByUsername(username string) (*models.User, error) {
+	db.WaitForReady()
+	u, err := scanUser(db.QueryRow(`SELECT `+userColumns+` FROM users WHERE username = ?`, username))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return u, err
+}
+
+// GetUserByID retrieves a user by ID. Returns nil if not found.
+func (db *DB) GetUserByID(id int64) (*models.User, error) {
+	db.WaitForReady()
+	u, err := scanUser(db.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return u, err
+}
+
+// GetUsers retrieves all users ordered by ID
+func (db *DB) GetUsers() ([]models.User, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT ` + userColumns + ` FROM users ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+	return users, rows.Err()
+}
+
+// CountUsers returns the number of registered users
+func (db *DB) CountUsers() (int, error) {
+	db.WaitForReady()
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count)
+	return count, err
+}
+
+// UpdateUserPassword replaces a user's password hash and signs out all of their sessions
+func (db *DB) UpdateUserPassword(id int64, passwordHash string) error {
+	db.WaitForReady()
+	if _, err := db.Exec(`UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, id); err != nil {
+		return err
+	}
+	_, err := db.Exec(`DELETE FROM user_sessions WHERE user_id = ?`, id)
+	return err
+}
+
+// SetUserFeverAPIKey stores the digest of a user's Fever API key
+func (db *DB) SetUserFeverAPIKey(id int64, apiKeyHash string) error {
+	db.WaitForReady()
+	_, err := db.Exec(`UPDATE users SET fever_api_key_hash = ? WHERE id = ?`, apiKeyHash, id)
+	return err
+}
+
+// GetUserByFeverAPIKey retrieves the user owning a Fever API key digest. Returns nil if not found.
+func (db *DB) GetUserByFeverAPIKey(apiKeyHash string) (*models.User, error) {
+	db.WaitForReady()
+	if apiKeyHash == "" {
+		return nil, nil
+	}
+	u, err := scanUser(db.QueryRow(`SELECT `+userColumns+` FROM users WHERE fever_api_key_hash = ?`, apiKeyHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return u, err
+}
+
+// SetUserFeedToken stores the digest of a user's output feed token. An empty digest revokes it.
+func (db *DB) SetUserFeedToken(id int64, tokenHash string) error {
+	db.WaitForReady()
+	_, err := db.Exec(`UPDATE users SET feed_token_hash = NULLIF(?, '') WHERE id = ?`, tokenHash, id)
+	return err
+}
+
+// GetUserByFeedToken retrieves the user owning an output feed token digest. Returns nil if not found.
+func (db *DB) GetUserByFeedToken(tokenHash string) (*models.User, error) {
+	db.WaitForReady()
+	if tokenHash == "" {
+		return nil, nil
+	}
+	u, err := scanUser(db.QueryRow(`SELECT `+userColumns+` FROM users WHERE feed_token_hash = ?`, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return u, err
+}
+
+// UpdateUserLastLogin records the time of a successful login
+func (db *DB) UpdateUserLastLogin(id int64) error {
+	db.WaitForReady()
+	_, err := db.Exec(`UPDATE users SET last_login_at = ? WHERE id = ?`, time.Now(), id)
+	return err
+}
+
+// DeleteUser removes a user and all of their sessions
+func (db *DB) DeleteUser(id int64) error {
+	db.WaitForReady()
+	if _, err := db.Exec(`DELETE FROM user_sessions WHERE user_id = ?`, id); err != nil {
+		return err
+	}
+	_, err := db.Exec(`DELETE FROM users WHERE id = ?`, id)
+	return err
+}
+
+// CreateUserSession stores a session for a user. Only the token hash is persisted.
+func (db *DB) CreateUserSession(userID int64, tokenHash string, expiresAt time.Time) error {
+	db.WaitForReady()
+	_, err := db.Exec(
+		`INSERT INTO user_sessions (token_hash, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)`,
+		tokenHash, userID, time.Now().Unix(), expiresAt.Unix(),
+	)
+	return err
+}
+
+// GetSessionUser returns the user owning an unexpired session, or nil if the
+// session does not exist or has expired.
+func (db *DB) GetSessionUser(tokenHash string) (*models.User, error) {
+	db.WaitForReady()
+	u, err := scanUser(db.QueryRow(`
+		SELECT u.id, u.username, u.password_hash, u.is_admin, u.created_at, u.last_login_at
+		FROM user_sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.token_hash = ? AND s.expires_at > ?
+	`, tokenHash, time.Now().Unix()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return u, err
+}
+
+// DeleteUserSession removes a single session
+func (db *DB) DeleteUserSession(tokenHash string) error {
+	db.WaitForReady()
+	_, err := db.Exec(`DELETE FROM user_sessions WHERE token_hash = ?`, tokenHash)
+	return err
+}
+
+// DeleteExpiredUserSessions removes all expired sessions
+func (db *DB) DeleteExpiredUserSessions() (int64, error) {
+	db.WaitForReady()
+	result, err := db.Exec(`DELETE FROM user_sessions WHERE expires_at <= ?`, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
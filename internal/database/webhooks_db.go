@@ -0,0 +1,297 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/models"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+const webhookColumns = `id, name, url, COALESCE(secret, ''), events, COALESCE(conditions, ''), enabled, created_at, updated_at`
+
+// GetWebhooks retrieves all webhooks. Secrets are decrypted.
+func (db *DB) GetWebhooks() ([]models.Webhook, error) {
+	db.WaitForReady()
+
+	rows, err := db.Query(`SELECT ` + webhookColumns + ` FROM webhooks ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]models.Webhook, 0)
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *w)
+	}
+	return webhooks, rows.Err()
+}
+
+// GetWebhook retrieves a webhook by ID, or nil if it does not exist. The secret is decrypted.
+func (db *DB) GetWebhook(id int64) (*models.Webhook, error) {
+	db.WaitForReady()
+
+	w, err := scanWebhook(db.QueryRow(`SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return w, err
+}
+
+// GetWebhooksForEvent retrieves the enabled webhooks subscribed to an event type
+func (db *DB) GetWebhooksForEvent(event string) ([]models.Webhook, error) {
+	webhooks, err := db.GetWebhooks()
+	if err != nil {
+		return nil, err
+	}
+
+	var subscribed []models.Webhook
+	for _, w := range webhooks {
+		if !w.Enabled {
+			continue
+		}
+		for _, e := range w.Events {
+			if e == event {
+				subscribed = append(subscribed, w)
+				break
+			}
+		}
+	}
+	return subscribed, nil
+}
+
+// AddWebhook stores a new webhook and returns its ID. The secret is encrypted.
+func (db *DB) AddWebhook(w *models.Webhook) (int64, error) {
+	db.WaitForReady()
+
+	events, secret, err := encodeWebhook(w)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO webhooks (name, url, secret, events, conditions, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, w.Name, w.URL, secret, events, w.Conditions, w.Enabled)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateWebhook updates a webhook. The stored secret is kept when keepSecret is true.
+func (db *DB) UpdateWebhook(w *models.Webhook, keepSecret bool) error {
+	db.WaitForReady()
+
+	events, secret, err := encodeWebhook(w)
+	if err != nil {
+		return err
+	}
+
+	if keepSecret {
+		_, err = db.Exec(`
+			UPDATE webhooks SET name = ?, url = ?, events = ?, conditions = ?, enabled = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, w.Name, w.URL, events, w.Conditions, w.Enabled, w.ID)
+	} else {
+		_, err = db.Exec(`
+			UPDATE webhooks SET name = ?, url = ?, secret = ?, events = ?, conditions = ?, enabled = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, w.Name, w.URL, secret, events, w.Conditions, w.Enabled, w.ID)
+	}
+	return err
+}
+
+// DeleteWebhook removes a webhook and its deliveries
+func (db *DB) DeleteWebhook(id int64) error {
+	db.WaitForReady()
+
+	if _, err := db.Exec(`DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+		return err
+	}
+	_, err := db.Exec(`DELETE FROM webhooks WHERE id = ?`, id)
+	return err
+}
+
+// EnqueueWebhookDelivery queues a payload for delivery and returns the delivery ID
+func (db *DB) EnqueueWebhookDelivery(webhookID int64, event, payload string) (int64, error) {
+	db.WaitForReady()
+
+	now := time.Now().UTC()
+	result, err := db.Exec(`
+		INSERT INTO webhook_deliveries (webhook_id, event, payload, status, attempts, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, 0, ?, ?)
+	`, webhookID, event, payload, WebhookDeliveryPending, now, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetDueWebhookDeliveries retrieves pending deliveries whose next attempt is due, oldest first
+func (db *DB) GetDueWebhookDeliveries(limit int) ([]models.WebhookDelivery, error) {
+	db.WaitForReady()
+
+	rows, err := db.Query(`
+		SELECT id, webhook_id, event, payload, status, attempts, response_status, COALESCE(error, ''), next_attempt_at, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at ASC, id ASC
+		LIMIT ?
+	`, WebhookDeliveryPending, time.Now().UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+// GetWebhookDeliveries retrieves the most recent deliveries, optionally for one webhook (0 for all)
+func (db *DB) GetWebhookDeliveries(webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	db.WaitForReady()
+
+	query := `
+		SELECT id, webhook_id, event, payload, status, attempts, response_status, COALESCE(error, ''), next_attempt_at, created_at, delivered_at
+		FROM webhook_deliveries`
+	var args []interface{}
+	if webhookID > 0 {
+		query += ` WHERE webhook_id = ?`
+		args = append(args, webhookID)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+// MarkWebhookDeliveryDelivered records a successful delivery attempt
+func (db *DB) MarkWebhookDeliveryDelivered(id int64, responseStatus int) error {
+	db.WaitForReady()
+
+	_, err := db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = attempts + 1, response_status = ?, error = '', delivered_at = ?
+		WHERE id = ?
+	`, WebhookDeliveryDelivered, responseStatus, time.Now().UTC(), id)
+	return err
+}
+
+// MarkWebhookDeliveryAttemptFailed records a failed delivery attempt. The delivery is retried
+// at nextAttempt, or marked failed if nextAttempt is zero.
+func (db *DB) MarkWebhookDeliveryAttemptFailed(id int64, responseStatus int, errMsg string, nextAttempt time.Time) error {
+	db.WaitForReady()
+
+	status := WebhookDeliveryPending
+	if nextAttempt.IsZero() {
+		status = WebhookDeliveryFailed
+		nextAttempt = time.Now()
+	}
+
+	_, err := db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = attempts + 1, response_status = ?, error = ?, next_attempt_at = ?
+		WHERE id = ?
+	`, status, responseStatus, errMsg, nextAttempt.UTC(), id)
+	return err
+}
+
+// RetryWebhookDelivery queues a delivery to be sent again immediately
+func (db *DB) RetryWebhookDelivery(id int64) error {
+	db.WaitForReady()
+
+	_, err := db.Exec(`
+		UPDATE webhook_deliveries SET status = ?, next_attempt_at = ? WHERE id = ?
+	`, WebhookDeliveryPending, time.Now().UTC(), id)
+	return err
+}
+
+// CleanupWebhookDeliveries removes finished deliveries older than maxAgeDays
+func (db *DB) CleanupWebhookDeliveries(maxAgeDays int) (int64, error) {
+	db.WaitForReady()
+
+	result, err := db.Exec(`
+		DELETE FROM webhook_deliveries WHERE status != ? AND created_at < ?
+	`, WebhookDeliveryPending, time.Now().UTC().AddDate(0, 0, -maxAgeDays))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// encodeWebhook returns the stored forms of a webhook's event list and secret
+func encodeWebhook(w *models.Webhook) (string, string, error) {
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret := w.Secret
+	if secret != "" {
+		secret, err = crypto.Encrypt(secret)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to encrypt webhook secret: %w", err)
+		}
+	}
+	return string(events), secret, nil
+}
+
+// scanWebhook scans a webhook row selected with webhookColumns
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*models.Webhook, error) {
+	var w models.Webhook
+	var events string
+	if err := row.Scan(&w.ID, &w.Name, &w.URL, &w.Secret, &events, &w.Conditions, &w.Enabled, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(events), &w.Events); err != nil {
+		w.Events = []string{}
+	}
+
+	if w.Secret != "" {
+		if crypto.IsEncrypted(w.Secret) {
+			secret, err := crypto.Decrypt(w.Secret)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt webhook secret: %w", err)
+			}
+			w.Secret = secret
+		}
+		w.HasSecret = true
+	}
+	return &w, nil
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]models.WebhookDelivery, error) {
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var deliveredAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.ResponseStatus, &d.Error, &d.NextAttemptAt, &d.CreatedAt, &deliveredAt); err != nil {
+			return nil, err
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+func TestWebhooks(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.DB.Close()
+
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	id, err := db.AddWebhook(&models.Webhook{
+		Name:    "Hook",
+		URL:     "https://example.com/hook",
+		Secret:  "s3cret",
+		Events:  []string{EventArticlesNew, EventFeedFetchFailed},
+		Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("AddWebhook failed: %v", err)
+	}
+
+	t.Run("Secret is encrypted at rest", func(t *testing.T) {
+		var stored string
+		db.QueryRow(`SELECT secret FROM webhooks WHERE id = ?`, id).Scan(&stored)
+		if stored == "" || stored == "s3cret" {
+			t.Errorf("Expected an encrypted secret, got %q", stored)
+		}
+
+		w, err := db.GetWebhook(id)
+		if err != nil || w == nil {
+			t.Fatalf("GetWebhook failed: %v", err)
+		}
+		if w.Secret != "s3cret" || !w.HasSecret || len(w.Events) != 2 {
+			t.Errorf("Unexpected webhook: %+v", w)
+		}
+	})
+
+	t.Run("Update keeps secret", func(t *testing.T) {
+		err := db.UpdateWebhook(&models.Webhook{ID: id, Name: "Renamed", URL: "https://example.com/hook", Events: []string{EventArticleStarred}, Enabled: true}, true)
+		if err != nil {
+			t.Fatalf("UpdateWebhook failed: %v", err)
+		}
+		w, _ := db.GetWebhook(id)
+		if w.Name != "Renamed" || w.Secret != "s3cret" {
+			t.Errorf("Unexpected webhook after update: %+v", w)
+		}
+
+		subscribed, _ := db.GetWebhooksForEvent(EventArticleStarred)
+		if len(subscribed) != 1 {
+			t.Errorf("Expected 1 subscribed webhook, got %d", len(subscribed))
+		}
+		subscribed, _ = db.GetWebhooksForEvent(EventArticlesNew)
+		if len(subscribed) != 0 {
+			t.Errorf("Expected no webhook for unsubscribed event, got %d", len(subscribed))
+		}
+	})
+
+	t.Run("Delivery queue", func(t *testing.T) {
+		deliveryID, err := db.EnqueueWebhookDelivery(id, EventArticleStarred, `{"event":"article.starred"}`)
+		if err != nil {
+			t.Fatalf("EnqueueWebhookDelivery failed: %v", err)
+		}
+
+		due, err := db.GetDueWebhookDeliveries(10)
+		if err != nil || len(due) != 1 || due[0].ID != deliveryID {
+			t.Fatalf("Expected the queued delivery to be due, got %+v, %v", due, err)
+		}
+
+		// A failed attempt with a retry time in the future is no longer due
+		if err := db.MarkWebhookDeliveryAttemptFailed(deliveryID, 500, "server error", time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("MarkWebhookDeliveryAttemptFailed failed: %v", err)
+		}
+		due, _ = db.GetDueWebhookDeliveries(10)
+		if len(due) != 0 {
+			t.Errorf("Expected no due deliveries, got %d", len(due))
+		}
+
+		if err := db.RetryWebhookDelivery(deliveryID); err != nil {
+			t.Fatalf("RetryWebhookDelivery failed: %v", err)
+		}
+		if err := db.MarkWebhookDeliveryDelivered(deliveryID, 204); err != nil {
+			t.Fatalf("MarkWebhookDeliveryDelivered failed: %v", err)
+		}
+
+		log, err := db.GetWebhookDeliveries(id, 10)
+		if err != nil || len(log) != 1 {
+			t.Fatalf("GetWebhookDeliveries failed: %+v, %v", log, err)
+		}
+		d := log[0]
+		if d.Status != WebhookDeliveryDelivered || d.Attempts != 2 || d.ResponseStatus != 204 || d.DeliveredAt == nil {
+			t.Errorf("Unexpected delivery: %+v", d)
+		}
+	})
+
+	t.Run("Delete removes deliveries", func(t *testing.T) {
+		if err := db.DeleteWebhook(id); err != nil {
+			t.Fatalf("DeleteWebhook failed: %v", err)
+		}
+		var count int
+		db.QueryRow(`SELECT COUNT(*) FROM webhook_deliveries`).Scan(&count)
+		if count != 0 {
+			t.Errorf("Expected deliveries to be removed, %d left", count)
+		}
+	})
+}
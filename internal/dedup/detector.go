@@ -0,0 +1,221 @@
+// Package dedup detects articles from different feeds that report the same story
+// and groups them into stories. The first article of a story that was fetched is its
+// primary article; later ones are its duplicates and can be hidden or marked as read.
+package dedup
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/summary"
+	"MrRSS/internal/utils/textutil"
+	"MrRSS/internal/utils/urlutil"
+)
+
+// Actions applied to duplicates, set by the duplicate_action setting
+const (
+	ActionNone     = "none"
+	ActionHide     = "hide"
+	ActionMarkRead = "mark_read"
+)
+
+const (
+	// window is how far apart the publication times of two articles of a story can be
+	window = 72 * time.Hour
+	// titleMaxDistance is the largest number of differing SimHash bits of duplicate titles
+	titleMaxDistance = 3
+	// minTitleTerms is the number of words a title needs to be compared, so that short
+	// generic titles ("Weekly update") do not join unrelated articles
+	minTitleTerms = 4
+	// contentThreshold is the estimated Jaccard similarity above which contents are duplicates
+	contentThreshold = 0.5
+	// minContentShingles is the number of word pairs content needs to be compared
+	minContentShingles = 20
+	// maxContentRunes is how much of the content is compared
+	maxContentRunes = 8000
+)
+
+// Article is a newly fetched article to check for duplicates
+type Article struct {
+	ID          int64
+	FeedID      int64
+	Title       string
+	URL         string
+	Content     string // HTML or plain text content, may be empty
+	PublishedAt time.Time
+}
+
+// Detector assigns newly fetched articles to stories
+type Detector struct {
+	db *database.DB
+	mu sync.Mutex // Serializes detection so that concurrent fetches do not start the same story twice
+}
+
+// NewDetector creates a detector for the articles stored in db
+func NewDetector(db *database.DB) *Detector {
+	return &Detector{db: db}
+}
+
+// Enabled reports whether duplicate detection is turned on
+func (d *Detector) Enabled() bool {
+	enabled, _ := d.db.GetSetting("duplicate_detection_enabled")
+	return enabled == "true"
+}
+
+// Process fingerprints the given articles and assigns those that duplicate an article of
+// another feed to its story, applying the duplicate action to them. Articles that were
+// already processed are skipped. It returns the number of duplicates found.
+func (d *Detector) Process(articles []Article) (int, error) {
+	if len(articles) == 0 || !d.Enabled() {
+		return 0, nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ids := make([]int64, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	processed, err := d.db.GetFingerprintedArticleIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	var pending []Article
+	oldest := time.Now()
+	for _, a := range articles {
+		if processed[a.ID] {
+			continue
+		}
+		processed[a.ID] = true
+		pending = append(pending, a)
+		if a.PublishedAt.Before(oldest) {
+			oldest = a.PublishedAt
+		}
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	candidates, err := d.db.GetFingerprintsSince(oldest.Add(-window))
+	if err != nil {
+		return 0, err
+	}
+	action, _ := d.db.GetSetting("duplicate_action")
+
+	found := 0
+	for _, a := range pending {
+		fp := fingerprint(a)
+		if match := bestMatch(&fp, candidates); match != nil {
+			if match.StoryID == 0 {
+				match.StoryID = match.ArticleID
+				if err := d.db.SetArticleStory(match.ArticleID, match.StoryID); err != nil {
+					return found, err
+				}
+			}
+			fp.StoryID = match.StoryID
+			found++
+		}
+
+		if err := d.db.SaveArticleFingerprint(&fp); err != nil {
+			return found, err
+		}
+		if fp.StoryID != 0 {
+			d.apply(action, a.ID)
+		}
+		candidates = append(candidates, fp)
+	}
+	return found, nil
+}
+
+// apply applies the duplicate action to a duplicate article
+func (d *Detector) apply(action string, articleID int64) {
+	var err error
+	switch action {
+	case ActionHide:
+		err = d.db.SetArticleHidden(articleID, true)
+	case ActionMarkRead:
+		err = d.db.MarkArticleRead(articleID, true)
+	}
+	if err != nil {
+		log.Printf("Error applying duplicate action %s to article %d: %v", action, articleID, err)
+	}
+}
+
+// fingerprint computes the fingerprint of an article
+func fingerprint(a Article) database.ArticleFingerprint {
+	fp := database.ArticleFingerprint{
+		ArticleID:    a.ID,
+		FeedID:       a.FeedID,
+		CanonicalURL: urlutil.CanonicalArticleURL(a.URL),
+		PublishedAt:  a.PublishedAt,
+	}
+
+	titleWords := words(a.Title)
+	fp.TitleHash = SimHash(titleWords)
+	fp.TitleTerms = len(titleWords)
+
+	content := []rune(textutil.StripHTML(a.Content))
+	if len(content) > maxContentRunes {
+		content = content[:maxContentRunes]
+	}
+	if shingles := Shingles(words(string(content))); len(shingles) >= minContentShingles {
+		fp.ContentHash = MinHash(shingles)
+	}
+	return fp
+}
+
+// words returns the words of a text, without stopwords and punctuation
+func words(text string) []string {
+	tokens := summary.Tokenize(text)
+	result := tokens[:0]
+	for _, token := range tokens {
+		if strings.IndexFunc(token, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+// bestMatch returns the candidate from another feed that is most likely the same story, or nil
+func bestMatch(fp *database.ArticleFingerprint, candidates []database.ArticleFingerprint) *database.ArticleFingerprint {
+	var best *database.ArticleFingerprint
+	bestScore := 0.0
+	for i := range candidates {
+		c := &candidates[i]
+		if c.FeedID == fp.FeedID || c.ArticleID == fp.ArticleID {
+			continue
+		}
+		if diff := fp.PublishedAt.Sub(c.PublishedAt); diff > window || diff < -window {
+			continue
+		}
+		if score := similarity(fp, c); score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// similarity returns how similar two articles are, or 0 if they are not duplicates.
+// Articles with the same canonical link are always duplicates.
+func similarity(a, b *database.ArticleFingerprint) float64 {
+	if a.CanonicalURL != "" && a.CanonicalURL == b.CanonicalURL {
+		return 1
+	}
+
+	score := 0.0
+	if s := Similarity(a.ContentHash, b.ContentHash); s >= contentThreshold {
+		score = s
+	}
+	if a.TitleTerms >= minTitleTerms && b.TitleTerms >= minTitleTerms {
+		if distance := HammingDistance(a.TitleHash, b.TitleHash); distance <= titleMaxDistance {
+			score = max(score, 1-float64(distance)/64)
+		}
+	}
+	return score
+}
@@ -0,0 +1,129 @@
+package dedup
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils/urlutil"
+)
+
+func TestCanonicalArticleURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"http://www.example.com/news/story/", "https://example.com/news/story"},
+		{"https://m.example.com/news/story?utm_source=rss#comments", "https://example.com/news/story"},
+		{"https://example.com/news/story/amp", "https://EXAMPLE.com/news/story"},
+		{"https://example.com/article?id=42&fbclid=abc", "https://example.com/article?id=42"},
+	}
+	for _, tt := range tests {
+		if a, b := urlutil.CanonicalArticleURL(tt.a), urlutil.CanonicalArticleURL(tt.b); a != b {
+			t.Errorf("Expected %q and %q to be the same article, got %q and %q", tt.a, tt.b, a, b)
+		}
+	}
+
+	if urlutil.CanonicalArticleURL("https://example.com/article?id=1") == urlutil.CanonicalArticleURL("https://example.com/article?id=2") {
+		t.Error("Expected different article IDs to stay different")
+	}
+	if got := urlutil.CanonicalArticleURL("mailto:someone@example.com"); got != "" {
+		t.Errorf("Expected no canonical URL for a non-web link, got %q", got)
+	}
+}
+
+func TestFingerprints(t *testing.T) {
+	body := "The central bank raised interest rates by a quarter point on Wednesday, citing persistent inflation in services and a tight labor market. Officials signaled that further increases remain possible if price pressures do not ease over the coming months, while several members argued for a pause."
+	a := fingerprint(Article{Title: "Central bank raises interest rates again", Content: "<p>" + body + "</p>"})
+	b := fingerprint(Article{Title: "Central bank raises interest rates again!", Content: body + " Markets fell after the announcement."})
+	c := fingerprint(Article{Title: "New telescope captures distant galaxy", Content: strings.Repeat("Astronomers released images of a distant spiral galaxy taken by the new telescope. ", 5)})
+
+	if d := HammingDistance(a.TitleHash, b.TitleHash); d > titleMaxDistance {
+		t.Errorf("Expected near-identical titles to have close SimHashes, got distance %d", d)
+	}
+	if s := Similarity(a.ContentHash, b.ContentHash); s < contentThreshold {
+		t.Errorf("Expected near-identical contents to be similar, got %.2f", s)
+	}
+	if s := similarity(&a, &c); s != 0 {
+		t.Errorf("Expected unrelated articles not to match, got %.2f", s)
+	}
+
+	zh1 := fingerprint(Article{Title: "苹果公司发布新款智能手机和平板电脑"})
+	zh2 := fingerprint(Article{Title: "苹果公司发布新款智能手机和平板电脑！"})
+	if zh1.TitleTerms < minTitleTerms || HammingDistance(zh1.TitleHash, zh2.TitleHash) > titleMaxDistance {
+		t.Errorf("Expected Chinese titles to be segmented into words and match, got %d words", zh1.TitleTerms)
+	}
+}
+
+func TestDetector_Process(t *testing.T) {
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	db.SetSetting("duplicate_detection_enabled", "true")
+	db.SetSetting("duplicate_action", ActionHide)
+
+	feedA, _ := db.AddFeed(&models.Feed{Title: "A", URL: "https://a.example.com/feed"})
+	feedB, _ := db.AddFeed(&models.Feed{Title: "B", URL: "https://b.example.com/feed"})
+	feedC, _ := db.AddFeed(&models.Feed{Title: "C", URL: "https://c.example.com/feed"})
+
+	now := time.Now()
+	save := func(feedID int64, title, url string, published time.Time) Article {
+		a := &models.Article{FeedID: feedID, Title: title, URL: url, PublishedAt: published, HasValidPublishedTime: true}
+		if err := db.SaveArticle(a); err != nil {
+			t.Fatalf("SaveArticle failed: %v", err)
+		}
+		id, err := db.GetArticleIDByUniqueID(title, feedID, published, true)
+		if err != nil {
+			t.Fatalf("GetArticleIDByUniqueID failed: %v", err)
+		}
+		return Article{ID: id, FeedID: feedID, Title: title, URL: url, PublishedAt: published}
+	}
+
+	d := NewDetector(db)
+	first := save(feedA, "Spacecraft lands on the far side of the moon", "https://news.example.com/moon-landing?utm_source=a", now.Add(-2*time.Hour))
+	other := save(feedA, "Local elections results announced today", "https://a.example.com/elections", now.Add(-time.Hour))
+	if found, err := d.Process([]Article{first, other}); err != nil || found != 0 {
+		t.Fatalf("Expected no duplicates in a single feed, got %d (%v)", found, err)
+	}
+
+	sameLink := save(feedB, "Historic moon landing", "https://www.news.example.com/moon-landing/", now.Add(-time.Hour))
+	sameTitle := save(feedC, "Spacecraft lands on the far side of the Moon", "https://c.example.com/1", now)
+	old := save(feedC, "Spacecraft lands on the far side of the moon", "https://c.example.com/2", now.Add(-10*24*time.Hour))
+	found, err := d.Process([]Article{sameLink, sameTitle, old})
+	if err != nil || found != 2 {
+		t.Fatalf("Expected 2 duplicates, got %d (%v)", found, err)
+	}
+
+	// Processing the same articles again changes nothing
+	if found, err := d.Process([]Article{sameLink, sameTitle}); err != nil || found != 0 {
+		t.Errorf("Expected processed articles to be skipped, got %d (%v)", found, err)
+	}
+
+	ids, err := db.GetStoryArticleIDs(sameTitle.ID)
+	if err != nil || len(ids) != 3 || ids[0] != first.ID {
+		t.Fatalf("Expected a story of 3 articles with the first one as primary, got %v (%v)", ids, err)
+	}
+
+	stories, siblings, err := db.GetStorySiblings([]int64{first.ID, other.ID, sameLink.ID})
+	if err != nil {
+		t.Fatalf("GetStorySiblings failed: %v", err)
+	}
+	if stories[first.ID] != first.ID || len(siblings[first.ID]) != 2 || stories[other.ID] != 0 {
+		t.Errorf("Unexpected stories %v, siblings %+v", stories, siblings)
+	}
+	if s := siblings[sameLink.ID]; len(s) != 2 || !s[0].IsPrimary || s[0].FeedTitle != "A" {
+		t.Errorf("Expected the primary article first among the siblings, got %+v", s)
+	}
+
+	for _, id := range []int64{first.ID, sameLink.ID, old.ID} {
+		article, _ := db.GetArticleByID(id)
+		if wantHidden := id == sameLink.ID; article.IsHidden != wantHidden {
+			t.Errorf("Article %d: expected hidden=%v", id, wantHidden)
+		}
+	}
+}
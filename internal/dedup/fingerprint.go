@@ -0,0 +1,121 @@
+package dedup
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/bits"
+)
+
+// signatureSize is the number of hash functions of a MinHash signature
+const signatureSize = 64
+
+// minHashSeeds are the seeds of the MinHash hash functions, fixed so that signatures
+// stored in the database stay comparable across runs
+var minHashSeeds = func() [signatureSize]uint64 {
+	var seeds [signatureSize]uint64
+	state := uint64(0x9e3779b97f4a7c15)
+	for i := range seeds {
+		state += 0x9e3779b97f4a7c15
+		seeds[i] = mix(state)
+	}
+	return seeds
+}()
+
+// SimHash returns the 64-bit SimHash of a list of words.
+// Texts that share most of their words have hashes that differ in few bits.
+func SimHash(words []string) uint64 {
+	var weights [64]int
+	for _, word := range words {
+		h := hash64(word)
+		for bit := 0; bit < 64; bit++ {
+			if h&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var result uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			result |= 1 << bit
+		}
+	}
+	return result
+}
+
+// HammingDistance returns the number of bits that differ between two hashes
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Shingles returns the distinct pairs of consecutive words of a text, which capture
+// word order better than single words when comparing longer texts
+func Shingles(words []string) []string {
+	seen := make(map[string]bool)
+	var shingles []string
+	for i := 0; i+1 < len(words); i++ {
+		shingle := words[i] + " " + words[i+1]
+		if !seen[shingle] {
+			seen[shingle] = true
+			shingles = append(shingles, shingle)
+		}
+	}
+	return shingles
+}
+
+// MinHash returns the MinHash signature of a set of shingles, encoded for storage.
+// The share of equal values of two signatures estimates the Jaccard similarity of the sets.
+func MinHash(shingles []string) []byte {
+	var mins [signatureSize]uint32
+	for i := range mins {
+		mins[i] = ^uint32(0)
+	}
+	for _, shingle := range shingles {
+		h := hash64(shingle)
+		for i, seed := range minHashSeeds {
+			if v := uint32(mix(h ^ seed)); v < mins[i] {
+				mins[i] = v
+			}
+		}
+	}
+
+	signature := make([]byte, 4*signatureSize)
+	for i, v := range mins {
+		binary.LittleEndian.PutUint32(signature[4*i:], v)
+	}
+	return signature
+}
+
+// Similarity returns the estimated Jaccard similarity of the sets two MinHash signatures
+// were computed from, or 0 if either signature is missing
+func Similarity(a, b []byte) float64 {
+	if len(a) != 4*signatureSize || len(b) != 4*signatureSize {
+		return 0
+	}
+	equal := 0
+	for i := 0; i < len(a); i += 4 {
+		if binary.LittleEndian.Uint32(a[i:]) == binary.LittleEndian.Uint32(b[i:]) {
+			equal++
+		}
+	}
+	return float64(equal) / signatureSize
+}
+
+// hash64 returns the FNV-1a hash of a string
+func hash64(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mix scrambles the bits of a 64-bit value (SplitMix64 finalizer)
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,130 @@
+package digest
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"MrRSS/internal/embeddings"
+	"MrRSS/internal/models"
+)
+
+const (
+	// vectorThreshold is the cosine similarity above which two articles with embeddings share a topic
+	vectorThreshold = 0.75
+	// termThreshold is the Jaccard similarity of terms above which two articles share a topic
+	termThreshold = 0.2
+	// termExcerptRunes is how much of the excerpt is compared, so that long articles are not
+	// kept apart by the words their headlines do not mention
+	termExcerptRunes = 200
+)
+
+// stopWords are frequent English words that say nothing about the topic of an article
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "from": true,
+	"are": true, "was": true, "were": true, "has": true, "have": true, "had": true, "its": true,
+	"not": true, "but": true, "you": true, "your": true, "our": true, "all": true, "new": true,
+	"how": true, "why": true, "what": true, "who": true, "will": true, "can": true, "about": true,
+	"into": true, "over": true, "after": true, "more": true, "than": true, "out": true, "now": true,
+}
+
+// Item is an article considered for a digest
+type Item struct {
+	Article models.Article
+	Excerpt string    // Plain text from the beginning of the article
+	Vector  []float32 // Normalized embedding, nil if the article has none
+
+	terms map[string]bool
+}
+
+// Cluster groups items by topic. Each item joins the topic of its most similar earlier item,
+// comparing embeddings when both items have one and shared terms otherwise.
+// Topics are returned largest first; items keep their order within a topic.
+func Cluster(items []Item) [][]Item {
+	for i := range items {
+		items[i].terms = terms(items[i].Article.Title + " " + truncate(items[i].Excerpt, termExcerptRunes))
+	}
+
+	var clusters [][]Item
+	for _, item := range items {
+		best, bestScore := -1, float32(0)
+		for c, cluster := range clusters {
+			for _, other := range cluster {
+				if score, ok := similar(item, other); ok && score > bestScore {
+					best, bestScore = c, score
+				}
+			}
+		}
+		if best >= 0 {
+			clusters[best] = append(clusters[best], item)
+		} else {
+			clusters = append(clusters, []Item{item})
+		}
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i]) > len(clusters[j])
+	})
+	return clusters
+}
+
+// similar returns the similarity of two items and whether it is high enough for them to share a topic
+func similar(a, b Item) (float32, bool) {
+	if a.Vector != nil && b.Vector != nil {
+		score := embeddings.Dot(a.Vector, b.Vector)
+		return score, score >= vectorThreshold
+	}
+	score := jaccard(a.terms, b.terms)
+	return score, score >= termThreshold
+}
+
+// terms returns the distinctive words of a text. Chinese and Japanese text has no spaces,
+// so its characters are taken in overlapping pairs.
+func terms(text string) map[string]bool {
+	set := make(map[string]bool)
+	var word []rune
+	var prevCJK rune
+
+	flush := func() {
+		if len(word) >= 3 {
+			w := string(word)
+			if !stopWords[w] {
+				set[w] = true
+			}
+		}
+		word = word[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flush()
+			if prevCJK != 0 {
+				set[string([]rune{prevCJK, r})] = true
+			}
+			prevCJK = r
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+		prevCJK = 0
+	}
+	flush()
+	return set
+}
+
+// jaccard returns the size of the intersection of two sets divided by the size of their union
+func jaccard(a, b map[string]bool) float32 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for term := range a {
+		if b[term] {
+			shared++
+		}
+	}
+	return float32(shared) / float32(len(a)+len(b)-shared)
+}
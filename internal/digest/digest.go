@@ -0,0 +1,638 @@
+// Package digest generates AI briefings from the recent articles of a saved filter,
+// category or tag. Articles are grouped by topic before they are sent to the AI, which
+// writes one section per topic and cites the articles by ID.
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"MrRSS/internal/ai"
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils/textutil"
+)
+
+// Digest sources
+const (
+	SourceFilter   = "filter"
+	SourceCategory = "category"
+	SourceTag      = "tag"
+)
+
+// Digest schedules
+const (
+	ScheduleManual = "manual"
+	ScheduleDaily  = "daily"
+	ScheduleWeekly = "weekly"
+)
+
+// FeedType is the type of the synthetic feed digests are published to
+const FeedType = "digest"
+
+const (
+	feedURL        = "mrrss://digests"
+	maxArticles    = 60  // Newest articles of the window sent to the AI
+	excerptRunes   = 400 // Beginning of each article sent to the AI
+	pageSize       = 200
+	scanLimit      = 5000 // Articles scanned when evaluating a saved filter
+	checkInterval  = 5 * time.Minute
+	requestTimeout = 3 * time.Minute
+	maxTokens      = 4096
+)
+
+var (
+	// ErrNotConfigured is returned when no AI endpoint or model is configured
+	ErrNotConfigured = errors.New("AI is not configured")
+	// ErrLimitReached is returned when a digest would exceed the AI usage limit
+	ErrLimitReached = errors.New("AI usage limit reached")
+	// ErrNoArticles is returned when no article was published in the digest's time window
+	ErrNoArticles = errors.New("no articles in the time window")
+	// ErrRunning is returned when the digest is already being generated
+	ErrRunning = errors.New("digest is already being generated")
+)
+
+// citationPattern matches citations of article IDs such as [12] or [12, 15]
+var citationPattern = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// ArticleFilter returns the articles matching a saved filter's conditions (JSON)
+type ArticleFilter func(articles []models.Article, conditions string) ([]models.Article, error)
+
+// VectorSource returns the normalized embeddings of the articles that have one
+type VectorSource func(articleIDs []int64) map[int64][]float32
+
+// Service generates digests on their schedule and on demand
+type Service struct {
+	db       *database.DB
+	tracker  *ai.UsageTracker
+	profiles *ai.ProfileProvider
+
+	mu        sync.RWMutex
+	newClient func() (*http.Client, error)
+	filter    ArticleFilter
+	vectors   VectorSource
+
+	runningMu sync.Mutex
+	running   map[int64]bool
+}
+
+// NewService creates a digest service
+func NewService(db *database.DB, tracker *ai.UsageTracker, profiles *ai.ProfileProvider) *Service {
+	return &Service{
+		db:        db,
+		tracker:   tracker,
+		profiles:  profiles,
+		newClient: func() (*http.Client, error) { return &http.Client{}, nil },
+		running:   make(map[int64]bool),
+	}
+}
+
+// SetHTTPClient sets the function that creates HTTP clients for AI requests, e.g. to apply the proxy settings
+func (s *Service) SetHTTPClient(newClient func() (*http.Client, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.newClient = newClient
+}
+
+// SetFilter sets the function used to evaluate saved filters
+func (s *Service) SetFilter(filter ArticleFilter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filter = filter
+}
+
+// SetVectors sets the source of article embeddings used to group articles by topic.
+// Without it, articles are grouped by the words they share.
+func (s *Service) SetVectors(vectors VectorSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vectors = vectors
+}
+
+// Run generates scheduled digests when they are due until ctx is cancelled
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		s.runDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDue generates the enabled digests whose scheduled time has passed since their last run
+func (s *Service) runDue(ctx context.Context) {
+	digests, err := s.db.GetDigests()
+	if err != nil {
+		log.Printf("Error loading digests: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, d := range digests {
+		if ctx.Err() != nil {
+			return
+		}
+		if !d.Enabled || !Due(d, now) {
+			continue
+		}
+		run, err := s.Generate(ctx, d.ID)
+		if err != nil {
+			log.Printf("Error generating digest %q: %v", d.Name, err)
+			continue
+		}
+		log.Printf("Generated digest %q from %d articles", d.Name, run.ArticleCount)
+	}
+}
+
+// Due reports whether a scheduled digest has not run since its most recent scheduled time
+func Due(d models.Digest, now time.Time) bool {
+	scheduled, ok := lastScheduled(d, now)
+	if !ok {
+		return false
+	}
+	since := d.CreatedAt
+	if d.LastRunAt != nil {
+		since = *d.LastRunAt
+	}
+	return since.Before(scheduled)
+}
+
+// lastScheduled returns the most recent scheduled time of a digest at or before now, in local time
+func lastScheduled(d models.Digest, now time.Time) (time.Time, bool) {
+	now = now.Local()
+	t := time.Date(now.Year(), now.Month(), now.Day(), d.Hour, 0, 0, 0, time.Local)
+
+	switch d.Schedule {
+	case ScheduleDaily:
+		if t.After(now) {
+			t = t.AddDate(0, 0, -1)
+		}
+	case ScheduleWeekly:
+		t = t.AddDate(0, 0, -((int(t.Weekday()) - d.Weekday + 7) % 7))
+		if t.After(now) {
+			t = t.AddDate(0, 0, -7)
+		}
+	default:
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Generate generates a digest now and stores it in the digest's history. Failed runs are
+// stored too, so that the history shows why a scheduled digest is missing.
+func (s *Service) Generate(ctx context.Context, digestID int64) (*models.DigestRun, error) {
+	d, err := s.db.GetDigest(digestID)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, fmt.Errorf("digest %d not found", digestID)
+	}
+
+	s.runningMu.Lock()
+	if s.running[d.ID] {
+		s.runningMu.Unlock()
+		return nil, ErrRunning
+	}
+	s.running[d.ID] = true
+	s.runningMu.Unlock()
+	defer func() {
+		s.runningMu.Lock()
+		delete(s.running, d.ID)
+		s.runningMu.Unlock()
+	}()
+
+	end := time.Now()
+	run := &models.DigestRun{
+		DigestID:    d.ID,
+		Status:      database.DigestRunSuccess,
+		PeriodStart: end.Add(-time.Duration(d.WindowHours) * time.Hour),
+		PeriodEnd:   end,
+	}
+
+	genErr := s.generate(ctx, d, run)
+	if genErr != nil {
+		run.Status = database.DigestRunFailed
+		run.Error = genErr.Error()
+	}
+
+	run.ID, err = s.db.AddDigestRun(run)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.UpdateDigestLastRun(d.ID, end); err != nil {
+		log.Printf("Error saving last run of digest %d: %v", d.ID, err)
+	}
+	if genErr != nil {
+		return run, genErr
+	}
+
+	if d.PublishToFeed {
+		if articleID, err := s.publish(run); err != nil {
+			log.Printf("Error publishing digest %q to the Digests feed: %v", d.Name, err)
+		} else {
+			run.ArticleID = articleID
+			if err := s.db.UpdateDigestRunArticle(run.ID, articleID); err != nil {
+				log.Printf("Error saving the article of digest run %d: %v", run.ID, err)
+			}
+		}
+	}
+	return run, nil
+}
+
+// generate collects and clusters the articles of the digest's window and asks the AI for the briefing
+func (s *Service) generate(ctx context.Context, d *models.Digest, run *models.DigestRun) error {
+	articles, err := s.collect(d, run.PeriodStart)
+	if err != nil {
+		return err
+	}
+	if len(articles) == 0 {
+		return ErrNoArticles
+	}
+	run.ArticleCount = len(articles)
+
+	items, err := s.items(articles)
+	if err != nil {
+		return err
+	}
+	clusters := Cluster(items)
+
+	client, model, err := s.client()
+	if err != nil {
+		return err
+	}
+	language, _ := s.db.GetSetting("language")
+	systemPrompt := systemPrompt(language)
+	userPrompt := userPrompt(d, run, clusters)
+
+	tokens := ai.EstimateTokens(systemPrompt) + ai.EstimateTokens(userPrompt)
+	if !s.tracker.HasBudgetFor(tokens) {
+		return ErrLimitReached
+	}
+	s.tracker.WaitForRateLimit()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	result, err := client.RequestWithConfig(ai.RequestConfig{
+		Model:        model,
+		SystemPrompt: systemPrompt,
+		UserPrompt:   userPrompt,
+		Temperature:  0.3,
+		MaxTokens:    maxTokens,
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.tracker.AddUsage(tokens + ai.EstimateTokens(result.Content)); err != nil {
+		log.Printf("Warning: failed to track AI usage: %v", err)
+	}
+
+	run.Title, run.Content, run.Citations = parse(result.Content, articles)
+	if run.Title == "" {
+		run.Title = fmt.Sprintf("%s · %s", d.Name, run.PeriodEnd.Local().Format("2006-01-02"))
+	}
+	if run.Content == "" {
+		return errors.New("the AI returned an empty digest")
+	}
+	return nil
+}
+
+// collect returns the newest articles of a digest's source published since the start of its window
+func (s *Service) collect(d *models.Digest, since time.Time) ([]models.Article, error) {
+	// Never digest earlier digests
+	digestFeedID, err := s.db.GetFeedIDByType(FeedType)
+	if err != nil {
+		return nil, err
+	}
+
+	var page func(offset int) ([]models.Article, error)
+	var keep func([]models.Article) ([]models.Article, error)
+
+	switch d.SourceType {
+	case SourceFilter:
+		filter, err := s.db.GetSavedFilter(d.SourceID)
+		if err != nil {
+			return nil, err
+		}
+		if filter == nil {
+			return nil, fmt.Errorf("saved filter %d not found", d.SourceID)
+		}
+		s.mu.RLock()
+		apply := s.filter
+		s.mu.RUnlock()
+		if apply == nil {
+			return nil, errors.New("saved filters are not available")
+		}
+		page = func(offset int) ([]models.Article, error) {
+			return s.db.GetArticles("", 0, "", false, pageSize, offset)
+		}
+		keep = func(articles []models.Article) ([]models.Article, error) {
+			if filter.Conditions == "" {
+				return articles, nil
+			}
+			return apply(articles, filter.Conditions)
+		}
+	case SourceCategory:
+		page = func(offset int) ([]models.Article, error) {
+			return s.db.GetArticles("", 0, d.Category, false, pageSize, offset)
+		}
+	case SourceTag:
+		page = func(offset int) ([]models.Article, error) {
+			return s.db.GetArticlesWithTag(d.SourceID, pageSize, offset)
+		}
+	default:
+		return nil, fmt.Errorf("unknown digest source %q", d.SourceType)
+	}
+
+	var collected []models.Article
+	for offset := 0; offset < scanLimit && len(collected) < maxArticles; offset += pageSize {
+		articles, err := page(offset)
+		if err != nil {
+			return nil, err
+		}
+
+		// Articles are ordered newest first, so the window ends at the first older article
+		inWindow := make([]models.Article, 0, len(articles))
+		done := len(articles) < pageSize
+		for _, a := range articles {
+			if a.PublishedAt.Before(since) {
+				done = true
+				break
+			}
+			if a.FeedID != digestFeedID {
+				inWindow = append(inWindow, a)
+			}
+		}
+
+		if keep != nil {
+			if inWindow, err = keep(inWindow); err != nil {
+				return nil, err
+			}
+		}
+		collected = append(collected, inWindow...)
+		if done {
+			break
+		}
+	}
+
+	if len(collected) > maxArticles {
+		collected = collected[:maxArticles]
+	}
+	return collected, nil
+}
+
+// items attaches the excerpts and embeddings of articles
+func (s *Service) items(articles []models.Article) ([]Item, error) {
+	ids := make([]int64, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	contents, err := s.db.GetArticleContents(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	source := s.vectors
+	s.mu.RUnlock()
+	var vectors map[int64][]float32
+	if source != nil {
+		vectors = source(ids)
+	}
+
+	items := make([]Item, len(articles))
+	for i, a := range articles {
+		excerpt := a.Summary
+		if content := contents[a.ID]; content != "" {
+			excerpt = textutil.StripHTML(content)
+		}
+		items[i] = Item{
+			Article: a,
+			Excerpt: truncate(strings.Join(strings.Fields(excerpt), " "), excerptRunes),
+			Vector:  vectors[a.ID],
+		}
+	}
+	return items, nil
+}
+
+// client creates an AI client for the digest profile and returns the model
+func (s *Service) client() (*ai.Client, string, error) {
+	var cfg ai.ClientConfig
+	if s.profiles != nil {
+		if profile, err := s.profiles.GetProfileForFeature(ai.FeatureDigest); err == nil && profile != nil {
+			cfg = ai.ClientConfig{
+				APIKey:        profile.APIKey,
+				Endpoint:      profile.Endpoint,
+				Model:         profile.Model,
+				CustomHeaders: profile.CustomHeaders,
+			}
+		}
+	}
+
+	// Fallback to global settings if no profile configured
+	if cfg.Endpoint == "" {
+		cfg.APIKey, _ = s.db.GetEncryptedSetting("ai_api_key")
+		cfg.Endpoint, _ = s.db.GetSetting("ai_endpoint")
+		cfg.Model, _ = s.db.GetSetting("ai_model")
+		cfg.CustomHeaders, _ = s.db.GetSetting("ai_custom_headers")
+	}
+	if cfg.Endpoint == "" || cfg.Model == "" {
+		return nil, "", ErrNotConfigured
+	}
+
+	s.mu.RLock()
+	newClient := s.newClient
+	s.mu.RUnlock()
+	httpClient, err := newClient()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	httpClient.Timeout = requestTimeout
+	cfg.Timeout = requestTimeout
+
+	return ai.NewClientWithHTTPClient(cfg, httpClient), cfg.Model, nil
+}
+
+// systemPrompt returns the instructions for writing a digest in the user's language
+func systemPrompt(language string) string {
+	if strings.HasPrefix(language, "zh") {
+		return "你是一名新闻编辑，负责根据 RSS 文章撰写简报。文章已按主题分组。请用中文输出 Markdown：" +
+			"第一行以“# ”开头，给出简报的简短标题；然后为每个主题写一个“## ”标题和一段 2 到 4 句的概述。" +
+			"每个事实后都用方括号注明来源文章的 ID，例如 [12]，只能引用列表中的 ID。" +
+			"报道同一事件的主题请合并，没有新闻价值的文章可以省略。"
+	}
+	return "You are a news editor writing a briefing from RSS articles. The articles are grouped by topic. " +
+		"Write in English and output Markdown: the first line starts with \"# \" followed by a short title for the briefing, " +
+		"then each topic gets a \"## \" heading and a paragraph of 2 to 4 sentences summarizing what happened. " +
+		"Cite every fact with the ID of its article in square brackets, e.g. [12], and only cite IDs from the list. " +
+		"Merge topics about the same story and leave out articles without news value."
+}
+
+// userPrompt lists the clustered articles of a digest
+func userPrompt(d *models.Digest, run *models.DigestRun, clusters [][]Item) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Digest: %s\nArticles published from %s to %s:\n",
+		d.Name, run.PeriodStart.Local().Format("2006-01-02 15:04"), run.PeriodEnd.Local().Format("2006-01-02 15:04"))
+
+	for i, cluster := range clusters {
+		fmt.Fprintf(&b, "\nTopic %d\n", i+1)
+		for _, item := range cluster {
+			fmt.Fprintf(&b, "[%d] %s (%s)\n", item.Article.ID, item.Article.Title, item.Article.FeedTitle)
+			if item.Excerpt != "" {
+				b.WriteString(item.Excerpt)
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// parse extracts the title and body of the AI's briefing. Citations of articles that are not
+// part of the digest are removed, and the cited articles are returned in order of first citation.
+func parse(content string, articles []models.Article) (string, string, []models.DigestCitation) {
+	content = strings.TrimSpace(ai.RemoveThinkingTags(content))
+	if strings.HasPrefix(content, "```") {
+		content = strings.TrimPrefix(content, "```markdown")
+		content = strings.TrimPrefix(content, "```")
+		content = strings.TrimSuffix(strings.TrimSpace(content), "```")
+		content = strings.TrimSpace(content)
+	}
+
+	var title string
+	if first, rest, _ := strings.Cut(content, "\n"); strings.HasPrefix(first, "# ") {
+		title = strings.TrimSpace(strings.TrimPrefix(first, "# "))
+		content = strings.TrimSpace(rest)
+	}
+
+	byID := make(map[int64]models.Article, len(articles))
+	for _, a := range articles {
+		byID[a.ID] = a
+	}
+	citations := []models.DigestCitation{}
+	cited := make(map[int64]bool)
+
+	content = citationPattern.ReplaceAllStringFunc(content, func(match string) string {
+		var kept []string
+		for _, part := range strings.Split(strings.Trim(match, "[]"), ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			a, ok := byID[id]
+			if err != nil || !ok {
+				continue
+			}
+			kept = append(kept, strconv.FormatInt(id, 10))
+			if !cited[id] {
+				cited[id] = true
+				citations = append(citations, models.DigestCitation{ArticleID: id, Title: a.Title, URL: a.URL, FeedTitle: a.FeedTitle})
+			}
+		}
+		if len(kept) == 0 {
+			return ""
+		}
+		return "[" + strings.Join(kept, ", ") + "]"
+	})
+	return title, content, citations
+}
+
+// RenderHTML renders a digest run as HTML. Citations become numbered links to the cited
+// articles, which are listed at the end.
+func RenderHTML(run *models.DigestRun) string {
+	numbers := make(map[int64]int, len(run.Citations))
+	for i, c := range run.Citations {
+		numbers[c.ArticleID] = i + 1
+	}
+
+	body := citationPattern.ReplaceAllStringFunc(run.Content, func(match string) string {
+		var links []string
+		for _, part := range strings.Split(strings.Trim(match, "[]"), ",") {
+			id, _ := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if n, ok := numbers[id]; ok {
+				links = append(links, fmt.Sprintf("[\\[%d\\]](%s)", n, run.Citations[n-1].URL))
+			}
+		}
+		return strings.Join(links, "")
+	})
+
+	if len(run.Citations) > 0 {
+		var b strings.Builder
+		b.WriteString(body)
+		b.WriteString("\n\n---\n\n")
+		for i, c := range run.Citations {
+			fmt.Fprintf(&b, "%d. [%s](%s)", i+1, escapeLinkText(c.Title), c.URL)
+			if c.FeedTitle != "" {
+				fmt.Fprintf(&b, " · %s", c.FeedTitle)
+			}
+			b.WriteString("\n")
+		}
+		body = b.String()
+	}
+	return textutil.ConvertMarkdownToHTML(body)
+}
+
+// publish adds a digest run as an article of the Digests feed and returns the article ID
+func (s *Service) publish(run *models.DigestRun) (int64, error) {
+	feedID, err := s.feedID()
+	if err != nil {
+		return 0, err
+	}
+
+	article := &models.Article{
+		FeedID:                feedID,
+		Title:                 run.Title,
+		URL:                   fmt.Sprintf("%s/%d", feedURL, run.ID),
+		PublishedAt:           run.PeriodEnd,
+		HasValidPublishedTime: true,
+	}
+	if err := s.db.SaveArticle(article); err != nil {
+		return 0, err
+	}
+	id, err := s.db.GetArticleIDByUniqueID(article.Title, feedID, article.PublishedAt, true)
+	if err != nil {
+		return 0, err
+	}
+	return id, s.db.SetArticleContent(id, RenderHTML(run))
+}
+
+// feedID returns the ID of the Digests feed, creating the feed if needed
+func (s *Service) feedID() (int64, error) {
+	id, err := s.db.GetFeedIDByType(FeedType)
+	if err != nil || id != 0 {
+		return id, err
+	}
+	return s.db.AddFeed(&models.Feed{
+		Title:           "Digests",
+		URL:             feedURL,
+		Description:     "AI digests generated by MrRSS",
+		Type:            FeedType,
+		RefreshInterval: -2, // Articles are added by the digest service, there is nothing to fetch
+		ArticleViewMode: "rendered",
+	})
+}
+
+// escapeLinkText escapes the characters that would end a Markdown link text
+func escapeLinkText(s string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]").Replace(s)
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,199 @@
+package digest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"MrRSS/internal/ai"
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func TestCluster(t *testing.T) {
+	item := func(id int64, title string) Item {
+		return Item{Article: models.Article{ID: id, Title: title}}
+	}
+	clusters := Cluster([]Item{
+		item(1, "Apple announces new iPhone with faster chip"),
+		item(2, "Rust 2.0 released with async traits"),
+		item(3, "iPhone chip benchmarks: Apple silicon leads again"),
+		item(4, "苹果发布新款手机"),
+		item(5, "苹果新款手机开售"),
+	})
+
+	if len(clusters) != 3 {
+		t.Fatalf("Expected 3 topics, got %d: %+v", len(clusters), clusters)
+	}
+	ids := func(cluster []Item) []int64 {
+		var ids []int64
+		for _, it := range cluster {
+			ids = append(ids, it.Article.ID)
+		}
+		return ids
+	}
+	if got := fmt.Sprint(ids(clusters[0]), ids(clusters[1]), ids(clusters[2])); got != "[1 3] [4 5] [2]" {
+		t.Errorf("Unexpected topics: %s", got)
+	}
+
+	// Embeddings take precedence over shared words
+	clusters = Cluster([]Item{
+		{Article: models.Article{ID: 1, Title: "Markets rally"}, Vector: []float32{1, 0}},
+		{Article: models.Article{ID: 2, Title: "Stocks climb"}, Vector: []float32{0.96, 0.28}},
+	})
+	if len(clusters) != 1 {
+		t.Errorf("Expected articles with similar embeddings to share a topic, got %d topics", len(clusters))
+	}
+}
+
+func TestDue(t *testing.T) {
+	loc := time.Local
+	created := time.Date(2026, 3, 2, 10, 0, 0, 0, loc) // Monday
+	tests := []struct {
+		name    string
+		digest  models.Digest
+		lastRun *time.Time
+		now     time.Time
+		want    bool
+	}{
+		{"daily before first scheduled time", models.Digest{Schedule: ScheduleDaily, Hour: 8}, nil, time.Date(2026, 3, 3, 7, 0, 0, 0, loc), false},
+		{"daily after first scheduled time", models.Digest{Schedule: ScheduleDaily, Hour: 8}, nil, time.Date(2026, 3, 3, 8, 30, 0, 0, loc), true},
+		{"daily already run", models.Digest{Schedule: ScheduleDaily, Hour: 8}, ptr(time.Date(2026, 3, 3, 8, 5, 0, 0, loc)), time.Date(2026, 3, 3, 20, 0, 0, 0, loc), false},
+		{"weekly on another day", models.Digest{Schedule: ScheduleWeekly, Hour: 9, Weekday: 5}, nil, time.Date(2026, 3, 5, 12, 0, 0, 0, loc), false},
+		{"weekly after scheduled day", models.Digest{Schedule: ScheduleWeekly, Hour: 9, Weekday: 5}, nil, time.Date(2026, 3, 8, 12, 0, 0, 0, loc), true},
+		{"manual", models.Digest{Schedule: ScheduleManual}, nil, time.Date(2026, 4, 1, 12, 0, 0, 0, loc), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.digest
+			d.CreatedAt = created
+			d.LastRunAt = tt.lastRun
+			if got := Due(d, tt.now); got != tt.want {
+				t.Errorf("Due() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr(t time.Time) *time.Time { return &t }
+
+func TestParse(t *testing.T) {
+	articles := []models.Article{
+		{ID: 12, Title: "First", URL: "https://example.com/1"},
+		{ID: 15, Title: "Second", URL: "https://example.com/2"},
+	}
+	title, body, citations := parse("<think>planning</think>\n# Morning Briefing\n\n## Topic\nSomething happened [15, 99]. More [12][7].", articles)
+
+	if title != "Morning Briefing" {
+		t.Errorf("Unexpected title %q", title)
+	}
+	if body != "## Topic\nSomething happened [15]. More [12]." {
+		t.Errorf("Unexpected body %q", body)
+	}
+	if len(citations) != 2 || citations[0].ArticleID != 15 || citations[1].ArticleID != 12 {
+		t.Errorf("Unexpected citations %+v", citations)
+	}
+
+	html := RenderHTML(&models.DigestRun{Content: body, Citations: citations})
+	if !strings.Contains(html, `href="https://example.com/2"`) || !strings.Contains(html, "[1]") {
+		t.Errorf("Expected numbered citation links, got %s", html)
+	}
+}
+
+func TestService_Generate(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Messages) > 0 {
+			prompt = req.Messages[len(req.Messages)-1].Content
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "# Tech Today\n\n## Chips\nA new chip shipped [1]. Old news [3]."}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	db.SetSetting("ai_endpoint", server.URL+"/v1/chat/completions")
+	db.SetSetting("ai_model", "test-model")
+	tracker := ai.NewUsageTracker(db)
+	tracker.SetMinInterval(0)
+	s := NewService(db, tracker, ai.NewProfileProvider(db))
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Tech", URL: "https://example.com/feed", Category: "Tech"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	now := time.Now()
+	for _, a := range []models.Article{
+		{FeedID: feedID, Title: "New chip ships", URL: "https://example.com/chip", PublishedAt: now.Add(-time.Hour)},
+		{FeedID: feedID, Title: "Chip review", URL: "https://example.com/review", PublishedAt: now.Add(-2 * time.Hour)},
+		{FeedID: feedID, Title: "Old chip", URL: "https://example.com/old", PublishedAt: now.Add(-48 * time.Hour)},
+	} {
+		a.HasValidPublishedTime = true
+		if err := db.SaveArticle(&a); err != nil {
+			t.Fatalf("SaveArticle failed: %v", err)
+		}
+	}
+
+	id, err := db.AddDigest(&models.Digest{
+		Name: "Tech", SourceType: SourceCategory, Category: "Tech", WindowHours: 24,
+		Schedule: ScheduleDaily, Hour: 8, PublishToFeed: true, Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("AddDigest failed: %v", err)
+	}
+
+	run, err := s.Generate(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if run.ArticleCount != 2 || strings.Contains(prompt, "Old chip") {
+		t.Errorf("Expected only the 2 articles of the window, got %d; prompt:\n%s", run.ArticleCount, prompt)
+	}
+	if run.Title != "Tech Today" || len(run.Citations) != 1 || run.Citations[0].ArticleID != 1 {
+		t.Errorf("Unexpected run: %+v", run)
+	}
+	if run.ArticleID == 0 {
+		t.Fatal("Expected the digest to be published to the Digests feed")
+	}
+
+	content, found, err := db.GetArticleContent(run.ArticleID)
+	if err != nil || !found || !strings.Contains(content, "https://example.com/chip") {
+		t.Errorf("Expected the published digest to link the cited article, got %q (%v)", content, err)
+	}
+	runs, err := db.GetDigestRuns(id, 10)
+	if err != nil || len(runs) != 1 || runs[0].Status != database.DigestRunSuccess {
+		t.Errorf("Expected one successful run in the history, got %+v (%v)", runs, err)
+	}
+	if d, _ := db.GetDigest(id); d.LastRunAt == nil || Due(*d, now.Add(time.Minute)) {
+		t.Errorf("Expected the digest not to be due right after it ran: %+v", d)
+	}
+
+	// The published digest is not digested again
+	db.Exec(`UPDATE feeds SET category = 'Tech' WHERE type = ?`, FeedType)
+	articles, err := s.collect(&models.Digest{SourceType: SourceCategory, Category: "Tech"}, now.Add(-24*time.Hour))
+	if err != nil || len(articles) != 2 {
+		t.Errorf("Expected the Digests feed to be skipped, got %d articles (%v)", len(articles), err)
+	}
+}
@@ -0,0 +1,359 @@
+// Package embeddings computes article embeddings through the configured AI profile and
+// finds similar articles by cosine similarity over the vectors stored in the database.
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"MrRSS/internal/ai"
+	"MrRSS/internal/database"
+)
+
+const (
+	batchSize      = 16
+	pollInterval   = 5 * time.Minute
+	requestTimeout = 60 * time.Second
+	maxTextRunes   = 2000 // The title and the beginning of an article carry most of its meaning
+)
+
+var (
+	// ErrNotConfigured is returned when no endpoint or embedding model is configured
+	ErrNotConfigured = errors.New("embeddings are not configured")
+	// ErrLimitReached is returned when an embeddings request would exceed the AI usage limit
+	ErrLimitReached = errors.New("AI usage limit reached")
+)
+
+// Match is an article found by similarity
+type Match struct {
+	ArticleID int64
+	Score     float32 // Cosine similarity, 1 for identical meaning
+}
+
+// Status describes how many articles have been embedded with the current model
+type Status struct {
+	Enabled  bool   `json:"enabled"`
+	Model    string `json:"model"`
+	Embedded int    `json:"embedded"`
+	Total    int    `json:"total"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Service embeds articles in the background and answers similarity queries
+type Service struct {
+	db       *database.DB
+	tracker  *ai.UsageTracker
+	profiles *ai.ProfileProvider
+	wake     chan struct{}
+
+	mu        sync.RWMutex
+	newClient func() (*http.Client, error)
+	lastError string
+}
+
+// NewService creates an embeddings service
+func NewService(db *database.DB, tracker *ai.UsageTracker, profiles *ai.ProfileProvider) *Service {
+	return &Service{
+		db:        db,
+		tracker:   tracker,
+		profiles:  profiles,
+		wake:      make(chan struct{}, 1),
+		newClient: func() (*http.Client, error) { return &http.Client{}, nil },
+	}
+}
+
+// SetHTTPClient sets the function that creates HTTP clients for embeddings requests, e.g. to apply the proxy settings
+func (s *Service) SetHTTPClient(newClient func() (*http.Client, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.newClient = newClient
+}
+
+// Enabled reports whether embeddings are turned on in the settings
+func (s *Service) Enabled() bool {
+	enabled, _ := s.db.GetSetting("ai_embedding_enabled")
+	return enabled == "true"
+}
+
+// Wake triggers an immediate pass of the background embedding
+func (s *Service) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run embeds new articles in the background until ctx is cancelled
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if s.Enabled() {
+			n, err := s.EmbedPending(ctx)
+			if n > 0 {
+				log.Printf("Embedded %d articles", n)
+			}
+			s.setLastError(err)
+			if err != nil && !errors.Is(err, ErrNotConfigured) && ctx.Err() == nil {
+				log.Printf("Error embedding articles: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// EmbedPending embeds all articles that have no embedding from the current model and returns how many were embedded
+func (s *Service) EmbedPending(ctx context.Context) (int, error) {
+	client, model, err := s.client()
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for ctx.Err() == nil {
+		sources, err := s.db.GetArticlesWithoutEmbedding(model, batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(sources) == 0 {
+			break
+		}
+
+		texts := make([]string, len(sources))
+		for i, source := range sources {
+			texts[i] = sourceText(source)
+		}
+		vectors, err := s.embed(ctx, client, texts)
+		if err != nil {
+			return total, err
+		}
+		for i, source := range sources {
+			if err := s.db.SaveArticleEmbedding(source.ArticleID, model, Encode(vectors[i])); err != nil {
+				return total, err
+			}
+		}
+		total += len(sources)
+	}
+	return total, ctx.Err()
+}
+
+// Related returns the articles most similar to an article, embedding it first if needed
+func (s *Service) Related(ctx context.Context, articleID int64, limit int) ([]Match, error) {
+	client, model, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.db.GetArticleEmbedding(articleID, model)
+	if err != nil {
+		return nil, err
+	}
+	var vector []float32
+	if stored != nil {
+		vector = Decode(stored)
+	} else {
+		source, err := s.db.GetEmbeddingSource(articleID)
+		if err != nil {
+			return nil, err
+		}
+		if source == nil {
+			return nil, fmt.Errorf("article %d not found", articleID)
+		}
+		vectors, err := s.embed(ctx, client, []string{sourceText(*source)})
+		if err != nil {
+			return nil, err
+		}
+		vector = vectors[0]
+		if err := s.db.SaveArticleEmbedding(articleID, model, Encode(vector)); err != nil {
+			log.Printf("Error saving embedding of article %d: %v", articleID, err)
+		}
+	}
+
+	return s.nearest(model, vector, limit, articleID)
+}
+
+// Search returns the articles closest in meaning to a query
+func (s *Service) Search(ctx context.Context, query string, limit int) ([]Match, error) {
+	client, model, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := s.embed(ctx, client, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	return s.nearest(model, vectors[0], limit, 0)
+}
+
+// Vectors returns the stored embeddings of the given articles from the current model.
+// Articles without an embedding are missing from the result, which is empty if embeddings are disabled.
+func (s *Service) Vectors(articleIDs []int64) map[int64][]float32 {
+	vectors := make(map[int64][]float32)
+	if !s.Enabled() {
+		return vectors
+	}
+	_, model, err := s.client()
+	if err != nil {
+		return vectors
+	}
+	stored, err := s.db.GetArticleEmbeddings(articleIDs, model)
+	if err != nil {
+		log.Printf("Error loading embeddings: %v", err)
+		return vectors
+	}
+	for id, b := range stored {
+		vectors[id] = Decode(b)
+	}
+	return vectors
+}
+
+// Status returns the embedding progress of the current model
+func (s *Service) Status() Status {
+	status := Status{Enabled: s.Enabled()}
+	_, model, err := s.client()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Model = model
+	status.Embedded, status.Total, err = s.db.CountArticleEmbeddings(model)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	s.mu.RLock()
+	status.Error = s.lastError
+	s.mu.RUnlock()
+	return status
+}
+
+// nearest scans the stored embeddings of a model and returns the best matches, excluding one article
+func (s *Service) nearest(model string, vector []float32, limit int, excludeID int64) ([]Match, error) {
+	var matches []Match
+	err := s.db.ForEachArticleEmbedding(model, func(articleID int64, stored []byte) {
+		if articleID == excludeID {
+			return
+		}
+		matches = append(matches, Match{ArticleID: articleID, Score: Dot(vector, Decode(stored))})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// embed requests normalized embeddings within the AI usage limit and rate limit
+func (s *Service) embed(ctx context.Context, client *ai.Client, texts []string) ([][]float32, error) {
+	var tokens int64
+	for _, text := range texts {
+		tokens += ai.EstimateTokens(text)
+	}
+	if !s.tracker.HasBudgetFor(tokens) {
+		return nil, ErrLimitReached
+	}
+
+	s.tracker.WaitForRateLimit()
+	vectors, err := client.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tracker.AddUsage(tokens); err != nil {
+		log.Printf("Warning: failed to track AI usage: %v", err)
+	}
+
+	for _, v := range vectors {
+		Normalize(v)
+	}
+	return vectors, nil
+}
+
+// client creates an AI client for the embedding profile and returns the embedding model.
+// Chat models cannot compute embeddings, so the model setting overrides the profile's model.
+func (s *Service) client() (*ai.Client, string, error) {
+	var cfg ai.ClientConfig
+	if s.profiles != nil {
+		if profile, err := s.profiles.GetProfileForFeature(ai.FeatureEmbedding); err == nil && profile != nil {
+			cfg = ai.ClientConfig{
+				APIKey:        profile.APIKey,
+				Endpoint:      profile.Endpoint,
+				Model:         profile.Model,
+				CustomHeaders: profile.CustomHeaders,
+			}
+		}
+	}
+
+	// Fallback to global settings if no profile configured
+	if cfg.Endpoint == "" {
+		cfg.APIKey, _ = s.db.GetEncryptedSetting("ai_api_key")
+		cfg.Endpoint, _ = s.db.GetSetting("ai_endpoint")
+		cfg.Model, _ = s.db.GetSetting("ai_model")
+	}
+	if model, _ := s.db.GetSetting("ai_embedding_model"); strings.TrimSpace(model) != "" {
+		cfg.Model = strings.TrimSpace(model)
+	}
+	if cfg.Endpoint == "" || cfg.Model == "" {
+		return nil, "", ErrNotConfigured
+	}
+
+	s.mu.RLock()
+	newClient := s.newClient
+	s.mu.RUnlock()
+	httpClient, err := newClient()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	httpClient.Timeout = requestTimeout
+	cfg.Timeout = requestTimeout
+
+	return ai.NewClientWithHTTPClient(cfg, httpClient), cfg.Model, nil
+}
+
+func (s *Service) setLastError(err error) {
+	msg := ""
+	if err != nil && !errors.Is(err, context.Canceled) {
+		msg = err.Error()
+	}
+	s.mu.Lock()
+	s.lastError = msg
+	s.mu.Unlock()
+}
+
+// sourceText builds the text of an article that is embedded
+func sourceText(source database.EmbeddingSource) string {
+	parts := []string{source.Title}
+	if source.Summary != "" {
+		parts = append(parts, source.Summary)
+	}
+	if source.Content != "" {
+		parts = append(parts, source.Content)
+	}
+	text := strings.Join(parts, "\n\n")
+
+	runes := []rune(text)
+	if len(runes) > maxTextRunes {
+		text = string(runes[:maxTextRunes])
+	}
+	return text
+}
@@ -0,0 +1,146 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"MrRSS/internal/ai"
+	"MrRSS/internal/database"
+)
+
+// topicServer embeds texts as one-hot vectors of the topics they mention
+func topicServer(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		var req struct {
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		var data []item
+		for i, text := range req.Input {
+			v := []float32{0, 0, 0.1}
+			if strings.Contains(strings.ToLower(text), "cat") {
+				v[0] = 1
+			}
+			if strings.Contains(strings.ToLower(text), "dog") {
+				v[1] = 1
+			}
+			data = append(data, item{Index: i, Embedding: v})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func setupService(t *testing.T, endpoint string) (*Service, *database.DB) {
+	t.Helper()
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	db.SetSetting("ai_endpoint", endpoint)
+	db.SetSetting("ai_embedding_model", "test-embedding")
+
+	tracker := ai.NewUsageTracker(db)
+	tracker.SetMinInterval(0)
+	return NewService(db, tracker, ai.NewProfileProvider(db)), db
+}
+
+func insertArticle(t *testing.T, db *database.DB, feedID int64, title string) int64 {
+	t.Helper()
+	res, err := db.Exec(`INSERT INTO articles (feed_id, title, url, published_at) VALUES (?, ?, ?, datetime('now'))`,
+		feedID, title, "https://example.com/"+title)
+	if err != nil {
+		t.Fatalf("insert article failed: %v", err)
+	}
+	id, _ := res.LastInsertId()
+	return id
+}
+
+func TestService_RelatedAndSearch(t *testing.T) {
+	requests := 0
+	server := topicServer(t, &requests)
+	s, db := setupService(t, server.URL+"/v1/chat/completions")
+
+	res, err := db.Exec(`INSERT INTO feeds (title, url) VALUES ('Feed', 'https://example.com/feed')`)
+	if err != nil {
+		t.Fatalf("insert feed failed: %v", err)
+	}
+	feedID, _ := res.LastInsertId()
+	cat1 := insertArticle(t, db, feedID, "My cat sleeps")
+	cat2 := insertArticle(t, db, feedID, "Cat food review")
+	dog := insertArticle(t, db, feedID, "Dog training")
+
+	n, err := s.EmbedPending(context.Background())
+	if err != nil {
+		t.Fatalf("EmbedPending failed: %v", err)
+	}
+	if n != 3 || requests != 1 {
+		t.Errorf("Expected 3 articles in one request, got %d in %d requests", n, requests)
+	}
+	if n, _ := s.EmbedPending(context.Background()); n != 0 {
+		t.Errorf("Expected embedded articles to be skipped, got %d", n)
+	}
+
+	related, err := s.Related(context.Background(), cat1, 10)
+	if err != nil {
+		t.Fatalf("Related failed: %v", err)
+	}
+	if len(related) != 2 || related[0].ArticleID != cat2 || related[1].ArticleID != dog {
+		t.Errorf("Unexpected related articles: %+v", related)
+	}
+
+	matches, err := s.Search(context.Background(), "dogs", 1)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(matches) != 1 || matches[0].ArticleID != dog {
+		t.Errorf("Unexpected search results: %+v", matches)
+	}
+
+	status := s.Status()
+	if status.Model != "test-embedding" || status.Embedded != 3 || status.Total != 3 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	// Deleting an article removes its embedding
+	db.Exec(`DELETE FROM articles WHERE id = ?`, cat2)
+	if status := s.Status(); status.Embedded != 2 {
+		t.Errorf("Expected the embedding to be deleted with its article, got %+v", status)
+	}
+}
+
+func TestService_UsageLimit(t *testing.T) {
+	requests := 0
+	server := topicServer(t, &requests)
+	s, db := setupService(t, server.URL+"/v1/chat/completions")
+	db.SetSetting("ai_usage_limit", "5")
+
+	if _, err := s.Search(context.Background(), "a long query about cats and dogs", 10); !errors.Is(err, ErrLimitReached) {
+		t.Errorf("Expected ErrLimitReached, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no request over the usage limit, got %d", requests)
+	}
+
+	db.SetSetting("ai_embedding_model", "")
+	db.SetSetting("ai_model", "")
+	if _, err := s.Search(context.Background(), "cats", 10); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("Expected ErrNotConfigured, got %v", err)
+	}
+}
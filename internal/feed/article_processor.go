@@ -37,6 +37,7 @@ func ExtractContent(item *gofeed.Item) string {
 type ArticleWithContent struct {
 	Article *models.Article
 	Content string
+	Episode *models.PodcastEpisode // Podcast metadata, nil if the item has no audio enclosure
 }
 
 // processArticles processes RSS feed items and converts them to Article models
@@ -107,6 +108,7 @@ func (f *Fetcher) processArticles(feed models.Feed, items []*gofeed.Item) []*Art
 		articlesWithContent = append(articlesWithContent, &ArticleWithContent{
 			Article: article,
 			Content: content,
+			Episode: extractPodcastEpisode(item),
 		})
 	}
 
@@ -244,7 +246,8 @@ func extractAudioURL(item *gofeed.Item) string {
 	return ""
 }
 
-// extractVideoURL extracts the video URL from a feed item (for YouTube videos)
+// extractVideoURL extracts the video URL from a feed item: a YouTube embed URL
+// for YouTube videos, or the first video enclosure
 func extractVideoURL(item *gofeed.Item) string {
 	// Check if this is a YouTube link (watch, youtu.be, or shorts)
 	if item.Link != "" && (strings.Contains(item.Link, "youtube.com/watch") ||
@@ -270,6 +273,13 @@ func extractVideoURL(item *gofeed.Item) string {
 		}
 	}
 
+	// Try enclosures for video files (e.g. Mastodon media attachments)
+	for _, enc := range item.Enclosures {
+		if strings.HasPrefix(enc.Type, "video/") {
+			return enc.URL
+		}
+	}
+
 	return ""
 }
 
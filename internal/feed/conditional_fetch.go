@@ -0,0 +1,62 @@
+package feed
+
+import (
+	"log"
+	"net/http"
+
+	"MrRSS/internal/models"
+)
+
+// httpValidators holds the HTTP cache validators used for conditional feed requests.
+// It is passed by pointer through the fetch path: the stored values are sent as
+// If-None-Match/If-Modified-Since, and replaced with the response values on a 200.
+type httpValidators struct {
+	ETag         string
+	LastModified string
+}
+
+// applyToRequest adds the conditional request headers for the stored validators
+func (v *httpValidators) applyToRequest(req *http.Request) {
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// updateFromResponse replaces the stored validators with those returned by the server
+func (v *httpValidators) updateFromResponse(resp *http.Response) {
+	v.ETag = resp.Header.Get("ETag")
+	v.LastModified = resp.Header.Get("Last-Modified")
+}
+
+// supportsConditionalFetch reports whether a feed is fetched over plain HTTP and can
+// therefore use ETag/Last-Modified validators. Script feeds and feeds of another
+// source type, such as XPath, email or Mastodon, cannot.
+func supportsConditionalFetch(feed *models.Feed) bool {
+	return feed.ScriptPath == "" && feed.Type == ""
+}
+
+// loadHTTPValidators returns the stored validators for a feed, or nil if the feed
+// does not support conditional fetching.
+func (f *Fetcher) loadHTTPValidators(feed *models.Feed) *httpValidators {
+	if feed.ID == 0 || !supportsConditionalFetch(feed) {
+		return nil
+	}
+	etag, lastModified, err := f.db.GetFeedHTTPValidators(feed.ID)
+	if err != nil {
+		return &httpValidators{}
+	}
+	return &httpValidators{ETag: etag, LastModified: lastModified}
+}
+
+// saveHTTPValidators persists the validators from a successful fetch
+func (f *Fetcher) saveHTTPValidators(feedID int64, validators *httpValidators) {
+	if validators == nil {
+		return
+	}
+	if err := f.db.UpdateFeedHTTPValidators(feedID, validators.ETag, validators.LastModified); err != nil {
+		log.Printf("Error saving HTTP validators for feed %d: %v", feedID, err)
+	}
+}
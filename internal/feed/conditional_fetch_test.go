@@ -0,0 +1,99 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"MrRSS/internal/models"
+)
+
+func TestFetchFeedWithContext_ConditionalRequest(t *testing.T) {
+	db := setupDBForFeedTests(t)
+
+	rss := `<?xml version="1.0"?><rss><channel><title>Cond</title>` +
+		`<item><title>first</title><link>/1</link><guid>1</guid><pubDate>Mon, 02 Jan 2006 15:04:05 MST</pubDate></item>` +
+		`</channel></rss>`
+
+	var fullResponses, notModified int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&fullResponses, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(rss))
+	}))
+	defer srv.Close()
+
+	f := NewFetcher(db)
+	id, err := db.AddFeed(&models.Feed{Title: "cond", URL: srv.URL})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	feed, err := db.GetFeedByID(id)
+	if err != nil {
+		t.Fatalf("GetFeedByID error: %v", err)
+	}
+
+	if err := f.fetchFeedWithContext(context.Background(), *feed); err != nil {
+		t.Fatalf("first fetch error: %v", err)
+	}
+
+	etag, lastModified, err := db.GetFeedHTTPValidators(id)
+	if err != nil {
+		t.Fatalf("GetFeedHTTPValidators error: %v", err)
+	}
+	if etag != `"v1"` || lastModified != "Mon, 02 Jan 2006 15:04:05 GMT" {
+		t.Fatalf("validators not stored, got etag=%q last_modified=%q", etag, lastModified)
+	}
+
+	if err := f.fetchFeedWithContext(context.Background(), *feed); err != nil {
+		t.Fatalf("expected 304 to be treated as success, got: %v", err)
+	}
+	if atomic.LoadInt32(&fullResponses) != 1 || atomic.LoadInt32(&notModified) != 1 {
+		t.Fatalf("expected 1 full and 1 not-modified response, got %d and %d", fullResponses, notModified)
+	}
+
+	articles, err := db.GetArticles("all", id, "", false, 10, 0)
+	if err != nil {
+		t.Fatalf("GetArticles error: %v", err)
+	}
+	if len(articles) != 1 {
+		t.Fatalf("expected 1 article after not-modified refresh, got %d", len(articles))
+	}
+}
+
+func TestUpdateFeedClearsHTTPValidatorsOnURLChange(t *testing.T) {
+	db := setupDBForFeedTests(t)
+
+	id, err := db.AddFeed(&models.Feed{Title: "v", URL: "https://example.com/a.xml"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	if err := db.UpdateFeedHTTPValidators(id, `"x"`, "yesterday"); err != nil {
+		t.Fatalf("UpdateFeedHTTPValidators error: %v", err)
+	}
+
+	// Same URL keeps the validators
+	if err := db.UpdateFeed(id, "v", "https://example.com/a.xml", "", "", false, "", false, 0, false, "", "", "", "", "", "", "", "", "", "", "", "global", "global", "", "", "", "", "INBOX", 993); err != nil {
+		t.Fatalf("UpdateFeed error: %v", err)
+	}
+	if etag, _, _ := db.GetFeedHTTPValidators(id); etag != `"x"` {
+		t.Fatalf("expected validators to be kept, got etag=%q", etag)
+	}
+
+	// A new URL drops them
+	if err := db.UpdateFeed(id, "v", "https://example.com/b.xml", "", "", false, "", false, 0, false, "", "", "", "", "", "", "", "", "", "", "", "global", "global", "", "", "", "", "INBOX", 993); err != nil {
+		t.Fatalf("UpdateFeed error: %v", err)
+	}
+	if etag, lastModified, _ := db.GetFeedHTTPValidators(id); etag != "" || lastModified != "" {
+		t.Fatalf("expected validators to be cleared, got etag=%q last_modified=%q", etag, lastModified)
+	}
+}
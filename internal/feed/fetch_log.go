@@ -0,0 +1,105 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"MrRSS/internal/feed/health"
+	"MrRSS/internal/models"
+)
+
+// fetchLogMaxAgeDays is how long fetch attempts are kept in the fetch log
+const fetchLogMaxAgeDays = 30
+
+// fetchAttempt runs one attempt of a refresh and writes it to the fetch log.
+// attempt is 1 for the first attempt and 2 for the retry.
+func (tm *TaskManager) fetchAttempt(ctx context.Context, feed models.Feed, attempt int) (*fetchReport, error) {
+	start := time.Now()
+	reportCtx, report := withFetchReport(ctx, feed)
+	err := tm.fetcher.fetchFeedWithContext(reportCtx, feed)
+	if !canceled(ctx) {
+		entry := report.logEntry()
+		entry.FetchedAt = start
+		entry.Attempt = attempt
+		entry.DurationMs = time.Since(start).Milliseconds()
+		if err != nil {
+			entry.ErrorClass = health.ClassifyError(err, entry.HTTPStatus)
+			entry.Error = err.Error()
+		}
+		if logErr := tm.fetcher.db.AddFeedFetchLog(&entry); logErr != nil {
+			log.Printf("Error writing fetch log of feed %s: %v", feed.Title, logErr)
+		}
+	}
+	return report, err
+}
+
+// recordRefresh updates the health state of a feed with the result of its last attempt
+func (tm *TaskManager) recordRefresh(ctx context.Context, feed models.Feed, report *fetchReport, err error) {
+	if canceled(ctx) {
+		return
+	}
+	entry := report.logEntry()
+	redirectURL := report.pendingRedirect()
+	if recordErr := tm.fetcher.db.RecordFeedRefresh(entry.FeedID, time.Now(), err == nil, entry.NewArticles, redirectURL); recordErr != nil {
+		log.Printf("Error recording refresh of feed %s: %v", feed.Title, recordErr)
+	}
+	if err == nil && redirectURL != "" {
+		log.Printf("Feed %s permanently redirects from %s to %s", feed.Title, feed.URL, redirectURL)
+	}
+}
+
+// canceled reports whether a refresh was canceled, e.g. on shutdown, rather than
+// timed out. Canceled attempts say nothing about the feed and are not recorded.
+func canceled(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.Canceled)
+}
+
+// HealthPolicy returns the feed health policy of the current settings
+func (f *Fetcher) HealthPolicy() health.Policy {
+	return health.LoadPolicy(f.db)
+}
+
+// dueFeeds returns the feeds whose scheduled refresh is not held back by the health
+// policy, i.e. that do not back off and are not paused
+func (f *Fetcher) dueFeeds(feeds []models.Feed) []models.Feed {
+	states, err := f.db.GetAllFeedHealth()
+	if err != nil {
+		log.Printf("Error loading feed health: %v", err)
+		return feeds
+	}
+
+	policy := f.HealthPolicy()
+	now := time.Now()
+	due := make([]models.Feed, 0, len(feeds))
+	for _, feed := range feeds {
+		if policy.Due(states[feed.ID], now) {
+			due = append(due, feed)
+		}
+	}
+	if skipped := len(feeds) - len(due); skipped > 0 {
+		log.Printf("Skipped %d failing feeds that back off or are paused", skipped)
+	}
+	return due
+}
+
+// refreshDue reports whether the scheduled refresh of a feed is not held back by the
+// health policy
+func (f *Fetcher) refreshDue(feed models.Feed) bool {
+	h, err := f.db.GetFeedHealth(feed.ID)
+	if err != nil {
+		log.Printf("Error loading health of feed %s: %v", feed.Title, err)
+		return true
+	}
+	return f.HealthPolicy().Due(h, time.Now())
+}
+
+// cleanupFetchLog removes old fetch attempts from the fetch log
+func (f *Fetcher) cleanupFetchLog() {
+	if deleted, err := f.db.CleanupFeedFetchLog(fetchLogMaxAgeDays); err != nil {
+		log.Printf("Error cleaning up the fetch log: %v", err)
+	} else if deleted > 0 {
+		log.Printf("Removed %d old fetch log entries", deleted)
+	}
+}
@@ -0,0 +1,180 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"MrRSS/internal/feed/health"
+	"MrRSS/internal/models"
+)
+
+func TestFetchAttempt_LogsRedirectAndSize(t *testing.T) {
+	db := setupDBForFeedTests(t)
+
+	rss := `<?xml version="1.0"?><rss><channel><title>Moved</title>` +
+		`<item><title>first</title><link>/1</link><guid>1</guid></item>` +
+		`<item><title>second</title><link>/2</link><guid>2</guid></item>` +
+		`</channel></rss>`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/new.xml")
+		w.WriteHeader(http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/new.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(rss))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// The redirect is only reported while the feed is not moved to the new URL
+	if err := db.SetSetting("feed_url_auto_migrate", "false"); err != nil {
+		t.Fatalf("SetSetting error: %v", err)
+	}
+
+	f := NewFetcher(db)
+	id, err := db.AddFeed(&models.Feed{Title: "moved", URL: srv.URL + "/old.xml"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	feed, err := db.GetFeedByID(id)
+	if err != nil {
+		t.Fatalf("GetFeedByID error: %v", err)
+	}
+
+	tm := f.GetTaskManager()
+	for attempt := 1; attempt <= 2; attempt++ {
+		report, err := tm.fetchAttempt(context.Background(), *feed, attempt)
+		if err != nil {
+			t.Fatalf("fetch %d error: %v", attempt, err)
+		}
+		tm.recordRefresh(context.Background(), *feed, report, err)
+	}
+
+	entries, err := db.GetFeedFetchLog(id, 10)
+	if err != nil {
+		t.Fatalf("GetFeedFetchLog error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(entries))
+	}
+	// Newest first: the second fetch finds no new articles
+	second, first := entries[0], entries[1]
+	if first.HTTPStatus != http.StatusOK || first.Bytes != int64(len(rss)) || first.ItemCount != 2 || first.NewArticles != 2 {
+		t.Errorf("unexpected first entry: %+v", first)
+	}
+	if first.RedirectURL != srv.URL+"/new.xml" || first.ErrorClass != "" {
+		t.Errorf("expected redirect to /new.xml without error, got %+v", first)
+	}
+	if second.Attempt != 2 || second.NewArticles != 0 {
+		t.Errorf("unexpected second entry: %+v", second)
+	}
+
+	h, err := db.GetFeedHealth(id)
+	if err != nil {
+		t.Fatalf("GetFeedHealth error: %v", err)
+	}
+	if h.ConsecutiveFailures != 0 || h.LastSuccessAt == nil || h.LastNewArticlesAt == nil {
+		t.Errorf("unexpected health after successful refreshes: %+v", h)
+	}
+	if h.RedirectURL != srv.URL+"/new.xml" {
+		t.Errorf("expected redirect URL to be recorded, got %q", h.RedirectURL)
+	}
+}
+
+func TestFetchAttempt_FailuresBackOff(t *testing.T) {
+	db := setupDBForFeedTests(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	if err := db.SetSetting(health.SettingBackoffFailures, "2"); err != nil {
+		t.Fatalf("SetSetting error: %v", err)
+	}
+
+	f := NewFetcher(db)
+	id, err := db.AddFeed(&models.Feed{Title: "down", URL: srv.URL})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	feed, err := db.GetFeedByID(id)
+	if err != nil {
+		t.Fatalf("GetFeedByID error: %v", err)
+	}
+
+	tm := f.GetTaskManager()
+	for i := 0; i < 2; i++ {
+		if !f.refreshDue(*feed) {
+			t.Fatalf("expected refresh %d to be due", i+1)
+		}
+		report, err := tm.fetchAttempt(context.Background(), *feed, 1)
+		if err == nil {
+			t.Fatal("expected fetch to fail")
+		}
+		tm.recordRefresh(context.Background(), *feed, report, err)
+	}
+	if f.refreshDue(*feed) {
+		t.Error("expected the feed to back off after 2 failed refreshes")
+	}
+	if due := f.dueFeeds([]models.Feed{*feed}); len(due) != 0 {
+		t.Errorf("expected no due feeds, got %d", len(due))
+	}
+
+	entries, err := db.GetFeedFetchLog(id, 10)
+	if err != nil {
+		t.Fatalf("GetFeedFetchLog error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(entries))
+	}
+	if entries[0].HTTPStatus != http.StatusServiceUnavailable || entries[0].ErrorClass != health.ErrorHTTP {
+		t.Errorf("unexpected entry: %+v", entries[0])
+	}
+
+	h, err := db.GetFeedHealth(id)
+	if err != nil {
+		t.Fatalf("GetFeedHealth error: %v", err)
+	}
+	if h.ConsecutiveFailures != 2 || h.FailingSince == nil || h.LastSuccessAt != nil {
+		t.Errorf("unexpected health after failed refreshes: %+v", h)
+	}
+}
+
+func TestFetchAttempt_CanceledIsNotLogged(t *testing.T) {
+	db := setupDBForFeedTests(t)
+	f := NewFetcher(db)
+	id, err := db.AddFeed(&models.Feed{Title: "canceled", URL: "http://127.0.0.1:1/feed.xml"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	feed, err := db.GetFeedByID(id)
+	if err != nil {
+		t.Fatalf("GetFeedByID error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tm := f.GetTaskManager()
+	report, err := tm.fetchAttempt(ctx, *feed, 1)
+	tm.recordRefresh(ctx, *feed, report, err)
+
+	entries, err := db.GetFeedFetchLog(id, 10)
+	if err != nil {
+		t.Fatalf("GetFeedFetchLog error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected canceled attempt not to be logged, got %d entries", len(entries))
+	}
+	h, err := db.GetFeedHealth(id)
+	if err != nil {
+		t.Fatalf("GetFeedHealth error: %v", err)
+	}
+	if h.LastAttemptAt != nil {
+		t.Errorf("expected canceled refresh not to be recorded, got %+v", h)
+	}
+}
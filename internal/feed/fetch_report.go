@@ -0,0 +1,135 @@
+package feed
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"MrRSS/internal/models"
+)
+
+// fetchReport collects the HTTP status, response size and permanent redirect of a fetch
+// attempt for the fetch log. It travels in the context of the attempt and is filled by
+// reportingTransport, so that every source reports the same way. The item counts and
+// URL migration are set by fetchFeedWithContext.
+type fetchReport struct {
+	mu          sync.Mutex
+	feedID      int64  // Feed the attempt is recorded for, the other feed after a merge
+	currentURL  string // URL of the feed, or the target of its permanent redirects so far
+	statusCode  int
+	bytes       int64
+	redirectURL string
+	urlMigrated bool // The feed was moved to its new URL
+	itemCount   int
+	newArticles int
+}
+
+type fetchReportKey struct{}
+
+// withFetchReport returns a context that collects a fetch report for a feed
+func withFetchReport(ctx context.Context, feed models.Feed) (context.Context, *fetchReport) {
+	report := &fetchReport{feedID: feed.ID, currentURL: feed.URL}
+	return context.WithValue(ctx, fetchReportKey{}, report), report
+}
+
+// fetchReportFrom returns the fetch report collected in a context, nil if there is none
+func fetchReportFrom(ctx context.Context) *fetchReport {
+	report, _ := ctx.Value(fetchReportKey{}).(*fetchReport)
+	return report
+}
+
+// recordResponse records the status of a response, and follows a permanent redirect
+// (301 or 308) of the feed URL
+func (r *fetchReport) recordResponse(req *http.Request, resp *http.Response) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.statusCode = resp.StatusCode
+	if resp.StatusCode != http.StatusMovedPermanently && resp.StatusCode != http.StatusPermanentRedirect {
+		return
+	}
+	if req.URL.String() != r.currentURL {
+		return
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return
+	}
+	r.currentURL = location.String()
+	r.redirectURL = r.currentURL
+}
+
+func (r *fetchReport) addBytes(n int) {
+	r.mu.Lock()
+	r.bytes += int64(n)
+	r.mu.Unlock()
+}
+
+// setArticles records the number of items of the fetched feed and how many were new
+func (r *fetchReport) setArticles(itemCount, newArticles int) {
+	r.mu.Lock()
+	r.itemCount, r.newArticles = itemCount, newArticles
+	r.mu.Unlock()
+}
+
+// setMigrated records that the feed was moved to its new URL, and merged into the feed
+// intoID if that differs from the feed
+func (r *fetchReport) setMigrated(intoID int64) {
+	r.mu.Lock()
+	r.feedID, r.urlMigrated = intoID, true
+	r.mu.Unlock()
+}
+
+// pendingRedirect returns the target of a permanent redirect the feed was not moved to
+func (r *fetchReport) pendingRedirect() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.urlMigrated {
+		return ""
+	}
+	return r.redirectURL
+}
+
+// logEntry returns the fetch log entry of the report
+func (r *fetchReport) logEntry() models.FeedFetchLog {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return models.FeedFetchLog{
+		FeedID:      r.feedID,
+		HTTPStatus:  r.statusCode,
+		Bytes:       r.bytes,
+		ItemCount:   r.itemCount,
+		NewArticles: r.newArticles,
+		RedirectURL: r.redirectURL,
+	}
+}
+
+// reportingTransport records the responses of requests made with a fetch report context
+type reportingTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t reportingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	report := fetchReportFrom(req.Context())
+	if err != nil || report == nil {
+		return resp, err
+	}
+	report.recordResponse(req, resp)
+	resp.Body = &countingBody{ReadCloser: resp.Body, report: report}
+	return resp, nil
+}
+
+// countingBody adds the bytes read from a response body to a fetch report
+type countingBody struct {
+	io.ReadCloser
+	report *fetchReport
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.report.addBytes(n)
+	return n, err
+}
@@ -2,6 +2,7 @@ package feed
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,6 +11,9 @@ import (
 	"time"
 
 	"MrRSS/internal/database"
+	"MrRSS/internal/dedup"
+	"MrRSS/internal/feed/httpoptions"
+	"MrRSS/internal/feed/source"
 	"MrRSS/internal/models"
 	"MrRSS/internal/rsshub"
 	"MrRSS/internal/rules"
@@ -32,11 +36,17 @@ type Fetcher struct {
 	highPriorityFp    FeedParser // High priority parser for content fetching
 	scriptExecutor    *ScriptExecutor
 	emailFetcher      *EmailFetcher
+	youtube           *source.YouTubeSource
+	mastodon          *source.MastodonSource
+	jsonSource        *source.JSONSource
 	progress          Progress
 	mu                sync.Mutex
 	refreshCalculator *IntelligentRefreshCalculator
 	taskManager       *TaskManager
 	cleanupManager    *CleanupManager
+	ruleServices      rules.Services
+	dedup             *dedup.Detector
+	rejectedURLs      sync.Map // Feed ID -> announced URL that failed verification, see migrateFeedURL
 }
 
 func NewFetcher(db *database.DB) *Fetcher {
@@ -57,8 +67,9 @@ func NewFetcher(db *database.DB) *Fetcher {
 	if err != nil {
 		// Fallback to default client if proxy setup fails
 		log.Printf("Warning: Failed to create HTTP client with User-Agent: %v, using default client", err)
-		httpClient = &http.Client{Timeout: 30 * time.Second}
+		httpClient = &http.Client{Timeout: 30 * time.Second, Transport: http.DefaultTransport}
 	}
+	httpClient.Transport = reportingTransport{base: httpClient.Transport}
 
 	// Create parser with custom HTTP client to support localhost and other endpoints
 	parser := gofeed.NewParser()
@@ -74,7 +85,11 @@ func NewFetcher(db *database.DB) *Fetcher {
 		highPriorityFp:    highPriorityParser,
 		scriptExecutor:    executor,
 		emailFetcher:      NewEmailFetcher(db),
+		youtube:           source.NewYouTubeSource(),
+		mastodon:          source.NewMastodonSource(),
+		jsonSource:        source.NewJSONSource(),
 		refreshCalculator: NewIntelligentRefreshCalculator(db),
+		dedup:             dedup.NewDetector(db),
 	}
 
 	// Initialize task manager with default capacity (increased from 5 to 10)
@@ -98,6 +113,11 @@ func (f *Fetcher) GetStaggeredDelay(feedID int64, totalFeeds int) time.Duration
 	return GetStaggeredDelay(feedID, totalFeeds)
 }
 
+// SetRuleServices sets the services used by rule actions that call AI, translation or export integrations
+func (f *Fetcher) SetRuleServices(services rules.Services) {
+	f.ruleServices = services
+}
+
 // GetTaskManager returns the task manager
 func (f *Fetcher) GetTaskManager() *TaskManager {
 	return f.taskManager
@@ -186,11 +206,50 @@ func (f *Fetcher) getHTTPClient(feed models.Feed) (*http.Client, error) {
 
 	// Create HTTP client with browser-like headers to bypass Cloudflare and anti-bot protections
 	// This is critical for RSSHub feeds and other services with anti-bot protection
-	return httputil.CreateHTTPClientWithUserAgent(
+	client, err := httputil.CreateHTTPClientWithUserAgent(
 		proxyURL,
 		30*time.Second,
 		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the feed's headers, cookies and authentication below the user agent
+	// transport, so that a custom user agent replaces the browser one
+	if options := f.feedHTTPOptions(feed); options != nil {
+		if transport, ok := client.Transport.(*httputil.UserAgentTransport); ok {
+			transport.Original = httpoptions.Transport(transport.Original, options, feed.URL, feed.Link)
+		}
+	}
+	return client, nil
+}
+
+// getFetchHTTPClient returns the HTTP client of a feed for fetching it, which reports
+// the responses to the fetch log
+func (f *Fetcher) getFetchHTTPClient(feed models.Feed) (*http.Client, error) {
+	client, err := f.getHTTPClient(feed)
+	if err != nil {
+		return nil, err
+	}
+	client.Transport = reportingTransport{base: client.Transport}
+	return client, nil
+}
+
+// feedHTTPOptions returns the HTTP options of a saved feed, or nil if it has none
+func (f *Fetcher) feedHTTPOptions(feed models.Feed) *models.FeedHTTPOptions {
+	if feed.ID == 0 {
+		return nil
+	}
+	options, err := f.db.GetFeedHTTPOptions(feed.ID)
+	if err != nil {
+		log.Printf("Error loading HTTP options of feed %d: %v", feed.ID, err)
+		return nil
+	}
+	if httpoptions.IsEmpty(options) {
+		return nil
+	}
+	return options
 }
 
 func (f *Fetcher) FetchAll(ctx context.Context) {
@@ -256,6 +315,14 @@ func (f *Fetcher) FetchAll(ctx context.Context) {
 		log.Printf("Standard refresh: %d feeds (skipped %d FreshRSS feeds)", len(filteredFeeds), freshRSSCount)
 	}
 
+	// Feeds that keep failing back off or are paused
+	filteredFeeds = f.dueFeeds(filteredFeeds)
+	f.cleanupFetchLog()
+	if len(filteredFeeds) == 0 {
+		f.taskManager.MarkCompleted()
+		return
+	}
+
 	// Update task manager capacity based on network
 	concurrency := f.getConcurrencyLimit()
 	f.taskManager.SetPoolCapacity(concurrency)
@@ -265,8 +332,15 @@ func (f *Fetcher) FetchAll(ctx context.Context) {
 }
 
 func (f *Fetcher) FetchFeed(ctx context.Context, feed models.Feed) {
-	// Use ParseFeedWithFeed with normal priority for feed refresh
-	parsedFeed, err := f.ParseFeedWithFeed(ctx, &feed, false) // Normal priority for refresh
+	// Conditional request with normal priority for feed refresh
+	validators := f.loadHTTPValidators(&feed)
+	state := f.loadScriptState(&feed)
+	parsedFeed, err := f.parseAndTransformFeed(ctx, &feed, false, validators, state)
+	if errors.Is(err, source.ErrNotModified) {
+		utils.DebugLog("Feed not modified: %s", feed.Title)
+		f.db.UpdateFeedError(feed.ID, "")
+		return
+	}
 	if err != nil {
 		log.Printf("Error parsing feed %s: %v", feed.URL, err)
 		f.db.UpdateFeedError(feed.ID, err.Error())
@@ -312,33 +386,53 @@ func (f *Fetcher) FetchFeed(ctx context.Context, feed models.Feed) {
 
 		if err := f.db.SaveArticles(ctx, articlesToSave); err != nil {
 			log.Printf("Error saving articles for feed %s: %v", feed.Title, err)
-		} else {
-			// Cache article content from RSS feed
-			f.cacheArticleContents(articlesWithContent)
+			return
+		}
 
-			// Apply rules to newly saved articles
-			// We fetch the recent articles for this feed since SaveArticles doesn't return IDs
-			// This is limited to the number of articles we just saved
-			savedArticles, err := f.db.GetArticles("", feed.ID, "", false, len(articlesToSave), 0)
-			if err == nil && len(savedArticles) > 0 {
-				engine := rules.NewEngine(f.db)
-				affected, err := engine.ApplyRulesToArticles(savedArticles)
-				if err != nil {
-					log.Printf("Error applying rules for feed %s: %v", feed.Title, err)
-				} else if affected > 0 {
-					utils.DebugLog("Applied rules to %d articles in feed %s", affected, feed.Title)
-				}
+		// Cache article content from RSS feed
+		f.cacheArticleContents(articlesWithContent)
+
+		// Group new articles with the same story from other feeds
+		f.detectDuplicates(articlesWithContent)
+
+		// Store podcast episode metadata and queue automatic downloads
+		f.savePodcastEpisodes(feed, articlesWithContent)
+
+		// Apply rules to newly saved articles
+		// We fetch the recent articles for this feed since SaveArticles doesn't return IDs
+		// This is limited to the number of articles we just saved
+		savedArticles, err := f.db.GetArticles("", feed.ID, "", false, len(articlesToSave), 0)
+		if err == nil && len(savedArticles) > 0 {
+			engine := rules.NewEngine(f.db)
+			engine.SetServices(f.ruleServices)
+			affected, err := engine.ApplyRulesToArticles(savedArticles)
+			if err != nil {
+				log.Printf("Error applying rules for feed %s: %v", feed.Title, err)
+			} else if affected > 0 {
+				utils.DebugLog("Applied rules to %d articles in feed %s", affected, feed.Title)
 			}
 		}
 	}
+
+	// Only remember the validators and script state once the articles are safely stored,
+	// otherwise a later 304 or script run would skip articles that were never saved
+	f.saveHTTPValidators(feed.ID, validators)
+	f.saveScriptState(feed.ID, state)
 	utils.DebugLog("Updated feed: %s", feed.Title)
 }
 
 // fetchFeedWithContext is the internal fetch method used by TaskManager
 // Returns error instead of storing in progress.Errors
+// A 304 Not Modified response is treated as a successful fetch with no new articles.
 func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) error {
-	// Use ParseFeedWithFeed with normal priority for feed refresh
-	parsedFeed, err := f.ParseFeedWithFeed(ctx, &feed, false)
+	// Conditional request with normal priority for feed refresh
+	validators := f.loadHTTPValidators(&feed)
+	state := f.loadScriptState(&feed)
+	parsedFeed, err := f.parseAndTransformFeed(ctx, &feed, false, validators, state)
+	if errors.Is(err, source.ErrNotModified) {
+		utils.DebugLog("Feed not modified: %s", feed.Title)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -350,6 +444,10 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 	default:
 	}
 
+	// Follow a feed that has moved to its new URL, merging it into the subscription
+	// there if there is one. The articles are saved for the feed it ends up as.
+	feed = f.migrateFeedURL(ctx, feed, parsedFeed)
+
 	// Clear any previous error on successful fetch
 	f.db.UpdateFeedError(feed.ID, "")
 
@@ -387,9 +485,13 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 			articlesToSave[i] = awc.Article
 		}
 
-		if err := f.db.SaveArticles(ctx, articlesToSave); err != nil {
+		newArticles, err := f.db.SaveNewArticles(ctx, articlesToSave)
+		if err != nil {
 			return err
 		}
+		if report := fetchReportFrom(ctx); report != nil {
+			report.setArticles(len(parsedFeed.Items), newArticles)
+		}
 
 		// Post-processing operations (content caching and rule application)
 		// These are non-critical and run asynchronously to avoid blocking the feed refresh
@@ -398,6 +500,12 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 			// Cache article content from RSS feed
 			f.cacheArticleContents(articlesWithContent)
 
+			// Group new articles with the same story from other feeds
+			f.detectDuplicates(articlesWithContent)
+
+			// Store podcast episode metadata and queue automatic downloads
+			f.savePodcastEpisodes(feed, articlesWithContent)
+
 			// Apply rules to newly saved articles
 			savedArticles, err := f.db.GetArticles("", feed.ID, "", false, len(articlesToSave), 0)
 			if err != nil {
@@ -409,6 +517,7 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 			}
 
 			engine := rules.NewEngine(f.db)
+			engine.SetServices(f.ruleServices)
 			affected, err := engine.ApplyRulesToArticles(savedArticles)
 			if err != nil {
 				log.Printf("Error applying rules for feed %s: %v", feed.Title, err)
@@ -417,6 +526,9 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 			}
 		}()
 	}
+
+	f.saveHTTPValidators(feed.ID, validators)
+	f.saveScriptState(feed.ID, state)
 	return nil
 }
 
@@ -429,8 +541,8 @@ func (f *Fetcher) FetchSingleFeed(ctx context.Context, feed models.Feed, isManua
 	if isManual {
 		// Manual operations go to queue head
 		f.taskManager.AddToQueueHead(ctx, feed, TaskReasonManualRefresh)
-	} else {
-		// Scheduled operations go to queue tail
+	} else if f.refreshDue(feed) {
+		// Scheduled operations go to queue tail, unless the feed keeps failing
 		f.taskManager.AddToQueueTail(ctx, feed, TaskReasonScheduledCustom)
 	}
 }
@@ -486,3 +598,35 @@ func (f *Fetcher) cacheArticleContents(articlesWithContent []*ArticleWithContent
 		}
 	}
 }
+
+// detectDuplicates assigns saved articles that report the same story as articles of other
+// feeds to that story. Articles that were checked before are skipped by the detector.
+func (f *Fetcher) detectDuplicates(articlesWithContent []*ArticleWithContent) {
+	if f.dedup == nil || !f.dedup.Enabled() {
+		return
+	}
+
+	articles := make([]dedup.Article, 0, len(articlesWithContent))
+	for _, awc := range articlesWithContent {
+		a := awc.Article
+		articleID, err := f.db.GetArticleIDByUniqueID(a.Title, a.FeedID, a.PublishedAt, a.HasValidPublishedTime)
+		if err != nil {
+			continue
+		}
+		articles = append(articles, dedup.Article{
+			ID:          articleID,
+			FeedID:      a.FeedID,
+			Title:       a.Title,
+			URL:         a.URL,
+			Content:     awc.Content,
+			PublishedAt: a.PublishedAt,
+		})
+	}
+
+	found, err := f.dedup.Process(articles)
+	if err != nil {
+		log.Printf("Error detecting duplicate articles: %v", err)
+	} else if found > 0 {
+		utils.DebugLog("Found %d duplicate articles", found)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"MrRSS/internal/utils/httputil"
 	"context"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
@@ -102,3 +103,37 @@ func TestSetupTranslatorSelectsNonNil(t *testing.T) {
 	// Translation is now handled on-demand in the frontend
 	t.Skip("Translation setup removed from Fetcher - now handled on-demand")
 }
+
+func TestFetchAndSanitizeFeedAppliesHTTPOptions(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Write([]byte(`<rss version="2.0"><channel><title>Members</title></channel></rss>`))
+	}))
+	defer server.Close()
+
+	db := setupDBForFeedTests(t)
+	f := NewFetcher(db)
+	feedID, _ := db.AddFeed(&models.Feed{Title: "Members", URL: server.URL + "/feed"})
+	db.SaveFeedHTTPOptions(&models.FeedHTTPOptions{
+		FeedID:     feedID,
+		Method:     http.MethodPost,
+		UserAgent:  "Reader/1.0",
+		AuthType:   "bearer",
+		AuthSecret: "token",
+	})
+	feed, _ := db.GetFeedByID(feedID)
+
+	if _, err := f.fetchAndSanitizeFeed(context.Background(), *feed, feed.URL, nil); err != nil {
+		t.Fatalf("fetchAndSanitizeFeed error: %v", err)
+	}
+	if got.Method != http.MethodPost {
+		t.Errorf("expected POST, got %s", got.Method)
+	}
+	if got.UserAgent() != "Reader/1.0" {
+		t.Errorf("expected the custom user agent, got %q", got.UserAgent())
+	}
+	if got.Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("expected the bearer token, got %q", got.Header.Get("Authorization"))
+	}
+}
@@ -0,0 +1,200 @@
+// Package health implements the policy for feeds that keep failing or no longer
+// publish, and aggregates the fetch log of feeds for the health dashboard.
+//
+// Scheduled refreshes of a feed back off exponentially once it has failed a number of
+// times in a row, and stop when it has been failing for a configurable period. A feed
+// that is fetched fine but has had no new articles for a long time is flagged as dead.
+// Manual refreshes always run, and a successful one ends the backoff or pause.
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/config"
+	"MrRSS/internal/models"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Feed statuses
+const (
+	StatusHealthy = "healthy"
+	StatusFailing = "failing"
+	StatusBackoff = "backoff"
+	StatusPaused  = "paused"
+	StatusDead    = "dead"
+	StatusUnknown = "unknown" // Not refreshed since fetches are logged
+)
+
+// Error classes of failed fetch attempts
+const (
+	ErrorTimeout = "timeout"
+	ErrorNetwork = "network"
+	ErrorTLS     = "tls"
+	ErrorHTTP    = "http"
+	ErrorParse   = "parse"
+	ErrorOther   = "other"
+)
+
+// Setting keys of the policy
+const (
+	SettingBackoffFailures = "feed_health_backoff_failures"
+	SettingPauseDays       = "feed_health_pause_days"
+	SettingDeadDays        = "feed_health_dead_days"
+)
+
+// The first backoff delay, doubled with every further failure up to maxBackoff
+const (
+	baseBackoff = time.Hour
+	maxBackoff  = 24 * time.Hour
+)
+
+// SettingsProvider reads settings
+type SettingsProvider interface {
+	GetSetting(key string) (string, error)
+}
+
+// Policy decides when feeds are refreshed and how they are flagged
+type Policy struct {
+	BackoffFailures int           // Failed refreshes in a row before backing off, 0 to never back off
+	PauseAfter      time.Duration // Failing period after which scheduled refreshes stop, 0 to never pause
+	DeadAfter       time.Duration // Period without new articles after which a feed is dead, 0 to never flag
+}
+
+// LoadPolicy returns the policy of the current settings
+func LoadPolicy(settings SettingsProvider) Policy {
+	return Policy{
+		BackoffFailures: intSetting(settings, SettingBackoffFailures),
+		PauseAfter:      time.Duration(intSetting(settings, SettingPauseDays)) * 24 * time.Hour,
+		DeadAfter:       time.Duration(intSetting(settings, SettingDeadDays)) * 24 * time.Hour,
+	}
+}
+
+// intSetting returns a non-negative integer setting, or its default if it is not valid
+func intSetting(settings SettingsProvider, key string) int {
+	value, err := settings.GetSetting(key)
+	if err == nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && n >= 0 {
+			return n
+		}
+	}
+	n, _ := strconv.Atoi(config.GetString(key))
+	return n
+}
+
+// NextAttempt returns the earliest time of the next scheduled refresh of a feed that
+// backs off, or the zero time if it does not
+func (p Policy) NextAttempt(h *models.FeedHealth) time.Time {
+	if h == nil || h.LastAttemptAt == nil || p.BackoffFailures <= 0 || h.ConsecutiveFailures < p.BackoffFailures {
+		return time.Time{}
+	}
+	delay := maxBackoff
+	if doublings := h.ConsecutiveFailures - p.BackoffFailures; doublings < 5 {
+		delay = min(baseBackoff<<doublings, maxBackoff)
+	}
+	return h.LastAttemptAt.Add(delay)
+}
+
+// Paused reports whether a feed has been failing for so long that it is no longer
+// refreshed on schedule
+func (p Policy) Paused(h *models.FeedHealth, now time.Time) bool {
+	return p.PauseAfter > 0 && h != nil && h.FailingSince != nil && now.Sub(*h.FailingSince) >= p.PauseAfter
+}
+
+// Due reports whether a scheduled refresh of a feed should run now
+func (p Policy) Due(h *models.FeedHealth, now time.Time) bool {
+	return !p.Paused(h, now) && !now.Before(p.NextAttempt(h))
+}
+
+// Dead reports whether a feed that is fetched fine has had no new articles for too long.
+// lastNew is the time of the last new article, nil if unknown.
+func (p Policy) Dead(h *models.FeedHealth, lastNew *time.Time, now time.Time) bool {
+	return p.DeadAfter > 0 && h != nil && h.LastSuccessAt != nil && h.ConsecutiveFailures == 0 &&
+		lastNew != nil && now.Sub(*lastNew) >= p.DeadAfter
+}
+
+// Status returns the status of a feed
+func (p Policy) Status(h *models.FeedHealth, lastNew *time.Time, now time.Time) string {
+	switch {
+	case h == nil || h.LastAttemptAt == nil:
+		return StatusUnknown
+	case p.Paused(h, now):
+		return StatusPaused
+	case p.BackoffFailures > 0 && h.ConsecutiveFailures >= p.BackoffFailures:
+		return StatusBackoff
+	case h.ConsecutiveFailures > 0:
+		return StatusFailing
+	case p.Dead(h, lastNew, now):
+		return StatusDead
+	default:
+		return StatusHealthy
+	}
+}
+
+// LastNewArticles returns the time of the last new article of a feed: the last refresh
+// that saved new articles, or the publish time of its latest article before that was logged
+func LastNewArticles(h *models.FeedHealth, feed *models.Feed) *time.Time {
+	if h != nil && h.LastNewArticlesAt != nil {
+		return h.LastNewArticlesAt
+	}
+	return feed.LatestArticleTime
+}
+
+// ClassifyError returns the error class of a failed fetch attempt. status is the HTTP
+// status of the last response, 0 if there was none.
+func ClassifyError(err error, status int) string {
+	if err == nil {
+		return ""
+	}
+
+	var httpErr gofeed.HTTPError
+	if status >= 400 || errors.As(err, &httpErr) {
+		return ErrorHTTP
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorTimeout
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &certErr) || errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr) || errors.As(err, &recordErr) {
+		return ErrorTLS
+	}
+
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	if errors.As(err, &dnsErr) || errors.As(err, &opErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorNetwork
+	}
+
+	var xmlErr *xml.SyntaxError
+	var jsonErr *json.SyntaxError
+	if errors.Is(err, gofeed.ErrFeedTypeNotDetected) || errors.As(err, &xmlErr) || errors.As(err, &jsonErr) {
+		return ErrorParse
+	}
+
+	// Sources wrap parse errors in their own messages
+	message := strings.ToLower(err.Error())
+	for _, hint := range []string{"parse", "syntax error", "not a valid", "failed to detect feed type", "invalid character"} {
+		if strings.Contains(message, hint) {
+			return ErrorParse
+		}
+	}
+	return ErrorOther
+}
@@ -0,0 +1,186 @@
+package health
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+type mapSettings map[string]string
+
+func (m mapSettings) GetSetting(key string) (string, error) {
+	value, ok := m[key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestLoadPolicy(t *testing.T) {
+	p := LoadPolicy(mapSettings{SettingBackoffFailures: "5", SettingPauseDays: "2", SettingDeadDays: "invalid"})
+	if p.BackoffFailures != 5 {
+		t.Errorf("expected 5 backoff failures, got %d", p.BackoffFailures)
+	}
+	if p.PauseAfter != 48*time.Hour {
+		t.Errorf("expected pause after 48h, got %v", p.PauseAfter)
+	}
+	// Invalid settings fall back to the default
+	if p.DeadAfter != 90*24*time.Hour {
+		t.Errorf("expected default dead period of 90 days, got %v", p.DeadAfter)
+	}
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	p := Policy{BackoffFailures: 3, PauseAfter: 14 * 24 * time.Hour}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	last := now.Add(-30 * time.Minute)
+
+	tests := []struct {
+		failures int
+		next     time.Time
+	}{
+		{2, time.Time{}},
+		{3, last.Add(time.Hour)},
+		{4, last.Add(2 * time.Hour)},
+		{5, last.Add(4 * time.Hour)},
+		{8, last.Add(24 * time.Hour)},
+		{100, last.Add(24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		h := &models.FeedHealth{ConsecutiveFailures: tt.failures, FailingSince: ptr(now.Add(-time.Hour)), LastAttemptAt: &last}
+		if next := p.NextAttempt(h); !next.Equal(tt.next) {
+			t.Errorf("%d failures: expected next attempt %v, got %v", tt.failures, tt.next, next)
+		}
+		if due := p.Due(h, now); due != tt.next.IsZero() {
+			t.Errorf("%d failures: expected due=%v", tt.failures, tt.next.IsZero())
+		}
+	}
+
+	if !p.Due(nil, now) {
+		t.Error("expected feeds without health state to be due")
+	}
+	if (Policy{}).NextAttempt(&models.FeedHealth{ConsecutiveFailures: 10, LastAttemptAt: &last}) != (time.Time{}) {
+		t.Error("expected no backoff when it is disabled")
+	}
+}
+
+func TestPolicy_Status(t *testing.T) {
+	p := Policy{BackoffFailures: 3, PauseAfter: 7 * 24 * time.Hour, DeadAfter: 30 * 24 * time.Hour}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	recent := ptr(now.Add(-time.Hour))
+	old := ptr(now.Add(-60 * 24 * time.Hour))
+
+	tests := []struct {
+		name    string
+		h       *models.FeedHealth
+		lastNew *time.Time
+		want    string
+	}{
+		{"no state", nil, recent, StatusUnknown},
+		{"healthy", &models.FeedHealth{LastAttemptAt: recent, LastSuccessAt: recent}, recent, StatusHealthy},
+		{"failing", &models.FeedHealth{ConsecutiveFailures: 1, FailingSince: recent, LastAttemptAt: recent}, recent, StatusFailing},
+		{"backoff", &models.FeedHealth{ConsecutiveFailures: 3, FailingSince: recent, LastAttemptAt: recent}, recent, StatusBackoff},
+		{"paused", &models.FeedHealth{ConsecutiveFailures: 3, FailingSince: old, LastAttemptAt: recent}, recent, StatusPaused},
+		{"dead", &models.FeedHealth{LastAttemptAt: recent, LastSuccessAt: recent}, old, StatusDead},
+		{"unknown last article", &models.FeedHealth{LastAttemptAt: recent, LastSuccessAt: recent}, nil, StatusHealthy},
+	}
+	for _, tt := range tests {
+		if got := p.Status(tt.h, tt.lastNew, now); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+
+	paused := &models.FeedHealth{ConsecutiveFailures: 3, FailingSince: old, LastAttemptAt: old}
+	if p.Due(paused, now) {
+		t.Error("expected paused feeds not to be due")
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		want   string
+	}{
+		{"success", nil, 200, ""},
+		{"http status", errors.New("http error: 404 Not Found"), 404, ErrorHTTP},
+		{"timeout", fmt.Errorf("fetch: %w", context.DeadlineExceeded), 0, ErrorTimeout},
+		{"dns", &net.DNSError{Err: "no such host", Name: "example.invalid"}, 0, ErrorNetwork},
+		{"tls", fmt.Errorf("get: %w", x509.UnknownAuthorityError{}), 0, ErrorTLS},
+		{"xml", fmt.Errorf("parse: %w", &xml.SyntaxError{Msg: "unexpected EOF", Line: 1}), 200, ErrorParse},
+		{"message", errors.New("failed to parse feed"), 200, ErrorParse},
+		{"other", errors.New("something else"), 200, ErrorOther},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err, tt.status); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []int64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+	tests := []struct {
+		percent float64
+		want    int64
+	}{
+		{0, 10},
+		{50, 50},
+		{90, 90},
+		{99, 100},
+		{100, 100},
+	}
+	for _, tt := range tests {
+		if got := Percentile(values, tt.percent); got != tt.want {
+			t.Errorf("p%v: expected %d, got %d", tt.percent, tt.want, got)
+		}
+	}
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("expected 0 without values, got %d", got)
+	}
+}
+
+func TestReport(t *testing.T) {
+	p := Policy{BackoffFailures: 3, DeadAfter: 30 * 24 * time.Hour}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	feed := &models.Feed{ID: 1, Title: "Feed", URL: "https://example.com/feed.xml", LatestArticleTime: ptr(now.Add(-50 * time.Hour))}
+	h := &models.FeedHealth{FeedID: 1, LastAttemptAt: ptr(now), LastSuccessAt: ptr(now)}
+	entries := []models.FeedFetchLog{
+		{FeedID: 1, DurationMs: 300, ErrorClass: ErrorTimeout, Error: "timeout"},
+		{FeedID: 1, DurationMs: 100},
+		{FeedID: 1, DurationMs: 200},
+		{FeedID: 1, DurationMs: 400, HTTPStatus: 200, RedirectURL: "https://example.org/feed.xml"},
+	}
+
+	r := Report(feed, h, entries, p, now)
+	if r.Status != StatusHealthy || r.Attempts != 4 || r.SuccessRate != 0.75 {
+		t.Errorf("unexpected report: %+v", r)
+	}
+	if r.LatencyP50Ms != 200 || r.LatencyP90Ms != 400 {
+		t.Errorf("expected p50 200 and p90 400, got %d and %d", r.LatencyP50Ms, r.LatencyP90Ms)
+	}
+	// The last new article falls back to the latest article of the feed
+	if r.DaysWithoutNew != 2 {
+		t.Errorf("expected 2 days without new articles, got %d", r.DaysWithoutNew)
+	}
+	if r.LastHTTPStatus != 200 || r.LastError != "" || r.RedirectURL != "https://example.org/feed.xml" {
+		t.Errorf("expected the last attempt to be reported, got %+v", r)
+	}
+
+	empty := Report(&models.Feed{ID: 2}, nil, nil, p, now)
+	if empty.Status != StatusUnknown || empty.DaysWithoutNew != -1 || empty.Attempts != 0 {
+		t.Errorf("unexpected report without state: %+v", empty)
+	}
+}
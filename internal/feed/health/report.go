@@ -0,0 +1,86 @@
+package health
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// Reports aggregates the fetch attempts of feeds, oldest first, with their health state.
+// Feeds without a state or attempts are reported with the unknown status.
+func Reports(feeds []models.Feed, states map[int64]*models.FeedHealth, entries []models.FeedFetchLog, p Policy, now time.Time) []models.FeedHealthReport {
+	byFeed := make(map[int64][]models.FeedFetchLog)
+	for _, e := range entries {
+		byFeed[e.FeedID] = append(byFeed[e.FeedID], e)
+	}
+
+	reports := make([]models.FeedHealthReport, 0, len(feeds))
+	for i := range feeds {
+		feed := &feeds[i]
+		reports = append(reports, Report(feed, states[feed.ID], byFeed[feed.ID], p, now))
+	}
+	return reports
+}
+
+// Report aggregates the fetch attempts of one feed, oldest first, with its health state
+func Report(feed *models.Feed, h *models.FeedHealth, entries []models.FeedFetchLog, p Policy, now time.Time) models.FeedHealthReport {
+	lastNew := LastNewArticles(h, feed)
+	r := models.FeedHealthReport{
+		FeedID:         feed.ID,
+		Title:          feed.Title,
+		URL:            feed.URL,
+		Status:         p.Status(h, lastNew, now),
+		Attempts:       len(entries),
+		DaysWithoutNew: -1,
+	}
+	if lastNew != nil {
+		r.LastNewArticlesAt = lastNew
+		r.DaysWithoutNew = int(now.Sub(*lastNew) / (24 * time.Hour))
+	}
+	if h != nil {
+		r.ConsecutiveFailures = h.ConsecutiveFailures
+		r.FailingSince = h.FailingSince
+		r.LastSuccessAt = h.LastSuccessAt
+		r.RedirectURL = h.RedirectURL
+		if next := p.NextAttempt(h); next.After(now) {
+			r.NextAttemptAt = &next
+		}
+	}
+
+	if len(entries) == 0 {
+		return r
+	}
+	durations := make([]int64, len(entries))
+	successes := 0
+	for i, e := range entries {
+		durations[i] = e.DurationMs
+		if e.ErrorClass == "" {
+			successes++
+		}
+	}
+	slices.Sort(durations)
+	r.SuccessRate = float64(successes) / float64(len(entries))
+	r.LatencyP50Ms = Percentile(durations, 50)
+	r.LatencyP90Ms = Percentile(durations, 90)
+	r.LatencyP99Ms = Percentile(durations, 99)
+
+	last := entries[len(entries)-1]
+	r.LastHTTPStatus = last.HTTPStatus
+	r.LastErrorClass = last.ErrorClass
+	r.LastError = last.Error
+	if r.RedirectURL == "" {
+		r.RedirectURL = last.RedirectURL
+	}
+	return r
+}
+
+// Percentile returns the nearest-rank percentile of sorted values, 0 if there are none
+func Percentile(sorted []int64, percent float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(percent / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
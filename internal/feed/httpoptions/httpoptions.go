@@ -0,0 +1,290 @@
+// Package httpoptions applies the per-feed HTTP options: a custom user agent, headers,
+// cookies and Basic or Bearer authentication. Options only apply to requests to the
+// site of the feed, so that credentials are not sent to image hosts or other sites
+// that articles link to.
+package httpoptions
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"MrRSS/internal/models"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Authentication types
+const (
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+)
+
+// IsEmpty reports whether the options change nothing
+func IsEmpty(o *models.FeedHTTPOptions) bool {
+	return o == nil || (o.Method == "" && o.UserAgent == "" && strings.TrimSpace(o.Headers) == "" &&
+		strings.TrimSpace(o.Cookies) == "" && o.AuthType == "")
+}
+
+// Validate checks the method, headers, cookies and authentication type
+func Validate(o *models.FeedHTTPOptions) error {
+	switch o.Method {
+	case "", http.MethodGet, http.MethodPost:
+	default:
+		return fmt.Errorf("unsupported request method %q, expected GET or POST", o.Method)
+	}
+	if strings.ContainsAny(o.UserAgent, "\r\n") {
+		return fmt.Errorf("the user agent must be a single line")
+	}
+	if _, err := ParseHeaders(o.Headers); err != nil {
+		return err
+	}
+	if _, err := ParseCookies(o.Cookies); err != nil {
+		return err
+	}
+	switch o.AuthType {
+	case "", AuthBasic, AuthBearer:
+	default:
+		return fmt.Errorf("unsupported authentication type %q, expected basic or bearer", o.AuthType)
+	}
+	return nil
+}
+
+// ParseHeaders parses request headers given as one "Name: value" per line
+func ParseHeaders(text string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header line %q, expected \"Name: value\"", line)
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// ParseCookies parses cookies given as in a Cookie header, "name=value; name2=value2".
+// Cookies may also be given one per line.
+func ParseCookies(text string) ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == ';' || r == '\n' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t,") {
+			return nil, fmt.Errorf("invalid cookie %q, expected \"name=value\"", part)
+		}
+		cookies = append(cookies, &http.Cookie{Name: name, Value: strings.TrimSpace(value)})
+	}
+	return cookies, nil
+}
+
+// Method returns the method of the feed request
+func Method(o *models.FeedHTTPOptions) string {
+	if o == nil || o.Method == "" {
+		return http.MethodGet
+	}
+	return o.Method
+}
+
+// Apply sets the user agent, headers, cookies and authentication of the options on a
+// request. Headers, cookies and secrets without a value are skipped; they are left
+// when options are exported without their secrets.
+func Apply(req *http.Request, o *models.FeedHTTPOptions) {
+	if o == nil {
+		return
+	}
+	if o.UserAgent != "" {
+		req.Header.Set("User-Agent", o.UserAgent)
+	}
+	// Invalid options are rejected when they are saved
+	headers, _ := ParseHeaders(o.Headers)
+	for name, value := range headers {
+		if value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	cookies, _ := ParseCookies(o.Cookies)
+	for _, cookie := range cookies {
+		if cookie.Value != "" {
+			req.AddCookie(cookie)
+		}
+	}
+	switch o.AuthType {
+	case AuthBasic:
+		if o.AuthUsername != "" {
+			req.SetBasicAuth(o.AuthUsername, o.AuthSecret)
+		}
+	case AuthBearer:
+		if o.AuthSecret != "" {
+			req.Header.Set("Authorization", "Bearer "+o.AuthSecret)
+		}
+	}
+}
+
+// ApplyToSite applies the options to a request if it goes to the site of one of the
+// given URLs, usually the feed URL and the website link of the feed
+func ApplyToSite(req *http.Request, o *models.FeedHTTPOptions, siteURLs ...string) {
+	if IsEmpty(o) || req == nil || req.URL == nil {
+		return
+	}
+	for _, siteURL := range siteURLs {
+		if SameSite(req.URL, siteURL) {
+			Apply(req, o)
+			return
+		}
+	}
+}
+
+// SameSite reports whether a request URL belongs to the same site as siteURL, that is
+// the same registrable domain, such as feeds.example.com and www.example.com
+func SameSite(requestURL *url.URL, siteURL string) bool {
+	site, err := url.Parse(siteURL)
+	if err != nil || (site.Scheme != "http" && site.Scheme != "https") {
+		return false
+	}
+	requestHost := strings.ToLower(requestURL.Hostname())
+	siteHost := strings.ToLower(site.Hostname())
+	if requestHost == "" || siteHost == "" {
+		return false
+	}
+	if requestHost == siteHost {
+		return true
+	}
+	if net.ParseIP(requestHost) != nil || net.ParseIP(siteHost) != nil {
+		return false
+	}
+	requestDomain, err := publicsuffix.EffectiveTLDPlusOne(requestHost)
+	if err != nil {
+		return false
+	}
+	siteDomain, err := publicsuffix.EffectiveTLDPlusOne(siteHost)
+	if err != nil {
+		return false
+	}
+	return requestDomain == siteDomain
+}
+
+// Transport returns a round tripper that applies the options to the requests to the
+// site of the given URLs, including redirects within the site
+func Transport(base http.RoundTripper, o *models.FeedHTTPOptions, siteURLs ...string) http.RoundTripper {
+	if IsEmpty(o) {
+		return base
+	}
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		ApplyToSite(req, o, siteURLs...)
+		return base.RoundTrip(req)
+	})
+}
+
+// roundTripFunc is an adapter for ordinary functions as http.RoundTripper
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper
+func (rt roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return rt(req)
+}
+
+// Metadata returns a copy of the options without secrets, as exported in backups and
+// OPML files: the names of headers and cookies are kept with empty values, and the
+// auth secret is removed.
+func Metadata(o *models.FeedHTTPOptions) *models.FeedHTTPOptions {
+	if o == nil {
+		return nil
+	}
+	m := *o
+	m.FeedID = 0
+	m.Headers = FromNames(HeaderNames(o), nil).Headers
+	m.Cookies = FromNames(nil, CookieNames(o)).Cookies
+	m.AuthSecret = ""
+	return &m
+}
+
+// HeaderNames returns the names of the headers of the options
+func HeaderNames(o *models.FeedHTTPOptions) []string {
+	var names []string
+	for _, line := range strings.Split(o.Headers, "\n") {
+		if name, _, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(name) != "" {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+	return names
+}
+
+// CookieNames returns the names of the cookies of the options
+func CookieNames(o *models.FeedHTTPOptions) []string {
+	cookies, _ := ParseCookies(o.Cookies)
+	names := make([]string, len(cookies))
+	for i, cookie := range cookies {
+		names[i] = cookie.Name
+	}
+	return names
+}
+
+// FromNames returns options with headers and cookies of the given names and empty
+// values, as restored from exported metadata
+func FromNames(headerNames, cookieNames []string) *models.FeedHTTPOptions {
+	o := &models.FeedHTTPOptions{}
+	headers := make([]string, len(headerNames))
+	for i, name := range headerNames {
+		headers[i] = name + ":"
+	}
+	o.Headers = strings.Join(headers, "\n")
+	cookies := make([]string, len(cookieNames))
+	for i, name := range cookieNames {
+		cookies[i] = name + "="
+	}
+	o.Cookies = strings.Join(cookies, "; ")
+	return o
+}
+
+// MergeSecrets fills the empty header and cookie values and the empty auth secret of o
+// from stored, so that importing exported metadata keeps the local secrets
+func MergeSecrets(o, stored *models.FeedHTTPOptions) {
+	if stored == nil {
+		return
+	}
+
+	storedHeaders, _ := ParseHeaders(stored.Headers)
+	lines := strings.Split(o.Headers, "\n")
+	for i, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if ok && strings.TrimSpace(value) == "" && storedHeaders[name] != "" {
+			lines[i] = name + ": " + storedHeaders[name]
+		}
+	}
+	o.Headers = strings.Join(lines, "\n")
+
+	storedCookies, _ := ParseCookies(stored.Cookies)
+	cookies, err := ParseCookies(o.Cookies)
+	if err == nil {
+		parts := make([]string, len(cookies))
+		for i, cookie := range cookies {
+			if cookie.Value == "" {
+				for _, storedCookie := range storedCookies {
+					if storedCookie.Name == cookie.Name {
+						cookie.Value = storedCookie.Value
+					}
+				}
+			}
+			parts[i] = cookie.Name + "=" + cookie.Value
+		}
+		o.Cookies = strings.Join(parts, "; ")
+	}
+
+	if o.AuthSecret == "" && o.AuthType == stored.AuthType && o.AuthUsername == stored.AuthUsername {
+		o.AuthSecret = stored.AuthSecret
+	}
+}
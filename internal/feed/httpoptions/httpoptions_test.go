@@ -0,0 +1,133 @@
+package httpoptions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"MrRSS/internal/models"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []models.FeedHTTPOptions{
+		{},
+		{Method: "POST", Headers: "X-Api-Key: abc\nAccept: application/xml", Cookies: "session=1; lang=en;"},
+		{AuthType: AuthBearer, AuthSecret: "token"},
+	}
+	for _, o := range valid {
+		if err := Validate(&o); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", o, err)
+		}
+	}
+
+	invalid := []models.FeedHTTPOptions{
+		{Method: "DELETE"},
+		{UserAgent: "Reader\nX-Injected: 1"},
+		{Headers: "not a header"},
+		{Cookies: "session"},
+		{AuthType: "digest"},
+	}
+	for _, o := range invalid {
+		if err := Validate(&o); err == nil {
+			t.Errorf("Expected %+v to be invalid", o)
+		}
+	}
+}
+
+func TestApplyToSite(t *testing.T) {
+	options := &models.FeedHTTPOptions{
+		UserAgent:    "Reader/1.0",
+		Headers:      "X-Api-Key: abc\nX-Empty:",
+		Cookies:      "session=s1; empty=",
+		AuthType:     AuthBasic,
+		AuthUsername: "reader",
+		AuthSecret:   "password",
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://cdn.example.com/image.jpg", nil)
+	ApplyToSite(req, options, "https://feeds.example.com/rss")
+	if req.Header.Get("User-Agent") != "Reader/1.0" || req.Header.Get("X-Api-Key") != "abc" {
+		t.Errorf("Expected the options on the same site, got %v", req.Header)
+	}
+	if _, ok := req.Header["X-Empty"]; ok {
+		t.Error("Expected headers without a value to be skipped")
+	}
+	if cookies := req.Cookies(); len(cookies) != 1 || cookies[0].Value != "s1" {
+		t.Errorf("Expected only the session cookie, got %v", cookies)
+	}
+	if user, password, ok := req.BasicAuth(); !ok || user != "reader" || password != "password" {
+		t.Errorf("Expected Basic auth, got %q %q", user, password)
+	}
+
+	other := httptest.NewRequest(http.MethodGet, "https://images.other.com/image.jpg", nil)
+	ApplyToSite(other, options, "https://feeds.example.com/rss", "rsshub://route")
+	if other.Header.Get("X-Api-Key") != "" || other.Header.Get("Authorization") != "" {
+		t.Errorf("Expected no options on another site, got %v", other.Header)
+	}
+
+	bearer := httptest.NewRequest(http.MethodGet, "https://example.com/feed", nil)
+	ApplyToSite(bearer, &models.FeedHTTPOptions{AuthType: AuthBearer, AuthSecret: "token"}, "https://example.com")
+	if bearer.Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("Expected a Bearer token, got %q", bearer.Header.Get("Authorization"))
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}))
+	defer server.Close()
+
+	options := &models.FeedHTTPOptions{Cookies: "session=s1"}
+	client := &http.Client{Transport: Transport(http.DefaultTransport, options, server.URL)}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if cookie, err := got.Cookie("session"); err != nil || cookie.Value != "s1" {
+		t.Errorf("Expected the session cookie, got %v", got.Header)
+	}
+	if len(req.Cookies()) != 0 {
+		t.Error("Expected the original request to be left unchanged")
+	}
+}
+
+func TestMetadataAndMergeSecrets(t *testing.T) {
+	stored := &models.FeedHTTPOptions{
+		FeedID:       1,
+		Method:       "POST",
+		Headers:      "X-Api-Key: abc\nX-Plan: gold",
+		Cookies:      "session=s1",
+		AuthType:     AuthBasic,
+		AuthUsername: "reader",
+		AuthSecret:   "password",
+	}
+
+	metadata := Metadata(stored)
+	want := models.FeedHTTPOptions{
+		Method:       "POST",
+		Headers:      "X-Api-Key:\nX-Plan:",
+		Cookies:      "session=",
+		AuthType:     AuthBasic,
+		AuthUsername: "reader",
+	}
+	if *metadata != want {
+		t.Fatalf("Expected %+v, got %+v", want, *metadata)
+	}
+
+	MergeSecrets(metadata, stored)
+	if metadata.Headers != "X-Api-Key: abc\nX-Plan: gold" || metadata.Cookies != "session=s1" || metadata.AuthSecret != "password" {
+		t.Errorf("Expected the stored secrets to be restored, got %+v", metadata)
+	}
+
+	// The secret of another account is not taken over
+	other := &models.FeedHTTPOptions{AuthType: AuthBasic, AuthUsername: "admin"}
+	MergeSecrets(other, stored)
+	if other.AuthSecret != "" {
+		t.Errorf("Expected no secret for another user, got %q", other.AuthSecret)
+	}
+}
@@ -0,0 +1,64 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"MrRSS/internal/feed/transform"
+	"MrRSS/internal/models"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// parseAndTransformFeed parses a feed and runs its item transform on the parsed items,
+// so that the transform applies between parsing and processArticles
+func (f *Fetcher) parseAndTransformFeed(ctx context.Context, feed *models.Feed, priority bool, validators *httpValidators, state *scriptState) (*gofeed.Feed, error) {
+	parsedFeed, err := f.parseFeedWithFeedInternal(ctx, feed, priority, validators, state)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.applyItemTransform(ctx, feed, parsedFeed); err != nil {
+		return nil, err
+	}
+	return parsedFeed, nil
+}
+
+// applyItemTransform runs the enabled item transform of a feed on its parsed items
+func (f *Fetcher) applyItemTransform(ctx context.Context, feed *models.Feed, parsedFeed *gofeed.Feed) error {
+	if feed.ID == 0 {
+		return nil
+	}
+	t, err := f.db.GetItemTransform(feed.ID)
+	if err != nil {
+		return err
+	}
+	if !t.Enabled || strings.TrimSpace(t.Script) == "" {
+		return nil
+	}
+
+	result, err := transform.Run(ctx, t.Script, transform.Feed{Title: feed.Title, URL: feed.URL}, parsedFeed.Items)
+	if err != nil {
+		return fmt.Errorf("item transform failed: %w", err)
+	}
+	parsedFeed.Items = result.Items
+	return nil
+}
+
+// TestItemTransform fetches the current items of a feed and runs a transform script on
+// them without storing anything. It returns copies of the items as they were before the
+// transform; they are nil if the feed could not be fetched.
+func (f *Fetcher) TestItemTransform(ctx context.Context, feed *models.Feed, script string) ([]gofeed.Item, *transform.Result, error) {
+	// Without a state, script feeds do a full run and keep their stored state
+	parsedFeed, err := f.parseFeedWithFeedInternal(ctx, feed, true, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	before := make([]gofeed.Item, len(parsedFeed.Items))
+	for i, item := range parsedFeed.Items {
+		before[i] = *item
+	}
+	result, err := transform.Run(ctx, script, transform.Feed{Title: feed.Title, URL: feed.URL}, parsedFeed.Items)
+	return before, result, err
+}
@@ -0,0 +1,119 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"MrRSS/internal/feed/httpoptions"
+	"MrRSS/internal/feed/source"
+	"MrRSS/internal/models"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// ParseJSONHeaders parses custom request headers given as one "Name: value" per line
+// Wrapper around httpoptions.ParseHeaders, which parses the headers of all feed types
+func ParseJSONHeaders(text string) (map[string]string, error) {
+	return httpoptions.ParseHeaders(text)
+}
+
+// jsonSourceConfig builds the source configuration of a JSON API feed. The item
+// mapping is stored in the XPath fields of the feed.
+func jsonSourceConfig(feed *models.Feed, options *models.JSONFeedOptions) (*source.Config, error) {
+	config := &source.Config{
+		URL:              feed.URL,
+		SourceType:       source.TypeJSON,
+		JSONItemPath:     feed.XPathItem,
+		JSONTitlePath:    feed.XPathItemTitle,
+		JSONLinkPath:     feed.XPathItemUri,
+		JSONContentPath:  feed.XPathItemContent,
+		JSONDatePath:     feed.XPathItemTimestamp,
+		JSONDateFormat:   feed.XPathItemTimeFormat,
+		JSONAuthorPath:   feed.XPathItemAuthor,
+		JSONImagePath:    feed.XPathItemThumbnail,
+		JSONCategoryPath: feed.XPathItemCategories,
+		JSONUIDPath:      feed.XPathItemUid,
+	}
+	if options != nil {
+		headers, err := ParseJSONHeaders(options.Headers)
+		if err != nil {
+			return nil, err
+		}
+		config.Headers = headers
+		config.JSONNextPagePath = options.NextPagePath
+		config.JSONPageParam = options.PageParam
+		config.JSONMaxPages = options.MaxPages
+	}
+	return config, nil
+}
+
+// ValidateJSONFeed checks the URL, paths and headers of a JSON API feed
+func (f *Fetcher) ValidateJSONFeed(feed *models.Feed, options *models.JSONFeedOptions) error {
+	config, err := jsonSourceConfig(feed, options)
+	if err != nil {
+		return err
+	}
+	return f.jsonSource.Validate(config)
+}
+
+// fetchJSONFeed fetches the items of a JSON API feed
+func (f *Fetcher) fetchJSONFeed(ctx context.Context, feed *models.Feed, options *models.JSONFeedOptions) (*gofeed.Feed, error) {
+	config, err := jsonSourceConfig(feed, options)
+	if err != nil {
+		return nil, err
+	}
+	client, err := f.getFetchHTTPClient(*feed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	config.HTTPClient = client
+	return f.jsonSource.Fetch(ctx, config)
+}
+
+// PreviewJSONFeed fetches a JSON API with the given mapping without subscribing,
+// so that the extracted items can be checked.
+func (f *Fetcher) PreviewJSONFeed(ctx context.Context, feed *models.Feed, options *models.JSONFeedOptions) (*gofeed.Feed, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	return f.fetchJSONFeed(ctx, feed, options)
+}
+
+// AddJSONSubscription adds a JSON API feed after checking that the mapping
+// selects at least one item, and returns the feed ID.
+func (f *Fetcher) AddJSONSubscription(feed *models.Feed, options *models.JSONFeedOptions) (int64, error) {
+	parsedFeed, err := f.PreviewJSONFeed(context.Background(), feed, options)
+	if err != nil {
+		return 0, err
+	}
+	if len(parsedFeed.Items) == 0 {
+		return 0, fmt.Errorf("no items found, the item path %q does not match anything in the response", feed.XPathItem)
+	}
+
+	feed.Type = string(source.TypeJSON)
+	if feed.Title == "" {
+		feed.Title = parsedFeed.Title
+	}
+	feedID, err := f.db.AddFeed(feed)
+	if err != nil {
+		return 0, err
+	}
+
+	options.FeedID = feedID
+	if err := f.db.SaveJSONFeedOptions(options); err != nil {
+		return feedID, fmt.Errorf("failed to save JSON feed options: %w", err)
+	}
+	return feedID, nil
+}
+
+// ImportJSONSubscription imports a JSON API feed with its item mapping, as found
+// in OPML files, and returns the feed ID. Headers and pagination are not exported,
+// so they have to be set again after the import.
+func (f *Fetcher) ImportJSONSubscription(feed models.Feed) (int64, error) {
+	if _, err := url.Parse(feed.URL); err != nil || feed.XPathItem == "" {
+		return 0, fmt.Errorf("invalid JSON feed %q: the URL and item path are required", feed.URL)
+	}
+	feed.Type = string(source.TypeJSON)
+	return f.db.AddFeed(&feed)
+}
@@ -0,0 +1,186 @@
+package feed
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+// maxAutoDownloadsPerFetch limits how many new episodes of a feed are queued for download
+// at once, so that subscribing to a podcast does not download its whole back catalog
+const maxAutoDownloadsPerFetch = 3
+
+// transcriptTypes are the transcript formats in order of preference
+var transcriptTypes = []string{
+	"text/vtt",
+	"application/x-subrip",
+	"application/srt",
+	"application/json",
+	"text/html",
+	"text/plain",
+}
+
+// extractPodcastEpisode extracts the podcast metadata of a feed item with an audio enclosure.
+// It returns nil for items without audio.
+func extractPodcastEpisode(item *gofeed.Item) *models.PodcastEpisode {
+	var enclosure *gofeed.Enclosure
+	for _, enc := range item.Enclosures {
+		if strings.HasPrefix(enc.Type, "audio/") {
+			enclosure = enc
+			break
+		}
+	}
+	if enclosure == nil {
+		return nil
+	}
+
+	ep := &models.PodcastEpisode{
+		EnclosureURL:  enclosure.URL,
+		EnclosureType: enclosure.Type,
+	}
+	ep.EnclosureLength, _ = strconv.ParseInt(strings.TrimSpace(enclosure.Length), 10, 64)
+
+	if itunes := item.ITunesExt; itunes != nil {
+		ep.Duration = parseDuration(itunes.Duration)
+		ep.Season, _ = strconv.Atoi(strings.TrimSpace(itunes.Season))
+		ep.Episode, _ = strconv.Atoi(strings.TrimSpace(itunes.Episode))
+		ep.EpisodeType = strings.ToLower(strings.TrimSpace(itunes.EpisodeType))
+	}
+
+	// Podcasting 2.0 namespace: external chapters and transcripts
+	if podcast, ok := item.Extensions["podcast"]; ok {
+		if chapters := podcast["chapters"]; len(chapters) > 0 {
+			ep.ChaptersURL = chapters[0].Attrs["url"]
+		}
+		ep.TranscriptURL, ep.TranscriptType = preferredTranscript(podcast["transcript"])
+	}
+
+	// Podlove Simple Chapters embedded in the feed
+	if psc, ok := item.Extensions["psc"]; ok {
+		if groups := psc["chapters"]; len(groups) > 0 {
+			ep.Chapters = parseSimpleChapters(groups[0].Children["chapter"])
+		}
+	}
+
+	return ep
+}
+
+// preferredTranscript returns the URL and type of the transcript in the most useful format
+func preferredTranscript(transcripts []ext.Extension) (string, string) {
+	best, bestRank := -1, len(transcriptTypes)
+	for i, t := range transcripts {
+		if t.Attrs["url"] == "" {
+			continue
+		}
+		rank := len(transcriptTypes)
+		for r, typ := range transcriptTypes {
+			if strings.EqualFold(t.Attrs["type"], typ) {
+				rank = r
+				break
+			}
+		}
+		if best < 0 || rank < bestRank {
+			best, bestRank = i, rank
+		}
+	}
+	if best < 0 {
+		return "", ""
+	}
+	return transcripts[best].Attrs["url"], transcripts[best].Attrs["type"]
+}
+
+// parseSimpleChapters parses psc:chapter elements, ordered by start time
+func parseSimpleChapters(elements []ext.Extension) []models.PodcastChapter {
+	var chapters []models.PodcastChapter
+	for _, el := range elements {
+		title := strings.TrimSpace(el.Attrs["title"])
+		if title == "" {
+			continue
+		}
+		chapters = append(chapters, models.PodcastChapter{
+			Start: parseDuration(el.Attrs["start"]),
+			Title: title,
+			URL:   el.Attrs["href"],
+		})
+	}
+	sort.SliceStable(chapters, func(i, j int) bool { return chapters[i].Start < chapters[j].Start })
+	return chapters
+}
+
+// parseDuration parses an itunes:duration or chapter start time, given in seconds or as
+// [[HH:]MM:]SS with optional fractional seconds. It returns 0 for invalid values.
+func parseDuration(value string) float64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0
+	}
+	seconds := 0.0
+	for _, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds
+}
+
+// savePodcastEpisodes stores the podcast metadata of saved articles and queues new episodes
+// for download if the feed has auto-download enabled
+func (f *Fetcher) savePodcastEpisodes(feed models.Feed, articlesWithContent []*ArticleWithContent) {
+	var created []*ArticleWithContent
+	for _, awc := range articlesWithContent {
+		if awc.Episode == nil {
+			continue
+		}
+		a := awc.Article
+		articleID, err := f.db.GetArticleIDByUniqueID(a.Title, a.FeedID, a.PublishedAt, a.HasValidPublishedTime)
+		if err != nil {
+			continue
+		}
+		awc.Episode.ArticleID = articleID
+		awc.Episode.FeedID = a.FeedID
+
+		isNew, err := f.db.SavePodcastEpisode(awc.Episode)
+		if err != nil {
+			log.Printf("Error saving podcast episode for article %d: %v", articleID, err)
+			continue
+		}
+		if isNew {
+			created = append(created, awc)
+		}
+	}
+	if len(created) == 0 {
+		return
+	}
+
+	settings, err := f.db.GetPodcastFeedSettings(feed.ID)
+	if err != nil || !settings.AutoDownload {
+		return
+	}
+
+	sort.SliceStable(created, func(i, j int) bool {
+		return created[i].Article.PublishedAt.After(created[j].Article.PublishedAt)
+	})
+	if len(created) > maxAutoDownloadsPerFetch {
+		created = created[:maxAutoDownloadsPerFetch]
+	}
+	for _, awc := range created {
+		if _, err := f.db.QueuePodcastDownload(awc.Episode.ArticleID); err != nil {
+			log.Printf("Error queueing podcast download for article %d: %v", awc.Episode.ArticleID, err)
+		}
+	}
+	utils.DebugLog("Queued %d podcast episodes of feed %s for download", len(created), feed.Title)
+}
@@ -0,0 +1,147 @@
+package feed
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const podcastFeedXML = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"
+	xmlns:podcast="https://podcastindex.org/namespace/1.0" xmlns:psc="http://podlove.org/simple-chapters">
+<channel>
+	<title>Test Podcast</title>
+	<item>
+		<title>Episode 12: Chapters</title>
+		<link>https://example.com/12</link>
+		<enclosure url="https://example.com/12.mp3" type="audio/mpeg" length="1234567"/>
+		<itunes:duration>1:02:03</itunes:duration>
+		<itunes:season>2</itunes:season>
+		<itunes:episode>12</itunes:episode>
+		<itunes:episodeType>full</itunes:episodeType>
+		<podcast:chapters url="https://example.com/12/chapters.json" type="application/json+chapters"/>
+		<podcast:transcript url="https://example.com/12.txt" type="text/plain"/>
+		<podcast:transcript url="https://example.com/12.vtt" type="text/vtt"/>
+		<psc:chapters version="1.2">
+			<psc:chapter start="00:10:00.500" title="News"/>
+			<psc:chapter start="0" title="Intro" href="https://example.com/intro"/>
+		</psc:chapters>
+	</item>
+	<item>
+		<title>Trailer</title>
+		<enclosure url="https://example.com/trailer.m4a" type="audio/x-m4a"/>
+		<itunes:duration>95</itunes:duration>
+	</item>
+	<item>
+		<title>Blog post</title>
+		<enclosure url="https://example.com/cover.jpg" type="image/jpeg"/>
+	</item>
+</channel>
+</rss>`
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]float64{
+		"3723":       3723,
+		"1:02:03":    3723,
+		"62:03":      3723,
+		"00:10:00.5": 600.5,
+		"":           0,
+		"abc":        0,
+		"1:2:3:4":    0,
+	}
+	for value, want := range tests {
+		if got := parseDuration(value); got != want {
+			t.Errorf("parseDuration(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestExtractPodcastEpisode(t *testing.T) {
+	feed, err := gofeed.NewParser().ParseString(podcastFeedXML)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	ep := extractPodcastEpisode(feed.Items[0])
+	if ep == nil {
+		t.Fatal("Expected an episode for an item with audio")
+	}
+	if ep.EnclosureURL != "https://example.com/12.mp3" || ep.EnclosureLength != 1234567 || ep.Duration != 3723 {
+		t.Errorf("Unexpected enclosure %q (%d bytes, %v s)", ep.EnclosureURL, ep.EnclosureLength, ep.Duration)
+	}
+	if ep.Season != 2 || ep.Episode != 12 || ep.EpisodeType != "full" {
+		t.Errorf("Expected season 2 episode 12 (full), got %d/%d (%s)", ep.Season, ep.Episode, ep.EpisodeType)
+	}
+	if ep.ChaptersURL != "https://example.com/12/chapters.json" {
+		t.Errorf("Unexpected chapters URL %q", ep.ChaptersURL)
+	}
+	if ep.TranscriptURL != "https://example.com/12.vtt" || ep.TranscriptType != "text/vtt" {
+		t.Errorf("Expected the WebVTT transcript to be preferred, got %q (%s)", ep.TranscriptURL, ep.TranscriptType)
+	}
+	if len(ep.Chapters) != 2 || ep.Chapters[0].Title != "Intro" || ep.Chapters[1].Start != 600.5 {
+		t.Errorf("Expected 2 chapters ordered by start time, got %+v", ep.Chapters)
+	}
+
+	if ep := extractPodcastEpisode(feed.Items[1]); ep == nil || ep.Duration != 95 {
+		t.Errorf("Expected a 95 s episode, got %+v", ep)
+	}
+	if ep := extractPodcastEpisode(feed.Items[2]); ep != nil {
+		t.Errorf("Expected no episode for an item without audio, got %+v", ep)
+	}
+}
+
+func TestFetchFeedSavesPodcastEpisodes(t *testing.T) {
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create db: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to init db: %v", err)
+	}
+
+	parsed, err := gofeed.NewParser().Parse(strings.NewReader(podcastFeedXML))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	fetcher := NewFetcher(db)
+	fetcher.fp = &MockParser{Feed: parsed}
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Test Podcast", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	if err := db.SavePodcastFeedSettings(&models.PodcastFeedSettings{FeedID: feedID, AutoDownload: true}); err != nil {
+		t.Fatalf("SavePodcastFeedSettings failed: %v", err)
+	}
+	feed, _ := db.GetFeedByID(feedID)
+
+	fetcher.FetchFeed(context.Background(), *feed)
+
+	downloads, err := db.GetPodcastDownloads(feedID)
+	if err != nil {
+		t.Fatalf("GetPodcastDownloads failed: %v", err)
+	}
+	if len(downloads) != 2 {
+		t.Fatalf("Expected both episodes to be queued for download, got %d", len(downloads))
+	}
+
+	// Playback position survives a refresh of the feed
+	articleID := downloads[0].ArticleID
+	if err := db.UpdatePodcastProgress(articleID, 42, 0, false); err != nil {
+		t.Fatalf("UpdatePodcastProgress failed: %v", err)
+	}
+	fetcher.FetchFeed(context.Background(), *feed)
+
+	ep, err := db.GetPodcastEpisode(articleID)
+	if err != nil || ep == nil {
+		t.Fatalf("GetPodcastEpisode failed: %v", err)
+	}
+	if ep.Position != 42 || ep.Duration == 0 || ep.DownloadStatus != models.DownloadQueued {
+		t.Errorf("Expected position and download state to be kept, got %+v", ep)
+	}
+}
@@ -2,8 +2,12 @@ package feed
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
@@ -13,11 +17,44 @@ import (
 	"github.com/mmcdole/gofeed"
 )
 
+const (
+	defaultScriptTimeout = 30 * time.Second
+	maxScriptTimeout     = 10 * time.Minute
+	maxScriptStderr      = 64 * 1024 // Bytes of stderr kept for debugging
+)
+
+// scriptDateFormats are the accepted formats of item dates in the simple JSON output
+var scriptDateFormats = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
 // ScriptExecutor handles executing custom scripts for feed fetching
 type ScriptExecutor struct {
 	scriptsDir string
 }
 
+// ScriptRunOptions are the per-feed options of a script run
+type ScriptRunOptions struct {
+	Args    []string          // Command line arguments
+	Env     map[string]string // Environment variables, added to the environment of MrRSS
+	Timeout time.Duration     // Run timeout; 0 uses the default of 30 seconds
+	State   []byte            // State saved by the previous run
+}
+
+// ScriptResult is the outcome of a script run
+type ScriptResult struct {
+	Feed         *gofeed.Feed
+	State        []byte // State left by the script for the next run
+	StateChanged bool
+	Stderr       string
+	ExitCode     int // -1 if the script could not be started or was stopped
+	Duration     time.Duration
+}
+
 // NewScriptExecutor creates a new ScriptExecutor
 func NewScriptExecutor(scriptsDir string) *ScriptExecutor {
 	return &ScriptExecutor{scriptsDir: scriptsDir}
@@ -38,9 +75,23 @@ func findPythonExecutable(ctx context.Context) (string, error) {
 	return "", fmt.Errorf("no Python executable found")
 }
 
-// ExecuteScript runs the given script and parses the output as an RSS feed
-// The script should output valid RSS/Atom XML to stdout
+// ExecuteScript runs the given script without options and parses the output as a feed
 func (e *ScriptExecutor) ExecuteScript(ctx context.Context, scriptPath string) (*gofeed.Feed, error) {
+	result, err := e.RunScript(ctx, scriptPath, ScriptRunOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return result.Feed, nil
+}
+
+// RunScript runs the given script and parses the output as a feed.
+// The script should output RSS/Atom XML, a JSON Feed, or a simple JSON list of items to stdout.
+// The state of the previous run is written to stdin and to the file named by the
+// MRRSS_STATE_FILE environment variable; what the script leaves in that file is
+// returned as the new state. The result is returned with errors too, for debugging.
+func (e *ScriptExecutor) RunScript(ctx context.Context, scriptPath string, opts ScriptRunOptions) (*ScriptResult, error) {
+	result := &ScriptResult{ExitCode: -1, State: opts.State}
+
 	// Construct full path
 	fullPath := filepath.Join(e.scriptsDir, scriptPath)
 	fullPath = filepath.Clean(fullPath)
@@ -52,68 +103,141 @@ func (e *ScriptExecutor) ExecuteScript(ctx context.Context, scriptPath string) (
 	// Use filepath.Rel to prevent directory traversal attacks
 	relPath, err := filepath.Rel(cleanScriptsDir, fullPath)
 	if err != nil || strings.HasPrefix(relPath, "..") || strings.Contains(relPath, string(filepath.Separator)+"..") {
-		return nil, fmt.Errorf("invalid script path: script must be within scripts directory")
+		return result, fmt.Errorf("invalid script path: script must be within scripts directory")
 	}
 
-	// Create a context with timeout (30 seconds for script execution)
-	execCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	timeout := scriptTimeout(opts.Timeout)
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Prepare command based on OS and file extension
-	var cmd *exec.Cmd
+	cmd, err := scriptCommand(execCtx, fullPath, opts.Args)
+	if err != nil {
+		return result, err
+	}
+
+	// Set working directory to the scripts directory
+	cmd.Dir = e.scriptsDir
+	// Don't wait for child processes of a stopped script that keep the output open
+	cmd.WaitDelay = 2 * time.Second
+
+	// Pass the state on stdin and in a file the script can overwrite
+	stateFile, err := os.CreateTemp("", "mrrss-script-state-*")
+	if err != nil {
+		return result, fmt.Errorf("failed to create script state file: %w", err)
+	}
+	defer os.Remove(stateFile.Name())
+	_, err = stateFile.Write(opts.State)
+	if closeErr := stateFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to write script state file: %w", err)
+	}
+
+	cmd.Env = os.Environ()
+	for name, value := range opts.Env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	cmd.Env = append(cmd.Env, "MRRSS_STATE_FILE="+stateFile.Name())
+	cmd.Stdin = bytes.NewReader(opts.State)
+
+	// Capture stdout and stderr
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// Execute the script
+	start := time.Now()
+	runErr := cmd.Run()
+	result.Duration = time.Since(start)
+	result.Stderr = truncateScriptOutput(stderr.String())
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	if runErr != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			// The deadline of the caller fired before the timeout of the script
+			return result, fmt.Errorf("script stopped after %s by the fetch deadline", result.Duration.Round(time.Second))
+		}
+		if errors.Is(execCtx.Err(), context.DeadlineExceeded) {
+			return result, fmt.Errorf("script timed out after %s", timeout)
+		}
+		if result.Stderr != "" {
+			return result, fmt.Errorf("script execution failed: %v, stderr: %s", runErr, result.Stderr)
+		}
+		return result, fmt.Errorf("script execution failed: %v", runErr)
+	}
+
+	if state, err := os.ReadFile(stateFile.Name()); err == nil && !bytes.Equal(state, opts.State) {
+		result.State = state
+		result.StateChanged = true
+	}
+
+	result.Feed, err = parseScriptOutput(stdout.String())
+	return result, err
+}
+
+// scriptTimeout returns the run timeout of a script: the configured timeout, or the default
+// without one, at most maxScriptTimeout
+func scriptTimeout(configured time.Duration) time.Duration {
+	if configured <= 0 {
+		return defaultScriptTimeout
+	}
+	return min(configured, maxScriptTimeout)
+}
+
+// scriptCommand prepares the command running a script, based on the OS and file extension
+func scriptCommand(ctx context.Context, fullPath string, args []string) (*exec.Cmd, error) {
 	ext := strings.ToLower(filepath.Ext(fullPath))
 
 	switch ext {
 	case ".py":
 		// Python script - try to find a working Python executable
-		pythonCmd, err := findPythonExecutable(execCtx)
+		pythonCmd, err := findPythonExecutable(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("python script execution failed: %w", err)
 		}
-		cmd = exec.CommandContext(execCtx, pythonCmd, fullPath)
+		return exec.CommandContext(ctx, pythonCmd, append([]string{fullPath}, args...)...), nil
 	case ".sh":
 		// Shell script (Unix-like systems)
 		if runtime.GOOS == "windows" {
 			return nil, fmt.Errorf("shell scripts are not supported on Windows")
 		}
-		cmd = exec.CommandContext(execCtx, "bash", fullPath)
+		return exec.CommandContext(ctx, "bash", append([]string{fullPath}, args...)...), nil
 	case ".ps1":
 		// PowerShell script (Windows)
 		if runtime.GOOS != "windows" {
-			cmd = exec.CommandContext(execCtx, "pwsh", "-File", fullPath)
-		} else {
-			cmd = exec.CommandContext(execCtx, "powershell.exe", "-ExecutionPolicy", "Bypass", "-File", fullPath)
+			return exec.CommandContext(ctx, "pwsh", append([]string{"-File", fullPath}, args...)...), nil
 		}
+		return exec.CommandContext(ctx, "powershell.exe", append([]string{"-ExecutionPolicy", "Bypass", "-File", fullPath}, args...)...), nil
 	case ".js":
 		// Node.js script
-		cmd = exec.CommandContext(execCtx, "node", fullPath)
+		return exec.CommandContext(ctx, "node", append([]string{fullPath}, args...)...), nil
 	case ".rb":
 		// Ruby script
-		cmd = exec.CommandContext(execCtx, "ruby", fullPath)
+		return exec.CommandContext(ctx, "ruby", append([]string{fullPath}, args...)...), nil
 	default:
 		// Try to execute directly (for compiled binaries)
-		cmd = exec.CommandContext(execCtx, fullPath)
+		return exec.CommandContext(ctx, fullPath, args...), nil
 	}
+}
 
-	// Set working directory to the scripts directory
-	cmd.Dir = e.scriptsDir
-
-	// Capture stdout and stderr
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	// Execute the script
-	if err := cmd.Run(); err != nil {
-		stderrStr := stderr.String()
-		if stderrStr != "" {
-			return nil, fmt.Errorf("script execution failed: %v, stderr: %s", err, stderrStr)
-		}
-		return nil, fmt.Errorf("script execution failed: %v", err)
+// truncateScriptOutput keeps the end of long script output, where errors usually are
+func truncateScriptOutput(output string) string {
+	if len(output) <= maxScriptStderr {
+		return output
 	}
+	return "…" + output[len(output)-maxScriptStderr:]
+}
 
-	// Get the script output
-	output := stdout.String()
+// parseScriptOutput parses the output of a script as a JSON Feed, a simple JSON
+// list of items, or RSS/Atom XML
+func parseScriptOutput(output string) (*gofeed.Feed, error) {
+	trimmed := strings.TrimSpace(output)
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		return parseScriptJSON(trimmed)
+	}
 
 	// Sanitize the XML to remove problematic links (like file:// URLs)
 	cleanedOutput := sanitizeFeedXML(output)
@@ -130,3 +254,89 @@ func (e *ScriptExecutor) ExecuteScript(ctx context.Context, scriptPath string) (
 
 	return feed, nil
 }
+
+// scriptJSONItem is an item of the simple JSON output
+type scriptJSONItem struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Link        string   `json:"link"`
+	URL         string   `json:"url"`
+	Content     string   `json:"content"`
+	Description string   `json:"description"`
+	Summary     string   `json:"summary"`
+	Author      string   `json:"author"`
+	Published   string   `json:"published"`
+	Date        string   `json:"date"`
+	Image       string   `json:"image"`
+	Categories  []string `json:"categories"`
+}
+
+// scriptJSONFeed is the simple JSON output with feed information
+type scriptJSONFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	Link        string           `json:"link"`
+	Description string           `json:"description"`
+	Items       []scriptJSONItem `json:"items"`
+}
+
+// parseScriptJSON parses JSON output. Objects with a jsonfeed.org version are JSON
+// Feeds; other objects and arrays use the simple format:
+//
+//	{"title": "...", "link": "...", "items": [{"title": "...", "link": "...", "content": "...", "published": "..."}]}
+func parseScriptJSON(output string) (*gofeed.Feed, error) {
+	var simple scriptJSONFeed
+	if strings.HasPrefix(output, "[") {
+		if err := json.Unmarshal([]byte(output), &simple.Items); err != nil {
+			return nil, fmt.Errorf("failed to parse script output as JSON items: %v", err)
+		}
+	} else {
+		if err := json.Unmarshal([]byte(output), &simple); err != nil {
+			return nil, fmt.Errorf("failed to parse script output as JSON: %v", err)
+		}
+		if strings.Contains(simple.Version, "jsonfeed.org") {
+			feed, err := gofeed.NewParser().ParseString(output)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse script output as JSON Feed: %v", err)
+			}
+			return feed, nil
+		}
+	}
+
+	feed := &gofeed.Feed{
+		Title:       simple.Title,
+		Link:        simple.Link,
+		Description: simple.Description,
+		FeedType:    "json",
+	}
+	for _, it := range simple.Items {
+		item := &gofeed.Item{
+			Title:       it.Title,
+			Link:        cmp.Or(it.Link, it.URL),
+			Content:     it.Content,
+			Description: cmp.Or(it.Description, it.Summary),
+			Categories:  it.Categories,
+		}
+		item.GUID = cmp.Or(it.ID, item.Link)
+		if item.Title == "" && item.Link == "" {
+			return nil, fmt.Errorf("script output item %d has neither a title nor a link", len(feed.Items)+1)
+		}
+		if it.Author != "" {
+			item.Author = &gofeed.Person{Name: it.Author}
+		}
+		if it.Image != "" {
+			item.Image = &gofeed.Image{URL: it.Image}
+		}
+		if published := cmp.Or(it.Published, it.Date); published != "" {
+			for _, format := range scriptDateFormats {
+				if t, err := time.Parse(format, published); err == nil {
+					item.Published = published
+					item.PublishedParsed = &t
+					break
+				}
+			}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
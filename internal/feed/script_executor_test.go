@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"MrRSS/internal/models"
 )
 
 func TestScriptExecutor_ExecuteScript_InvalidPath(t *testing.T) {
@@ -135,3 +137,187 @@ func TestFindPythonExecutable(t *testing.T) {
 		t.Errorf("Found Python executable '%s' failed to run: %v", pythonCmd, err)
 	}
 }
+
+func TestScriptExecutor_RunScript_OptionsAndState(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	tempDir := t.TempDir()
+
+	// Print the arguments, environment and state as a JSON item, and save a new state
+	scriptContent := `state=$(cat)
+echo "seen-$1" > "$MRRSS_STATE_FILE"
+echo "[{\"id\": \"$state\", \"title\": \"$1 $2\", \"url\": \"https://example.com/$API_TOKEN\", \"published\": \"2024-05-01 10:00:00\"}]"
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "state.sh"), []byte(scriptContent), 0755); err != nil {
+		t.Fatalf("Failed to create test script: %v", err)
+	}
+
+	executor := NewScriptExecutor(tempDir)
+	result, err := executor.RunScript(context.Background(), "state.sh", ScriptRunOptions{
+		Args:  []string{"one", "two"},
+		Env:   map[string]string{"API_TOKEN": "secret"},
+		State: []byte("old"),
+	})
+	if err != nil {
+		t.Fatalf("RunScript() failed: %v", err)
+	}
+
+	if len(result.Feed.Items) != 1 {
+		t.Fatalf("Feed items count = %d, want 1", len(result.Feed.Items))
+	}
+	item := result.Feed.Items[0]
+	if item.Title != "one two" || item.Link != "https://example.com/secret" || item.GUID != "old" {
+		t.Errorf("Unexpected item %+v", item)
+	}
+	if item.PublishedParsed == nil || item.PublishedParsed.Hour() != 10 {
+		t.Errorf("Expected the published date, got %v", item.PublishedParsed)
+	}
+	if !result.StateChanged || strings.TrimSpace(string(result.State)) != "seen-one" {
+		t.Errorf("Expected the new state, got %q (changed %v)", result.State, result.StateChanged)
+	}
+	if result.ExitCode != 0 {
+		t.Errorf("ExitCode = %d, want 0", result.ExitCode)
+	}
+}
+
+func TestScriptExecutor_RunScript_Failure(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	tempDir := t.TempDir()
+
+	scriptContent := "echo 'login failed' >&2\nexit 3\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "fail.sh"), []byte(scriptContent), 0755); err != nil {
+		t.Fatalf("Failed to create test script: %v", err)
+	}
+
+	executor := NewScriptExecutor(tempDir)
+	result, err := executor.RunScript(context.Background(), "fail.sh", ScriptRunOptions{})
+	if err == nil {
+		t.Fatal("RunScript() should return error for a failing script")
+	}
+	if result.ExitCode != 3 || strings.TrimSpace(result.Stderr) != "login failed" {
+		t.Errorf("Expected exit code 3 and stderr, got %d %q", result.ExitCode, result.Stderr)
+	}
+
+	// The configured timeout stops the script
+	if err := os.WriteFile(filepath.Join(tempDir, "slow.sh"), []byte("sleep 10\n"), 0755); err != nil {
+		t.Fatalf("Failed to create test script: %v", err)
+	}
+	_, err = executor.RunScript(context.Background(), "slow.sh", ScriptRunOptions{Timeout: 100 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+
+	// A shorter deadline of the caller is reported as such
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = executor.RunScript(ctx, "slow.sh", ScriptRunOptions{Timeout: time.Minute})
+	if err == nil || !strings.Contains(err.Error(), "by the fetch deadline") {
+		t.Errorf("Expected the fetch deadline to be reported, got %v", err)
+	}
+}
+
+func TestParseScriptOutput_JSON(t *testing.T) {
+	jsonFeed := `{"version": "https://jsonfeed.org/version/1.1", "title": "JSON Feed",
+		"items": [{"id": "1", "url": "https://example.com/1", "title": "First", "content_html": "<p>Hi</p>"}]}`
+	feed, err := parseScriptOutput(jsonFeed)
+	if err != nil {
+		t.Fatalf("parseScriptOutput() failed for JSON Feed: %v", err)
+	}
+	if feed.Title != "JSON Feed" || len(feed.Items) != 1 || feed.Items[0].Link != "https://example.com/1" {
+		t.Errorf("Unexpected JSON Feed %+v", feed)
+	}
+
+	simple := `{"title": "Simple", "link": "https://example.com", "items": [
+		{"title": "A", "link": "https://example.com/a", "summary": "Short", "author": "Ann", "categories": ["x"]}]}`
+	feed, err = parseScriptOutput(simple)
+	if err != nil {
+		t.Fatalf("parseScriptOutput() failed for simple JSON: %v", err)
+	}
+	if feed.Title != "Simple" || len(feed.Items) != 1 {
+		t.Fatalf("Unexpected simple feed %+v", feed)
+	}
+	if item := feed.Items[0]; item.Description != "Short" || item.Author == nil || item.Author.Name != "Ann" || item.GUID != "https://example.com/a" {
+		t.Errorf("Unexpected simple item %+v", item)
+	}
+
+	if _, err := parseScriptOutput(`[{"content": "no title or link"}]`); err == nil {
+		t.Error("parseScriptOutput() should return error for items without title and link")
+	}
+}
+
+func TestAttemptTimeout_ScriptFeed(t *testing.T) {
+	db := setupDBForFeedTests(t)
+	f := NewFetcher(db)
+	tm := f.GetTaskManager()
+
+	regular := models.Feed{ID: 1, URL: "https://example.com/feed"}
+	if got := tm.attemptTimeout(regular, 10*time.Second); got != 10*time.Second {
+		t.Errorf("Expected the attempt timeout of a regular feed, got %v", got)
+	}
+
+	id, _ := db.AddFeed(&models.Feed{Title: "Script", URL: "script://slow", ScriptPath: "slow.py"})
+	db.SaveScriptFeedSettings(&models.ScriptFeedSettings{FeedID: id, TimeoutSeconds: 300})
+	script := models.Feed{ID: id, ScriptPath: "slow.py"}
+	if got := tm.attemptTimeout(script, 60*time.Second); got != 300*time.Second+scriptSaveMargin {
+		t.Errorf("Expected the script timeout plus the save margin, got %v", got)
+	}
+	if got := tm.attemptTimeout(script, time.Hour); got != time.Hour {
+		t.Errorf("Expected a longer attempt timeout to be kept, got %v", got)
+	}
+}
+
+func TestFetchScriptFeed_SavesStateAfterArticles(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	tempDir := t.TempDir()
+
+	// Return the item after the one in the state and move the state forward
+	scriptContent := `n=$(( $(cat) + 1 ))
+echo "$n" > "$MRRSS_STATE_FILE"
+echo "[{\"id\": \"$n\", \"title\": \"Item $n\", \"url\": \"https://example.com/$n\"}]"
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "cursor.sh"), []byte(scriptContent), 0755); err != nil {
+		t.Fatalf("Failed to create test script: %v", err)
+	}
+
+	db := setupDBForFeedTests(t)
+	f := NewFetcher(db)
+	f.scriptExecutor = NewScriptExecutor(tempDir)
+	id, _ := db.AddFeed(&models.Feed{Title: "Cursor", URL: "script://cursor.sh", ScriptPath: "cursor.sh"})
+	db.SaveScriptFeedSettings(&models.ScriptFeedSettings{FeedID: id})
+	db.SaveScriptState(id, []byte("0"))
+	feed, _ := db.GetFeedByID(id)
+
+	// A failing item transform leaves the state as it was
+	db.SaveItemTransform(&models.ItemTransform{FeedID: id, Enabled: true, Script: `function transform(item) { throw new Error("broken"); }`})
+	if err := f.fetchFeedWithContext(context.Background(), *feed); err == nil {
+		t.Fatal("Expected the item transform to fail")
+	}
+	if state, _ := db.GetScriptState(id); strings.TrimSpace(string(state)) != "0" {
+		t.Errorf("Expected the state to be kept after a failed refresh, got %q", state)
+	}
+
+	db.SaveItemTransform(&models.ItemTransform{FeedID: id})
+	if err := f.fetchFeedWithContext(context.Background(), *feed); err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	if state, _ := db.GetScriptState(id); strings.TrimSpace(string(state)) != "1" {
+		t.Errorf("Expected the new state after the articles are saved, got %q", state)
+	}
+	articles, _ := db.GetArticles("", id, "", true, 10, 0)
+	if len(articles) != 1 || articles[0].Title != "Item 1" {
+		t.Errorf("Expected the item of the run to be saved, got %+v", articles)
+	}
+
+	// Runs without a state, such as content fetches, do not save one
+	if _, err := f.ParseFeedWithFeed(context.Background(), feed, true); err != nil {
+		t.Fatalf("ParseFeedWithFeed error: %v", err)
+	}
+	if state, _ := db.GetScriptState(id); strings.TrimSpace(string(state)) != "1" {
+		t.Errorf("Expected a content fetch to keep the state, got %q", state)
+	}
+}
@@ -0,0 +1,149 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// scriptSaveMargin is the time a refresh of a script feed has to save the items after the
+// script has run
+const scriptSaveMargin = 30 * time.Second
+
+// scriptRunOptions converts the stored settings of a script feed to run options
+func scriptRunOptions(settings *models.ScriptFeedSettings) ScriptRunOptions {
+	opts := ScriptRunOptions{}
+	if settings == nil {
+		return opts
+	}
+	opts.Args = settings.Args
+	opts.Env = make(map[string]string, len(settings.Env)+len(settings.Secrets))
+	for name, value := range settings.Env {
+		opts.Env[name] = value
+	}
+	for name, value := range settings.Secrets {
+		opts.Env[name] = value
+	}
+	opts.Timeout = time.Duration(settings.TimeoutSeconds) * time.Second
+	return opts
+}
+
+// scriptState holds the state of a script feed. It is passed by pointer through the fetch
+// path of a refresh: the script runs with the stored state, and the state it writes replaces
+// it. The refresh saves it once the articles are stored, so that a failure after the run does
+// not move the script past items that were never saved.
+type scriptState struct {
+	Data    []byte
+	Changed bool
+}
+
+// loadScriptState returns the stored state of a script feed, or nil if the feed is not a
+// subscribed script feed or its state cannot be read
+func (f *Fetcher) loadScriptState(feed *models.Feed) *scriptState {
+	if feed.ID == 0 || feed.ScriptPath == "" {
+		return nil
+	}
+	data, err := f.db.GetScriptState(feed.ID)
+	if err != nil {
+		log.Printf("Error loading script state for feed %d: %v", feed.ID, err)
+		return nil
+	}
+	return &scriptState{Data: data}
+}
+
+// saveScriptState persists the state written by the script of a successful refresh
+func (f *Fetcher) saveScriptState(feedID int64, state *scriptState) {
+	if state == nil || !state.Changed {
+		return
+	}
+	if err := f.db.SaveScriptState(feedID, state.Data); err != nil {
+		log.Printf("Error saving script state for feed %d: %v", feedID, err)
+	}
+}
+
+// runFeedScript runs the script of a subscribed feed with its settings and records the run.
+// Only refreshes pass a state (see scriptState); without one the script does a full run, so
+// that content fetches and transform tests see all its items.
+func (f *Fetcher) runFeedScript(ctx context.Context, feed *models.Feed, state *scriptState) (*gofeed.Feed, error) {
+	if f.scriptExecutor == nil {
+		return nil, &ScriptError{Message: "Script executor not initialized"}
+	}
+	if feed.ID == 0 {
+		return f.scriptExecutor.ExecuteScript(ctx, feed.ScriptPath)
+	}
+
+	settings, err := f.db.GetScriptFeedSettings(feed.ID)
+	if err != nil {
+		return nil, err
+	}
+	opts := scriptRunOptions(settings)
+	if state != nil {
+		opts.State = state.Data
+	}
+
+	startedAt := time.Now()
+	result, err := f.scriptExecutor.RunScript(ctx, feed.ScriptPath, opts)
+
+	run := &models.ScriptRun{
+		FeedID:     feed.ID,
+		StartedAt:  startedAt,
+		DurationMs: result.Duration.Milliseconds(),
+		ExitCode:   result.ExitCode,
+		Stderr:     result.Stderr,
+	}
+	if err != nil {
+		run.Error = err.Error()
+	} else {
+		run.ItemCount = len(result.Feed.Items)
+	}
+	if recordErr := f.db.AddScriptRun(run); recordErr != nil {
+		utils.DebugLog("runFeedScript: Failed to record run of %s: %v", feed.ScriptPath, recordErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if state != nil && result.StateChanged {
+		state.Data = result.State
+		state.Changed = true
+	}
+	return result.Feed, nil
+}
+
+// scriptFetchTimeout returns the time a refresh of a script feed needs: the run timeout of
+// its script plus time to save the items. It returns 0 for other feeds.
+func (f *Fetcher) scriptFetchTimeout(feed *models.Feed) time.Duration {
+	if feed.ScriptPath == "" || feed.ID == 0 {
+		return 0
+	}
+	settings, err := f.db.GetScriptFeedSettings(feed.ID)
+	if err != nil {
+		return 0
+	}
+	return scriptTimeout(time.Duration(settings.TimeoutSeconds)*time.Second) + scriptSaveMargin
+}
+
+// ValidateScriptFeedSettings checks the environment variable names and timeout of script settings
+func ValidateScriptFeedSettings(settings *models.ScriptFeedSettings) error {
+	if settings.TimeoutSeconds < 0 || time.Duration(settings.TimeoutSeconds)*time.Second > maxScriptTimeout {
+		return fmt.Errorf("timeout must be between 1 and %d seconds", int(maxScriptTimeout.Seconds()))
+	}
+	for _, vars := range []map[string]string{settings.Env, settings.Secrets} {
+		for name := range vars {
+			if name == "" || strings.ContainsAny(name, "= \t\n") {
+				return fmt.Errorf("invalid environment variable name %q", name)
+			}
+			if name == "MRRSS_STATE_FILE" {
+				return fmt.Errorf("environment variable %s is set by MrRSS", name)
+			}
+		}
+	}
+	return nil
+}
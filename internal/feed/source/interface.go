@@ -3,6 +3,8 @@ package source
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"time"
 
 	"github.com/mmcdole/gofeed"
@@ -12,12 +14,19 @@ import (
 type Type string
 
 const (
-	TypeRSS    Type = "rss"    // Standard RSS/Atom feed via HTTP
-	TypeScript Type = "script" // Custom script that outputs RSS
-	TypeXPath  Type = "xpath"  // HTML scraping with XPath selectors
-	TypeEmail  Type = "email"  // Email/IMAP as feed source
+	TypeRSS      Type = "rss"      // Standard RSS/Atom feed via HTTP
+	TypeScript   Type = "script"   // Custom script that outputs RSS
+	TypeXPath    Type = "xpath"    // HTML scraping with XPath selectors
+	TypeEmail    Type = "email"    // Email/IMAP as feed source
+	TypeYouTube  Type = "youtube"  // YouTube channel or playlist with video transcripts
+	TypeMastodon Type = "mastodon" // Mastodon-compatible account or hashtag via the public API
+	TypeJSON     Type = "json"     // JSON API with JSONPath item mapping
 )
 
+// ErrNotModified is returned by sources that support conditional requests
+// when the server reports that the feed has not changed since the last fetch.
+var ErrNotModified = errors.New("feed not modified")
+
 // Source is the interface that all feed sources must implement.
 type Source interface {
 	// Type returns the source type identifier.
@@ -39,6 +48,11 @@ type Config struct {
 	Timeout    time.Duration // Request timeout
 	SourceType Type          // Explicit source type (optional, auto-detected if empty)
 
+	// HTTP cache validators for conditional requests.
+	// Sources that support them update these fields in place after a successful fetch.
+	ETag         string // Value of the last ETag response header
+	LastModified string // Value of the last Last-Modified response header
+
 	// Script source fields
 	ScriptPath string // Path to the script file (relative to scripts dir)
 
@@ -51,6 +65,21 @@ type Config struct {
 	XPathItemContentSelector string // CSS selector for item content
 	XPathItemDateSelector    string // CSS selector for item date
 
+	// JSON source fields (JSONPath expressions, relative to the item except JSONItemPath)
+	JSONItemPath     string // Path of the items in the response
+	JSONTitlePath    string // Path of the item title
+	JSONLinkPath     string // Path of the item link
+	JSONContentPath  string // Path of the item content
+	JSONDatePath     string // Path of the item date
+	JSONDateFormat   string // Go layout of the item date (optional, common formats and Unix timestamps are detected)
+	JSONAuthorPath   string // Path of the item author
+	JSONImagePath    string // Path of the item thumbnail
+	JSONCategoryPath string // Path of the item categories
+	JSONUIDPath      string // Path of the item unique ID
+	JSONNextPagePath string // Path of the next page URL or cursor in the response (optional)
+	JSONPageParam    string // Query parameter of the page number or cursor (optional)
+	JSONMaxPages     int    // Maximum number of pages to fetch (default: 1)
+
 	// Email source fields
 	EmailIMAPServer string // IMAP server address
 	EmailIMAPPort   int    // IMAP server port (default: 993)
@@ -60,9 +89,10 @@ type Config struct {
 	EmailLastUID    int    // Last processed email UID
 
 	// Network configuration
-	ProxyURL  string            // HTTP proxy URL
-	Headers   map[string]string // Custom HTTP headers
-	UserAgent string            // Custom User-Agent string
+	ProxyURL   string            // HTTP proxy URL
+	Headers    map[string]string // Custom HTTP headers
+	UserAgent  string            // Custom User-Agent string
+	HTTPClient *http.Client      // Client to use instead of the source's default (YouTube, Mastodon, JSON, XPath sources)
 
 	// Authentication
 	BasicAuthUser     string // HTTP Basic Auth username
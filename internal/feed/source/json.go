@@ -0,0 +1,367 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"MrRSS/internal/utils/textutil"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	jsonMaxPages     = 10               // Upper limit for the number of pages per fetch
+	jsonMaxResponse  = 16 * 1024 * 1024 // Maximum size of a response body
+	jsonTitleLength  = 80               // Length of titles taken from the content
+	jsonMilliseconds = 1e12             // Unix timestamps above this are in milliseconds
+)
+
+// jsonDateFormats are tried in order when no date format is configured
+var jsonDateFormats = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+}
+
+// JSONSource fetches items from JSON APIs and maps their fields with JSONPath expressions.
+type JSONSource struct {
+	client *http.Client
+}
+
+// jsonMapping holds the compiled paths of a JSON source configuration.
+// Optional paths that are not configured are nil.
+type jsonMapping struct {
+	items, title, link, content, date, author, image, categories, uid, next jsonPath
+}
+
+// NewJSONSource creates a new JSON source.
+// Uses a default HTTP client with 30s timeout.
+func NewJSONSource() *JSONSource {
+	return &JSONSource{
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Type returns the source type identifier.
+func (s *JSONSource) Type() Type {
+	return TypeJSON
+}
+
+// Validate checks if the configuration is valid for JSON source.
+func (s *JSONSource) Validate(config *Config) error {
+	if config == nil {
+		return errors.New("config is nil")
+	}
+	if config.URL == "" {
+		return errors.New("URL is required for JSON source")
+	}
+	if u, err := url.Parse(config.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.New("URL must be an HTTP or HTTPS URL")
+	}
+	if strings.TrimSpace(config.JSONItemPath) == "" {
+		return errors.New("item path is required for JSON source")
+	}
+	if config.JSONMaxPages < 0 || config.JSONMaxPages > jsonMaxPages {
+		return fmt.Errorf("maximum pages must be between 1 and %d", jsonMaxPages)
+	}
+	_, err := compileJSONMapping(config)
+	return err
+}
+
+// SetHTTPClient updates the HTTP client used for requests.
+func (s *JSONSource) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// Fetch requests the API, following pagination, and maps the selected items to feed items.
+func (s *JSONSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed, error) {
+	if err := s.Validate(config); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	mapping, _ := compileJSONMapping(config)
+	client := config.HTTPClient
+	if client == nil {
+		client = s.client
+	}
+
+	feed := &gofeed.Feed{Link: config.URL}
+	if u, err := url.Parse(config.URL); err == nil {
+		feed.Title = u.Hostname()
+	}
+
+	pages := max(config.JSONMaxPages, 1)
+	pageURL := config.URL
+	for page := 1; page <= pages; page++ {
+		doc, err := s.get(ctx, client, pageURL, config)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			// Keep the items of the pages fetched so far
+			break
+		}
+
+		items := selectJSONItems(mapping.items, doc)
+		for _, v := range items {
+			feed.Items = append(feed.Items, mapping.item(v, pageURL, config))
+		}
+		if len(items) == 0 || page == pages {
+			break
+		}
+		if pageURL = nextJSONPage(mapping, doc, config, pageURL, page); pageURL == "" {
+			break
+		}
+	}
+	return feed, nil
+}
+
+// get requests one page and decodes the JSON response
+func (s *JSONSource) get(ctx context.Context, client *http.Client, pageURL string, config *Config) (any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if config.UserAgent != "" {
+		req.Header.Set("User-Agent", config.UserAgent)
+	} else {
+		req.Header.Set("User-Agent", "MrRSS/1.0")
+	}
+	for name, value := range config.Headers {
+		req.Header.Set(name, value)
+	}
+	if config.BasicAuthUser != "" {
+		req.SetBasicAuth(config.BasicAuthUser, config.BasicAuthPassword)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, pageURL)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, jsonMaxResponse))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var doc any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+	return doc, nil
+}
+
+// compileJSONMapping compiles the paths of a configuration
+func compileJSONMapping(config *Config) (*jsonMapping, error) {
+	mapping := &jsonMapping{}
+	paths := []struct {
+		name string
+		expr string
+		dest *jsonPath
+	}{
+		{"item", config.JSONItemPath, &mapping.items},
+		{"title", config.JSONTitlePath, &mapping.title},
+		{"link", config.JSONLinkPath, &mapping.link},
+		{"content", config.JSONContentPath, &mapping.content},
+		{"date", config.JSONDatePath, &mapping.date},
+		{"author", config.JSONAuthorPath, &mapping.author},
+		{"thumbnail", config.JSONImagePath, &mapping.image},
+		{"categories", config.JSONCategoryPath, &mapping.categories},
+		{"unique ID", config.JSONUIDPath, &mapping.uid},
+		{"next page", config.JSONNextPagePath, &mapping.next},
+	}
+	for _, p := range paths {
+		if strings.TrimSpace(p.expr) == "" {
+			continue
+		}
+		path, err := compileJSONPath(p.expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s path: %w", p.name, err)
+		}
+		*p.dest = path
+	}
+	return mapping, nil
+}
+
+// selectJSONItems returns the items selected by the item path. A path that
+// selects a single array, such as $.data, selects the elements of the array.
+func selectJSONItems(path jsonPath, doc any) []any {
+	values := path.find(doc)
+	if len(values) == 1 {
+		if array, ok := values[0].([]any); ok {
+			return array
+		}
+	}
+	return values
+}
+
+// text returns the first value a path selects in an item as text
+func (m *jsonMapping) text(path jsonPath, item any) string {
+	if path == nil {
+		return ""
+	}
+	return jsonString(path.first(item))
+}
+
+// item maps one selected item to a feed item
+func (m *jsonMapping) item(v any, pageURL string, config *Config) *gofeed.Item {
+	item := &gofeed.Item{
+		Title:   m.text(m.title, v),
+		Content: m.text(m.content, v),
+		Link:    resolveJSONLink(pageURL, m.text(m.link, v)),
+		GUID:    m.text(m.uid, v),
+	}
+	if item.Title == "" {
+		item.Title = jsonTitle(item.Content)
+	}
+	if item.Link == "" {
+		id := item.GUID
+		if id == "" {
+			hash := fnv.New64a()
+			hash.Write([]byte(jsonString(v)))
+			id = strconv.FormatUint(hash.Sum64(), 16)
+		}
+		item.Link = config.URL + "#json-" + url.PathEscape(id)
+	}
+	if item.GUID == "" {
+		item.GUID = item.Link
+	}
+	if author := m.text(m.author, v); author != "" {
+		item.Author = &gofeed.Person{Name: author}
+	}
+	if image := resolveJSONLink(pageURL, m.text(m.image, v)); image != "" {
+		item.Image = &gofeed.Image{URL: image}
+	}
+	if m.date != nil {
+		if published, ok := parseJSONDate(m.date.first(v), config.JSONDateFormat); ok {
+			item.PublishedParsed = &published
+		}
+	}
+	if m.categories != nil {
+		for _, category := range m.categories.find(v) {
+			if values, ok := category.([]any); ok {
+				for _, value := range values {
+					if text := jsonString(value); text != "" {
+						item.Categories = append(item.Categories, text)
+					}
+				}
+			} else if text := jsonString(category); text != "" {
+				item.Categories = append(item.Categories, text)
+			}
+		}
+	}
+	return item
+}
+
+// nextJSONPage returns the URL of the page after the given one, or an empty
+// string when there are no more pages
+func nextJSONPage(m *jsonMapping, doc any, config *Config, pageURL string, page int) string {
+	if m.next != nil {
+		next := jsonString(m.next.first(doc))
+		if next == "" || next == "null" {
+			return ""
+		}
+		if config.JSONPageParam != "" {
+			// The value is a cursor
+			return setJSONQueryParam(config.URL, config.JSONPageParam, next)
+		}
+		return resolveJSONLink(pageURL, next)
+	}
+	if config.JSONPageParam == "" {
+		return ""
+	}
+
+	// Page numbers count up from the one in the URL, or from 1
+	first := 1
+	if u, err := url.Parse(config.URL); err == nil {
+		if n, err := strconv.Atoi(u.Query().Get(config.JSONPageParam)); err == nil {
+			first = n
+		}
+	}
+	return setJSONQueryParam(config.URL, config.JSONPageParam, strconv.Itoa(first+page))
+}
+
+// setJSONQueryParam returns the URL with a query parameter set to a value
+func setJSONQueryParam(rawURL, name, value string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	query := u.Query()
+	query.Set(name, value)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// resolveJSONLink resolves a link relative to the URL of the response it was found in
+func resolveJSONLink(base, link string) string {
+	if link == "" {
+		return ""
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return link
+	}
+	ref, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return baseURL.ResolveReference(ref).String()
+}
+
+// parseJSONDate parses a date value. Numbers are Unix timestamps in seconds or
+// milliseconds; strings are parsed with the layout, or with common formats.
+func parseJSONDate(v any, layout string) (time.Time, bool) {
+	text := jsonString(v)
+	if text == "" {
+		return time.Time{}, false
+	}
+	if layout != "" {
+		t, err := time.Parse(layout, text)
+		return t, err == nil
+	}
+	if n, err := strconv.ParseFloat(text, 64); err == nil {
+		if n > jsonMilliseconds {
+			return time.UnixMilli(int64(n)).UTC(), true
+		}
+		return time.Unix(int64(n), 0).UTC(), true
+	}
+	for _, format := range jsonDateFormats {
+		if t, err := time.Parse(format, text); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// jsonTitle returns the beginning of the content as title for items without one
+func jsonTitle(content string) string {
+	title := strings.Join(strings.Fields(textutil.StripHTML(content)), " ")
+	if utf8.RuneCountInString(title) <= jsonTitleLength {
+		return title
+	}
+	runes := []rune(title)
+	return strings.TrimSpace(string(runes[:jsonTitleLength])) + "…"
+}
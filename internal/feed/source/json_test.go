@@ -0,0 +1,180 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONPath(t *testing.T) {
+	const doc = `{"data": {"posts": [
+		{"id": 1, "title": "First", "tags": ["a", "b"], "meta": {"author": {"name": "Ann"}}},
+		{"id": 2, "title": "Second", "tags": [], "meta": {"author": {"name": "Bob"}}}
+	]}, "next": null, "my key": "spaced"}`
+	decoder := json.NewDecoder(strings.NewReader(doc))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"$.data.posts[*].title", []string{"First", "Second"}},
+		{"data.posts[0].id", []string{"1"}},
+		{"$.data.posts[-1].title", []string{"Second"}},
+		{"$['my key']", []string{"spaced"}},
+		{`$.data["posts"][1].meta.author.name`, []string{"Bob"}},
+		{"$..name", []string{"Ann", "Bob"}},
+		{"$.data.posts[0].tags", []string{`["a","b"]`}},
+		{"$.data.posts[5].title", nil},
+		{"$.missing.title", nil},
+	}
+	for _, tt := range tests {
+		path, err := compileJSONPath(tt.expr)
+		if err != nil {
+			t.Errorf("compileJSONPath(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		var got []string
+		for _, value := range path.find(v) {
+			got = append(got, jsonString(value))
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "$.", "$.data[", "$.data[a]", "$..", "$...title"} {
+		if _, err := compileJSONPath(expr); err == nil {
+			t.Errorf("Expected an error for %q", expr)
+		}
+	}
+}
+
+// newJSONTestServer serves two pages of posts linked by a cursor and requires an API key header
+func newJSONTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Write([]byte(`{"results": [
+				{"id": "a1", "headline": "First", "url": "/posts/a1", "body": "<p>One</p>",
+				 "published": 1714557600, "by": {"name": "Ann"}, "image": "https://cdn.example.com/a1.png", "tags": ["go", "json"]},
+				{"id": "a2", "headline": "", "body": "<p>Second post without a title or link</p>",
+				 "published": "2024-05-02T10:00:00Z", "tags": "news"}
+			], "paging": {"next": "c2"}}`))
+		case "c2":
+			w.Write([]byte(`{"results": [
+				{"id": "a3", "headline": "Third", "url": "https://example.com/a3", "published": 1714730400000}
+			], "paging": {"next": null}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestJSONSource_Fetch(t *testing.T) {
+	server := newJSONTestServer(t)
+	s := NewJSONSource()
+	config := &Config{
+		URL:              server.URL + "/api/posts",
+		Headers:          map[string]string{"X-Api-Key": "secret"},
+		JSONItemPath:     "$.results",
+		JSONTitlePath:    "headline",
+		JSONLinkPath:     "url",
+		JSONContentPath:  "body",
+		JSONDatePath:     "published",
+		JSONAuthorPath:   "by.name",
+		JSONImagePath:    "image",
+		JSONCategoryPath: "tags",
+		JSONUIDPath:      "id",
+		JSONNextPagePath: "$.paging.next",
+		JSONPageParam:    "cursor",
+		JSONMaxPages:     3,
+	}
+
+	feed, err := s.Fetch(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(feed.Items) != 3 {
+		t.Fatalf("Expected 3 items from 2 pages, got %d", len(feed.Items))
+	}
+	first, second, third := feed.Items[0], feed.Items[1], feed.Items[2]
+
+	if first.Title != "First" || first.Link != server.URL+"/posts/a1" || first.GUID != "a1" || first.Content != "<p>One</p>" {
+		t.Errorf("Unexpected first item %+v", first)
+	}
+	if first.Author == nil || first.Author.Name != "Ann" || first.Image == nil || first.Image.URL != "https://cdn.example.com/a1.png" {
+		t.Errorf("Expected the author and thumbnail, got %+v %+v", first.Author, first.Image)
+	}
+	if first.PublishedParsed == nil || first.PublishedParsed.Unix() != 1714557600 || fmt.Sprint(first.Categories) != "[go json]" {
+		t.Errorf("Expected the date and categories, got %v %v", first.PublishedParsed, first.Categories)
+	}
+
+	if second.Title != "Second post without a title or link" || second.Link != config.URL+"#json-a2" {
+		t.Errorf("Expected a title from the content and a generated link, got %q %s", second.Title, second.Link)
+	}
+	if second.PublishedParsed == nil || second.PublishedParsed.Day() != 2 || fmt.Sprint(second.Categories) != "[news]" {
+		t.Errorf("Expected the date and category, got %v %v", second.PublishedParsed, second.Categories)
+	}
+
+	if third.PublishedParsed == nil || third.PublishedParsed.Unix() != 1714730400 {
+		t.Errorf("Expected a millisecond timestamp, got %v", third.PublishedParsed)
+	}
+
+	// Without the header the API refuses the request
+	config.Headers = nil
+	if _, err := s.Fetch(context.Background(), config); err == nil {
+		t.Error("Expected an error for a refused request")
+	}
+}
+
+func TestJSONSource_Validate(t *testing.T) {
+	s := NewJSONSource()
+	tests := []struct {
+		name   string
+		config *Config
+		ok     bool
+	}{
+		{"valid", &Config{URL: "https://example.com/api", JSONItemPath: "$.items[*]", JSONTitlePath: "title"}, true},
+		{"missing URL", &Config{JSONItemPath: "$.items"}, false},
+		{"not HTTP", &Config{URL: "file:///etc/passwd", JSONItemPath: "$.items"}, false},
+		{"missing item path", &Config{URL: "https://example.com/api"}, false},
+		{"invalid field path", &Config{URL: "https://example.com/api", JSONItemPath: "$.items", JSONLinkPath: "$.a[x]"}, false},
+		{"too many pages", &Config{URL: "https://example.com/api", JSONItemPath: "$.items", JSONMaxPages: 50}, false},
+	}
+	for _, tt := range tests {
+		if err := s.Validate(tt.config); (err == nil) != tt.ok {
+			t.Errorf("%s: Validate = %v", tt.name, err)
+		}
+	}
+}
+
+func TestNextJSONPage(t *testing.T) {
+	mapping := &jsonMapping{}
+	config := &Config{URL: "https://example.com/api?page=3&size=10", JSONPageParam: "page"}
+	if got := nextJSONPage(mapping, nil, config, config.URL, 1); got != "https://example.com/api?page=4&size=10" {
+		t.Errorf("Unexpected next page %s", got)
+	}
+
+	mapping.next, _ = compileJSONPath("$.links.next")
+	doc := map[string]any{"links": map[string]any{"next": "/api?page=2"}}
+	config = &Config{URL: "https://example.com/api"}
+	if got := nextJSONPage(mapping, doc, config, config.URL, 1); got != "https://example.com/api?page=2" {
+		t.Errorf("Unexpected next page %s", got)
+	}
+}
@@ -0,0 +1,198 @@
+package source
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep is one step of a compiled JSONPath expression
+type jsonPathStep struct {
+	name      string // Object member to select, empty for index and wildcard steps
+	index     int    // Array element to select; negative indexes count from the end
+	isIndex   bool
+	wildcard  bool // All members of an object or elements of an array
+	recursive bool // Select at any depth below the current value (..)
+}
+
+// jsonPath is a compiled JSONPath expression. The supported subset covers what
+// item mappings need: $ or @ as root, .name, ['name'], [n], [*], .* and ..name.
+// A path without a root is relative to the current value, so "title" equals "$.title".
+type jsonPath []jsonPathStep
+
+// compileJSONPath parses a JSONPath expression
+func compileJSONPath(expr string) (jsonPath, error) {
+	s := strings.TrimSpace(expr)
+	if s == "" {
+		return nil, fmt.Errorf("empty JSONPath")
+	}
+	if s[0] == '$' || s[0] == '@' {
+		s = s[1:]
+	} else if s[0] != '.' && s[0] != '[' {
+		s = "." + s
+	}
+
+	var path jsonPath
+	recursive := false
+	for len(s) > 0 {
+		var step jsonPathStep
+		switch {
+		case strings.HasPrefix(s, ".."):
+			if recursive {
+				return nil, fmt.Errorf("invalid JSONPath %q: unexpected ..", expr)
+			}
+			// The next step selects at any depth
+			recursive = true
+			s = s[2:]
+			if !strings.HasPrefix(s, "[") {
+				s = "." + s
+			}
+			continue
+		case s[0] == '.':
+			name, rest := readJSONPathName(s[1:])
+			if name == "" {
+				return nil, fmt.Errorf("invalid JSONPath %q: missing name after .", expr)
+			}
+			if name == "*" {
+				step.wildcard = true
+			} else {
+				step.name = name
+			}
+			s = rest
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: unclosed [", expr)
+			}
+			selector := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+			switch {
+			case selector == "*":
+				step.wildcard = true
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				step.name = selector[1 : len(selector)-1]
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil {
+					return nil, fmt.Errorf("invalid JSONPath %q: unsupported selector [%s]", expr, selector)
+				}
+				step.index, step.isIndex = index, true
+			}
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q: unexpected %q", expr, s[:1])
+		}
+		step.recursive, recursive = recursive, false
+		path = append(path, step)
+	}
+	if recursive {
+		return nil, fmt.Errorf("invalid JSONPath %q: missing name after ..", expr)
+	}
+	return path, nil
+}
+
+// readJSONPathName reads a member name up to the next . or [
+func readJSONPathName(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// find returns all values the path selects in a decoded JSON document
+func (p jsonPath) find(doc any) []any {
+	values := []any{doc}
+	for _, step := range p {
+		var next []any
+		for _, v := range values {
+			if step.recursive {
+				for _, d := range jsonDescendants(v) {
+					next = append(next, step.apply(d)...)
+				}
+			} else {
+				next = append(next, step.apply(v)...)
+			}
+		}
+		values = next
+		if len(values) == 0 {
+			break
+		}
+	}
+	return values
+}
+
+// first returns the first value the path selects, or nil
+func (p jsonPath) first(doc any) any {
+	if values := p.find(doc); len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
+
+// apply selects the children of a value matched by the step
+func (step jsonPathStep) apply(v any) []any {
+	switch node := v.(type) {
+	case map[string]any:
+		if step.wildcard {
+			values := make([]any, 0, len(node))
+			for _, child := range node {
+				values = append(values, child)
+			}
+			return values
+		}
+		if child, ok := node[step.name]; ok && !step.isIndex {
+			return []any{child}
+		}
+	case []any:
+		if step.wildcard {
+			return node
+		}
+		if step.isIndex {
+			i := step.index
+			if i < 0 {
+				i += len(node)
+			}
+			if i >= 0 && i < len(node) {
+				return []any{node[i]}
+			}
+		}
+	}
+	return nil
+}
+
+// jsonDescendants returns a value and all values nested in it
+func jsonDescendants(v any) []any {
+	values := []any{v}
+	switch node := v.(type) {
+	case map[string]any:
+		for _, child := range node {
+			values = append(values, jsonDescendants(child)...)
+		}
+	case []any:
+		for _, child := range node {
+			values = append(values, jsonDescendants(child)...)
+		}
+	}
+	return values
+}
+
+// jsonString converts a selected value to text. Objects and arrays are returned as JSON.
+func jsonString(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(value)
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
@@ -12,10 +12,13 @@ import (
 
 // Manager manages different feed sources and provides a unified interface.
 type Manager struct {
-	rss    *RSSSource
-	script *ScriptSource
-	xpath  *XPathSource
-	email  *EmailSource
+	rss      *RSSSource
+	script   *ScriptSource
+	xpath    *XPathSource
+	email    *EmailSource
+	youtube  *YouTubeSource
+	mastodon *MastodonSource
+	json     *JSONSource
 
 	mu sync.RWMutex
 }
@@ -23,10 +26,13 @@ type Manager struct {
 // NewManager creates a new source manager.
 func NewManager(scriptsDir string) *Manager {
 	return &Manager{
-		rss:    NewRSSSource(),
-		script: NewScriptSource(scriptsDir),
-		xpath:  NewXPathSource(),
-		email:  NewEmailSource(),
+		rss:      NewRSSSource(),
+		script:   NewScriptSource(scriptsDir),
+		xpath:    NewXPathSource(),
+		email:    NewEmailSource(),
+		youtube:  NewYouTubeSource(),
+		mastodon: NewMastodonSource(),
+		json:     NewJSONSource(),
 	}
 }
 
@@ -44,6 +50,12 @@ func (m *Manager) GetSource(sourceType Type) (Source, error) {
 		return m.xpath, nil
 	case TypeEmail:
 		return m.email, nil
+	case TypeYouTube:
+		return m.youtube, nil
+	case TypeMastodon:
+		return m.mastodon, nil
+	case TypeJSON:
+		return m.json, nil
 	default:
 		return nil, fmt.Errorf("unknown source type: %s", sourceType)
 	}
@@ -81,18 +93,27 @@ func (m *Manager) detectSourceType(config *Config) Type {
 	if config.XPathItemSelector != "" {
 		return TypeXPath
 	}
+	if config.JSONItemPath != "" {
+		return TypeJSON
+	}
+	if IsYouTubeFeedURL(config.URL) {
+		return TypeYouTube
+	}
 
 	// Default to RSS
 	return TypeRSS
 }
 
-// SetHTTPClient sets the HTTP client for RSS and XPath sources.
+// SetHTTPClient sets the HTTP client for RSS, XPath, YouTube, Mastodon and JSON sources.
 func (m *Manager) SetHTTPClient(client *http.Client) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.rss.SetHTTPClient(client)
 	m.xpath.SetHTTPClient(client)
+	m.youtube.SetHTTPClient(client)
+	m.mastodon.SetHTTPClient(client)
+	m.json.SetHTTPClient(client)
 }
 
 // Validate validates the configuration for the appropriate source.
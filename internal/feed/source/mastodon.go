@@ -0,0 +1,474 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"MrRSS/internal/utils/textutil"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	mastodonPageSize      = 40      // Statuses requested per fetch, the API maximum
+	mastodonMaxResponse   = 8 << 20 // Largest API response read
+	mastodonMaxAncestors  = 3       // Posts quoted above a reply
+	mastodonMaxCached     = 1000    // Thread contexts kept in memory
+	mastodonTitleLength   = 80      // Characters of a post used as its title
+	mastodonDefaultScheme = "https"
+)
+
+var (
+	mastodonHandlePattern  = regexp.MustCompile(`^@([\w.-]+)@([\w-]+(?:\.[\w-]+)+)$`)
+	mastodonHashtagPattern = regexp.MustCompile(`^#([\p{L}\p{N}_]+)@([\w-]+(?:\.[\w-]+)+)$`)
+	mastodonAccountPath    = regexp.MustCompile(`^/@([\w.-]+(?:@[\w-]+(?:\.[\w-]+)+)?)/?$`)
+	mastodonTagPath        = regexp.MustCompile(`^/tags/([\p{L}\p{N}_]+)/?$`)
+	mastodonSpanPattern    = regexp.MustCompile(`</?span[^>]*>`)
+)
+
+// MastodonTarget is an account or hashtag on a Mastodon-compatible server.
+type MastodonTarget struct {
+	BaseURL string // Scheme and host of the server whose API is used
+	Account string // Account name, with the domain of its home server if that is another server
+	Hashtag string // Hashtag without the leading #
+}
+
+// FeedURL returns the URL a subscription to the target is stored under: the profile
+// page of the account or the page of the hashtag.
+func (t MastodonTarget) FeedURL() string {
+	if t.Hashtag != "" {
+		return t.BaseURL + "/tags/" + url.PathEscape(t.Hashtag)
+	}
+	return t.BaseURL + "/@" + t.Account
+}
+
+// Host returns the host name of the target's server.
+func (t MastodonTarget) Host() string {
+	if u, err := url.Parse(t.BaseURL); err == nil {
+		return u.Host
+	}
+	return t.BaseURL
+}
+
+// MastodonSource turns the public timeline of an account or a hashtag on a
+// Mastodon-compatible server into a feed, using the server's public API.
+// Boosts, content warnings, media attachments and the posts a reply answers are
+// included in the item content.
+type MastodonSource struct {
+	client *http.Client
+
+	mu      sync.Mutex
+	threads map[string]string // Quoted ancestors by status ID
+}
+
+// mastodonAccount is an account in API responses
+type mastodonAccount struct {
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	Acct        string `json:"acct"`
+	DisplayName string `json:"display_name"`
+	Note        string `json:"note"`
+	URL         string `json:"url"`
+	Avatar      string `json:"avatar"`
+}
+
+// mastodonStatus is a post or boost in API responses
+type mastodonStatus struct {
+	ID               string               `json:"id"`
+	URI              string               `json:"uri"`
+	URL              string               `json:"url"`
+	CreatedAt        time.Time            `json:"created_at"`
+	EditedAt         *time.Time           `json:"edited_at"`
+	InReplyToID      string               `json:"in_reply_to_id"`
+	Sensitive        bool                 `json:"sensitive"`
+	SpoilerText      string               `json:"spoiler_text"`
+	Content          string               `json:"content"`
+	Account          mastodonAccount      `json:"account"`
+	Reblog           *mastodonStatus      `json:"reblog"`
+	MediaAttachments []mastodonAttachment `json:"media_attachments"`
+	Tags             []struct {
+		Name string `json:"name"`
+	} `json:"tags"`
+}
+
+// mastodonAttachment is a media attachment of a status
+type mastodonAttachment struct {
+	Type        string `json:"type"` // image, gifv, video, audio or unknown
+	URL         string `json:"url"`
+	PreviewURL  string `json:"preview_url"`
+	RemoteURL   string `json:"remote_url"`
+	Description string `json:"description"`
+}
+
+// mastodonContext holds the thread a status belongs to
+type mastodonContext struct {
+	Ancestors []mastodonStatus `json:"ancestors"`
+}
+
+// NewMastodonSource creates a new Mastodon source.
+// Uses a default HTTP client with 30s timeout.
+func NewMastodonSource() *MastodonSource {
+	return &MastodonSource{
+		client:  &http.Client{Timeout: 30 * time.Second},
+		threads: make(map[string]string),
+	}
+}
+
+// Type returns the source type identifier.
+func (s *MastodonSource) Type() Type {
+	return TypeMastodon
+}
+
+// Validate checks if the configuration is valid for Mastodon source.
+func (s *MastodonSource) Validate(config *Config) error {
+	if config == nil {
+		return errors.New("config is nil")
+	}
+	if _, ok := ParseMastodonURL(config.URL); !ok {
+		return errors.New("URL must be the profile page of an account or the page of a hashtag")
+	}
+	return nil
+}
+
+// SetHTTPClient updates the HTTP client used for requests.
+func (s *MastodonSource) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// Fetch retrieves the latest public posts of the account or hashtag.
+func (s *MastodonSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed, error) {
+	if err := s.Validate(config); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	target, _ := ParseMastodonURL(config.URL)
+	client := s.clientFor(config)
+
+	var feed *gofeed.Feed
+	var statuses []mastodonStatus
+	if target.Hashtag != "" {
+		feed = &gofeed.Feed{
+			Title:       "#" + target.Hashtag,
+			Link:        target.FeedURL(),
+			Description: fmt.Sprintf("Public posts tagged #%s on %s", target.Hashtag, target.Host()),
+		}
+		timelineURL := fmt.Sprintf("%s/api/v1/timelines/tag/%s?limit=%d", target.BaseURL, url.PathEscape(target.Hashtag), mastodonPageSize)
+		if err := mastodonGet(ctx, client, timelineURL, &statuses); err != nil {
+			return nil, fmt.Errorf("failed to fetch posts tagged #%s: %w", target.Hashtag, err)
+		}
+	} else {
+		account, err := lookUpMastodonAccount(ctx, client, target)
+		if err != nil {
+			return nil, err
+		}
+		feed = &gofeed.Feed{
+			Title:       mastodonDisplayName(account),
+			Link:        account.URL,
+			Description: textutil.StripHTML(account.Note),
+		}
+		if account.Avatar != "" {
+			feed.Image = &gofeed.Image{URL: account.Avatar}
+		}
+		statusesURL := fmt.Sprintf("%s/api/v1/accounts/%s/statuses?limit=%d", target.BaseURL, url.PathEscape(account.ID), mastodonPageSize)
+		if err := mastodonGet(ctx, client, statusesURL, &statuses); err != nil {
+			return nil, fmt.Errorf("failed to fetch posts of @%s: %w", target.Account, err)
+		}
+	}
+
+	for i := range statuses {
+		feed.Items = append(feed.Items, s.statusItem(ctx, client, target, &statuses[i]))
+	}
+	return feed, nil
+}
+
+// lookUpMastodonAccount returns the account of a target that is not a hashtag
+func lookUpMastodonAccount(ctx context.Context, client *http.Client, target MastodonTarget) (*mastodonAccount, error) {
+	var account mastodonAccount
+	lookupURL := target.BaseURL + "/api/v1/accounts/lookup?acct=" + url.QueryEscape(target.Account)
+	if err := mastodonGet(ctx, client, lookupURL, &account); err != nil {
+		return nil, fmt.Errorf("failed to look up @%s: %w", target.Account, err)
+	}
+	if account.ID == "" {
+		return nil, fmt.Errorf("account @%s not found", target.Account)
+	}
+	return &account, nil
+}
+
+// statusItem converts a status to a feed item. A boost shows the boosted post.
+func (s *MastodonSource) statusItem(ctx context.Context, client *http.Client, target MastodonTarget, status *mastodonStatus) *gofeed.Item {
+	post := status
+	if status.Reblog != nil {
+		post = status.Reblog
+	}
+
+	var content strings.Builder
+	if status.Reblog != nil {
+		fmt.Fprintf(&content, "<p>Boosted a post by <a href=\"%s\">%s</a></p>\n",
+			html.EscapeString(post.Account.URL), html.EscapeString(mastodonDisplayName(&post.Account)))
+	}
+	if post.InReplyToID != "" {
+		content.WriteString(s.threadContext(ctx, client, target, post.ID))
+	}
+
+	body := post.Content
+	media := mastodonMediaHTML(post.MediaAttachments)
+	if post.Sensitive && post.SpoilerText == "" && media != "" {
+		media = "<details><summary>Sensitive media</summary>" + media + "</details>"
+	}
+	body += media
+	if post.SpoilerText != "" {
+		body = "<details><summary>" + html.EscapeString(post.SpoilerText) + "</summary>" + body + "</details>"
+	}
+	content.WriteString(body)
+
+	title := mastodonTitle(post)
+	if status.Reblog != nil {
+		title = "Boosted @" + post.Account.Acct + ": " + title
+	}
+
+	link := post.URL
+	if link == "" {
+		link = post.URI
+	}
+	published := status.CreatedAt
+	item := &gofeed.Item{
+		Title:           title,
+		Link:            link,
+		GUID:            status.URI,
+		Content:         content.String(),
+		Published:       published.Format(time.RFC3339),
+		PublishedParsed: &published,
+		Author:          &gofeed.Person{Name: mastodonDisplayName(&post.Account)},
+	}
+	if post.EditedAt != nil {
+		item.Updated = post.EditedAt.Format(time.RFC3339)
+		item.UpdatedParsed = post.EditedAt
+	}
+	for _, tag := range post.Tags {
+		item.Categories = append(item.Categories, tag.Name)
+	}
+
+	for _, attachment := range post.MediaAttachments {
+		mediaURL := mastodonAttachmentURL(attachment)
+		if mediaURL == "" {
+			continue
+		}
+		item.Enclosures = append(item.Enclosures, &gofeed.Enclosure{URL: mediaURL, Type: mastodonMIMEType(attachment.Type, mediaURL)})
+		if item.Image == nil {
+			switch attachment.Type {
+			case "image":
+				item.Image = &gofeed.Image{URL: mediaURL, Title: attachment.Description}
+			case "video", "gifv":
+				if attachment.PreviewURL != "" {
+					item.Image = &gofeed.Image{URL: attachment.PreviewURL, Title: attachment.Description}
+				}
+			}
+		}
+	}
+	return item
+}
+
+// threadContext returns the posts a reply answers as quotes, oldest first.
+// Contexts are cached, as the posts above a reply do not change; failed lookups
+// leave the reply without context until the next fetch.
+func (s *MastodonSource) threadContext(ctx context.Context, client *http.Client, target MastodonTarget, statusID string) string {
+	s.mu.Lock()
+	thread, ok := s.threads[statusID]
+	s.mu.Unlock()
+	if ok {
+		return thread
+	}
+
+	var result mastodonContext
+	contextURL := target.BaseURL + "/api/v1/statuses/" + url.PathEscape(statusID) + "/context"
+	if err := mastodonGet(ctx, client, contextURL, &result); err != nil {
+		return ""
+	}
+
+	ancestors := result.Ancestors
+	if len(ancestors) > mastodonMaxAncestors {
+		ancestors = ancestors[len(ancestors)-mastodonMaxAncestors:]
+	}
+	var b strings.Builder
+	for _, ancestor := range ancestors {
+		link := ancestor.URL
+		if link == "" {
+			link = ancestor.URI
+		}
+		fmt.Fprintf(&b, "<blockquote><p><a href=\"%s\">%s</a></p>%s%s</blockquote>\n",
+			html.EscapeString(link), html.EscapeString(mastodonDisplayName(&ancestor.Account)),
+			ancestor.Content, mastodonMediaHTML(ancestor.MediaAttachments))
+	}
+	thread = b.String()
+
+	s.mu.Lock()
+	if len(s.threads) >= mastodonMaxCached {
+		s.threads = make(map[string]string)
+	}
+	s.threads[statusID] = thread
+	s.mu.Unlock()
+	return thread
+}
+
+// clientFor returns the client configured for a fetch, or the source's default client
+func (s *MastodonSource) clientFor(config *Config) *http.Client {
+	if config.HTTPClient != nil {
+		return config.HTTPClient
+	}
+	return s.client
+}
+
+// ParseMastodonHandle parses an account handle (@user@example.social) or a hashtag
+// on a server (#tag@example.social).
+func ParseMastodonHandle(input string) (MastodonTarget, bool) {
+	input = strings.TrimSpace(input)
+	if m := mastodonHandlePattern.FindStringSubmatch(input); m != nil {
+		return MastodonTarget{BaseURL: mastodonDefaultScheme + "://" + strings.ToLower(m[2]), Account: m[1]}, true
+	}
+	if m := mastodonHashtagPattern.FindStringSubmatch(input); m != nil {
+		return MastodonTarget{BaseURL: mastodonDefaultScheme + "://" + strings.ToLower(m[2]), Hashtag: m[1]}, true
+	}
+	return MastodonTarget{}, false
+}
+
+// ParseMastodonURL parses the URL of a profile page (https://example.social/@user,
+// also for accounts of other servers as /@user@other.social) or of a hashtag page
+// (https://example.social/tags/tag). Other sites use the same paths, so whether the
+// URL belongs to a Mastodon-compatible server is only known after asking its API.
+func ParseMastodonURL(rawURL string) (MastodonTarget, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" {
+		return MastodonTarget{}, false
+	}
+	baseURL := u.Scheme + "://" + strings.ToLower(u.Host)
+	if m := mastodonAccountPath.FindStringSubmatch(u.Path); m != nil {
+		return MastodonTarget{BaseURL: baseURL, Account: m[1]}, true
+	}
+	if m := mastodonTagPath.FindStringSubmatch(u.Path); m != nil {
+		return MastodonTarget{BaseURL: baseURL, Hashtag: m[1]}, true
+	}
+	return MastodonTarget{}, false
+}
+
+// ParseMastodonTarget parses a handle or the URL of a profile or hashtag page.
+func ParseMastodonTarget(input string) (MastodonTarget, bool) {
+	if target, ok := ParseMastodonHandle(input); ok {
+		return target, true
+	}
+	return ParseMastodonURL(input)
+}
+
+// mastodonDisplayName returns "Name (@acct)", or the handle alone for accounts without a name
+func mastodonDisplayName(account *mastodonAccount) string {
+	name := strings.TrimSpace(account.DisplayName)
+	if name == "" {
+		return "@" + account.Acct
+	}
+	return name + " (@" + account.Acct + ")"
+}
+
+// mastodonTitle returns the content warning of a post, or the start of its text.
+// Posts with only media get no title, which leaves it to the article processor.
+func mastodonTitle(post *mastodonStatus) string {
+	if post.SpoilerText != "" {
+		return "CW: " + post.SpoilerText
+	}
+	// Mentions and links split their text into spans, which must not become spaces
+	text := textutil.StripHTML(mastodonSpanPattern.ReplaceAllString(post.Content, ""))
+	runes := []rune(text)
+	if len(runes) > mastodonTitleLength {
+		return strings.TrimSpace(string(runes[:mastodonTitleLength])) + "…"
+	}
+	return text
+}
+
+// mastodonMediaHTML renders media attachments below the post text. Videos and audio
+// are linked, as the article view plays them from the item's enclosures.
+func mastodonMediaHTML(attachments []mastodonAttachment) string {
+	var b strings.Builder
+	for _, attachment := range attachments {
+		mediaURL := html.EscapeString(mastodonAttachmentURL(attachment))
+		if mediaURL == "" {
+			continue
+		}
+		description := html.EscapeString(attachment.Description)
+		switch attachment.Type {
+		case "image":
+			fmt.Fprintf(&b, "<p><img src=\"%s\" alt=\"%s\"></p>", mediaURL, description)
+		case "video", "gifv":
+			// Linked through its preview image, or by name without one
+			if attachment.PreviewURL != "" {
+				fmt.Fprintf(&b, "<p><a href=\"%s\"><img src=\"%s\" alt=\"%s\"></a></p>", mediaURL, html.EscapeString(attachment.PreviewURL), description)
+				continue
+			}
+			fallthrough
+		default:
+			label := description
+			if label == "" {
+				label = mediaURL
+			}
+			fmt.Fprintf(&b, "<p><a href=\"%s\">%s</a></p>", mediaURL, label)
+		}
+	}
+	return b.String()
+}
+
+// mastodonAttachmentURL returns where an attachment can be loaded from. Servers that
+// did not copy a remote attachment only have its original URL.
+func mastodonAttachmentURL(attachment mastodonAttachment) string {
+	if attachment.URL != "" {
+		return attachment.URL
+	}
+	return attachment.RemoteURL
+}
+
+// mastodonMIMEType returns the MIME type of an attachment for its enclosure
+func mastodonMIMEType(kind, mediaURL string) string {
+	if u, err := url.Parse(mediaURL); err == nil {
+		if mimeType := mime.TypeByExtension(strings.ToLower(path.Ext(u.Path))); mimeType != "" {
+			return mimeType
+		}
+	}
+	switch kind {
+	case "image":
+		return "image/jpeg"
+	case "video", "gifv":
+		return "video/mp4"
+	case "audio":
+		return "audio/mpeg"
+	}
+	return "application/octet-stream"
+}
+
+// mastodonGet calls an API endpoint and decodes its JSON response into v
+func mastodonGet(ctx context.Context, client *http.Client, rawURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, mastodonMaxResponse)).Decode(v); err != nil {
+		return fmt.Errorf("invalid API response: %w", err)
+	}
+	return nil
+}
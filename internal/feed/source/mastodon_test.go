@@ -0,0 +1,174 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParseMastodonTarget(t *testing.T) {
+	tests := []struct {
+		input string
+		want  MastodonTarget
+		ok    bool
+	}{
+		{"@alice@Example.Social", MastodonTarget{BaseURL: "https://example.social", Account: "alice"}, true},
+		{" #golang@example.social ", MastodonTarget{BaseURL: "https://example.social", Hashtag: "golang"}, true},
+		{"#日本語@example.social", MastodonTarget{BaseURL: "https://example.social", Hashtag: "日本語"}, true},
+		{"https://example.social/@alice", MastodonTarget{BaseURL: "https://example.social", Account: "alice"}, true},
+		{"https://example.social/@bob@other.social/", MastodonTarget{BaseURL: "https://example.social", Account: "bob@other.social"}, true},
+		{"https://example.social/tags/golang", MastodonTarget{BaseURL: "https://example.social", Hashtag: "golang"}, true},
+		{"https://example.social/@alice/112233", MastodonTarget{}, false},
+		{"https://example.social/@alice?page=2", MastodonTarget{}, false},
+		{"alice@example.social", MastodonTarget{}, false},
+		{"@alice", MastodonTarget{}, false},
+		{"https://example.com/feed.xml", MastodonTarget{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseMastodonTarget(tt.input)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseMastodonTarget(%q) = %+v, %v, want %+v, %v", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	target, _ := ParseMastodonHandle("@alice@example.social")
+	if got := target.FeedURL(); got != "https://example.social/@alice" {
+		t.Errorf("Unexpected feed URL %s", got)
+	}
+	target, _ = ParseMastodonHandle("#golang@example.social")
+	if got := target.FeedURL(); got != "https://example.social/tags/golang" {
+		t.Errorf("Unexpected feed URL %s", got)
+	}
+}
+
+// newMastodonTestServer serves the API of a server with one account posting a reply,
+// a boost and a post with a content warning and media
+func newMastodonTestServer(t *testing.T, contextRequests *int32) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account := fmt.Sprintf(`{"id": "1", "username": "alice", "acct": "alice", "display_name": "Alice",
+			"note": "<p>Hello &amp; welcome</p>", "url": "%[1]s/@alice", "avatar": "%[1]s/avatar.png"}`, server.URL)
+		switch r.URL.Path {
+		case "/api/v1/accounts/lookup":
+			if r.URL.Query().Get("acct") != "alice" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(account))
+		case "/api/v1/accounts/1/statuses":
+			fmt.Fprintf(w, `[
+				{"id": "30", "uri": "%[1]s/users/alice/statuses/30", "url": "%[1]s/@alice/30",
+				 "created_at": "2024-05-03T10:00:00.000Z", "in_reply_to_id": "20",
+				 "content": "<p>I agree</p>", "account": %[2]s, "media_attachments": [], "tags": []},
+				{"id": "25", "uri": "%[1]s/users/alice/statuses/25/activity", "url": null,
+				 "created_at": "2024-05-02T10:00:00.000Z", "content": "", "account": %[2]s,
+				 "reblog": {"id": "24", "uri": "https://other.social/users/bob/statuses/24", "url": "https://other.social/@bob/24",
+				  "created_at": "2024-05-01T10:00:00.000Z", "content": "<p>Hi <span class=\"h-card\"><a href=\"https://example.social/@alice\">@<span>alice</span></a></span></p>",
+				  "account": {"id": "2", "username": "bob", "acct": "bob@other.social", "display_name": "", "url": "https://other.social/@bob"},
+				  "media_attachments": [], "tags": []},
+				 "media_attachments": [], "tags": []},
+				{"id": "22", "uri": "%[1]s/users/alice/statuses/22", "url": "%[1]s/@alice/22",
+				 "created_at": "2024-05-01T09:00:00.000Z", "edited_at": "2024-05-01T09:30:00.000Z",
+				 "sensitive": true, "spoiler_text": "Food", "content": "<p>Lunch</p>", "account": %[2]s,
+				 "media_attachments": [
+				  {"type": "image", "url": "%[1]s/media/lunch.png", "preview_url": "%[1]s/media/lunch_small.png", "description": "A sandwich"},
+				  {"type": "video", "url": "%[1]s/media/clip.mp4", "preview_url": "%[1]s/media/clip.png", "description": ""}],
+				 "tags": [{"name": "food"}]}
+			]`, server.URL, account)
+		case "/api/v1/statuses/30/context":
+			atomic.AddInt32(contextRequests, 1)
+			fmt.Fprintf(w, `{"ancestors": [
+				{"id": "10", "uri": "%[1]s/users/carol/statuses/10", "url": "%[1]s/@carol/10", "content": "<p>Start of the thread</p>",
+				 "account": {"acct": "carol", "display_name": "Carol"}},
+				{"id": "20", "uri": "%[1]s/users/carol/statuses/20", "url": "%[1]s/@carol/20", "content": "<p>Tabs are better</p>",
+				 "account": {"acct": "carol", "display_name": "Carol"}}
+			], "descendants": []}`, server.URL)
+		case "/api/v1/timelines/tag/golang":
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMastodonSource_FetchAccount(t *testing.T) {
+	var contextRequests int32
+	server := newMastodonTestServer(t, &contextRequests)
+	s := NewMastodonSource()
+	config := &Config{URL: server.URL + "/@alice"}
+
+	feed, err := s.Fetch(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if feed.Title != "Alice (@alice)" || feed.Description != "Hello & welcome" || feed.Image == nil {
+		t.Errorf("Unexpected feed %+v", feed)
+	}
+	if len(feed.Items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(feed.Items))
+	}
+	reply, boost, media := feed.Items[0], feed.Items[1], feed.Items[2]
+
+	if reply.Title != "I agree" || reply.Link != server.URL+"/@alice/30" {
+		t.Errorf("Unexpected reply %q %s", reply.Title, reply.Link)
+	}
+	if !strings.Contains(reply.Content, "<blockquote><p><a href=\""+server.URL+"/@carol/20\">Carol (@carol)</a></p><p>Tabs are better</p></blockquote>") ||
+		!strings.HasSuffix(reply.Content, "<p>I agree</p>") {
+		t.Errorf("Expected the reply to quote the thread, got %s", reply.Content)
+	}
+
+	if boost.Title != "Boosted @bob@other.social: Hi @alice" {
+		t.Errorf("Unexpected boost title %q", boost.Title)
+	}
+	if boost.Link != "https://other.social/@bob/24" || boost.GUID != server.URL+"/users/alice/statuses/25/activity" {
+		t.Errorf("Expected the boost to link to the boosted post, got %s (%s)", boost.Link, boost.GUID)
+	}
+	if boost.Author == nil || boost.Author.Name != "@bob@other.social" || boost.PublishedParsed.Day() != 2 {
+		t.Errorf("Expected the boosted author and the boost time, got %+v %v", boost.Author, boost.PublishedParsed)
+	}
+
+	if media.Title != "CW: Food" || !strings.HasPrefix(media.Content, "<details><summary>Food</summary><p>Lunch</p>") {
+		t.Errorf("Expected the post to be hidden behind its content warning, got %q %s", media.Title, media.Content)
+	}
+	if media.Image == nil || media.Image.URL != server.URL+"/media/lunch.png" {
+		t.Errorf("Expected the first image as item image, got %+v", media.Image)
+	}
+	if len(media.Enclosures) != 2 || media.Enclosures[0].Type != "image/png" || media.Enclosures[1].Type != "video/mp4" {
+		t.Errorf("Unexpected enclosures %+v", media.Enclosures)
+	}
+	if media.UpdatedParsed == nil || len(media.Categories) != 1 || media.Categories[0] != "food" {
+		t.Errorf("Expected the edit time and tags, got %v %v", media.UpdatedParsed, media.Categories)
+	}
+
+	// Thread contexts are not fetched again
+	if _, err := s.Fetch(context.Background(), config); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&contextRequests); got != 1 {
+		t.Errorf("Expected the thread context to be fetched once, got %d", got)
+	}
+}
+
+func TestMastodonSource_FetchErrors(t *testing.T) {
+	var contextRequests int32
+	server := newMastodonTestServer(t, &contextRequests)
+	s := NewMastodonSource()
+
+	feed, err := s.Fetch(context.Background(), &Config{URL: server.URL + "/tags/golang"})
+	if err != nil || feed.Title != "#golang" || len(feed.Items) != 0 {
+		t.Errorf("Unexpected hashtag feed %+v (%v)", feed, err)
+	}
+	if _, err := s.Fetch(context.Background(), &Config{URL: server.URL + "/@nobody"}); err == nil {
+		t.Error("Expected an error for an unknown account")
+	}
+	if _, err := s.Fetch(context.Background(), &Config{URL: server.URL + "/feed.xml"}); err == nil {
+		t.Error("Expected an error for a URL that is not an account or hashtag")
+	}
+}
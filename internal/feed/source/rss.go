@@ -47,17 +47,49 @@ func (s *RSSSource) Validate(config *Config) error {
 }
 
 // Fetch retrieves and parses the RSS/Atom feed from the URL.
+// If the config carries ETag or Last-Modified validators, the request is sent
+// conditionally and ErrNotModified is returned when the server answers 304.
+// On success the validators in config are replaced with the response values.
 func (s *RSSSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed, error) {
 	if err := s.Validate(config); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Use context-aware parsing if available
-	feed, err := s.parser.ParseURLWithContext(config.URL, ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", config.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if config.UserAgent != "" {
+		req.Header.Set("User-Agent", config.UserAgent)
+	}
+	if config.ETag != "" {
+		req.Header.Set("If-None-Match", config.ETag)
+	}
+	if config.LastModified != "" {
+		req.Header.Set("If-Modified-Since", config.LastModified)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed from %s: %w", config.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from %s: %d", config.URL, resp.StatusCode)
+	}
+
+	feed, err := s.parser.Parse(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse feed from %s: %w", config.URL, err)
 	}
 
+	config.ETag = resp.Header.Get("ETag")
+	config.LastModified = resp.Header.Get("Last-Modified")
+
 	return feed, nil
 }
 
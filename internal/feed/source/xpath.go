@@ -13,6 +13,9 @@ import (
 	"github.com/mmcdole/gofeed"
 )
 
+// xpathUserAgent is the default user agent of page requests
+const xpathUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
 // XPathSource fetches content from web pages using XPath/CSS selectors.
 type XPathSource struct {
 	client *http.Client
@@ -46,6 +49,24 @@ func (x *XPathSource) Validate(config *Config) error {
 	return nil
 }
 
+// setXPathRequestHeaders sets the user agent, a browser one by default, as pages often
+// block unknown clients, and the custom headers and Basic auth of config, if given
+func setXPathRequestHeaders(req *http.Request, userAgent string, config *Config) {
+	if userAgent == "" {
+		userAgent = xpathUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if config == nil {
+		return
+	}
+	for name, value := range config.Headers {
+		req.Header.Set(name, value)
+	}
+	if config.BasicAuthUser != "" {
+		req.SetBasicAuth(config.BasicAuthUser, config.BasicAuthPassword)
+	}
+}
+
 // SetHTTPClient allows setting a custom HTTP client.
 func (x *XPathSource) SetHTTPClient(client *http.Client) {
 	if client != nil {
@@ -64,16 +85,14 @@ func (x *XPathSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed,
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-
-	// Set default user agent
-	if config.UserAgent != "" {
-		req.Header.Set("User-Agent", config.UserAgent)
-	} else {
-		req.Header.Set("User-Agent", "MrRSS/1.0")
-	}
+	setXPathRequestHeaders(req, config.UserAgent, config)
 
 	// Execute request
-	resp, err := x.client.Do(req)
+	client := x.client
+	if config.HTTPClient != nil {
+		client = config.HTTPClient
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch page: %w", err)
 	}
@@ -210,11 +229,7 @@ func (x *XPathSource) FetchRaw(ctx context.Context, url string, userAgent string
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if userAgent != "" {
-		req.Header.Set("User-Agent", userAgent)
-	} else {
-		req.Header.Set("User-Agent", "MrRSS/1.0")
-	}
+	setXPathRequestHeaders(req, userAgent, nil)
 
 	resp, err := x.client.Do(req)
 	if err != nil {
@@ -0,0 +1,551 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	youTubeUserAgent      = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	youTubeMaxPageSize    = 8 << 20 // Watch and channel pages are large, but not larger than this
+	youTubeMaxCached      = 1000    // Videos whose transcript and Shorts state are kept in memory
+	youTubeWorkers        = 4       // Videos looked up in parallel
+	youTubeParagraphSpan  = 30.0    // Seconds of captions after which a paragraph may end at a sentence
+	youTubeParagraphLimit = 60.0    // Seconds of captions after which a paragraph always ends
+)
+
+// youTubeBaseURL is the origin of all YouTube requests; tests point it to a local server.
+var youTubeBaseURL = "https://www.youtube.com"
+
+var (
+	youTubeChannelIDPattern = regexp.MustCompile(`^UC[\w-]{22}$`)
+	youTubeCanonicalPattern = regexp.MustCompile(`<link rel="canonical" href="[^"]*/channel/(UC[\w-]{22})"`)
+	youTubeChannelIDInPage  = regexp.MustCompile(`"(?:externalId|channelId)":"(UC[\w-]{22})"`)
+	youTubeVideoIDInLink    = regexp.MustCompile(`(?:[?&]v=|youtu\.be/|/shorts/)([\w-]{11})`)
+	youTubeTagPattern       = regexp.MustCompile(`<[^>]+>`)
+	youTubeSentenceEnd      = regexp.MustCompile(`[.!?。！？]["')\]]?$`)
+)
+
+// YouTubeSource fetches the Atom feed of a YouTube channel or playlist and fills in
+// the video transcripts as item content. Videos published as Shorts get a /shorts/ link.
+type YouTubeSource struct {
+	client *http.Client
+
+	mu     sync.Mutex
+	videos map[string]youTubeVideo // Looked-up videos by ID
+}
+
+// youTubeVideo holds what was looked up for a video, which does not change once published
+type youTubeVideo struct {
+	transcript string // Transcript HTML, empty if the video has no captions
+	isShort    bool
+}
+
+// youTubeCaptionTrack is a caption track listed in the player response of a watch page
+type youTubeCaptionTrack struct {
+	BaseURL      string `json:"baseUrl"`
+	LanguageCode string `json:"languageCode"`
+	Kind         string `json:"kind"` // "asr" for automatic captions
+}
+
+// youTubeTimedText is a caption track document, in the legacy format (text elements
+// with start times in seconds) or in format 3 (p elements with times in milliseconds).
+type youTubeTimedText struct {
+	Texts []struct {
+		Start float64 `xml:"start,attr"`
+		Body  string  `xml:",innerxml"`
+	} `xml:"text"`
+	Paragraphs []struct {
+		Time int64  `xml:"t,attr"`
+		Body string `xml:",innerxml"`
+	} `xml:"body>p"`
+}
+
+// captionSegment is a line of captions
+type captionSegment struct {
+	start float64
+	text  string
+}
+
+// NewYouTubeSource creates a new YouTube source.
+// Uses a default HTTP client with 30s timeout.
+func NewYouTubeSource() *YouTubeSource {
+	return &YouTubeSource{
+		client: &http.Client{Timeout: 30 * time.Second},
+		videos: make(map[string]youTubeVideo),
+	}
+}
+
+// Type returns the source type identifier.
+func (s *YouTubeSource) Type() Type {
+	return TypeYouTube
+}
+
+// Validate checks if the configuration is valid for YouTube source.
+func (s *YouTubeSource) Validate(config *Config) error {
+	if config == nil {
+		return errors.New("config is nil")
+	}
+	if !IsYouTubeFeedURL(config.URL) {
+		return errors.New("URL must be a YouTube channel or playlist feed")
+	}
+	return nil
+}
+
+// SetHTTPClient updates the HTTP client used for requests.
+func (s *YouTubeSource) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// Fetch retrieves the Atom feed and fills in the transcripts of its videos.
+// Videos without captions keep their description as content.
+func (s *YouTubeSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed, error) {
+	if err := s.Validate(config); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	client := s.clientFor(config)
+
+	body, err := youTubeGet(ctx, client, config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed from %s: %w", config.URL, err)
+	}
+	feed, err := gofeed.NewParser().ParseString(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse feed from %s: %w", config.URL, err)
+	}
+
+	s.lookUpVideos(ctx, client, feed.Items)
+	return feed, nil
+}
+
+// ResolveFeedURL returns the Atom feed URL for a YouTube channel, handle or playlist.
+// It accepts channel, handle, custom, user and playlist URLs, watch URLs (resolved to
+// their channel), bare channel IDs and @handles. Feed URLs are returned unchanged.
+func (s *YouTubeSource) ResolveFeedURL(ctx context.Context, client *http.Client, input string) (string, error) {
+	if client == nil {
+		client = s.client
+	}
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", errors.New("YouTube URL is empty")
+	}
+
+	if youTubeChannelIDPattern.MatchString(input) {
+		return youTubeChannelFeedURL(input), nil
+	}
+	if strings.HasPrefix(input, "@") && !strings.Contains(input[1:], "@") {
+		return s.resolveChannelPage(ctx, client, youTubeBaseURL+"/"+input)
+	}
+	if !strings.Contains(input, "://") {
+		input = "https://" + input
+	}
+	if !IsYouTubeURL(input) {
+		return "", fmt.Errorf("not a YouTube URL: %s", input)
+	}
+	if IsYouTubeFeedURL(input) {
+		return input, nil
+	}
+
+	u, err := url.Parse(input)
+	if err != nil {
+		return "", fmt.Errorf("invalid YouTube URL: %w", err)
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	// Playlists, including a watch URL opened from a playlist
+	if list := u.Query().Get("list"); list != "" {
+		return youTubeBaseURL + "/feeds/videos.xml?playlist_id=" + url.QueryEscape(list), nil
+	}
+
+	switch {
+	case len(segments) >= 2 && segments[0] == "channel" && youTubeChannelIDPattern.MatchString(segments[1]):
+		return youTubeChannelFeedURL(segments[1]), nil
+	case len(segments) >= 2 && segments[0] == "user":
+		return youTubeBaseURL + "/feeds/videos.xml?user=" + url.QueryEscape(segments[1]), nil
+	case len(segments) >= 1 && segments[0] != "":
+		// Handles, custom URLs and videos: the page names its channel
+		return s.resolveChannelPage(ctx, client, youTubeBaseURL+u.EscapedPath()+youTubeVideoQuery(u))
+	}
+	return "", fmt.Errorf("not a YouTube channel or playlist URL: %s", input)
+}
+
+// resolveChannelPage finds the channel ID in a channel or video page
+func (s *YouTubeSource) resolveChannelPage(ctx context.Context, client *http.Client, pageURL string) (string, error) {
+	page, err := youTubeGet(ctx, client, pageURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to load YouTube page: %w", err)
+	}
+	for _, pattern := range []*regexp.Regexp{youTubeCanonicalPattern, youTubeChannelIDInPage} {
+		if m := pattern.FindStringSubmatch(page); m != nil {
+			return youTubeChannelFeedURL(m[1]), nil
+		}
+	}
+	return "", fmt.Errorf("no YouTube channel found at %s", pageURL)
+}
+
+// lookUpVideos fills in the transcripts and Shorts links of the items, looking up
+// videos that were not seen before in parallel
+func (s *YouTubeSource) lookUpVideos(ctx context.Context, client *http.Client, items []*gofeed.Item) {
+	ids := make([]string, len(items))
+	var missing []int
+	s.mu.Lock()
+	for i, item := range items {
+		ids[i] = youTubeItemVideoID(item)
+		if ids[i] == "" {
+			continue
+		}
+		if _, ok := s.videos[ids[i]]; !ok {
+			missing = append(missing, i)
+		}
+	}
+	s.mu.Unlock()
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < youTubeWorkers && w < len(missing); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				video, err := s.lookUpVideo(ctx, client, ids[i], items[i].Link)
+				if err != nil {
+					// Try again on the next refresh
+					continue
+				}
+				s.mu.Lock()
+				if len(s.videos) >= youTubeMaxCached {
+					s.videos = make(map[string]youTubeVideo)
+				}
+				s.videos[ids[i]] = video
+				s.mu.Unlock()
+			}
+		}()
+	}
+	for _, i := range missing {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, item := range items {
+		video, ok := s.videos[ids[i]]
+		if !ok {
+			continue
+		}
+		if video.isShort {
+			item.Link = youTubeBaseURL + "/shorts/" + ids[i]
+		}
+		if video.transcript != "" {
+			setYouTubeContent(item, video.transcript)
+		}
+	}
+}
+
+// lookUpVideo fetches the transcript of a video and checks whether it is a Short
+func (s *YouTubeSource) lookUpVideo(ctx context.Context, client *http.Client, videoID, link string) (youTubeVideo, error) {
+	var video youTubeVideo
+	if strings.Contains(link, "/shorts/") {
+		video.isShort = true
+	} else {
+		isShort, err := youTubeIsShort(ctx, client, videoID)
+		if err != nil {
+			return video, err
+		}
+		video.isShort = isShort
+	}
+
+	transcript, err := youTubeTranscript(ctx, client, videoID)
+	if err != nil {
+		return video, err
+	}
+	video.transcript = transcript
+	return video, nil
+}
+
+// clientFor returns the client configured for a fetch, or the source's default client
+func (s *YouTubeSource) clientFor(config *Config) *http.Client {
+	if config.HTTPClient != nil {
+		return config.HTTPClient
+	}
+	return s.client
+}
+
+// IsYouTubeURL reports whether a URL points to youtube.com.
+func IsYouTubeURL(rawURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	switch strings.ToLower(u.Hostname()) {
+	case "youtube.com", "www.youtube.com", "m.youtube.com":
+		return true
+	}
+	return false
+}
+
+// IsYouTubeFeedURL reports whether a URL is the Atom feed of a YouTube channel or playlist.
+func IsYouTubeFeedURL(rawURL string) bool {
+	if !IsYouTubeURL(rawURL) && !strings.HasPrefix(rawURL, youTubeBaseURL+"/") {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	return err == nil && u.Path == "/feeds/videos.xml"
+}
+
+func youTubeChannelFeedURL(channelID string) string {
+	return youTubeBaseURL + "/feeds/videos.xml?channel_id=" + channelID
+}
+
+// youTubeVideoQuery keeps the video ID of watch URLs, which is needed to find their channel
+func youTubeVideoQuery(u *url.URL) string {
+	if v := u.Query().Get("v"); v != "" {
+		return "?v=" + url.QueryEscape(v)
+	}
+	return ""
+}
+
+// youTubeItemVideoID returns the video ID of a feed item, from yt:videoId or its link
+func youTubeItemVideoID(item *gofeed.Item) string {
+	if ytExt, ok := item.Extensions["yt"]; ok {
+		if ids, ok := ytExt["videoId"]; ok && len(ids) > 0 && ids[0].Value != "" {
+			return ids[0].Value
+		}
+	}
+	if m := youTubeVideoIDInLink.FindStringSubmatch(item.Link); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// youTubeIsShort checks whether a video is a Short: the Shorts URL of other videos
+// redirects to their watch page
+func youTubeIsShort(ctx context.Context, client *http.Client, videoID string) (bool, error) {
+	noRedirect := *client
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	req, err := youTubeRequest(ctx, http.MethodHead, youTubeBaseURL+"/shorts/"+videoID)
+	if err != nil {
+		return false, err
+	}
+	resp, err := noRedirect.Do(req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return true, nil
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+}
+
+// youTubeTranscript returns the transcript of a video as HTML paragraphs, or an empty
+// string if the video has no captions
+func youTubeTranscript(ctx context.Context, client *http.Client, videoID string) (string, error) {
+	page, err := youTubeGet(ctx, client, youTubeBaseURL+"/watch?v="+url.QueryEscape(videoID))
+	if err != nil {
+		return "", err
+	}
+	track := chooseCaptionTrack(parseCaptionTracks(page))
+	if track == nil {
+		return "", nil
+	}
+
+	// Drop any requested format to get the documented XML formats
+	trackURL, err := url.Parse(track.BaseURL)
+	if err != nil {
+		return "", err
+	}
+	query := trackURL.Query()
+	query.Del("fmt")
+	trackURL.RawQuery = query.Encode()
+	if !trackURL.IsAbs() {
+		trackURL, _ = url.Parse(youTubeBaseURL + trackURL.String())
+	}
+
+	doc, err := youTubeGet(ctx, client, trackURL.String())
+	if err != nil {
+		return "", err
+	}
+	return formatTranscript(parseTimedText(doc)), nil
+}
+
+// parseCaptionTracks extracts the caption tracks from the player response in a watch page
+func parseCaptionTracks(page string) []youTubeCaptionTrack {
+	const marker = `"captionTracks":`
+	idx := strings.Index(page, marker)
+	if idx < 0 {
+		return nil
+	}
+	var tracks []youTubeCaptionTrack
+	if err := json.NewDecoder(strings.NewReader(page[idx+len(marker):])).Decode(&tracks); err != nil {
+		return nil
+	}
+	return tracks
+}
+
+// chooseCaptionTrack prefers captions written by the uploader over automatic ones.
+// YouTube lists the video's own language first.
+func chooseCaptionTrack(tracks []youTubeCaptionTrack) *youTubeCaptionTrack {
+	var automatic *youTubeCaptionTrack
+	for i := range tracks {
+		if tracks[i].BaseURL == "" {
+			continue
+		}
+		if tracks[i].Kind != "asr" {
+			return &tracks[i]
+		}
+		if automatic == nil {
+			automatic = &tracks[i]
+		}
+	}
+	return automatic
+}
+
+// parseTimedText returns the caption lines of a caption track document, ordered by time
+func parseTimedText(doc string) []captionSegment {
+	var tt youTubeTimedText
+	if err := xml.Unmarshal([]byte(doc), &tt); err != nil {
+		return nil
+	}
+
+	var segments []captionSegment
+	add := func(start float64, body string) {
+		// Caption text is escaped once more inside the XML text
+		text := youTubeTagPattern.ReplaceAllString(body, "")
+		text = html.UnescapeString(html.UnescapeString(text))
+		text = strings.Join(strings.Fields(text), " ")
+		if text != "" {
+			segments = append(segments, captionSegment{start: start, text: text})
+		}
+	}
+	for _, t := range tt.Texts {
+		add(t.Start, t.Body)
+	}
+	for _, p := range tt.Paragraphs {
+		add(float64(p.Time)/1000, p.Body)
+	}
+
+	sort.SliceStable(segments, func(i, j int) bool { return segments[i].start < segments[j].start })
+	return segments
+}
+
+// formatTranscript groups caption lines into paragraphs, ending them at a sentence
+// after youTubeParagraphSpan seconds, or after youTubeParagraphLimit seconds at the latest
+func formatTranscript(segments []captionSegment) string {
+	if len(segments) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	var paragraph []string
+	paragraphStart := segments[0].start
+	flush := func() {
+		if len(paragraph) > 0 {
+			sb.WriteString("<p>")
+			sb.WriteString(html.EscapeString(strings.Join(paragraph, " ")))
+			sb.WriteString("</p>\n")
+			paragraph = nil
+		}
+	}
+
+	for _, seg := range segments {
+		if len(paragraph) > 0 {
+			elapsed := seg.start - paragraphStart
+			last := paragraph[len(paragraph)-1]
+			if elapsed >= youTubeParagraphLimit || (elapsed >= youTubeParagraphSpan && youTubeSentenceEnd.MatchString(last)) {
+				flush()
+			}
+		}
+		if len(paragraph) == 0 {
+			paragraphStart = seg.start
+		}
+		paragraph = append(paragraph, seg.text)
+	}
+	flush()
+	return sb.String()
+}
+
+// setYouTubeContent makes the video description and transcript the item content.
+// The media:description is removed because content extraction prefers it, and it
+// only holds the plain-text description.
+func setYouTubeContent(item *gofeed.Item, transcript string) {
+	description := ""
+	if media, ok := item.Extensions["media"]; ok {
+		if groups, ok := media["group"]; ok && len(groups) > 0 && groups[0].Children != nil {
+			if descs, ok := groups[0].Children["description"]; ok && len(descs) > 0 {
+				description = descs[0].Value
+				delete(groups[0].Children, "description")
+			}
+		}
+	}
+
+	var sb strings.Builder
+	if description = strings.TrimSpace(description); description != "" {
+		sb.WriteString("<p>")
+		sb.WriteString(strings.ReplaceAll(html.EscapeString(description), "\n", "<br>"))
+		sb.WriteString("</p>\n")
+	}
+	sb.WriteString("<h3>Transcript</h3>\n")
+	sb.WriteString(transcript)
+	item.Content = sb.String()
+	if item.Description == "" {
+		item.Description = description
+	}
+}
+
+// youTubeRequest creates a request that looks like a browser which accepted the cookie consent
+func youTubeRequest(ctx context.Context, method, rawURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", youTubeUserAgent)
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	req.Header.Set("Cookie", "CONSENT=YES+cb; SOCS=CAI")
+	return req, nil
+}
+
+// youTubeGet fetches a YouTube page or document
+func youTubeGet(ctx context.Context, client *http.Client, rawURL string) (string, error) {
+	req, err := youTubeRequest(ctx, http.MethodGet, rawURL)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, youTubeMaxPageSize))
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
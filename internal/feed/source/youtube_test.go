@@ -0,0 +1,185 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const youTubeTestFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns:media="http://search.yahoo.com/mrss/" xmlns="http://www.w3.org/2005/Atom">
+ <title>Test Channel</title>
+ <link rel="alternate" href="https://www.youtube.com/channel/UCabcdefghijklmnopqrstuv"/>
+ <entry>
+  <yt:videoId>video000001</yt:videoId>
+  <title>Long video</title>
+  <link rel="alternate" href="https://www.youtube.com/watch?v=video000001"/>
+  <published>2024-05-01T10:00:00+00:00</published>
+  <media:group>
+   <media:title>Long video</media:title>
+   <media:description>First line
+Second line</media:description>
+  </media:group>
+ </entry>
+ <entry>
+  <yt:videoId>short000001</yt:videoId>
+  <title>Short video</title>
+  <link rel="alternate" href="https://www.youtube.com/watch?v=short000001"/>
+  <published>2024-05-02T10:00:00+00:00</published>
+  <media:group>
+   <media:title>Short video</media:title>
+   <media:description>A short</media:description>
+  </media:group>
+ </entry>
+</feed>`
+
+// newYouTubeTestServer serves a channel feed, watch pages and caption tracks, and
+// points youTubeBaseURL to it for the duration of the test
+func newYouTubeTestServer(t *testing.T, requests *int32) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		switch {
+		case r.URL.Path == "/feeds/videos.xml":
+			w.Write([]byte(youTubeTestFeed))
+		case r.URL.Path == "/@testchannel":
+			w.Write([]byte(`<html><head><link rel="canonical" href="https://www.youtube.com/channel/UCabcdefghijklmnopqrstuv"></head></html>`))
+		case r.URL.Path == "/shorts/short000001":
+			w.WriteHeader(http.StatusOK)
+		case strings.HasPrefix(r.URL.Path, "/shorts/"):
+			http.Redirect(w, r, "/watch?v="+strings.TrimPrefix(r.URL.Path, "/shorts/"), http.StatusSeeOther)
+		case r.URL.Path == "/watch" && r.URL.Query().Get("v") == "video000001":
+			fmt.Fprintf(w, `<script>var ytInitialPlayerResponse = {"captions":{"playerCaptionsTracklistRenderer":{"captionTracks":[`+
+				`{"baseUrl":"%[1]s/api/timedtext?v=video000001&kind=asr&lang=en","languageCode":"en","kind":"asr"},`+
+				`{"baseUrl":"%[1]s/api/timedtext?v=video000001&lang=en&fmt=json3","languageCode":"en"}]}}};</script>`, server.URL)
+		case r.URL.Path == "/watch":
+			w.Write([]byte(`<script>var ytInitialPlayerResponse = {"playabilityStatus":{"status":"OK"}};</script>`))
+		case r.URL.Path == "/api/timedtext":
+			if r.URL.Query().Get("kind") == "asr" || r.URL.Query().Get("fmt") != "" {
+				t.Errorf("Expected the uploaded captions without a format, got %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`<?xml version="1.0" encoding="utf-8" ?><transcript>` +
+				`<text start="0.5" dur="2">Hello and welcome.</text>` +
+				`<text start="40" dur="2">Today we talk about &amp;lt;b&amp;gt; tags</text>` +
+				`<text start="42" dur="2">and it&amp;#39;s fun.</text>` +
+				`</transcript>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	original := youTubeBaseURL
+	youTubeBaseURL = server.URL
+	t.Cleanup(func() { youTubeBaseURL = original })
+	return server
+}
+
+func TestYouTubeSource_ResolveFeedURL(t *testing.T) {
+	var requests int32
+	server := newYouTubeTestServer(t, &requests)
+	s := NewYouTubeSource()
+	channelFeed := server.URL + "/feeds/videos.xml?channel_id=UCabcdefghijklmnopqrstuv"
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://www.youtube.com/channel/UCabcdefghijklmnopqrstuv/videos", channelFeed},
+		{"UCabcdefghijklmnopqrstuv", channelFeed},
+		{"https://www.youtube.com/@testchannel", channelFeed},
+		{"youtube.com/@testchannel/featured", ""}, // Only the handle page exists on the test server
+		{"@testchannel", channelFeed},
+		{"https://m.youtube.com/user/oldname", server.URL + "/feeds/videos.xml?user=oldname"},
+		{"https://www.youtube.com/playlist?list=PL1234", server.URL + "/feeds/videos.xml?playlist_id=PL1234"},
+		{"https://www.youtube.com/watch?v=video000001&list=PL1234", server.URL + "/feeds/videos.xml?playlist_id=PL1234"},
+		{"https://www.youtube.com/feeds/videos.xml?channel_id=UCabcdefghijklmnopqrstuv", "https://www.youtube.com/feeds/videos.xml?channel_id=UCabcdefghijklmnopqrstuv"},
+		{"https://example.com/@testchannel", ""},
+	}
+	for _, tt := range tests {
+		got, err := s.ResolveFeedURL(context.Background(), nil, tt.input)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ResolveFeedURL(%q) = %q, want an error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ResolveFeedURL(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestYouTubeSource_Fetch(t *testing.T) {
+	var requests int32
+	server := newYouTubeTestServer(t, &requests)
+	s := NewYouTubeSource()
+	config := &Config{URL: server.URL + "/feeds/videos.xml?channel_id=UCabcdefghijklmnopqrstuv"}
+
+	feed, err := s.Fetch(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(feed.Items))
+	}
+
+	long, short := feed.Items[0], feed.Items[1]
+	wantContent := "<p>First line<br>Second line</p>\n<h3>Transcript</h3>\n" +
+		"<p>Hello and welcome.</p>\n<p>Today we talk about &lt;b&gt; tags and it&#39;s fun.</p>\n"
+	if long.Content != wantContent {
+		t.Errorf("Unexpected content:\n%s\nwant:\n%s", long.Content, wantContent)
+	}
+	if long.Link != "https://www.youtube.com/watch?v=video000001" {
+		t.Errorf("Expected the video link to be kept, got %s", long.Link)
+	}
+	if short.Link != server.URL+"/shorts/short000001" {
+		t.Errorf("Expected a Shorts link, got %s", short.Link)
+	}
+	if short.Content != "" {
+		t.Errorf("Expected no content for a video without captions, got %q", short.Content)
+	}
+
+	// Looked-up videos are not fetched again
+	before := atomic.LoadInt32(&requests)
+	feed, err = s.Fetch(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&requests) - before; got != 1 {
+		t.Errorf("Expected only the feed to be fetched again, got %d requests", got)
+	}
+	if feed.Items[0].Content != wantContent || feed.Items[1].Link != server.URL+"/shorts/short000001" {
+		t.Errorf("Expected the cached transcript and Shorts link to be applied")
+	}
+}
+
+func TestFormatTranscript(t *testing.T) {
+	segments := []captionSegment{
+		{0, "One"},
+		{35, "two, no sentence end"},
+		{50, "three."},
+		{65, "four"},
+		{130, "five"},
+	}
+	want := "<p>One two, no sentence end three.</p>\n<p>four</p>\n<p>five</p>\n"
+	if got := formatTranscript(segments); got != want {
+		t.Errorf("formatTranscript = %q, want %q", got, want)
+	}
+	if got := formatTranscript(nil); got != "" {
+		t.Errorf("Expected no transcript without captions, got %q", got)
+	}
+}
+
+func TestParseTimedTextFormat3(t *testing.T) {
+	doc := `<timedtext format="3"><body><p t="2000" d="1000"><s>second</s><s> line</s></p><p t="500" d="1000">first</p></body></timedtext>`
+	segments := parseTimedText(doc)
+	if len(segments) != 2 || segments[0].text != "first" || segments[1].text != "second line" || segments[1].start != 2 {
+		t.Errorf("Unexpected segments %+v", segments)
+	}
+}
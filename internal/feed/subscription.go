@@ -2,6 +2,7 @@ package feed
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"MrRSS/internal/feed/httpoptions"
+	"MrRSS/internal/feed/source"
 	"MrRSS/internal/models"
 	"MrRSS/internal/rsshub"
 	"MrRSS/internal/utils"
@@ -111,8 +114,12 @@ func sanitizeFeedXML(xmlContent string) string {
 	return cleaned
 }
 
-// fetchAndSanitizeFeed fetches feed content and sanitizes it before parsing
-func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (string, error) {
+// fetchAndSanitizeFeed fetches feed content and sanitizes it before parsing.
+// feedURL is the URL to fetch, which differs from the URL of the feed for RSSHub routes.
+// The proxy and HTTP options of the feed are used.
+// If validators is non-nil the request is conditional: source.ErrNotModified is
+// returned on 304, and validators is updated from the response on 200.
+func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feed models.Feed, feedURL string, validators *httpValidators) (string, error) {
 	debugTimer := NewDebugTimer(fmt.Sprintf("FetchSanitize-%s", feedURL), shouldEnableDebugLogging(feedURL))
 	defer debugTimer.End()
 
@@ -120,7 +127,7 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (str
 
 	// Use the feed's HTTP client to fetch content
 	debugTimer.LogWithTime("Getting HTTP client")
-	httpClient, err := f.getHTTPClient(models.Feed{URL: feedURL})
+	httpClient, err := f.getFetchHTTPClient(feed)
 	if err != nil {
 		debugTimer.LogWithTime("Failed to create HTTP client: %v", err)
 		return "", fmt.Errorf("failed to create HTTP client: %w", err)
@@ -128,7 +135,7 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (str
 	debugTimer.Stage("HTTP client created")
 
 	debugTimer.LogWithTime("Creating HTTP request")
-	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	req, err := http.NewRequestWithContext(ctx, httpoptions.Method(f.feedHTTPOptions(feed)), feedURL, nil)
 	if err != nil {
 		debugTimer.LogWithTime("Failed to create request: %v", err)
 		return "", fmt.Errorf("failed to create request: %w", err)
@@ -144,6 +151,9 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (str
 	req.Header.Set("DNT", "1")
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Upgrade-Insecure-Requests", "1")
+	if validators != nil {
+		validators.applyToRequest(req)
+	}
 
 	debugTimer.LogWithTime("Sending HTTP request to %s", feedURL)
 	resp, err := httpClient.Do(req)
@@ -154,6 +164,11 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (str
 	defer resp.Body.Close()
 	debugTimer.Stage("HTTP request completed")
 
+	if resp.StatusCode == http.StatusNotModified && validators != nil {
+		debugTimer.LogWithTime("Feed not modified since last fetch")
+		return "", source.ErrNotModified
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		debugTimer.LogWithTime("HTTP status not OK: %d", resp.StatusCode)
 		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
@@ -170,6 +185,10 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (str
 	debugTimer.LogWithTime("Read %d bytes from response", len(body))
 	debugTimer.Stage("Body read complete")
 
+	if validators != nil {
+		validators.updateFromResponse(resp)
+	}
+
 	xmlContent := string(body)
 
 	// Sanitize the XML to remove problematic links
@@ -185,9 +204,26 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (str
 func (f *Fetcher) AddSubscription(url string, category string, customTitle string) (int64, error) {
 	utils.DebugLog("AddSubscription: Starting to add feed from URL: %s", url)
 
+	if source.IsYouTubeURL(url) {
+		return f.AddYouTubeSubscription(url, category, customTitle)
+	}
+
+	// Fediverse handles, and profile and hashtag pages of Mastodon-compatible servers
+	if target, ok := source.ParseMastodonTarget(url); ok {
+		feedID, err := f.AddMastodonSubscription(target, category, customTitle)
+		if err == nil {
+			return feedID, nil
+		}
+		if _, isHandle := source.ParseMastodonHandle(url); isHandle {
+			return 0, err
+		}
+		// Other sites use the same paths, so the URL may still be a feed
+		utils.DebugLog("AddSubscription: %s is not a Mastodon account or hashtag: %v", url, err)
+	}
+
 	// Try fetching and sanitizing the feed first
 	ctx := context.Background()
-	cleanedXML, err := f.fetchAndSanitizeFeed(ctx, url)
+	cleanedXML, err := f.fetchAndSanitizeFeed(ctx, models.Feed{URL: url}, url, nil)
 	if err != nil {
 		utils.DebugLog("AddSubscription: Failed to fetch feed for %s: %v", url, err)
 		// Fall through to standard parsing which might handle it differently
@@ -285,22 +321,99 @@ func (f *Fetcher) AddSubscription(url string, category string, customTitle strin
 	return f.db.AddFeed(feed)
 }
 
+// ResolveYouTubeFeedURL returns the Atom feed URL of a YouTube channel, handle or playlist URL.
+func (f *Fetcher) ResolveYouTubeFeedURL(ctx context.Context, url string) (string, error) {
+	client, err := f.getHTTPClient(models.Feed{URL: url})
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	return f.youtube.ResolveFeedURL(ctx, client, url)
+}
+
+// AddYouTubeSubscription adds a YouTube channel or playlist, given as a channel, handle,
+// playlist or feed URL, and returns the feed ID. The feed fetches the video transcripts.
+func (f *Fetcher) AddYouTubeSubscription(url string, category string, customTitle string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	feedURL, err := f.ResolveYouTubeFeedURL(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+	utils.DebugLog("AddYouTubeSubscription: Resolved %s to %s", url, feedURL)
+
+	parsedFeed, err := f.fp.ParseURLWithContext(feedURL, ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch YouTube feed: %w", err)
+	}
+
+	title := parsedFeed.Title
+	if customTitle != "" {
+		title = customTitle
+	}
+
+	feed := &models.Feed{
+		Title:       title,
+		URL:         feedURL,
+		Link:        parsedFeed.Link,
+		Description: parsedFeed.Description,
+		Category:    category,
+		Type:        string(source.TypeYouTube),
+	}
+
+	return f.db.AddFeed(feed)
+}
+
+// AddMastodonSubscription adds the public posts of an account or hashtag on a
+// Mastodon-compatible server and returns the feed ID.
+func (f *Fetcher) AddMastodonSubscription(target source.MastodonTarget, category string, customTitle string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	feedURL := target.FeedURL()
+	client, err := f.getHTTPClient(models.Feed{URL: feedURL})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	parsedFeed, err := f.mastodon.Fetch(ctx, &source.Config{URL: feedURL, SourceType: source.TypeMastodon, HTTPClient: client})
+	if err != nil {
+		return 0, err
+	}
+
+	title := parsedFeed.Title
+	if customTitle != "" {
+		title = customTitle
+	}
+
+	feed := &models.Feed{
+		Title:       title,
+		URL:         feedURL,
+		Link:        parsedFeed.Link,
+		Description: parsedFeed.Description,
+		Category:    category,
+		Type:        string(source.TypeMastodon),
+	}
+	if parsedFeed.Image != nil {
+		feed.ImageURL = parsedFeed.Image.URL
+	}
+
+	return f.db.AddFeed(feed)
+}
+
 // AddScriptSubscription adds a new feed subscription that uses a custom script
-// and returns the feed ID.
-func (f *Fetcher) AddScriptSubscription(scriptPath string, category string, customTitle string) (int64, error) {
+// and returns the feed ID. settings may be nil to run the script without options.
+func (f *Fetcher) AddScriptSubscription(scriptPath string, category string, customTitle string, settings *models.ScriptFeedSettings) (int64, error) {
 	// Validate script path
 	if f.scriptExecutor == nil {
 		return 0, &ScriptError{Message: "script executor not initialized"}
 	}
 
-	// Execute script to get initial feed info
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	parsedFeed, err := f.scriptExecutor.ExecuteScript(ctx, scriptPath)
+	// Execute script to get initial feed info; the timeout comes from the settings
+	result, err := f.scriptExecutor.RunScript(context.Background(), scriptPath, scriptRunOptions(settings))
 	if err != nil {
 		return 0, err
 	}
+	parsedFeed := result.Feed
 
 	title := parsedFeed.Title
 	if customTitle != "" {
@@ -323,7 +436,21 @@ func (f *Fetcher) AddScriptSubscription(scriptPath string, category string, cust
 		feed.ImageURL = parsedFeed.Image.URL
 	}
 
-	return f.db.AddFeed(feed)
+	feedID, err := f.db.AddFeed(feed)
+	if err != nil || settings == nil {
+		return feedID, err
+	}
+
+	settings.FeedID = feedID
+	if err := f.db.SaveScriptFeedSettings(settings); err != nil {
+		return feedID, fmt.Errorf("failed to save script settings: %w", err)
+	}
+	if result.StateChanged {
+		if err := f.db.SaveScriptState(feedID, result.State); err != nil {
+			return feedID, fmt.Errorf("failed to save script state: %w", err)
+		}
+	}
+	return feedID, nil
 }
 
 // AddXPathSubscription adds a new feed subscription that uses XPath expressions
@@ -463,13 +590,20 @@ func (f *Fetcher) AddXPathSubscription(url string, category string, customTitle
 }
 
 // ImportSubscription imports a feed subscription and returns the feed ID.
-func (f *Fetcher) ImportSubscription(title, url, category string) (int64, error) {
+// feedType is the type recorded in the OPML outline; it is only kept for source
+// types that cannot be told from the URL.
+func (f *Fetcher) ImportSubscription(title, url, category, feedType string) (int64, error) {
 	feed := &models.Feed{
 		Title:    title,
 		URL:      url,
 		Link:     "", // Link will be fetched later when feed is refreshed
 		Category: category,
 	}
+	if source.IsYouTubeFeedURL(url) {
+		feed.Type = string(source.TypeYouTube)
+	} else if _, ok := source.ParseMastodonURL(url); ok && feedType == string(source.TypeMastodon) {
+		feed.Type = feedType
+	}
 	return f.db.AddFeed(feed)
 }
 
@@ -493,13 +627,17 @@ func (f *Fetcher) ParseFeedWithScript(ctx context.Context, url string, scriptPat
 }
 
 // ParseFeedWithFeed parses a feed using the feed configuration (script or XPath)
+// and applies its item transform
 func (f *Fetcher) ParseFeedWithFeed(ctx context.Context, feed *models.Feed, priority bool) (*gofeed.Feed, error) {
 	// Parse the feed - priority parameter is kept for compatibility but no longer uses priorityMu
-	return f.parseFeedWithFeedInternal(ctx, feed, priority)
+	return f.parseAndTransformFeed(ctx, feed, priority, nil, nil)
 }
 
-// parseFeedWithFeedInternal does the actual parsing work
-func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Feed, priority bool) (*gofeed.Feed, error) {
+// parseFeedWithFeedInternal does the actual parsing work.
+// validators enables a conditional request for plain HTTP feeds (see fetchAndSanitizeFeed);
+// it is ignored for email, script and XPath feeds. state runs a script feed incrementally
+// (see scriptState); it is ignored for other feeds.
+func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Feed, priority bool, validators *httpValidators, state *scriptState) (*gofeed.Feed, error) {
 	// Enable debug timing for problematic feeds
 	debugTimer := NewDebugTimer(fmt.Sprintf("Feed-%s", feed.URL), shouldEnableDebugLogging(feed.URL))
 	defer debugTimer.End()
@@ -507,6 +645,73 @@ func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Fe
 	debugTimer.Stage("Starting parseFeedWithFeedInternal")
 	utils.DebugLog("parseFeedWithFeedInternal: Starting parsing for URL: %s, scriptPath: %s, type: %s, priority: %v", feed.URL, feed.ScriptPath, feed.Type, priority)
 
+	// The Digests feed only holds articles written by the digest service
+	if feed.Type == "digest" {
+		return &gofeed.Feed{Title: feed.Title, Link: feed.URL, Description: feed.Description}, nil
+	}
+
+	// YouTube channels and playlists get the video transcripts as content
+	if feed.Type == string(source.TypeYouTube) {
+		utils.DebugLog("parseFeedWithFeedInternal: Using YouTube source for %s", feed.URL)
+		client, err := f.getFetchHTTPClient(*feed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+
+		// For high priority requests, use shorter timeout
+		youTubeCtx := ctx
+		if priority {
+			var cancel context.CancelFunc
+			youTubeCtx, cancel = context.WithTimeout(ctx, 15*time.Second) // Shorter timeout for content fetching
+			defer cancel()
+		}
+
+		return f.youtube.Fetch(youTubeCtx, &source.Config{URL: feed.URL, SourceType: source.TypeYouTube, HTTPClient: client})
+	}
+
+	// Mastodon accounts and hashtags are fetched through the server's API
+	if feed.Type == string(source.TypeMastodon) {
+		utils.DebugLog("parseFeedWithFeedInternal: Using Mastodon source for %s", feed.URL)
+		client, err := f.getFetchHTTPClient(*feed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+
+		// For high priority requests, use shorter timeout
+		mastodonCtx := ctx
+		if priority {
+			var cancel context.CancelFunc
+			mastodonCtx, cancel = context.WithTimeout(ctx, 15*time.Second) // Shorter timeout for content fetching
+			defer cancel()
+		}
+
+		return f.mastodon.Fetch(mastodonCtx, &source.Config{URL: feed.URL, SourceType: source.TypeMastodon, HTTPClient: client})
+	}
+
+	// JSON APIs are mapped to items with the feed's JSONPath expressions
+	if feed.Type == string(source.TypeJSON) {
+		utils.DebugLog("parseFeedWithFeedInternal: Using JSON source for %s", feed.URL)
+		options, err := f.db.GetJSONFeedOptions(feed.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JSON feed options: %w", err)
+		}
+
+		// For high priority requests, use shorter timeout
+		jsonCtx := ctx
+		if priority {
+			var cancel context.CancelFunc
+			jsonCtx, cancel = context.WithTimeout(ctx, 15*time.Second) // Shorter timeout for content fetching
+			defer cancel()
+		}
+
+		parsedFeed, err := f.fetchJSONFeed(jsonCtx, feed, options)
+		if err != nil {
+			return nil, err
+		}
+		parsedFeed.Title = feed.Title
+		return parsedFeed, nil
+	}
+
 	// Check if this is an email-based newsletter feed
 	if feed.Type == "email" {
 		utils.DebugLog("parseFeedWithFeedInternal: Using email fetching for newsletter: %s", feed.EmailAddress)
@@ -533,20 +738,9 @@ func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Fe
 
 	if feed.ScriptPath != "" {
 		utils.DebugLog("parseFeedWithFeedInternal: Using script execution for %s", feed.ScriptPath)
-		// Execute the custom script to fetch feed
-		if f.scriptExecutor == nil {
-			return nil, &ScriptError{Message: "Script executor not initialized"}
-		}
-
-		// For high priority requests, use shorter timeout
-		scriptCtx := ctx
-		if priority {
-			var cancel context.CancelFunc
-			scriptCtx, cancel = context.WithTimeout(ctx, 15*time.Second) // Shorter timeout for content fetching
-			defer cancel()
-		}
-
-		return f.scriptExecutor.ExecuteScript(scriptCtx, feed.ScriptPath)
+		// Execute the custom script with the feed's arguments, environment and state.
+		// The run is limited by the timeout configured for the script, also for content fetching.
+		return f.runFeedScript(ctx, feed, state)
 	}
 
 	// Check if this is an XPath-based feed
@@ -590,9 +784,14 @@ func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Fe
 	// Try fetching and sanitizing the feed first to handle file:// URLs in atom:link
 	debugTimer.LogWithTime("About to call fetchAndSanitizeFeed")
 	utils.DebugLog("parseFeedWithFeedInternal: Attempting to fetch and sanitize feed for %s", actualURL)
-	cleanedXML, sanitizeErr := f.fetchAndSanitizeFeed(fetchCtx, actualURL)
+	cleanedXML, sanitizeErr := f.fetchAndSanitizeFeed(fetchCtx, *feed, actualURL, validators)
 	debugTimer.LogWithTime("fetchAndSanitizeFeed completed, err=%v", sanitizeErr)
 
+	if errors.Is(sanitizeErr, source.ErrNotModified) {
+		utils.DebugLog("parseFeedWithFeedInternal: Feed not modified since last fetch: %s", actualURL)
+		return nil, sanitizeErr
+	}
+
 	if sanitizeErr == nil {
 		debugTimer.Stage("Parsing sanitized XML")
 		// Successfully fetched and sanitized, try parsing
@@ -674,7 +873,7 @@ func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Fe
 }
 
 // parseFeedWithXPath parses a feed using XPath expressions
-func (f *Fetcher) parseFeedWithXPath(_ context.Context, feed *models.Feed) (*gofeed.Feed, error) {
+func (f *Fetcher) parseFeedWithXPath(ctx context.Context, feed *models.Feed) (*gofeed.Feed, error) {
 	if feed.XPathItem == "" {
 		return nil, &XPathError{
 			Operation: "validate",
@@ -682,8 +881,8 @@ func (f *Fetcher) parseFeedWithXPath(_ context.Context, feed *models.Feed) (*gof
 		}
 	}
 
-	// Fetch the content
-	httpClient, err := httputil.CreateHTTPClient("", 30*time.Second)
+	// Fetch the content with the proxy and HTTP options of the feed
+	httpClient, err := f.getFetchHTTPClient(*feed)
 	if err != nil {
 		return nil, &XPathError{
 			Operation: "fetch",
@@ -692,7 +891,16 @@ func (f *Fetcher) parseFeedWithXPath(_ context.Context, feed *models.Feed) (*gof
 			Err:       err,
 		}
 	}
-	resp, err := httpClient.Get(feed.URL)
+	req, err := http.NewRequestWithContext(ctx, httpoptions.Method(f.feedHTTPOptions(*feed)), feed.URL, nil)
+	if err != nil {
+		return nil, &XPathError{
+			Operation: "fetch",
+			URL:       feed.URL,
+			Details:   "Invalid feed URL",
+			Err:       err,
+		}
+	}
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, &XPathError{
 			Operation: "fetch",
@@ -473,17 +473,18 @@ func (tm *TaskManager) ExecuteImmediately(ctx context.Context, feed models.Feed)
 		}()
 
 		// Execute with timeout and retry
+		var report *fetchReport
 		var err error
 		var success bool
 
 		// Get retry timeout from settings
 		retryTimeout := tm.getRetryTimeout()
 
-		// First attempt: 10 second timeout
-		ctx1, cancel1 := context.WithTimeout(ctx, 10*time.Second)
+		// First attempt: 10 second timeout, longer for scripts with a longer run timeout
+		ctx1, cancel1 := context.WithTimeout(ctx, tm.attemptTimeout(task.Feed, 10*time.Second))
 		defer cancel1()
 
-		err = tm.fetcher.fetchFeedWithContext(ctx1, task.Feed)
+		report, err = tm.fetchAttempt(ctx1, task.Feed, 1)
 		if err == nil {
 			success = true
 			log.Printf("Successfully fetched feed: %s (immediate, first attempt)", task.Feed.Title)
@@ -493,10 +494,10 @@ func (tm *TaskManager) ExecuteImmediately(ctx context.Context, feed models.Feed)
 		if !success && err != nil {
 			log.Printf("First attempt failed for %s: %v, retrying with %v timeout", task.Feed.Title, err, retryTimeout)
 
-			ctx2, cancel2 := context.WithTimeout(ctx, retryTimeout)
+			ctx2, cancel2 := context.WithTimeout(ctx, tm.attemptTimeout(task.Feed, retryTimeout))
 			defer cancel2()
 
-			err = tm.fetcher.fetchFeedWithContext(ctx2, task.Feed)
+			report, err = tm.fetchAttempt(ctx2, task.Feed, 2)
 			if err == nil {
 				success = true
 				log.Printf("Successfully fetched feed: %s (immediate, second attempt)", task.Feed.Title)
@@ -519,6 +520,8 @@ func (tm *TaskManager) ExecuteImmediately(ctx context.Context, feed models.Feed)
 			tm.fetcher.db.UpdateFeedError(task.Feed.ID, "")
 			tm.fetcher.db.UpdateFeedLastUpdated(task.Feed.ID)
 		}
+
+		tm.recordRefresh(ctx, task.Feed, report, err)
 	}()
 
 	// Return completion callback
@@ -619,6 +622,7 @@ func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
 	log.Printf("Processing feed: %s (reason: %d)", task.Feed.Title, task.Reason)
 
 	// Try fetching with timeout and retry
+	var report *fetchReport
 	var err error
 	var success bool
 
@@ -627,11 +631,12 @@ func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
 
 	// First attempt: 60 second timeout (increased from 10s for large feeds)
 	// Many feeds have 100+ articles, and processing can take time
-	ctx1, cancel1 := context.WithTimeout(ctx, 60*time.Second)
+	firstTimeout := tm.attemptTimeout(task.Feed, 60*time.Second)
+	ctx1, cancel1 := context.WithTimeout(ctx, firstTimeout)
 	defer cancel1()
 
-	log.Printf("Starting first attempt to fetch feed: %s (timeout: 60s)", task.Feed.Title)
-	err = tm.fetcher.fetchFeedWithContext(ctx1, task.Feed)
+	log.Printf("Starting first attempt to fetch feed: %s (timeout: %v)", task.Feed.Title, firstTimeout)
+	report, err = tm.fetchAttempt(ctx1, task.Feed, 1)
 	if err == nil {
 		success = true
 		log.Printf("Successfully fetched feed: %s (first attempt)", task.Feed.Title)
@@ -642,10 +647,10 @@ func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
 		log.Printf("First attempt failed for %s: %v, retrying with %v timeout", task.Feed.Title, err, retryTimeout)
 		tm.logOperation("RT", task.Feed.Title)
 
-		ctx2, cancel2 := context.WithTimeout(ctx, retryTimeout)
+		ctx2, cancel2 := context.WithTimeout(ctx, tm.attemptTimeout(task.Feed, retryTimeout))
 		defer cancel2()
 
-		err = tm.fetcher.fetchFeedWithContext(ctx2, task.Feed)
+		report, err = tm.fetchAttempt(ctx2, task.Feed, 2)
 		if err == nil {
 			success = true
 			log.Printf("Successfully fetched feed: %s (second attempt)", task.Feed.Title)
@@ -674,6 +679,9 @@ func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
 		tm.fetcher.db.UpdateFeedError(task.Feed.ID, "")
 		tm.fetcher.db.UpdateFeedLastUpdated(task.Feed.ID)
 	}
+
+	// Update the health state used to back off, pause or flag the feed
+	tm.recordRefresh(ctx, task.Feed, report, err)
 }
 
 // checkCompletion checks if all tasks are completed and triggers cleanup if needed
@@ -941,6 +949,12 @@ func parseInt(s string) (int, error) {
 	return i, err
 }
 
+// attemptTimeout returns the timeout of a fetch attempt, extended for script feeds so that
+// the script can use its configured run timeout
+func (tm *TaskManager) attemptTimeout(feed models.Feed, timeout time.Duration) time.Duration {
+	return max(timeout, tm.fetcher.scriptFetchTimeout(&feed))
+}
+
 // getRetryTimeout retrieves the retry timeout from settings
 // Returns the configured timeout in seconds (default 60 seconds)
 func (tm *TaskManager) getRetryTimeout() time.Duration {
@@ -0,0 +1,319 @@
+// Package transform runs per-feed JavaScript on fetched items before they are stored.
+// Scripts define a function transform(item, feed) that can change the fields of an
+// item, return a replacement object, or return null or false to drop the item.
+// Scripts run in an embedded interpreter without file, network or process access,
+// and are stopped when they exceed the time limit.
+package transform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	// MaxScriptSize is the maximum length of a transform script in bytes
+	MaxScriptSize = 64 * 1024
+	// maxRunTime limits the time a script can take for all items of a fetch
+	maxRunTime = 2 * time.Second
+	// maxCallStackSize limits recursion in scripts
+	maxCallStackSize = 256
+	// maxLogLines is the number of console.log lines kept for the test endpoint
+	maxLogLines = 100
+)
+
+// dateFormats are the accepted formats of dates set as strings
+var dateFormats = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Feed is the information about the feed passed to scripts
+type Feed struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// Result is the outcome of a transform run
+type Result struct {
+	Items   []*gofeed.Item
+	Dropped []bool // Whether each input item was dropped
+	Logs    []string
+}
+
+// Validate compiles a script and checks that it defines the transform function
+func Validate(script string) error {
+	if len(script) > MaxScriptSize {
+		return fmt.Errorf("script is longer than %d bytes", MaxScriptSize)
+	}
+	vm, err := newRuntime(script, nil)
+	if err != nil {
+		return err
+	}
+	_, err = transformFunction(vm)
+	return err
+}
+
+// Run applies a script to items. Items are changed in place; the returned items are
+// those that were not dropped. An error in the script for any item fails the whole
+// run, so that items the script was meant to drop are never stored by accident.
+func Run(ctx context.Context, script string, feed Feed, items []*gofeed.Item) (*Result, error) {
+	result := &Result{Dropped: make([]bool, len(items))}
+	if len(script) > MaxScriptSize {
+		return result, fmt.Errorf("script is longer than %d bytes", MaxScriptSize)
+	}
+
+	vm, err := newRuntime(script, result)
+	if err != nil {
+		return result, err
+	}
+	fn, err := transformFunction(vm)
+	if err != nil {
+		return result, err
+	}
+
+	// Stop the script when the time limit is reached or the fetch is cancelled
+	ctx, cancel := context.WithTimeout(ctx, maxRunTime)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() {
+		vm.Interrupt(ctx.Err())
+	})
+	defer stop()
+
+	feedValue := vm.ToValue(map[string]interface{}{"title": feed.Title, "url": feed.URL})
+	for i, item := range items {
+		obj := itemObject(vm, item)
+		ret, err := fn(goja.Undefined(), obj, feedValue)
+		if err != nil {
+			return result, scriptError(err, i)
+		}
+
+		switch {
+		case goja.IsNull(ret) || ret.StrictEquals(vm.ToValue(false)):
+			// null or false drops the item
+			result.Dropped[i] = true
+			continue
+		case goja.IsUndefined(ret):
+			// The item object was changed in place
+		default:
+			returned, ok := ret.(*goja.Object)
+			if !ok {
+				return result, fmt.Errorf("transform of item %d returned %s, expected an object, null or false", i+1, ret.String())
+			}
+			obj = returned
+		}
+
+		if err := applyObject(obj, item); err != nil {
+			return result, fmt.Errorf("item %d: %w", i+1, err)
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
+// newRuntime creates a sandboxed runtime with the script loaded. Logs of console.log
+// are collected in result, if given.
+func newRuntime(script string, result *Result) (*goja.Runtime, error) {
+	vm := goja.New()
+	vm.SetMaxCallStackSize(maxCallStackSize)
+
+	console := vm.NewObject()
+	_ = console.Set("log", func(call goja.FunctionCall) goja.Value {
+		if result != nil && len(result.Logs) < maxLogLines {
+			parts := make([]string, len(call.Arguments))
+			for i, arg := range call.Arguments {
+				parts[i] = arg.String()
+			}
+			result.Logs = append(result.Logs, strings.Join(parts, " "))
+		}
+		return goja.Undefined()
+	})
+	_ = vm.Set("console", console)
+
+	// queryParam returns a query parameter of a URL, e.g. the target of a tracking link
+	_ = vm.Set("queryParam", func(rawURL, name string) string {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return ""
+		}
+		return u.Query().Get(name)
+	})
+
+	program, err := goja.Compile("transform.js", script, false)
+	if err != nil {
+		return nil, fmt.Errorf("script error: %w", err)
+	}
+
+	// Top-level code also runs under the time limit
+	timer := time.AfterFunc(maxRunTime, func() {
+		vm.Interrupt(context.DeadlineExceeded)
+	})
+	defer timer.Stop()
+	if _, err := vm.RunProgram(program); err != nil {
+		return nil, scriptError(err, -1)
+	}
+	vm.ClearInterrupt()
+	return vm, nil
+}
+
+// transformFunction returns the transform function defined by the script
+func transformFunction(vm *goja.Runtime) (goja.Callable, error) {
+	fn, ok := goja.AssertFunction(vm.Get("transform"))
+	if !ok {
+		return nil, errors.New("script must define a function transform(item, feed)")
+	}
+	return fn, nil
+}
+
+// scriptError describes an error thrown by a script or an interrupted run.
+// index is the item being transformed, or -1 for the top-level code.
+func scriptError(err error, index int) error {
+	where := "script error"
+	if index >= 0 {
+		where = fmt.Sprintf("script error in item %d", index+1)
+	}
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		if interrupted.Value() == context.Canceled {
+			return fmt.Errorf("%s: cancelled", where)
+		}
+		return fmt.Errorf("%s: time limit of %s exceeded", where, maxRunTime)
+	}
+	var exception *goja.Exception
+	if errors.As(err, &exception) {
+		return fmt.Errorf("%s: %s", where, exception.Error())
+	}
+	return fmt.Errorf("%s: %w", where, err)
+}
+
+// itemObject converts an item to the object passed to scripts
+func itemObject(vm *goja.Runtime, item *gofeed.Item) *goja.Object {
+	obj := vm.NewObject()
+	author := ""
+	if item.Author != nil {
+		author = item.Author.Name
+	}
+	image := ""
+	if item.Image != nil {
+		image = item.Image.URL
+	}
+	categories := make([]interface{}, len(item.Categories))
+	for i, category := range item.Categories {
+		categories[i] = category
+	}
+
+	_ = obj.Set("title", item.Title)
+	_ = obj.Set("link", item.Link)
+	_ = obj.Set("guid", item.GUID)
+	_ = obj.Set("content", item.Content)
+	_ = obj.Set("description", item.Description)
+	_ = obj.Set("author", author)
+	_ = obj.Set("image", image)
+	_ = obj.Set("categories", vm.NewArray(categories...))
+	_ = obj.Set("published", formatDate(item.PublishedParsed))
+	_ = obj.Set("updated", formatDate(item.UpdatedParsed))
+	return obj
+}
+
+// applyObject copies the fields of a script object back to an item
+func applyObject(obj *goja.Object, item *gofeed.Item) error {
+	item.Title = stringField(obj, "title")
+	item.Link = stringField(obj, "link")
+	item.GUID = stringField(obj, "guid")
+	item.Content = stringField(obj, "content")
+	item.Description = stringField(obj, "description")
+
+	// Authors and images are replaced rather than changed, as copies of the item share them
+	if author := stringField(obj, "author"); author == "" {
+		item.Author = nil
+	} else if item.Author == nil || item.Author.Name != author {
+		item.Author = &gofeed.Person{Name: author}
+	}
+	if image := stringField(obj, "image"); image == "" {
+		item.Image = nil
+	} else if item.Image == nil || item.Image.URL != image {
+		item.Image = &gofeed.Image{URL: image}
+	}
+
+	item.Categories = nil
+	if categories := obj.Get("categories"); categories != nil && !goja.IsUndefined(categories) && !goja.IsNull(categories) {
+		values, ok := categories.Export().([]interface{})
+		if !ok {
+			return errors.New("categories must be an array")
+		}
+		for _, v := range values {
+			if category := strings.TrimSpace(fmt.Sprint(v)); category != "" {
+				item.Categories = append(item.Categories, category)
+			}
+		}
+	}
+
+	published, err := dateField(obj, "published")
+	if err != nil {
+		return err
+	}
+	item.PublishedParsed = published
+	updated, err := dateField(obj, "updated")
+	if err != nil {
+		return err
+	}
+	item.UpdatedParsed = updated
+	return nil
+}
+
+// stringField returns a field of a script object as string, empty if unset
+func stringField(obj *goja.Object, name string) string {
+	v := obj.Get(name)
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return ""
+	}
+	return v.String()
+}
+
+// dateField returns a date field, which scripts can set to a Date, a string or a
+// Unix time in milliseconds
+func dateField(obj *goja.Object, name string) (*time.Time, error) {
+	v := obj.Get(name)
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return nil, nil
+	}
+	switch value := v.Export().(type) {
+	case time.Time:
+		return &value, nil
+	case int64:
+		t := time.UnixMilli(value).UTC()
+		return &t, nil
+	case float64:
+		t := time.UnixMilli(int64(value)).UTC()
+		return &t, nil
+	case string:
+		if value == "" {
+			return nil, nil
+		}
+		for _, format := range dateFormats {
+			if t, err := time.Parse(format, value); err == nil {
+				return &t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid %s date %q", name, value)
+	default:
+		return nil, fmt.Errorf("invalid %s date %v", name, value)
+	}
+}
+
+// formatDate formats a date for scripts, empty if unset
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,91 @@
+package transform
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func testItems() []*gofeed.Item {
+	published := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return []*gofeed.Item{
+		{Title: "News: First", Link: "https://track.example.com/r?url=https%3A%2F%2Fexample.com%2F1", PublishedParsed: &published},
+		{Title: "Sponsored: Buy now", Link: "https://example.com/ad", Categories: []string{"ad"}},
+		{Title: "Third", Link: "https://example.com/3", Author: &gofeed.Person{Name: "Ann"}},
+	}
+}
+
+func TestRun(t *testing.T) {
+	script := `
+function transform(item, feed) {
+	if (item.categories.includes("ad")) return false;
+	console.log("item", item.title, feed.title);
+	item.title = item.title.replace(/^News: /, "");
+	var target = queryParam(item.link, "url");
+	if (target) item.link = target;
+	if (item.title === "Third") {
+		return {title: "Third!", link: item.link, published: "2024-05-03", categories: ["x"]};
+	}
+}`
+	result, err := Run(context.Background(), script, Feed{Title: "Example"}, testItems())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Items) != 2 || !result.Dropped[1] || result.Dropped[0] || result.Dropped[2] {
+		t.Fatalf("Expected the sponsored item to be dropped, got %d items %v", len(result.Items), result.Dropped)
+	}
+
+	first, third := result.Items[0], result.Items[1]
+	if first.Title != "First" || first.Link != "https://example.com/1" {
+		t.Errorf("Unexpected first item %q %q", first.Title, first.Link)
+	}
+	if first.PublishedParsed == nil || first.PublishedParsed.Day() != 1 {
+		t.Errorf("Expected the date to be kept, got %v", first.PublishedParsed)
+	}
+	if third.Title != "Third!" || third.Author != nil || third.PublishedParsed == nil || third.PublishedParsed.Day() != 3 {
+		t.Errorf("Expected the returned object to replace the item, got %+v", third)
+	}
+	if len(third.Categories) != 1 || third.Categories[0] != "x" {
+		t.Errorf("Unexpected categories %v", third.Categories)
+	}
+	if len(result.Logs) != 2 || result.Logs[0] != "item News: First Example" {
+		t.Errorf("Unexpected logs %q", result.Logs)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"syntax error", "function transform(item) {", "script error"},
+		{"missing function", "var x = 1;", "must define a function transform"},
+		{"thrown error", "function transform(item) { throw new Error('boom'); }", "boom"},
+		{"endless loop", "function transform(item) { while (true) {} }", "time limit"},
+		{"endless top-level loop", "while (true) {} function transform(item) {}", "time limit"},
+		{"invalid date", "function transform(item) { item.published = 'yesterday'; }", "invalid published date"},
+		{"invalid return", "function transform(item) { return 42; }", "expected an object"},
+	}
+	for _, tt := range tests {
+		_, err := Run(context.Background(), tt.script, Feed{}, testItems())
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate("function transform(item) { return item; }"); err != nil {
+		t.Errorf("Validate failed for a valid script: %v", err)
+	}
+	if err := Validate("function other() {}"); err == nil {
+		t.Error("Expected an error for a script without transform")
+	}
+	if err := Validate(strings.Repeat(" ", MaxScriptSize+1)); err == nil {
+		t.Error("Expected an error for a script that is too long")
+	}
+}
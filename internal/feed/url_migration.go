@@ -0,0 +1,263 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"MrRSS/internal/feed/httpoptions"
+	"MrRSS/internal/feed/source"
+	"MrRSS/internal/models"
+	"MrRSS/internal/rsshub"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Reasons of feed URL changes recorded in the URL history
+const (
+	urlChangeRedirect   = "redirect"     // The feed URL answered 301 or 308
+	urlChangeNewFeedURL = "new_feed_url" // The feed announced its new URL with itunes:new-feed-url
+	urlChangeSelfLink   = "self_link"    // The atom:link rel="self" of the feed differs from its URL
+)
+
+// migrateFeedURL moves a feed to the new URL it was found at by a fetch. The feed is
+// moved when its URL permanently redirects, or when its itunes:new-feed-url or
+// atom:link rel="self" points elsewhere and a feed can be fetched from there. If the new
+// URL is already subscribed, the feed is merged into that subscription.
+// Announced URLs on another site are only followed with feed_url_migrate_other_sites,
+// and feeds with HTTP options are never moved to another site, which would send their
+// credentials there.
+// It returns the feed the fetched articles belong to: the moved feed, or the
+// subscription it was merged into.
+func (f *Fetcher) migrateFeedURL(ctx context.Context, feed models.Feed, parsedFeed *gofeed.Feed) models.Feed {
+	if autoMigrate, _ := f.db.GetSetting("feed_url_auto_migrate"); autoMigrate != "true" {
+		return feed
+	}
+
+	newURL, reason := f.newFeedURL(ctx, feed, parsedFeed)
+	if newURL == "" {
+		return feed
+	}
+	if !sameSite(feed.URL, newURL) && f.feedHTTPOptions(feed) != nil {
+		log.Printf("Not moving feed %s to %s: the feed has HTTP options for its current site", feed.Title, newURL)
+		return feed
+	}
+	if reason != urlChangeRedirect {
+		// A moved URL that is announced but not redirected must serve the feed.
+		// A URL that fails is not checked again until the feed announces another one.
+		if rejected, ok := f.rejectedURLs.Load(feed.ID); ok && rejected == newURL {
+			return feed
+		}
+		if err := f.verifyFeedURL(ctx, feed, newURL); err != nil {
+			log.Printf("Not moving feed %s to %s: %v", feed.Title, newURL, err)
+			if !canceled(ctx) {
+				f.rejectedURLs.Store(feed.ID, newURL)
+			}
+			return feed
+		}
+	}
+
+	report := fetchReportFrom(ctx)
+	existingID, err := f.db.GetLocalFeedIDByURL(newURL, feed.ID)
+	if err != nil {
+		log.Printf("Error looking up feed URL %s: %v", newURL, err)
+		return feed
+	}
+	if existingID == 0 {
+		if err := f.db.MoveFeedURL(feed.ID, newURL, reason); err != nil {
+			log.Printf("Error moving feed %s to %s: %v", feed.Title, newURL, err)
+			return feed
+		}
+		log.Printf("Moved feed %s from %s to %s (%s)", feed.Title, feed.URL, newURL, reason)
+		if report != nil {
+			report.setMigrated(feed.ID)
+		}
+		feed.URL = newURL
+		return feed
+	}
+
+	moved, err := f.db.MergeFeed(feed.ID, existingID, reason)
+	if err != nil {
+		log.Printf("Error merging feed %s into the subscription of %s: %v", feed.Title, newURL, err)
+		return feed
+	}
+	into, err := f.db.GetFeedByID(existingID)
+	if err != nil {
+		log.Printf("Error loading feed %d: %v", existingID, err)
+		return feed
+	}
+	log.Printf("Merged feed %s into %s at %s (%s), moving %d articles", feed.Title, into.Title, newURL, reason, moved)
+	if report != nil {
+		report.setMigrated(existingID)
+	}
+	return *into
+}
+
+// newFeedURL returns the new URL of a feed announced by a fetch and the reason of the
+// change, or "" if the feed has not moved. A permanent redirect takes precedence over
+// itunes:new-feed-url, which takes precedence over atom:link rel="self".
+func (f *Fetcher) newFeedURL(ctx context.Context, feed models.Feed, parsedFeed *gofeed.Feed) (string, string) {
+	// Feeds with a URL that is not fetched as is are never moved
+	if feed.IsFreshRSSSource || feed.ScriptPath != "" || rsshub.IsRSSHubURL(feed.URL) {
+		return "", ""
+	}
+	switch feed.Type {
+	case "", string(source.TypeRSS), string(source.TypeJSON), "HTML+XPath", "XML+XPath":
+	default:
+		return "", ""
+	}
+
+	if report := fetchReportFrom(ctx); report != nil {
+		if redirectURL := report.pendingRedirect(); redirectURL != "" && isFeedURLChange(feed.URL, redirectURL) {
+			return redirectURL, urlChangeRedirect
+		}
+	}
+
+	// Only feeds parsed from RSS or Atom announce their URL
+	if feed.Type != "" && feed.Type != string(source.TypeRSS) {
+		return "", ""
+	}
+	otherSites, _ := f.db.GetSetting("feed_url_migrate_other_sites")
+	for _, a := range announcedURLs(parsedFeed) {
+		newURL, ok := resolveFeedURL(feed.URL, a.url)
+		if !ok || !isFeedURLChange(feed.URL, newURL) {
+			continue
+		}
+		// Self links are often set by a proxy or a feed service, such as FeedBurner,
+		// rather than by the site
+		if otherSites != "true" && !sameSite(feed.URL, newURL) {
+			continue
+		}
+		// Do not move back to a URL the feed was moved away from, e.g. because
+		// the old URL redirects to the new one but is still its self link
+		if f.movedFrom(feed.ID, newURL) {
+			continue
+		}
+		return newURL, a.reason
+	}
+	return "", ""
+}
+
+// announcedURL is a URL that a feed announces for itself
+type announcedURL struct {
+	url    string
+	reason string
+}
+
+// announcedURLs returns the URLs that a parsed feed announces for itself, the strongest first
+func announcedURLs(parsedFeed *gofeed.Feed) []announcedURL {
+	var announced []announcedURL
+	if parsedFeed.ITunesExt != nil && parsedFeed.ITunesExt.NewFeedURL != "" {
+		announced = append(announced, announcedURL{parsedFeed.ITunesExt.NewFeedURL, urlChangeNewFeedURL})
+	}
+	if parsedFeed.FeedLink != "" {
+		announced = append(announced, announcedURL{parsedFeed.FeedLink, urlChangeSelfLink})
+	}
+	return announced
+}
+
+// resolveFeedURL resolves a URL announced by a feed against the feed URL. It reports
+// false if it is not an HTTP URL.
+func resolveFeedURL(feedURL, announced string) (string, bool) {
+	base, err := url.Parse(feedURL)
+	if err != nil {
+		return "", false
+	}
+	ref, err := url.Parse(strings.TrimSpace(announced))
+	if err != nil {
+		return "", false
+	}
+	resolved := base.ResolveReference(ref)
+	if (resolved.Scheme != "http" && resolved.Scheme != "https") || resolved.Host == "" {
+		return "", false
+	}
+	return resolved.String(), true
+}
+
+// isFeedURLChange reports whether newURL is another URL than the feed URL that the feed can
+// be moved to. URLs that only differ in case, a "www." prefix, a default port or a trailing
+// slash are the same. A move from HTTPS to HTTP is never accepted: sites behind a
+// TLS-terminating proxy often announce an http:// self link.
+func isFeedURLChange(feedURL, newURL string) bool {
+	current, err := url.Parse(feedURL)
+	if err != nil {
+		return false
+	}
+	next, err := url.Parse(newURL)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(current.Scheme, "https") && !strings.EqualFold(next.Scheme, "https") {
+		return false
+	}
+	return normalizeFeedURL(current) != normalizeFeedURL(next)
+}
+
+// normalizeFeedURL returns a URL for comparison, without the differences that do not
+// change the feed that is served
+func normalizeFeedURL(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return scheme + "://" + host + path
+}
+
+// sameSite reports whether two URLs belong to the same site (see httpoptions.SameSite)
+func sameSite(feedURL, newURL string) bool {
+	u, err := url.Parse(newURL)
+	if err != nil {
+		return false
+	}
+	return httpoptions.SameSite(u, feedURL)
+}
+
+// movedFrom reports whether a feed was ever moved away from a URL
+func (f *Fetcher) movedFrom(feedID int64, oldURL string) bool {
+	history, err := f.db.GetFeedURLHistory(feedID)
+	if err != nil {
+		log.Printf("Error loading URL history of feed %d: %v", feedID, err)
+		return true
+	}
+	for _, change := range history {
+		if change.OldURL == oldURL {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyFeedURL checks that the feed can be fetched from a new URL, with the proxy of the
+// feed, and that it is not moved again from there: the new URL does not permanently
+// redirect and the feed there does not announce yet another URL. The HTTP options of the
+// feed are only sent if the new URL is on the site of the current one, as the new URL is
+// taken from the feed document.
+func (f *Fetcher) verifyFeedURL(ctx context.Context, feed models.Feed, newURL string) error {
+	moved := feed
+	moved.URL = newURL
+	verifyCtx, report := withFetchReport(ctx, moved)
+	content, err := f.fetchAndSanitizeFeed(verifyCtx, feed, newURL, nil)
+	if err != nil {
+		return err
+	}
+	if redirectURL := report.pendingRedirect(); redirectURL != "" {
+		return fmt.Errorf("the new URL permanently redirects to %s", redirectURL)
+	}
+	parsedFeed, err := gofeed.NewParser().ParseString(content)
+	if err != nil {
+		return err
+	}
+	for _, a := range announcedURLs(parsedFeed) {
+		if announced, ok := resolveFeedURL(newURL, a.url); ok && isFeedURLChange(newURL, announced) {
+			return fmt.Errorf("the feed at the new URL announces %s", announced)
+		}
+	}
+	return nil
+}
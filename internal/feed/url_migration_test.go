@@ -0,0 +1,344 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// movedFeedServer serves /old.xml and /new.xml with handlers for the old URL and the RSS
+// of the new one
+func movedFeedServer(t *testing.T, old http.HandlerFunc, newRSS string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old.xml", old)
+	mux.HandleFunc("/new.xml", func(w http.ResponseWriter, r *http.Request) {
+		if newRSS == "" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(newRSS))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func redirectToNew(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Location", "/new.xml")
+	w.WriteHeader(http.StatusMovedPermanently)
+}
+
+func serveRSS(rss string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(rss))
+	}
+}
+
+// selfLinkedRSS returns an RSS feed with an atom:link rel="self" and a single item
+func selfLinkedRSS(self string) string {
+	return `<?xml version="1.0"?><rss xmlns:atom="http://www.w3.org/2005/Atom"><channel><title>Moved</title>` +
+		`<atom:link href="` + self + `" rel="self" type="application/rss+xml"/>` +
+		`<item><title>first</title><link>https://example.com/1</link><guid>1</guid></item>` +
+		`</channel></rss>`
+}
+
+// fetchFeed adds a feed and fetches it once, returning its ID
+func fetchFeed(t *testing.T, db *database.DB, f *Fetcher, feed models.Feed) int64 {
+	t.Helper()
+	id, err := db.AddFeed(&feed)
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	stored, err := db.GetFeedByID(id)
+	if err != nil {
+		t.Fatalf("GetFeedByID error: %v", err)
+	}
+	tm := f.GetTaskManager()
+	report, err := tm.fetchAttempt(context.Background(), *stored, 1)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	tm.recordRefresh(context.Background(), *stored, report, err)
+	return id
+}
+
+func assertFeedURL(t *testing.T, db *database.DB, id int64, want string) {
+	t.Helper()
+	feed, err := db.GetFeedByID(id)
+	if err != nil {
+		t.Fatalf("GetFeedByID error: %v", err)
+	}
+	if feed.URL != want {
+		t.Errorf("expected feed URL %s, got %s", want, feed.URL)
+	}
+}
+
+func TestMigrateFeedURL_PermanentRedirect(t *testing.T) {
+	db := setupDBForFeedTests(t)
+	srv := movedFeedServer(t, redirectToNew, selfLinkedRSS("/new.xml"))
+	f := NewFetcher(db)
+
+	id := fetchFeed(t, db, f, models.Feed{Title: "moved", URL: srv.URL + "/old.xml"})
+	assertFeedURL(t, db, id, srv.URL+"/new.xml")
+
+	history, err := db.GetFeedURLHistory(id)
+	if err != nil {
+		t.Fatalf("GetFeedURLHistory error: %v", err)
+	}
+	if len(history) != 1 || history[0].OldURL != srv.URL+"/old.xml" || history[0].Reason != urlChangeRedirect {
+		t.Errorf("unexpected history: %+v", history)
+	}
+
+	// The fetch log keeps the redirect, the health no longer reports it
+	entries, err := db.GetFeedFetchLog(id, 10)
+	if err != nil {
+		t.Fatalf("GetFeedFetchLog error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].RedirectURL != srv.URL+"/new.xml" || entries[0].NewArticles != 1 {
+		t.Errorf("unexpected log entries: %+v", entries)
+	}
+	h, err := db.GetFeedHealth(id)
+	if err != nil {
+		t.Fatalf("GetFeedHealth error: %v", err)
+	}
+	if h.RedirectURL != "" {
+		t.Errorf("expected no pending redirect after the move, got %q", h.RedirectURL)
+	}
+}
+
+func TestMigrateFeedURL_AnnouncedURL(t *testing.T) {
+	newFeedURLRSS := `<?xml version="1.0"?><rss xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel><title>Podcast</title>` +
+		`<itunes:new-feed-url>/new.xml</itunes:new-feed-url>` +
+		`<item><title>episode</title><guid>1</guid></item>` +
+		`</channel></rss>`
+
+	tests := []struct {
+		name   string
+		oldRSS string
+		newRSS string
+		moved  bool
+		reason string
+	}{
+		{name: "self link", oldRSS: selfLinkedRSS("/new.xml"), newRSS: selfLinkedRSS("/new.xml"), moved: true, reason: urlChangeSelfLink},
+		{name: "new feed url", oldRSS: newFeedURLRSS, newRSS: selfLinkedRSS("/new.xml"), moved: true, reason: urlChangeNewFeedURL},
+		{name: "unreachable", oldRSS: selfLinkedRSS("/new.xml")},
+		{name: "announces another", oldRSS: selfLinkedRSS("/new.xml"), newRSS: selfLinkedRSS("/other.xml")},
+		{name: "own url", oldRSS: selfLinkedRSS("/old.xml")},
+		{name: "own url with trailing slash", oldRSS: selfLinkedRSS("/old.xml/")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupDBForFeedTests(t)
+			srv := movedFeedServer(t, serveRSS(tt.oldRSS), tt.newRSS)
+			f := NewFetcher(db)
+
+			id := fetchFeed(t, db, f, models.Feed{Title: "announced", URL: srv.URL + "/old.xml"})
+			history, err := db.GetFeedURLHistory(id)
+			if err != nil {
+				t.Fatalf("GetFeedURLHistory error: %v", err)
+			}
+			if !tt.moved {
+				assertFeedURL(t, db, id, srv.URL+"/old.xml")
+				if len(history) != 0 {
+					t.Errorf("expected no history, got %+v", history)
+				}
+				return
+			}
+			assertFeedURL(t, db, id, srv.URL+"/new.xml")
+			if len(history) != 1 || history[0].Reason != tt.reason {
+				t.Errorf("unexpected history: %+v", history)
+			}
+		})
+	}
+}
+
+func TestMigrateFeedURL_MergesIntoSubscription(t *testing.T) {
+	db := setupDBForFeedTests(t)
+	srv := movedFeedServer(t, redirectToNew, selfLinkedRSS("/new.xml"))
+	f := NewFetcher(db)
+
+	existingID, err := db.AddFeed(&models.Feed{Title: "existing", URL: srv.URL + "/new.xml"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	id := fetchFeed(t, db, f, models.Feed{Title: "moved", URL: srv.URL + "/old.xml"})
+
+	if _, err := db.GetFeedByID(id); err == nil {
+		t.Error("expected the moved feed to be merged and deleted")
+	}
+	articles, err := db.GetArticles("", existingID, "", true, 10, 0)
+	if err != nil {
+		t.Fatalf("GetArticles error: %v", err)
+	}
+	if len(articles) != 1 {
+		t.Errorf("expected the fetched article in the existing feed, got %d", len(articles))
+	}
+	history, err := db.GetFeedURLHistory(existingID)
+	if err != nil {
+		t.Fatalf("GetFeedURLHistory error: %v", err)
+	}
+	if len(history) != 1 || history[0].MergedTitle != "moved" || history[0].OldURL != srv.URL+"/old.xml" {
+		t.Errorf("unexpected history: %+v", history)
+	}
+	entries, err := db.GetFeedFetchLog(existingID, 10)
+	if err != nil {
+		t.Fatalf("GetFeedFetchLog error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected the fetch to be logged for the existing feed, got %d entries", len(entries))
+	}
+}
+
+func TestMigrateFeedURL_OtherSite(t *testing.T) {
+	// The feed at 127.0.0.1 announces its new URL at localhost, another site
+	var mu sync.Mutex
+	var keys []string
+	var otherSite string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old.xml", func(w http.ResponseWriter, r *http.Request) {
+		serveRSS(selfLinkedRSS(otherSite+"/new.xml"))(w, r)
+	})
+	mux.HandleFunc("/new.xml", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get("X-Api-Key"))
+		mu.Unlock()
+		serveRSS(selfLinkedRSS(otherSite+"/new.xml"))(w, r)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	otherSite = strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	t.Run("not followed by default", func(t *testing.T) {
+		db := setupDBForFeedTests(t)
+		id := fetchFeed(t, db, NewFetcher(db), models.Feed{Title: "announced", URL: srv.URL + "/old.xml"})
+		assertFeedURL(t, db, id, srv.URL+"/old.xml")
+	})
+
+	t.Run("followed when enabled", func(t *testing.T) {
+		db := setupDBForFeedTests(t)
+		db.SetSetting("feed_url_migrate_other_sites", "true")
+		id := fetchFeed(t, db, NewFetcher(db), models.Feed{Title: "announced", URL: srv.URL + "/old.xml"})
+		assertFeedURL(t, db, id, otherSite+"/new.xml")
+	})
+
+	t.Run("never with HTTP options", func(t *testing.T) {
+		db := setupDBForFeedTests(t)
+		db.SetSetting("feed_url_migrate_other_sites", "true")
+		id, err := db.AddFeed(&models.Feed{Title: "members", URL: srv.URL + "/old.xml"})
+		if err != nil {
+			t.Fatalf("AddFeed error: %v", err)
+		}
+		db.SaveFeedHTTPOptions(&models.FeedHTTPOptions{FeedID: id, Headers: "X-Api-Key: secret"})
+		stored, _ := db.GetFeedByID(id)
+		tm := NewFetcher(db).GetTaskManager()
+		report, err := tm.fetchAttempt(context.Background(), *stored, 1)
+		if err != nil {
+			t.Fatalf("fetch error: %v", err)
+		}
+		tm.recordRefresh(context.Background(), *stored, report, err)
+
+		assertFeedURL(t, db, id, srv.URL+"/old.xml")
+		mu.Lock()
+		defer mu.Unlock()
+		for _, key := range keys {
+			if key != "" {
+				t.Errorf("Expected the other site not to get the HTTP options, got X-Api-Key %q", key)
+			}
+		}
+	})
+}
+
+func TestNewFeedURL_AnnouncedURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		feedURL    string
+		selfLink   string
+		otherSites bool
+		want       string
+	}{
+		{name: "downgrade", feedURL: "https://example.com/feed.xml", selfLink: "http://example.com/feed.xml"},
+		{name: "downgrade to another path", feedURL: "https://example.com/feed.xml", selfLink: "http://example.com/rss.xml"},
+		{name: "trailing slash", feedURL: "https://example.com/feed", selfLink: "https://example.com/feed/"},
+		{name: "www", feedURL: "https://example.com/feed", selfLink: "https://www.example.com/feed"},
+		{name: "upgrade", feedURL: "http://example.com/feed.xml", selfLink: "https://example.com/feed.xml", want: "https://example.com/feed.xml"},
+		{name: "same site", feedURL: "https://example.com/feed.xml", selfLink: "https://feeds.example.com/feed.xml", want: "https://feeds.example.com/feed.xml"},
+		{name: "other site", feedURL: "https://example.com/feed.xml", selfLink: "https://feeds.feedburner.com/example"},
+		{name: "other site enabled", feedURL: "https://example.com/feed.xml", selfLink: "https://feeds.feedburner.com/example", otherSites: true, want: "https://feeds.feedburner.com/example"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupDBForFeedTests(t)
+			db.SetSetting("feed_url_migrate_other_sites", strconv.FormatBool(tt.otherSites))
+			f := NewFetcher(db)
+
+			got, _ := f.newFeedURL(context.Background(), models.Feed{URL: tt.feedURL}, &gofeed.Feed{FeedLink: tt.selfLink})
+			if got != tt.want {
+				t.Errorf("newFeedURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsFeedURLChange(t *testing.T) {
+	tests := []struct {
+		feedURL, newURL string
+		want            bool
+	}{
+		{"https://example.com/feed", "https://example.com/feed/", false},
+		{"https://Example.com/feed", "https://www.example.com:443/feed", false},
+		{"https://example.com/feed", "http://example.com/feed", false},
+		{"https://example.com/feed", "http://example.com/other", false},
+		{"http://example.com/feed", "https://example.com/feed", true},
+		{"https://example.com/feed", "https://example.com/feed?format=rss", true},
+		{"https://example.com/feed", "https://example.org/feed", true},
+	}
+	for _, tt := range tests {
+		if got := isFeedURLChange(tt.feedURL, tt.newURL); got != tt.want {
+			t.Errorf("isFeedURLChange(%q, %q) = %v, want %v", tt.feedURL, tt.newURL, got, tt.want)
+		}
+	}
+}
+
+func TestMigrateFeedURL_Disabled(t *testing.T) {
+	db := setupDBForFeedTests(t)
+	srv := movedFeedServer(t, serveRSS(selfLinkedRSS("/new.xml")), selfLinkedRSS("/new.xml"))
+	if err := db.SetSetting("feed_url_auto_migrate", "false"); err != nil {
+		t.Fatalf("SetSetting error: %v", err)
+	}
+	f := NewFetcher(db)
+
+	id := fetchFeed(t, db, f, models.Feed{Title: "announced", URL: srv.URL + "/old.xml"})
+	assertFeedURL(t, db, id, srv.URL+"/old.xml")
+}
+
+func TestResolveFeedURL(t *testing.T) {
+	tests := []struct {
+		announced string
+		want      string
+		ok        bool
+	}{
+		{"https://example.org/feed", "https://example.org/feed", true},
+		{"/rss.xml", "https://example.com/rss.xml", true},
+		{" new.xml ", "https://example.com/blog/new.xml", true},
+		{"feed://example.org/feed", "", false},
+		{"mailto:someone@example.com", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := resolveFeedURL("https://example.com/blog/feed.xml", tt.announced)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("resolveFeedURL(%q) = %q, %v, want %q, %v", tt.announced, got, ok, tt.want, tt.ok)
+		}
+	}
+}
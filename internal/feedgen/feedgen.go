@@ -0,0 +1,406 @@
+// Package feedgen renders lists of articles as RSS 2.0, Atom 1.0 and JSON Feed 1.1 documents.
+package feedgen
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+// Content types of the generated documents
+const (
+	ContentTypeRSS  = "application/rss+xml; charset=utf-8"
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+	ContentTypeJSON = "application/feed+json; charset=utf-8"
+)
+
+// Feed is a format-independent description of a generated feed
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // Human-readable page of the feed
+	FeedURL     string // URL the feed is served from
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item is a single entry of a generated feed
+type Item struct {
+	ID            string
+	Title         string // Display title, translated when a translation is available
+	OriginalTitle string // Untranslated title, set only when it differs from Title
+	URL           string
+	Author        string
+	Source        string // Title of the feed the article came from
+	Summary       string // Plain-text AI summary
+	ContentHTML   string
+	ImageURL      string
+	AudioURL      string
+	VideoURL      string
+	Published     time.Time
+	Tags          []string
+}
+
+// Format is an output format
+type Format string
+
+// Supported output formats
+const (
+	FormatRSS  Format = "rss"
+	FormatAtom Format = "atom"
+	FormatJSON Format = "json"
+)
+
+// FormatFromExtension returns the format served for a file extension (".xml", ".rss", ".atom", ".json")
+func FormatFromExtension(ext string) (Format, bool) {
+	switch strings.ToLower(strings.TrimPrefix(ext, ".")) {
+	case "xml", "rss":
+		return FormatRSS, true
+	case "atom":
+		return FormatAtom, true
+	case "json":
+		return FormatJSON, true
+	}
+	return "", false
+}
+
+// Render renders a feed in the given format and returns the document and its content type
+func Render(feed *Feed, format Format) ([]byte, string, error) {
+	switch format {
+	case FormatAtom:
+		data, err := Atom(feed)
+		return data, ContentTypeAtom, err
+	case FormatJSON:
+		data, err := JSON(feed)
+		return data, ContentTypeJSON, err
+	default:
+		data, err := RSS(feed)
+		return data, ContentTypeRSS, err
+	}
+}
+
+// RSS renders a feed as RSS 2.0
+func RSS(feed *Feed) ([]byte, error) {
+	type guid struct {
+		IsPermaLink string `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	}
+	type enclosure struct {
+		URL    string `xml:"url,attr"`
+		Type   string `xml:"type,attr"`
+		Length string `xml:"length,attr"`
+	}
+	type atomLink struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+		Type string `xml:"type,attr"`
+	}
+	type item struct {
+		Title       string     `xml:"title"`
+		Link        string     `xml:"link,omitempty"`
+		GUID        guid       `xml:"guid"`
+		PubDate     string     `xml:"pubDate,omitempty"`
+		Author      string     `xml:"dc:creator,omitempty"`
+		Categories  []string   `xml:"category"`
+		Description string     `xml:"description,omitempty"`
+		Content     *cdata     `xml:"content:encoded,omitempty"`
+		Enclosure   *enclosure `xml:"enclosure,omitempty"`
+	}
+	type channel struct {
+		Title         string   `xml:"title"`
+		Link          string   `xml:"link"`
+		Description   string   `xml:"description"`
+		AtomLink      atomLink `xml:"atom:link"`
+		LastBuildDate string   `xml:"lastBuildDate"`
+		Generator     string   `xml:"generator"`
+		Items         []item   `xml:"item"`
+	}
+	type rss struct {
+		XMLName   xml.Name `xml:"rss"`
+		Version   string   `xml:"version,attr"`
+		AtomNS    string   `xml:"xmlns:atom,attr"`
+		DCNS      string   `xml:"xmlns:dc,attr"`
+		ContentNS string   `xml:"xmlns:content,attr"`
+		Channel   channel  `xml:"channel"`
+	}
+
+	doc := rss{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel: channel{
+			Title:         feed.Title,
+			Link:          feed.Link,
+			Description:   feed.Description,
+			AtomLink:      atomLink{Href: feed.FeedURL, Rel: "self", Type: "application/rss+xml"},
+			LastBuildDate: updated(feed).Format(time.RFC1123Z),
+			Generator:     "MrRSS",
+		},
+	}
+	for _, it := range feed.Items {
+		i := item{
+			Title:       it.Title,
+			Link:        it.URL,
+			GUID:        guid{IsPermaLink: "false", Value: it.ID},
+			Author:      it.Author,
+			Categories:  it.Tags,
+			Description: it.Summary,
+		}
+		if !it.Published.IsZero() {
+			i.PubDate = it.Published.Format(time.RFC1123Z)
+		}
+		if content := itemContent(it); content != "" {
+			i.Content = &cdata{Value: content}
+		}
+		if url, typ := itemEnclosure(it); url != "" {
+			i.Enclosure = &enclosure{URL: url, Type: typ, Length: "0"}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, i)
+	}
+
+	return marshalXML(doc)
+}
+
+// Atom renders a feed as Atom 1.0
+func Atom(feed *Feed) ([]byte, error) {
+	type link struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr,omitempty"`
+		Type string `xml:"type,attr,omitempty"`
+	}
+	type text struct {
+		Type  string `xml:"type,attr,omitempty"`
+		Value string `xml:",chardata"`
+	}
+	type person struct {
+		Name string `xml:"name"`
+	}
+	type category struct {
+		Term string `xml:"term,attr"`
+	}
+	type source struct {
+		Title string `xml:"title"`
+	}
+	type entry struct {
+		Title      string     `xml:"title"`
+		ID         string     `xml:"id"`
+		Links      []link     `xml:"link"`
+		Published  string     `xml:"published,omitempty"`
+		Updated    string     `xml:"updated"`
+		Author     *person    `xml:"author,omitempty"`
+		Categories []category `xml:"category"`
+		Source     *source    `xml:"source,omitempty"`
+		Summary    *text      `xml:"summary,omitempty"`
+		Content    *text      `xml:"content,omitempty"`
+	}
+	type atomFeed struct {
+		XMLName   xml.Name `xml:"feed"`
+		NS        string   `xml:"xmlns,attr"`
+		Title     string   `xml:"title"`
+		Subtitle  string   `xml:"subtitle,omitempty"`
+		ID        string   `xml:"id"`
+		Links     []link   `xml:"link"`
+		Updated   string   `xml:"updated"`
+		Generator string   `xml:"generator"`
+		Entries   []entry  `xml:"entry"`
+	}
+
+	doc := atomFeed{
+		NS:        "http://www.w3.org/2005/Atom",
+		Title:     feed.Title,
+		Subtitle:  feed.Description,
+		ID:        feed.FeedURL,
+		Links:     []link{{Href: feed.FeedURL, Rel: "self", Type: "application/atom+xml"}},
+		Updated:   updated(feed).Format(time.RFC3339),
+		Generator: "MrRSS",
+	}
+	if feed.Link != "" {
+		doc.Links = append(doc.Links, link{Href: feed.Link, Rel: "alternate", Type: "text/html"})
+	}
+
+	for _, it := range feed.Items {
+		e := entry{
+			Title:   it.Title,
+			ID:      it.ID,
+			Updated: it.Published.Format(time.RFC3339),
+		}
+		if it.URL != "" {
+			e.Links = append(e.Links, link{Href: it.URL, Rel: "alternate", Type: "text/html"})
+		}
+		if url, typ := itemEnclosure(it); url != "" {
+			e.Links = append(e.Links, link{Href: url, Rel: "enclosure", Type: typ})
+		}
+		if !it.Published.IsZero() {
+			e.Published = it.Published.Format(time.RFC3339)
+		} else {
+			e.Updated = updated(feed).Format(time.RFC3339)
+		}
+		if it.Author != "" {
+			e.Author = &person{Name: it.Author}
+		}
+		for _, tag := range it.Tags {
+			e.Categories = append(e.Categories, category{Term: tag})
+		}
+		if it.Source != "" {
+			e.Source = &source{Title: it.Source}
+		}
+		if it.Summary != "" {
+			e.Summary = &text{Type: "text", Value: it.Summary}
+		}
+		if content := itemContent(it); content != "" {
+			e.Content = &text{Type: "html", Value: content}
+		}
+		doc.Entries = append(doc.Entries, e)
+	}
+
+	return marshalXML(doc)
+}
+
+// JSONFeedVersion is the version URL of the JSON Feed format
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// JSONFeed is a JSON Feed 1.1 document
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+// JSONFeedItem is an item of a JSON Feed 1.1 document
+type JSONFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	ContentHTML   string               `json:"content_html,omitempty"`
+	ContentText   string               `json:"content_text,omitempty"`
+	Summary       string               `json:"summary,omitempty"`
+	Image         string               `json:"image,omitempty"`
+	DatePublished string               `json:"date_published,omitempty"`
+	Authors       []JSONFeedAuthor     `json:"authors,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Attachments   []JSONFeedAttachment `json:"attachments,omitempty"`
+	MrRSS         *JSONFeedExtension   `json:"_mrrss,omitempty"`
+}
+
+// JSONFeedAuthor is an author of a JSON Feed item
+type JSONFeedAuthor struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// JSONFeedAttachment is a media attachment of a JSON Feed item
+type JSONFeedAttachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+}
+
+// JSONFeedExtension carries MrRSS-specific item data. Custom JSON Feed extensions start with an underscore.
+type JSONFeedExtension struct {
+	OriginalTitle string `json:"original_title,omitempty"`
+	Source        string `json:"source,omitempty"`
+}
+
+// JSON renders a feed as JSON Feed 1.1
+func JSON(feed *Feed) ([]byte, error) {
+	doc := JSONFeed{
+		Version:     JSONFeedVersion,
+		Title:       feed.Title,
+		HomePageURL: feed.Link,
+		FeedURL:     feed.FeedURL,
+		Description: feed.Description,
+		Items:       make([]JSONFeedItem, 0, len(feed.Items)),
+	}
+
+	for _, it := range feed.Items {
+		i := JSONFeedItem{
+			ID:          it.ID,
+			URL:         it.URL,
+			Title:       it.Title,
+			ContentHTML: it.ContentHTML,
+			Summary:     it.Summary,
+			Image:       it.ImageURL,
+			Tags:        it.Tags,
+		}
+		if i.ContentHTML == "" {
+			i.ContentText = it.Summary
+		}
+		if !it.Published.IsZero() {
+			i.DatePublished = it.Published.Format(time.RFC3339)
+		}
+		if it.Author != "" {
+			i.Authors = []JSONFeedAuthor{{Name: it.Author}}
+		}
+		if url, typ := itemEnclosure(it); url != "" {
+			i.Attachments = []JSONFeedAttachment{{URL: url, MimeType: typ}}
+		}
+		if it.OriginalTitle != "" || it.Source != "" {
+			i.MrRSS = &JSONFeedExtension{OriginalTitle: it.OriginalTitle, Source: it.Source}
+		}
+		doc.Items = append(doc.Items, i)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// cdata wraps HTML content in a CDATA section
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+// itemContent returns the HTML body of an item, falling back to its summary
+func itemContent(it Item) string {
+	if it.ContentHTML != "" {
+		return it.ContentHTML
+	}
+	if it.ImageURL != "" {
+		return `<img src="` + xmlEscape(it.ImageURL) + `">`
+	}
+	return ""
+}
+
+// itemEnclosure returns the media attachment of an item and its MIME type
+func itemEnclosure(it Item) (string, string) {
+	switch {
+	case it.AudioURL != "":
+		return it.AudioURL, "audio/mpeg"
+	case it.VideoURL != "":
+		return it.VideoURL, "video/mp4"
+	}
+	return "", ""
+}
+
+// updated returns the feed's update time, defaulting to its newest item
+func updated(feed *Feed) time.Time {
+	if !feed.Updated.IsZero() {
+		return feed.Updated
+	}
+	var latest time.Time
+	for _, it := range feed.Items {
+		if it.Published.After(latest) {
+			latest = it.Published
+		}
+	}
+	if latest.IsZero() {
+		return time.Now()
+	}
+	return latest
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,118 @@
+package feedgen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func testFeed() *Feed {
+	published := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &Feed{
+		Title:   "Shared <stream>",
+		Link:    "https://mrrss.example.com/",
+		FeedURL: "https://mrrss.example.com/feeds/favorites.xml",
+		Items: []Item{
+			{
+				ID:            "urn:mrrss:article:1",
+				Title:         "Übersetzter Titel",
+				OriginalTitle: "Translated title",
+				URL:           "https://example.com/post?a=1&b=2",
+				Author:        "Ada",
+				Source:        "Example",
+				Summary:       "A short summary.",
+				ContentHTML:   "<p>Body with ]]> inside</p>",
+				AudioURL:      "https://example.com/episode.mp3",
+				Published:     published,
+				Tags:          []string{"go", "feeds"},
+			},
+		},
+	}
+}
+
+func TestRSS(t *testing.T) {
+	data, err := RSS(testFeed())
+	if err != nil {
+		t.Fatalf("RSS failed: %v", err)
+	}
+
+	parsed, err := gofeed.NewParser().ParseString(string(data))
+	if err != nil {
+		t.Fatalf("Generated RSS does not parse: %v", err)
+	}
+	if parsed.FeedType != "rss" || parsed.Title != "Shared <stream>" || len(parsed.Items) != 1 {
+		t.Fatalf("Unexpected feed: %+v", parsed)
+	}
+	item := parsed.Items[0]
+	if item.Title != "Übersetzter Titel" || item.Link != "https://example.com/post?a=1&b=2" || item.GUID != "urn:mrrss:article:1" {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+	if item.Description != "A short summary." || !strings.Contains(item.Content, "]]> inside") {
+		t.Errorf("Unexpected summary or content: %q / %q", item.Description, item.Content)
+	}
+	if len(item.Enclosures) != 1 || item.Enclosures[0].URL != "https://example.com/episode.mp3" {
+		t.Errorf("Expected audio enclosure, got %+v", item.Enclosures)
+	}
+	if item.PublishedParsed == nil || !item.PublishedParsed.Equal(testFeed().Items[0].Published) {
+		t.Errorf("Unexpected published date: %v", item.PublishedParsed)
+	}
+}
+
+func TestAtom(t *testing.T) {
+	data, err := Atom(testFeed())
+	if err != nil {
+		t.Fatalf("Atom failed: %v", err)
+	}
+
+	parsed, err := gofeed.NewParser().ParseString(string(data))
+	if err != nil {
+		t.Fatalf("Generated Atom does not parse: %v", err)
+	}
+	if parsed.FeedType != "atom" || len(parsed.Items) != 1 {
+		t.Fatalf("Unexpected feed: %+v", parsed)
+	}
+	item := parsed.Items[0]
+	if item.Title != "Übersetzter Titel" || item.Link != "https://example.com/post?a=1&b=2" || len(item.Categories) != 2 {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+	if item.Content != "<p>Body with ]]> inside</p>" {
+		t.Errorf("Unexpected content: %q", item.Content)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := JSON(testFeed())
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+
+	var doc JSONFeed
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Generated JSON Feed does not parse: %v", err)
+	}
+	if doc.Version != JSONFeedVersion || len(doc.Items) != 1 {
+		t.Fatalf("Unexpected feed: %+v", doc)
+	}
+	item := doc.Items[0]
+	if item.DatePublished != "2025-03-01T12:00:00Z" || len(item.Attachments) != 1 || item.Attachments[0].MimeType != "audio/mpeg" {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+	if item.MrRSS == nil || item.MrRSS.OriginalTitle != "Translated title" {
+		t.Errorf("Expected original title extension, got %+v", item.MrRSS)
+	}
+}
+
+func TestFormatFromExtension(t *testing.T) {
+	tests := map[string]Format{".xml": FormatRSS, ".rss": FormatRSS, ".atom": FormatAtom, ".JSON": FormatJSON}
+	for ext, want := range tests {
+		if got, ok := FormatFromExtension(ext); !ok || got != want {
+			t.Errorf("FormatFromExtension(%q) = %q, %v; want %q", ext, got, ok, want)
+		}
+	}
+	if _, ok := FormatFromExtension(".html"); ok {
+		t.Error("Expected .html to be unsupported")
+	}
+}
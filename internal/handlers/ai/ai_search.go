@@ -6,18 +6,32 @@ import (
 	"log"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"MrRSS/internal/ai"
 	"MrRSS/internal/config"
+	"MrRSS/internal/database"
 	"MrRSS/internal/handlers/core"
 	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
 )
 
+// Search modes
+const (
+	SearchModeKeywords = "keywords" // AI keyword expansion and full-text search (default)
+	SearchModeSemantic = "semantic" // Embedding similarity search
+)
+
+// semanticSearchLimit is the number of closest articles returned by semantic search
+const semanticSearchLimit = 50
+
 // AISearchRequest represents the request for AI-powered search
 type AISearchRequest struct {
 	Query string `json:"query"`
+	Mode  string `json:"mode,omitempty"` // "keywords" (default) or "semantic"
 }
 
 // AISearchResponse represents the response from AI search
@@ -47,7 +61,7 @@ func extractSearchTerms(response string) string {
 type SearchTerms struct {
 	Required []string `json:"required"` // Must match at least one
 	Optional []string `json:"optional"` // Boost relevance if matched
-	Patterns []string `json:"patterns"` // LIKE patterns like "详解%llm"
+	Patterns []string `json:"patterns"` // Multi-part patterns like "详解%llm"
 }
 
 // parseSearchTermsAdvanced parses JSON object with required/optional/patterns from AI response
@@ -87,13 +101,14 @@ func buildAISearchPrompt() string {
 {
   "required": ["must-match keywords - core topic"],
   "optional": ["nice-to-have keywords - related/synonyms"],
-  "patterns": ["SQL LIKE patterns with % wildcards"]
+  "patterns": ["multi-part phrases with % between parts that must all appear"]
 }
 
 Rules:
 - required: Core topic keywords that MUST appear (2-5 terms)
 - optional: Related terms for better ranking (3-8 terms)
-- patterns: Specific phrase patterns using % as wildcard (0-3 patterns)
+- patterns: Specific phrase patterns using % as separator (0-3 patterns)
+- Every keyword or pattern part must be at least 3 characters long
 - Include English and Chinese terms where applicable
 
 Examples:
@@ -104,98 +119,83 @@ Input: "Python web框架"
 Output: {"required":["Python","web框架","web framework"],"optional":["Django","Flask","FastAPI","后端","backend"],"patterns":["Python%web","Python%框架"]}`
 }
 
-// buildSearchSQL builds the SQL query from search terms with relevance scoring
-func buildSearchSQL(terms *SearchTerms, limit int) string {
-	if terms == nil || (len(terms.Required) == 0 && len(terms.Patterns) == 0) {
+// buildFTSQuery builds an FTS5 query matching any of the required terms or patterns.
+// Pattern parts separated by % must all appear in the article. Terms shorter than
+// three characters are skipped because the trigram index cannot match them.
+func buildFTSQuery(terms *SearchTerms) string {
+	if terms == nil {
 		return ""
 	}
 
-	// Build required conditions (must match at least one)
-	var requiredConditions []string
+	var clauses []string
 	for _, term := range terms.Required {
-		escapedTerm := strings.ReplaceAll(term, "'", "''")
-		condition := fmt.Sprintf(
-			"(a.title LIKE '%%%s%%' OR c.content LIKE '%%%s%%' OR a.summary LIKE '%%%s%%')",
-			escapedTerm, escapedTerm, escapedTerm,
-		)
-		requiredConditions = append(requiredConditions, condition)
+		if phrase := ftsPhrase(term); phrase != "" {
+			clauses = append(clauses, phrase)
+		}
 	}
 
-	// Build pattern conditions
 	for _, pattern := range terms.Patterns {
-		escapedPattern := strings.ReplaceAll(pattern, "'", "''")
-		condition := fmt.Sprintf(
-			"(a.title LIKE '%%%s%%' OR c.content LIKE '%%%s%%' OR a.summary LIKE '%%%s%%')",
-			escapedPattern, escapedPattern, escapedPattern,
-		)
-		requiredConditions = append(requiredConditions, condition)
+		var parts []string
+		for _, part := range strings.Split(pattern, "%") {
+			if phrase := ftsPhrase(part); phrase != "" {
+				parts = append(parts, phrase)
+			}
+		}
+		switch len(parts) {
+		case 0:
+		case 1:
+			clauses = append(clauses, parts[0])
+		default:
+			clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+		}
 	}
 
-	// Build relevance score with weighted scoring
-	var scoreTerms []string
-
-	// Required terms get higher base weight
-	for _, term := range terms.Required {
-		escapedTerm := strings.ReplaceAll(term, "'", "''")
-		scoreTerm := fmt.Sprintf(
-			"(CASE WHEN a.title LIKE '%%%s%%' THEN 5 ELSE 0 END + "+
-				"CASE WHEN c.content LIKE '%%%s%%' THEN 2 ELSE 0 END + "+
-				"CASE WHEN a.summary LIKE '%%%s%%' THEN 3 ELSE 0 END)",
-			escapedTerm, escapedTerm, escapedTerm,
-		)
-		scoreTerms = append(scoreTerms, scoreTerm)
-	}
+	return strings.Join(clauses, " OR ")
+}
 
-	// Patterns get highest weight (exact phrase match)
-	for _, pattern := range terms.Patterns {
-		escapedPattern := strings.ReplaceAll(pattern, "'", "''")
-		scoreTerm := fmt.Sprintf(
-			"(CASE WHEN a.title LIKE '%%%s%%' THEN 8 ELSE 0 END + "+
-				"CASE WHEN c.content LIKE '%%%s%%' THEN 4 ELSE 0 END + "+
-				"CASE WHEN a.summary LIKE '%%%s%%' THEN 5 ELSE 0 END)",
-			escapedPattern, escapedPattern, escapedPattern,
-		)
-		scoreTerms = append(scoreTerms, scoreTerm)
+// ftsPhrase quotes a term as an FTS5 phrase, or returns "" if it is too short to match
+func ftsPhrase(term string) string {
+	term = strings.TrimSpace(term)
+	if utf8.RuneCountInString(term) < 3 {
+		return ""
 	}
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+}
 
-	// Optional terms get lower weight
-	for _, term := range terms.Optional {
-		escapedTerm := strings.ReplaceAll(term, "'", "''")
-		scoreTerm := fmt.Sprintf(
-			"(CASE WHEN a.title LIKE '%%%s%%' THEN 2 ELSE 0 END + "+
-				"CASE WHEN c.content LIKE '%%%s%%' THEN 1 ELSE 0 END + "+
-				"CASE WHEN a.summary LIKE '%%%s%%' THEN 1 ELSE 0 END)",
-			escapedTerm, escapedTerm, escapedTerm,
-		)
-		scoreTerms = append(scoreTerms, scoreTerm)
+// boostOptionalTerms reorders ranked results so that articles whose title or summary
+// mentions more of the optional terms come first. The index order is kept otherwise.
+func boostOptionalTerms(results []database.ArticleSearchResult, optional []string) {
+	if len(optional) == 0 {
+		return
 	}
 
-	relevanceScore := "0"
-	if len(scoreTerms) > 0 {
-		relevanceScore = strings.Join(scoreTerms, " + ")
+	score := func(r database.ArticleSearchResult) int {
+		title := strings.ToLower(r.Title + " " + r.TranslatedTitle)
+		summary := strings.ToLower(r.Summary)
+		total := 0
+		for _, term := range optional {
+			term = strings.ToLower(strings.TrimSpace(term))
+			if term == "" {
+				continue
+			}
+			if strings.Contains(title, term) {
+				total += 2
+			}
+			if strings.Contains(summary, term) {
+				total++
+			}
+		}
+		return total
 	}
 
-	// Build full query with LEFT JOIN to article_contents for content search
-	whereClause := strings.Join(requiredConditions, " OR ")
-	query := fmt.Sprintf(`
-		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url,
-			   a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later,
-			   a.translated_title, a.summary, a.freshrss_item_id, f.title AS feed_title, a.author,
-			   (%s) AS relevance_score
-		FROM articles a
-		JOIN feeds f ON a.feed_id = f.id
-		LEFT JOIN article_contents c ON a.id = c.article_id
-		WHERE a.is_hidden = 0 AND (%s)
-		ORDER BY relevance_score DESC, a.published_at DESC
-		LIMIT %d
-	`, relevanceScore, whereClause, limit)
-
-	return query
+	sort.SliceStable(results, func(i, j int) bool {
+		return score(results[i]) > score(results[j])
+	})
 }
 
 // HandleAISearch handles POST /api/ai/search for AI-powered article search
 // @Summary      AI-powered article search
-// @Description  Use AI to expand keywords and search articles with relevance ranking
+// @Description  Use AI to expand keywords and search articles with relevance ranking, or rank articles by embedding similarity in semantic mode
 // @Tags         ai
 // @Accept       json
 // @Produce      json
@@ -228,7 +228,12 @@ func HandleAISearch(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("[AI Search] User query: %s", req.Query)
+	log.Printf("[AI Search] User query: %s (mode: %s)", req.Query, req.Mode)
+
+	if req.Mode == SearchModeSemantic {
+		handleSemanticSearch(h, w, r, req.Query)
+		return
+	}
 
 	// Get AI settings - try ProfileProvider first
 	var apiKey, endpoint, model string
@@ -317,12 +322,19 @@ func HandleAISearch(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 	allTerms = append(allTerms, searchTerms.Patterns...)
 	log.Printf("[AI Search] Required: %v, Optional: %v, Patterns: %v", searchTerms.Required, searchTerms.Optional, searchTerms.Patterns)
 
-	// Build and execute search query
-	searchSQL := buildSearchSQL(searchTerms, 100)
-	log.Printf("[AI Search] SQL query:\n%s", searchSQL)
+	// Build and execute the full-text search query
+	ftsQuery := buildFTSQuery(searchTerms)
+	log.Printf("[AI Search] FTS query: %s", ftsQuery)
+	if ftsQuery == "" {
+		response.JSON(w, AISearchResponse{
+			Success:     false,
+			Error:       "Search terms are too short for full-text search",
+			SearchTerms: strings.Join(allTerms, ", "),
+		})
+		return
+	}
 
-	// Execute search
-	articles, err := h.DB.SearchArticlesWithSQL(searchSQL)
+	articles, _, err := h.DB.SearchArticles(database.ArticleSearchOptions{Query: ftsQuery, Limit: 100})
 	if err != nil {
 		log.Printf("[AI Search] Query error: %v", err)
 		response.JSON(w, AISearchResponse{
@@ -332,30 +344,14 @@ func HandleAISearch(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	boostOptionalTerms(articles, searchTerms.Optional)
 
 	log.Printf("[AI Search] Found %d articles", len(articles))
 
 	// Convert articles to response format
 	articleMaps := make([]map[string]any, len(articles))
 	for i, article := range articles {
-		articleMaps[i] = map[string]any{
-			"id":               article.ID,
-			"feed_id":          article.FeedID,
-			"title":            article.Title,
-			"url":              article.URL,
-			"image_url":        article.ImageURL,
-			"audio_url":        article.AudioURL,
-			"video_url":        article.VideoURL,
-			"published_at":     article.PublishedAt,
-			"is_read":          article.IsRead,
-			"is_favorite":      article.IsFavorite,
-			"is_hidden":        article.IsHidden,
-			"is_read_later":    article.IsReadLater,
-			"feed_title":       article.FeedTitle,
-			"author":           article.Author,
-			"translated_title": article.TranslatedTitle,
-			"summary":          article.Summary,
-		}
+		articleMaps[i] = searchResultMap(article.Article)
 	}
 
 	response.JSON(w, AISearchResponse{
@@ -365,3 +361,69 @@ func HandleAISearch(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		TotalCount:  len(articles),
 	})
 }
+
+// handleSemanticSearch ranks articles by the similarity of their embeddings to the query
+func handleSemanticSearch(h *core.Handler, w http.ResponseWriter, r *http.Request, query string) {
+	if !h.Embeddings.Enabled() {
+		response.JSON(w, AISearchResponse{
+			Success: false,
+			Error:   "Semantic search requires embeddings. Please enable them in the AI settings first.",
+		})
+		return
+	}
+
+	matches, err := h.Embeddings.Search(r.Context(), query, semanticSearchLimit)
+	if err != nil {
+		log.Printf("[AI Search] Semantic search error: %v", err)
+		response.JSON(w, AISearchResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Semantic search failed: %v", err),
+		})
+		return
+	}
+
+	related, err := relatedArticles(h, matches)
+	if err != nil {
+		response.JSON(w, AISearchResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Search query failed: %v", err),
+		})
+		return
+	}
+
+	log.Printf("[AI Search] Found %d articles by semantic search", len(related))
+
+	articleMaps := make([]map[string]any, len(related))
+	for i, article := range related {
+		articleMaps[i] = searchResultMap(article.Article)
+		articleMaps[i]["score"] = article.Score
+	}
+
+	response.JSON(w, AISearchResponse{
+		Success:    true,
+		Articles:   articleMaps,
+		TotalCount: len(related),
+	})
+}
+
+// searchResultMap converts an article to the search response format
+func searchResultMap(article models.Article) map[string]any {
+	return map[string]any{
+		"id":               article.ID,
+		"feed_id":          article.FeedID,
+		"title":            article.Title,
+		"url":              article.URL,
+		"image_url":        article.ImageURL,
+		"audio_url":        article.AudioURL,
+		"video_url":        article.VideoURL,
+		"published_at":     article.PublishedAt,
+		"is_read":          article.IsRead,
+		"is_favorite":      article.IsFavorite,
+		"is_hidden":        article.IsHidden,
+		"is_read_later":    article.IsReadLater,
+		"feed_title":       article.FeedTitle,
+		"author":           article.Author,
+		"translated_title": article.TranslatedTitle,
+		"summary":          article.Summary,
+	}
+}
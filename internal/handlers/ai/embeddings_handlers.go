@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"MrRSS/internal/embeddings"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+)
+
+const (
+	defaultRelatedLimit = 10
+	maxRelatedLimit     = 50
+)
+
+// RelatedArticle is an article similar in meaning to another article
+type RelatedArticle struct {
+	models.Article
+	Score float32 `json:"score"` // Cosine similarity, 1 for identical meaning
+}
+
+// NewHTTPClientFactory returns a function that creates HTTP clients using the global proxy settings
+func NewHTTPClientFactory(h *core.Handler) func() (*http.Client, error) {
+	return func() (*http.Client, error) {
+		return createHTTPClientWithProxy(h)
+	}
+}
+
+// HandleRelatedArticles handles GET /api/articles/related
+// @Summary      Get related articles
+// @Description  Find the articles closest in meaning to an article using embeddings. The article is embedded first if needed.
+// @Tags         ai
+// @Produce      json
+// @Param        id     query     int  true   "Article ID"
+// @Param        limit  query     int  false  "Maximum number of articles (default 10, max 50)"
+// @Success      200  {array}   RelatedArticle  "Related articles, most similar first"
+// @Failure      400  {object}  map[string]string  "Bad request or embeddings not enabled"
+// @Failure      429  {object}  map[string]string  "AI usage limit reached"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/related [get]
+func HandleRelatedArticles(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Error(w, errors.New("invalid article id"), http.StatusBadRequest)
+		return
+	}
+	limit := defaultRelatedLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxRelatedLimit)
+	}
+
+	if !h.Embeddings.Enabled() {
+		response.Error(w, errors.New("embeddings are disabled"), http.StatusBadRequest)
+		return
+	}
+
+	matches, err := h.Embeddings.Related(r.Context(), id, limit)
+	if err != nil {
+		log.Printf("[Embeddings] Related articles for %d failed: %v", id, err)
+		response.Error(w, err, embeddingErrorStatus(err))
+		return
+	}
+
+	related, err := relatedArticles(h, matches)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, related)
+}
+
+// HandleEmbeddingStatus handles GET /api/ai/embeddings/status
+// @Summary      Get embedding status
+// @Description  Get the embedding model and how many articles have been embedded with it
+// @Tags         ai
+// @Produce      json
+// @Success      200  {object}  embeddings.Status  "Embedding status"
+// @Router       /ai/embeddings/status [get]
+func HandleEmbeddingStatus(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+	response.JSON(w, h.Embeddings.Status())
+}
+
+// HandleClearEmbeddings handles POST /api/ai/embeddings/clear
+// @Summary      Clear embeddings
+// @Description  Delete all stored embeddings. Articles are embedded again in the background if embeddings are enabled.
+// @Tags         ai
+// @Produce      json
+// @Success      200  {object}  map[string]bool  "success"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /ai/embeddings/clear [post]
+func HandleClearEmbeddings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+	if err := h.DB.ClearArticleEmbeddings(); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	h.Embeddings.Wake()
+	response.JSON(w, map[string]bool{"success": true})
+}
+
+// relatedArticles loads the matched articles, keeping the order of the matches
+func relatedArticles(h *core.Handler, matches []embeddings.Match) ([]RelatedArticle, error) {
+	ids := make([]int64, len(matches))
+	for i, m := range matches {
+		ids[i] = m.ArticleID
+	}
+	articles, err := h.DB.GetArticlesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.Article, len(articles))
+	for _, a := range articles {
+		byID[a.ID] = a
+	}
+
+	related := make([]RelatedArticle, 0, len(matches))
+	for _, m := range matches {
+		if a, ok := byID[m.ArticleID]; ok {
+			related = append(related, RelatedArticle{Article: a, Score: m.Score})
+		}
+	}
+	return related, nil
+}
+
+// embeddingErrorStatus maps embeddings errors to HTTP status codes
+func embeddingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, embeddings.ErrNotConfigured):
+		return http.StatusBadRequest
+	case errors.Is(err, embeddings.ErrLimitReached):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
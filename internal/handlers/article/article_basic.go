@@ -67,6 +67,8 @@ func HandleArticles(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		response.Error(w, err, http.StatusInternalServerError)
 		return
 	}
+	attachArticleTags(h, articles)
+	attachStories(h, articles)
 	response.JSON(w, articles)
 }
 
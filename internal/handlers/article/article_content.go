@@ -1,6 +1,7 @@
 package article
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
@@ -114,7 +115,7 @@ func HandleFetchFullArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	}
 
 	// Fetch full content
-	fullContent, err := h.FetchFullArticleContent(article.URL)
+	fullContent, err := h.FetchFullArticleContent(article)
 	if err != nil {
 		log.Printf("Error fetching full article content: %v", err)
 		response.Error(w, err, http.StatusInternalServerError)
@@ -198,3 +199,23 @@ func HandleExtractAllImages(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		"feed_url": feedURL,
 	})
 }
+
+// FetchAndStoreFullContent fetches the full text of an article with readability and
+// replaces the cached feed content with it. It is used by rule actions.
+func FetchAndStoreFullContent(h *core.Handler, articleID int64) error {
+	article, err := h.DB.GetArticleByID(articleID)
+	if err != nil {
+		return err
+	}
+	if article.URL == "" {
+		return fmt.Errorf("article %d has no URL", articleID)
+	}
+
+	fullContent, err := h.FetchFullArticleContent(article)
+	if err != nil {
+		return err
+	}
+
+	h.ContentCache.Set(articleID, fullContent)
+	return h.DB.SetArticleContent(articleID, fullContent)
+}
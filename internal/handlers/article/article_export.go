@@ -2,11 +2,13 @@ package article
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -58,38 +60,89 @@ func HandleExportToObsidian(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	filePath, err := exportArticleToObsidian(h, article)
+	if err != nil {
+		var cfgErr *exportConfigError
+		if errors.As(err, &cfgErr) {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	response.JSON(w, map[string]string{
+		"success":   "true",
+		"file_path": filePath,
+		"message":   "Article exported to Obsidian successfully",
+	})
+}
+
+// exportConfigError reports a missing or invalid export setting
+type exportConfigError struct {
+	msg string
+}
+
+func (e *exportConfigError) Error() string {
+	return e.msg
+}
+
+// ExportArticle exports an article to "obsidian" or "notion". It is used by rule actions.
+func ExportArticle(h *core.Handler, articleID int64, target string) error {
+	article, err := h.DB.GetArticleByID(articleID)
+	if err != nil {
+		return err
+	}
+
+	switch target {
+	case "obsidian":
+		_, err = exportArticleToObsidian(h, article)
+	case "notion":
+		_, err = exportArticleToNotion(h, article)
+	default:
+		err = fmt.Errorf("unknown export target %q", target)
+	}
+	return err
+}
+
+// exportArticleToObsidian writes an article into the configured Obsidian vault and returns the file path
+func exportArticleToObsidian(h *core.Handler, article *models.Article) (string, error) {
 	// Check if Obsidian integration is enabled
 	obsidianEnabled, _ := h.DB.GetSetting("obsidian_enabled")
 	if obsidianEnabled != "true" {
-		response.Error(w, nil, http.StatusBadRequest)
-		return
+		return "", &exportConfigError{"obsidian integration is not enabled"}
 	}
 
 	// Get vault path (required for direct file access)
 	vaultPath, _ := h.DB.GetSetting("obsidian_vault_path")
 	if vaultPath == "" {
-		response.Error(w, nil, http.StatusBadRequest)
-		return
+		return "", &exportConfigError{"obsidian vault path is not configured"}
 	}
 
 	// Validate vault path exists and is a directory
 	if info, err := os.Stat(vaultPath); os.IsNotExist(err) {
-		response.Error(w, nil, http.StatusBadRequest)
-		return
+		return "", &exportConfigError{"obsidian vault path does not exist"}
 	} else if !info.IsDir() {
-		response.Error(w, nil, http.StatusBadRequest)
-		return
+		return "", &exportConfigError{"obsidian vault path is not a directory"}
 	}
 
 	// Get article content
-	content, _, err := h.GetArticleContent(int64(req.ArticleID))
+	content, _, err := h.GetArticleContent(article.ID)
 	if err != nil {
 		// If content fetch fails, continue with empty content
 		content = ""
 	}
 
+	// Include the article's own tags in the front matter
+	if tags, err := h.DB.GetArticleTags(article.ID); err == nil {
+		article.Tags = tags
+	}
+
+	highlights, _ := h.DB.GetHighlightsByArticle(article.ID)
+
 	// Generate Markdown content
-	markdownContent := generateObsidianMarkdown(*article, content)
+	markdownContent := generateObsidianMarkdown(*article, content, highlights)
 
 	// Generate filename (sanitize title)
 	filename := sanitizeFilename(article.Title)
@@ -103,20 +156,13 @@ func HandleExportToObsidian(h *core.Handler, w http.ResponseWriter, r *http.Requ
 
 	// Write file to Obsidian vault
 	if err := os.WriteFile(filePath, []byte(markdownContent), 0644); err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
-		return
+		return "", err
 	}
-
-	// Return success response
-	response.JSON(w, map[string]string{
-		"success":   "true",
-		"file_path": filePath,
-		"message":   "Article exported to Obsidian successfully",
-	})
+	return filePath, nil
 }
 
 // generateObsidianMarkdown converts an article to Markdown format for Obsidian
-func generateObsidianMarkdown(article models.Article, content string) string {
+func generateObsidianMarkdown(article models.Article, content string, highlights []models.Highlight) string {
 	var sb strings.Builder
 
 	// Front matter - exclude URL to avoid URI parsing issues
@@ -124,7 +170,13 @@ func generateObsidianMarkdown(article models.Article, content string) string {
 	sb.WriteString(fmt.Sprintf("title: \"%s\"\n", escapeYamlString(article.Title)))
 	sb.WriteString(fmt.Sprintf("feed: \"%s\"\n", escapeYamlString(article.FeedTitle)))
 	sb.WriteString(fmt.Sprintf("published: \"%s\"\n", article.PublishedAt.Format(time.RFC3339)))
-	sb.WriteString(fmt.Sprintf("tags: [rss, %s]\n", sanitizeTag(article.FeedTitle)))
+	tags := []string{"rss", sanitizeTag(article.FeedTitle)}
+	for _, tag := range article.Tags {
+		if name := sanitizeTag(tag.Name); name != "" && !slices.Contains(tags, name) {
+			tags = append(tags, name)
+		}
+	}
+	sb.WriteString(fmt.Sprintf("tags: [%s]\n", strings.Join(tags, ", ")))
 	sb.WriteString("---\n\n")
 
 	// Title
@@ -137,11 +189,24 @@ func generateObsidianMarkdown(article models.Article, content string) string {
 	if content != "" {
 		// Decode HTML entities first, then convert HTML to Markdown
 		decodedContent := html.UnescapeString(content)
-		markdownContent := htmlToMarkdown(decodedContent)
+		markdownContent := markHighlights(htmlToMarkdown(decodedContent), highlights)
 		sb.WriteString(markdownContent)
 		sb.WriteString("\n\n")
 	}
 
+	// Highlights and notes
+	if len(highlights) > 0 {
+		sb.WriteString("## Highlights\n\n")
+		for _, highlight := range highlights {
+			sb.WriteString(quoteMarkdown(highlight.Quote))
+			sb.WriteString("\n")
+			if highlight.Note != "" {
+				sb.WriteString(fmt.Sprintf("\n%s\n", highlight.Note))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
 	// Add metadata at the end
 	sb.WriteString("---\n")
 	sb.WriteString(fmt.Sprintf("**Added to Obsidian:** %s\n", time.Now().Format("2006-01-02 15:04:05")))
@@ -150,6 +215,28 @@ func generateObsidianMarkdown(article models.Article, content string) string {
 	return sb.String()
 }
 
+// markHighlights wraps the first occurrence of each highlight quote in Obsidian's ==highlight== syntax.
+// Quotes that span formatting do not appear verbatim in the Markdown and are left unmarked.
+func markHighlights(markdown string, highlights []models.Highlight) string {
+	for _, highlight := range highlights {
+		quote := strings.TrimSpace(highlight.Quote)
+		if quote == "" || strings.Contains(quote, "\n") {
+			continue
+		}
+		markdown = strings.Replace(markdown, quote, "=="+quote+"==", 1)
+	}
+	return markdown
+}
+
+// quoteMarkdown formats text as a Markdown blockquote
+func quoteMarkdown(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return strings.Join(lines, "\n")
+}
+
 // sanitizeFilename creates a safe filename from a title
 func sanitizeFilename(title string) string {
 	// Replace invalid filename characters
@@ -176,6 +263,8 @@ func sanitizeTag(feedName string) string {
 	// Remove special characters
 	tag = strings.ReplaceAll(tag, "-", "_")
 	tag = strings.ReplaceAll(tag, ".", "_")
+	// Drop characters that would break the YAML flow sequence
+	tag = strings.NewReplacer(",", "", "[", "", "]", "").Replace(tag)
 	return tag
 }
 
@@ -7,7 +7,9 @@ import (
 	"strings"
 	"time"
 
+	"MrRSS/internal/handlers/core"
 	"MrRSS/internal/models"
+	"MrRSS/internal/utils/urlutil"
 )
 
 // FilterCondition represents a single filter condition from the frontend
@@ -15,7 +17,7 @@ type FilterCondition struct {
 	ID       int64    `json:"id"`
 	Logic    string   `json:"logic"`    // "and", "or" (null for first condition)
 	Negate   bool     `json:"negate"`   // NOT modifier for this condition
-	Field    string   `json:"field"`    // "feed_name", "feed_category", "article_title", "published_after", "published_before"
+	Field    string   `json:"field"`    // "feed_name", "feed_category", "article_tags", "article_title", "published_after", "published_before"
 	Operator string   `json:"operator"` // "contains", "exact" (null for date fields and multi-select)
 	Value    string   `json:"value"`    // Single value for text/date fields
 	Values   []string `json:"values"`   // Multiple values for feed_name and feed_category
@@ -48,16 +50,17 @@ func evaluateArticleConditions(
 	feedArticlesPerMonth map[int64]float64,
 	feedLastUpdateStatus map[int64]string,
 	articleContents map[int64]string,
+	articleTags map[int64][]string,
 ) bool {
 	if len(conditions) == 0 {
 		return true
 	}
 
-	result := evaluateSingleCondition(article, conditions[0], feedCategories, feedTypes, feedIsImageMode, feedTags, feedArticlesPerMonth, feedLastUpdateStatus, articleContents)
+	result := evaluateSingleCondition(article, conditions[0], feedCategories, feedTypes, feedIsImageMode, feedTags, feedArticlesPerMonth, feedLastUpdateStatus, articleContents, articleTags)
 
 	for i := 1; i < len(conditions); i++ {
 		condition := conditions[i]
-		conditionResult := evaluateSingleCondition(article, condition, feedCategories, feedTypes, feedIsImageMode, feedTags, feedArticlesPerMonth, feedLastUpdateStatus, articleContents)
+		conditionResult := evaluateSingleCondition(article, condition, feedCategories, feedTypes, feedIsImageMode, feedTags, feedArticlesPerMonth, feedLastUpdateStatus, articleContents, articleTags)
 
 		switch condition.Logic {
 		case "and":
@@ -70,6 +73,112 @@ func evaluateArticleConditions(
 	return result
 }
 
+// FilterArticles returns the articles matching the saved-filter conditions, in their original order
+func FilterArticles(h *core.Handler, articles []models.Article, conditions []FilterCondition) ([]models.Article, error) {
+	if len(conditions) == 0 {
+		return articles, nil
+	}
+
+	// Get feeds for category lookup
+	feeds, err := h.DB.GetFeeds()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create maps of feed ID to feed data
+	feedCategories := make(map[int64]string)
+	feedTypes := make(map[int64]string)
+	feedIsImageMode := make(map[int64]bool)
+	feedTags := make(map[int64][]string)
+	feedArticlesPerMonth := make(map[int64]float64)
+	feedLastUpdateStatus := make(map[int64]string)
+
+	for _, feed := range feeds {
+		feedCategories[feed.ID] = feed.Category
+		feedTypes[feed.ID] = GetFeedType(&feed)
+		feedIsImageMode[feed.ID] = feed.IsImageMode
+		feedArticlesPerMonth[feed.ID] = feed.ArticlesPerMonth
+		feedLastUpdateStatus[feed.ID] = feed.LastUpdateStatus
+
+		// Build tag names list for this feed
+		tags, _ := h.DB.GetFeedTags(feed.ID)
+		tagNames := make([]string, len(tags))
+		for i, tag := range tags {
+			tagNames[i] = tag.Name
+		}
+		feedTags[feed.ID] = tagNames
+	}
+
+	// Check if any filter condition requires article content
+	needsArticleContent := false
+	for _, condition := range conditions {
+		if condition.Field == "article_content" {
+			needsArticleContent = true
+			break
+		}
+	}
+
+	// Build article content map if needed
+	articleContents := make(map[int64]string)
+	if needsArticleContent {
+		// Collect article IDs
+		articleIDs := make([]int64, len(articles))
+		for i, article := range articles {
+			articleIDs[i] = article.ID
+		}
+
+		// Build placeholders for SQL query
+		placeholders := make([]string, len(articleIDs))
+		args := make([]interface{}, len(articleIDs))
+		for i, id := range articleIDs {
+			placeholders[i] = "?"
+			args[i] = id
+		}
+
+		// Query all article contents at once
+		query := `SELECT article_id, content FROM article_contents WHERE article_id IN (` + strings.Join(placeholders, ",") + `)`
+		rows, err := h.DB.Query(query, args...)
+		if err == nil {
+			defer rows.Close()
+			for rows.Next() {
+				var articleID int64
+				var content string
+				if err := rows.Scan(&articleID, &content); err == nil {
+					articleContents[articleID] = content
+				}
+			}
+		}
+	}
+
+	// Build article tag map if any condition filters by article tags
+	articleTags := make(map[int64][]string)
+	for _, condition := range conditions {
+		if condition.Field == "article_tags" {
+			articleTags = loadArticleTagNames(h, articles)
+			break
+		}
+	}
+
+	var filteredArticles []models.Article
+	for _, article := range articles {
+		if evaluateArticleConditions(
+			article,
+			conditions,
+			feedCategories,
+			feedTypes,
+			feedIsImageMode,
+			feedTags,
+			feedArticlesPerMonth,
+			feedLastUpdateStatus,
+			articleContents,
+			articleTags,
+		) {
+			filteredArticles = append(filteredArticles, article)
+		}
+	}
+	return filteredArticles, nil
+}
+
 // matchMultiSelectContains checks if fieldValue matches any of the selected values using contains logic
 func matchMultiSelectContains(fieldValue string, values []string, singleValue string) bool {
 	if len(values) > 0 {
@@ -86,6 +195,27 @@ func matchMultiSelectContains(fieldValue string, values []string, singleValue st
 	return true
 }
 
+// loadArticleTagNames returns the tag names of the articles, keyed by article ID
+func loadArticleTagNames(h *core.Handler, articles []models.Article) map[int64][]string {
+	ids := make([]int64, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
+	}
+
+	names := make(map[int64][]string)
+	tagsByArticle, err := h.DB.GetTagsForArticles(ids)
+	if err != nil {
+		log.Printf("Error loading article tags for filtering: %v", err)
+		return names
+	}
+	for articleID, tags := range tagsByArticle {
+		for _, tag := range tags {
+			names[articleID] = append(names[articleID], tag.Name)
+		}
+	}
+	return names
+}
+
 // evaluateSingleCondition evaluates a single filter condition for an article
 func evaluateSingleCondition(
 	article models.Article,
@@ -97,6 +227,7 @@ func evaluateSingleCondition(
 	feedArticlesPerMonth map[int64]float64,
 	feedLastUpdateStatus map[int64]string,
 	articleContents map[int64]string,
+	articleTags map[int64][]string,
 ) bool {
 	var result bool
 
@@ -135,6 +266,10 @@ func evaluateSingleCondition(
 			}
 		}
 
+	case "article_tags":
+		// Check if any of the article's own tags matches
+		result = matchMultiSelectTags(articleTags[article.ID], condition.Values, condition.Value)
+
 	case "author":
 		if condition.Value == "" {
 			result = true
@@ -333,6 +468,15 @@ func evaluateSingleCondition(
 			result = (article.VideoURL != "") == wantVideo
 		}
 
+	case "is_youtube_short":
+		// Filter by whether article is a YouTube Short
+		if condition.Value == "" {
+			result = true
+		} else {
+			wantShort := condition.Value == "true"
+			result = urlutil.IsYouTubeShortURL(article.URL) == wantShort
+		}
+
 	case "published_after_hours":
 		// Filter by articles published within the last N hours
 		if condition.Value == "" {
@@ -0,0 +1,269 @@
+package article
+
+import (
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+)
+
+// feedConditionFields are the filter fields that depend only on the feed of an article.
+// They are evaluated once per feed and become a feed ID list in SQL.
+var feedConditionFields = map[string]bool{
+	"feed_name":               true,
+	"feed_category":           true,
+	"feed_tags":               true,
+	"feed_type":               true,
+	"is_image_mode_feed":      true,
+	"feed_articles_per_month": true,
+	"feed_last_update_status": true,
+}
+
+// articleFlagColumns maps boolean filter fields to their article columns
+var articleFlagColumns = map[string]string{
+	"is_read":       "a.is_read",
+	"is_favorite":   "a.is_favorite",
+	"is_hidden":     "a.is_hidden",
+	"is_read_later": "a.is_read_later",
+}
+
+// articlePresenceColumns maps the has_* filter fields to the article columns they check
+var articlePresenceColumns = map[string]string{
+	"has_summary":     "a.summary",
+	"has_translation": "a.translated_title",
+	"has_image":       "a.image_url",
+	"has_audio":       "a.audio_url",
+	"has_video":       "a.video_url",
+}
+
+// FilterSQL converts saved-filter conditions into a SQL condition on the articles (a) and
+// their feeds (f), to be used with database.GetArticlesMatching. It matches the same
+// articles as FilterArticles, combining the conditions from left to right.
+func FilterSQL(h *core.Handler, conditions []FilterCondition) (string, []interface{}, error) {
+	if len(conditions) == 0 {
+		return "1", nil, nil
+	}
+
+	var feeds []models.Feed
+	for _, condition := range conditions {
+		if feedConditionFields[condition.Field] {
+			var err error
+			if feeds, err = h.DB.GetFeeds(); err != nil {
+				return "", nil, err
+			}
+			break
+		}
+	}
+
+	b := &filterSQLBuilder{h: h, feeds: feeds}
+	expr := b.condition(conditions[0])
+	for _, condition := range conditions[1:] {
+		switch condition.Logic {
+		case "and":
+			expr = "(" + expr + " AND " + b.condition(condition) + ")"
+		case "or":
+			expr = "(" + expr + " OR " + b.condition(condition) + ")"
+		}
+	}
+	return expr, b.args, nil
+}
+
+// filterSQLBuilder collects the arguments of the SQL built for filter conditions
+type filterSQLBuilder struct {
+	h     *core.Handler
+	feeds []models.Feed
+	args  []interface{}
+
+	feedTags map[int64][]string
+}
+
+// condition returns the SQL of a single filter condition, including its NOT modifier
+func (b *filterSQLBuilder) condition(condition FilterCondition) string {
+	if feedConditionFields[condition.Field] {
+		// The NOT modifier is applied when evaluating the feeds
+		return b.feedCondition(condition)
+	}
+
+	expr := b.articleCondition(condition)
+	if condition.Negate {
+		return "NOT " + expr
+	}
+	return expr
+}
+
+// feedCondition evaluates a feed-level condition for every feed with evaluateSingleCondition
+// and returns the condition that the article belongs to one of the matching feeds
+func (b *filterSQLBuilder) feedCondition(condition FilterCondition) string {
+	if condition.Field == "feed_tags" && b.feedTags == nil {
+		b.feedTags = make(map[int64][]string)
+		for _, feed := range b.feeds {
+			tags, _ := b.h.DB.GetFeedTags(feed.ID)
+			for _, tag := range tags {
+				b.feedTags[feed.ID] = append(b.feedTags[feed.ID], tag.Name)
+			}
+		}
+	}
+
+	var ids []string
+	for _, feed := range b.feeds {
+		article := models.Article{FeedID: feed.ID, FeedTitle: feed.Title}
+		if evaluateSingleCondition(
+			article,
+			condition,
+			map[int64]string{feed.ID: feed.Category},
+			map[int64]string{feed.ID: GetFeedType(&feed)},
+			map[int64]bool{feed.ID: feed.IsImageMode},
+			b.feedTags,
+			map[int64]float64{feed.ID: feed.ArticlesPerMonth},
+			map[int64]string{feed.ID: feed.LastUpdateStatus},
+			nil,
+			nil,
+		) {
+			ids = append(ids, strconv.FormatInt(feed.ID, 10))
+		}
+	}
+	if len(ids) == 0 {
+		return "0"
+	}
+	return "a.feed_id IN (" + strings.Join(ids, ",") + ")"
+}
+
+// articleCondition returns the SQL of an article-level condition without its NOT modifier
+func (b *filterSQLBuilder) articleCondition(condition FilterCondition) string {
+	if column, ok := articleFlagColumns[condition.Field]; ok {
+		if condition.Value == "" {
+			return "1"
+		}
+		return b.arg("COALESCE("+column+", 0) = ?", condition.Value == "true")
+	}
+	if column, ok := articlePresenceColumns[condition.Field]; ok {
+		if condition.Value == "" {
+			return "1"
+		}
+		if condition.Value == "true" {
+			return "COALESCE(" + column + ", '') != ''"
+		}
+		return "COALESCE(" + column + ", '') = ''"
+	}
+
+	switch condition.Field {
+	case "article_title":
+		return b.textMatch("COALESCE(a.title, '')", condition)
+
+	case "author":
+		return b.textMatch("COALESCE(a.author, '')", condition)
+
+	case "url":
+		return b.textMatch("COALESCE(a.url, '')", condition)
+
+	case "article_content":
+		// Only cached content can match
+		if condition.Value == "" {
+			return "1"
+		}
+		return "EXISTS (SELECT 1 FROM article_contents c WHERE c.article_id = a.id AND " +
+			b.textMatch("c.content", condition) + ")"
+
+	case "article_tags":
+		values := condition.Values
+		if len(values) == 0 {
+			if condition.Value == "" {
+				return "1"
+			}
+			values = []string{condition.Value}
+		}
+		var matches []string
+		for _, value := range values {
+			matches = append(matches, b.arg(`t.name LIKE ? ESCAPE '\'`, likeContains(value)))
+		}
+		return "EXISTS (SELECT 1 FROM article_tags at JOIN tags t ON t.id = at.tag_id WHERE at.article_id = a.id AND (" +
+			strings.Join(matches, " OR ") + "))"
+
+	case "published_after":
+		if condition.Value == "" {
+			return "1"
+		}
+		afterDate, err := time.Parse("2006-01-02", condition.Value)
+		if err != nil {
+			log.Printf("Invalid date format for published_after filter: %s", condition.Value)
+			return "1"
+		}
+		return b.arg("a.published_at >= ?", afterDate)
+
+	case "published_before":
+		// Articles published on the selected date (UTC) or before
+		if condition.Value == "" {
+			return "1"
+		}
+		beforeDate, err := time.Parse("2006-01-02", condition.Value)
+		if err != nil {
+			log.Printf("Invalid date format for published_before filter: %s", condition.Value)
+			return "1"
+		}
+		return b.arg("a.published_at < ?", beforeDate.Truncate(24*time.Hour).Add(24*time.Hour))
+
+	case "published_after_hours", "published_after_days":
+		if condition.Value == "" {
+			return "1"
+		}
+		n, err := strconv.Atoi(condition.Value)
+		if err != nil || n < 0 {
+			log.Printf("Invalid value for %s filter: %s", condition.Field, condition.Value)
+			return "1"
+		}
+		cutoffTime := time.Now().AddDate(0, 0, -n)
+		if condition.Field == "published_after_hours" {
+			cutoffTime = time.Now().Add(-time.Duration(n) * time.Hour)
+		}
+		return b.arg("a.published_at >= ?", cutoffTime)
+
+	case "is_youtube_short":
+		if condition.Value == "" {
+			return "1"
+		}
+		expr := `(LOWER(a.url) LIKE 'http%://youtube.com/shorts/%' OR LOWER(a.url) LIKE 'http%://www.youtube.com/shorts/%' OR LOWER(a.url) LIKE 'http%://m.youtube.com/shorts/%')`
+		if condition.Value == "true" {
+			return expr
+		}
+		return "NOT " + expr
+
+	default:
+		return "1"
+	}
+}
+
+// textMatch returns the SQL matching a text column with the operator of a condition:
+// case-insensitive "contains" or "exact", or a case-sensitive "regex"
+func (b *filterSQLBuilder) textMatch(column string, condition FilterCondition) string {
+	if condition.Value == "" {
+		return "1"
+	}
+	switch condition.Operator {
+	case "exact":
+		return b.arg("LOWER("+column+") = LOWER(?)", condition.Value)
+	case "regex":
+		if _, err := regexp.Compile(condition.Value); err != nil {
+			log.Printf("Invalid regex pattern: %v", err)
+			return "0"
+		}
+		return b.arg(column+" REGEXP ?", condition.Value)
+	default:
+		return b.arg(column+` LIKE ? ESCAPE '\'`, likeContains(condition.Value))
+	}
+}
+
+// arg adds an argument and returns the SQL that uses it
+func (b *filterSQLBuilder) arg(sql string, value interface{}) string {
+	b.args = append(b.args, value)
+	return sql
+}
+
+// likeContains returns a LIKE pattern matching values that contain s
+func likeContains(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
@@ -5,7 +5,6 @@ import (
 	"log"
 	"net/http"
 	"sort"
-	"strings"
 	"time"
 
 	"MrRSS/internal/handlers/core"
@@ -15,7 +14,7 @@ import (
 )
 
 // GetFeedType returns the type code of a feed
-// Possible values: "regular", "freshrss", "rsshub", "script", "xpath", "email"
+// Possible values: "regular", "freshrss", "rsshub", "script", "xpath", "email", "youtube", "mastodon", "json"
 func GetFeedType(feed *models.Feed) string {
 	// Check FreshRSS
 	if feed.IsFreshRSSSource {
@@ -42,6 +41,21 @@ func GetFeedType(feed *models.Feed) string {
 		return "xpath"
 	}
 
+	// Check YouTube
+	if feed.Type == "youtube" {
+		return "youtube"
+	}
+
+	// Check Mastodon
+	if feed.Type == "mastodon" {
+		return "mastodon"
+	}
+
+	// Check JSON API
+	if feed.Type == "json" {
+		return "json"
+	}
+
 	// Default: regular RSS/Atom feed
 	return "regular"
 }
@@ -178,99 +192,12 @@ func HandleFilteredArticles(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Get feeds for category lookup
-	feeds, err := h.DB.GetFeeds()
+	articles, err = FilterArticles(h, articles, req.Conditions)
 	if err != nil {
 		response.Error(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	// Create maps of feed ID to feed data
-	feedCategories := make(map[int64]string)
-	feedTypes := make(map[int64]string)
-	feedIsImageMode := make(map[int64]bool)
-	feedTags := make(map[int64][]string)
-	feedArticlesPerMonth := make(map[int64]float64)
-	feedLastUpdateStatus := make(map[int64]string)
-
-	for _, feed := range feeds {
-		feedCategories[feed.ID] = feed.Category
-		feedTypes[feed.ID] = GetFeedType(&feed)
-		feedIsImageMode[feed.ID] = feed.IsImageMode
-		feedArticlesPerMonth[feed.ID] = feed.ArticlesPerMonth
-		feedLastUpdateStatus[feed.ID] = feed.LastUpdateStatus
-
-		// Build tag names list for this feed
-		tags, _ := h.DB.GetFeedTags(feed.ID)
-		tagNames := make([]string, len(tags))
-		for i, tag := range tags {
-			tagNames[i] = tag.Name
-		}
-		feedTags[feed.ID] = tagNames
-	}
-
-	// Check if any filter condition requires article content
-	needsArticleContent := false
-	for _, condition := range req.Conditions {
-		if condition.Field == "article_content" {
-			needsArticleContent = true
-			break
-		}
-	}
-
-	// Build article content map if needed
-	articleContents := make(map[int64]string)
-	if needsArticleContent {
-		// Collect article IDs
-		articleIDs := make([]int64, len(articles))
-		for i, article := range articles {
-			articleIDs[i] = article.ID
-		}
-
-		// Build placeholders for SQL query
-		placeholders := make([]string, len(articleIDs))
-		args := make([]interface{}, len(articleIDs))
-		for i, id := range articleIDs {
-			placeholders[i] = "?"
-			args[i] = id
-		}
-
-		// Query all article contents at once
-		query := `SELECT article_id, content FROM article_contents WHERE article_id IN (` + strings.Join(placeholders, ",") + `)`
-		rows, err := h.DB.Query(query, args...)
-		if err == nil {
-			defer rows.Close()
-			for rows.Next() {
-				var articleID int64
-				var content string
-				if err := rows.Scan(&articleID, &content); err == nil {
-					articleContents[articleID] = content
-				}
-			}
-		}
-	}
-
-	// Apply filter conditions
-	if len(req.Conditions) > 0 {
-		var filteredArticles []models.Article
-		for _, article := range articles {
-			if evaluateArticleConditions(
-				article,
-				req.Conditions,
-				feedCategories,
-				feedTypes,
-				feedIsImageMode,
-				feedTags,
-				feedArticlesPerMonth,
-				feedLastUpdateStatus,
-				articleContents,
-			) {
-				filteredArticles = append(filteredArticles, article)
-			}
-		}
-		articles = filteredArticles
-	}
-
 	// Apply pagination
 	total := len(articles)
 	offset := (page - 1) * limit
@@ -287,6 +214,8 @@ func HandleFilteredArticles(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		}
 		paginatedArticles = articles[offset:end]
 	}
+	attachArticleTags(h, paginatedArticles)
+	attachStories(h, paginatedArticles)
 
 	hasMore := end < total
 
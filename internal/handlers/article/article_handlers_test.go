@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -176,6 +177,15 @@ func TestHandleExportToObsidian(t *testing.T) {
 	}
 	articleID := articles[0].ID
 
+	// Tags and highlights are included in the note
+	tagID, _ := h.DB.AddTag(&models.Tag{Name: "To Cite", Color: "#000000"})
+	if err := h.DB.AddArticleTag(articleID, tagID); err != nil {
+		t.Fatalf("AddArticleTag: %v", err)
+	}
+	if _, err := h.DB.CreateHighlight(&models.Highlight{ArticleID: articleID, Quote: "An important sentence", Note: "Follow up", StartOffset: -1, EndOffset: -1}); err != nil {
+		t.Fatalf("CreateHighlight: %v", err)
+	}
+
 	// Test export request
 	reqBody := fmt.Sprintf(`{"article_id": %d}`, articleID)
 	req := httptest.NewRequest(http.MethodPost, "/api/articles/export/obsidian", strings.NewReader(reqBody))
@@ -197,4 +207,160 @@ func TestHandleExportToObsidian(t *testing.T) {
 	if response["success"] != "true" {
 		t.Fatalf("Export not successful: %v", response)
 	}
+
+	filePath, _ := response["file_path"].(string)
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	note := string(data)
+	if !strings.Contains(note, "tags: [rss, test_feed, to_cite]") {
+		t.Errorf("expected article tags in front matter, got:\n%s", note)
+	}
+	if !strings.Contains(note, "## Highlights\n\n> An important sentence\n\nFollow up\n") {
+		t.Errorf("expected highlights section, got:\n%s", note)
+	}
+}
+
+func TestArticleTags_AssignAndFilter(t *testing.T) {
+	h := setupHandler(t)
+
+	feedID, err := h.DB.AddFeed(&models.Feed{Title: "F", URL: "http://x"})
+	if err != nil {
+		t.Fatalf("AddFeed: %v", err)
+	}
+	if err := h.DB.SaveArticles(context.Background(), []*models.Article{
+		{FeedID: feedID, Title: "tagged", URL: "u1", PublishedAt: time.Now()},
+		{FeedID: feedID, Title: "untagged", URL: "u2", PublishedAt: time.Now()},
+	}); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+	articles, err := h.DB.GetArticles("", feedID, "", false, 10, 0)
+	if err != nil || len(articles) != 2 {
+		t.Fatalf("GetArticles: %v", err)
+	}
+	var taggedID int64
+	for _, a := range articles {
+		if a.Title == "tagged" {
+			taggedID = a.ID
+		}
+	}
+
+	tagID, err := h.DB.AddTag(&models.Tag{Name: "Research", Color: "#123456"})
+	if err != nil {
+		t.Fatalf("AddTag: %v", err)
+	}
+
+	// Assign the tag
+	req := httptest.NewRequest(http.MethodPost, "/api/articles/tags/add", strings.NewReader(fmt.Sprintf(`{"article_id": %d, "tag_id": %d}`, taggedID, tagID)))
+	w := httptest.NewRecorder()
+	article.HandleAddArticleTag(h, w, req)
+	if w.Result().StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Result().StatusCode, w.Body.String())
+	}
+	var tags []models.Tag
+	if err := json.NewDecoder(w.Body).Decode(&tags); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(tags) != 1 || tags[0].ID != tagID {
+		t.Fatalf("unexpected tags: %+v", tags)
+	}
+
+	// Unknown tags are rejected
+	req = httptest.NewRequest(http.MethodPost, "/api/articles/tags/add", strings.NewReader(fmt.Sprintf(`{"article_id": %d, "tag_id": 999}`, taggedID)))
+	w = httptest.NewRecorder()
+	article.HandleAddArticleTag(h, w, req)
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown tag, got %d", w.Result().StatusCode)
+	}
+
+	// Filter by article tag
+	req = httptest.NewRequest(http.MethodPost, "/api/articles/filter", strings.NewReader(`{"conditions":[{"field":"article_tags","values":["Research"]}]}`))
+	w = httptest.NewRecorder()
+	article.HandleFilteredArticles(h, w, req)
+	if w.Result().StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Result().StatusCode, w.Body.String())
+	}
+	var resp article.FilterResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Articles) != 1 || resp.Articles[0].ID != taggedID {
+		t.Fatalf("expected only the tagged article, got %+v", resp.Articles)
+	}
+	if len(resp.Articles[0].Tags) != 1 || resp.Articles[0].Tags[0].Name != "Research" {
+		t.Errorf("expected tags on filtered article, got %+v", resp.Articles[0].Tags)
+	}
+}
+
+func TestFilterSQL_MatchesFilterArticles(t *testing.T) {
+	h := setupHandler(t)
+
+	tech, _ := h.DB.AddFeed(&models.Feed{Title: "Tech Daily", URL: "http://tech", Category: "Tech"})
+	news, _ := h.DB.AddFeed(&models.Feed{Title: "World News", URL: "http://news", Category: "News"})
+	now := time.Now()
+	if err := h.DB.SaveArticles(context.Background(), []*models.Article{
+		{FeedID: tech, Title: "Go 1.30 released", URL: "https://go.dev/1", Author: "Gopher", PublishedAt: now, IsRead: true},
+		{FeedID: tech, Title: "100% faster_builds", URL: "https://www.youtube.com/shorts/abc", ImageURL: "http://img", PublishedAt: now.AddDate(0, 0, -3)},
+		{FeedID: news, Title: "Elections", URL: "https://news/1", Author: "Reporter", PublishedAt: now.AddDate(0, 0, -10), IsFavorite: true},
+		{FeedID: news, Title: "Go fishing", URL: "https://news/2", PublishedAt: now.AddDate(0, -2, 0)},
+	}); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+	all, err := h.DB.GetArticles("", 0, "", false, 100, 0)
+	if err != nil {
+		t.Fatalf("GetArticles: %v", err)
+	}
+	tagID, _ := h.DB.AddTag(&models.Tag{Name: "Research", Color: "#123456"})
+	for _, a := range all {
+		if a.Title == "Elections" {
+			h.DB.AddArticleTag(a.ID, tagID)
+		}
+	}
+
+	tests := []string{
+		`[{"field":"article_title","operator":"contains","value":"go"}]`,
+		`[{"field":"article_title","operator":"exact","value":"go fishing"}]`,
+		`[{"field":"article_title","operator":"regex","value":"^Go \\d"}]`,
+		`[{"field":"article_title","operator":"regex","value":"("}]`,
+		`[{"field":"article_title","value":"100%"}]`,
+		`[{"field":"article_title","value":"r_b"}]`,
+		`[{"field":"feed_category","values":["Tech"]},{"logic":"and","field":"is_read","value":"false"}]`,
+		`[{"field":"feed_name","values":["news"],"negate":true},{"logic":"or","field":"is_favorite","value":"true"}]`,
+		`[{"field":"author","value":"port"},{"logic":"or","field":"has_image","value":"true"}]`,
+		`[{"field":"article_tags","values":["research"]}]`,
+		`[{"field":"article_tags","values":["research"],"negate":true},{"logic":"and","field":"feed_type","values":["regular"]}]`,
+		`[{"field":"published_after_days","value":"7"}]`,
+		`[{"field":"is_youtube_short","value":"false"}]`,
+		`[{"field":"published_before","value":"` + now.AddDate(0, 0, -5).UTC().Format("2006-01-02") + `"}]`,
+	}
+	for _, tt := range tests {
+		var conditions []article.FilterCondition
+		if err := json.Unmarshal([]byte(tt), &conditions); err != nil {
+			t.Fatalf("Unmarshal %s: %v", tt, err)
+		}
+		want, err := article.FilterArticles(h, all, conditions)
+		if err != nil {
+			t.Fatalf("FilterArticles %s: %v", tt, err)
+		}
+		condition, args, err := article.FilterSQL(h, conditions)
+		if err != nil {
+			t.Fatalf("FilterSQL %s: %v", tt, err)
+		}
+		got, err := h.DB.GetArticlesMatching(condition, args, 100)
+		if err != nil {
+			t.Fatalf("GetArticlesMatching %s: %v", tt, err)
+		}
+
+		titles := func(articles []models.Article) string {
+			var s []string
+			for _, a := range articles {
+				s = append(s, a.Title)
+			}
+			return strings.Join(s, ", ")
+		}
+		if titles(got) != titles(want) {
+			t.Errorf("%s: expected [%s], got [%s]", tt, titles(want), titles(got))
+		}
+	}
 }
@@ -0,0 +1,117 @@
+package article
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+)
+
+// SearchResponse represents the response for full-text article search
+type SearchResponse struct {
+	Articles []database.ArticleSearchResult `json:"articles"`
+	Total    int                            `json:"total"`
+	Page     int                            `json:"page"`
+	Limit    int                            `json:"limit"`
+	HasMore  bool                           `json:"has_more"`
+}
+
+// HandleSearchArticles performs a ranked full-text search over articles.
+// @Summary      Full-text article search
+// @Description  Search article titles, authors, summaries and cached content. Supports FTS5 syntax: "exact phrase", prefix*, AND/OR/NOT, and column filters such as title:term
+// @Tags         articles
+// @Accept       json
+// @Produce      json
+// @Param        q         query     string  true   "Search query"
+// @Param        feed_id   query     int64   false  "Filter by feed ID"
+// @Param        category  query     string  false  "Filter by category name"
+// @Param        after     query     string  false  "Only articles published on or after this date (YYYY-MM-DD)"
+// @Param        before    query     string  false  "Only articles published on or before this date (YYYY-MM-DD)"
+// @Param        page      query     int     false  "Page number (default: 1)"  minimum(1)
+// @Param        limit     query     int     false  "Items per page (default: 50, max: 500)"  minimum(1)  maximum(500)
+// @Success      200  {object}  SearchResponse  "Ranked search results with highlighted snippets"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/search [get]
+func HandleSearchArticles(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	opts := database.ArticleSearchOptions{Query: q.Get("q")}
+	if opts.Query == "" {
+		response.Error(w, errors.New("search query is required"), http.StatusBadRequest)
+		return
+	}
+
+	if feedIDStr := q.Get("feed_id"); feedIDStr != "" {
+		opts.FeedID, _ = strconv.ParseInt(feedIDStr, 10, 64)
+	}
+
+	// Use the special value "\x00" for explicit uncategorized filtering, as in HandleArticles
+	if _, exists := q["category"]; exists {
+		opts.Category = q.Get("category")
+		if opts.Category == "" {
+			opts.Category = "\x00"
+		}
+	}
+
+	if afterStr := q.Get("after"); afterStr != "" {
+		after, err := time.Parse("2006-01-02", afterStr)
+		if err != nil {
+			response.Error(w, errors.New("invalid after date, expected YYYY-MM-DD"), http.StatusBadRequest)
+			return
+		}
+		opts.After = after
+	}
+	if beforeStr := q.Get("before"); beforeStr != "" {
+		before, err := time.Parse("2006-01-02", beforeStr)
+		if err != nil {
+			response.Error(w, errors.New("invalid before date, expected YYYY-MM-DD"), http.StatusBadRequest)
+			return
+		}
+		// Include the whole "before" day
+		opts.Before = before.AddDate(0, 0, 1)
+	}
+
+	page := 1
+	if p, err := strconv.Atoi(q.Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	limit := 50
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	opts.Limit = limit
+	opts.Offset = (page - 1) * limit
+
+	showHiddenStr, _ := h.DB.GetSetting("show_hidden_articles")
+	opts.ShowHidden = showHiddenStr == "true"
+
+	results, total, err := h.DB.SearchArticles(opts)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidSearchQuery) {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, SearchResponse{
+		Articles: results,
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+		HasMore:  opts.Offset+len(results) < total,
+	})
+}
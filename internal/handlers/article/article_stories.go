@@ -0,0 +1,82 @@
+package article
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+)
+
+// HandleArticleStory lists the articles of the story an article belongs to.
+// @Summary      Get the articles of a story
+// @Description  Retrieve the near-duplicate articles from other feeds that report the same story as an article, including the article itself, oldest first. Empty if the article has no duplicates.
+// @Tags         articles
+// @Produce      json
+// @Param        id   query     int64  true  "Article ID"
+// @Success      200  {array}   models.Article  "Articles of the story"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/story [get]
+func HandleArticleStory(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Error(w, errors.New("invalid article id"), http.StatusBadRequest)
+		return
+	}
+
+	ids, err := h.DB.GetStoryArticleIDs(id)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	articles, err := h.DB.GetArticlesByIDs(ids)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// Keep the story order, which the IN query does not preserve
+	byID := make(map[int64]models.Article, len(articles))
+	for _, a := range articles {
+		byID[a.ID] = a
+	}
+	story := make([]models.Article, 0, len(ids))
+	for _, storyArticleID := range ids {
+		if a, ok := byID[storyArticleID]; ok {
+			story = append(story, a)
+		}
+	}
+	attachArticleTags(h, story)
+	attachStories(h, story)
+
+	response.JSON(w, story)
+}
+
+// attachStories populates the story fields of the articles that have near-duplicates
+func attachStories(h *core.Handler, articles []models.Article) {
+	if len(articles) == 0 {
+		return
+	}
+
+	ids := make([]int64, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
+	}
+
+	stories, siblings, err := h.DB.GetStorySiblings(ids)
+	if err != nil {
+		return
+	}
+	for i := range articles {
+		articles[i].StoryID = stories[articles[i].ID]
+		articles[i].StorySiblings = siblings[articles[i].ID]
+	}
+}
@@ -0,0 +1,258 @@
+package article
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+)
+
+// maxBulkTagArticles limits the number of articles in one bulk tag request
+const maxBulkTagArticles = 5000
+
+// HandleArticleTags gets or replaces the tags of an article.
+// @Summary      Get or set article tags
+// @Description  GET: Retrieve the tags of an article. POST: Replace the tags of an article with tag_ids.
+// @Tags         articles
+// @Accept       json
+// @Produce      json
+// @Param        id       query     int64   false  "Article ID (for GET)"
+// @Param        request  body      object  false  "Article ID and tag IDs (for POST: article_id, tag_ids)"
+// @Success      200  {array}   models.Tag  "Tags of the article"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/tags [get]
+// @Router       /articles/tags [post]
+func HandleArticleTags(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		articleID, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+
+		tags, err := h.DB.GetArticleTags(articleID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, tags)
+
+	case http.MethodPost:
+		var req struct {
+			ArticleID int64   `json:"article_id"`
+			TagIDs    []int64 `json:"tag_ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if req.ArticleID <= 0 {
+			response.Error(w, nil, http.StatusBadRequest)
+			return
+		}
+
+		if err := h.DB.SetArticleTags(req.ArticleID, req.TagIDs); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		tags, err := h.DB.GetArticleTags(req.ArticleID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, tags)
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleAddArticleTag assigns a single tag to an article.
+// @Summary      Add a tag to an article
+// @Description  Assign a tag to an article. Assigning a tag twice has no effect.
+// @Tags         articles
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Article ID and tag ID (article_id, tag_id)"
+// @Success      200  {array}   models.Tag  "Tags of the article"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/tags/add [post]
+func HandleAddArticleTag(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	handleArticleTagChange(h, w, r, true)
+}
+
+// HandleRemoveArticleTag removes a single tag from an article.
+// @Summary      Remove a tag from an article
+// @Description  Remove a tag from an article
+// @Tags         articles
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Article ID and tag ID (article_id, tag_id)"
+// @Success      200  {array}   models.Tag  "Tags of the article"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/tags/remove [post]
+func HandleRemoveArticleTag(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	handleArticleTagChange(h, w, r, false)
+}
+
+func handleArticleTagChange(h *core.Handler, w http.ResponseWriter, r *http.Request, add bool) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ArticleID int64 `json:"article_id"`
+		TagID     int64 `json:"tag_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if req.ArticleID <= 0 || req.TagID <= 0 {
+		response.Error(w, nil, http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	if add {
+		tag, tagErr := h.DB.GetTagByID(req.TagID)
+		if tagErr != nil {
+			response.Error(w, tagErr, http.StatusInternalServerError)
+			return
+		}
+		if tag == nil {
+			response.Error(w, nil, http.StatusBadRequest)
+			return
+		}
+		err = h.DB.AddArticleTag(req.ArticleID, req.TagID)
+	} else {
+		err = h.DB.RemoveArticleTag(req.ArticleID, req.TagID)
+	}
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := h.DB.GetArticleTags(req.ArticleID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, tags)
+}
+
+// HandleBulkArticleTags adds and removes tags on many articles at once.
+// @Summary      Bulk assign article tags
+// @Description  Add the tags in add_tag_ids to and remove the tags in remove_tag_ids from every article in article_ids
+// @Tags         articles
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Bulk tag request (article_ids, add_tag_ids, remove_tag_ids)"
+// @Success      200  {object}  map[string]interface{}  "Success status and number of articles"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/tags/bulk [post]
+func HandleBulkArticleTags(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ArticleIDs   []int64 `json:"article_ids"`
+		AddTagIDs    []int64 `json:"add_tag_ids"`
+		RemoveTagIDs []int64 `json:"remove_tag_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if len(req.ArticleIDs) == 0 || len(req.ArticleIDs) > maxBulkTagArticles || len(req.AddTagIDs)+len(req.RemoveTagIDs) == 0 {
+		response.Error(w, nil, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.BulkUpdateArticleTags(req.ArticleIDs, req.AddTagIDs, req.RemoveTagIDs); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success":  true,
+		"articles": len(req.ArticleIDs),
+	})
+}
+
+// HandleTagArticles lists the articles with a tag.
+// @Summary      Get articles by tag
+// @Description  Retrieve the visible articles that have a tag, newest first
+// @Tags         articles
+// @Accept       json
+// @Produce      json
+// @Param        tag_id  query     int64  true   "Tag ID"
+// @Param        page    query     int    false  "Page number (default: 1)"  minimum(1)
+// @Param        limit   query     int    false  "Items per page (default: 50)"  minimum(1)
+// @Success      200  {array}   models.Article  "List of articles"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/by-tag [get]
+func HandleTagArticles(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	tagID, err := strconv.ParseInt(r.URL.Query().Get("tag_id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	page := 1
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	articles, err := h.DB.GetArticlesByTag(tagID, limit, (page-1)*limit)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	attachArticleTags(h, articles)
+	attachStories(h, articles)
+
+	response.JSON(w, articles)
+}
+
+// attachArticleTags populates the Tags field of the articles
+func attachArticleTags(h *core.Handler, articles []models.Article) {
+	if len(articles) == 0 {
+		return
+	}
+
+	ids := make([]int64, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
+	}
+
+	tagsByArticle, err := h.DB.GetTagsForArticles(ids)
+	if err != nil {
+		return
+	}
+	for i := range articles {
+		articles[i].Tags = tagsByArticle[articles[i].ID]
+	}
+}
@@ -3,6 +3,7 @@ package article
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -184,39 +185,79 @@ func HandleExportToNotion(h *core.Handler, w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	pageURL, err := exportArticleToNotion(h, article)
+	if err != nil {
+		var cfgErr *exportConfigError
+		var partialErr *notionPartialError
+		switch {
+		case errors.As(err, &cfgErr):
+			response.Error(w, err, http.StatusBadRequest)
+		case errors.As(err, &partialErr):
+			// Page was created but some content failed to append
+			// Still return success but mention the issue
+			response.JSON(w, map[string]string{
+				"success":  "true",
+				"page_url": pageURL,
+				"message":  fmt.Sprintf("Article exported but some content may be missing: %v", partialErr.err),
+			})
+		default:
+			response.Error(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Return success response
+	response.JSON(w, map[string]string{
+		"success":  "true",
+		"page_url": pageURL,
+		"message":  "Article exported to Notion successfully",
+	})
+}
+
+// notionPartialError reports that a page was created but not all content blocks could be appended
+type notionPartialError struct {
+	err error
+}
+
+func (e *notionPartialError) Error() string {
+	return "notion page created but some content may be missing: " + e.err.Error()
+}
+
+// exportArticleToNotion creates a Notion page for an article and returns its URL
+func exportArticleToNotion(h *core.Handler, article *models.Article) (string, error) {
 	// Check if Notion integration is enabled
 	notionEnabled, _ := h.DB.GetSetting("notion_enabled")
 	if notionEnabled != "true" {
-		response.Error(w, fmt.Errorf("notion integration is not enabled"), http.StatusBadRequest)
-		return
+		return "", &exportConfigError{"notion integration is not enabled"}
 	}
 
 	// Get API key (encrypted setting)
 	apiKey, _ := h.DB.GetEncryptedSetting("notion_api_key")
 	if apiKey == "" {
-		response.Error(w, fmt.Errorf("notion API key is not configured"), http.StatusBadRequest)
-		return
+		return "", &exportConfigError{"notion API key is not configured"}
 	}
 
 	// Get parent page ID
 	pageID, _ := h.DB.GetSetting("notion_page_id")
 	if pageID == "" {
-		response.Error(w, fmt.Errorf("notion page ID is not configured"), http.StatusBadRequest)
-		return
+		return "", &exportConfigError{"notion page ID is not configured"}
 	}
 
 	// Normalize page ID (remove hyphens if present)
 	pageID = strings.ReplaceAll(pageID, "-", "")
 
 	// Get article content
-	content, _, err := h.GetArticleContent(int64(req.ArticleID))
+	content, _, err := h.GetArticleContent(article.ID)
 	if err != nil {
 		// If content fetch fails, continue with empty content
 		content = ""
 	}
 
-	// Convert content to Notion blocks
+	// Convert content to Notion blocks, followed by the article's highlights
 	contentBlocks := htmlToNotionBlocks(content)
+	if highlights, err := h.DB.GetHighlightsByArticle(article.ID); err == nil {
+		contentBlocks = append(contentBlocks, highlightsToNotionBlocks(highlights)...)
+	}
 
 	// Build initial page with metadata (max 100 blocks including metadata)
 	metadataBlocks := buildMetadataBlocks(*article)
@@ -250,31 +291,17 @@ func HandleExportToNotion(h *core.Handler, w http.ResponseWriter, r *http.Reques
 	// Send request to Notion API to create the page
 	pageURL, createdPageID, err := createNotionPage(apiKey, notionRequest)
 	if err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
-		return
+		return "", err
 	}
 
 	// If there are remaining content blocks, append them in batches
 	if len(contentBlocks) > 0 {
-		err = appendBlocksInBatches(apiKey, createdPageID, contentBlocks)
-		if err != nil {
-			// Page was created but some content failed to append
-			// Still return success but mention the issue
-			response.JSON(w, map[string]string{
-				"success":  "true",
-				"page_url": pageURL,
-				"message":  fmt.Sprintf("Article exported but some content may be missing: %v", err),
-			})
-			return
+		if err := appendBlocksInBatches(apiKey, createdPageID, contentBlocks); err != nil {
+			return pageURL, &notionPartialError{err: err}
 		}
 	}
 
-	// Return success response
-	response.JSON(w, map[string]string{
-		"success":  "true",
-		"page_url": pageURL,
-		"message":  "Article exported to Notion successfully",
-	})
+	return pageURL, nil
 }
 
 // buildMetadataBlocks creates metadata blocks for the article
@@ -345,6 +372,38 @@ func htmlToNotionBlocks(htmlContent string) []NotionBlock {
 	return markdownToNotionBlocks(markdown)
 }
 
+// highlightsToNotionBlocks converts highlights to a "Highlights" section with each quote
+// as a highlighted quote block followed by its note
+func highlightsToNotionBlocks(highlights []models.Highlight) []NotionBlock {
+	if len(highlights) == 0 {
+		return nil
+	}
+
+	blocks := []NotionBlock{
+		{Object: "block", Type: "divider", Divider: &Divider{}},
+		createHeading2Block("Highlights"),
+	}
+	for _, highlight := range highlights {
+		var richTexts []RichText
+		for _, chunk := range splitIntoChunks(highlight.Quote, 2000) {
+			richTexts = append(richTexts, RichText{
+				Type:        "text",
+				Text:        TextData{Content: chunk},
+				Annotations: &Annotations{Color: "yellow_background"},
+			})
+		}
+		blocks = append(blocks, NotionBlock{
+			Object: "block",
+			Type:   "quote",
+			Quote:  &Quote{RichText: richTexts},
+		})
+		if highlight.Note != "" {
+			blocks = append(blocks, createParagraphBlock(highlight.Note))
+		}
+	}
+	return blocks
+}
+
 // markdownToNotionBlocks converts Markdown text to Notion blocks
 func markdownToNotionBlocks(markdown string) []NotionBlock {
 	blocks := []NotionBlock{}
@@ -0,0 +1,406 @@
+// Package auth contains the login, logout and user management handlers used in server mode.
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/middleware"
+	"MrRSS/internal/models"
+)
+
+const (
+	// SessionCookieName is the cookie that carries the session token for browser clients
+	SessionCookieName = "mrrss_session"
+	// SessionDuration is how long a login stays valid
+	SessionDuration = 30 * 24 * time.Hour
+)
+
+var errInvalidCredentials = errors.New("invalid username or password")
+
+// LoginRequest represents the request body for login
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// LoginResponse is returned after a successful login
+type LoginResponse struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	User      *models.User `json:"user"`
+}
+
+// Authenticate resolves a session token to its user. It is used by the auth middleware.
+func Authenticate(h *core.Handler) func(token string) (*models.User, error) {
+	return func(token string) (*models.User, error) {
+		return h.DB.GetSessionUser(crypto.HashToken(token))
+	}
+}
+
+// IsAuthenticated reports whether the request carries a valid session token or cookie.
+func IsAuthenticated(h *core.Handler, r *http.Request) bool {
+	token := middleware.TokenFromRequest(r, SessionCookieName)
+	if token == "" {
+		return false
+	}
+	user, err := Authenticate(h)(token)
+	return err == nil && user != nil
+}
+
+// HandleLogin verifies credentials and issues a session token.
+// @Summary      Log in
+// @Description  Verify username and password. Returns a bearer token and also sets it as an HttpOnly session cookie.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      LoginRequest  true  "Credentials"
+// @Success      200  {object}  LoginResponse  "Session token"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      401  {object}  map[string]string  "Invalid credentials"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /auth/login [post]
+func HandleLogin(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.DB.GetUserByUsername(strings.TrimSpace(req.Username))
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if user == nil || !crypto.CheckPassword(user.PasswordHash, req.Password) {
+		response.Error(w, errInvalidCredentials, http.StatusUnauthorized)
+		return
+	}
+
+	token, err := crypto.GenerateToken()
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	expiresAt := time.Now().Add(SessionDuration)
+	if err := h.DB.CreateUserSession(user.ID, crypto.HashToken(token), expiresAt); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	_, _ = h.DB.DeleteExpiredUserSessions()
+	_ = h.DB.UpdateUserLastLogin(user.ID)
+	// Accounts created before the Fever API existed get their key on the next login
+	_ = setFeverAPIKey(h, user.ID, user.Username, req.Password)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	response.JSON(w, LoginResponse{Token: token, ExpiresAt: expiresAt, User: user})
+}
+
+// HandleLogout ends the current session.
+// @Summary      Log out
+// @Description  Invalidate the current session token and clear the session cookie
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  map[string]bool  "Success status"
+// @Router       /auth/logout [post]
+func HandleLogout(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if token := middleware.TokenFromRequest(r, SessionCookieName); token != "" {
+		if err := h.DB.DeleteUserSession(crypto.HashToken(token)); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	response.JSON(w, map[string]bool{"success": true})
+}
+
+// HandleMe returns the currently authenticated user.
+// @Summary      Current user
+// @Description  Get the user that owns the current session
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  models.User  "Current user"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Router       /auth/me [get]
+func HandleMe(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+	response.JSON(w, user)
+}
+
+// HandleChangePassword changes the password of the current user and signs out all of their sessions.
+// @Summary      Change password
+// @Description  Change the current user's password. All existing sessions of the user are invalidated.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "current_password and new_password"
+// @Success      200  {object}  map[string]bool  "Success status"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Router       /auth/password [post]
+func HandleChangePassword(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := middleware.UserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if !crypto.CheckPassword(user.PasswordHash, req.CurrentPassword) {
+		response.Error(w, errInvalidCredentials, http.StatusUnauthorized)
+		return
+	}
+
+	hash, err := crypto.HashPassword(req.NewPassword)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := h.DB.UpdateUserPassword(user.ID, hash); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if err := setFeverAPIKey(h, user.ID, user.Username, req.NewPassword); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, map[string]bool{"success": true})
+}
+
+// HandleFeedToken issues or revokes the current user's output feed token.
+// @Summary      Manage output feed token
+// @Description  POST: Generate a new token for the /feeds/ output feeds, replacing the previous one. The token is only returned once. DELETE: Revoke the token.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  map[string]string  "New token (POST) or revocation status (DELETE)"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /auth/feed-token [post]
+// @Router       /auth/feed-token [delete]
+func HandleFeedToken(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		token, err := crypto.GenerateToken()
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		if err := h.DB.SetUserFeedToken(user.ID, crypto.HashToken(token)); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, map[string]string{"token": token})
+
+	case http.MethodDelete:
+		if err := h.DB.SetUserFeedToken(user.ID, ""); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, map[string]string{"status": "revoked"})
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleUsers lists or creates users. Admin only.
+// @Summary      List or create users
+// @Description  GET: List all users. POST: Create a user with username, password and is_admin. Requires an admin session.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  false  "User details (for POST)"
+// @Success      200  {array}   models.User  "List of users (GET)"
+// @Success      201  {object}  models.User  "Created user (POST)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      403  {object}  map[string]string  "Forbidden"
+// @Failure      409  {object}  map[string]string  "Username already exists"
+// @Router       /auth/users [get]
+// @Router       /auth/users [post]
+func HandleUsers(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		users, err := h.DB.GetUsers()
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, users)
+
+	case http.MethodPost:
+		var req struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			IsAdmin  bool   `json:"is_admin"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+
+		req.Username = strings.TrimSpace(req.Username)
+		if req.Username == "" {
+			response.Error(w, errors.New("username is required"), http.StatusBadRequest)
+			return
+		}
+
+		existing, err := h.DB.GetUserByUsername(req.Username)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		if existing != nil {
+			response.Error(w, errors.New("username already exists"), http.StatusConflict)
+			return
+		}
+
+		hash, err := crypto.HashPassword(req.Password)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+
+		id, err := h.DB.CreateUser(req.Username, hash, req.IsAdmin)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		if err := setFeverAPIKey(h, id, req.Username, req.Password); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		user, err := h.DB.GetUserByID(id)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		response.JSON(w, user)
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleUserDelete deletes a user and all of their sessions. Admin only.
+// @Summary      Delete a user
+// @Description  Delete a user by ID. Admins cannot delete their own account.
+// @Tags         auth
+// @Produce      json
+// @Param        id   query     int64   true  "User ID"
+// @Success      200  {object}  map[string]bool  "Success status"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      403  {object}  map[string]string  "Forbidden"
+// @Router       /auth/users/delete [post]
+func HandleUserDelete(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if current := middleware.UserFromContext(r.Context()); current.ID == id {
+		response.Error(w, errors.New("cannot delete the current user"), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.DeleteUser(id); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, map[string]bool{"success": true})
+}
+
+// requireAdmin writes a 403 response and returns false unless the request was made by an admin
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	user := middleware.UserFromContext(r.Context())
+	if user == nil || !user.IsAdmin {
+		response.Error(w, errors.New("admin privileges required"), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// setFeverAPIKey derives the user's Fever API key from their credentials and stores its digest
+func setFeverAPIKey(h *core.Handler, userID int64, username, password string) error {
+	return h.DB.SetUserFeverAPIKey(userID, crypto.HashToken(crypto.FeverAPIKey(username, password)))
+}
+
+// isSecureRequest reports whether the request reached us over HTTPS, directly or via a proxy
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
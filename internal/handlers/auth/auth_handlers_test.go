@@ -0,0 +1,168 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/database"
+	ff "MrRSS/internal/feed"
+	authhandlers "MrRSS/internal/handlers/auth"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/routes"
+)
+
+func setupServer(t *testing.T) (*core.Handler, http.Handler) {
+	t.Helper()
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	h := core.NewHandler(db, ff.NewFetcher(db), nil, nil)
+
+	mux := http.NewServeMux()
+	routes.RegisterAuthRoutes(mux, h)
+	mux.HandleFunc("/api/protected", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return h, routes.WrapWithAuth(mux, h)
+}
+
+func createUser(t *testing.T, h *core.Handler, username, password string, isAdmin bool) {
+	t.Helper()
+	hash, err := crypto.HashPassword(password)
+	if err != nil {
+		t.Fatalf("HashPassword error: %v", err)
+	}
+	if _, err := h.DB.CreateUser(username, hash, isAdmin); err != nil {
+		t.Fatalf("CreateUser error: %v", err)
+	}
+}
+
+func login(t *testing.T, srv http.Handler, username, password string) *httptest.ResponseRecorder {
+	t.Helper()
+	body := `{"username":"` + username + `","password":"` + password + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(body))
+	req.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuth_LoginBearerAndCookie(t *testing.T) {
+	h, srv := setupServer(t)
+	createUser(t, h, "admin", "correct-horse", true)
+
+	// Unauthenticated requests are rejected
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/protected", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", w.Code)
+	}
+
+	// Wrong password
+	if w := login(t, srv, "admin", "wrong-password"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for wrong password, got %d", w.Code)
+	}
+
+	// Successful login, username is case-insensitive
+	w = login(t, srv, "Admin", "correct-horse")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 on login, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp authhandlers.LoginResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Token == "" || resp.User == nil || !resp.User.IsAdmin {
+		t.Fatalf("unexpected login response: %+v", resp)
+	}
+	if strings.Contains(w.Body.String(), "password_hash") || strings.Contains(w.Body.String(), "$2a$") {
+		t.Fatalf("login response leaks the password hash: %s", w.Body.String())
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != authhandlers.SessionCookieName || !cookies[0].HttpOnly {
+		t.Fatalf("expected HttpOnly session cookie, got %+v", cookies)
+	}
+
+	// Bearer token
+	req := httptest.NewRequest(http.MethodGet, "/api/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+resp.Token)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with bearer token, got %d", w.Code)
+	}
+
+	// Session cookie
+	req = httptest.NewRequest(http.MethodGet, "/api/protected", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with session cookie, got %d", w.Code)
+	}
+
+	// Logout invalidates the token
+	req = httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+resp.Token)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 on logout, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+resp.Token)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 after logout, got %d", w.Code)
+	}
+}
+
+func TestAuth_UserManagementRequiresAdmin(t *testing.T) {
+	h, srv := setupServer(t)
+	createUser(t, h, "admin", "correct-horse", true)
+	createUser(t, h, "reader", "battery-staple", false)
+
+	tokenFor := func(username, password string) string {
+		w := login(t, srv, username, password)
+		var resp authhandlers.LoginResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp.Token
+	}
+
+	createReq := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/users", strings.NewReader(`{"username":"new","password":"long-enough"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := createReq(tokenFor("reader", "battery-staple")); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for non-admin, got %d", w.Code)
+	}
+
+	adminToken := tokenFor("admin", "correct-horse")
+	if w := createReq(adminToken); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for admin, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := createReq(adminToken); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for duplicate username, got %d", w.Code)
+	}
+
+	user, err := h.DB.GetUserByUsername("new")
+	if err != nil || user == nil || user.IsAdmin {
+		t.Fatalf("expected non-admin user to be created, got %+v (err %v)", user, err)
+	}
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"net/http"
+)
+
+// loginPageHTML is a minimal sign-in form served by the server build. It posts to
+// /api/auth/login, which sets the session cookie used by the web frontend.
+const loginPageHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>MrRSS - Sign in</title>
+<style>
+  body { font-family: system-ui, sans-serif; background: #f3f4f6; display: flex; align-items: center; justify-content: center; height: 100vh; margin: 0; }
+  form { background: #fff; padding: 2rem; border-radius: 8px; box-shadow: 0 2px 8px rgba(0,0,0,.1); width: 300px; }
+  h1 { font-size: 1.25rem; margin: 0 0 1rem; }
+  input { width: 100%; box-sizing: border-box; padding: .5rem; margin-bottom: .75rem; border: 1px solid #d1d5db; border-radius: 4px; }
+  button { width: 100%; padding: .5rem; border: 0; border-radius: 4px; background: #3b82f6; color: #fff; cursor: pointer; }
+  #error { color: #dc2626; font-size: .875rem; min-height: 1.25rem; }
+</style>
+</head>
+<body>
+<form id="login">
+  <h1>MrRSS</h1>
+  <input name="username" placeholder="Username" autocomplete="username" required autofocus>
+  <input name="password" type="password" placeholder="Password" autocomplete="current-password" required>
+  <div id="error"></div>
+  <button type="submit">Sign in</button>
+</form>
+<script>
+document.getElementById('login').addEventListener('submit', async (e) => {
+  e.preventDefault();
+  const data = new FormData(e.target);
+  const res = await fetch('/api/auth/login', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ username: data.get('username'), password: data.get('password') }),
+  });
+  if (res.ok) {
+    window.location.href = '/';
+  } else {
+    document.getElementById('error').textContent = res.status === 401 ? 'Invalid username or password' : 'Sign in failed';
+  }
+});
+</script>
+</body>
+</html>
+`
+
+// HandleLoginPage serves the sign-in page for the web frontend in server mode.
+func HandleLoginPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write([]byte(loginPageHTML))
+}
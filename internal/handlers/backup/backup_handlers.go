@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"MrRSS/internal/backup"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+)
+
+// maxBackupSize bounds the size of uploaded backups
+const maxBackupSize = 64 << 20
+
+// HandleBackupExport downloads a full backup.
+// @Summary      Export full backup
+// @Description  Download a versioned backup of the local feeds with all their settings, tags, saved filters, rules, AI profiles and favorite/read-later article states. Email passwords and AI API keys are only included with include_secrets=true.
+// @Tags         backup
+// @Produce      json
+// @Param        include_secrets  query     bool  false  "Include email passwords and AI API keys"
+// @Success      200  {object}  backup.Backup  "Backup document"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /backup/export [get]
+func HandleBackupExport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	b, err := backup.Create(h.DB, r.URL.Query().Get("include_secrets") == "true")
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("mrrss-backup-%s.json", time.Now().Format("20060102"))
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// HandleBackupImport restores a full backup.
+// @Summary      Import full backup
+// @Description  Restore a backup created by /backup/export, uploaded as the "file" form field or as the raw request body. In merge mode missing items are added and existing ones are kept; in replace mode the configuration is made to match the backup and feeds, tags, saved filters and AI profiles not in it are removed.
+// @Tags         backup
+// @Accept       multipart/form-data
+// @Accept       json
+// @Produce      json
+// @Param        mode  query     string  false  "Conflict resolution: merge (default) or replace"
+// @Param        file  formData  file    false  "Backup file"
+// @Success      200  {object}  backup.Result  "Restore summary"
+// @Failure      400  {object}  map[string]string  "Invalid backup or mode"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /backup/import [post]
+func HandleBackupImport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = backup.ModeMerge
+	}
+	if mode != backup.ModeMerge && mode != backup.ModeReplace {
+		response.Error(w, fmt.Errorf("invalid mode %q", mode), http.StatusBadRequest)
+		return
+	}
+
+	var body io.Reader = http.MaxBytesReader(w, r.Body, maxBackupSize)
+	if strings.Contains(r.Header.Get("Content-Type"), "multipart/form-data") {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBackupSize)
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	b, err := backup.Parse(body)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	result, err := backup.Restore(h.DB, b, mode)
+	if err != nil {
+		log.Printf("Error restoring backup: %v", err)
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Restored backup from %s (%s): %d feeds added, %d updated, %d removed",
+		b.CreatedAt.Format(time.RFC3339), mode, result.FeedsAdded, result.FeedsUpdated, result.FeedsRemoved)
+
+	if len(result.NewFeedIDs) > 0 {
+		go h.Fetcher.FetchFeedsByIDs(context.Background(), result.NewFeedIDs)
+	}
+
+	response.JSON(w, result)
+}
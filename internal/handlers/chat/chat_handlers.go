@@ -24,6 +24,8 @@ type ChatMessage struct {
 // ChatRequest represents the incoming chat request
 type ChatRequest struct {
 	Messages       []ChatMessage `json:"messages"`
+	SessionID      int64         `json:"session_id,omitempty"` // Session to save the exchange to
+	ArticleID      int64         `json:"article_id,omitempty"` // Article to start a session for when there is none
 	ArticleTitle   string        `json:"article_title,omitempty"`
 	ArticleURL     string        `json:"article_url,omitempty"`
 	ArticleContent string        `json:"article_content,omitempty"`
@@ -32,44 +34,89 @@ type ChatRequest struct {
 
 // ChatResponse represents the response from the AI chat
 type ChatResponse struct {
-	Response string `json:"response"`
-	HTML     string `json:"html,omitempty"` // Rendered HTML version of markdown response
+	Response  string `json:"response"`
+	HTML      string `json:"html,omitempty"` // Rendered HTML version of markdown response
+	Thinking  string `json:"thinking,omitempty"`
+	SessionID int64  `json:"session_id,omitempty"`
 }
 
 // HandleAIChat handles chat requests for article discussions
 // @Summary      AI chat with article
-// @Description  Send messages to AI for discussing article content (requires ai_chat_enabled setting)
+// @Description  Send messages to AI for discussing article content (requires ai_chat_enabled setting). The exchange is saved to session_id, or to a new session when only article_id is given.
 // @Tags         chat
 // @Accept       json
 // @Produce      json
 // @Param        request  body      chat.ChatRequest  true  "Chat request (messages, article info)"
-// @Success      200  {object}  chat.ChatResponse  "AI response (response, html)"
+// @Success      200  {object}  chat.ChatResponse  "AI response (response, html, thinking, session_id)"
 // @Failure      400  {object}  map[string]string  "Bad request (missing messages)"
 // @Failure      403  {object}  map[string]string  "AI chat is disabled or limit reached"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Router       /chat [post]
 func HandleAIChat(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	req, ok := beginChat(h, w, r)
+	if !ok {
+		return
+	}
+
+	// Optimize context to reduce token usage
+	optimizedMessages := optimizeChatContext(req.Messages, req.ArticleTitle, req.ArticleURL, req.ArticleContent, req.IsFirstMessage)
+
+	client := newChatClient(h, 60*time.Second)
+
+	// Send chat request using universal client
+	result, err := client.RequestWithMessages(toMessageMaps(optimizedMessages))
+	if err != nil {
+		log.Printf("AI chat request failed: %v", err)
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// Extract thinking content and remove tags
+	respContent := result.Content
+	thinking := ai.ExtractThinking(respContent)
+	if thinking == "" {
+		thinking = result.Thinking
+	}
+	respContent = ai.RemoveThinkingTags(respContent)
+
+	// Convert markdown response to HTML
+	htmlResponse := textutil.ConvertMarkdownToHTML(respContent)
+
+	// Log thinking if present (for debugging)
+	if thinking != "" {
+		log.Printf("AI chat thinking: %s", thinking)
+	}
+
+	finishChat(h, optimizedMessages, respContent)
+	sessionID := saveChatExchange(h, req, respContent, thinking)
+
+	response.JSON(w, ChatResponse{Response: respContent, HTML: htmlResponse, Thinking: thinking, SessionID: sessionID})
+}
+
+// beginChat decodes a chat request and checks that chat is enabled and within the usage limit,
+// writing the error response otherwise. It waits for the AI rate limit before returning.
+func beginChat(h *core.Handler, w http.ResponseWriter, r *http.Request) (*ChatRequest, bool) {
 	if r.Method != http.MethodPost {
 		response.Error(w, nil, http.StatusMethodNotAllowed)
-		return
+		return nil, false
 	}
 
 	var req ChatRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.Error(w, err, http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
 	if len(req.Messages) == 0 {
 		response.Error(w, nil, http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
 	// Check if AI chat is enabled
 	chatEnabled, _ := h.DB.GetSetting("ai_chat_enabled")
 	if chatEnabled != "true" {
 		response.Error(w, nil, http.StatusForbidden)
-		return
+		return nil, false
 	}
 
 	// Check if AI usage limit is reached
@@ -79,12 +126,17 @@ func HandleAIChat(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		response.JSON(w, map[string]string{
 			"error": "AI usage limit reached",
 		})
-		return
+		return nil, false
 	}
 
 	// Apply rate limiting for AI requests
 	h.AITracker.WaitForRateLimit()
 
+	return &req, true
+}
+
+// newChatClient creates an AI client for chat from the chat profile or the global AI settings
+func newChatClient(h *core.Handler, timeout time.Duration) *ai.Client {
 	// Get AI settings - try ProfileProvider first
 	var apiKey, endpoint, model string
 	if h.AIProfileProvider != nil {
@@ -113,25 +165,13 @@ func HandleAIChat(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		log.Printf("Using global AI settings for chat (endpoint: %s, model: %s)", endpoint, model)
 	}
 
-	// Optimize context to reduce token usage
-	optimizedMessages := optimizeChatContext(req.Messages, req.ArticleTitle, req.ArticleURL, req.ArticleContent, req.IsFirstMessage)
-
-	// Convert messages to map format
-	messagesMap := make([]map[string]string, len(optimizedMessages))
-	for i, msg := range optimizedMessages {
-		messagesMap[i] = map[string]string{
-			"role":    msg.Role,
-			"content": msg.Content,
-		}
-	}
-
 	// Create HTTP client with proxy support if configured
 	httpClient, err := createHTTPClientWithProxy(h)
 	if err != nil {
 		log.Printf("Failed to create HTTP client with proxy: %v", err)
-		httpClient = &http.Client{Timeout: 60 * time.Second}
+		httpClient = &http.Client{Timeout: timeout}
 	} else {
-		httpClient.Timeout = 60 * time.Second
+		httpClient.Timeout = timeout
 	}
 
 	// Create AI client
@@ -139,41 +179,62 @@ func HandleAIChat(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		APIKey:   apiKey,
 		Endpoint: endpoint,
 		Model:    model,
-		Timeout:  60 * time.Second,
-	}
-	client := ai.NewClientWithHTTPClient(clientConfig, httpClient)
-
-	// Send chat request using universal client
-	result, err := client.RequestWithMessages(messagesMap)
-	if err != nil {
-		log.Printf("AI chat request failed: %v", err)
-		response.Error(w, err, http.StatusInternalServerError)
-		return
+		Timeout:  timeout,
 	}
+	return ai.NewClientWithHTTPClient(clientConfig, httpClient)
+}
 
-	// Extract thinking content and remove tags
-	respContent := result.Content
-	thinking := ai.ExtractThinking(respContent)
-	respContent = ai.RemoveThinkingTags(respContent)
-
-	// Convert markdown response to HTML
-	htmlResponse := textutil.ConvertMarkdownToHTML(respContent)
-
-	// Log thinking if present (for debugging)
-	if thinking != "" {
-		log.Printf("AI chat thinking: %s", thinking)
+// toMessageMaps converts chat messages to the map format of the AI client
+func toMessageMaps(messages []ChatMessage) []map[string]string {
+	messagesMap := make([]map[string]string, len(messages))
+	for i, msg := range messages {
+		messagesMap[i] = map[string]string{
+			"role":    msg.Role,
+			"content": msg.Content,
+		}
 	}
+	return messagesMap
+}
 
+// finishChat records the usage and statistics of a completed chat request
+func finishChat(h *core.Handler, messages []ChatMessage, reply string) {
 	// Track AI usage (estimate tokens from input and output)
-	estimatedTokens := estimateChatTokens(optimizedMessages, respContent)
+	estimatedTokens := estimateChatTokens(messages, reply)
 	if err := h.AITracker.AddUsage(int64(estimatedTokens)); err != nil {
 		log.Printf("Warning: failed to track AI usage: %v", err)
 	}
 
 	// Track statistics
 	_ = h.DB.IncrementStat("ai_chat")
+}
+
+// saveChatExchange saves the last user message and the reply to the request's session,
+// creating a session for the article when the request has none. Returns the session ID,
+// or 0 when the exchange was not saved.
+func saveChatExchange(h *core.Handler, req *ChatRequest, reply, thinking string) int64 {
+	sessionID := req.SessionID
+	if sessionID == 0 {
+		if req.ArticleID == 0 {
+			return 0
+		}
+		id, err := h.DB.CreateChatSession(req.ArticleID, "New Chat")
+		if err != nil {
+			log.Printf("Failed to create chat session: %v", err)
+			return 0
+		}
+		sessionID = id
+	}
 
-	response.JSON(w, ChatResponse{Response: respContent, HTML: htmlResponse})
+	if last := req.Messages[len(req.Messages)-1]; last.Role == "user" {
+		if _, err := h.DB.CreateChatMessage(sessionID, "user", last.Content, ""); err != nil {
+			log.Printf("Failed to save chat message: %v", err)
+			return sessionID
+		}
+	}
+	if _, err := h.DB.CreateChatMessage(sessionID, "assistant", reply, thinking); err != nil {
+		log.Printf("Failed to save chat message: %v", err)
+	}
+	return sessionID
 }
 
 // optimizeChatContext reduces the chat context to save tokens while preserving important information
@@ -0,0 +1,58 @@
+package chat
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"MrRSS/internal/ai"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/utils/textutil"
+)
+
+// chatStreamTimeout bounds a whole streamed answer, which takes longer than waiting for a complete one
+const chatStreamTimeout = 5 * time.Minute
+
+// HandleAIChatStream handles chat requests like HandleAIChat but streams the answer.
+// @Summary      AI chat with article (streaming)
+// @Description  Same request as /chat, answered as Server-Sent Events: "delta" events carry pieces of content and thinking as they are generated, followed by a "done" event with the complete response (as /chat) or an "error" event. The exchange is saved once the stream completes.
+// @Tags         chat
+// @Accept       json
+// @Produce      text/event-stream
+// @Param        request  body      chat.ChatRequest  true  "Chat request (messages, article info)"
+// @Success      200  {object}  ai.StreamDelta  "Stream of delta events, then a done event with a chat.ChatResponse"
+// @Failure      400  {object}  map[string]string  "Bad request (missing messages)"
+// @Failure      403  {object}  map[string]string  "AI chat is disabled or limit reached"
+// @Router       /chat/stream [post]
+func HandleAIChatStream(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	req, ok := beginChat(h, w, r)
+	if !ok {
+		return
+	}
+
+	// Optimize context to reduce token usage
+	optimizedMessages := optimizeChatContext(req.Messages, req.ArticleTitle, req.ArticleURL, req.ArticleContent, req.IsFirstMessage)
+
+	client := newChatClient(h, chatStreamTimeout)
+	sse := response.NewSSE(w)
+
+	result, err := client.RequestWithMessagesStream(r.Context(), toMessageMaps(optimizedMessages), func(delta ai.StreamDelta) error {
+		return sse.Send("delta", delta)
+	})
+	if err != nil {
+		log.Printf("AI chat stream failed: %v", err)
+		_ = sse.Send("error", map[string]string{"error": err.Error()})
+		return
+	}
+
+	finishChat(h, optimizedMessages, result.Content)
+	sessionID := saveChatExchange(h, req, result.Content, result.Thinking)
+
+	_ = sse.Send("done", ChatResponse{
+		Response:  result.Content,
+		HTML:      textutil.ConvertMarkdownToHTML(result.Content),
+		Thinking:  result.Thinking,
+		SessionID: sessionID,
+	})
+}
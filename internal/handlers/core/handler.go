@@ -7,6 +7,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -14,14 +16,19 @@ import (
 	"MrRSS/internal/ai"
 	"MrRSS/internal/cache"
 	"MrRSS/internal/database"
+	"MrRSS/internal/digest"
 	"MrRSS/internal/discovery"
+	"MrRSS/internal/embeddings"
 	"MrRSS/internal/feed"
+	"MrRSS/internal/feed/httpoptions"
 	"MrRSS/internal/models"
+	"MrRSS/internal/podcast"
 	svc "MrRSS/internal/service"
 	"MrRSS/internal/statistics"
 	"MrRSS/internal/translation"
 	"MrRSS/internal/utils/textutil"
 	"MrRSS/internal/utils/urlutil"
+	"MrRSS/internal/webhooks"
 
 	"codeberg.org/readeck/go-readability/v2"
 
@@ -58,9 +65,14 @@ type Handler struct {
 	AIProfileProvider *ai.ProfileProvider // AI profile provider for feature-specific configurations
 	AITracker         *ai.UsageTracker
 	DiscoveryService  *discovery.Service
-	App               interface{}         // Wails app instance for browser integration (interface{} to avoid import in server mode)
-	ContentCache      *cache.ContentCache // Cache for article content
-	Stats             *statistics.Service // Statistics tracking service
+	App               interface{}          // Wails app instance for browser integration (interface{} to avoid import in server mode)
+	ContentCache      *cache.ContentCache  // Cache for article content
+	Stats             *statistics.Service  // Statistics tracking service
+	Webhooks          *webhooks.Dispatcher // Outbound webhook delivery
+	Embeddings        *embeddings.Service  // Article embeddings for semantic search
+	Digests           *digest.Service      // Scheduled AI digests
+	Podcasts          *podcast.Downloader  // Podcast episode downloads
+	TrustProxy        bool                 // Honor X-Forwarded-Host and X-Forwarded-Proto (server mode -trust-proxy flag)
 
 	// Discovery state tracking for polling-based progress
 	DiscoveryMu          sync.RWMutex
@@ -83,6 +95,10 @@ func NewHandler(db *database.DB, fetcher *feed.Fetcher, translator translation.T
 		DiscoveryService:  registry.DiscoveryService(),
 		ContentCache:      registry.ContentCache(),
 		Stats:             registry.Stats(),
+		Webhooks:          webhooks.NewDispatcher(db),
+		Embeddings:        embeddings.NewService(db, registry.AITracker(), profileProvider),
+		Digests:           digest.NewService(db, registry.AITracker(), profileProvider),
+		Podcasts:          podcast.NewDownloader(db),
 	}
 
 	return h
@@ -180,9 +196,20 @@ func (h *Handler) GetArticleContent(articleID int64) (string, bool, error) {
 }
 
 // FetchFullArticleContent fetches the full article content from the original URL using readability.
-func (h *Handler) FetchFullArticleContent(url string) (string, error) {
-	// Use FromURL which handles the HTTP request internally
-	article, err := readability.FromURL(url, 30*time.Second)
+// The HTTP options of the article's feed are used for pages on the site of the feed.
+func (h *Handler) FetchFullArticleContent(a *models.Article) (string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	if f, err := h.DB.GetFeedByID(a.FeedID); err == nil && f != nil {
+		options, err := h.DB.GetFeedHTTPOptions(f.ID)
+		if err != nil {
+			log.Printf("Error loading HTTP options of feed %d: %v", f.ID, err)
+		} else if !httpoptions.IsEmpty(options) {
+			// Applied to each request, so that they are not sent along to redirects to other sites
+			client.Transport = httpoptions.Transport(http.DefaultTransport, options, f.URL, f.Link)
+		}
+	}
+
+	article, err := readableFromURL(client, a.URL)
 	if err != nil {
 		return "", fmt.Errorf("readability parse: %w", err)
 	}
@@ -197,6 +224,26 @@ func (h *Handler) FetchFullArticleContent(url string) (string, error) {
 	return buf.String(), nil
 }
 
+// readableFromURL fetches a web page with the client and parses its readable content,
+// like readability.FromURL which has no option for the transport
+func readableFromURL(client *http.Client, pageURL string) (readability.Article, error) {
+	parsedURL, err := url.ParseRequestURI(pageURL)
+	if err != nil {
+		return readability.Article{}, fmt.Errorf("failed to parse URL: %v", err)
+	}
+
+	resp, err := client.Get(pageURL)
+	if err != nil {
+		return readability.Article{}, fmt.Errorf("failed to fetch the page: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if !strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		return readability.Article{}, fmt.Errorf("URL is not a HTML document")
+	}
+	return readability.FromReader(resp.Body, parsedURL)
+}
+
 // findMatchingFeedItem finds the best matching feed item for an article using multiple criteria
 func (h *Handler) findMatchingFeedItem(article *models.Article, items []*gofeed.Item) *gofeed.Item {
 	// First pass: exact URL match
@@ -13,6 +13,13 @@ import (
 
 // StartBackgroundScheduler starts the background scheduler for auto-updates and cleanup.
 func (h *Handler) StartBackgroundScheduler(ctx context.Context) {
+	// Deliver webhooks, embed new articles, generate digests and download podcast episodes
+	// regardless of the refresh mode
+	go h.Webhooks.Run(ctx)
+	go h.Embeddings.Run(ctx)
+	go h.Digests.Run(ctx)
+	go h.Podcasts.Run(ctx)
+
 	// Trigger initial cleanup on startup
 	go func() {
 		log.Println("Triggering initial cleanup on startup")
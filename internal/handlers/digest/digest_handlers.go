@@ -0,0 +1,322 @@
+package digest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"MrRSS/internal/digest"
+	"MrRSS/internal/handlers/article"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+)
+
+const (
+	defaultWindowHours = 24
+	maxWindowHours     = 24 * 31
+	defaultRunsLimit   = 20
+	maxRunsLimit       = 200
+)
+
+// NewArticleFilter returns a digest filter that applies saved-filter conditions
+// the same way as the filtered articles endpoint
+func NewArticleFilter(h *core.Handler) digest.ArticleFilter {
+	return func(articles []models.Article, conditions string) ([]models.Article, error) {
+		var parsed []article.FilterCondition
+		if err := json.Unmarshal([]byte(conditions), &parsed); err != nil {
+			return nil, err
+		}
+		return article.FilterArticles(h, articles, parsed)
+	}
+}
+
+// HandleDigests handles GET and POST requests for digests.
+// @Summary      List or create digests
+// @Description  GET: Retrieve all digests. POST: Create a digest over a saved filter, category or tag.
+// @Tags         digests
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.Digest  false  "Digest (for POST: name, source_type, source_id, category, window_hours, schedule, hour, weekday, publish_to_feed, enabled)"
+// @Success      200  {array}   models.Digest  "List of digests (GET)"
+// @Success      201  {object}  models.Digest  "Created digest (POST)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /digests [get]
+// @Router       /digests [post]
+func HandleDigests(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := h.DB.GetDigests()
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, list)
+
+	case http.MethodPost:
+		var req models.Digest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := validateDigest(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+
+		id, err := h.DB.AddDigest(&req)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		created, err := h.DB.GetDigest(id)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		response.JSON(w, created)
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleDigestUpdate updates an existing digest.
+// @Summary      Update a digest
+// @Description  Update a digest's name, source, time window, schedule and publishing options
+// @Tags         digests
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.Digest  true  "Digest with its ID"
+// @Success      200  {object}  models.Digest  "Updated digest"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      404  {object}  map[string]string  "Digest not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /digests/update [post]
+func HandleDigestUpdate(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.Digest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := validateDigest(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.DB.GetDigest(req.ID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if existing == nil {
+		response.Error(w, nil, http.StatusNotFound)
+		return
+	}
+
+	if err := h.DB.UpdateDigest(&req); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := h.DB.GetDigest(req.ID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, updated)
+}
+
+// HandleDigestDelete deletes a digest and its history.
+// @Summary      Delete a digest
+// @Description  Delete a digest by its ID, including its history. Digests published to the Digests feed are kept.
+// @Tags         digests
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Digest ID to delete"
+// @Success      200  {object}  map[string]string  "Deletion status"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /digests/delete [post]
+func HandleDigestDelete(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.DeleteDigest(req.ID); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, map[string]string{"status": "deleted"})
+}
+
+// HandleDigestGenerate generates a digest immediately.
+// @Summary      Generate a digest
+// @Description  Generate a digest now from the articles of its time window and store it in its history. Failed runs are stored as well.
+// @Tags         digests
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Digest ID to generate"
+// @Success      200  {object}  models.DigestRun  "Generated digest"
+// @Failure      400  {object}  map[string]string  "Bad request, AI not configured or no articles"
+// @Failure      404  {object}  map[string]string  "Digest not found"
+// @Failure      409  {object}  map[string]string  "Digest is already being generated"
+// @Failure      429  {object}  map[string]string  "AI usage limit reached"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /digests/generate [post]
+func HandleDigestGenerate(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.DB.GetDigest(req.ID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if existing == nil {
+		response.Error(w, nil, http.StatusNotFound)
+		return
+	}
+
+	run, err := h.Digests.Generate(r.Context(), req.ID)
+	if err != nil {
+		response.Error(w, err, generateErrorStatus(err))
+		return
+	}
+	run.HTML = digest.RenderHTML(run)
+	response.JSON(w, run)
+}
+
+// HandleDigestRuns returns the history of a digest.
+// @Summary      Get digest history
+// @Description  Retrieve the most recent runs of a digest, newest first, with their rendered content
+// @Tags         digests
+// @Produce      json
+// @Param        digest_id  query     int64  true   "Digest ID"
+// @Param        limit      query     int    false  "Maximum number of runs (default: 20, max: 200)"
+// @Success      200  {array}   models.DigestRun  "Digest runs"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /digests/runs [get]
+func HandleDigestRuns(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	digestID, err := strconv.ParseInt(q.Get("digest_id"), 10, 64)
+	if err != nil || digestID <= 0 {
+		response.Error(w, errors.New("invalid digest id"), http.StatusBadRequest)
+		return
+	}
+	limit := defaultRunsLimit
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxRunsLimit)
+	}
+
+	runs, err := h.DB.GetDigestRuns(digestID, limit)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	for i := range runs {
+		if runs[i].Content != "" {
+			runs[i].HTML = digest.RenderHTML(&runs[i])
+		}
+	}
+	response.JSON(w, runs)
+}
+
+// validateDigest checks and normalizes a digest request
+func validateDigest(d *models.Digest) error {
+	d.Name = strings.TrimSpace(d.Name)
+	d.Category = strings.TrimSpace(d.Category)
+	if d.Name == "" {
+		return errors.New("name is required")
+	}
+
+	switch d.SourceType {
+	case digest.SourceFilter, digest.SourceTag:
+		if d.SourceID <= 0 {
+			return errors.New("source_id is required for saved filter and tag digests")
+		}
+		d.Category = ""
+	case digest.SourceCategory:
+		if d.Category == "" {
+			return errors.New("category is required for category digests")
+		}
+		d.SourceID = 0
+	default:
+		return errors.New("source_type must be filter, category or tag")
+	}
+
+	switch d.Schedule {
+	case "":
+		d.Schedule = digest.ScheduleDaily
+	case digest.ScheduleManual, digest.ScheduleDaily, digest.ScheduleWeekly:
+	default:
+		return errors.New("schedule must be manual, daily or weekly")
+	}
+
+	if d.WindowHours <= 0 {
+		d.WindowHours = defaultWindowHours
+		if d.Schedule == digest.ScheduleWeekly {
+			d.WindowHours = 7 * 24
+		}
+	}
+	if d.WindowHours > maxWindowHours {
+		return errors.New("window_hours must be at most 744")
+	}
+	if d.Hour < 0 || d.Hour > 23 {
+		return errors.New("hour must be between 0 and 23")
+	}
+	if d.Weekday < 0 || d.Weekday > 6 {
+		return errors.New("weekday must be between 0 (Sunday) and 6")
+	}
+	return nil
+}
+
+// generateErrorStatus maps digest generation errors to HTTP status codes
+func generateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, digest.ErrNotConfigured), errors.Is(err, digest.ErrNoArticles):
+		return http.StatusBadRequest
+	case errors.Is(err, digest.ErrRunning):
+		return http.StatusConflict
+	case errors.Is(err, digest.ErrLimitReached):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
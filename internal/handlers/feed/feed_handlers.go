@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
+	ff "MrRSS/internal/feed"
+	"MrRSS/internal/feed/source"
 	"MrRSS/internal/handlers/core"
 	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
 	"MrRSS/internal/rsshub"
 	"MrRSS/internal/utils/urlutil"
 )
@@ -42,7 +46,7 @@ func HandleFeeds(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 
 // HandleAddFeed adds a new feed subscription and immediately fetches its articles.
 // @Summary      Add a new feed
-// @Description  Add a new RSS/Atom/Email/Script/XPath feed subscription
+// @Description  Add a new RSS/Atom/Email/Script/XPath/JSON API feed subscription
 // @Tags         feeds
 // @Accept       json
 // @Produce      json
@@ -84,6 +88,10 @@ func HandleAddFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		EmailUsername   string `json:"email_username"`
 		EmailPassword   string `json:"email_password"`
 		EmailFolder     string `json:"email_folder"`
+		// JSON API fields, the item mapping is sent in the XPath fields
+		jsonFeedOptionsRequest
+		// Script feed arguments, environment and timeout
+		ScriptSettings *models.ScriptFeedSettings `json:"script_settings"`
 		// Tags
 		Tags []int64 `json:"tags"`
 	}
@@ -92,8 +100,23 @@ func HandleAddFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Fediverse handles are not URLs; the feed is stored under the profile or hashtag page
+	mastodonTarget, isMastodonHandle := source.ParseMastodonHandle(req.URL)
+
 	// Normalize the URL to ensure it has a protocol
-	req.URL = urlutil.NormalizeFeedURL(req.URL)
+	if !isMastodonHandle {
+		req.URL = urlutil.NormalizeFeedURL(req.URL)
+	}
+
+	// YouTube channel, handle and playlist pages are subscribed to through their Atom feed
+	if req.ScriptPath == "" && req.XPathItem == "" && req.Type == "" && source.IsYouTubeURL(req.URL) {
+		feedURL, err := h.Fetcher.ResolveYouTubeFeedURL(r.Context(), req.URL)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		req.URL = feedURL
+	}
 
 	// Determine the feed URL to check for duplicates
 	feedURL := req.URL
@@ -101,6 +124,8 @@ func HandleAddFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		feedURL = "script://" + req.ScriptPath
 	} else if req.Type == "email" {
 		feedURL = "email://" + req.EmailAddress
+	} else if isMastodonHandle {
+		feedURL = mastodonTarget.FeedURL()
 	}
 
 	// Check if feed with this URL already exists (excluding FreshRSS feeds)
@@ -113,10 +138,36 @@ func HandleAddFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.ScriptPath != "" && req.ScriptSettings != nil {
+		if err := ff.ValidateScriptFeedSettings(req.ScriptSettings); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
 	var feedID int64
 	if req.ScriptPath != "" {
 		// Add feed using custom script
-		feedID, err = h.Fetcher.AddScriptSubscription(req.ScriptPath, req.Category, req.Title)
+		feedID, err = h.Fetcher.AddScriptSubscription(req.ScriptPath, req.Category, req.Title, req.ScriptSettings)
+	} else if req.Type == string(source.TypeJSON) {
+		// Add feed using a JSON API
+		feedID, err = h.Fetcher.AddJSONSubscription(&models.Feed{
+			Title:               req.Title,
+			URL:                 req.URL,
+			Category:            req.Category,
+			ProxyURL:            req.ProxyURL,
+			ProxyEnabled:        req.ProxyEnabled,
+			XPathItem:           req.XPathItem,
+			XPathItemTitle:      req.XPathItemTitle,
+			XPathItemContent:    req.XPathItemContent,
+			XPathItemUri:        req.XPathItemUri,
+			XPathItemAuthor:     req.XPathItemAuthor,
+			XPathItemTimestamp:  req.XPathItemTimestamp,
+			XPathItemTimeFormat: req.XPathItemTimeFormat,
+			XPathItemThumbnail:  req.XPathItemThumbnail,
+			XPathItemCategories: req.XPathItemCategories,
+			XPathItemUid:        req.XPathItemUid,
+		}, req.options(0))
 	} else if req.XPathItem != "" {
 		// Add feed using XPath
 		feedID, err = h.Fetcher.AddXPathSubscription(req.URL, req.Category, req.Title, req.Type, req.XPathItem, req.XPathItemTitle, req.XPathItemContent, req.XPathItemUri, req.XPathItemAuthor, req.XPathItemTimestamp, req.XPathItemTimeFormat, req.XPathItemThumbnail, req.XPathItemCategories, req.XPathItemUid)
@@ -232,6 +283,10 @@ func HandleUpdateFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		EmailUsername   string `json:"email_username"`
 		EmailPassword   string `json:"email_password"`
 		EmailFolder     string `json:"email_folder"`
+		// JSON API fields, the item mapping is sent in the XPath fields
+		jsonFeedOptionsRequest
+		// Script feed arguments, environment and timeout
+		ScriptSettings *models.ScriptFeedSettings `json:"script_settings"`
 		// Tags
 		Tags []int64 `json:"tags"`
 	}
@@ -240,9 +295,39 @@ func HandleUpdateFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Fediverse handles are stored under the profile or hashtag page. Such a page is
+	// only known to be a Mastodon feed by its type, which the edit form does not send.
+	if req.ScriptPath == "" && req.XPathItem == "" && req.Type == "" {
+		if target, ok := source.ParseMastodonHandle(req.URL); ok {
+			req.URL = target.FeedURL()
+			req.Type = string(source.TypeMastodon)
+		} else if _, ok := source.ParseMastodonURL(req.URL); ok {
+			currentFeed, err := h.DB.GetFeedByID(req.ID)
+			if err != nil {
+				response.Error(w, err, http.StatusInternalServerError)
+				return
+			}
+			if currentFeed.Type == string(source.TypeMastodon) {
+				req.Type = currentFeed.Type
+			}
+		}
+	}
+
 	// Normalize the URL to ensure it has a protocol
 	req.URL = urlutil.NormalizeFeedURL(req.URL)
 
+	// YouTube channel, handle and playlist pages are fetched through their Atom feed,
+	// with the transcripts of the videos
+	if req.ScriptPath == "" && req.XPathItem == "" && req.Type == "" && source.IsYouTubeURL(req.URL) {
+		feedURL, err := h.Fetcher.ResolveYouTubeFeedURL(r.Context(), req.URL)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		req.URL = feedURL
+		req.Type = string(source.TypeYouTube)
+	}
+
 	// Validate RSSHub URL if provided
 	if req.URL != "" && rsshub.IsRSSHubURL(req.URL) {
 		// Check if RSSHub is enabled
@@ -287,6 +372,41 @@ func HandleUpdateFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// JSON API feeds are checked before saving, as they are not fetched until the next refresh
+	isJSONFeed := req.Type == string(source.TypeJSON)
+	if isJSONFeed {
+		jsonFeed := &models.Feed{
+			URL:                 req.URL,
+			XPathItem:           req.XPathItem,
+			XPathItemTitle:      req.XPathItemTitle,
+			XPathItemContent:    req.XPathItemContent,
+			XPathItemUri:        req.XPathItemUri,
+			XPathItemAuthor:     req.XPathItemAuthor,
+			XPathItemTimestamp:  req.XPathItemTimestamp,
+			XPathItemTimeFormat: req.XPathItemTimeFormat,
+			XPathItemThumbnail:  req.XPathItemThumbnail,
+			XPathItemCategories: req.XPathItemCategories,
+			XPathItemUid:        req.XPathItemUid,
+		}
+		if err := h.Fetcher.ValidateJSONFeed(jsonFeed, req.options(req.ID)); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Script settings are only sent by the feed form; secrets sent without a value keep the stored one
+	if req.ScriptPath != "" && req.ScriptSettings != nil {
+		req.ScriptSettings.FeedID = req.ID
+		if err := ff.ValidateScriptFeedSettings(req.ScriptSettings); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := mergeScriptSecrets(h, req.ScriptSettings); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
 	// If title is empty, fetch the default title from the feed
 	finalTitle := req.Title
 	if finalTitle == "" {
@@ -312,6 +432,12 @@ func HandleUpdateFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			} else {
 				finalTitle = scriptPathToUse
 			}
+		} else if isJSONFeed {
+			// JSON API feed: use the host of the API as title
+			finalTitle = req.URL
+			if u, err := url.Parse(req.URL); err == nil && u.Host != "" {
+				finalTitle = u.Host
+			}
 		} else if req.XPathItem != "" || (currentFeed != nil && currentFeed.XPathItem != "") {
 			// XPath-based feed: use default title
 			finalTitle = "XPath Feed"
@@ -347,6 +473,22 @@ func HandleUpdateFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Requests that do not come from the feed form, such as moving feeds to another
+	// category, carry no options and keep the stored ones
+	if isJSONFeed && req.JSONMaxPages > 0 {
+		if err := h.DB.SaveJSONFeedOptions(req.options(req.ID)); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if req.ScriptPath != "" && req.ScriptSettings != nil {
+		if err := h.DB.SaveScriptFeedSettings(req.ScriptSettings); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
 	// Update tags for the feed
 	if req.Tags != nil {
 		if err := h.DB.SetFeedTags(req.ID, req.Tags); err != nil {
@@ -0,0 +1,88 @@
+package feed
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"MrRSS/internal/feed/health"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+)
+
+// HandleFeedHealth returns the health of all feeds.
+// @Summary      Get feed health
+// @Description  Aggregate the fetch log of each feed over the last days: success rate, latency percentiles, days without new articles, its status under the health policy (healthy, failing, backoff, paused, dead or unknown) and the new URL of a permanent redirect
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        days  query     int  false  "Days of the fetch log to aggregate (default 7, maximum 30)"
+// @Success      200  {array}   models.FeedHealthReport  "Health of the feeds"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/health [get]
+func HandleFeedHealth(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days <= 0 {
+		days = 7
+	}
+	days = min(days, 30)
+
+	feeds, err := h.DB.GetFeeds()
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	states, err := h.DB.GetAllFeedHealth()
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	entries, err := h.DB.GetFeedFetchLogSince(now.AddDate(0, 0, -days))
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// FreshRSS feeds are refreshed by the sync, not fetched
+	fetched := make([]models.Feed, 0, len(feeds))
+	for _, feed := range feeds {
+		if !feed.IsFreshRSSSource {
+			fetched = append(fetched, feed)
+		}
+	}
+	response.JSON(w, health.Reports(fetched, states, entries, h.Fetcher.HealthPolicy(), now))
+}
+
+// HandleFeedFetchLog returns the recent fetch attempts of a feed.
+// @Summary      Get feed fetch log
+// @Description  Get the recent fetch attempts of a feed, newest first, with their duration, HTTP status, size, item counts and error class
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        feed_id  query     int64  true   "Feed ID"
+// @Param        limit    query     int    false  "Maximum number of attempts (default 50, maximum 500)"
+// @Success      200  {array}   models.FeedFetchLog  "Recent fetch attempts"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid feed ID)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/health/log [get]
+func HandleFeedFetchLog(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.URL.Query().Get("feed_id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+	limit = min(limit, 500)
+
+	entries, err := h.DB.GetFeedFetchLog(feedID, limit)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, entries)
+}
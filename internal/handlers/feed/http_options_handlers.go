@@ -0,0 +1,89 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"MrRSS/internal/feed/httpoptions"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+)
+
+// HandleFeedHTTPOptions gets or saves the HTTP options of a feed.
+// @Summary      Feed HTTP options
+// @Description  GET: Get the user agent, headers, cookies, authentication and request method of a feed. Header and cookie values and the auth secret are not returned. POST: Save them; headers, cookies and the auth secret are stored encrypted, and empty values keep the stored ones.
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        feed_id  query     int64                   false  "Feed ID (for GET)"
+// @Param        request  body      models.FeedHTTPOptions  false  "HTTP options (for POST)"
+// @Success      200  {object}  models.FeedHTTPOptions  "HTTP options"
+// @Failure      400  {object}  map[string]string  "Bad request, or the options are invalid"
+// @Failure      404  {object}  map[string]string  "Feed not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/http-options [get]
+// @Router       /feeds/http-options [post]
+func HandleFeedHTTPOptions(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		feedID, err := strconv.ParseInt(r.URL.Query().Get("feed_id"), 10, 64)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		options, err := h.DB.GetFeedHTTPOptions(feedID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, withoutSecrets(options))
+
+	case http.MethodPost:
+		var req models.FeedHTTPOptions
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if req.FeedID <= 0 {
+			response.Error(w, errors.New("invalid feed ID"), http.StatusBadRequest)
+			return
+		}
+		if _, err := h.DB.GetFeedByID(req.FeedID); err != nil {
+			response.Error(w, err, http.StatusNotFound)
+			return
+		}
+		req.Method = strings.ToUpper(strings.TrimSpace(req.Method))
+		req.UserAgent = strings.TrimSpace(req.UserAgent)
+		stored, err := h.DB.GetFeedHTTPOptions(req.FeedID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		httpoptions.MergeSecrets(&req, stored)
+		if err := httpoptions.Validate(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if err := h.DB.SaveFeedHTTPOptions(&req); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, withoutSecrets(&req))
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
+
+// withoutSecrets returns the options of a feed as sent to the client, with the names of
+// headers and cookies but without their values and the auth secret
+func withoutSecrets(o *models.FeedHTTPOptions) *models.FeedHTTPOptions {
+	m := httpoptions.Metadata(o)
+	m.FeedID = o.FeedID
+	return m
+}
@@ -0,0 +1,64 @@
+package feed_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	fh "MrRSS/internal/handlers/feed"
+	"MrRSS/internal/models"
+)
+
+func TestHandleFeedHTTPOptions_KeepsSecrets(t *testing.T) {
+	h := setupHandler(t)
+
+	id, err := h.DB.AddFeed(&models.Feed{Title: "Members", URL: "https://example.com/members.xml"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	h.DB.SaveFeedHTTPOptions(&models.FeedHTTPOptions{
+		FeedID:     id,
+		Headers:    "X-Api-Key: stored-key",
+		Cookies:    "session=stored-session",
+		AuthType:   "bearer",
+		AuthSecret: "stored-token",
+	})
+
+	// GET returns the names without the secret values
+	req := httptest.NewRequest("GET", "/api/feeds/http-options?feed_id="+strconv.FormatInt(id, 10), nil)
+	w := httptest.NewRecorder()
+	fh.HandleFeedHTTPOptions(h, w, req)
+	if w.Result().StatusCode != 200 {
+		t.Fatalf("expected 200 OK, got %d", w.Result().StatusCode)
+	}
+	var options models.FeedHTTPOptions
+	json.NewDecoder(w.Body).Decode(&options)
+	if options.FeedID != id || options.Headers != "X-Api-Key:" || options.Cookies != "session=" ||
+		options.AuthType != "bearer" || options.AuthSecret != "" {
+		t.Errorf("expected the options without secrets, got %+v", options)
+	}
+
+	// Saving the options as returned, with a new header, keeps the stored secrets
+	options.Headers += "\nX-Plan: gold"
+	options.UserAgent = "Reader/1.0"
+	body, _ := json.Marshal(options)
+	req = httptest.NewRequest("POST", "/api/feeds/http-options", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	fh.HandleFeedHTTPOptions(h, w, req)
+	if w.Result().StatusCode != 200 {
+		t.Fatalf("expected 200 OK, got %d: %s", w.Result().StatusCode, w.Body.String())
+	}
+	var saved models.FeedHTTPOptions
+	json.NewDecoder(w.Body).Decode(&saved)
+	if saved.AuthSecret != "" || saved.Cookies != "session=" {
+		t.Errorf("expected the response without secrets, got %+v", saved)
+	}
+
+	stored, _ := h.DB.GetFeedHTTPOptions(id)
+	if stored.Headers != "X-Api-Key: stored-key\nX-Plan: gold" || stored.Cookies != "session=stored-session" ||
+		stored.AuthSecret != "stored-token" || stored.UserAgent != "Reader/1.0" {
+		t.Errorf("expected the stored secrets to be kept, got %+v", stored)
+	}
+}
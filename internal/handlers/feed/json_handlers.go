@@ -0,0 +1,168 @@
+package feed
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"MrRSS/internal/feed/source"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils/textutil"
+	"MrRSS/internal/utils/urlutil"
+)
+
+// jsonPreviewSnippetLength is the length of the content shown for previewed items
+const jsonPreviewSnippetLength = 200
+
+// jsonFeedOptionsRequest holds the request and pagination options of a JSON API feed
+// in add, update and preview requests
+type jsonFeedOptionsRequest struct {
+	JSONHeaders      string `json:"json_headers"`
+	JSONNextPagePath string `json:"json_next_page_path"`
+	JSONPageParam    string `json:"json_page_param"`
+	JSONMaxPages     int    `json:"json_max_pages"` // The feed form always sends at least 1
+}
+
+// options returns the options of the request for a feed
+func (o jsonFeedOptionsRequest) options(feedID int64) *models.JSONFeedOptions {
+	return &models.JSONFeedOptions{
+		FeedID:       feedID,
+		Headers:      o.JSONHeaders,
+		NextPagePath: o.JSONNextPagePath,
+		PageParam:    o.JSONPageParam,
+		MaxPages:     o.JSONMaxPages,
+	}
+}
+
+// jsonPreviewItem is an item extracted by a JSON feed preview
+type jsonPreviewItem struct {
+	Title      string     `json:"title"`
+	Link       string     `json:"link"`
+	Author     string     `json:"author,omitempty"`
+	Published  *time.Time `json:"published,omitempty"`
+	Thumbnail  string     `json:"thumbnail,omitempty"`
+	Categories []string   `json:"categories,omitempty"`
+	Snippet    string     `json:"snippet,omitempty"`
+}
+
+// HandlePreviewJSONFeed fetches a JSON API with an item mapping and returns the extracted items.
+// @Summary      Preview a JSON API feed
+// @Description  Fetch a JSON API with the given JSONPath mapping, headers and pagination without subscribing, and return the extracted items
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "JSON feed (url, xpath_item and the other xpath_item_* paths, json_headers, json_next_page_path, json_page_param, json_max_pages, proxy_url, proxy_enabled)"
+// @Success      200  {object}  map[string]interface{}  "Feed title and extracted items (title, items)"
+// @Failure      400  {object}  map[string]string  "Invalid mapping, or the API could not be fetched"
+// @Router       /feeds/json/preview [post]
+func HandlePreviewJSONFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		URL                 string `json:"url"`
+		ProxyURL            string `json:"proxy_url"`
+		ProxyEnabled        bool   `json:"proxy_enabled"`
+		XPathItem           string `json:"xpath_item"`
+		XPathItemTitle      string `json:"xpath_item_title"`
+		XPathItemContent    string `json:"xpath_item_content"`
+		XPathItemUri        string `json:"xpath_item_uri"`
+		XPathItemAuthor     string `json:"xpath_item_author"`
+		XPathItemTimestamp  string `json:"xpath_item_timestamp"`
+		XPathItemTimeFormat string `json:"xpath_item_time_format"`
+		XPathItemThumbnail  string `json:"xpath_item_thumbnail"`
+		XPathItemCategories string `json:"xpath_item_categories"`
+		XPathItemUid        string `json:"xpath_item_uid"`
+		jsonFeedOptionsRequest
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	feed := &models.Feed{
+		URL:                 urlutil.NormalizeFeedURL(req.URL),
+		Type:                string(source.TypeJSON),
+		ProxyURL:            req.ProxyURL,
+		ProxyEnabled:        req.ProxyEnabled,
+		XPathItem:           req.XPathItem,
+		XPathItemTitle:      req.XPathItemTitle,
+		XPathItemContent:    req.XPathItemContent,
+		XPathItemUri:        req.XPathItemUri,
+		XPathItemAuthor:     req.XPathItemAuthor,
+		XPathItemTimestamp:  req.XPathItemTimestamp,
+		XPathItemTimeFormat: req.XPathItemTimeFormat,
+		XPathItemThumbnail:  req.XPathItemThumbnail,
+		XPathItemCategories: req.XPathItemCategories,
+		XPathItemUid:        req.XPathItemUid,
+	}
+	options := req.options(0)
+	if err := h.Fetcher.ValidateJSONFeed(feed, options); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	parsedFeed, err := h.Fetcher.PreviewJSONFeed(r.Context(), feed, options)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	items := make([]jsonPreviewItem, 0, len(parsedFeed.Items))
+	for _, item := range parsedFeed.Items {
+		preview := jsonPreviewItem{
+			Title:      item.Title,
+			Link:       item.Link,
+			Published:  item.PublishedParsed,
+			Categories: item.Categories,
+		}
+		if item.Author != nil {
+			preview.Author = item.Author.Name
+		}
+		if item.Image != nil {
+			preview.Thumbnail = item.Image.URL
+		}
+		if snippet := []rune(textutil.StripHTML(item.Content)); len(snippet) > jsonPreviewSnippetLength {
+			preview.Snippet = string(snippet[:jsonPreviewSnippetLength]) + "…"
+		} else {
+			preview.Snippet = string(snippet)
+		}
+		items = append(items, preview)
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"title": parsedFeed.Title,
+		"items": items,
+	})
+}
+
+// HandleGetJSONFeedOptions returns the request and pagination options of a JSON API feed.
+// @Summary      Get JSON feed options
+// @Description  Get the custom headers and pagination options of a JSON API feed
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        feed_id  query     int64  true  "Feed ID"
+// @Success      200  {object}  models.JSONFeedOptions  "JSON feed options"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid feed ID)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/json/options [get]
+func HandleGetJSONFeedOptions(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.URL.Query().Get("feed_id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	options, err := h.DB.GetJSONFeedOptions(feedID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, options)
+}
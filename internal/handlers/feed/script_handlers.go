@@ -0,0 +1,82 @@
+package feed
+
+import (
+	"net/http"
+	"strconv"
+
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+)
+
+// mergeScriptSecrets fills in the stored values of secrets that are sent without a
+// value, as the API never returns secret values. Secrets that are not sent are removed.
+func mergeScriptSecrets(h *core.Handler, settings *models.ScriptFeedSettings) error {
+	stored, err := h.DB.GetScriptFeedSettings(settings.FeedID)
+	if err != nil {
+		return err
+	}
+	for name, value := range settings.Secrets {
+		if value == "" {
+			settings.Secrets[name] = stored.Secrets[name]
+		}
+	}
+	return nil
+}
+
+// HandleGetScriptFeedSettings returns the arguments, environment and timeout of a script feed.
+// @Summary      Get script feed settings
+// @Description  Get the arguments, environment variables, secret names and timeout of a script feed. Secret values are not returned.
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        feed_id  query     int64  true  "Feed ID"
+// @Success      200  {object}  models.ScriptFeedSettings  "Script feed settings"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid feed ID)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/script/settings [get]
+func HandleGetScriptFeedSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.URL.Query().Get("feed_id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.DB.GetScriptFeedSettings(feedID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	for name := range settings.Secrets {
+		settings.Secrets[name] = ""
+	}
+	response.JSON(w, settings)
+}
+
+// HandleGetScriptRuns returns the recent runs of a feed script.
+// @Summary      Get script runs
+// @Description  Get the recent runs of a feed script, newest first, with their exit status and stderr output
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        feed_id  query     int64  true   "Feed ID"
+// @Param        limit    query     int    false  "Maximum number of runs (default and maximum 20)"
+// @Success      200  {array}   models.ScriptRun  "Recent script runs"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid feed ID)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/script/runs [get]
+func HandleGetScriptRuns(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.URL.Query().Get("feed_id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	runs, err := h.DB.GetScriptRuns(feedID, limit)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, runs)
+}
@@ -0,0 +1,169 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/feed/transform"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// transformTestItem is an item before and after a transform test
+type transformTestItem struct {
+	Before transformTestFields  `json:"before"`
+	After  *transformTestFields `json:"after"` // nil if the item was dropped
+}
+
+// transformTestFields are the fields of an item shown by transform tests
+type transformTestFields struct {
+	Title      string     `json:"title"`
+	Link       string     `json:"link"`
+	Author     string     `json:"author,omitempty"`
+	Published  *time.Time `json:"published,omitempty"`
+	Categories []string   `json:"categories,omitempty"`
+}
+
+// newTransformTestFields returns the fields of an item shown by transform tests
+func newTransformTestFields(item *gofeed.Item) *transformTestFields {
+	fields := &transformTestFields{
+		Title:      item.Title,
+		Link:       item.Link,
+		Published:  item.PublishedParsed,
+		Categories: item.Categories,
+	}
+	if item.Author != nil {
+		fields.Author = item.Author.Name
+	}
+	return fields
+}
+
+// HandleItemTransform gets or saves the item transform of a feed.
+// @Summary      Feed item transform
+// @Description  GET: Get the JavaScript item transform of a feed. POST: Save it; enabled scripts are checked first.
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        feed_id  query     int64                 false  "Feed ID (for GET)"
+// @Param        request  body      models.ItemTransform  false  "Item transform (for POST)"
+// @Success      200  {object}  models.ItemTransform  "Item transform"
+// @Failure      400  {object}  map[string]string  "Bad request, or the script is invalid"
+// @Failure      404  {object}  map[string]string  "Feed not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/transform [get]
+// @Router       /feeds/transform [post]
+func HandleItemTransform(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		feedID, err := strconv.ParseInt(r.URL.Query().Get("feed_id"), 10, 64)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		t, err := h.DB.GetItemTransform(feedID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, t)
+
+	case http.MethodPost:
+		var req models.ItemTransform
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if req.FeedID <= 0 {
+			response.Error(w, errors.New("invalid feed ID"), http.StatusBadRequest)
+			return
+		}
+		if _, err := h.DB.GetFeedByID(req.FeedID); err != nil {
+			response.Error(w, err, http.StatusNotFound)
+			return
+		}
+		if req.Enabled && strings.TrimSpace(req.Script) != "" {
+			if err := transform.Validate(req.Script); err != nil {
+				response.Error(w, err, http.StatusBadRequest)
+				return
+			}
+		}
+
+		if err := h.DB.SaveItemTransform(&req); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, req)
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleTestItemTransform runs a transform script on the current items of a feed.
+// @Summary      Test a feed item transform
+// @Description  Fetch the current items of a feed and run a transform script on them without storing anything. Script errors are returned in the result together with the console.log output.
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Feed ID and script (feed_id, script)"
+// @Success      200  {object}  map[string]interface{}  "Items before and after the transform, logs and error (items, logs, error)"
+// @Failure      400  {object}  map[string]string  "Bad request, or the feed could not be fetched"
+// @Failure      404  {object}  map[string]string  "Feed not found"
+// @Router       /feeds/transform/test [post]
+func HandleTestItemTransform(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		FeedID int64  `json:"feed_id"`
+		Script string `json:"script"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	feed, err := h.DB.GetFeedByID(req.FeedID)
+	if err != nil {
+		response.Error(w, err, http.StatusNotFound)
+		return
+	}
+
+	before, result, err := h.Fetcher.TestItemTransform(r.Context(), feed, req.Script)
+	if before == nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	items := make([]transformTestItem, len(before))
+	for i := range before {
+		items[i].Before = *newTransformTestFields(&before[i])
+	}
+	// After a script error only the items before the transform are shown
+	if err == nil {
+		kept := result.Items
+		for i := range items {
+			if !result.Dropped[i] {
+				items[i].After = newTransformTestFields(kept[0])
+				kept = kept[1:]
+			}
+		}
+	}
+
+	resp := map[string]interface{}{
+		"items": items,
+		"logs":  result.Logs,
+	}
+	if err != nil {
+		resp["error"] = err.Error()
+	}
+	response.JSON(w, resp)
+}
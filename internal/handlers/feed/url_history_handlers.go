@@ -0,0 +1,35 @@
+package feed
+
+import (
+	"net/http"
+	"strconv"
+
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+)
+
+// HandleFeedURLHistory returns the URL changes of a feed.
+// @Summary      Get feed URL history
+// @Description  Get the moves of a feed to a new URL, newest first: after a permanent redirect, an itunes:new-feed-url or an atom:link rel="self" pointing elsewhere, including feeds merged into it because they moved to its URL
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        feed_id  query     int64  true  "Feed ID"
+// @Success      200  {array}   models.FeedURLChange  "URL changes"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid feed ID)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/url-history [get]
+func HandleFeedURLHistory(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.URL.Query().Get("feed_id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	changes, err := h.DB.GetFeedURLHistory(feedID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, changes)
+}
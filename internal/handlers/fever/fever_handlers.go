@@ -0,0 +1,361 @@
+// Package fever serves the Fever API for older clients that do not support the Google Reader API.
+package fever
+
+import (
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+)
+
+const (
+	apiVersion = 3
+	// itemsPerRequest is the fixed page size of the items endpoint defined by the protocol
+	itemsPerRequest = 50
+)
+
+type group struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+type feedsGroup struct {
+	GroupID int64  `json:"group_id"`
+	FeedIDs string `json:"feed_ids"`
+}
+
+type feed struct {
+	ID                int64  `json:"id"`
+	FaviconID         int64  `json:"favicon_id"`
+	Title             string `json:"title"`
+	URL               string `json:"url"`
+	SiteURL           string `json:"site_url"`
+	IsSpark           int    `json:"is_spark"`
+	LastUpdatedOnTime int64  `json:"last_updated_on_time"`
+}
+
+type item struct {
+	ID            int64  `json:"id"`
+	FeedID        int64  `json:"feed_id"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	HTML          string `json:"html"`
+	URL           string `json:"url"`
+	IsSaved       int    `json:"is_saved"`
+	IsRead        int    `json:"is_read"`
+	CreatedOnTime int64  `json:"created_on_time"`
+}
+
+// HandleFever serves all Fever API requests.
+// @Summary      Fever API
+// @Description  Fever API endpoint. Authenticate with the api_key form field, which is md5("username:password"). The key is set when a user is created, changes their password or logs in. Supported requests: groups, feeds, favicons, items (since_id, max_id, with_ids), links, unread_item_ids, saved_item_ids and mark (item, feed, group).
+// @Tags         fever
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        api_key  formData  string  true  "API key"
+// @Success      200  {object}  map[string]interface{}  "Fever response"
+// @Router       /fever/ [post]
+func HandleFever(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	result := map[string]interface{}{"api_version": apiVersion, "auth": 0}
+
+	user, err := h.DB.GetUserByFeverAPIKey(crypto.HashToken(strings.ToLower(r.FormValue("api_key"))))
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		response.JSON(w, result)
+		return
+	}
+	result["auth"] = 1
+
+	feeds, err := h.DB.GetFeeds()
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	result["last_refreshed_on_time"] = lastRefreshed(feeds)
+
+	if has(r, "mark") {
+		if err := mark(h, r, feeds); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if has(r, "groups") || has(r, "feeds") {
+		result["feeds_groups"] = feedsGroups(feeds)
+	}
+	if has(r, "groups") {
+		result["groups"] = groups(feeds)
+	}
+	if has(r, "feeds") {
+		list := make([]feed, 0, len(feeds))
+		for _, f := range feeds {
+			list = append(list, feed{
+				ID:                f.ID,
+				Title:             f.Title,
+				URL:               f.URL,
+				SiteURL:           f.Link,
+				LastUpdatedOnTime: unix(f.LastUpdated),
+			})
+		}
+		result["feeds"] = list
+	}
+	if has(r, "favicons") {
+		result["favicons"] = []interface{}{}
+	}
+	if has(r, "links") {
+		result["links"] = []interface{}{}
+	}
+
+	if has(r, "items") {
+		items, total, err := fetchItems(h, r)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		result["items"] = items
+		result["total_items"] = total
+	}
+
+	if has(r, "unread_item_ids") || r.FormValue("as") == "read" || r.FormValue("as") == "unread" {
+		ids, err := itemIDs(h, database.ArticleStreamQuery{Unread: true})
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		result["unread_item_ids"] = ids
+	}
+	if has(r, "saved_item_ids") || r.FormValue("as") == "saved" || r.FormValue("as") == "unsaved" {
+		ids, err := itemIDs(h, database.ArticleStreamQuery{Starred: true})
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		result["saved_item_ids"] = ids
+	}
+
+	response.JSON(w, result)
+}
+
+// has reports whether a parameter is present in the query string or form, with or without a value
+func has(r *http.Request, name string) bool {
+	_, ok := r.Form[name]
+	return ok
+}
+
+// fetchItems returns a page of items selected by since_id, max_id or with_ids, plus the total item count
+func fetchItems(h *core.Handler, r *http.Request) ([]item, int, error) {
+	q := database.ArticleStreamQuery{OrderByID: true, OldestFirst: true, Limit: itemsPerRequest}
+	switch {
+	case r.FormValue("with_ids") != "":
+		q.IDs = parseIDList(r.FormValue("with_ids"), itemsPerRequest)
+	case r.FormValue("max_id") != "":
+		q.MaxID, _ = strconv.ParseInt(r.FormValue("max_id"), 10, 64)
+		q.OldestFirst = false
+	default:
+		q.SinceID, _ = strconv.ParseInt(r.FormValue("since_id"), 10, 64)
+	}
+
+	total, err := h.DB.CountArticleStream(database.ArticleStreamQuery{})
+	if err != nil {
+		return nil, 0, err
+	}
+	if r.FormValue("with_ids") != "" && len(q.IDs) == 0 {
+		return []item{}, total, nil
+	}
+
+	articles, err := h.DB.GetArticleStream(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]int64, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	contents, err := h.DB.GetArticleContents(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]item, 0, len(articles))
+	for _, a := range articles {
+		// Content comes from the article content cache, falling back to the feed summary
+		content, ok := contents[a.ID]
+		if !ok {
+			content = a.Summary
+		}
+		result = append(result, item{
+			ID:            a.ID,
+			FeedID:        a.FeedID,
+			Title:         a.Title,
+			Author:        a.Author,
+			HTML:          content,
+			URL:           a.URL,
+			IsSaved:       boolInt(a.IsFavorite),
+			IsRead:        boolInt(a.IsRead),
+			CreatedOnTime: unix(a.PublishedAt),
+		})
+	}
+	return result, total, nil
+}
+
+// itemIDs returns the IDs of the matching articles as a comma-separated list
+func itemIDs(h *core.Handler, q database.ArticleStreamQuery) (string, error) {
+	q.OrderByID = true
+	ids, _, err := h.DB.GetArticleStreamIDs(q)
+	if err != nil {
+		return "", err
+	}
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// mark applies a mark=item|feed|group request
+func mark(h *core.Handler, r *http.Request, feeds []models.Feed) error {
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		return err
+	}
+	as := r.FormValue("as")
+
+	switch r.FormValue("mark") {
+	case "item":
+		switch as {
+		case "read":
+			return h.DB.MarkArticleRead(id, true)
+		case "unread":
+			return h.DB.MarkArticleRead(id, false)
+		case "saved":
+			return h.DB.SetArticleFavorite(id, true)
+		case "unsaved":
+			return h.DB.SetArticleFavorite(id, false)
+		}
+		return nil
+
+	case "feed", "group":
+		if as != "read" {
+			return nil
+		}
+		q := database.ArticleStreamQuery{}
+		if before, err := strconv.ParseInt(r.FormValue("before"), 10, 64); err == nil && before > 0 {
+			q.Until = time.Unix(before, 0)
+		}
+		if r.FormValue("mark") == "feed" {
+			q.FeedID = id
+		} else if id != 0 {
+			// Group 0 is the "Kindling" super group containing all feeds
+			q.Category = categoryForGroup(feeds, id)
+			if q.Category == "" {
+				return nil
+			}
+		}
+		_, err := h.DB.MarkArticleStreamRead(q)
+		return err
+	}
+	return nil
+}
+
+// groupID derives a stable group ID from a category name
+func groupID(category string) int64 {
+	hash := fnv.New32a()
+	hash.Write([]byte(category))
+	return int64(hash.Sum32()&0x7fffffff) + 1
+}
+
+// categoryForGroup returns the category whose group ID is id, or "" if there is none
+func categoryForGroup(feeds []models.Feed, id int64) string {
+	for _, f := range feeds {
+		if f.Category != "" && groupID(f.Category) == id {
+			return f.Category
+		}
+	}
+	return ""
+}
+
+func groups(feeds []models.Feed) []group {
+	seen := make(map[string]bool)
+	result := []group{}
+	for _, f := range feeds {
+		if f.Category != "" && !seen[f.Category] {
+			seen[f.Category] = true
+			result = append(result, group{ID: groupID(f.Category), Title: f.Category})
+		}
+	}
+	return result
+}
+
+func feedsGroups(feeds []models.Feed) []feedsGroup {
+	var order []string
+	members := make(map[string][]string)
+	for _, f := range feeds {
+		if f.Category == "" {
+			continue
+		}
+		if _, ok := members[f.Category]; !ok {
+			order = append(order, f.Category)
+		}
+		members[f.Category] = append(members[f.Category], strconv.FormatInt(f.ID, 10))
+	}
+
+	result := make([]feedsGroup, 0, len(order))
+	for _, c := range order {
+		result = append(result, feedsGroup{GroupID: groupID(c), FeedIDs: strings.Join(members[c], ",")})
+	}
+	return result
+}
+
+func lastRefreshed(feeds []models.Feed) int64 {
+	var latest time.Time
+	for _, f := range feeds {
+		if f.LastUpdated.After(latest) {
+			latest = f.LastUpdated
+		}
+	}
+	return unix(latest)
+}
+
+// parseIDList parses a comma-separated ID list, keeping at most max valid IDs
+func parseIDList(s string, max int) []int64 {
+	var ids []int64
+	for _, part := range strings.Split(s, ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64); err == nil {
+			ids = append(ids, id)
+			if len(ids) == max {
+				break
+			}
+		}
+	}
+	return ids
+}
+
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
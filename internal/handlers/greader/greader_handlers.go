@@ -0,0 +1,295 @@
+// Package greader serves a subset of the Google Reader API so that mobile
+// clients such as Reeder, FeedMe and NetNewsWire can sync against MrRSS.
+// It follows the dialect implemented by FreshRSS and Miniflux.
+package greader
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/crypto"
+	authhandlers "MrRSS/internal/handlers/auth"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/middleware"
+	"MrRSS/internal/models"
+)
+
+// Stream and tag identifiers used by the protocol
+const (
+	streamReadingList = "user/-/state/com.google/reading-list"
+	streamRead        = "user/-/state/com.google/read"
+	streamStarred     = "user/-/state/com.google/starred"
+	streamKeptUnread  = "user/-/state/com.google/kept-unread"
+	labelPrefix       = "user/-/label/"
+	feedPrefix        = "feed/"
+)
+
+// RequireAuth returns a middleware that accepts a session token sent as
+// "Authorization: GoogleLogin auth=<token>" or as a bearer token.
+func RequireAuth(h *core.Handler) middleware.Middleware {
+	authenticate := authhandlers.Authenticate(h)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := tokenFromRequest(r)
+			if token == "" {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			user, err := authenticate(token)
+			if err != nil {
+				response.Error(w, err, http.StatusInternalServerError)
+				return
+			}
+			if user == nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(middleware.WithUser(r.Context(), user)))
+		})
+	}
+}
+
+// tokenFromRequest extracts the session token from a GoogleLogin or Bearer Authorization header
+func tokenFromRequest(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) > 12 && strings.EqualFold(auth[:12], "GoogleLogin ") {
+		for _, part := range strings.Fields(auth[12:]) {
+			if strings.HasPrefix(strings.ToLower(part), "auth=") {
+				return part[5:]
+			}
+		}
+		return ""
+	}
+	return middleware.TokenFromRequest(r, "")
+}
+
+// HandleClientLogin verifies credentials and returns a session token in the ClientLogin format.
+// @Summary      Google Reader login
+// @Description  Log in with Email (username) and Passwd. Responds with SID, LSID and Auth lines; send the Auth value as "Authorization: GoogleLogin auth=<Auth>"
+// @Tags         greader
+// @Accept       x-www-form-urlencoded
+// @Produce      plain
+// @Param        Email   formData  string  true  "Username"
+// @Param        Passwd  formData  string  true  "Password"
+// @Success      200  {string}  string  "SID=...\nLSID=...\nAuth=..."
+// @Failure      401  {string}  string  "Error=BadAuthentication"
+// @Router       /accounts/ClientLogin [post]
+func HandleClientLogin(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := h.DB.GetUserByUsername(strings.TrimSpace(r.FormValue("Email")))
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if user == nil || !crypto.CheckPassword(user.PasswordHash, r.FormValue("Passwd")) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, "Error=BadAuthentication\n")
+		return
+	}
+
+	token, err := crypto.GenerateToken()
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if err := h.DB.CreateUserSession(user.ID, crypto.HashToken(token), time.Now().Add(authhandlers.SessionDuration)); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	_ = h.DB.UpdateUserLastLogin(user.ID)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "SID=%s\nLSID=%s\nAuth=%s\n", token, token, token)
+}
+
+// HandleToken returns a write token.
+// Requests are authenticated with a header rather than a cookie, so the token is
+// not needed for CSRF protection and is accepted but not checked on writes.
+// @Summary      Google Reader write token
+// @Tags         greader
+// @Produce      plain
+// @Success      200  {string}  string  "Token"
+// @Router       /reader/api/0/token [get]
+func HandleToken(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, crypto.HashToken("greader:" + tokenFromRequest(r))[:57])
+}
+
+// HandleUserInfo returns the authenticated user.
+// @Summary      Google Reader user info
+// @Tags         greader
+// @Produce      json
+// @Success      200  {object}  map[string]string  "User info"
+// @Router       /reader/api/0/user-info [get]
+func HandleUserInfo(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := strconv.FormatInt(user.ID, 10)
+	response.JSON(w, map[string]string{
+		"userId":        id,
+		"userName":      user.Username,
+		"userProfileId": id,
+		"userEmail":     "",
+	})
+}
+
+type category struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type subscription struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Categories []category `json:"categories"`
+	URL        string     `json:"url"`
+	HTMLURL    string     `json:"htmlUrl"`
+	IconURL    string     `json:"iconUrl"`
+}
+
+// HandleSubscriptionList lists all feeds.
+// @Summary      Google Reader subscription list
+// @Tags         greader
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "Subscriptions"
+// @Router       /reader/api/0/subscription/list [get]
+func HandleSubscriptionList(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	feeds, err := h.DB.GetFeeds()
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	subscriptions := make([]subscription, 0, len(feeds))
+	for _, f := range feeds {
+		sub := subscription{
+			ID:         feedStreamID(f.ID),
+			Title:      f.Title,
+			Categories: []category{},
+			URL:        f.URL,
+			HTMLURL:    f.Link,
+			IconURL:    f.ImageURL,
+		}
+		if f.Category != "" {
+			sub.Categories = append(sub.Categories, category{ID: labelPrefix + f.Category, Label: f.Category})
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+
+	response.JSON(w, map[string]interface{}{"subscriptions": subscriptions})
+}
+
+// HandleTagList lists the starred state and all categories as labels.
+// @Summary      Google Reader tag list
+// @Tags         greader
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "Tags"
+// @Router       /reader/api/0/tag/list [get]
+func HandleTagList(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	feeds, err := h.DB.GetFeeds()
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	type tag struct {
+		ID   string `json:"id"`
+		Type string `json:"type,omitempty"`
+	}
+	tags := []tag{{ID: streamStarred}}
+	for _, c := range categories(feeds) {
+		tags = append(tags, tag{ID: labelPrefix + c, Type: "folder"})
+	}
+
+	response.JSON(w, map[string]interface{}{"tags": tags})
+}
+
+type unreadCount struct {
+	ID                      string `json:"id"`
+	Count                   int    `json:"count"`
+	NewestItemTimestampUsec string `json:"newestItemTimestampUsec"`
+}
+
+// HandleUnreadCount returns unread counts per feed, per label and in total.
+// @Summary      Google Reader unread counts
+// @Tags         greader
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "Unread counts"
+// @Router       /reader/api/0/unread-count [get]
+func HandleUnreadCount(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	feeds, err := h.DB.GetFeeds()
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	counts, err := h.DB.GetUnreadCountsForAllFeeds()
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var total int
+	var newest time.Time
+	labelCounts := make(map[string]int)
+	labelNewest := make(map[string]time.Time)
+	unreadCounts := []unreadCount{}
+	for _, f := range feeds {
+		count := counts[f.ID]
+		unreadCounts = append(unreadCounts, unreadCount{ID: feedStreamID(f.ID), Count: count, NewestItemTimestampUsec: usec(f.LastUpdated)})
+		total += count
+		if f.LastUpdated.After(newest) {
+			newest = f.LastUpdated
+		}
+		if f.Category != "" {
+			labelCounts[f.Category] += count
+			if f.LastUpdated.After(labelNewest[f.Category]) {
+				labelNewest[f.Category] = f.LastUpdated
+			}
+		}
+	}
+	for _, c := range categories(feeds) {
+		unreadCounts = append(unreadCounts, unreadCount{ID: labelPrefix + c, Count: labelCounts[c], NewestItemTimestampUsec: usec(labelNewest[c])})
+	}
+	unreadCounts = append(unreadCounts, unreadCount{ID: streamReadingList, Count: total, NewestItemTimestampUsec: usec(newest)})
+
+	response.JSON(w, map[string]interface{}{"max": total, "unreadcounts": unreadCounts})
+}
+
+// categories returns the sorted, distinct non-empty categories of feeds
+func categories(feeds []models.Feed) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, f := range feeds {
+		if f.Category != "" && !seen[f.Category] {
+			seen[f.Category] = true
+			result = append(result, f.Category)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func feedStreamID(id int64) string {
+	return feedPrefix + strconv.FormatInt(id, 10)
+}
+
+// usec formats t as microseconds since the epoch, or "0" for the zero time
+func usec(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixMicro(), 10)
+}
@@ -0,0 +1,225 @@
+package greader_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/database"
+	ff "MrRSS/internal/feed"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+	"MrRSS/internal/routes"
+)
+
+func setupServer(t *testing.T) (*core.Handler, http.Handler) {
+	t.Helper()
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	h := core.NewHandler(db, ff.NewFetcher(db), nil, nil)
+
+	hash, err := crypto.HashPassword("correct-horse")
+	if err != nil {
+		t.Fatalf("HashPassword error: %v", err)
+	}
+	userID, err := db.CreateUser("reader", hash, false)
+	if err != nil {
+		t.Fatalf("CreateUser error: %v", err)
+	}
+	if err := db.SetUserFeverAPIKey(userID, crypto.HashToken(crypto.FeverAPIKey("reader", "correct-horse"))); err != nil {
+		t.Fatalf("SetUserFeverAPIKey error: %v", err)
+	}
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Tech", URL: "https://tech.example.com/feed", Category: "Tech"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	now := time.Now()
+	articles := []*models.Article{
+		{FeedID: feedID, Title: "First", URL: "https://tech.example.com/1", Summary: "<p>one</p>", PublishedAt: now.Add(-time.Hour)},
+		{FeedID: feedID, Title: "Second", URL: "https://tech.example.com/2", Summary: "<p>two</p>", PublishedAt: now},
+	}
+	if err := db.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatalf("SaveArticles error: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	routes.RegisterSyncRoutes(mux, h)
+	return h, mux
+}
+
+func clientLogin(t *testing.T, srv http.Handler, password string) *httptest.ResponseRecorder {
+	t.Helper()
+	form := url.Values{"Email": {"reader"}, "Passwd": {password}}
+	req := httptest.NewRequest(http.MethodPost, "/accounts/ClientLogin", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	return w
+}
+
+func TestGReader_SyncFlow(t *testing.T) {
+	h, srv := setupServer(t)
+
+	if w := clientLogin(t, srv, "wrong-password"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for wrong password, got %d", w.Code)
+	}
+
+	w := clientLogin(t, srv, "correct-horse")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 on login, got %d: %s", w.Code, w.Body.String())
+	}
+	var auth string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if strings.HasPrefix(line, "Auth=") {
+			auth = strings.TrimPrefix(line, "Auth=")
+		}
+	}
+	if auth == "" {
+		t.Fatalf("no Auth line in login response: %q", w.Body.String())
+	}
+
+	do := func(method, path string, form url.Values) *httptest.ResponseRecorder {
+		t.Helper()
+		var req *http.Request
+		if method == http.MethodPost {
+			req = httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			req = httptest.NewRequest(method, path, nil)
+		}
+		req.Header.Set("Authorization", "GoogleLogin auth="+auth)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	// Requests without credentials are rejected
+	req := httptest.NewRequest(http.MethodGet, "/reader/api/0/subscription/list?output=json", nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", w.Code)
+	}
+
+	var subs struct {
+		Subscriptions []struct {
+			ID         string `json:"id"`
+			Categories []struct {
+				ID string `json:"id"`
+			} `json:"categories"`
+		} `json:"subscriptions"`
+	}
+	w = do(http.MethodGet, "/reader/api/0/subscription/list?output=json", nil)
+	if err := json.NewDecoder(w.Body).Decode(&subs); err != nil {
+		t.Fatalf("decode subscriptions: %v", err)
+	}
+	if len(subs.Subscriptions) != 1 || len(subs.Subscriptions[0].Categories) != 1 || subs.Subscriptions[0].Categories[0].ID != "user/-/label/Tech" {
+		t.Fatalf("unexpected subscriptions: %+v", subs)
+	}
+	feedStream := subs.Subscriptions[0].ID
+
+	type stream struct {
+		Items []struct {
+			ID      string `json:"id"`
+			Title   string `json:"title"`
+			Summary struct {
+				Content string `json:"content"`
+			} `json:"summary"`
+			Categories []string `json:"categories"`
+		} `json:"items"`
+		Continuation string `json:"continuation"`
+	}
+	var page stream
+	w = do(http.MethodGet, "/reader/api/0/stream/contents/"+feedStream+"?n=1&xt=user/-/state/com.google/read", nil)
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("decode stream: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].Title != "Second" || page.Items[0].Summary.Content != "<p>two</p>" || page.Continuation != "1" {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+	itemID := page.Items[0].ID
+
+	// Mark the newest item read and starred
+	w = do(http.MethodPost, "/reader/api/0/edit-tag", url.Values{
+		"i": {itemID},
+		"a": {"user/-/state/com.google/read"},
+	})
+	if w.Code != http.StatusOK || w.Body.String() != "OK" {
+		t.Fatalf("edit-tag failed: %d %s", w.Code, w.Body.String())
+	}
+	w = do(http.MethodPost, "/reader/api/0/edit-tag", url.Values{
+		"i": {itemID},
+		"a": {"user/-/state/com.google/starred"},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("edit-tag failed: %d %s", w.Code, w.Body.String())
+	}
+
+	var counts struct {
+		UnreadCounts []struct {
+			ID    string `json:"id"`
+			Count int    `json:"count"`
+		} `json:"unreadcounts"`
+	}
+	w = do(http.MethodGet, "/reader/api/0/unread-count?output=json", nil)
+	if err := json.NewDecoder(w.Body).Decode(&counts); err != nil {
+		t.Fatalf("decode unread counts: %v", err)
+	}
+	for _, c := range counts.UnreadCounts {
+		if c.Count != 1 {
+			t.Errorf("expected unread count 1 for %s, got %d", c.ID, c.Count)
+		}
+	}
+
+	var refs struct {
+		ItemRefs []struct {
+			ID string `json:"id"`
+		} `json:"itemRefs"`
+	}
+	w = do(http.MethodGet, "/reader/api/0/stream/items/ids?s=user/-/state/com.google/starred&n=1000", nil)
+	if err := json.NewDecoder(w.Body).Decode(&refs); err != nil {
+		t.Fatalf("decode item ids: %v", err)
+	}
+	if len(refs.ItemRefs) != 1 {
+		t.Fatalf("expected 1 starred item, got %+v", refs)
+	}
+
+	// Item contents accept the short decimal form
+	w = do(http.MethodPost, "/reader/api/0/stream/items/contents", url.Values{"i": {refs.ItemRefs[0].ID}})
+	page = stream{}
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("decode item contents: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != itemID {
+		t.Fatalf("unexpected item contents: %+v", page)
+	}
+	var starred, read bool
+	for _, c := range page.Items[0].Categories {
+		starred = starred || c == "user/-/state/com.google/starred"
+		read = read || c == "user/-/state/com.google/read"
+	}
+	if !starred || !read {
+		t.Errorf("expected read and starred categories, got %v", page.Items[0].Categories)
+	}
+
+	w = do(http.MethodPost, "/reader/api/0/mark-all-as-read", url.Values{"s": {"user/-/label/Tech"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("mark-all-as-read failed: %d %s", w.Code, w.Body.String())
+	}
+	if count, err := h.DB.GetTotalUnreadCount(); err != nil || count != 0 {
+		t.Errorf("expected no unread articles, got %d (err %v)", count, err)
+	}
+}
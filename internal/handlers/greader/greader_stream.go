@@ -0,0 +1,445 @@
+package greader
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+)
+
+const (
+	itemIDPrefix   = "tag:google.com,2005:reader/item/"
+	defaultCount   = 20
+	maxCount       = 1000
+	maxIDCount     = 10000
+	streamPathBase = "/reader/api/0/stream/contents/"
+)
+
+var errUnknownStream = errors.New("unknown stream")
+
+// userIDPattern matches the numeric user part of stream IDs such as "user/1/state/..."
+var userIDPattern = regexp.MustCompile(`^user/\d+/`)
+
+// parseStreamID converts a stream ID into an article query
+func parseStreamID(streamID string) (database.ArticleStreamQuery, error) {
+	var q database.ArticleStreamQuery
+	streamID = userIDPattern.ReplaceAllString(streamID, "user/-/")
+
+	switch {
+	case streamID == "" || streamID == streamReadingList:
+	case streamID == streamStarred:
+		q.Starred = true
+	case streamID == streamRead:
+		q.Read = true
+	case strings.HasPrefix(streamID, labelPrefix):
+		q.Category = strings.TrimPrefix(streamID, labelPrefix)
+		if q.Category == "" {
+			return q, errUnknownStream
+		}
+	case strings.HasPrefix(streamID, feedPrefix):
+		id, err := strconv.ParseInt(strings.TrimPrefix(streamID, feedPrefix), 10, 64)
+		if err != nil || id <= 0 {
+			return q, errUnknownStream
+		}
+		q.FeedID = id
+	default:
+		return q, errUnknownStream
+	}
+	return q, nil
+}
+
+// parseStreamQuery builds an article query from the common stream parameters:
+// the stream ID, n (count), c (continuation), xt (excluded tag), ot/nt (time range) and r (order)
+func parseStreamQuery(r *http.Request, streamID string, limit int) (database.ArticleStreamQuery, error) {
+	q, err := parseStreamID(streamID)
+	if err != nil {
+		return q, err
+	}
+
+	if xt := userIDPattern.ReplaceAllString(r.FormValue("xt"), "user/-/"); xt == streamRead {
+		q.Unread = true
+	}
+	if ot, err := strconv.ParseInt(r.FormValue("ot"), 10, 64); err == nil && ot > 0 {
+		q.Since = time.Unix(ot, 0)
+	}
+	if nt, err := strconv.ParseInt(r.FormValue("nt"), 10, 64); err == nil && nt > 0 {
+		q.Until = time.Unix(nt, 0)
+	}
+	q.OldestFirst = r.FormValue("r") == "o"
+
+	q.Limit = defaultCount
+	if n, err := strconv.Atoi(r.FormValue("n")); err == nil && n > 0 {
+		q.Limit = n
+	}
+	if q.Limit > limit {
+		q.Limit = limit
+	}
+	if c, err := strconv.Atoi(r.FormValue("c")); err == nil && c > 0 {
+		q.Offset = c
+	}
+	return q, nil
+}
+
+// continuation returns the continuation token for the next page, or "" if this is the last page
+func continuation(q database.ArticleStreamQuery, returned int) string {
+	if returned < q.Limit {
+		return ""
+	}
+	return strconv.Itoa(q.Offset + returned)
+}
+
+// formatItemID returns the long form item ID for an article
+func formatItemID(id int64) string {
+	return fmt.Sprintf("%s%016x", itemIDPrefix, uint64(id))
+}
+
+// parseItemID accepts the long form ("tag:google.com,2005:reader/item/<hex>") and the
+// short form (signed decimal) item IDs
+func parseItemID(s string) (int64, error) {
+	if strings.HasPrefix(s, itemIDPrefix) {
+		v, err := strconv.ParseUint(strings.TrimPrefix(s, itemIDPrefix), 16, 64)
+		return int64(v), err
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// parseItemIDs parses all "i" parameters of a request
+func parseItemIDs(r *http.Request) ([]int64, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(r.Form["i"]))
+	for _, s := range r.Form["i"] {
+		id, err := parseItemID(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid item id %q", s)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+type link struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type itemContent struct {
+	Direction string `json:"direction"`
+	Content   string `json:"content"`
+}
+
+type origin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+type item struct {
+	ID            string      `json:"id"`
+	CrawlTimeMsec string      `json:"crawlTimeMsec"`
+	TimestampUsec string      `json:"timestampUsec"`
+	Published     int64       `json:"published"`
+	Updated       int64       `json:"updated"`
+	Title         string      `json:"title"`
+	Canonical     []link      `json:"canonical"`
+	Alternate     []link      `json:"alternate"`
+	Summary       itemContent `json:"summary"`
+	Author        string      `json:"author,omitempty"`
+	Categories    []string    `json:"categories"`
+	Origin        origin      `json:"origin"`
+	Enclosure     []link      `json:"enclosure,omitempty"`
+}
+
+type streamContents struct {
+	ID           string `json:"id"`
+	Updated      int64  `json:"updated"`
+	Items        []item `json:"items"`
+	Continuation string `json:"continuation,omitempty"`
+}
+
+// buildItems converts articles to protocol items. Content comes from the article
+// content cache, falling back to the feed summary for articles that were never opened.
+func buildItems(h *core.Handler, articles []models.Article) ([]item, error) {
+	ids := make([]int64, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	contents, err := h.DB.GetArticleContents(ids)
+	if err != nil {
+		return nil, err
+	}
+	feeds, err := h.DB.GetFeeds()
+	if err != nil {
+		return nil, err
+	}
+	feedsByID := make(map[int64]models.Feed, len(feeds))
+	for _, f := range feeds {
+		feedsByID[f.ID] = f
+	}
+
+	items := make([]item, 0, len(articles))
+	for _, a := range articles {
+		f := feedsByID[a.FeedID]
+
+		content, ok := contents[a.ID]
+		if !ok {
+			content = a.Summary
+		}
+		if content == "" && a.ImageURL != "" {
+			content = fmt.Sprintf(`<img src="%s">`, html.EscapeString(a.ImageURL))
+		}
+
+		categories := []string{streamReadingList}
+		if a.IsRead {
+			categories = append(categories, streamRead)
+		}
+		if a.IsFavorite {
+			categories = append(categories, streamStarred)
+		}
+		if f.Category != "" {
+			categories = append(categories, labelPrefix+f.Category)
+		}
+
+		it := item{
+			ID:            formatItemID(a.ID),
+			CrawlTimeMsec: strconv.FormatInt(a.PublishedAt.UnixMilli(), 10),
+			TimestampUsec: strconv.FormatInt(a.PublishedAt.UnixMicro(), 10),
+			Published:     a.PublishedAt.Unix(),
+			Updated:       a.PublishedAt.Unix(),
+			Title:         a.Title,
+			Canonical:     []link{{Href: a.URL}},
+			Alternate:     []link{{Href: a.URL, Type: "text/html"}},
+			Summary:       itemContent{Direction: "ltr", Content: content},
+			Author:        a.Author,
+			Categories:    categories,
+			Origin:        origin{StreamID: feedStreamID(a.FeedID), Title: a.FeedTitle, HTMLURL: f.Link},
+		}
+		if a.AudioURL != "" {
+			it.Enclosure = append(it.Enclosure, link{Href: a.AudioURL, Type: "audio/mpeg"})
+		}
+		if a.VideoURL != "" {
+			it.Enclosure = append(it.Enclosure, link{Href: a.VideoURL, Type: "video/mp4"})
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// HandleStreamContents returns the articles of a stream.
+// @Summary      Google Reader stream contents
+// @Description  The stream ID follows the path, e.g. /reader/api/0/stream/contents/feed/12. Supported streams: reading-list, starred, read, feed/<id> and user/-/label/<category>.
+// @Tags         greader
+// @Produce      json
+// @Param        n   query     int     false  "Number of items (default 20, max 1000)"
+// @Param        c   query     string  false  "Continuation token"
+// @Param        xt  query     string  false  "Exclude tag, e.g. user/-/state/com.google/read"
+// @Param        ot  query     int     false  "Only items published after this Unix time"
+// @Param        nt  query     int     false  "Only items published before this Unix time"
+// @Param        r   query     string  false  "Set to o for oldest first"
+// @Success      200  {object}  map[string]interface{}  "Stream contents"
+// @Failure      400  {object}  map[string]string  "Unknown stream"
+// @Router       /reader/api/0/stream/contents/{streamId} [get]
+func HandleStreamContents(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	streamID := strings.TrimPrefix(r.URL.Path, streamPathBase)
+	if streamID == "" {
+		streamID = r.FormValue("s")
+	}
+
+	q, err := parseStreamQuery(r, streamID, maxCount)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.DB.GetArticleStream(q)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	items, err := buildItems(h, articles)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, streamContents{
+		ID:           streamID,
+		Updated:      time.Now().Unix(),
+		Items:        items,
+		Continuation: continuation(q, len(items)),
+	})
+}
+
+// HandleStreamItemIDs returns only the item IDs of a stream, which clients use for incremental sync.
+// @Summary      Google Reader stream item IDs
+// @Tags         greader
+// @Produce      json
+// @Param        s   query     string  true   "Stream ID"
+// @Param        n   query     int     false  "Number of items (default 20, max 10000)"
+// @Param        c   query     string  false  "Continuation token"
+// @Param        xt  query     string  false  "Exclude tag"
+// @Success      200  {object}  map[string]interface{}  "Item references"
+// @Failure      400  {object}  map[string]string  "Unknown stream"
+// @Router       /reader/api/0/stream/items/ids [get]
+func HandleStreamItemIDs(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	q, err := parseStreamQuery(r, r.FormValue("s"), maxIDCount)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	ids, times, err := h.DB.GetArticleStreamIDs(q)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	type itemRef struct {
+		ID              string   `json:"id"`
+		DirectStreamIDs []string `json:"directStreamIds"`
+		TimestampUsec   string   `json:"timestampUsec"`
+	}
+	refs := make([]itemRef, len(ids))
+	for i, id := range ids {
+		refs[i] = itemRef{ID: strconv.FormatInt(id, 10), DirectStreamIDs: []string{}, TimestampUsec: strconv.FormatInt(times[i].UnixMicro(), 10)}
+	}
+
+	result := map[string]interface{}{"itemRefs": refs}
+	if c := continuation(q, len(ids)); c != "" {
+		result["continuation"] = c
+	}
+	response.JSON(w, result)
+}
+
+// HandleStreamItemContents returns the items with the given IDs.
+// @Summary      Google Reader item contents
+// @Tags         greader
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        i  formData  string  true  "Item ID (repeatable)"
+// @Success      200  {object}  map[string]interface{}  "Stream contents"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Router       /reader/api/0/stream/items/contents [post]
+func HandleStreamItemContents(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	ids, err := parseItemIDs(r)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	items := []item{}
+	if len(ids) > 0 {
+		articles, err := h.DB.GetArticleStream(database.ArticleStreamQuery{IDs: ids})
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		if items, err = buildItems(h, articles); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	response.JSON(w, streamContents{ID: streamReadingList, Updated: time.Now().Unix(), Items: items})
+}
+
+// HandleEditTag adds or removes the read and starred states of items.
+// @Summary      Google Reader edit tag
+// @Description  Mark items read/unread (user/-/state/com.google/read) or starred/unstarred (user/-/state/com.google/starred). Labels on individual items are not supported and ignored.
+// @Tags         greader
+// @Accept       x-www-form-urlencoded
+// @Produce      plain
+// @Param        i  formData  string  true   "Item ID (repeatable)"
+// @Param        a  formData  string  false  "Tag to add"
+// @Param        r  formData  string  false  "Tag to remove"
+// @Success      200  {string}  string  "OK"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Router       /reader/api/0/edit-tag [post]
+func HandleEditTag(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	ids, err := parseItemIDs(r)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	apply := func(tags []string, add bool) error {
+		for _, tag := range tags {
+			tag = userIDPattern.ReplaceAllString(tag, "user/-/")
+			for _, id := range ids {
+				var err error
+				switch tag {
+				case streamRead:
+					err = h.DB.MarkArticleRead(id, add)
+				case streamKeptUnread:
+					err = h.DB.MarkArticleRead(id, !add)
+				case streamStarred:
+					err = h.DB.SetArticleFavorite(id, add)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := apply(r.Form["a"], true); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if err := apply(r.Form["r"], false); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "OK")
+}
+
+// HandleMarkAllAsRead marks every article of a stream as read.
+// @Summary      Google Reader mark all as read
+// @Tags         greader
+// @Accept       x-www-form-urlencoded
+// @Produce      plain
+// @Param        s   formData  string  true   "Stream ID"
+// @Param        ts  formData  int     false  "Only mark items published before this time (microseconds)"
+// @Success      200  {string}  string  "OK"
+// @Failure      400  {object}  map[string]string  "Unknown stream"
+// @Router       /reader/api/0/mark-all-as-read [post]
+func HandleMarkAllAsRead(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, err := parseStreamID(r.FormValue("s"))
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if ts, err := strconv.ParseInt(r.FormValue("ts"), 10, 64); err == nil && ts > 0 {
+		q.Until = time.UnixMicro(ts)
+	}
+
+	if _, err := h.DB.MarkArticleStreamRead(q); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "OK")
+}
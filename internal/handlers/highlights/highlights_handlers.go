@@ -0,0 +1,250 @@
+package highlights
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+)
+
+// HighlightSearchResponse represents the response for highlight search
+type HighlightSearchResponse struct {
+	Highlights []database.HighlightSearchResult `json:"highlights"`
+	Total      int                              `json:"total"`
+	Page       int                              `json:"page"`
+	Limit      int                              `json:"limit"`
+	HasMore    bool                             `json:"has_more"`
+}
+
+// HandleHighlights handles GET and POST requests for highlights.
+// @Summary      List or create highlights
+// @Description  GET: Retrieve the highlights of an article. POST: Create a highlight on an article's content.
+// @Tags         highlights
+// @Accept       json
+// @Produce      json
+// @Param        article_id  query     int64   false  "Article ID (for GET)"
+// @Param        request     body      object  false  "Highlight details (for POST: article_id, quote, prefix, suffix, start_offset, end_offset, note, color)"
+// @Success      200  {array}   models.Highlight  "List of highlights (GET)"
+// @Success      201  {object}  models.Highlight  "Created highlight (POST)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      404  {object}  map[string]string  "Article not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /highlights [get]
+// @Router       /highlights [post]
+func HandleHighlights(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		articleID, err := strconv.ParseInt(r.URL.Query().Get("article_id"), 10, 64)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+
+		highlights, err := h.DB.GetHighlightsByArticle(articleID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, highlights)
+
+	case http.MethodPost:
+		var req models.Highlight
+		req.StartOffset = -1
+		req.EndOffset = -1
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if req.ArticleID <= 0 || strings.TrimSpace(req.Quote) == "" {
+			response.Error(w, errors.New("article_id and quote are required"), http.StatusBadRequest)
+			return
+		}
+		if req.StartOffset >= 0 && req.EndOffset < req.StartOffset {
+			response.Error(w, errors.New("end_offset must not be before start_offset"), http.StatusBadRequest)
+			return
+		}
+
+		if _, err := h.DB.GetArticleByID(req.ArticleID); err != nil {
+			response.Error(w, err, http.StatusNotFound)
+			return
+		}
+
+		id, err := h.DB.CreateHighlight(&req)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		pinArticleContent(h, req.ArticleID)
+
+		highlight, err := h.DB.GetHighlight(id)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		response.JSON(w, highlight)
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleHighlightUpdate updates the note and color of a highlight.
+// @Summary      Update a highlight
+// @Description  Update the note and color of an existing highlight
+// @Tags         highlights
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Highlight update details (id, note, color)"
+// @Success      200  {object}  models.Highlight  "Updated highlight"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      404  {object}  map[string]string  "Highlight not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /highlights/update [post]
+func HandleHighlightUpdate(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID    int64  `json:"id"`
+		Note  string `json:"note"`
+		Color string `json:"color"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.DB.GetHighlight(req.ID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if existing == nil {
+		response.Error(w, nil, http.StatusNotFound)
+		return
+	}
+
+	if err := h.DB.UpdateHighlight(req.ID, req.Note, req.Color); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	highlight, err := h.DB.GetHighlight(req.ID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, highlight)
+}
+
+// HandleHighlightDelete deletes a highlight by ID.
+// @Summary      Delete a highlight
+// @Description  Delete an existing highlight by its ID
+// @Tags         highlights
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Highlight ID to delete"
+// @Success      200  {object}  map[string]string  "Deletion status"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /highlights/delete [post]
+func HandleHighlightDelete(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.DeleteHighlight(req.ID); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, map[string]string{"status": "deleted"})
+}
+
+// HandleSearchHighlights searches highlight quotes and notes.
+// @Summary      Search highlights
+// @Description  Full-text search over highlight quotes and notes, newest first. Supports FTS5 syntax.
+// @Tags         highlights
+// @Accept       json
+// @Produce      json
+// @Param        q      query     string  true   "Search query"
+// @Param        page   query     int     false  "Page number (default: 1)"  minimum(1)
+// @Param        limit  query     int     false  "Items per page (default: 50, max: 500)"  minimum(1)  maximum(500)
+// @Success      200  {object}  HighlightSearchResponse  "Matching highlights with snippets"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /highlights/search [get]
+func HandleSearchHighlights(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	query := q.Get("q")
+	if query == "" {
+		response.Error(w, errors.New("search query is required"), http.StatusBadRequest)
+		return
+	}
+
+	page := 1
+	if p, err := strconv.Atoi(q.Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	limit := 50
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	offset := (page - 1) * limit
+
+	results, total, err := h.DB.SearchHighlights(query, limit, offset)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidSearchQuery) {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, HighlightSearchResponse{
+		Highlights: results,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		HasMore:    offset+len(results) < total,
+	})
+}
+
+// pinArticleContent persists content that is only in the memory cache, so the
+// highlighted text is kept by the database cleanup that protects annotated articles.
+func pinArticleContent(h *core.Handler, articleID int64) {
+	if _, found, err := h.DB.GetArticleContent(articleID); err != nil || found {
+		return
+	}
+	if content, found := h.ContentCache.Get(articleID); found && content != "" {
+		_ = h.DB.SetArticleContent(articleID, content)
+	}
+}
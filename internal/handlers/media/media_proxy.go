@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"MrRSS/internal/cache"
+	"MrRSS/internal/feed/httpoptions"
 	"MrRSS/internal/handlers/core"
 	"MrRSS/internal/handlers/response"
 	"MrRSS/internal/utils/fileutil"
@@ -154,6 +155,7 @@ func getSmartReferer(imageURL, originalReferer string) string {
 // @Param        url         query     string  true  "Media URL to proxy"
 // @Param        referer     query     string  false  "Referer URL for hotlink protection"
 // @Param        force_cache query     bool    false  "Force caching even if globally disabled"
+// @Param        feed_id     query     int64   false  "Feed of the media, whose HTTP options are used for media on its site"
 // @Success      200  {file}  file  "Media file"
 // @Failure      400  {object}  map[string]string  "Bad request (missing or invalid URL)"
 // @Failure      403  {object}  map[string]string  "Media proxy is disabled"
@@ -224,6 +226,12 @@ func HandleMediaProxy(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Media of paywalled feeds may need the feed's cookies or authentication
+	var transport http.RoundTripper
+	if feedID, err := strconv.ParseInt(r.URL.Query().Get("feed_id"), 10, 64); err == nil {
+		transport = feedTransport(h, feedID)
+	}
+
 	// Try cache first if enabled
 	if mediaCacheEnabled == "true" {
 		// Get media cache directory
@@ -239,7 +247,7 @@ func HandleMediaProxy(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 				// Continue to fallback if enabled
 			} else {
 				// Get media (from cache or download)
-				data, contentType, err := mediaCache.Get(mediaURL, referer)
+				data, contentType, err := mediaCache.GetWithTransport(mediaURL, referer, transport)
 				if err == nil {
 					// Success! Serve from cache
 					w.Header().Set("Content-Type", contentType)
@@ -256,7 +264,7 @@ func HandleMediaProxy(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 
 	// Fallback: Direct proxy if enabled
 	if mediaProxyFallback == "true" {
-		err := proxyMediaDirectly(mediaURL, referer, w)
+		err := proxyMediaDirectly(mediaURL, referer, w, transport)
 		if err == nil {
 			return // Success
 		}
@@ -1749,10 +1757,31 @@ func HandleWebpageResource(h *core.Handler, w http.ResponseWriter, r *http.Reque
 	}
 }
 
-// proxyMediaDirectly proxies media directly without caching
-func proxyMediaDirectly(mediaURL, referer string, w http.ResponseWriter) error {
+// feedTransport returns a transport that applies the HTTP options of a feed to the requests
+// to its site, or nil if the feed has no options. The options are applied to each request
+// separately, so that they are not sent along when redirected to another site.
+func feedTransport(h *core.Handler, feedID int64) http.RoundTripper {
+	f, err := h.DB.GetFeedByID(feedID)
+	if err != nil || f == nil {
+		return nil
+	}
+	options, err := h.DB.GetFeedHTTPOptions(feedID)
+	if err != nil {
+		log.Printf("Failed to load HTTP options of feed %d: %v", feedID, err)
+		return nil
+	}
+	if httpoptions.IsEmpty(options) {
+		return nil
+	}
+	return httpoptions.Transport(http.DefaultTransport, options, f.URL, f.Link)
+}
+
+// proxyMediaDirectly proxies media directly without caching. A nil transport uses the
+// default transport.
+func proxyMediaDirectly(mediaURL, referer string, w http.ResponseWriter, transport http.RoundTripper) error {
 	client := &http.Client{
-		Timeout: 30 * time.Second,
+		Timeout:   30 * time.Second,
+		Transport: transport,
 	}
 
 	req, err := http.NewRequest("GET", mediaURL, nil)
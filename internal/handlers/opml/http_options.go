@@ -0,0 +1,41 @@
+package opml
+
+import (
+	"log"
+
+	"MrRSS/internal/feed/httpoptions"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+)
+
+// addHTTPOptions sets the HTTP options of feeds for export, without their secrets
+func addHTTPOptions(h *core.Handler, feeds []models.Feed) {
+	for i := range feeds {
+		options, err := h.DB.GetFeedHTTPOptions(feeds[i].ID)
+		if err != nil {
+			log.Printf("Error loading HTTP options of feed %d: %v", feeds[i].ID, err)
+			continue
+		}
+		if !httpoptions.IsEmpty(options) {
+			feeds[i].HTTPOptions = httpoptions.Metadata(options)
+		}
+	}
+}
+
+// saveHTTPOptions saves the HTTP options of an imported feed. Imported options have no
+// secrets, so those of existing options are kept.
+func saveHTTPOptions(h *core.Handler, feedID int64, options *models.FeedHTTPOptions) error {
+	if options == nil {
+		return nil
+	}
+	if err := httpoptions.Validate(options); err != nil {
+		return err
+	}
+	stored, err := h.DB.GetFeedHTTPOptions(feedID)
+	if err != nil {
+		return err
+	}
+	httpoptions.MergeSecrets(options, stored)
+	options.FeedID = feedID
+	return h.DB.SaveFeedHTTPOptions(options)
+}
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"MrRSS/internal/feed/source"
 	"MrRSS/internal/handlers/core"
 	"MrRSS/internal/handlers/response"
 	"MrRSS/internal/jsonimport"
@@ -136,8 +137,10 @@ func HandleOPMLImport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		var feedID int64
 		var err error
 
-		// Check if feed has XPath configuration
-		if f.Type == "HTML+XPath" || f.Type == "XML+XPath" {
+		// Check if feed has XPath or JSON API configuration
+		if f.Type == string(source.TypeJSON) {
+			feedID, err = h.Fetcher.ImportJSONSubscription(f)
+		} else if f.Type == "HTML+XPath" || f.Type == "XML+XPath" {
 			feedID, err = h.Fetcher.AddXPathSubscription(
 				f.URL, f.Category, f.Title, f.Type,
 				f.XPathItem, f.XPathItemTitle, f.XPathItemContent, f.XPathItemUri,
@@ -145,7 +148,7 @@ func HandleOPMLImport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 				f.XPathItemThumbnail, f.XPathItemCategories, f.XPathItemUid,
 			)
 		} else {
-			feedID, err = h.Fetcher.ImportSubscription(f.Title, f.URL, f.Category)
+			feedID, err = h.Fetcher.ImportSubscription(f.Title, f.URL, f.Category, f.Type)
 		}
 
 		if err != nil {
@@ -161,6 +164,10 @@ func HandleOPMLImport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		if err := saveHTTPOptions(h, feedID, f.HTTPOptions); err != nil {
+			log.Printf("Error saving HTTP options for feed %s: %v", f.Title, err)
+		}
+
 		feedIDs = append(feedIDs, feedID)
 	}
 
@@ -200,6 +207,7 @@ func HandleOPMLExport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("[OPML Export] Exporting %d local feeds (excluded %d FreshRSS feeds)",
 		len(localFeeds), len(feeds)-len(localFeeds))
+	addHTTPOptions(h, localFeeds)
 
 	data, err := opml.Generate(localFeeds)
 	if err != nil {
@@ -331,8 +339,10 @@ func HandleOPMLImportDialog(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		var feedID int64
 		var err error
 
-		// Check if feed has XPath configuration
-		if f.Type == "HTML+XPath" || f.Type == "XML+XPath" {
+		// Check if feed has XPath or JSON API configuration
+		if f.Type == string(source.TypeJSON) {
+			feedID, err = h.Fetcher.ImportJSONSubscription(f)
+		} else if f.Type == "HTML+XPath" || f.Type == "XML+XPath" {
 			feedID, err = h.Fetcher.AddXPathSubscription(
 				f.URL, f.Category, f.Title, f.Type,
 				f.XPathItem, f.XPathItemTitle, f.XPathItemContent, f.XPathItemUri,
@@ -340,7 +350,7 @@ func HandleOPMLImportDialog(h *core.Handler, w http.ResponseWriter, r *http.Requ
 				f.XPathItemThumbnail, f.XPathItemCategories, f.XPathItemUid,
 			)
 		} else {
-			feedID, err = h.Fetcher.ImportSubscription(f.Title, f.URL, f.Category)
+			feedID, err = h.Fetcher.ImportSubscription(f.Title, f.URL, f.Category, f.Type)
 		}
 
 		if err != nil {
@@ -356,6 +366,10 @@ func HandleOPMLImportDialog(h *core.Handler, w http.ResponseWriter, r *http.Requ
 			}
 		}
 
+		if err := saveHTTPOptions(h, feedID, f.HTTPOptions); err != nil {
+			log.Printf("Error saving HTTP options for feed %s: %v", f.Title, err)
+		}
+
 		feedIDs = append(feedIDs, feedID)
 	}
 
@@ -416,6 +430,7 @@ func HandleOPMLExportDialog(h *core.Handler, w http.ResponseWriter, r *http.Requ
 
 	log.Printf("[OPML Export Dialog] Exporting %d local feeds (excluded %d FreshRSS feeds)",
 		len(localFeeds), len(feeds)-len(localFeeds))
+	addHTTPOptions(h, localFeeds)
 
 	// Type assert to *application.App to access Dialog
 	app, ok := h.App.(*application.App)
@@ -67,11 +67,14 @@ func HandleOPMLImport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 	// Import feeds
 	imported := 0
 	for _, feed := range feeds {
-		_, err := h.DB.AddFeed(&feed)
+		feedID, err := h.DB.AddFeed(&feed)
 		if err != nil {
 			log.Printf("Error importing feed %s: %v", feed.URL, err)
 			continue
 		}
+		if err := saveHTTPOptions(h, feedID, feed.HTTPOptions); err != nil {
+			log.Printf("Error saving HTTP options for feed %s: %v", feed.URL, err)
+		}
 		imported++
 	}
 
@@ -119,6 +122,7 @@ func HandleOPMLExport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate OPML content
+	addHTTPOptions(h, feeds)
 	data, err := opml.Generate(feeds)
 	if err != nil {
 		response.Error(w, err, http.StatusInternalServerError)
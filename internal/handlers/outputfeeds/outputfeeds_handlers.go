@@ -0,0 +1,332 @@
+// Package outputfeeds serves saved filters, tags and favorites as RSS, Atom and JSON feeds
+// in server mode. Feeds are authenticated with the per-user token from /api/auth/feed-token,
+// passed as the "token" query parameter so that any feed reader can subscribe.
+// Single feeds can also be downloaded as JSON Feed documents through the regular API.
+package outputfeeds
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/feedgen"
+	"MrRSS/internal/handlers/article"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+)
+
+const (
+	defaultItemLimit = 50
+	maxItemLimit     = 200
+)
+
+var errNotFound = errors.New("not found")
+
+// HandleSavedFilterFeed serves the articles matching a saved filter.
+// @Summary      Saved filter feed
+// @Description  Serve the newest articles matching a saved filter. The extension selects the format: .xml/.rss (RSS 2.0), .atom (Atom 1.0) or .json (JSON Feed 1.1).
+// @Tags         output-feeds
+// @Produce      xml
+// @Produce      json
+// @Param        file   path      string  true   "Saved filter ID with format extension, e.g. 3.atom"
+// @Param        token  query     string  true   "Output feed token"
+// @Param        limit  query     int     false  "Maximum number of items (default: 50, max: 200)"
+// @Success      200  {string}  string  "Feed document"
+// @Failure      401  {string}  string  "Unauthorized"
+// @Failure      404  {string}  string  "Saved filter not found"
+// @Router       /feeds/saved-filter/{file} [get]
+func HandleSavedFilterFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	serveFeed(h, w, r, func(id int64, limit int) (*feedgen.Feed, []models.Article, error) {
+		filter, err := h.DB.GetSavedFilter(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if filter == nil {
+			return nil, nil, errNotFound
+		}
+
+		var conditions []article.FilterCondition
+		if filter.Conditions != "" {
+			if err := json.Unmarshal([]byte(filter.Conditions), &conditions); err != nil {
+				return nil, nil, fmt.Errorf("invalid saved filter conditions: %w", err)
+			}
+		}
+
+		condition, args, err := article.FilterSQL(h, conditions)
+		if err != nil {
+			return nil, nil, err
+		}
+		articles, err := h.DB.GetArticlesMatching(condition, args, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &feedgen.Feed{Title: filter.Name, Description: "MrRSS saved filter: " + filter.Name}, articles, nil
+	})
+}
+
+// HandleTagFeed serves the articles with a tag, directly or through their feed.
+// @Summary      Tag feed
+// @Description  Serve the newest articles tagged with a tag or belonging to a feed with the tag. The extension selects the format: .xml/.rss, .atom or .json.
+// @Tags         output-feeds
+// @Produce      xml
+// @Produce      json
+// @Param        file   path      string  true   "Tag ID with format extension, e.g. 2.xml"
+// @Param        token  query     string  true   "Output feed token"
+// @Param        limit  query     int     false  "Maximum number of items (default: 50, max: 200)"
+// @Success      200  {string}  string  "Feed document"
+// @Failure      401  {string}  string  "Unauthorized"
+// @Failure      404  {string}  string  "Tag not found"
+// @Router       /feeds/tag/{file} [get]
+func HandleTagFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	serveFeed(h, w, r, func(id int64, limit int) (*feedgen.Feed, []models.Article, error) {
+		tag, err := h.DB.GetTagByID(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if tag == nil {
+			return nil, nil, errNotFound
+		}
+
+		articles, err := h.DB.GetArticlesWithTag(id, limit, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &feedgen.Feed{Title: tag.Name, Description: "MrRSS tag: " + tag.Name}, articles, nil
+	})
+}
+
+// HandleFavoritesFeed serves the favorite articles.
+// @Summary      Favorites feed
+// @Description  Serve the most recently published favorite articles. The extension selects the format: favorites.xml/.rss, favorites.atom or favorites.json.
+// @Tags         output-feeds
+// @Produce      xml
+// @Produce      json
+// @Param        file   path      string  true   "favorites with format extension, e.g. favorites.json"
+// @Param        token  query     string  true   "Output feed token"
+// @Param        limit  query     int     false  "Maximum number of items (default: 50, max: 200)"
+// @Success      200  {string}  string  "Feed document"
+// @Failure      401  {string}  string  "Unauthorized"
+// @Router       /feeds/{file} [get]
+func HandleFavoritesFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if strings.TrimSuffix(r.PathValue("file"), path.Ext(r.PathValue("file"))) != "favorites" {
+		http.NotFound(w, r)
+		return
+	}
+
+	serveFeed(h, w, r, func(_ int64, limit int) (*feedgen.Feed, []models.Article, error) {
+		articles, err := h.DB.GetArticles("favorites", 0, "", false, limit, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &feedgen.Feed{Title: "Favorites", Description: "MrRSS favorite articles"}, articles, nil
+	})
+}
+
+// feedLoader returns the feed metadata and articles for the ID in the request path
+type feedLoader func(id int64, limit int) (*feedgen.Feed, []models.Article, error)
+
+// serveFeed authenticates the request, loads the feed and renders it in the requested format
+func serveFeed(h *core.Handler, w http.ResponseWriter, r *http.Request, load feedLoader) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !authenticate(h, r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	file := r.PathValue("file")
+	ext := path.Ext(file)
+	format, ok := feedgen.FormatFromExtension(ext)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	// Favorites have no ID, so a non-numeric name is only an error for the other feeds
+	id, _ := strconv.ParseInt(strings.TrimSuffix(file, ext), 10, 64)
+
+	limit := defaultItemLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxItemLimit)
+	}
+
+	feed, articles, err := load(id, limit)
+	if errors.Is(err, errNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("Error building output feed %s: %v", r.URL.Path, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	base := baseURL(h, r)
+	feed.Link = base + "/"
+	feed.FeedURL = base + selfPath(r)
+	feed.Items = buildItems(h, articles)
+
+	data, contentType, err := feedgen.Render(feed, format)
+	if err != nil {
+		log.Printf("Error rendering output feed %s: %v", r.URL.Path, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(data)
+}
+
+// authenticate checks the token query parameter against the users' output feed tokens
+func authenticate(h *core.Handler, r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		return false
+	}
+	user, err := h.DB.GetUserByFeedToken(crypto.HashToken(token))
+	if err != nil {
+		log.Printf("Error authenticating output feed request: %v", err)
+		return false
+	}
+	return user != nil
+}
+
+// buildItems converts articles to feed items with their translated titles, AI summaries,
+// cached content and tags
+func buildItems(h *core.Handler, articles []models.Article) []feedgen.Item {
+	ids := make([]int64, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	tagsByArticle, err := h.DB.GetTagsForArticles(ids)
+	if err != nil {
+		log.Printf("Error loading article tags for output feed: %v", err)
+	}
+
+	items := make([]feedgen.Item, 0, len(articles))
+	for _, a := range articles {
+		item := feedgen.Item{
+			ID:        fmt.Sprintf("urn:mrrss:article:%d", a.ID),
+			Title:     a.Title,
+			URL:       a.URL,
+			Author:    a.Author,
+			Source:    a.FeedTitle,
+			Summary:   a.Summary,
+			ImageURL:  a.ImageURL,
+			AudioURL:  a.AudioURL,
+			VideoURL:  a.VideoURL,
+			Published: a.PublishedAt,
+		}
+		if a.TranslatedTitle != "" && a.TranslatedTitle != a.Title {
+			item.Title = a.TranslatedTitle
+			item.OriginalTitle = a.Title
+		}
+		if content, found, err := h.DB.GetArticleContent(a.ID); err == nil && found {
+			item.ContentHTML = content
+		}
+		for _, tag := range tagsByArticle[a.ID] {
+			item.Tags = append(item.Tags, tag.Name)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// baseURL returns the scheme and host the request was made to. The reverse proxy headers
+// are only honored with the -trust-proxy flag, as any client can send them otherwise.
+func baseURL(h *core.Handler, r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || (h.TrustProxy && strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")) {
+		scheme = "https"
+	}
+	host := r.Host
+	if forwarded := r.Header.Get("X-Forwarded-Host"); h.TrustProxy && forwarded != "" {
+		host = forwarded
+	}
+	return scheme + "://" + host
+}
+
+// selfPath returns the path and query of the feed's self link. The token is left out so
+// that it is not published with the feed document.
+func selfPath(r *http.Request) string {
+	query := r.URL.Query()
+	query.Del("token")
+	if len(query) == 0 {
+		return r.URL.EscapedPath()
+	}
+	return r.URL.EscapedPath() + "?" + query.Encode()
+}
+
+// HandleJSONFeedExport downloads the articles of a feed as a JSON Feed document.
+// @Summary      Export feed as JSON Feed
+// @Description  Download the newest articles of a feed as a JSON Feed 1.1 document, with translated titles, AI summaries, cached content and tags
+// @Tags         output-feeds
+// @Produce      json
+// @Param        feed_id  query     int  true   "Feed ID"
+// @Param        limit    query     int  false  "Maximum number of items (default: 50, max: 200)"
+// @Success      200  {object}  feedgen.JSONFeed  "JSON Feed document"
+// @Failure      400  {object}  map[string]string  "Invalid feed ID"
+// @Failure      404  {object}  map[string]string  "Feed not found"
+// @Router       /feeds/export-jsonfeed [get]
+func HandleJSONFeedExport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	feedID, err := strconv.ParseInt(r.URL.Query().Get("feed_id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	f, err := h.DB.GetFeedByID(feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		response.Error(w, errNotFound, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	limit := defaultItemLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxItemLimit)
+	}
+	articles, err := h.DB.GetArticles("", feedID, "", false, limit, 0)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	data, contentType, err := feedgen.Render(&feedgen.Feed{
+		Title:       f.Title,
+		Description: f.Description,
+		Link:        f.Link,
+		FeedURL:     f.URL,
+		Items:       buildItems(h, articles),
+	}, feedgen.FormatJSON)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=feed-%d.json", feedID))
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(data)
+}
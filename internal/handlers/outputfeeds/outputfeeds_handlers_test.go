@@ -0,0 +1,193 @@
+package outputfeeds_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/database"
+	ff "MrRSS/internal/feed"
+	"MrRSS/internal/feedgen"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+	"MrRSS/internal/routes"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const feedToken = "output-feed-token"
+
+func setupServer(t *testing.T) (*core.Handler, http.Handler, []int64) {
+	t.Helper()
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	h := core.NewHandler(db, ff.NewFetcher(db), nil, nil)
+
+	userID, err := db.CreateUser("reader", "unused", false)
+	if err != nil {
+		t.Fatalf("CreateUser error: %v", err)
+	}
+	if err := db.SetUserFeedToken(userID, crypto.HashToken(feedToken)); err != nil {
+		t.Fatalf("SetUserFeedToken error: %v", err)
+	}
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Tech", URL: "https://tech.example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	now := time.Now()
+	articles := []*models.Article{
+		{FeedID: feedID, Title: "Go generics", URL: "https://tech.example.com/1", PublishedAt: now.Add(-time.Hour)},
+		{FeedID: feedID, Title: "Rust lifetimes", URL: "https://tech.example.com/2", PublishedAt: now},
+	}
+	if err := db.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatalf("SaveArticles error: %v", err)
+	}
+
+	var ids []int64
+	rows, _ := db.Query(`SELECT id FROM articles ORDER BY url`)
+	for rows.Next() {
+		var id int64
+		rows.Scan(&id)
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	mux := http.NewServeMux()
+	routes.RegisterSyncRoutes(mux, h)
+	return h, mux, ids
+}
+
+func get(t *testing.T, srv http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestOutputFeeds(t *testing.T) {
+	h, srv, ids := setupServer(t)
+	db := h.DB
+
+	// Translated title and AI summary of the first article
+	db.Exec(`UPDATE articles SET translated_title = ?, summary = ?, is_favorite = 1 WHERE id = ?`, "Go 泛型", "An overview of generics.", ids[0])
+
+	t.Run("Requires token", func(t *testing.T) {
+		if w := get(t, srv, "/feeds/favorites.json"); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 without token, got %d", w.Code)
+		}
+		if w := get(t, srv, "/feeds/favorites.json?token=wrong"); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 with wrong token, got %d", w.Code)
+		}
+	})
+
+	t.Run("Favorites JSON Feed", func(t *testing.T) {
+		w := get(t, srv, "/feeds/favorites.json?token="+feedToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/feed+json") {
+			t.Errorf("Unexpected content type: %s", w.Header().Get("Content-Type"))
+		}
+
+		var doc feedgen.JSONFeed
+		if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("Invalid JSON Feed: %v", err)
+		}
+		if doc.Version != feedgen.JSONFeedVersion || len(doc.Items) != 1 {
+			t.Fatalf("Unexpected feed: %+v", doc)
+		}
+		item := doc.Items[0]
+		if item.Title != "Go 泛型" || item.Summary != "An overview of generics." || item.MrRSS == nil || item.MrRSS.OriginalTitle != "Go generics" {
+			t.Errorf("Expected translated title and summary, got %+v", item)
+		}
+	})
+
+	t.Run("Saved filter Atom", func(t *testing.T) {
+		filterID, err := db.AddSavedFilter(&models.SavedFilter{
+			Name:       "Rust",
+			Conditions: `[{"field":"article_title","operator":"contains","value":"rust"}]`,
+		})
+		if err != nil {
+			t.Fatalf("AddSavedFilter failed: %v", err)
+		}
+
+		w := get(t, srv, "/feeds/saved-filter/"+strconv.FormatInt(filterID, 10)+".atom?token="+feedToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		parsed, err := gofeed.NewParser().ParseString(w.Body.String())
+		if err != nil {
+			t.Fatalf("Invalid Atom: %v", err)
+		}
+		if parsed.FeedType != "atom" || parsed.Title != "Rust" || len(parsed.Items) != 1 || parsed.Items[0].Title != "Rust lifetimes" {
+			t.Errorf("Unexpected Atom feed: %+v", parsed)
+		}
+
+		if w := get(t, srv, "/feeds/saved-filter/999.atom?token="+feedToken); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for unknown filter, got %d", w.Code)
+		}
+	})
+
+	t.Run("Tag RSS", func(t *testing.T) {
+		tagID, _ := db.AddTag(&models.Tag{Name: "Reading", Color: "#000000"})
+		if err := db.AddArticleTag(ids[1], tagID); err != nil {
+			t.Fatalf("AddArticleTag failed: %v", err)
+		}
+
+		w := get(t, srv, "/feeds/tag/"+strconv.FormatInt(tagID, 10)+".xml?token="+feedToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		parsed, err := gofeed.NewParser().ParseString(w.Body.String())
+		if err != nil {
+			t.Fatalf("Invalid RSS: %v", err)
+		}
+		if parsed.FeedType != "rss" || len(parsed.Items) != 1 || parsed.Items[0].Link != "https://tech.example.com/2" {
+			t.Errorf("Unexpected RSS feed: %+v", parsed)
+		}
+		if len(parsed.Items[0].Categories) != 1 || parsed.Items[0].Categories[0] != "Reading" {
+			t.Errorf("Expected the article tag as category, got %v", parsed.Items[0].Categories)
+		}
+	})
+
+	t.Run("Self link", func(t *testing.T) {
+		selfLink := func(header http.Header) string {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/feeds/favorites.json?limit=5&token="+feedToken, nil)
+			r.Header = header
+			srv.ServeHTTP(w, r)
+			var doc feedgen.JSONFeed
+			json.Unmarshal(w.Body.Bytes(), &doc)
+			return doc.FeedURL
+		}
+		forwarded := http.Header{"X-Forwarded-Host": {"rss.example.com"}, "X-Forwarded-Proto": {"https"}}
+
+		// The token is left out, the proxy headers are ignored unless trusted
+		if got := selfLink(forwarded); got != "http://example.com/feeds/favorites.json?limit=5" {
+			t.Errorf("Unexpected self link: %s", got)
+		}
+		h.TrustProxy = true
+		defer func() { h.TrustProxy = false }()
+		if got := selfLink(forwarded); got != "https://rss.example.com/feeds/favorites.json?limit=5" {
+			t.Errorf("Unexpected self link behind a trusted proxy: %s", got)
+		}
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		if w := get(t, srv, "/feeds/favorites.html?token="+feedToken); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for unknown format, got %d", w.Code)
+		}
+	})
+}
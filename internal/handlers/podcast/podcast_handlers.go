@@ -0,0 +1,298 @@
+package podcast
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+)
+
+// DownloadsResponse lists the podcast downloads and the storage they use
+type DownloadsResponse struct {
+	Episodes   []models.PodcastEpisode `json:"episodes"`
+	UsedBytes  int64                   `json:"used_bytes"`
+	QuotaBytes int64                   `json:"quota_bytes"` // 0 if unlimited
+}
+
+// HandlePodcastEpisode returns the podcast metadata and playback position of an article.
+// @Summary      Get a podcast episode
+// @Description  Get the episode metadata, playback position and download state of an article with an audio enclosure. Chapters published as a separate document are fetched on first request.
+// @Tags         podcasts
+// @Produce      json
+// @Param        article_id  query     int64  true  "Article ID"
+// @Success      200  {object}  models.PodcastEpisode  "Episode"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      404  {object}  map[string]string  "Article has no audio"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /podcasts/episode [get]
+func HandlePodcastEpisode(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	articleID, err := strconv.ParseInt(r.URL.Query().Get("article_id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	episode, err := h.DB.EnsurePodcastEpisode(articleID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if episode == nil {
+		response.Error(w, errors.New("article has no audio enclosure"), http.StatusNotFound)
+		return
+	}
+	if err := h.Podcasts.LoadChapters(r.Context(), episode); err != nil {
+		log.Printf("Error loading chapters of episode %d: %v", articleID, err)
+	}
+	response.JSON(w, episode)
+}
+
+// HandlePodcastProgress saves the playback position of an episode.
+// @Summary      Save playback position
+// @Description  Save the playback position and duration of an episode, in seconds
+// @Tags         podcasts
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Progress (article_id, position, duration, played)"
+// @Success      200  {object}  map[string]bool  "Success"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /podcasts/progress [post]
+func HandlePodcastProgress(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ArticleID int64   `json:"article_id"`
+		Position  float64 `json:"position"`
+		Duration  float64 `json:"duration"`
+		Played    bool    `json:"played"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if req.ArticleID <= 0 || req.Position < 0 || req.Duration < 0 {
+		response.Error(w, errors.New("invalid progress"), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.UpdatePodcastProgress(req.ArticleID, req.Position, req.Duration, req.Played); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]bool{"success": true})
+}
+
+// HandlePodcastDownload queues an episode for download.
+// @Summary      Download an episode
+// @Description  Queue the audio enclosure of an article for download to the data directory
+// @Tags         podcasts
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Episode (article_id)"
+// @Success      200  {object}  models.PodcastEpisode  "Queued episode"
+// @Failure      400  {object}  map[string]string  "Bad request or article has no audio"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /podcasts/download [post]
+func HandlePodcastDownload(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ArticleID int64 `json:"article_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Podcasts.Queue(req.ArticleID); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	episode, err := h.DB.GetPodcastEpisode(req.ArticleID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, episode)
+}
+
+// HandlePodcastDownloadDelete cancels or deletes the download of an episode.
+// @Summary      Delete a download
+// @Description  Cancel a queued or running download, or delete the downloaded file of an episode
+// @Tags         podcasts
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Episode (article_id)"
+// @Success      200  {object}  map[string]bool  "Success"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /podcasts/download/delete [post]
+func HandlePodcastDownloadDelete(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ArticleID int64 `json:"article_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Podcasts.Delete(req.ArticleID); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]bool{"success": true})
+}
+
+// HandlePodcastDownloads lists the downloaded and queued episodes.
+// @Summary      List downloads
+// @Description  List the downloaded and queued episodes, newest first, with the storage used and the quota
+// @Tags         podcasts
+// @Produce      json
+// @Param        feed_id  query     int64  false  "Only the episodes of this feed"
+// @Success      200  {object}  DownloadsResponse  "Downloads"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /podcasts/downloads [get]
+func HandlePodcastDownloads(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	feedID, _ := strconv.ParseInt(r.URL.Query().Get("feed_id"), 10, 64)
+	episodes, err := h.DB.GetPodcastDownloads(feedID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	used, err := h.Podcasts.UsedBytes()
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if episodes == nil {
+		episodes = []models.PodcastEpisode{}
+	}
+	response.JSON(w, DownloadsResponse{Episodes: episodes, UsedBytes: used, QuotaBytes: h.Podcasts.QuotaBytes()})
+}
+
+// HandlePodcastFile serves the downloaded audio of an episode.
+// @Summary      Play a downloaded episode
+// @Description  Serve the downloaded audio file of an episode, with support for range requests
+// @Tags         podcasts
+// @Produce      octet-stream
+// @Param        article_id  query     int64  true  "Article ID"
+// @Success      200  {file}    binary  "Audio file"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      404  {object}  map[string]string  "Episode not downloaded"
+// @Router       /podcasts/file [get]
+func HandlePodcastFile(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	articleID, err := strconv.ParseInt(r.URL.Query().Get("article_id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	episode, err := h.DB.GetPodcastEpisode(articleID)
+	if err != nil || episode == nil || episode.DownloadStatus != models.DownloadDone {
+		response.Error(w, errors.New("episode not downloaded"), http.StatusNotFound)
+		return
+	}
+	path, err := h.Podcasts.FilePath(episode)
+	if err != nil {
+		response.Error(w, err, http.StatusNotFound)
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		response.Error(w, errors.New("episode not downloaded"), http.StatusNotFound)
+		return
+	}
+
+	if episode.EnclosureType != "" {
+		w.Header().Set("Content-Type", episode.EnclosureType)
+	}
+	http.ServeFile(w, r, path)
+}
+
+// HandlePodcastFeedSettings gets or saves the podcast settings of a feed.
+// @Summary      Podcast feed settings
+// @Description  GET: Get the auto-download and retention settings of a feed. POST: Save them.
+// @Tags         podcasts
+// @Accept       json
+// @Produce      json
+// @Param        feed_id  query     int64                       false  "Feed ID (for GET)"
+// @Param        request  body      models.PodcastFeedSettings  false  "Settings (for POST)"
+// @Success      200  {object}  models.PodcastFeedSettings  "Settings"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /podcasts/feed-settings [get]
+// @Router       /podcasts/feed-settings [post]
+func HandlePodcastFeedSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		feedID, err := strconv.ParseInt(r.URL.Query().Get("feed_id"), 10, 64)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		settings, err := h.DB.GetPodcastFeedSettings(feedID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, settings)
+
+	case http.MethodPost:
+		var req models.PodcastFeedSettings
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if req.FeedID <= 0 || req.KeepEpisodes < 0 || req.RetentionDays < 0 {
+			response.Error(w, errors.New("invalid podcast settings"), http.StatusBadRequest)
+			return
+		}
+		if _, err := h.DB.GetFeedByID(req.FeedID); err != nil {
+			response.Error(w, err, http.StatusNotFound)
+			return
+		}
+
+		if err := h.DB.SavePodcastFeedSettings(&req); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		// Apply lowered retention limits right away
+		go h.Podcasts.Cleanup()
+		response.JSON(w, req)
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
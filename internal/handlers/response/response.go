@@ -3,6 +3,7 @@ package response
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 
 	apperrors "MrRSS/internal/errors"
@@ -70,3 +71,34 @@ func Error(w http.ResponseWriter, err error, defaultStatus int) {
 	}
 	_ = json.NewEncoder(w).Encode(resp)
 }
+
+// SSE writes a stream of Server-Sent Events
+type SSE struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// NewSSE starts a Server-Sent Events response
+func NewSSE(w http.ResponseWriter) *SSE {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	return &SSE{w: w, rc: http.NewResponseController(w)}
+}
+
+// Send writes an event with JSON data and flushes it to the client
+func (s *SSE) Send(event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
@@ -11,13 +11,13 @@ import (
 
 // HandleApplyRule applies a rule to matching articles
 // @Summary      Apply rule to articles
-// @Description  Apply a rule with conditions and actions to matching articles (mark as read, favorite, etc.)
+// @Description  Apply a rule with conditions and actions to matching articles. Actions are objects with a type and optional params (tag_id, category, profile_id, length, target_language, target, url, method). Actions calling external services run in the background.
 // @Tags         rules
 // @Accept       json
 // @Produce      json
 // @Param        rule  body      rules.Rule  true  "Rule definition (conditions and actions)"
 // @Success      200  {object}  map[string]interface{}  "Application result (success, affected count)"
-// @Failure      400  {object}  map[string]string  "Bad request (invalid rule or no actions)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid rule, invalid action or no actions)"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Router       /rules/apply [post]
 func HandleApplyRule(h *core.Handler, w http.ResponseWriter, r *http.Request) {
@@ -36,8 +36,15 @@ func HandleApplyRule(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		response.Error(w, nil, http.StatusBadRequest)
 		return
 	}
+	for _, action := range rule.Actions {
+		if err := action.Validate(); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	}
 
 	engine := rules.NewEngine(h.DB)
+	engine.SetServices(NewActionServices(h))
 	affected, err := engine.ApplyRule(rule)
 	if err != nil {
 		response.Error(w, err, http.StatusInternalServerError)
@@ -0,0 +1,35 @@
+package rules
+
+import (
+	"MrRSS/internal/handlers/article"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/summary"
+	"MrRSS/internal/handlers/translation"
+	"MrRSS/internal/rules"
+)
+
+// actionServices implements rules.Services with the same code paths as the API handlers
+type actionServices struct {
+	h *core.Handler
+}
+
+// NewActionServices returns the services used by rule actions
+func NewActionServices(h *core.Handler) rules.Services {
+	return &actionServices{h: h}
+}
+
+func (s *actionServices) Summarize(articleID, profileID int64, length string) error {
+	return summary.SummarizeArticle(s.h, articleID, profileID, length)
+}
+
+func (s *actionServices) TranslateTitle(articleID int64, targetLang string) error {
+	return translation.TranslateArticleTitle(s.h, articleID, targetLang)
+}
+
+func (s *actionServices) FetchFullContent(articleID int64) error {
+	return article.FetchAndStoreFullContent(s.h, articleID)
+}
+
+func (s *actionServices) Export(articleID int64, target string) error {
+	return article.ExportArticle(s.h, articleID, target)
+}
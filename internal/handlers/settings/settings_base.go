@@ -21,6 +21,10 @@ var AllSettings = []SettingDef{
 	{Key: "ai_chat_enabled", Encrypted: false},
 	{Key: "ai_chat_profile_id", Encrypted: false},
 	{Key: "ai_custom_headers", Encrypted: false},
+	{Key: "ai_digest_profile_id", Encrypted: false},
+	{Key: "ai_embedding_enabled", Encrypted: false},
+	{Key: "ai_embedding_model", Encrypted: false},
+	{Key: "ai_embedding_profile_id", Encrypted: false},
 	{Key: "ai_endpoint", Encrypted: false},
 	{Key: "ai_model", Encrypted: false},
 	{Key: "ai_search_enabled", Encrypted: false},
@@ -52,8 +56,15 @@ var AllSettings = []SettingDef{
 	{Key: "deepl_api_key", Encrypted: true},
 	{Key: "deepl_endpoint", Encrypted: false},
 	{Key: "default_view_mode", Encrypted: false},
+	{Key: "duplicate_action", Encrypted: false},
+	{Key: "duplicate_detection_enabled", Encrypted: false},
 	{Key: "feed_drawer_expanded", Encrypted: false},
 	{Key: "feed_drawer_pinned", Encrypted: false},
+	{Key: "feed_health_backoff_failures", Encrypted: false},
+	{Key: "feed_health_dead_days", Encrypted: false},
+	{Key: "feed_health_pause_days", Encrypted: false},
+	{Key: "feed_url_auto_migrate", Encrypted: false},
+	{Key: "feed_url_migrate_other_sites", Encrypted: false},
 	{Key: "freshrss_api_password", Encrypted: true},
 	{Key: "freshrss_auto_sync_interval", Encrypted: false},
 	{Key: "freshrss_enabled", Encrypted: false},
@@ -85,6 +96,8 @@ var AllSettings = []SettingDef{
 	{Key: "obsidian_enabled", Encrypted: false},
 	{Key: "obsidian_vault", Encrypted: false},
 	{Key: "obsidian_vault_path", Encrypted: false},
+	{Key: "podcast_download_concurrency", Encrypted: false},
+	{Key: "podcast_storage_quota_mb", Encrypted: false},
 	{Key: "proxy_enabled", Encrypted: false},
 	{Key: "proxy_host", Encrypted: false},
 	{Key: "proxy_password", Encrypted: true},
@@ -109,6 +122,9 @@ var AllSettings = []SettingDef{
 	{Key: "target_language", Encrypted: false},
 	{Key: "theme", Encrypted: false},
 	{Key: "translation_enabled", Encrypted: false},
+	{Key: "translation_fallback_providers", Encrypted: false},
+	{Key: "translation_full_article", Encrypted: false},
+	{Key: "translation_monthly_char_budgets", Encrypted: false},
 	{Key: "translation_only_mode", Encrypted: false},
 	{Key: "translation_provider", Encrypted: false},
 	{Key: "update_interval", Encrypted: false},
@@ -66,6 +66,16 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Recreate translation providers so changed credentials take effect
+		if invalidator, ok := h.Translator.(interface{ InvalidateCache() }); ok {
+			invalidator.InvalidateCache()
+		}
+
+		// Start embedding right away when embeddings were just enabled or reconfigured
+		if h.Embeddings != nil && h.Embeddings.Enabled() {
+			h.Embeddings.Wake()
+		}
+
 		// Re-fetch all settings after save to return updated values
 		settings := GetAllSettings(h)
 		response.JSON(w, settings)
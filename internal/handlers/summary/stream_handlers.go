@@ -0,0 +1,122 @@
+package summary
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"MrRSS/internal/ai"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/summary"
+	"MrRSS/internal/utils/textutil"
+)
+
+// HandleSummarizeArticleStream generates a summary like HandleSummarizeArticle but streams it.
+// @Summary      Summarize article (streaming)
+// @Description  Same request as /summarize, answered as Server-Sent Events. With the AI provider, "delta" events carry pieces of the summary and thinking as they are generated. A final "done" event carries the same result as /summarize, or an "error" event is sent if the stream fails after it started. Cached and local summaries are sent as a single "done" event.
+// @Tags         summary
+// @Accept       json
+// @Produce      text/event-stream
+// @Param        request  body      object  true  "Summarize request (article_id, length, content)"
+// @Success      200  {object}  ai.StreamDelta  "Stream of delta events, then a done event with the summary result"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid length parameter)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /summarize/stream [post]
+func HandleSummarizeArticleStream(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ArticleID int64  `json:"article_id"`
+		Length    string `json:"length"`            // "short", "medium", "long"
+		Content   string `json:"content,omitempty"` // Optional: use provided content instead of fetching from DB
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	summaryLength, ok := parseLength(req.Length)
+	if !ok {
+		response.Error(w, nil, http.StatusBadRequest)
+		return
+	}
+
+	if req.Content == "" {
+		article, err := h.DB.GetArticleByID(req.ArticleID)
+		if err == nil && article.Summary != "" && article.Summary != "<no content>" {
+			_ = response.NewSSE(w).Send("done", map[string]interface{}{
+				"summary":        article.Summary,
+				"html":           textutil.ConvertMarkdownToHTML(article.Summary),
+				"sentence_count": 0,
+				"is_too_short":   false,
+				"cached":         true,
+			})
+			return
+		}
+	}
+
+	content, err := getArticleContent(h, req.ArticleID, req.Content)
+	if err != nil {
+		log.Printf("Error getting article content for summary: %v", err)
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	sse := response.NewSSE(w)
+	if content == "" {
+		_ = sse.Send("done", map[string]interface{}{
+			"summary":      "",
+			"is_too_short": true,
+			"error":        "No content available for this article",
+		})
+		return
+	}
+
+	provider, _ := h.DB.GetSetting("summary_provider")
+	if provider != "ai" || h.AITracker.IsLimitReached() {
+		// Nothing to stream, the local algorithm is used
+		result, usedFallback, limitReached := summarize(h, content, summaryLength, nil)
+		cacheSummary(h, req.ArticleID, result.Summary)
+		_ = sse.Send("done", summaryResponse(result, usedFallback, limitReached))
+		return
+	}
+
+	h.AITracker.WaitForRateLimit()
+
+	streamed := false
+	result, err := newAISummarizer(h, nil).SummarizeStream(r.Context(), content, summaryLength, func(delta ai.StreamDelta) error {
+		streamed = true
+		return sse.Send("delta", delta)
+	})
+	usedFallback := false
+	if err != nil {
+		if streamed || r.Context().Err() != nil {
+			log.Printf("AI summary stream failed: %v", err)
+			_ = sse.Send("error", map[string]string{"error": err.Error()})
+			return
+		}
+		log.Printf("Error generating AI summary, falling back to local: %v", err)
+		result = summary.NewSummarizer().Summarize(content, summaryLength)
+		usedFallback = true
+	} else if !result.IsTooShort {
+		// Track AI usage only on success
+		h.AITracker.TrackSummary(content, result.Summary)
+		_ = h.DB.IncrementStat("ai_summary")
+	}
+
+	cacheSummary(h, req.ArticleID, result.Summary)
+	_ = sse.Send("done", summaryResponse(result, usedFallback, false))
+}
+
+// cacheSummary stores a generated summary for an article
+func cacheSummary(h *core.Handler, articleID int64, text string) {
+	if err := h.DB.UpdateArticleSummary(articleID, text); err != nil {
+		// Don't fail the request if caching fails
+		log.Printf("Failed to cache summary for article %d: %v", articleID, err)
+	}
+}
@@ -2,12 +2,14 @@ package summary
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 
 	"MrRSS/internal/ai"
 	"MrRSS/internal/handlers/core"
 	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
 	"MrRSS/internal/summary"
 	"MrRSS/internal/utils/textutil"
 )
@@ -41,15 +43,8 @@ func HandleSummarizeArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	}
 
 	// Validate length parameter
-	summaryLength := summary.Medium
-	switch req.Length {
-	case "short":
-		summaryLength = summary.Short
-	case "long":
-		summaryLength = summary.Long
-	case "medium", "":
-		summaryLength = summary.Medium
-	default:
+	summaryLength, ok := parseLength(req.Length)
+	if !ok {
 		response.Error(w, nil, http.StatusBadRequest)
 		return
 	}
@@ -89,95 +84,16 @@ func HandleSummarizeArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Get summary provider from settings (with default)
-	provider, err := h.DB.GetSetting("summary_provider")
-	if err != nil || provider == "" {
-		provider = "local" // Default to local algorithm
-	}
-
-	var result summary.SummaryResult
-	usedFallback := false
-	limitReached := false
-
-	if provider == "ai" {
-		// Check if AI usage limit is reached - fallback to local if so
-		if h.AITracker.IsLimitReached() {
-			log.Printf("AI usage limit reached, falling back to local summarization")
-			limitReached = true
-			summarizer := summary.NewSummarizer()
-			result = summarizer.Summarize(content, summaryLength)
-			usedFallback = true
-		} else {
-			// Use AI summarization
-			// Apply rate limiting for AI requests
-			h.AITracker.WaitForRateLimit()
-
-			// Try to get AI config from ProfileProvider first
-			var apiKey, endpoint, model string
-			if h.AIProfileProvider != nil {
-				cfg, err := h.AIProfileProvider.GetConfigForFeature(ai.FeatureSummary)
-				if err == nil && cfg != nil {
-					apiKey = cfg.APIKey
-					endpoint = cfg.Endpoint
-					model = cfg.Model
-					log.Printf("Using AI profile for summarization (endpoint: %s, model: %s)", endpoint, model)
-				}
-			}
-
-			// Fallback to global settings if ProfileProvider not available or no profile configured
-			if apiKey == "" && endpoint == "" {
-				apiKey, _ = h.DB.GetEncryptedSetting("ai_api_key")
-				endpoint, _ = h.DB.GetSetting("ai_endpoint")
-				model, _ = h.DB.GetSetting("ai_model")
-				log.Printf("Using global AI settings for summarization (API key: %s)", func() string {
-					if apiKey != "" {
-						return "configured"
-					}
-					return "not configured (using keyless provider)"
-				}())
-			}
-
-			systemPrompt, _ := h.DB.GetSetting("ai_summary_prompt")
-			customHeaders, _ := h.DB.GetSetting("ai_custom_headers")
-			language, _ := h.DB.GetSetting("language")
-
-			aiSummarizer := summary.NewAISummarizerWithDB(apiKey, endpoint, model, h.DB)
-			if systemPrompt != "" {
-				aiSummarizer.SetSystemPrompt(systemPrompt)
-			}
-			if customHeaders != "" {
-				aiSummarizer.SetCustomHeaders(customHeaders)
-			}
-			if language != "" {
-				aiSummarizer.SetLanguage(language)
-			}
-			aiResult, err := aiSummarizer.Summarize(content, summaryLength)
-			if err != nil {
-				log.Printf("Error generating AI summary, falling back to local: %v", err)
-				// Fallback to local algorithm on any AI error
-				summarizer := summary.NewSummarizer()
-				result = summarizer.Summarize(content, summaryLength)
-				usedFallback = true
-			} else {
-				result = aiResult
-				// Track AI usage only on success
-				h.AITracker.TrackSummary(content, result.Summary)
-				// Track statistics
-				_ = h.DB.IncrementStat("ai_summary")
-			}
-		}
-	} else {
-		// Use local algorithm
-		summarizer := summary.NewSummarizer()
-		result = summarizer.Summarize(content, summaryLength)
-	}
+	result, usedFallback, limitReached := summarize(h, content, summaryLength, nil)
 
 	// Cache the summary in the database
-	if err := h.DB.UpdateArticleSummary(req.ArticleID, result.Summary); err != nil {
-		log.Printf("Failed to cache summary for article %d: %v", req.ArticleID, err)
-		// Don't fail the request if caching fails
-	}
+	cacheSummary(h, req.ArticleID, result.Summary)
+
+	response.JSON(w, summaryResponse(result, usedFallback, limitReached))
+}
 
+// summaryResponse builds the response for a generated summary
+func summaryResponse(result summary.SummaryResult, usedFallback, limitReached bool) map[string]interface{} {
 	// Convert markdown summary to HTML (for all summaries, not just AI)
 	htmlSummary := textutil.ConvertMarkdownToHTML(result.Summary)
 
@@ -192,8 +108,155 @@ func HandleSummarizeArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	if usedFallback {
 		resp["used_fallback"] = true
 	}
+	return resp
+}
+
+// parseLength converts a "short", "medium" or "long" length parameter, defaulting to medium
+func parseLength(length string) (summary.SummaryLength, bool) {
+	switch length {
+	case "short":
+		return summary.Short, true
+	case "long":
+		return summary.Long, true
+	case "medium", "":
+		return summary.Medium, true
+	default:
+		return summary.Medium, false
+	}
+}
+
+// summarize generates a summary using the configured provider. If profile is set,
+// AI summarization is used with that profile regardless of the summary_provider setting.
+func summarize(h *core.Handler, content string, summaryLength summary.SummaryLength, profile *models.AIProfile) (result summary.SummaryResult, usedFallback bool, limitReached bool) {
+	// Get summary provider from settings (with default)
+	provider, err := h.DB.GetSetting("summary_provider")
+	if err != nil || provider == "" {
+		provider = "local" // Default to local algorithm
+	}
+	if profile != nil {
+		provider = "ai"
+	}
 
-	response.JSON(w, resp)
+	if provider != "ai" {
+		// Use local algorithm
+		summarizer := summary.NewSummarizer()
+		return summarizer.Summarize(content, summaryLength), false, false
+	}
+
+	// Check if AI usage limit is reached - fallback to local if so
+	if h.AITracker.IsLimitReached() {
+		log.Printf("AI usage limit reached, falling back to local summarization")
+		summarizer := summary.NewSummarizer()
+		return summarizer.Summarize(content, summaryLength), true, true
+	}
+
+	// Use AI summarization
+	// Apply rate limiting for AI requests
+	h.AITracker.WaitForRateLimit()
+
+	aiSummarizer := newAISummarizer(h, profile)
+	aiResult, err := aiSummarizer.Summarize(content, summaryLength)
+	if err != nil {
+		log.Printf("Error generating AI summary, falling back to local: %v", err)
+		// Fallback to local algorithm on any AI error
+		summarizer := summary.NewSummarizer()
+		return summarizer.Summarize(content, summaryLength), true, false
+	}
+
+	// Track AI usage only on success
+	h.AITracker.TrackSummary(content, aiResult.Summary)
+	// Track statistics
+	_ = h.DB.IncrementStat("ai_summary")
+	return aiResult, false, false
+}
+
+// newAISummarizer creates an AI summarizer from the given profile, the summary profile
+// or the global AI settings, in that order
+func newAISummarizer(h *core.Handler, profile *models.AIProfile) *summary.AISummarizer {
+	customHeaders, _ := h.DB.GetSetting("ai_custom_headers")
+
+	var apiKey, endpoint, model string
+	if profile != nil {
+		apiKey = profile.APIKey
+		endpoint = profile.Endpoint
+		model = profile.Model
+		if profile.CustomHeaders != "" {
+			customHeaders = profile.CustomHeaders
+		}
+		log.Printf("Using AI profile %q for summarization (endpoint: %s, model: %s)", profile.Name, endpoint, model)
+	} else if h.AIProfileProvider != nil {
+		// Try to get AI config from ProfileProvider first
+		cfg, err := h.AIProfileProvider.GetConfigForFeature(ai.FeatureSummary)
+		if err == nil && cfg != nil {
+			apiKey = cfg.APIKey
+			endpoint = cfg.Endpoint
+			model = cfg.Model
+			log.Printf("Using AI profile for summarization (endpoint: %s, model: %s)", endpoint, model)
+		}
+	}
+
+	// Fallback to global settings if ProfileProvider not available or no profile configured
+	if apiKey == "" && endpoint == "" {
+		apiKey, _ = h.DB.GetEncryptedSetting("ai_api_key")
+		endpoint, _ = h.DB.GetSetting("ai_endpoint")
+		model, _ = h.DB.GetSetting("ai_model")
+		log.Printf("Using global AI settings for summarization (API key: %s)", func() string {
+			if apiKey != "" {
+				return "configured"
+			}
+			return "not configured (using keyless provider)"
+		}())
+	}
+
+	systemPrompt, _ := h.DB.GetSetting("ai_summary_prompt")
+	language, _ := h.DB.GetSetting("language")
+
+	aiSummarizer := summary.NewAISummarizerWithDB(apiKey, endpoint, model, h.DB)
+	if systemPrompt != "" {
+		aiSummarizer.SetSystemPrompt(systemPrompt)
+	}
+	if customHeaders != "" {
+		aiSummarizer.SetCustomHeaders(customHeaders)
+	}
+	if language != "" {
+		aiSummarizer.SetLanguage(language)
+	}
+	return aiSummarizer
+}
+
+// SummarizeArticle generates and stores a summary for an article. A profileID greater
+// than zero forces AI summarization with that profile. It is used by rule actions.
+func SummarizeArticle(h *core.Handler, articleID, profileID int64, length string) error {
+	summaryLength, ok := parseLength(length)
+	if !ok {
+		return fmt.Errorf("invalid summary length %q", length)
+	}
+
+	var profile *models.AIProfile
+	if profileID > 0 {
+		var err error
+		profile, err = h.DB.GetAIProfile(profileID)
+		if err != nil {
+			return err
+		}
+		if profile == nil {
+			return fmt.Errorf("AI profile %d not found", profileID)
+		}
+	}
+
+	content, _, err := h.GetArticleContent(articleID)
+	if err != nil {
+		return err
+	}
+	if content == "" {
+		return nil
+	}
+
+	result, _, _ := summarize(h, content, summaryLength, profile)
+	if result.Summary == "" {
+		return nil
+	}
+	return h.DB.UpdateArticleSummary(articleID, result.Summary)
 }
 
 // getArticleContent fetches the content of an article by ID, or uses provided content
@@ -114,3 +114,59 @@ func (m *mockParser) ParseURL(url string) (*gofeed.Feed, error) {
 func (m *mockParser) ParseURLWithContext(url string, ctx context.Context) (*gofeed.Feed, error) {
 	return &gofeed.Feed{Items: m.items}, nil
 }
+
+// Test that AI summaries are streamed as delta events and cached once complete.
+func TestHandleSummarizeArticleStream_AI(t *testing.T) {
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db init failed: %v", err)
+	}
+
+	// Ollama-style newline-delimited JSON stream, served on 127.0.0.1 which is detected as Ollama
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"response":"Short ","done":false}`)
+		fmt.Fprintln(w, `{"response":"summary.","done":false}`)
+		fmt.Fprintln(w, `{"response":"","done":true}`)
+	}))
+	defer server.Close()
+
+	db.SetSetting("summary_provider", "ai")
+	db.SetSetting("ai_endpoint", server.URL+"/api/generate")
+	db.SetSetting("ai_model", "llama")
+
+	feedID, _ := db.AddFeed(&models.Feed{Title: "T", URL: "http://example.com/feed"})
+	db.SaveArticle(&models.Article{FeedID: feedID, Title: "A", URL: "http://example.com/article/1", PublishedAt: time.Now()})
+	var articleID int64
+	db.QueryRow("SELECT id FROM articles").Scan(&articleID)
+
+	h := core.NewHandler(db, nil, nil, nil)
+	content := bytes.Repeat([]byte("This sentence is long enough to be worth summarizing. "), 10)
+	payload := fmt.Sprintf(`{"article_id": %d, "length": "short", "content": %q}`, articleID, content)
+	req := httptest.NewRequest(http.MethodPost, "/summarize/stream", bytes.NewReader([]byte(payload)))
+	rr := httptest.NewRecorder()
+
+	HandleSummarizeArticleStream(h, rr, req)
+
+	if ct := rr.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q: %s", ct, rr.Body.String())
+	}
+	body := rr.Body.String()
+	for _, want := range []string{
+		"event: delta\ndata: {\"content\":\"Short \"}",
+		"event: delta\ndata: {\"content\":\"summary.\"}",
+		"event: done\ndata: {",
+		`"summary":"Short summary."`,
+	} {
+		if !bytes.Contains([]byte(body), []byte(want)) {
+			t.Errorf("expected stream to contain %q, got:\n%s", want, body)
+		}
+	}
+
+	article, _ := db.GetArticleByID(articleID)
+	if article.Summary != "Short summary." {
+		t.Errorf("expected the summary to be cached, got %q", article.Summary)
+	}
+}
@@ -0,0 +1,172 @@
+package translation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"MrRSS/internal/ai"
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/translation"
+)
+
+// ArticleContentTranslationResponse is the full-article translation of an article
+type ArticleContentTranslationResponse struct {
+	TranslatedHTML string                     `json:"translated_html"`
+	BilingualHTML  string                     `json:"bilingual_html"`
+	Blocks         []translation.ArticleBlock `json:"blocks"`
+	Complete       bool                       `json:"complete"`
+	Cached         bool                       `json:"cached"`
+	LimitReached   bool                       `json:"limit_reached"`
+	Error          string                     `json:"error,omitempty"`
+}
+
+// HandleTranslateArticleContent translates the cached body of an article block by block.
+// @Summary      Translate article content
+// @Description  Translate the full article body while preserving HTML structure, code blocks and links. Long articles are sent in chunks; partial translations are stored and resumed on the next request.
+// @Tags         translation
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Translation request (article_id, target_language, force)"
+// @Success      200  {object}  ArticleContentTranslationResponse  "Translated and bilingual renditions"
+// @Failure      400  {object}  map[string]string  "Bad request (missing required fields)"
+// @Failure      404  {object}  map[string]string  "Article content not available"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/translate-content [post]
+func HandleTranslateArticleContent(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ArticleID  int64  `json:"article_id"`
+		TargetLang string `json:"target_language"`
+		Force      bool   `json:"force"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if req.ArticleID == 0 || req.TargetLang == "" {
+		response.Error(w, nil, http.StatusBadRequest)
+		return
+	}
+
+	content, _, err := h.GetArticleContent(req.ArticleID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if content == "" {
+		response.Error(w, errors.New("article content not available"), http.StatusNotFound)
+		return
+	}
+
+	doc, err := translation.ParseArticle(content)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	sourceHash := hex.EncodeToString(sum[:])
+
+	// Resume from the stored translation unless the content changed
+	stored, err := h.DB.GetArticleTranslation(req.ArticleID, req.TargetLang)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if stored != nil && stored.SourceHash == sourceHash && !req.Force {
+		doc.SetTranslations(stored.Blocks)
+		if doc.Complete() {
+			response.JSON(w, articleContentResponse(doc, true, false))
+			return
+		}
+	}
+
+	translator := newContentTranslator(h)
+	translateErr := doc.Translate(translator, req.TargetLang, translation.DefaultArticleChunkSize)
+
+	if err := h.DB.SaveArticleTranslation(&database.ArticleTranslation{
+		ArticleID:      req.ArticleID,
+		TargetLanguage: req.TargetLang,
+		SourceHash:     sourceHash,
+		Blocks:         doc.Translations(),
+		Complete:       doc.Complete(),
+	}); err != nil {
+		log.Printf("Error saving article translation: %v", err)
+	}
+
+	resp := articleContentResponse(doc, false, translator.limitReached)
+	if translateErr != nil {
+		log.Printf("Error translating article %d: %v", req.ArticleID, translateErr)
+		if !hasTranslatedBlock(doc) {
+			response.Error(w, translateErr, http.StatusInternalServerError)
+			return
+		}
+		// Return the blocks translated so far; the rest is translated on the next request
+		resp.Error = translateErr.Error()
+	}
+	response.JSON(w, resp)
+}
+
+func articleContentResponse(doc *translation.ArticleDocument, cached, limitReached bool) ArticleContentTranslationResponse {
+	return ArticleContentTranslationResponse{
+		TranslatedHTML: doc.TranslatedHTML(),
+		BilingualHTML:  doc.BilingualHTML(),
+		Blocks:         doc.Blocks(),
+		Complete:       doc.Complete(),
+		Cached:         cached,
+		LimitReached:   limitReached,
+	}
+}
+
+func hasTranslatedBlock(doc *translation.ArticleDocument) bool {
+	for _, t := range doc.Translations() {
+		if t != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// contentTranslator applies the AI usage limit and rate limit to each chunk of a full-article
+// translation. Like translateTitle, it falls back to Google Translate once the limit is reached.
+type contentTranslator struct {
+	h            *core.Handler
+	useAI        bool
+	limitReached bool
+}
+
+func newContentTranslator(h *core.Handler) *contentTranslator {
+	provider, _ := h.DB.GetSetting("translation_provider")
+	return &contentTranslator{h: h, useAI: provider == "ai"}
+}
+
+func (t *contentTranslator) Translate(text, targetLang string) (string, error) {
+	if !t.useAI {
+		return t.h.Translator.Translate(text, targetLang)
+	}
+
+	// Input and output are about the same size
+	if t.limitReached || !t.h.AITracker.HasBudgetFor(2*ai.EstimateTokens(text)) {
+		t.limitReached = true
+		return translation.NewGoogleFreeTranslatorWithDB(t.h.DB).Translate(text, targetLang)
+	}
+
+	t.h.AITracker.WaitForRateLimit()
+	translated, err := t.h.Translator.Translate(text, targetLang)
+	if err != nil {
+		log.Printf("AI translation failed, falling back to Google Translate: %v", err)
+		return translation.NewGoogleFreeTranslatorWithDB(t.h.DB).Translate(text, targetLang)
+	}
+	t.h.AITracker.TrackTranslation(text, translated)
+	return translated, nil
+}
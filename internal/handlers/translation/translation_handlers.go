@@ -95,43 +95,7 @@ func HandleTranslateArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	}
 
 	// Step 2: Proceed with translation
-	// Check if we should use AI translation or fallback to Google
-	provider, _ := h.DB.GetSetting("translation_provider")
-	isAIProvider := provider == "ai"
-
-	var translatedTitle string
-	var translateErr error
-	var limitReached = false
-
-	if isAIProvider {
-		// Check if AI usage limit is reached
-		if h.AITracker.IsLimitReached() {
-			limitReached = true
-			// Fallback to Google Translate
-			googleTranslator := translation.NewGoogleFreeTranslatorWithDB(h.DB)
-			translatedTitle, translateErr = translation.TranslateMarkdownPreservingStructure(req.Title, googleTranslator, req.TargetLang)
-		} else {
-			// Apply rate limiting for AI requests
-			h.AITracker.WaitForRateLimit()
-
-			// Use markdown-preserving translation for better list structure
-			translatedTitle, translateErr = translation.TranslateMarkdownAIPrompt(req.Title, h.Translator, req.TargetLang)
-
-			// If AI fails, fallback to Google Translate
-			if translateErr != nil {
-				googleTranslator := translation.NewGoogleFreeTranslatorWithDB(h.DB)
-				translatedTitle, translateErr = translation.TranslateMarkdownPreservingStructure(req.Title, googleTranslator, req.TargetLang)
-			}
-
-			// Track AI usage only on success (whether AI or fallback)
-			if translateErr == nil {
-				h.AITracker.TrackTranslation(req.Title, translatedTitle)
-			}
-		}
-	} else {
-		// Non-AI provider, use markdown-preserving translation
-		translatedTitle, translateErr = translation.TranslateMarkdownPreservingStructure(req.Title, h.Translator, req.TargetLang)
-	}
+	translatedTitle, limitReached, translateErr := translateTitle(h, req.Title, req.TargetLang)
 
 	if translateErr != nil {
 		response.Error(w, translateErr, http.StatusInternalServerError)
@@ -168,9 +132,69 @@ func HandleTranslateArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	})
 }
 
+// translateTitle translates a title with the configured provider. With the AI provider,
+// Google Translate is used as a fallback when the usage limit is reached or AI fails.
+func translateTitle(h *core.Handler, title, targetLang string) (translatedTitle string, limitReached bool, err error) {
+	// Check if we should use AI translation or fallback to Google
+	provider, _ := h.DB.GetSetting("translation_provider")
+	if provider != "ai" {
+		// Non-AI provider, use markdown-preserving translation
+		translatedTitle, err = translation.TranslateMarkdownPreservingStructure(title, h.Translator, targetLang)
+		return translatedTitle, false, err
+	}
+
+	// Check if AI usage limit is reached
+	if h.AITracker.IsLimitReached() {
+		// Fallback to Google Translate
+		googleTranslator := translation.NewGoogleFreeTranslatorWithDB(h.DB)
+		translatedTitle, err = translation.TranslateMarkdownPreservingStructure(title, googleTranslator, targetLang)
+		return translatedTitle, true, err
+	}
+
+	// Apply rate limiting for AI requests
+	h.AITracker.WaitForRateLimit()
+
+	// Use markdown-preserving translation for better list structure
+	translatedTitle, err = translation.TranslateMarkdownAIPrompt(title, h.Translator, targetLang)
+
+	// If AI fails, fallback to Google Translate
+	if err != nil {
+		googleTranslator := translation.NewGoogleFreeTranslatorWithDB(h.DB)
+		translatedTitle, err = translation.TranslateMarkdownPreservingStructure(title, googleTranslator, targetLang)
+	}
+
+	// Track AI usage only on success (whether AI or fallback)
+	if err == nil {
+		h.AITracker.TrackTranslation(title, translatedTitle)
+	}
+	return translatedTitle, false, err
+}
+
+// TranslateArticleTitle translates and stores an article's title unless it already has
+// a translation or is already in the target language. It is used by rule actions.
+func TranslateArticleTitle(h *core.Handler, articleID int64, targetLang string) error {
+	article, err := h.DB.GetArticleByID(articleID)
+	if err != nil {
+		return err
+	}
+	if article.TranslatedTitle != "" && article.TranslatedTitle != article.Title {
+		return nil
+	}
+
+	if !translation.GetLanguageDetector().ShouldTranslate(article.Title, targetLang) {
+		return h.DB.UpdateArticleTranslation(articleID, article.Title)
+	}
+
+	translatedTitle, _, err := translateTitle(h, article.Title, targetLang)
+	if err != nil {
+		return err
+	}
+	return h.DB.UpdateArticleTranslation(articleID, translatedTitle)
+}
+
 // HandleClearTranslations clears all translated titles from the database.
 // @Summary      Clear all translations
-// @Description  Clear all translated article titles from the database
+// @Description  Clear all translated article titles and full-article translations from the database
 // @Tags         translation
 // @Accept       json
 // @Produce      json
@@ -188,6 +212,11 @@ func HandleClearTranslations(h *core.Handler, w http.ResponseWriter, r *http.Req
 		response.Error(w, err, http.StatusInternalServerError)
 		return
 	}
+	if err := h.DB.ClearArticleTranslations(); err != nil {
+		log.Printf("Error clearing article translations: %v", err)
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
 
 	response.JSON(w, map[string]bool{"success": true})
 }
@@ -420,3 +449,28 @@ func HandleTestCustomTranslation(h *core.Handler, w http.ResponseWriter, r *http
 
 	response.JSON(w, resp)
 }
+
+// HandleTranslationProviderStatus returns the status of each translation provider in the fallback chain.
+// @Summary      Get translation provider status
+// @Description  Get the circuit breaker state, monthly usage and budget of each provider in the fallback chain
+// @Tags         translation
+// @Produce      json
+// @Success      200  {array}   translation.ProviderStatus  "Provider status in chain order"
+// @Failure      501  {object}  map[string]string  "Translator does not support a fallback chain"
+// @Router       /translation/providers/status [get]
+func HandleTranslationProviderStatus(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	reporter, ok := h.Translator.(interface {
+		ProviderStatus() []translation.ProviderStatus
+	})
+	if !ok {
+		response.Error(w, nil, http.StatusNotImplemented)
+		return
+	}
+
+	response.JSON(w, reporter.ProviderStatus())
+}
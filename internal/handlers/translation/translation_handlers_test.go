@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"MrRSS/internal/cache"
 	"MrRSS/internal/database"
 	corepkg "MrRSS/internal/handlers/core"
 	transpkg "MrRSS/internal/translation"
@@ -128,3 +130,83 @@ func TestHandleClearTranslations(t *testing.T) {
 		t.Fatalf("expected 0 translations remaining, got %d", count)
 	}
 }
+
+func TestHandleTranslationProviderStatus(t *testing.T) {
+	db := setupDB(t)
+	db.SetSetting("translation_provider", "deepl")
+	db.SetSetting("translation_fallback_providers", "google")
+	db.SetSetting("translation_monthly_char_budgets", `{"google":100}`)
+	db.AddTranslationUsage("google", time.Now().Format("2006-01"), 40)
+	h := &corepkg.Handler{DB: db, Translator: transpkg.NewDynamicTranslatorWithCache(db, db)}
+
+	rr := httptest.NewRecorder()
+	HandleTranslationProviderStatus(h, rr, httptest.NewRequest(http.MethodGet, "/api/translation/providers/status", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var statuses []transpkg.ProviderStatus
+	if err := json.NewDecoder(rr.Body).Decode(&statuses); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Provider != transpkg.ProviderDeepL || statuses[1].Provider != transpkg.ProviderGoogle {
+		t.Fatalf("unexpected chain: %+v", statuses)
+	}
+	if statuses[0].Configured {
+		t.Errorf("expected deepl without a key to be unconfigured")
+	}
+	if statuses[1].CharactersThisMonth != 40 || statuses[1].MonthlyBudget != 100 || statuses[1].State != transpkg.BreakerClosed {
+		t.Errorf("unexpected google status: %+v", statuses[1])
+	}
+
+	// The mock translator has no fallback chain
+	h.Translator = transpkg.NewMockTranslator()
+	rr = httptest.NewRecorder()
+	HandleTranslationProviderStatus(h, rr, httptest.NewRequest(http.MethodGet, "/api/translation/providers/status", nil))
+	if rr.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 got %d", rr.Code)
+	}
+}
+
+func TestHandleTranslateArticleContent_BilingualAndCached(t *testing.T) {
+	db := setupDB(t)
+
+	res, err := db.Exec("INSERT INTO articles (feed_id, title, url, published_at) VALUES (1, 't', 'u', datetime('now'))")
+	if err != nil {
+		t.Fatalf("insert article failed: %v", err)
+	}
+	id, _ := res.LastInsertId()
+	if err := db.SetArticleContent(id, `<p>Hello <a href="https://example.com">world</a></p><pre><code>x := 1</code></pre>`); err != nil {
+		t.Fatalf("SetArticleContent failed: %v", err)
+	}
+
+	h := &corepkg.Handler{DB: db, Translator: transpkg.NewMockTranslator(), ContentCache: cache.NewContentCache(10, time.Minute)}
+
+	call := func() ArticleContentTranslationResponse {
+		b, _ := json.Marshal(map[string]interface{}{"article_id": id, "target_language": "de"})
+		req := httptest.NewRequest(http.MethodPost, "/api/articles/translate-content", bytes.NewReader(b))
+		rr := httptest.NewRecorder()
+		HandleTranslateArticleContent(h, rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200 got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp ArticleContentTranslationResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := call()
+	if !resp.Complete || resp.Cached {
+		t.Fatalf("expected a fresh complete translation, got %+v", resp)
+	}
+	want := `<p>Hello <a href="https://example.com">world</a></p><div class="translation-text">[DE] Hello <a href="https://example.com">world</a></div><pre><code>x := 1</code></pre>`
+	if resp.BilingualHTML != want {
+		t.Errorf("unexpected bilingual html:\n got %s\nwant %s", resp.BilingualHTML, want)
+	}
+
+	if resp = call(); !resp.Cached {
+		t.Error("expected the second request to be served from the stored translation")
+	}
+}
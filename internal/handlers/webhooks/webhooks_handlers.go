@@ -0,0 +1,353 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/handlers/article"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/handlers/response"
+	"MrRSS/internal/models"
+	"MrRSS/internal/webhooks"
+)
+
+// webhookRequest is the body of create and update requests
+type webhookRequest struct {
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret"`       // Empty keeps the stored secret on update
+	ClearSecret bool     `json:"clear_secret"` // Remove the stored secret on update
+	Events      []string `json:"events"`
+	Conditions  string   `json:"conditions"`
+	Enabled     bool     `json:"enabled"`
+}
+
+// NewArticleFilter returns a webhook filter that applies saved-filter conditions
+// the same way as the filtered articles endpoint
+func NewArticleFilter(h *core.Handler) webhooks.ArticleFilter {
+	return func(articles []models.Article, conditions string) ([]models.Article, error) {
+		var parsed []article.FilterCondition
+		if err := json.Unmarshal([]byte(conditions), &parsed); err != nil {
+			return nil, err
+		}
+		return article.FilterArticles(h, articles, parsed)
+	}
+}
+
+// HandleWebhooks handles GET and POST requests for webhooks.
+// @Summary      List or create webhooks
+// @Description  GET: Retrieve all webhooks (secrets are not returned). POST: Create a webhook.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  false  "Webhook details (for POST: name, url, secret, events, conditions, enabled)"
+// @Success      200  {array}   models.Webhook  "List of webhooks (GET)"
+// @Success      201  {object}  models.Webhook  "Created webhook (POST)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /webhooks [get]
+// @Router       /webhooks [post]
+func HandleWebhooks(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := h.DB.GetWebhooks()
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		for i := range list {
+			list[i].Secret = ""
+		}
+		response.JSON(w, list)
+
+	case http.MethodPost:
+		var req webhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := validateWebhook(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+
+		id, err := h.DB.AddWebhook(&models.Webhook{
+			Name:       req.Name,
+			URL:        req.URL,
+			Secret:     req.Secret,
+			Events:     req.Events,
+			Conditions: req.Conditions,
+			Enabled:    req.Enabled,
+		})
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		webhook, err := h.DB.GetWebhook(id)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		webhook.Secret = ""
+		w.WriteHeader(http.StatusCreated)
+		response.JSON(w, webhook)
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleWebhookUpdate updates an existing webhook.
+// @Summary      Update a webhook
+// @Description  Update a webhook's name, URL, events, conditions and enabled state. An empty secret keeps the stored one unless clear_secret is set.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Webhook update details (id, name, url, secret, clear_secret, events, conditions, enabled)"
+// @Success      200  {object}  models.Webhook  "Updated webhook"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      404  {object}  map[string]string  "Webhook not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /webhooks/update [post]
+func HandleWebhookUpdate(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := validateWebhook(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.DB.GetWebhook(req.ID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if existing == nil {
+		response.Error(w, nil, http.StatusNotFound)
+		return
+	}
+
+	keepSecret := req.Secret == "" && !req.ClearSecret
+	err = h.DB.UpdateWebhook(&models.Webhook{
+		ID:         req.ID,
+		Name:       req.Name,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		Events:     req.Events,
+		Conditions: req.Conditions,
+		Enabled:    req.Enabled,
+	}, keepSecret)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	webhook, err := h.DB.GetWebhook(req.ID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	webhook.Secret = ""
+	response.JSON(w, webhook)
+}
+
+// HandleWebhookDelete deletes a webhook and its delivery log.
+// @Summary      Delete a webhook
+// @Description  Delete a webhook by its ID, including its queued and logged deliveries
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Webhook ID to delete"
+// @Success      200  {object}  map[string]string  "Deletion status"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /webhooks/delete [post]
+func HandleWebhookDelete(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.DeleteWebhook(req.ID); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, map[string]string{"status": "deleted"})
+}
+
+// HandleWebhookDeliveries returns the delivery log.
+// @Summary      Get webhook deliveries
+// @Description  Retrieve the most recent webhook deliveries, newest first, with their status, attempts and last error
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        webhook_id  query     int64  false  "Only return deliveries of this webhook"
+// @Param        limit       query     int    false  "Maximum number of deliveries (default: 100, max: 1000)"
+// @Success      200  {array}   models.WebhookDelivery  "Deliveries"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /webhooks/deliveries [get]
+func HandleWebhookDeliveries(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	webhookID, _ := strconv.ParseInt(q.Get("webhook_id"), 10, 64)
+	limit := 100
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 {
+		limit = min(l, 1000)
+	}
+
+	deliveries, err := h.DB.GetWebhookDeliveries(webhookID, limit)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, deliveries)
+}
+
+// HandleWebhookDeliveryRetry queues a delivery to be sent again.
+// @Summary      Retry a webhook delivery
+// @Description  Queue a failed or delivered webhook delivery to be sent again immediately
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Delivery ID to retry"
+// @Success      200  {object}  map[string]string  "Retry status"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /webhooks/deliveries/retry [post]
+func HandleWebhookDeliveryRetry(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.RetryWebhookDelivery(req.ID); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	h.Webhooks.Wake()
+
+	response.JSON(w, map[string]string{"status": "queued"})
+}
+
+// HandleWebhookTest sends a ping event to a webhook.
+// @Summary      Test a webhook
+// @Description  Send a signed ping event to a webhook immediately, without queuing, and report the response
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Webhook ID to test"
+// @Success      200  {object}  map[string]interface{}  "Test result (success, status_code, error)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      404  {object}  map[string]string  "Webhook not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /webhooks/test [post]
+func HandleWebhookTest(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := h.DB.GetWebhook(req.ID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if webhook == nil {
+		response.Error(w, nil, http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 20*time.Second)
+	defer cancel()
+
+	result := map[string]interface{}{"success": true}
+	status, err := h.Webhooks.Ping(ctx, webhook)
+	result["status_code"] = status
+	if err != nil {
+		result["success"] = false
+		result["error"] = err.Error()
+	}
+	response.JSON(w, result)
+}
+
+// validateWebhook checks and normalizes a webhook request
+func validateWebhook(req *webhookRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	req.URL = strings.TrimSpace(req.URL)
+	req.Conditions = strings.TrimSpace(req.Conditions)
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an http or https URL")
+	}
+	if req.Name == "" {
+		req.Name = u.Host
+	}
+
+	if len(req.Events) == 0 {
+		return errors.New("at least one event is required")
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(webhooks.Events, event) {
+			return fmt.Errorf("unknown event: %s", event)
+		}
+	}
+
+	if req.Conditions == "[]" {
+		req.Conditions = ""
+	}
+	if req.Conditions != "" {
+		var conditions []article.FilterCondition
+		if err := json.Unmarshal([]byte(req.Conditions), &conditions); err != nil {
+			return fmt.Errorf("invalid conditions: %w", err)
+		}
+	}
+	return nil
+}
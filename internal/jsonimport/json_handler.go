@@ -1,11 +1,13 @@
 package jsonimport
 
 import (
+	"MrRSS/internal/feedgen"
 	"MrRSS/internal/models"
 	"encoding/json"
 	"errors"
 	"io"
 	"log"
+	"strings"
 )
 
 // FeedExport represents the JSON export format for feeds
@@ -19,6 +21,9 @@ const (
 	ExportVersion = 1
 )
 
+// jsonFeedVersionPrefix starts the version URL of JSON Feed documents (1.0 and 1.1)
+const jsonFeedVersionPrefix = "https://jsonfeed.org/version/"
+
 // Parse parses JSON import data and returns feeds
 func Parse(r io.Reader) ([]models.Feed, error) {
 	content, err := io.ReadAll(r)
@@ -32,6 +37,16 @@ func Parse(r io.Reader) ([]models.Feed, error) {
 		return nil, errors.New("file content is empty")
 	}
 
+	// A JSON Feed document is imported as a subscription to the feed itself
+	var probe struct {
+		Version interface{} `json:"version"`
+	}
+	if err := json.Unmarshal(content, &probe); err == nil {
+		if v, ok := probe.Version.(string); ok && strings.HasPrefix(v, jsonFeedVersionPrefix) {
+			return parseJSONFeed(content)
+		}
+	}
+
 	var export FeedExport
 	if err := json.Unmarshal(content, &export); err != nil {
 		log.Printf("JSON Parse: Unmarshal error: %v", err)
@@ -52,6 +67,25 @@ func Parse(r io.Reader) ([]models.Feed, error) {
 	return export.Feeds, nil
 }
 
+// parseJSONFeed returns the subscription described by a JSON Feed document
+func parseJSONFeed(content []byte) ([]models.Feed, error) {
+	var doc feedgen.JSONFeed
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if doc.FeedURL == "" {
+		return nil, errors.New("JSON Feed has no feed_url to subscribe to")
+	}
+
+	log.Printf("JSON Parse: Found JSON Feed %q (%s)", doc.Title, doc.Version)
+	return []models.Feed{{
+		Title:       doc.Title,
+		URL:         doc.FeedURL,
+		Link:        doc.HomePageURL,
+		Description: doc.Description,
+	}}, nil
+}
+
 // Generate generates JSON export data from feeds
 func Generate(feeds []models.Feed) ([]byte, error) {
 	export := FeedExport{
@@ -0,0 +1,38 @@
+package jsonimport
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse_JSONFeed(t *testing.T) {
+	doc := `{
+		"version": "https://jsonfeed.org/version/1.1",
+		"title": "Example",
+		"home_page_url": "https://example.com/",
+		"feed_url": "https://example.com/feed.json",
+		"items": [{"id": "1", "content_text": "Hello"}]
+	}`
+
+	feeds, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].URL != "https://example.com/feed.json" || feeds[0].Title != "Example" || feeds[0].Link != "https://example.com/" {
+		t.Errorf("Unexpected feeds: %+v", feeds)
+	}
+
+	if _, err := Parse(strings.NewReader(`{"version": "https://jsonfeed.org/version/1", "title": "No URL", "items": []}`)); err == nil {
+		t.Error("Expected an error for a JSON Feed without feed_url")
+	}
+}
+
+func TestParse_ExportFormat(t *testing.T) {
+	feeds, err := Parse(strings.NewReader(`{"version": 1, "feeds": [{"title": "A", "url": "https://a.example.com/rss"}]}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].URL != "https://a.example.com/rss" {
+		t.Errorf("Unexpected feeds: %+v", feeds)
+	}
+}
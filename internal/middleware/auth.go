@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"MrRSS/internal/models"
+)
+
+type userContextKey struct{}
+
+// AuthConfig holds configuration for authentication middleware.
+type AuthConfig struct {
+	// Authenticate resolves a bearer token or session cookie value to a user.
+	// It returns nil if the token is unknown or expired.
+	Authenticate func(token string) (*models.User, error)
+	// CookieName is the session cookie checked when no Authorization header is sent.
+	CookieName string
+	// PublicPaths lists exact paths that can be accessed without authentication.
+	PublicPaths []string
+}
+
+// Auth returns a middleware that rejects requests without a valid bearer token
+// or session cookie. The authenticated user is stored in the request context.
+func Auth(config AuthConfig) Middleware {
+	public := make(map[string]bool, len(config.PublicPaths))
+	for _, p := range config.PublicPaths {
+		public[p] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Preflight requests carry no credentials
+			if r.Method == http.MethodOptions || public[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := TokenFromRequest(r, config.CookieName)
+			if token == "" {
+				unauthorized(w)
+				return
+			}
+
+			user, err := config.Authenticate(token)
+			if err != nil {
+				log.Printf("Error authenticating request: %v", err)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			if user == nil {
+				unauthorized(w)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+		})
+	}
+}
+
+// TokenFromRequest extracts a bearer token from the Authorization header,
+// falling back to the named session cookie.
+func TokenFromRequest(r *http.Request, cookieName string) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+			return strings.TrimSpace(auth[7:])
+		}
+		return ""
+	}
+	if cookieName != "" {
+		if cookie, err := r.Cookie(cookieName); err == nil {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// WithUser returns a copy of ctx carrying the authenticated user.
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// UserFromContext returns the authenticated user, or nil if the request was not authenticated.
+func UserFromContext(ctx context.Context) *models.User {
+	user, _ := ctx.Value(userContextKey{}).(*models.User)
+	return user
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="MrRSS"`)
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
@@ -51,6 +51,17 @@ type Feed struct {
 	LastUpdateStatus  string     `json:"last_update_status,omitempty"`  // Last update status ("success" or "failed")
 	// Tags (populated by API handlers)
 	Tags []Tag `json:"tags,omitempty"` // Tags assigned to this feed
+	// HTTP options (populated by backup and OPML export, without secrets unless requested)
+	HTTPOptions *FeedHTTPOptions `json:"http_options,omitempty"`
+	// Request and pagination options of a JSON API feed (populated by backup, header values only with secrets)
+	JSONOptions *JSONFeedOptions `json:"json_options,omitempty"`
+	// Run options and saved state of a script feed (populated by backup, secret values only with secrets)
+	ScriptSettings *ScriptFeedSettings `json:"script_settings,omitempty"`
+	ScriptState    string              `json:"script_state,omitempty"`
+	// JavaScript transform of the items (populated by backup)
+	ItemTransform *ItemTransform `json:"item_transform,omitempty"`
+	// Podcast download settings (populated by backup)
+	PodcastSettings *PodcastFeedSettings `json:"podcast_settings,omitempty"`
 }
 
 type Article struct {
@@ -73,6 +84,39 @@ type Article struct {
 	Summary               string    `json:"summary"`          // Cached AI-generated summary
 	UniqueID              string    `json:"unique_id"`        // Unique identifier for deduplication (title+feed_id+published_date)
 	FreshRSSItemID        string    `json:"freshrss_item_id"` // FreshRSS/Google Reader item ID for API operations
+	// Tags (populated by API handlers)
+	Tags []Tag `json:"tags,omitempty"` // Tags assigned to this article
+	// Story cluster of near-duplicate articles from other feeds (populated by API handlers)
+	StoryID       int64          `json:"story_id,omitempty"`       // ID of the primary article of the story
+	StorySiblings []StorySibling `json:"story_siblings,omitempty"` // Other articles of the story
+}
+
+// StorySibling is an article about the same story as another article, from a different feed
+type StorySibling struct {
+	ID          int64     `json:"id"`
+	FeedID      int64     `json:"feed_id"`
+	FeedTitle   string    `json:"feed_title"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	IsPrimary   bool      `json:"is_primary"` // The first article of the story that was fetched
+	IsRead      bool      `json:"is_read"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// ArticleState is the user-assigned state of an article, identified by its feed URL and link
+// so that it can be restored on another instance
+type ArticleState struct {
+	FeedURL     string    `json:"feed_url"`
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	PublishedAt time.Time `json:"published_at"`
+	IsFavorite  bool      `json:"is_favorite"`
+	IsReadLater bool      `json:"is_read_later"`
+	Tags        []string  `json:"tags,omitempty"` // Names of the tags assigned to the article
+	// Playback state of a podcast episode that was started or played
+	EnclosureURL    string  `json:"enclosure_url,omitempty"`
+	PodcastPosition float64 `json:"podcast_position,omitempty"` // Playback position in seconds
+	PodcastPlayed   bool    `json:"podcast_played,omitempty"`
 }
 
 // SavedFilter represents a user-saved article filter
@@ -85,7 +129,7 @@ type SavedFilter struct {
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
-// Tag represents a user-defined tag for organizing feeds
+// Tag represents a user-defined tag for organizing feeds and articles
 type Tag struct {
 	ID       int64  `json:"id"`
 	Name     string `json:"name"`
@@ -93,6 +137,261 @@ type Tag struct {
 	Position int    `json:"position"`
 }
 
+// Highlight is a quote from an article's content with an optional note.
+// The quote is anchored by its character offsets in the article's plain text and by
+// the text surrounding it, so it can be found again if the content changes.
+type Highlight struct {
+	ID          int64     `json:"id"`
+	ArticleID   int64     `json:"article_id"`
+	Quote       string    `json:"quote"`            // Highlighted text
+	Prefix      string    `json:"prefix,omitempty"` // Text just before the quote
+	Suffix      string    `json:"suffix,omitempty"` // Text just after the quote
+	StartOffset int       `json:"start_offset"`     // Start of the quote in the plain text (-1 if unknown)
+	EndOffset   int       `json:"end_offset"`       // End of the quote in the plain text (-1 if unknown)
+	Note        string    `json:"note,omitempty"`
+	Color       string    `json:"color,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Webhook is an outbound HTTP endpoint notified of events
+type Webhook struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"` // HMAC key for the signature header, stored encrypted
+	HasSecret  bool      `json:"has_secret"`
+	Events     []string  `json:"events"`     // Event types, e.g. "articles.new", "article.starred"
+	Conditions string    `json:"conditions"` // JSON string of FilterCondition[] applied to article events
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is a queued or completed webhook request
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	WebhookID      int64      `json:"webhook_id"`
+	Event          string     `json:"event"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"` // "pending", "delivered" or "failed"
+	Attempts       int        `json:"attempts"`
+	ResponseStatus int        `json:"response_status"`
+	Error          string     `json:"error,omitempty"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// Digest is an AI briefing generated on a schedule from the recent articles of a
+// saved filter, category or tag
+type Digest struct {
+	ID            int64      `json:"id"`
+	Name          string     `json:"name"`
+	SourceType    string     `json:"source_type"`     // "filter", "category" or "tag"
+	SourceID      int64      `json:"source_id"`       // Saved filter or tag ID
+	Category      string     `json:"category"`        // Category path for the "category" source
+	WindowHours   int        `json:"window_hours"`    // Articles published within this many hours are included
+	Schedule      string     `json:"schedule"`        // "manual", "daily" or "weekly"
+	Hour          int        `json:"hour"`            // Local hour of day scheduled digests are generated (0-23)
+	Weekday       int        `json:"weekday"`         // Day of week weekly digests are generated (0 = Sunday)
+	PublishToFeed bool       `json:"publish_to_feed"` // Add each digest as an article of the Digests feed
+	Enabled       bool       `json:"enabled"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// DigestRun is a generated digest. The briefing is Markdown citing articles by ID, e.g. [42].
+type DigestRun struct {
+	ID           int64            `json:"id"`
+	DigestID     int64            `json:"digest_id"`
+	Title        string           `json:"title"`
+	Content      string           `json:"content"`
+	Citations    []DigestCitation `json:"citations"`
+	ArticleCount int              `json:"article_count"` // Number of articles the digest was generated from
+	Status       string           `json:"status"`        // "success" or "failed"
+	Error        string           `json:"error,omitempty"`
+	ArticleID    int64            `json:"article_id,omitempty"` // Article in the Digests feed
+	HTML         string           `json:"html,omitempty"`       // Rendered content (populated by API handlers)
+	PeriodStart  time.Time        `json:"period_start"`
+	PeriodEnd    time.Time        `json:"period_end"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
+
+// DigestCitation is an article cited by a digest
+type DigestCitation struct {
+	ArticleID int64  `json:"article_id"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	FeedTitle string `json:"feed_title"`
+}
+
+// Download states of a podcast episode
+const (
+	DownloadQueued      = "queued"
+	DownloadDownloading = "downloading"
+	DownloadDone        = "done"
+	DownloadFailed      = "failed"
+)
+
+// PodcastEpisode is the podcast metadata, playback position and download state of an
+// article with an audio enclosure
+type PodcastEpisode struct {
+	ArticleID       int64            `json:"article_id"`
+	FeedID          int64            `json:"feed_id"`
+	EnclosureURL    string           `json:"enclosure_url"`
+	EnclosureType   string           `json:"enclosure_type"`
+	EnclosureLength int64            `json:"enclosure_length"` // Size in bytes announced by the feed, 0 if unknown
+	Duration        float64          `json:"duration"`         // Seconds, from itunes:duration or the player
+	Season          int              `json:"season,omitempty"`
+	Episode         int              `json:"episode,omitempty"`
+	EpisodeType     string           `json:"episode_type,omitempty"` // "full", "trailer" or "bonus"
+	Chapters        []PodcastChapter `json:"chapters,omitempty"`     // Inline Podlove Simple Chapters
+	ChaptersURL     string           `json:"chapters_url,omitempty"` // podcast:chapters JSON document
+	TranscriptURL   string           `json:"transcript_url,omitempty"`
+	TranscriptType  string           `json:"transcript_type,omitempty"`
+	Position        float64          `json:"position"` // Playback position in seconds
+	Played          bool             `json:"played"`
+	DownloadStatus  string           `json:"download_status,omitempty"` // Empty if never downloaded
+	DownloadPath    string           `json:"-"`
+	DownloadedBytes int64            `json:"downloaded_bytes"`
+	DownloadError   string           `json:"download_error,omitempty"`
+	DownloadedAt    *time.Time       `json:"downloaded_at,omitempty"`
+	Title           string           `json:"title,omitempty"`      // Joined field
+	FeedTitle       string           `json:"feed_title,omitempty"` // Joined field
+	PublishedAt     time.Time        `json:"published_at"`         // Joined field
+}
+
+// PodcastChapter is a chapter mark of a podcast episode
+type PodcastChapter struct {
+	Start float64 `json:"start"` // Seconds from the start of the episode
+	Title string  `json:"title"`
+	URL   string  `json:"url,omitempty"`
+}
+
+// PodcastFeedSettings are the download settings of a podcast feed
+type PodcastFeedSettings struct {
+	FeedID        int64 `json:"feed_id"`
+	AutoDownload  bool  `json:"auto_download"`  // Queue new episodes for download when the feed is fetched
+	KeepEpisodes  int   `json:"keep_episodes"`  // Downloaded episodes to keep, newest first (0 = all)
+	RetentionDays int   `json:"retention_days"` // Delete downloads older than this many days (0 = never)
+}
+
+// JSONFeedOptions are the request and pagination options of a JSON API feed.
+// The item mapping is stored in the XPath columns of the feed.
+type JSONFeedOptions struct {
+	FeedID       int64  `json:"feed_id"`
+	Headers      string `json:"headers"`        // Custom request headers, one "Name: value" per line, stored encrypted
+	NextPagePath string `json:"next_page_path"` // JSONPath of the next page URL or cursor
+	PageParam    string `json:"page_param"`     // Query parameter of the page number or cursor
+	MaxPages     int    `json:"max_pages"`      // Pages to fetch per refresh (0 = 1)
+}
+
+// ScriptFeedSettings are the run options of a script feed
+type ScriptFeedSettings struct {
+	FeedID         int64             `json:"feed_id"`
+	Args           []string          `json:"args"`            // Command line arguments
+	Env            map[string]string `json:"env"`             // Environment variables
+	Secrets        map[string]string `json:"secrets"`         // Secret environment variables, stored encrypted; the API only returns their names
+	TimeoutSeconds int               `json:"timeout_seconds"` // Run timeout (0 = 30 seconds)
+}
+
+// ScriptRun records a run of a feed script for debugging
+type ScriptRun struct {
+	ID         int64     `json:"id"`
+	FeedID     int64     `json:"feed_id"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	ExitCode   int       `json:"exit_code"` // -1 if the script could not be started or was stopped
+	Stderr     string    `json:"stderr"`
+	Error      string    `json:"error"` // Empty for successful runs
+	ItemCount  int       `json:"item_count"`
+}
+
+// FeedHTTPOptions customize the requests made for a feed, e.g. to read paywalled or
+// members-only feeds. They apply to requests to the site of the feed: fetching the feed,
+// full-text fetching and proxied media.
+type FeedHTTPOptions struct {
+	FeedID       int64  `json:"feed_id"`
+	Method       string `json:"method"`        // Method of the feed request, GET (default) or POST
+	UserAgent    string `json:"user_agent"`    // Replaces the default browser user agent
+	Headers      string `json:"headers"`       // Custom request headers, one "Name: value" per line, stored encrypted
+	Cookies      string `json:"cookies"`       // Cookies as in a Cookie header, "name=value; name2=value2", stored encrypted
+	AuthType     string `json:"auth_type"`     // "", "basic" or "bearer"
+	AuthUsername string `json:"auth_username"` // Username of Basic auth
+	AuthSecret   string `json:"auth_secret"`   // Password of Basic auth or Bearer token, stored encrypted
+}
+
+// FeedFetchLog records one attempt to fetch a feed
+type FeedFetchLog struct {
+	ID          int64     `json:"id"`
+	FeedID      int64     `json:"feed_id"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	Attempt     int       `json:"attempt"`     // 1 for the first attempt of a refresh, 2 for the retry
+	DurationMs  int64     `json:"duration_ms"` // Duration of the attempt, including parsing and saving
+	HTTPStatus  int       `json:"http_status"` // Status of the last response, 0 without an HTTP response
+	Bytes       int64     `json:"bytes"`       // Size of the response bodies read
+	ItemCount   int       `json:"item_count"`  // Items in the fetched feed
+	NewArticles int       `json:"new_articles"`
+	ErrorClass  string    `json:"error_class,omitempty"`  // "" on success, otherwise e.g. "timeout", "network", "http" or "parse"
+	Error       string    `json:"error,omitempty"`        // Error message of a failed attempt
+	RedirectURL string    `json:"redirect_url,omitempty"` // Target of a permanent redirect (301/308) of the feed URL
+}
+
+// FeedHealth is the refresh state of a feed used by the health policy. It is updated
+// once per refresh, after the retry.
+type FeedHealth struct {
+	FeedID              int64      `json:"feed_id"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	FailingSince        *time.Time `json:"failing_since,omitempty"`   // First failure since the last success
+	LastAttemptAt       *time.Time `json:"last_attempt_at,omitempty"` // Last refresh, successful or not
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastNewArticlesAt   *time.Time `json:"last_new_articles_at,omitempty"` // Last refresh that saved new articles
+	RedirectURL         string     `json:"redirect_url,omitempty"`         // Permanent redirect of the feed URL seen by the last successful refresh
+}
+
+// FeedHealthReport aggregates the fetch log and health state of a feed
+type FeedHealthReport struct {
+	FeedID              int64      `json:"feed_id"`
+	Title               string     `json:"title"`
+	URL                 string     `json:"url"`
+	Status              string     `json:"status"` // "healthy", "failing", "backoff", "paused", "dead" or "unknown"
+	Attempts            int        `json:"attempts"`
+	SuccessRate         float64    `json:"success_rate"` // Share of successful attempts, 0 to 1
+	LatencyP50Ms        int64      `json:"latency_p50_ms"`
+	LatencyP90Ms        int64      `json:"latency_p90_ms"`
+	LatencyP99Ms        int64      `json:"latency_p99_ms"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	FailingSince        *time.Time `json:"failing_since,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastNewArticlesAt   *time.Time `json:"last_new_articles_at,omitempty"`
+	DaysWithoutNew      int        `json:"days_without_new"`          // Days since the last new article, -1 if unknown
+	NextAttemptAt       *time.Time `json:"next_attempt_at,omitempty"` // Earliest scheduled refresh while backing off
+	LastHTTPStatus      int        `json:"last_http_status"`
+	LastErrorClass      string     `json:"last_error_class,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	RedirectURL         string     `json:"redirect_url,omitempty"` // New URL reported by a permanent redirect
+}
+
+// FeedURLChange records a move of a feed to a new URL
+type FeedURLChange struct {
+	ID          int64     `json:"id"`
+	FeedID      int64     `json:"feed_id"`
+	OldURL      string    `json:"old_url"`
+	NewURL      string    `json:"new_url"`
+	Reason      string    `json:"reason"`                 // "redirect", "self_link" or "new_feed_url"
+	MergedTitle string    `json:"merged_title,omitempty"` // Title of the feed at the old URL if it was merged into this one
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+// ItemTransform is the JavaScript transform applied to the items of a feed when it is fetched
+type ItemTransform struct {
+	FeedID  int64  `json:"feed_id"`
+	Script  string `json:"script"` // Defines transform(item, feed); see package feed/transform
+	Enabled bool   `json:"enabled"`
+}
+
 // AIProfile represents an AI configuration profile
 type AIProfile struct {
 	ID            int64     `json:"id"`
@@ -105,3 +404,13 @@ type AIProfile struct {
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
+
+// User represents an account that can sign in to a server-mode instance
+type User struct {
+	ID           int64      `json:"id"`
+	Username     string     `json:"username"`
+	PasswordHash string     `json:"-"`
+	IsAdmin      bool       `json:"is_admin"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastLoginAt  *time.Time `json:"last_login_at,omitempty"`
+}
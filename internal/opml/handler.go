@@ -1,6 +1,7 @@
 package opml
 
 import (
+	"MrRSS/internal/feed/httpoptions"
 	"MrRSS/internal/models"
 	"MrRSS/internal/utils"
 	"bytes"
@@ -50,6 +51,51 @@ type Outline struct {
 	XPathItemThumbnail  string `xml:"xPathItemThumbnail,attr"`
 	XPathItemCategories string `xml:"xPathItemCategories,attr"`
 	XPathItemUid        string `xml:"xPathItemUid,attr"`
+	// MrRSS HTTP option attributes, without secrets: header and cookie names are comma-separated
+	HTTPMethod    string `xml:"httpMethod,attr,omitempty"`
+	HTTPUserAgent string `xml:"httpUserAgent,attr,omitempty"`
+	HTTPHeaders   string `xml:"httpHeaders,attr,omitempty"`
+	HTTPCookies   string `xml:"httpCookies,attr,omitempty"`
+	HTTPAuth      string `xml:"httpAuth,attr,omitempty"`
+	HTTPAuthUser  string `xml:"httpAuthUser,attr,omitempty"`
+}
+
+// httpOptions returns the HTTP options of an outline, with empty header and cookie
+// values, or nil if it has none
+func (o *Outline) httpOptions() *models.FeedHTTPOptions {
+	if o.HTTPMethod == "" && o.HTTPUserAgent == "" && o.HTTPHeaders == "" && o.HTTPCookies == "" && o.HTTPAuth == "" {
+		return nil
+	}
+	options := httpoptions.FromNames(splitNames(o.HTTPHeaders), splitNames(o.HTTPCookies))
+	options.Method = o.HTTPMethod
+	options.UserAgent = o.HTTPUserAgent
+	options.AuthType = o.HTTPAuth
+	options.AuthUsername = o.HTTPAuthUser
+	return options
+}
+
+// setHTTPOptions sets the HTTP option attributes of an outline, leaving out all secrets
+func (o *Outline) setHTTPOptions(options *models.FeedHTTPOptions) {
+	if options == nil {
+		return
+	}
+	o.HTTPMethod = options.Method
+	o.HTTPUserAgent = options.UserAgent
+	o.HTTPHeaders = strings.Join(httpoptions.HeaderNames(options), ",")
+	o.HTTPCookies = strings.Join(httpoptions.CookieNames(options), ",")
+	o.HTTPAuth = options.AuthType
+	o.HTTPAuthUser = options.AuthUsername
+}
+
+// splitNames splits a comma-separated list of names
+func splitNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 // normalizeOPMLAttributes normalizes attribute names in OPML content to handle
@@ -192,6 +238,7 @@ func Parse(r io.Reader) ([]models.Feed, error) {
 					XPathItemThumbnail:  o.XPathItemThumbnail,
 					XPathItemCategories: o.XPathItemCategories,
 					XPathItemUid:        o.XPathItemUid,
+					HTTPOptions:         o.httpOptions(),
 				})
 			}
 
@@ -300,7 +347,7 @@ func Generate(feeds []models.Feed) ([]byte, error) {
 			}
 		}
 
-		*currentOutlines = append(*currentOutlines, &Outline{
+		outline := &Outline{
 			Text:   f.Title,
 			Title:  f.Title,
 			Type:   f.Type,
@@ -316,7 +363,9 @@ func Generate(feeds []models.Feed) ([]byte, error) {
 			XPathItemThumbnail:  f.XPathItemThumbnail,
 			XPathItemCategories: f.XPathItemCategories,
 			XPathItemUid:        f.XPathItemUid,
-		})
+		}
+		outline.setHTTPOptions(f.HTTPOptions)
+		*currentOutlines = append(*currentOutlines, outline)
 
 		// Add tags as additional outline elements with "tag:" prefix in category
 		for _, tag := range f.Tags {
@@ -64,3 +64,47 @@ func TestGenerate(t *testing.T) {
 		t.Error("Generated XML missing Feed 2 URL")
 	}
 }
+
+func TestGenerateAndParse_HTTPOptions(t *testing.T) {
+	feeds := []models.Feed{{
+		Title: "Members",
+		URL:   "https://example.com/members.xml",
+		HTTPOptions: &models.FeedHTTPOptions{
+			Method:       "POST",
+			UserAgent:    "Reader/1.0",
+			Headers:      "X-Api-Key: secret-key\nX-Plan: gold",
+			Cookies:      "session=secret-session; lang=en",
+			AuthType:     "basic",
+			AuthUsername: "reader",
+			AuthSecret:   "secret-password",
+		},
+	}}
+
+	data, err := Generate(feeds)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("Generated XML contains secrets: %s", data)
+	}
+
+	parsed, err := Parse(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	options := parsed[0].HTTPOptions
+	if options == nil {
+		t.Fatal("Expected HTTP options to be parsed")
+	}
+	want := models.FeedHTTPOptions{
+		Method:       "POST",
+		UserAgent:    "Reader/1.0",
+		Headers:      "X-Api-Key:\nX-Plan:",
+		Cookies:      "session=; lang=",
+		AuthType:     "basic",
+		AuthUsername: "reader",
+	}
+	if *options != want {
+		t.Errorf("Expected %+v, got %+v", want, *options)
+	}
+}
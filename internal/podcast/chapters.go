@@ -0,0 +1,77 @@
+package podcast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+const (
+	chaptersTimeout  = 15 * time.Second
+	maxChaptersBytes = 1 << 20
+)
+
+// chaptersDocument is a Podcasting 2.0 JSON chapters document
+type chaptersDocument struct {
+	Chapters []struct {
+		StartTime float64 `json:"startTime"`
+		Title     string  `json:"title"`
+		URL       string  `json:"url"`
+		TOC       *bool   `json:"toc"` // Chapters with toc false are not shown in the table of contents
+	} `json:"chapters"`
+}
+
+// LoadChapters fetches the chapters document of an episode without inline chapters and
+// stores its chapters in the episode
+func (d *Downloader) LoadChapters(ctx context.Context, ep *models.PodcastEpisode) error {
+	if len(ep.Chapters) > 0 || ep.ChaptersURL == "" {
+		return nil
+	}
+
+	client, err := d.httpClient(chaptersTimeout)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ep.ChaptersURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned HTTP %d", resp.StatusCode)
+	}
+
+	var doc chaptersDocument
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxChaptersBytes)).Decode(&doc); err != nil {
+		return fmt.Errorf("invalid chapters document: %w", err)
+	}
+
+	chapters := []models.PodcastChapter{}
+	for _, c := range doc.Chapters {
+		title := strings.TrimSpace(c.Title)
+		if title == "" || (c.TOC != nil && !*c.TOC) {
+			continue
+		}
+		chapters = append(chapters, models.PodcastChapter{Start: c.StartTime, Title: title, URL: c.URL})
+	}
+	sort.SliceStable(chapters, func(i, j int) bool { return chapters[i].Start < chapters[j].Start })
+
+	// Documents without chapters are stored too, so that they are not fetched again
+	if err := d.db.SetPodcastChapters(ep.ArticleID, chapters); err != nil {
+		return err
+	}
+	ep.Chapters = chapters
+	return nil
+}
@@ -0,0 +1,552 @@
+// Package podcast downloads podcast episodes to the data directory. Downloads are queued in
+// the database and run in the background with a concurrency limit and a storage quota;
+// the oldest downloads are deleted to make room for new ones.
+package podcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils"
+	"MrRSS/internal/utils/fileutil"
+	"MrRSS/internal/utils/httputil"
+)
+
+const (
+	defaultConcurrency = 2
+	maxConcurrency     = 10
+	defaultQuotaMB     = 2048
+	pollInterval       = 30 * time.Second
+	cleanupInterval    = time.Hour
+	downloadTimeout    = 2 * time.Hour
+	// reserveChunk is how much quota is reserved at a time for downloads of unknown size
+	reserveChunk   = 16 << 20
+	maxErrorLength = 500
+	partSuffix     = ".part"
+	userAgent      = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+)
+
+// ErrQuotaExceeded is returned when an episode does not fit in the storage quota
+var ErrQuotaExceeded = errors.New("podcast storage quota exceeded")
+
+// Downloader runs the queued podcast downloads
+type Downloader struct {
+	db   *database.DB
+	wake chan struct{}
+	dir  func() (string, error)
+
+	mu       sync.Mutex
+	active   map[int64]context.CancelFunc // Running downloads by article ID
+	reserved map[int64]int64              // Quota reserved by running downloads, in bytes
+	done     chan int64
+}
+
+// NewDownloader creates a downloader for the episodes stored in db
+func NewDownloader(db *database.DB) *Downloader {
+	return &Downloader{
+		db:       db,
+		wake:     make(chan struct{}, 1),
+		dir:      fileutil.GetPodcastsDir,
+		active:   make(map[int64]context.CancelFunc),
+		reserved: make(map[int64]int64),
+		done:     make(chan int64, maxConcurrency),
+	}
+}
+
+// SetDir sets the directory episodes are downloaded to, instead of the data directory
+func (d *Downloader) SetDir(dir string) {
+	d.dir = func() (string, error) {
+		return dir, os.MkdirAll(dir, 0755)
+	}
+}
+
+// Wake starts queued downloads immediately
+func (d *Downloader) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Queue queues the episode of an article for download
+func (d *Downloader) Queue(articleID int64) error {
+	ep, err := d.db.EnsurePodcastEpisode(articleID)
+	if err != nil {
+		return err
+	}
+	if ep == nil {
+		return errors.New("article has no audio enclosure")
+	}
+	if _, err := d.db.QueuePodcastDownload(articleID); err != nil {
+		return err
+	}
+	d.Wake()
+	return nil
+}
+
+// Delete cancels or deletes the download of an episode
+func (d *Downloader) Delete(articleID int64) error {
+	d.mu.Lock()
+	cancel := d.active[articleID]
+	d.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	ep, err := d.db.GetPodcastEpisode(articleID)
+	if err != nil || ep == nil {
+		return err
+	}
+	return d.remove(ep)
+}
+
+// UsedBytes returns the total size of the downloaded episodes
+func (d *Downloader) UsedBytes() (int64, error) {
+	return d.db.GetPodcastDownloadedBytes()
+}
+
+// QuotaBytes returns the storage quota in bytes, 0 if unlimited
+func (d *Downloader) QuotaBytes() int64 {
+	quota := int64(defaultQuotaMB)
+	if value, err := d.db.GetSetting("podcast_storage_quota_mb"); err == nil && value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && n >= 0 {
+			quota = n
+		}
+	}
+	return quota << 20
+}
+
+// Run downloads queued episodes and applies the retention settings until ctx is cancelled
+func (d *Downloader) Run(ctx context.Context) {
+	if err := d.db.RequeueInterruptedPodcastDownloads(); err != nil {
+		log.Printf("Error requeueing podcast downloads: %v", err)
+	}
+	d.removePartialFiles()
+	d.Cleanup()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		d.startDownloads(ctx)
+
+		select {
+		case <-ctx.Done():
+			d.mu.Lock()
+			for _, cancel := range d.active {
+				cancel()
+			}
+			d.mu.Unlock()
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		case <-d.done:
+		case <-cleanup.C:
+			d.Cleanup()
+		}
+	}
+}
+
+// startDownloads starts queued downloads up to the concurrency limit
+func (d *Downloader) startDownloads(ctx context.Context) {
+	limit := d.concurrency()
+	for {
+		d.mu.Lock()
+		running := len(d.active)
+		d.mu.Unlock()
+		if running >= limit {
+			return
+		}
+
+		ep, err := d.db.ClaimPodcastDownload()
+		if err != nil {
+			log.Printf("Error claiming podcast download: %v", err)
+			return
+		}
+		if ep == nil {
+			return
+		}
+
+		downloadCtx, cancel := context.WithTimeout(ctx, downloadTimeout)
+		d.mu.Lock()
+		d.active[ep.ArticleID] = cancel
+		d.mu.Unlock()
+
+		go func() {
+			defer func() {
+				cancel()
+				d.mu.Lock()
+				delete(d.active, ep.ArticleID)
+				delete(d.reserved, ep.ArticleID)
+				d.mu.Unlock()
+				d.done <- ep.ArticleID
+			}()
+			d.download(downloadCtx, ep)
+		}()
+	}
+}
+
+// download downloads an episode and records the result
+func (d *Downloader) download(ctx context.Context, ep *models.PodcastEpisode) {
+	filePath, size, err := d.fetch(ctx, ep)
+	if err != nil {
+		// A download deleted by the user was already cleared
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		message := err.Error()
+		if len(message) > maxErrorLength {
+			message = message[:maxErrorLength]
+		}
+		log.Printf("Error downloading podcast episode %d: %v", ep.ArticleID, err)
+		if err := d.db.FailPodcastDownload(ep.ArticleID, message); err != nil {
+			log.Printf("Error recording podcast download failure: %v", err)
+		}
+		return
+	}
+
+	if err := d.db.CompletePodcastDownload(ep.ArticleID, filePath, size); err != nil {
+		log.Printf("Error recording podcast download: %v", err)
+		os.Remove(filePath)
+		return
+	}
+	utils.DebugLog("Downloaded podcast episode %d (%d bytes)", ep.ArticleID, size)
+}
+
+// fetch downloads the enclosure of an episode to the podcasts directory and returns
+// the path and size of the file
+func (d *Downloader) fetch(ctx context.Context, ep *models.PodcastEpisode) (string, int64, error) {
+	dir, err := d.dir()
+	if err != nil {
+		return "", 0, err
+	}
+	if ep.EnclosureLength > 0 {
+		if err := d.reserve(ep.ArticleID, ep.EnclosureLength); err != nil {
+			return "", 0, err
+		}
+	}
+
+	client, err := d.httpClient(0)
+	if err != nil {
+		return "", 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ep.EnclosureURL, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("server returned HTTP %d", resp.StatusCode)
+	}
+	if resp.ContentLength > 0 {
+		if err := d.reserve(ep.ArticleID, resp.ContentLength); err != nil {
+			return "", 0, err
+		}
+	}
+
+	filePath := filepath.Join(dir, strconv.FormatInt(ep.ArticleID, 10)+fileExtension(ep.EnclosureURL, resp.Header.Get("Content-Type")))
+	partPath := filePath + partSuffix
+	file, err := os.Create(partPath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	size, err := d.copy(file, resp.Body, ep.ArticleID)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(partPath, filePath)
+	}
+	if err != nil {
+		os.Remove(partPath)
+		return "", 0, err
+	}
+	return filePath, size, nil
+}
+
+// copy copies the body to the file, reserving quota as the file grows
+func (d *Downloader) copy(dst io.Writer, src io.Reader, articleID int64) (int64, error) {
+	buf := make([]byte, 64<<10)
+	var written int64
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			written += int64(n)
+			if written > d.reservation(articleID) {
+				// Reserve ahead, or just what was written when the quota is almost full
+				if err := d.reserve(articleID, written+reserveChunk); err != nil {
+					if !errors.Is(err, ErrQuotaExceeded) {
+						return written, err
+					}
+					if err := d.reserve(articleID, written); err != nil {
+						return written, err
+					}
+				}
+			}
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return written, err
+			}
+		}
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// reservation returns the quota reserved by a running download
+func (d *Downloader) reservation(articleID int64) int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.reserved[articleID]
+}
+
+// reserve reserves quota for a running download, deleting the downloads that are played
+// or oldest until the download fits
+func (d *Downloader) reserve(articleID, size int64) error {
+	quota := d.QuotaBytes()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if quota == 0 {
+		d.reserved[articleID] = size
+		return nil
+	}
+	if size > quota {
+		return ErrQuotaExceeded
+	}
+
+	used, err := d.db.GetPodcastDownloadedBytes()
+	if err != nil {
+		return err
+	}
+	for id, r := range d.reserved {
+		if id != articleID {
+			used += r
+		}
+	}
+	if used+size > quota {
+		evictable, err := d.db.GetEvictablePodcastDownloads()
+		if err != nil {
+			return err
+		}
+		for i := range evictable {
+			if used+size <= quota {
+				break
+			}
+			if err := d.remove(&evictable[i]); err != nil {
+				return err
+			}
+			used -= evictable[i].DownloadedBytes
+		}
+		if used+size > quota {
+			return ErrQuotaExceeded
+		}
+	}
+
+	d.reserved[articleID] = size
+	return nil
+}
+
+// Cleanup deletes the downloads of deleted articles and the downloads beyond the
+// per-feed retention settings
+func (d *Downloader) Cleanup() {
+	orphans, err := d.db.GetOrphanedPodcastEpisodes()
+	if err != nil {
+		log.Printf("Error getting orphaned podcast episodes: %v", err)
+	}
+	for i := range orphans {
+		if d.isActive(orphans[i].ArticleID) {
+			continue
+		}
+		d.removeFile(&orphans[i])
+		if err := d.db.DeletePodcastEpisode(orphans[i].ArticleID); err != nil {
+			log.Printf("Error deleting podcast episode %d: %v", orphans[i].ArticleID, err)
+		}
+	}
+
+	settings, err := d.db.GetAllPodcastFeedSettings()
+	if err != nil {
+		log.Printf("Error getting podcast feed settings: %v", err)
+		return
+	}
+	removed := 0
+	for _, s := range settings {
+		if s.KeepEpisodes <= 0 && s.RetentionDays <= 0 {
+			continue
+		}
+		downloads, err := d.db.GetPodcastDownloads(s.FeedID)
+		if err != nil {
+			log.Printf("Error getting podcast downloads of feed %d: %v", s.FeedID, err)
+			continue
+		}
+		for _, ep := range expiredDownloads(downloads, s, time.Now()) {
+			if err := d.remove(&ep); err != nil {
+				log.Printf("Error deleting podcast download %d: %v", ep.ArticleID, err)
+				continue
+			}
+			removed++
+		}
+	}
+	if removed > 0 {
+		utils.DebugLog("Deleted %d podcast downloads by retention settings", removed)
+	}
+}
+
+// expiredDownloads returns the completed downloads of a feed, newest first, that its
+// retention settings no longer keep
+func expiredDownloads(downloads []models.PodcastEpisode, s models.PodcastFeedSettings, now time.Time) []models.PodcastEpisode {
+	var expired []models.PodcastEpisode
+	kept := 0
+	for _, ep := range downloads {
+		if ep.DownloadStatus != models.DownloadDone {
+			continue
+		}
+		tooMany := s.KeepEpisodes > 0 && kept >= s.KeepEpisodes
+		tooOld := s.RetentionDays > 0 && ep.DownloadedAt != nil &&
+			now.Sub(*ep.DownloadedAt) > time.Duration(s.RetentionDays)*24*time.Hour
+		if tooMany || tooOld {
+			expired = append(expired, ep)
+		} else {
+			kept++
+		}
+	}
+	return expired
+}
+
+// remove deletes the downloaded file of an episode and forgets the download
+func (d *Downloader) remove(ep *models.PodcastEpisode) error {
+	d.removeFile(ep)
+	return d.db.ClearPodcastDownload(ep.ArticleID)
+}
+
+// removeFile deletes the downloaded file of an episode, if it is in the podcasts directory
+func (d *Downloader) removeFile(ep *models.PodcastEpisode) {
+	if ep.DownloadPath == "" {
+		return
+	}
+	path, err := d.FilePath(ep)
+	if err != nil {
+		log.Printf("Not deleting podcast file %s: %v", ep.DownloadPath, err)
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("Error deleting podcast file %s: %v", path, err)
+	}
+}
+
+// FilePath returns the path of the downloaded file of an episode, checking that it is
+// in the podcasts directory
+func (d *Downloader) FilePath(ep *models.PodcastEpisode) (string, error) {
+	dir, err := d.dir()
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(dir, ep.DownloadPath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") || filepath.IsAbs(rel) {
+		return "", errors.New("file is outside the podcasts directory")
+	}
+	return filepath.Join(dir, rel), nil
+}
+
+// removePartialFiles deletes the files of downloads that were interrupted
+func (d *Downloader) removePartialFiles() {
+	dir, err := d.dir()
+	if err != nil {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), partSuffix) {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
+
+func (d *Downloader) isActive(articleID int64) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.active[articleID] != nil
+}
+
+// concurrency returns the number of episodes downloaded at the same time
+func (d *Downloader) concurrency() int {
+	value, _ := d.db.GetSetting("podcast_download_concurrency")
+	if n, err := strconv.Atoi(value); err == nil && n > 0 {
+		return min(n, maxConcurrency)
+	}
+	return defaultConcurrency
+}
+
+// httpClient creates an HTTP client with the global proxy settings if enabled
+func (d *Downloader) httpClient(timeout time.Duration) (*http.Client, error) {
+	var proxyURL string
+	if proxyEnabled, _ := d.db.GetSetting("proxy_enabled"); proxyEnabled == "true" {
+		proxyType, _ := d.db.GetSetting("proxy_type")
+		proxyHost, _ := d.db.GetSetting("proxy_host")
+		proxyPort, _ := d.db.GetSetting("proxy_port")
+		proxyUsername, _ := d.db.GetEncryptedSetting("proxy_username")
+		proxyPassword, _ := d.db.GetEncryptedSetting("proxy_password")
+		proxyURL = httputil.BuildProxyURL(proxyType, proxyHost, proxyPort, proxyUsername, proxyPassword)
+	}
+	return httputil.CreateHTTPClient(proxyURL, timeout)
+}
+
+// audioExtensions are the file extensions of enclosures that are kept for downloaded files
+var audioExtensions = map[string]bool{
+	".mp3": true, ".m4a": true, ".m4b": true, ".aac": true, ".ogg": true, ".oga": true,
+	".opus": true, ".flac": true, ".wav": true, ".mp4": true,
+}
+
+// audioTypeExtensions are the file extensions of enclosures whose URL has none, by content type
+var audioTypeExtensions = map[string]string{
+	"audio/mpeg": ".mp3", "audio/mp3": ".mp3", "audio/mp4": ".m4a", "audio/x-m4a": ".m4a",
+	"audio/aac": ".aac", "audio/ogg": ".ogg", "audio/opus": ".opus", "audio/flac": ".flac",
+	"audio/wav": ".wav", "audio/x-wav": ".wav",
+}
+
+// fileExtension returns the file extension of an enclosure, from its URL or content type
+func fileExtension(rawURL, contentType string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		ext := strings.ToLower(path.Ext(u.Path))
+		if audioExtensions[ext] {
+			return ext
+		}
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if ext, ok := audioTypeExtensions[mediaType]; ok {
+			return ext
+		}
+	}
+	return ".mp3"
+}
@@ -0,0 +1,227 @@
+package podcast
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func setupDownloader(t *testing.T) (*database.DB, *Downloader, int64) {
+	t.Helper()
+	// The downloader uses the database from several goroutines, which would each see
+	// their own empty in-memory database
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	feedID, err := db.AddFeed(&models.Feed{Title: "Podcast", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	d := NewDownloader(db)
+	d.SetDir(t.TempDir())
+	return db, d, feedID
+}
+
+func addEpisode(t *testing.T, db *database.DB, feedID int64, title, audioURL string, published time.Time) int64 {
+	t.Helper()
+	article := &models.Article{FeedID: feedID, Title: title, URL: audioURL, AudioURL: audioURL, PublishedAt: published, HasValidPublishedTime: true}
+	if err := db.SaveArticle(article); err != nil {
+		t.Fatalf("SaveArticle failed: %v", err)
+	}
+	id, err := db.GetArticleIDByUniqueID(title, feedID, published, true)
+	if err != nil {
+		t.Fatalf("GetArticleIDByUniqueID failed: %v", err)
+	}
+	return id
+}
+
+// waitForDownloads waits until no download is queued or running
+func waitForDownloads(t *testing.T, db *database.DB) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		downloads, err := db.GetPodcastDownloads(0)
+		if err != nil {
+			t.Fatalf("GetPodcastDownloads failed: %v", err)
+		}
+		pending := false
+		for _, ep := range downloads {
+			if ep.DownloadStatus == models.DownloadQueued || ep.DownloadStatus == models.DownloadDownloading {
+				pending = true
+			}
+		}
+		if !pending {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for downloads")
+}
+
+func TestDownloader_DownloadAndQuota(t *testing.T) {
+	audio := strings.Repeat("a", 600<<10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.mp3" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte(audio))
+	}))
+	defer server.Close()
+
+	db, d, feedID := setupDownloader(t)
+	db.SetSetting("podcast_storage_quota_mb", "1")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	now := time.Now()
+	first := addEpisode(t, db, feedID, "First", server.URL+"/first.mp3", now.Add(-2*time.Hour))
+	missing := addEpisode(t, db, feedID, "Missing", server.URL+"/missing.mp3", now.Add(-time.Hour))
+	for _, id := range []int64{first, missing} {
+		if err := d.Queue(id); err != nil {
+			t.Fatalf("Queue failed: %v", err)
+		}
+	}
+	waitForDownloads(t, db)
+
+	ep, _ := db.GetPodcastEpisode(first)
+	if ep.DownloadStatus != models.DownloadDone || ep.DownloadedBytes != int64(len(audio)) {
+		t.Fatalf("Expected the episode to be downloaded, got %+v", ep)
+	}
+	path, err := d.FilePath(ep)
+	if err != nil || !strings.HasSuffix(path, ".mp3") {
+		t.Fatalf("Unexpected file path %q (%v)", path, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != int64(len(audio)) {
+		t.Fatalf("Expected the downloaded file to exist, got %v", err)
+	}
+	if ep, _ := db.GetPodcastEpisode(missing); ep.DownloadStatus != models.DownloadFailed || ep.DownloadError == "" {
+		t.Errorf("Expected the missing episode to fail, got %+v", ep)
+	}
+
+	// A second episode does not fit next to the first one, which is deleted to make room
+	second := addEpisode(t, db, feedID, "Second", server.URL+"/second.mp3", now)
+	if err := d.Queue(second); err != nil {
+		t.Fatalf("Queue failed: %v", err)
+	}
+	waitForDownloads(t, db)
+
+	if ep, _ := db.GetPodcastEpisode(second); ep.DownloadStatus != models.DownloadDone {
+		t.Errorf("Expected the second episode to be downloaded, got %+v", ep)
+	}
+	if ep, _ := db.GetPodcastEpisode(first); ep.DownloadStatus != "" {
+		t.Errorf("Expected the first episode to be evicted, got %+v", ep)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the evicted file to be deleted, got %v", err)
+	}
+
+	if err := d.Delete(second); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if used, _ := d.UsedBytes(); used != 0 {
+		t.Errorf("Expected no storage to be used after deleting the download, got %d", used)
+	}
+}
+
+func TestExpiredDownloads(t *testing.T) {
+	now := time.Now()
+	at := func(days int) *time.Time {
+		t := now.Add(-time.Duration(days) * 24 * time.Hour)
+		return &t
+	}
+	downloads := []models.PodcastEpisode{
+		{ArticleID: 1, DownloadStatus: models.DownloadDone, DownloadedAt: at(1)},
+		{ArticleID: 2, DownloadStatus: models.DownloadQueued},
+		{ArticleID: 3, DownloadStatus: models.DownloadDone, DownloadedAt: at(2)},
+		{ArticleID: 4, DownloadStatus: models.DownloadDone, DownloadedAt: at(3)},
+		{ArticleID: 5, DownloadStatus: models.DownloadDone, DownloadedAt: at(40)},
+	}
+
+	ids := func(episodes []models.PodcastEpisode) []int64 {
+		var result []int64
+		for _, ep := range episodes {
+			result = append(result, ep.ArticleID)
+		}
+		return result
+	}
+
+	if got := ids(expiredDownloads(downloads, models.PodcastFeedSettings{KeepEpisodes: 2}, now)); len(got) != 2 || got[0] != 4 || got[1] != 5 {
+		t.Errorf("Expected all but the 2 newest downloads to expire, got %v", got)
+	}
+	if got := ids(expiredDownloads(downloads, models.PodcastFeedSettings{RetentionDays: 30}, now)); len(got) != 1 || got[0] != 5 {
+		t.Errorf("Expected downloads older than 30 days to expire, got %v", got)
+	}
+	if got := expiredDownloads(downloads, models.PodcastFeedSettings{}, now); len(got) != 0 {
+		t.Errorf("Expected nothing to expire without retention settings, got %v", ids(got))
+	}
+}
+
+func TestFileExtension(t *testing.T) {
+	tests := []struct {
+		url, contentType, want string
+	}{
+		{"https://example.com/episode.m4a?token=1", "", ".m4a"},
+		{"https://example.com/download?id=5", "audio/ogg", ".ogg"},
+		{"https://example.com/stream", "", ".mp3"},
+		{"https://example.com/episode.php", "", ".mp3"},
+	}
+	for _, tt := range tests {
+		if got := fileExtension(tt.url, tt.contentType); got != tt.want {
+			t.Errorf("fileExtension(%q, %q) = %q, want %q", tt.url, tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestLoadChapters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version": "1.2.0", "chapters": [
+			{"startTime": 120.5, "title": "Main topic", "url": "https://example.com/topic"},
+			{"startTime": 0, "title": "Intro"},
+			{"startTime": 60, "title": "Hidden", "toc": false}
+		]}`))
+	}))
+	defer server.Close()
+
+	db, d, feedID := setupDownloader(t)
+	articleID := addEpisode(t, db, feedID, "Episode", "https://example.com/episode.mp3", time.Now())
+	ep, err := db.EnsurePodcastEpisode(articleID)
+	if err != nil || ep == nil {
+		t.Fatalf("EnsurePodcastEpisode failed: %v", err)
+	}
+	ep.ChaptersURL = server.URL + "/chapters.json"
+	if _, err := db.SavePodcastEpisode(ep); err != nil {
+		t.Fatalf("SavePodcastEpisode failed: %v", err)
+	}
+
+	if err := d.LoadChapters(context.Background(), ep); err != nil {
+		t.Fatalf("LoadChapters failed: %v", err)
+	}
+	stored, _ := db.GetPodcastEpisode(articleID)
+	if len(stored.Chapters) != 2 || stored.Chapters[0].Title != "Intro" || stored.Chapters[1].Start != 120.5 {
+		t.Errorf("Expected 2 chapters ordered by start time, got %+v", stored.Chapters)
+	}
+
+	// Refreshing the feed keeps the loaded chapters
+	ep.Chapters = nil
+	if _, err := db.SavePodcastEpisode(ep); err != nil {
+		t.Fatalf("SavePodcastEpisode failed: %v", err)
+	}
+	if stored, _ := db.GetPodcastEpisode(articleID); len(stored.Chapters) != 2 {
+		t.Errorf("Expected the loaded chapters to be kept, got %+v", stored.Chapters)
+	}
+}
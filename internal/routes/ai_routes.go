@@ -8,6 +8,7 @@ import (
 	aihandlers "MrRSS/internal/handlers/ai"
 	chat "MrRSS/internal/handlers/chat"
 	"MrRSS/internal/handlers/core"
+	digesthandlers "MrRSS/internal/handlers/digest"
 	"MrRSS/internal/handlers/response"
 )
 
@@ -15,6 +16,7 @@ import (
 func registerAIRoutes(mux *http.ServeMux, h *core.Handler) {
 	// AI Chat
 	mux.HandleFunc("/api/ai-chat", func(w http.ResponseWriter, r *http.Request) { chat.HandleAIChat(h, w, r) })
+	mux.HandleFunc("/api/ai-chat/stream", func(w http.ResponseWriter, r *http.Request) { chat.HandleAIChatStream(h, w, r) })
 	mux.HandleFunc("/api/ai/chat/sessions/delete-all", func(w http.ResponseWriter, r *http.Request) { chat.HandleDeleteAllSessions(h, w, r) })
 	mux.HandleFunc("/api/ai/chat/sessions", func(w http.ResponseWriter, r *http.Request) { chat.HandleListSessions(h, w, r) })
 	mux.HandleFunc("/api/ai/chat/session/create", func(w http.ResponseWriter, r *http.Request) { chat.HandleCreateSession(h, w, r) })
@@ -38,6 +40,18 @@ func registerAIRoutes(mux *http.ServeMux, h *core.Handler) {
 	mux.HandleFunc("/api/ai/test/info", func(w http.ResponseWriter, r *http.Request) { aihandlers.HandleGetAITestInfo(h, w, r) })
 	mux.HandleFunc("/api/ai/search", func(w http.ResponseWriter, r *http.Request) { aihandlers.HandleAISearch(h, w, r) })
 
+	// Embeddings for semantic search and related articles
+	mux.HandleFunc("/api/articles/related", func(w http.ResponseWriter, r *http.Request) { aihandlers.HandleRelatedArticles(h, w, r) })
+	mux.HandleFunc("/api/ai/embeddings/status", func(w http.ResponseWriter, r *http.Request) { aihandlers.HandleEmbeddingStatus(h, w, r) })
+	mux.HandleFunc("/api/ai/embeddings/clear", func(w http.ResponseWriter, r *http.Request) { aihandlers.HandleClearEmbeddings(h, w, r) })
+
+	// AI digests
+	mux.HandleFunc("/api/digests", func(w http.ResponseWriter, r *http.Request) { digesthandlers.HandleDigests(h, w, r) })
+	mux.HandleFunc("/api/digests/update", func(w http.ResponseWriter, r *http.Request) { digesthandlers.HandleDigestUpdate(h, w, r) })
+	mux.HandleFunc("/api/digests/delete", func(w http.ResponseWriter, r *http.Request) { digesthandlers.HandleDigestDelete(h, w, r) })
+	mux.HandleFunc("/api/digests/generate", func(w http.ResponseWriter, r *http.Request) { digesthandlers.HandleDigestGenerate(h, w, r) })
+	mux.HandleFunc("/api/digests/runs", func(w http.ResponseWriter, r *http.Request) { digesthandlers.HandleDigestRuns(h, w, r) })
+
 	// AI Profiles
 	mux.HandleFunc("/api/ai/profiles/test-all", func(w http.ResponseWriter, r *http.Request) { aihandlers.HandleTestAllAIProfiles(h, w, r) })
 	mux.HandleFunc("/api/ai/profiles/test-config", func(w http.ResponseWriter, r *http.Request) { aihandlers.HandleTestAIProfileConfig(h, w, r) })
@@ -5,6 +5,7 @@ import (
 
 	article "MrRSS/internal/handlers/article"
 	"MrRSS/internal/handlers/core"
+	highlights "MrRSS/internal/handlers/highlights"
 	summary "MrRSS/internal/handlers/summary"
 	translationhandlers "MrRSS/internal/handlers/translation"
 )
@@ -15,6 +16,7 @@ func registerArticleRoutes(mux *http.ServeMux, h *core.Handler) {
 	mux.HandleFunc("/api/articles", func(w http.ResponseWriter, r *http.Request) { article.HandleArticles(h, w, r) })
 	mux.HandleFunc("/api/articles/images", func(w http.ResponseWriter, r *http.Request) { article.HandleImageGalleryArticles(h, w, r) })
 	mux.HandleFunc("/api/articles/filter", func(w http.ResponseWriter, r *http.Request) { article.HandleFilteredArticles(h, w, r) })
+	mux.HandleFunc("/api/articles/search", func(w http.ResponseWriter, r *http.Request) { article.HandleSearchArticles(h, w, r) })
 	mux.HandleFunc("/api/articles/read", func(w http.ResponseWriter, r *http.Request) { article.HandleMarkReadWithImmediateSync(h, w, r) })
 	mux.HandleFunc("/api/articles/favorite", func(w http.ResponseWriter, r *http.Request) { article.HandleToggleFavoriteWithImmediateSync(h, w, r) })
 	mux.HandleFunc("/api/articles/mark-relative", func(w http.ResponseWriter, r *http.Request) { article.HandleMarkRelativeToArticle(h, w, r) })
@@ -22,6 +24,20 @@ func registerArticleRoutes(mux *http.ServeMux, h *core.Handler) {
 	mux.HandleFunc("/api/articles/toggle-read-later", func(w http.ResponseWriter, r *http.Request) { article.HandleToggleReadLater(h, w, r) })
 	mux.HandleFunc("/api/articles/mark-all-read", func(w http.ResponseWriter, r *http.Request) { article.HandleMarkAllAsRead(h, w, r) })
 	mux.HandleFunc("/api/articles/clear-read-later", func(w http.ResponseWriter, r *http.Request) { article.HandleClearReadLater(h, w, r) })
+	mux.HandleFunc("/api/articles/story", func(w http.ResponseWriter, r *http.Request) { article.HandleArticleStory(h, w, r) })
+
+	// Article tags
+	mux.HandleFunc("/api/articles/tags", func(w http.ResponseWriter, r *http.Request) { article.HandleArticleTags(h, w, r) })
+	mux.HandleFunc("/api/articles/tags/add", func(w http.ResponseWriter, r *http.Request) { article.HandleAddArticleTag(h, w, r) })
+	mux.HandleFunc("/api/articles/tags/remove", func(w http.ResponseWriter, r *http.Request) { article.HandleRemoveArticleTag(h, w, r) })
+	mux.HandleFunc("/api/articles/tags/bulk", func(w http.ResponseWriter, r *http.Request) { article.HandleBulkArticleTags(h, w, r) })
+	mux.HandleFunc("/api/articles/by-tag", func(w http.ResponseWriter, r *http.Request) { article.HandleTagArticles(h, w, r) })
+
+	// Highlights
+	mux.HandleFunc("/api/highlights", func(w http.ResponseWriter, r *http.Request) { highlights.HandleHighlights(h, w, r) })
+	mux.HandleFunc("/api/highlights/update", func(w http.ResponseWriter, r *http.Request) { highlights.HandleHighlightUpdate(h, w, r) })
+	mux.HandleFunc("/api/highlights/delete", func(w http.ResponseWriter, r *http.Request) { highlights.HandleHighlightDelete(h, w, r) })
+	mux.HandleFunc("/api/highlights/search", func(w http.ResponseWriter, r *http.Request) { highlights.HandleSearchHighlights(h, w, r) })
 
 	// Article content
 	mux.HandleFunc("/api/articles/content", func(w http.ResponseWriter, r *http.Request) { article.HandleGetArticleContent(h, w, r) })
@@ -40,15 +56,22 @@ func registerArticleRoutes(mux *http.ServeMux, h *core.Handler) {
 	// Translation
 	mux.HandleFunc("/api/articles/translate", func(w http.ResponseWriter, r *http.Request) { translationhandlers.HandleTranslateArticle(h, w, r) })
 	mux.HandleFunc("/api/articles/translate-text", func(w http.ResponseWriter, r *http.Request) { translationhandlers.HandleTranslateText(h, w, r) })
+	mux.HandleFunc("/api/articles/translate-content", func(w http.ResponseWriter, r *http.Request) {
+		translationhandlers.HandleTranslateArticleContent(h, w, r)
+	})
 	mux.HandleFunc("/api/articles/clear-translations", func(w http.ResponseWriter, r *http.Request) { translationhandlers.HandleClearTranslations(h, w, r) })
 
 	// AI usage (translation related)
 	mux.HandleFunc("/api/ai-usage", func(w http.ResponseWriter, r *http.Request) { translationhandlers.HandleGetAIUsage(h, w, r) })
 	mux.HandleFunc("/api/ai-usage/reset", func(w http.ResponseWriter, r *http.Request) { translationhandlers.HandleResetAIUsage(h, w, r) })
 	mux.HandleFunc("/api/translation/test-custom", func(w http.ResponseWriter, r *http.Request) { translationhandlers.HandleTestCustomTranslation(h, w, r) })
+	mux.HandleFunc("/api/translation/providers/status", func(w http.ResponseWriter, r *http.Request) {
+		translationhandlers.HandleTranslationProviderStatus(h, w, r)
+	})
 
 	// Summary
 	mux.HandleFunc("/api/articles/summarize", func(w http.ResponseWriter, r *http.Request) { summary.HandleSummarizeArticle(h, w, r) })
+	mux.HandleFunc("/api/articles/summarize/stream", func(w http.ResponseWriter, r *http.Request) { summary.HandleSummarizeArticleStream(h, w, r) })
 	mux.HandleFunc("/api/articles/clear-summaries", func(w http.ResponseWriter, r *http.Request) { summary.HandleClearSummaries(h, w, r) })
 
 	// Export
@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	authhandlers "MrRSS/internal/handlers/auth"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/middleware"
+)
+
+// loginPath is the API route used to obtain a session
+const loginPath = "/api/auth/login"
+
+// publicAPIPaths can be reached without a session. The version endpoint is used by health checks.
+var publicAPIPaths = []string{loginPath, "/api/version"}
+
+// RegisterAuthRoutes registers the login, logout and user management routes.
+// These are only used in server mode, where the API is protected by WrapWithAuth.
+func RegisterAuthRoutes(mux *http.ServeMux, h *core.Handler) {
+	// Throttle login attempts to slow down password guessing
+	loginLimiter := middleware.RateLimiter(middleware.RateLimiterConfig{
+		RequestsPerSecond: 1,
+		BurstSize:         5,
+		CleanupInterval:   10 * time.Minute,
+	})
+	mux.Handle(loginPath, loginLimiter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { authhandlers.HandleLogin(h, w, r) })))
+
+	mux.HandleFunc("/api/auth/logout", func(w http.ResponseWriter, r *http.Request) { authhandlers.HandleLogout(h, w, r) })
+	mux.HandleFunc("/api/auth/me", func(w http.ResponseWriter, r *http.Request) { authhandlers.HandleMe(h, w, r) })
+	mux.HandleFunc("/api/auth/password", func(w http.ResponseWriter, r *http.Request) { authhandlers.HandleChangePassword(h, w, r) })
+	mux.HandleFunc("/api/auth/feed-token", func(w http.ResponseWriter, r *http.Request) { authhandlers.HandleFeedToken(h, w, r) })
+	mux.HandleFunc("/api/auth/users", func(w http.ResponseWriter, r *http.Request) { authhandlers.HandleUsers(h, w, r) })
+	mux.HandleFunc("/api/auth/users/delete", func(w http.ResponseWriter, r *http.Request) { authhandlers.HandleUserDelete(h, w, r) })
+}
+
+// WrapWithAuth protects every route of handler except publicAPIPaths with session authentication.
+// Requests must carry a bearer token or the session cookie issued by /api/auth/login.
+func WrapWithAuth(handler http.Handler, h *core.Handler) http.Handler {
+	return middleware.Apply(handler, middleware.Auth(middleware.AuthConfig{
+		Authenticate: authhandlers.Authenticate(h),
+		CookieName:   authhandlers.SessionCookieName,
+		PublicPaths:  publicAPIPaths,
+	}))
+}
@@ -8,6 +8,7 @@ import (
 	discovery "MrRSS/internal/handlers/discovery"
 	feedhandlers "MrRSS/internal/handlers/feed"
 	filter_category "MrRSS/internal/handlers/filter_category"
+	"MrRSS/internal/handlers/outputfeeds"
 	rsshubHandler "MrRSS/internal/handlers/rsshub"
 	taghandlers "MrRSS/internal/handlers/tags"
 )
@@ -20,7 +21,18 @@ func registerFeedRoutes(mux *http.ServeMux, h *core.Handler) {
 	mux.HandleFunc("/api/feeds/update", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleUpdateFeed(h, w, r) })
 	mux.HandleFunc("/api/feeds/refresh", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleRefreshFeed(h, w, r) })
 	mux.HandleFunc("/api/feeds/reorder", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleReorderFeed(h, w, r) })
+	mux.HandleFunc("/api/feeds/json/preview", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandlePreviewJSONFeed(h, w, r) })
+	mux.HandleFunc("/api/feeds/json/options", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleGetJSONFeedOptions(h, w, r) })
+	mux.HandleFunc("/api/feeds/script/settings", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleGetScriptFeedSettings(h, w, r) })
+	mux.HandleFunc("/api/feeds/script/runs", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleGetScriptRuns(h, w, r) })
+	mux.HandleFunc("/api/feeds/transform", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleItemTransform(h, w, r) })
+	mux.HandleFunc("/api/feeds/transform/test", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleTestItemTransform(h, w, r) })
+	mux.HandleFunc("/api/feeds/http-options", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeedHTTPOptions(h, w, r) })
+	mux.HandleFunc("/api/feeds/health", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeedHealth(h, w, r) })
+	mux.HandleFunc("/api/feeds/health/log", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeedFetchLog(h, w, r) })
+	mux.HandleFunc("/api/feeds/url-history", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeedURLHistory(h, w, r) })
 	mux.HandleFunc("/api/feeds/test-imap", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleTestIMAPConnection(h, w, r) })
+	mux.HandleFunc("/api/feeds/export-jsonfeed", func(w http.ResponseWriter, r *http.Request) { outputfeeds.HandleJSONFeedExport(h, w, r) })
 
 	// Discovery routes
 	mux.HandleFunc("/api/feeds/discover", func(w http.ResponseWriter, r *http.Request) { discovery.HandleDiscoverBlogs(h, w, r) })
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"MrRSS/internal/handlers/article"
+	backup "MrRSS/internal/handlers/backup"
 	browser "MrRSS/internal/handlers/browser"
 	"MrRSS/internal/handlers/core"
 	customcss "MrRSS/internal/handlers/custom_css"
@@ -9,9 +10,11 @@ import (
 	media "MrRSS/internal/handlers/media"
 	networkhandlers "MrRSS/internal/handlers/network"
 	opml "MrRSS/internal/handlers/opml"
+	podcast "MrRSS/internal/handlers/podcast"
 	rules "MrRSS/internal/handlers/rules"
 	script "MrRSS/internal/handlers/script"
 	update "MrRSS/internal/handlers/update"
+	webhooks "MrRSS/internal/handlers/webhooks"
 	window "MrRSS/internal/handlers/window"
 	"net/http"
 )
@@ -29,6 +32,10 @@ func registerOtherRoutes(mux *http.ServeMux, h *core.Handler) {
 	mux.HandleFunc("/api/opml/import-dialog", func(w http.ResponseWriter, r *http.Request) { opml.HandleOPMLImportDialog(h, w, r) })
 	mux.HandleFunc("/api/opml/export-dialog", func(w http.ResponseWriter, r *http.Request) { opml.HandleOPMLExportDialog(h, w, r) })
 
+	// Backup
+	mux.HandleFunc("/api/backup/export", func(w http.ResponseWriter, r *http.Request) { backup.HandleBackupExport(h, w, r) })
+	mux.HandleFunc("/api/backup/import", func(w http.ResponseWriter, r *http.Request) { backup.HandleBackupImport(h, w, r) })
+
 	// Update
 	mux.HandleFunc("/api/check-updates", func(w http.ResponseWriter, r *http.Request) { update.HandleCheckUpdates(h, w, r) })
 	mux.HandleFunc("/api/download-update", func(w http.ResponseWriter, r *http.Request) { update.HandleDownloadUpdate(h, w, r) })
@@ -38,6 +45,23 @@ func registerOtherRoutes(mux *http.ServeMux, h *core.Handler) {
 	// Rules
 	mux.HandleFunc("/api/rules/apply", func(w http.ResponseWriter, r *http.Request) { rules.HandleApplyRule(h, w, r) })
 
+	// Webhooks
+	mux.HandleFunc("/api/webhooks", func(w http.ResponseWriter, r *http.Request) { webhooks.HandleWebhooks(h, w, r) })
+	mux.HandleFunc("/api/webhooks/update", func(w http.ResponseWriter, r *http.Request) { webhooks.HandleWebhookUpdate(h, w, r) })
+	mux.HandleFunc("/api/webhooks/delete", func(w http.ResponseWriter, r *http.Request) { webhooks.HandleWebhookDelete(h, w, r) })
+	mux.HandleFunc("/api/webhooks/test", func(w http.ResponseWriter, r *http.Request) { webhooks.HandleWebhookTest(h, w, r) })
+	mux.HandleFunc("/api/webhooks/deliveries", func(w http.ResponseWriter, r *http.Request) { webhooks.HandleWebhookDeliveries(h, w, r) })
+	mux.HandleFunc("/api/webhooks/deliveries/retry", func(w http.ResponseWriter, r *http.Request) { webhooks.HandleWebhookDeliveryRetry(h, w, r) })
+
+	// Podcasts
+	mux.HandleFunc("/api/podcasts/episode", func(w http.ResponseWriter, r *http.Request) { podcast.HandlePodcastEpisode(h, w, r) })
+	mux.HandleFunc("/api/podcasts/progress", func(w http.ResponseWriter, r *http.Request) { podcast.HandlePodcastProgress(h, w, r) })
+	mux.HandleFunc("/api/podcasts/download", func(w http.ResponseWriter, r *http.Request) { podcast.HandlePodcastDownload(h, w, r) })
+	mux.HandleFunc("/api/podcasts/download/delete", func(w http.ResponseWriter, r *http.Request) { podcast.HandlePodcastDownloadDelete(h, w, r) })
+	mux.HandleFunc("/api/podcasts/downloads", func(w http.ResponseWriter, r *http.Request) { podcast.HandlePodcastDownloads(h, w, r) })
+	mux.HandleFunc("/api/podcasts/file", func(w http.ResponseWriter, r *http.Request) { podcast.HandlePodcastFile(h, w, r) })
+	mux.HandleFunc("/api/podcasts/feed-settings", func(w http.ResponseWriter, r *http.Request) { podcast.HandlePodcastFeedSettings(h, w, r) })
+
 	// Scripts
 	mux.HandleFunc("/api/scripts/dir", func(w http.ResponseWriter, r *http.Request) { script.HandleGetScriptsDir(h, w, r) })
 	mux.HandleFunc("/api/scripts/open", func(w http.ResponseWriter, r *http.Request) { script.HandleOpenScriptsDir(h, w, r) })
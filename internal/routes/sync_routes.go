@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"MrRSS/internal/handlers/core"
+	feverhandlers "MrRSS/internal/handlers/fever"
+	"MrRSS/internal/handlers/greader"
+	"MrRSS/internal/handlers/outputfeeds"
+	"MrRSS/internal/middleware"
+)
+
+// SyncPathPrefixes are the URL prefixes served by RegisterSyncRoutes
+var SyncPathPrefixes = []string{"/accounts/", "/reader/api/0/", "/fever/", "/feeds/"}
+
+// RegisterSyncRoutes registers the Google Reader and Fever compatible APIs and the
+// output feeds used by third-party clients in server mode. These routes authenticate
+// requests themselves and must not be wrapped with WrapWithAuth.
+func RegisterSyncRoutes(mux *http.ServeMux, h *core.Handler) {
+	// Throttle login attempts to slow down password guessing
+	loginLimiter := middleware.RateLimiter(middleware.RateLimiterConfig{
+		RequestsPerSecond: 1,
+		BurstSize:         5,
+		CleanupInterval:   10 * time.Minute,
+	})
+	mux.Handle("/accounts/ClientLogin", loginLimiter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { greader.HandleClientLogin(h, w, r) })))
+
+	requireAuth := greader.RequireAuth(h)
+	handle := func(pattern string, handler func(*core.Handler, http.ResponseWriter, *http.Request)) {
+		mux.Handle(pattern, requireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { handler(h, w, r) })))
+	}
+	handle("/reader/api/0/token", greader.HandleToken)
+	handle("/reader/api/0/user-info", greader.HandleUserInfo)
+	handle("/reader/api/0/subscription/list", greader.HandleSubscriptionList)
+	handle("/reader/api/0/tag/list", greader.HandleTagList)
+	handle("/reader/api/0/unread-count", greader.HandleUnreadCount)
+	handle("/reader/api/0/stream/contents/", greader.HandleStreamContents)
+	handle("/reader/api/0/stream/items/ids", greader.HandleStreamItemIDs)
+	handle("/reader/api/0/stream/items/contents", greader.HandleStreamItemContents)
+	handle("/reader/api/0/edit-tag", greader.HandleEditTag)
+	handle("/reader/api/0/mark-all-as-read", greader.HandleMarkAllAsRead)
+
+	mux.HandleFunc("/fever/", func(w http.ResponseWriter, r *http.Request) { feverhandlers.HandleFever(h, w, r) })
+
+	// Output feeds, authenticated with the token query parameter
+	mux.HandleFunc("/feeds/saved-filter/{file}", func(w http.ResponseWriter, r *http.Request) { outputfeeds.HandleSavedFilterFeed(h, w, r) })
+	mux.HandleFunc("/feeds/tag/{file}", func(w http.ResponseWriter, r *http.Request) { outputfeeds.HandleTagFeed(h, w, r) })
+	mux.HandleFunc("/feeds/{file}", func(w http.ResponseWriter, r *http.Request) { outputfeeds.HandleFavoritesFeed(h, w, r) })
+}
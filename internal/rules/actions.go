@@ -0,0 +1,235 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// Action types
+const (
+	ActionFavorite        = "favorite"
+	ActionUnfavorite      = "unfavorite"
+	ActionHide            = "hide"
+	ActionUnhide          = "unhide"
+	ActionMarkRead        = "mark_read"
+	ActionMarkUnread      = "mark_unread"
+	ActionReadLater       = "read_later"
+	ActionRemoveReadLater = "remove_read_later"
+	ActionAddTag          = "add_tag"         // params: tag_id
+	ActionMove            = "move"            // params: category, the feed of the article is moved
+	ActionSummarize       = "summarize"       // params: profile_id (optional), length (optional)
+	ActionTranslateTitle  = "translate_title" // params: target_language (optional, defaults to the setting)
+	ActionFetchContent    = "fetch_content"
+	ActionExport          = "export"  // params: target ("obsidian" or "notion")
+	ActionWebhook         = "webhook" // params: url, method (optional, defaults to POST)
+)
+
+// Action is a single rule action with optional parameters
+type Action struct {
+	Type   string            `json:"type"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// UnmarshalJSON accepts both the object form and the legacy plain string form ("favorite")
+func (a *Action) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*a = Action{Type: name}
+		return nil
+	}
+
+	type action Action
+	var v action
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*a = Action(v)
+	return nil
+}
+
+// Param returns the value of a parameter, or "" if it is not set
+func (a Action) Param(key string) string {
+	return a.Params[key]
+}
+
+// Validate checks that the action type is known and that required parameters are present
+func (a Action) Validate() error {
+	switch a.Type {
+	case ActionFavorite, ActionUnfavorite, ActionHide, ActionUnhide, ActionMarkRead, ActionMarkUnread,
+		ActionReadLater, ActionRemoveReadLater, ActionTranslateTitle, ActionFetchContent:
+		return nil
+	case ActionAddTag:
+		if _, err := strconv.ParseInt(a.Param("tag_id"), 10, 64); err != nil {
+			return fmt.Errorf("action %s: invalid tag_id %q", a.Type, a.Param("tag_id"))
+		}
+	case ActionMove:
+		if strings.TrimSpace(a.Param("category")) == "" {
+			return fmt.Errorf("action %s: missing category", a.Type)
+		}
+	case ActionSummarize:
+		if id := a.Param("profile_id"); id != "" {
+			if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+				return fmt.Errorf("action %s: invalid profile_id %q", a.Type, id)
+			}
+		}
+		switch a.Param("length") {
+		case "", "short", "medium", "long":
+		default:
+			return fmt.Errorf("action %s: invalid length %q", a.Type, a.Param("length"))
+		}
+	case ActionExport:
+		if t := a.Param("target"); t != "obsidian" && t != "notion" {
+			return fmt.Errorf("action %s: invalid target %q", a.Type, t)
+		}
+	case ActionWebhook:
+		u, err := url.Parse(a.Param("url"))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("action %s: invalid url %q", a.Type, a.Param("url"))
+		}
+		switch a.Param("method") {
+		case "", http.MethodPost, http.MethodPut:
+		default:
+			return fmt.Errorf("action %s: unsupported method %q", a.Type, a.Param("method"))
+		}
+	default:
+		return fmt.Errorf("unknown action %q", a.Type)
+	}
+	return nil
+}
+
+// isDeferred reports whether the action calls an external service and therefore runs in the background
+func (a Action) isDeferred() bool {
+	switch a.Type {
+	case ActionSummarize, ActionTranslateTitle, ActionFetchContent, ActionExport, ActionWebhook:
+		return true
+	}
+	return false
+}
+
+// Services performs the actions that depend on AI, translation, full-text fetching and
+// export integrations. It is implemented by the handler layer.
+type Services interface {
+	Summarize(articleID, profileID int64, length string) error
+	TranslateTitle(articleID int64, targetLang string) error
+	FetchFullContent(articleID int64) error
+	Export(articleID int64, target string) error
+}
+
+// deferredAction is an action queued to run after the synchronous actions of a rule
+type deferredAction struct {
+	ruleName string
+	article  models.Article
+	action   Action
+}
+
+// webhookClient is used to deliver webhook actions
+var webhookClient = &http.Client{Timeout: 15 * time.Second}
+
+// webhookPayload is the JSON body sent by the webhook action
+type webhookPayload struct {
+	Event   string         `json:"event"`
+	Rule    string         `json:"rule"`
+	Article webhookArticle `json:"article"`
+}
+
+type webhookArticle struct {
+	ID          int64     `json:"id"`
+	FeedID      int64     `json:"feed_id"`
+	FeedTitle   string    `json:"feed_title"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Author      string    `json:"author,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// runDeferredActions runs queued actions in order. Failures are logged and do not stop later actions.
+func (e *Engine) runDeferredActions(actions []deferredAction) {
+	for _, d := range actions {
+		if err := e.runDeferredAction(d); err != nil {
+			log.Printf("Error applying action %s to article %d: %v", d.action.Type, d.article.ID, err)
+		}
+	}
+}
+
+// runDeferredAction runs a single queued action
+func (e *Engine) runDeferredAction(d deferredAction) error {
+	if d.action.Type == ActionWebhook {
+		return sendWebhook(d)
+	}
+
+	if e.services == nil {
+		log.Printf("Skipping action %s for article %d: no services configured", d.action.Type, d.article.ID)
+		return nil
+	}
+
+	switch d.action.Type {
+	case ActionSummarize:
+		profileID, _ := strconv.ParseInt(d.action.Param("profile_id"), 10, 64)
+		return e.services.Summarize(d.article.ID, profileID, d.action.Param("length"))
+	case ActionTranslateTitle:
+		targetLang := d.action.Param("target_language")
+		if targetLang == "" {
+			targetLang, _ = e.db.GetSetting("target_language")
+		}
+		if targetLang == "" {
+			return fmt.Errorf("no target language configured")
+		}
+		return e.services.TranslateTitle(d.article.ID, targetLang)
+	case ActionFetchContent:
+		return e.services.FetchFullContent(d.article.ID)
+	case ActionExport:
+		return e.services.Export(d.article.ID, d.action.Param("target"))
+	}
+	return nil
+}
+
+// sendWebhook sends the article as JSON to the action's URL
+func sendWebhook(d deferredAction) error {
+	body, err := json.Marshal(webhookPayload{
+		Event: "rule.matched",
+		Rule:  d.ruleName,
+		Article: webhookArticle{
+			ID:          d.article.ID,
+			FeedID:      d.article.FeedID,
+			FeedTitle:   d.article.FeedTitle,
+			Title:       d.article.Title,
+			URL:         d.article.URL,
+			Author:      d.article.Author,
+			PublishedAt: d.article.PublishedAt,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	method := d.action.Param("method")
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequest(method, d.action.Param("url"), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MrRSS")
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
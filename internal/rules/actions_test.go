@@ -0,0 +1,182 @@
+package rules
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"MrRSS/internal/models"
+)
+
+type fakeServices struct {
+	calls []string
+}
+
+func (f *fakeServices) Summarize(articleID, profileID int64, length string) error {
+	f.calls = append(f.calls, "summarize:"+length)
+	return nil
+}
+
+func (f *fakeServices) TranslateTitle(articleID int64, targetLang string) error {
+	f.calls = append(f.calls, "translate_title:"+targetLang)
+	return nil
+}
+
+func (f *fakeServices) FetchFullContent(articleID int64) error {
+	f.calls = append(f.calls, "fetch_content")
+	return nil
+}
+
+func (f *fakeServices) Export(articleID int64, target string) error {
+	f.calls = append(f.calls, "export:"+target)
+	return nil
+}
+
+func TestAction_UnmarshalLegacyString(t *testing.T) {
+	var rule Rule
+	data := `{"name":"r","actions":["favorite",{"type":"add_tag","params":{"tag_id":"3"}}]}`
+	if err := json.Unmarshal([]byte(data), &rule); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if len(rule.Actions) != 2 {
+		t.Fatalf("Expected 2 actions, got %d", len(rule.Actions))
+	}
+	if rule.Actions[0].Type != "favorite" || rule.Actions[0].Params != nil {
+		t.Errorf("Unexpected legacy action: %+v", rule.Actions[0])
+	}
+	if rule.Actions[1].Type != "add_tag" || rule.Actions[1].Param("tag_id") != "3" {
+		t.Errorf("Unexpected object action: %+v", rule.Actions[1])
+	}
+}
+
+func TestAction_Validate(t *testing.T) {
+	tests := []struct {
+		action  Action
+		wantErr bool
+	}{
+		{Action{Type: "favorite"}, false},
+		{Action{Type: "add_tag", Params: map[string]string{"tag_id": "1"}}, false},
+		{Action{Type: "add_tag"}, true},
+		{Action{Type: "move", Params: map[string]string{"category": "Tech/AI"}}, false},
+		{Action{Type: "move", Params: map[string]string{"category": " "}}, true},
+		{Action{Type: "summarize", Params: map[string]string{"length": "short", "profile_id": "2"}}, false},
+		{Action{Type: "summarize", Params: map[string]string{"length": "huge"}}, true},
+		{Action{Type: "export", Params: map[string]string{"target": "notion"}}, false},
+		{Action{Type: "export", Params: map[string]string{"target": "evernote"}}, true},
+		{Action{Type: "webhook", Params: map[string]string{"url": "https://example.com/hook"}}, false},
+		{Action{Type: "webhook", Params: map[string]string{"url": "file:///etc/passwd"}}, true},
+		{Action{Type: "explode"}, true},
+	}
+
+	for _, tt := range tests {
+		err := tt.action.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.action, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEngine_AddTagAction(t *testing.T) {
+	engine := setupTestEngine(t)
+
+	tagID, err := engine.db.AddTag(&models.Tag{Name: "Later", Color: "#000000"})
+	if err != nil {
+		t.Fatalf("AddTag failed: %v", err)
+	}
+
+	action := Action{Type: ActionAddTag, Params: map[string]string{"tag_id": strconv.FormatInt(tagID, 10)}}
+	for i := 0; i < 2; i++ {
+		if err := engine.applyAction(42, action); err != nil {
+			t.Fatalf("applyAction failed: %v", err)
+		}
+	}
+
+	var count int
+	if err := engine.db.QueryRow(`SELECT COUNT(*) FROM article_tags WHERE article_id = 42 AND tag_id = ?`, tagID).Scan(&count); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 article tag, got %d", count)
+	}
+}
+
+func TestEngine_MoveAction(t *testing.T) {
+	engine := setupTestEngine(t)
+
+	feedID, err := engine.db.AddFeed(&models.Feed{Title: "Feed", URL: "https://example.com/feed", Category: "News"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	if err := engine.db.SaveArticle(&models.Article{FeedID: feedID, Title: "AI news", URL: "https://example.com/1"}); err != nil {
+		t.Fatalf("SaveArticle failed: %v", err)
+	}
+	var articleID int64
+	if err := engine.db.QueryRow(`SELECT id FROM articles WHERE feed_id = ?`, feedID).Scan(&articleID); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	action := Action{Type: ActionMove, Params: map[string]string{"category": "Tech/AI"}}
+	if err := engine.applyAction(articleID, action); err != nil {
+		t.Fatalf("applyAction failed: %v", err)
+	}
+
+	feed, err := engine.db.GetFeedByID(feedID)
+	if err != nil {
+		t.Fatalf("GetFeedByID failed: %v", err)
+	}
+	if feed.Category != "Tech/AI" {
+		t.Errorf("Expected the feed to be moved to Tech/AI, got %q", feed.Category)
+	}
+}
+
+func TestEngine_DeferredActions(t *testing.T) {
+	engine := setupTestEngine(t)
+	services := &fakeServices{}
+	engine.SetServices(services)
+	engine.db.SetSetting("target_language", "fr")
+
+	var received webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	rule := Rule{
+		Name: "Pipeline",
+		Actions: []Action{
+			{Type: ActionFetchContent},
+			{Type: ActionMarkRead},
+			{Type: ActionSummarize, Params: map[string]string{"length": "short"}},
+			{Type: ActionTranslateTitle},
+			{Type: ActionExport, Params: map[string]string{"target": "obsidian"}},
+			{Type: ActionWebhook, Params: map[string]string{"url": server.URL}},
+		},
+	}
+	article := models.Article{ID: 7, Title: "Hello"}
+
+	deferred := engine.applyActions(rule, article, nil)
+	if len(deferred) != 5 {
+		t.Fatalf("Expected 5 deferred actions, got %d", len(deferred))
+	}
+	engine.runDeferredActions(deferred)
+
+	want := []string{"fetch_content", "summarize:short", "translate_title:fr", "export:obsidian"}
+	if len(services.calls) != len(want) {
+		t.Fatalf("Expected calls %v, got %v", want, services.calls)
+	}
+	for i := range want {
+		if services.calls[i] != want[i] {
+			t.Errorf("Call %d = %s, want %s", i, services.calls[i], want[i])
+		}
+	}
+
+	if received.Rule != "Pipeline" || received.Article.ID != 7 || received.Article.Title != "Hello" {
+		t.Errorf("Unexpected webhook payload: %+v", received)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"log"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,10 +13,11 @@ import (
 	"MrRSS/internal/freshrss"
 	"MrRSS/internal/models"
 	"MrRSS/internal/rsshub"
+	"MrRSS/internal/utils/urlutil"
 )
 
 // getFeedType returns the type code of a feed
-// Possible values: "regular", "freshrss", "rsshub", "script", "xpath", "email"
+// Possible values: "regular", "freshrss", "rsshub", "script", "xpath", "email", "youtube", "mastodon", "json"
 func getFeedType(feed *models.Feed) string {
 	// Check FreshRSS
 	if feed.IsFreshRSSSource {
@@ -42,6 +44,21 @@ func getFeedType(feed *models.Feed) string {
 		return "xpath"
 	}
 
+	// Check YouTube
+	if feed.Type == "youtube" {
+		return "youtube"
+	}
+
+	// Check Mastodon
+	if feed.Type == "mastodon" {
+		return "mastodon"
+	}
+
+	// Check JSON API
+	if feed.Type == "json" {
+		return "json"
+	}
+
 	// Default: regular RSS/Atom feed
 	return "regular"
 }
@@ -63,13 +80,14 @@ type Rule struct {
 	Name       string      `json:"name"`
 	Enabled    bool        `json:"enabled"`
 	Conditions []Condition `json:"conditions"`
-	Actions    []string    `json:"actions"`  // "favorite", "unfavorite", "hide", "unhide", "mark_read", "mark_unread"
+	Actions    []Action    `json:"actions"`
 	Position   int         `json:"position"` // Execution order (0 = first)
 }
 
 // Engine handles rule application
 type Engine struct {
-	db *database.DB
+	db       *database.DB
+	services Services
 }
 
 // NewEngine creates a new rules engine
@@ -77,6 +95,12 @@ func NewEngine(db *database.DB) *Engine {
 	return &Engine{db: db}
 }
 
+// SetServices sets the services used by AI, translation, full-text and export actions.
+// Without services these actions are skipped.
+func (e *Engine) SetServices(services Services) {
+	e.services = services
+}
+
 // ApplyRulesToArticles applies all enabled rules to a batch of articles.
 // Each article is matched against rules in order, and only the first matching rule is applied.
 // This prevents conflicting actions from multiple rules being applied to the same article.
@@ -127,7 +151,10 @@ func (e *Engine) ApplyRulesToArticles(articles []models.Article) (int, error) {
 		feedTags[feed.ID] = tagNames
 	}
 
+	articleTags := e.loadArticleTags(articles, rules...)
+
 	affected := 0
+	var deferred []deferredAction
 	for _, article := range articles {
 		for _, rule := range rules {
 			if !rule.Enabled {
@@ -135,20 +162,20 @@ func (e *Engine) ApplyRulesToArticles(articles []models.Article) (int, error) {
 			}
 
 			// Check if article matches conditions
-			if matchesConditions(article, rule.Conditions, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, feedTags) {
+			if matchesConditions(article, rule.Conditions, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, feedTags, articleTags) {
 				// Apply actions
-				for _, action := range rule.Actions {
-					if err := e.applyAction(article.ID, action); err != nil {
-						log.Printf("Error applying action %s to article %d: %v", action, article.ID, err)
-						continue
-					}
-				}
+				deferred = e.applyActions(rule, article, deferred)
 				affected++
 				break // Only apply first matching rule per article to prevent conflicts
 			}
 		}
 	}
 
+	// Actions calling external services can be slow, so they run in the background
+	if len(deferred) > 0 {
+		go e.runDeferredActions(deferred)
+	}
+
 	return affected, nil
 }
 
@@ -192,34 +219,70 @@ func (e *Engine) ApplyRule(rule Rule) (int, error) {
 		feedTags[feed.ID] = tagNames
 	}
 
+	articleTags := e.loadArticleTags(articles, rule)
+
 	affected := 0
+	var deferred []deferredAction
 	for _, article := range articles {
-		if matchesConditions(article, rule.Conditions, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, feedTags) {
-			for _, action := range rule.Actions {
-				if err := e.applyAction(article.ID, action); err != nil {
-					log.Printf("Error applying action %s to article %d: %v", action, article.ID, err)
-					continue
-				}
-			}
+		if matchesConditions(article, rule.Conditions, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, feedTags, articleTags) {
+			deferred = e.applyActions(rule, article, deferred)
 			affected++
 		}
 	}
 
+	if len(deferred) > 0 {
+		go e.runDeferredActions(deferred)
+	}
+
 	return affected, nil
 }
 
+// loadArticleTags returns the tag names of the articles, keyed by article ID.
+// Tags are only loaded when one of the rules has an article_tags condition.
+func (e *Engine) loadArticleTags(articles []models.Article, rules ...Rule) map[int64][]string {
+	articleTags := make(map[int64][]string)
+
+	needed := false
+	for _, rule := range rules {
+		for _, condition := range rule.Conditions {
+			if condition.Field == "article_tags" {
+				needed = true
+			}
+		}
+	}
+	if !needed || len(articles) == 0 {
+		return articleTags
+	}
+
+	ids := make([]int64, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
+	}
+	tagsByArticle, err := e.db.GetTagsForArticles(ids)
+	if err != nil {
+		log.Printf("Error loading article tags: %v", err)
+		return articleTags
+	}
+	for articleID, tags := range tagsByArticle {
+		for _, tag := range tags {
+			articleTags[articleID] = append(articleTags[articleID], tag.Name)
+		}
+	}
+	return articleTags
+}
+
 // matchesConditions checks if an article matches the rule conditions
-func matchesConditions(article models.Article, conditions []Condition, feedCategories map[int64]string, feedTitles map[int64]string, feedTypes map[int64]string, feedIsImageMode map[int64]bool, feedIsFreshRSS map[int64]bool, feedTags map[int64][]string) bool {
+func matchesConditions(article models.Article, conditions []Condition, feedCategories map[int64]string, feedTitles map[int64]string, feedTypes map[int64]string, feedIsImageMode map[int64]bool, feedIsFreshRSS map[int64]bool, feedTags map[int64][]string, articleTags map[int64][]string) bool {
 	// If no conditions, apply to all articles
 	if len(conditions) == 0 {
 		return true
 	}
 
-	result := evaluateCondition(article, conditions[0], feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, feedTags)
+	result := evaluateCondition(article, conditions[0], feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, feedTags, articleTags)
 
 	for i := 1; i < len(conditions); i++ {
 		condition := conditions[i]
-		conditionResult := evaluateCondition(article, condition, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, feedTags)
+		conditionResult := evaluateCondition(article, condition, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, feedTags, articleTags)
 
 		switch condition.Logic {
 		case "and":
@@ -233,7 +296,7 @@ func matchesConditions(article models.Article, conditions []Condition, feedCateg
 }
 
 // evaluateCondition evaluates a single rule condition
-func evaluateCondition(article models.Article, condition Condition, feedCategories map[int64]string, feedTitles map[int64]string, feedTypes map[int64]string, feedIsImageMode map[int64]bool, feedIsFreshRSS map[int64]bool, feedTags map[int64][]string) bool {
+func evaluateCondition(article models.Article, condition Condition, feedCategories map[int64]string, feedTitles map[int64]string, feedTypes map[int64]string, feedIsImageMode map[int64]bool, feedIsFreshRSS map[int64]bool, feedTags map[int64][]string, articleTags map[int64][]string) bool {
 	var result bool
 
 	switch condition.Field {
@@ -275,9 +338,12 @@ func evaluateCondition(article models.Article, condition Condition, feedCategori
 		result = matchMultiSelect(feedType, condition.Values, condition.Value)
 
 	case "feed_tags":
-		articleTags := feedTags[article.FeedID]
 		// Check if any tag matches
-		result = matchMultiSelectTags(articleTags, condition.Values, condition.Value)
+		result = matchMultiSelectTags(feedTags[article.FeedID], condition.Values, condition.Value)
+
+	case "article_tags":
+		// Check if any of the article's own tags matches
+		result = matchMultiSelectTags(articleTags[article.ID], condition.Values, condition.Value)
 
 	case "is_freshrss_feed":
 		if condition.Value == "" {
@@ -353,6 +419,14 @@ func evaluateCondition(article models.Article, condition Condition, feedCategori
 			result = article.IsReadLater == wantReadLater
 		}
 
+	case "is_youtube_short":
+		if condition.Value == "" {
+			result = true
+		} else {
+			wantShort := condition.Value == "true"
+			result = urlutil.IsYouTubeShortURL(article.URL) == wantShort
+		}
+
 	default:
 		result = true
 	}
@@ -407,13 +481,28 @@ func matchMultiSelectTags(articleTags []string, values []string, singleValue str
 	return true
 }
 
+// applyActions applies the immediate actions of a rule to an article and appends
+// the actions that call external services to deferred, keeping their order
+func (e *Engine) applyActions(rule Rule, article models.Article, deferred []deferredAction) []deferredAction {
+	for _, action := range rule.Actions {
+		if action.isDeferred() {
+			deferred = append(deferred, deferredAction{ruleName: rule.Name, article: article, action: action})
+			continue
+		}
+		if err := e.applyAction(article.ID, action); err != nil {
+			log.Printf("Error applying action %s to article %d: %v", action.Type, article.ID, err)
+		}
+	}
+	return deferred
+}
+
 // applyAction applies an action to an article with FreshRSS sync if enabled
-func (e *Engine) applyAction(articleID int64, action string) error {
+func (e *Engine) applyAction(articleID int64, action Action) error {
 	var syncReq *database.SyncRequest
 	var err error
 
 	// Apply the action and get sync request if applicable
-	switch action {
+	switch action.Type {
 	case "favorite":
 		syncReq, err = e.db.SetArticleFavoriteWithSync(articleID, true)
 	case "unfavorite":
@@ -430,8 +519,21 @@ func (e *Engine) applyAction(articleID int64, action string) error {
 		err = e.db.SetArticleReadLater(articleID, true)
 	case "remove_read_later":
 		err = e.db.SetArticleReadLater(articleID, false)
+	case ActionAddTag:
+		var tagID int64
+		tagID, err = strconv.ParseInt(action.Param("tag_id"), 10, 64)
+		if err == nil {
+			err = e.db.AddArticleTag(articleID, tagID)
+		}
+	case ActionMove:
+		// Categories belong to feeds, so the feed of the article is moved
+		var article *models.Article
+		article, err = e.db.GetArticleByID(articleID)
+		if err == nil {
+			err = e.db.UpdateFeedCategory(article.FeedID, strings.TrimSpace(action.Param("category")))
+		}
 	default:
-		log.Printf("Unknown action: %s", action)
+		log.Printf("Unknown action: %s", action.Type)
 		return nil
 	}
 
@@ -42,7 +42,7 @@ func TestEngine_ApplyRulesToArticles(t *testing.T) {
 				Value:    "test",
 			},
 		},
-		Actions: []string{"favorite", "mark_read"},
+		Actions: []Action{{Type: "favorite"}, {Type: "mark_read"}},
 	}
 
 	rules := []Rule{rule}
@@ -86,7 +86,7 @@ func TestEngine_ApplyRule(t *testing.T) {
 				Value:    "test",
 			},
 		},
-		Actions: []string{"favorite"},
+		Actions: []Action{{Type: "favorite"}},
 	}
 
 	// Apply rule
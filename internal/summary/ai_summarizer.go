@@ -1,6 +1,7 @@
 package summary
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -170,28 +171,11 @@ func (s *AISummarizer) getUserPrompt(targetWords int, text string) string {
 // Summarize generates a summary of the given text using an OpenAI-compatible API.
 // Automatically detects and adapts to different API formats (Gemini, OpenAI, Ollama).
 func (s *AISummarizer) Summarize(text string, length SummaryLength) (SummaryResult, error) {
-	// Clean the text first
-	cleanedText := cleanText(text)
-
-	// Check if text is too short
-	if len(cleanedText) < MinContentLength {
-		return SummaryResult{
-			Summary:    cleanedText,
-			IsTooShort: true,
-		}, nil
+	systemPrompt, userPrompt, tooShort := s.buildPrompts(text, length)
+	if tooShort != nil {
+		return *tooShort, nil
 	}
 
-	targetWords := getTargetWordCount(length)
-
-	// Use custom system prompt if provided, otherwise use default
-	systemPrompt := s.SystemPrompt
-	if systemPrompt == "" {
-		systemPrompt = s.getDefaultSystemPrompt()
-	}
-
-	// Generate localized user prompt with target language specification
-	userPrompt := s.getUserPrompt(targetWords, cleanedText)
-
 	// Use the universal client which handles format detection automatically
 	result, err := s.client.RequestWithThinking(systemPrompt, userPrompt)
 	if err != nil {
@@ -212,3 +196,58 @@ func (s *AISummarizer) Summarize(text string, length SummaryLength) (SummaryResu
 		IsTooShort:    false,
 	}, nil
 }
+
+// SummarizeStream generates a summary like Summarize, calling onDelta with each piece of
+// the summary or the model's thinking as it is generated. Text too short to summarize is
+// returned without calling onDelta.
+func (s *AISummarizer) SummarizeStream(ctx context.Context, text string, length SummaryLength, onDelta ai.StreamCallback) (SummaryResult, error) {
+	systemPrompt, userPrompt, tooShort := s.buildPrompts(text, length)
+	if tooShort != nil {
+		return *tooShort, nil
+	}
+
+	config := ai.RequestConfig{
+		Model:        s.Model,
+		SystemPrompt: systemPrompt,
+		UserPrompt:   userPrompt,
+		Temperature:  0.3,
+		MaxTokens:    2048,
+	}
+	result, err := s.client.RequestStream(ctx, config, onDelta)
+	if err != nil {
+		return SummaryResult{}, err
+	}
+
+	return SummaryResult{
+		Summary:       result.Content,
+		Thinking:      result.Thinking,
+		SentenceCount: len(splitSentences(result.Content)),
+		IsTooShort:    false,
+	}, nil
+}
+
+// buildPrompts returns the system and user prompts for summarizing text,
+// or the result to return directly when the text is too short.
+func (s *AISummarizer) buildPrompts(text string, length SummaryLength) (string, string, *SummaryResult) {
+	// Clean the text first
+	cleanedText := cleanText(text)
+
+	// Check if text is too short
+	if len(cleanedText) < MinContentLength {
+		return "", "", &SummaryResult{
+			Summary:    cleanedText,
+			IsTooShort: true,
+		}
+	}
+
+	targetWords := getTargetWordCount(length)
+
+	// Use custom system prompt if provided, otherwise use default
+	systemPrompt := s.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = s.getDefaultSystemPrompt()
+	}
+
+	// Generate localized user prompt with target language specification
+	return systemPrompt, s.getUserPrompt(targetWords, cleanedText), nil
+}
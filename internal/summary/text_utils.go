@@ -193,3 +193,9 @@ func isStopWord(word string) bool {
 	}
 	return stopWords[word]
 }
+
+// Tokenize splits text into lowercase words without stopwords.
+// Chinese text is segmented with gse, so it can be compared word by word like other languages.
+func Tokenize(text string) []string {
+	return tokenize(cleanText(text))
+}
@@ -0,0 +1,526 @@
+package translation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// DefaultArticleChunkSize is the maximum number of characters sent in one request
+// when translating an article, which keeps requests within common provider limits.
+const DefaultArticleChunkSize = 4000
+
+// Classes of the translated blocks in the bilingual rendition. They match the markup
+// the article view uses for paragraph translations, so the same styles apply.
+const (
+	TranslationClass           = "translation-text"
+	TranslationInlineClass     = "translation-inline"
+	TranslationBlockquoteClass = "translation-blockquote"
+)
+
+// ArticleBlock is a translatable block of an article with its inner HTML
+type ArticleBlock struct {
+	Original   string `json:"original"`
+	Translated string `json:"translated"`
+}
+
+// ArticleDocument is an article body split into translatable blocks. Code, preformatted
+// text and media are never sent for translation, and inline markup such as links is
+// replaced by placeholders that are restored in the translation.
+type ArticleDocument struct {
+	root     *html.Node
+	blocks   []*articleBlock
+	starts   map[*html.Node]*articleBlock
+	elements map[*html.Node]*articleBlock
+	parents  map[*html.Node]bool
+}
+
+// articleBlock 一个可翻译的块：块元素的全部子节点，或容器中连续的行内节点
+type articleBlock struct {
+	element      *html.Node // 块元素（行内片段时为 nil）
+	first, last  *html.Node // 块内容的第一个和最后一个节点
+	source       string     // 带占位符的待翻译文本
+	markup       map[string]string
+	placeholders int
+	translated   string // 翻译后的 HTML
+}
+
+var (
+	// 叶子块元素，其内容作为一个整体翻译
+	articleBlockElements = map[atom.Atom]bool{
+		atom.P: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+		atom.Li: true, atom.Dt: true, atom.Dd: true, atom.Td: true, atom.Th: true,
+		atom.Figcaption: true, atom.Caption: true, atom.Summary: true, atom.Blockquote: true,
+		atom.Div: true, atom.Section: true, atom.Article: true, atom.Header: true, atom.Footer: true,
+		atom.Aside: true, atom.Main: true, atom.Figure: true, atom.Details: true,
+	}
+	// 只包含其他块的结构元素
+	articleStructureElements = map[atom.Atom]bool{
+		atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Table: true, atom.Thead: true,
+		atom.Tbody: true, atom.Tfoot: true, atom.Tr: true, atom.Hr: true, atom.Nav: true, atom.Form: true,
+	}
+	// 不翻译的元素
+	articleSkipElements = map[atom.Atom]bool{
+		atom.Pre: true, atom.Script: true, atom.Style: true, atom.Svg: true, atom.Math: true,
+		atom.Textarea: true, atom.Noscript: true, atom.Iframe: true, atom.Template: true,
+		atom.Video: true, atom.Audio: true, atom.Object: true, atom.Canvas: true,
+	}
+	// 原样保留为占位符的行内元素
+	articlePreservedElements = map[atom.Atom]bool{
+		atom.Code: true, atom.Kbd: true, atom.Samp: true, atom.Var: true, atom.Img: true, atom.Br: true,
+		atom.Picture: true, atom.Sub: true, atom.Sup: true, atom.Input: true, atom.Wbr: true,
+	}
+	// 翻译嵌入元素内部而不是作为兄弟节点插入
+	articleInlineTranslationElements = map[atom.Atom]bool{
+		atom.Li: true, atom.Td: true, atom.Th: true, atom.Dd: true, atom.Dt: true,
+	}
+
+	placeholderPattern = regexp.MustCompile(`⟦\s*(/?)\s*(\d+)\s*⟧`)
+	chunkSeparator     = "\n\n⟦#⟧\n\n"
+	separatorPattern   = regexp.MustCompile(`\s*⟦\s*#\s*⟧\s*`)
+	whitespacePattern  = regexp.MustCompile(`\s+`)
+)
+
+// ParseArticle splits the HTML of an article body into translatable blocks
+func ParseArticle(content string) (*ArticleDocument, error) {
+	root := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse article: %w", err)
+	}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+
+	d := &ArticleDocument{
+		root:     root,
+		starts:   make(map[*html.Node]*articleBlock),
+		elements: make(map[*html.Node]*articleBlock),
+		parents:  make(map[*html.Node]bool),
+	}
+	d.collect(root)
+	return d, nil
+}
+
+// Len returns the number of translatable blocks
+func (d *ArticleDocument) Len() int {
+	return len(d.blocks)
+}
+
+// Blocks returns the original and translated inner HTML of each block
+func (d *ArticleDocument) Blocks() []ArticleBlock {
+	blocks := make([]ArticleBlock, len(d.blocks))
+	for i, b := range d.blocks {
+		var sb strings.Builder
+		for n := b.first; n != nil; n = n.NextSibling {
+			html.Render(&sb, n)
+			if n == b.last {
+				break
+			}
+		}
+		blocks[i] = ArticleBlock{Original: sb.String(), Translated: b.translated}
+	}
+	return blocks
+}
+
+// Translations returns the translated HTML of each block, empty for blocks not translated yet
+func (d *ArticleDocument) Translations() []string {
+	translations := make([]string, len(d.blocks))
+	for i, b := range d.blocks {
+		translations[i] = b.translated
+	}
+	return translations
+}
+
+// SetTranslations restores previously translated blocks. It is ignored when the
+// number of blocks does not match, as the translations belong to other content.
+func (d *ArticleDocument) SetTranslations(translations []string) {
+	if len(translations) != len(d.blocks) {
+		return
+	}
+	for i, b := range d.blocks {
+		b.translated = translations[i]
+	}
+}
+
+// Complete reports whether every block has been translated
+func (d *ArticleDocument) Complete() bool {
+	for _, b := range d.blocks {
+		if b.translated == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Translate translates the blocks that have no translation yet. Consecutive blocks are
+// batched into requests of up to chunkSize characters. Translation stops at the first
+// failed request; the blocks translated so far are kept.
+func (d *ArticleDocument) Translate(translator Translator, targetLang string, chunkSize int) error {
+	if chunkSize <= 0 {
+		chunkSize = DefaultArticleChunkSize
+	}
+
+	var chunk []*articleBlock
+	size := 0
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		err := translateChunk(translator, chunk, targetLang)
+		chunk, size = nil, 0
+		return err
+	}
+
+	for _, b := range d.blocks {
+		if b.translated != "" {
+			continue
+		}
+		if len(chunk) > 0 && size+len(b.source)+len(chunkSeparator) > chunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		chunk = append(chunk, b)
+		size += len(b.source) + len(chunkSeparator)
+	}
+	return flush()
+}
+
+// TranslatedHTML renders the article with every translated block replaced by its translation
+func (d *ArticleDocument) TranslatedHTML() string {
+	var sb strings.Builder
+	d.render(&sb, d.root, false)
+	return sb.String()
+}
+
+// BilingualHTML renders the article with each translation following its original block
+func (d *ArticleDocument) BilingualHTML() string {
+	var sb strings.Builder
+	d.render(&sb, d.root, true)
+	return sb.String()
+}
+
+// translateChunk 批量翻译多个块；分隔符丢失时逐块翻译
+func translateChunk(translator Translator, chunk []*articleBlock, targetLang string) error {
+	if len(chunk) > 1 {
+		sources := make([]string, len(chunk))
+		for i, b := range chunk {
+			sources[i] = b.source
+		}
+		translated, err := translator.Translate(strings.Join(sources, chunkSeparator), targetLang)
+		if err != nil {
+			return err
+		}
+		parts := separatorPattern.Split(strings.TrimSpace(translated), -1)
+		if len(parts) == len(chunk) {
+			for i, b := range chunk {
+				b.translated = b.decode(parts[i])
+			}
+			return nil
+		}
+	}
+
+	for _, b := range chunk {
+		translated, err := translator.Translate(b.source, targetLang)
+		if err != nil {
+			return err
+		}
+		b.translated = b.decode(translated)
+	}
+	return nil
+}
+
+// collect 收集节点下的可翻译块
+func (d *ArticleDocument) collect(n *html.Node) bool {
+	found := false
+	var run []*html.Node
+	endRun := func() {
+		if len(run) > 0 && d.addBlock(nil, run[0], run[len(run)-1]) {
+			found = true
+		}
+		run = nil
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && articleSkipElements[c.DataAtom] {
+			endRun()
+			continue
+		}
+		if c.Type != html.ElementNode || !isBlockElement(c) {
+			run = append(run, c)
+			continue
+		}
+		endRun()
+
+		if articleBlockElements[c.DataAtom] && !hasBlockDescendant(c) {
+			if c.FirstChild != nil && d.addBlock(c, c.FirstChild, c.LastChild) {
+				found = true
+			}
+			continue
+		}
+		if d.collect(c) {
+			d.parents[c] = true
+			found = true
+		}
+	}
+	endRun()
+	return found
+}
+
+// addBlock 编码块内容，没有可翻译文本时跳过
+func (d *ArticleDocument) addBlock(element, first, last *html.Node) bool {
+	b := &articleBlock{element: element, first: first, last: last, markup: make(map[string]string)}
+
+	var sb strings.Builder
+	for n := first; n != nil; n = n.NextSibling {
+		b.encode(n, &sb)
+		if n == last {
+			break
+		}
+	}
+	b.source = strings.TrimSpace(whitespacePattern.ReplaceAllString(sb.String(), " "))
+	if !hasTranslatableText(placeholderPattern.ReplaceAllString(b.source, "")) {
+		return false
+	}
+
+	d.blocks = append(d.blocks, b)
+	if element != nil {
+		d.elements[element] = b
+	} else {
+		d.starts[first] = b
+	}
+	return true
+}
+
+// encode 将节点写为纯文本，行内标记替换为占位符
+func (b *articleBlock) encode(n *html.Node, sb *strings.Builder) {
+	switch n.Type {
+	case html.TextNode:
+		sb.WriteString(n.Data)
+	case html.ElementNode:
+		id := b.placeholders
+		b.placeholders++
+		if articlePreservedElements[n.DataAtom] || articleSkipElements[n.DataAtom] || !hasTranslatableText(textContent(n)) ||
+			hasAttr(n, "data-no-translate") || hasClass(n, "katex") || hasClass(n, "math") {
+			var markup strings.Builder
+			html.Render(&markup, n)
+			token := fmt.Sprintf("⟦%d⟧", id)
+			b.markup[token] = markup.String()
+			sb.WriteString(token)
+			return
+		}
+
+		open, end := fmt.Sprintf("⟦%d⟧", id), fmt.Sprintf("⟦/%d⟧", id)
+		b.markup[open] = startTag(n)
+		b.markup[end] = "</" + n.Data + ">"
+		sb.WriteString(open)
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			b.encode(c, sb)
+		}
+		sb.WriteString(end)
+	}
+}
+
+// decode 将翻译结果还原为 HTML。占位符缺失或顺序错误时退化为纯文本。
+func (b *articleBlock) decode(translated string) string {
+	translated = strings.TrimSpace(translated)
+	matches := placeholderPattern.FindAllStringSubmatchIndex(translated, -1)
+
+	tokens := make([]string, len(matches))
+	for i, m := range matches {
+		tokens[i] = "⟦" + translated[m[2]:m[3]] + translated[m[4]:m[5]] + "⟧"
+	}
+	if !b.validTokens(tokens) {
+		return html.EscapeString(placeholderPattern.ReplaceAllString(translated, ""))
+	}
+
+	var sb strings.Builder
+	pos := 0
+	for i, m := range matches {
+		sb.WriteString(html.EscapeString(translated[pos:m[0]]))
+		sb.WriteString(b.markup[tokens[i]])
+		pos = m[1]
+	}
+	sb.WriteString(html.EscapeString(translated[pos:]))
+	return sb.String()
+}
+
+// validTokens 检查每个占位符恰好出现一次且正确嵌套
+func (b *articleBlock) validTokens(tokens []string) bool {
+	if len(tokens) != len(b.markup) {
+		return false
+	}
+	seen := make(map[string]bool)
+	var stack []string
+	for _, token := range tokens {
+		if _, ok := b.markup[token]; !ok || seen[token] {
+			return false
+		}
+		seen[token] = true
+
+		if strings.HasPrefix(token, "⟦/") {
+			open := "⟦" + strings.TrimPrefix(token, "⟦/")
+			if len(stack) == 0 || stack[len(stack)-1] != open {
+				return false
+			}
+			stack = stack[:len(stack)-1]
+		} else if _, paired := b.markup["⟦/"+strings.TrimPrefix(token, "⟦")]; paired {
+			stack = append(stack, token)
+		}
+	}
+	return len(stack) == 0
+}
+
+// render 输出节点的子节点，bilingual 为 true 时保留原文并在其后插入译文
+func (d *ArticleDocument) render(sb *strings.Builder, n *html.Node, bilingual bool) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if b := d.starts[c]; b != nil {
+			for o := b.first; o != nil; o = o.NextSibling {
+				if !bilingual && b.translated != "" {
+					break
+				}
+				html.Render(sb, o)
+				if o == b.last {
+					break
+				}
+			}
+			if b.translated != "" {
+				if bilingual {
+					sb.WriteString(`<div class="` + TranslationClass + `">` + b.translated + `</div>`)
+				} else {
+					sb.WriteString(b.translated)
+				}
+			}
+			c = b.last
+			continue
+		}
+
+		if b := d.elements[c]; b != nil && b.translated != "" {
+			d.renderElement(sb, c, b, bilingual)
+			continue
+		}
+
+		if d.parents[c] {
+			sb.WriteString(startTag(c))
+			d.render(sb, c, bilingual)
+			sb.WriteString("</" + c.Data + ">")
+			continue
+		}
+
+		html.Render(sb, c)
+	}
+}
+
+// renderElement 输出已翻译的块元素
+func (d *ArticleDocument) renderElement(sb *strings.Builder, n *html.Node, b *articleBlock, bilingual bool) {
+	if !bilingual {
+		sb.WriteString(startTag(n) + b.translated + "</" + n.Data + ">")
+		return
+	}
+
+	switch {
+	case articleInlineTranslationElements[n.DataAtom]:
+		sb.WriteString(startTag(n))
+		renderChildren(sb, n)
+		sb.WriteString(`<div class="` + TranslationClass + " " + TranslationInlineClass + `">` + b.translated + `</div>`)
+		sb.WriteString("</" + n.Data + ">")
+	case hasAncestor(n, atom.Blockquote):
+		html.Render(sb, n)
+		sb.WriteString(`<div class="` + TranslationClass + " " + TranslationBlockquoteClass + `">` + b.translated + `</div>`)
+	default:
+		html.Render(sb, n)
+		sb.WriteString(`<div class="` + TranslationClass + `">` + b.translated + `</div>`)
+	}
+}
+
+func renderChildren(sb *strings.Builder, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		html.Render(sb, c)
+	}
+}
+
+// startTag 输出元素的开始标签
+func startTag(n *html.Node) string {
+	var sb strings.Builder
+	sb.WriteString("<" + n.Data)
+	for _, attr := range n.Attr {
+		sb.WriteString(" ")
+		if attr.Namespace != "" {
+			sb.WriteString(attr.Namespace + ":")
+		}
+		sb.WriteString(attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	sb.WriteString(">")
+	return sb.String()
+}
+
+func isBlockElement(n *html.Node) bool {
+	return articleBlockElements[n.DataAtom] || articleStructureElements[n.DataAtom]
+}
+
+func hasBlockDescendant(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if isBlockElement(c) || articleSkipElements[c.DataAtom] || hasBlockDescendant(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAncestor(n *html.Node, a atom.Atom) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.DataAtom == a {
+			return true
+		}
+	}
+	return false
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && (articlePreservedElements[c.DataAtom] || articleSkipElements[c.DataAtom]) {
+			continue
+		}
+		sb.WriteString(textContent(c))
+	}
+	return sb.String()
+}
+
+// hasTranslatableText 检查文本中是否包含字母（纯数字和符号无需翻译）
+func hasTranslatableText(text string) bool {
+	return strings.IndexFunc(text, unicode.IsLetter) != -1
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == "class" && strings.Contains(" "+attr.Val+" ", " "+class+" ") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package translation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// upperTranslator upper-cases text outside placeholders and counts requests
+func upperTranslator(requests *[]string) *TestTranslator {
+	return &TestTranslator{TranslateFunc: func(text, targetLang string) (string, error) {
+		*requests = append(*requests, text)
+		return placeholderPattern.ReplaceAllStringFunc(strings.ToUpper(text), strings.ToLower), nil
+	}}
+}
+
+func TestParseArticle_Blocks(t *testing.T) {
+	doc, err := ParseArticle(`<h2>Title</h2>
+<p>Read <a href="https://example.com/x">the <em>docs</em></a> and run <code>go test</code>.</p>
+<pre><code>func main() {}</code></pre>
+<ul><li>One</li><li>Two<ul><li>Nested</li></ul></li></ul>
+<p><img src="a.png"></p>
+<div>Loose text<br>more</div>
+<p>123</p>`)
+	if err != nil {
+		t.Fatalf("ParseArticle failed: %v", err)
+	}
+
+	var sources []string
+	for _, b := range doc.blocks {
+		sources = append(sources, b.source)
+	}
+	want := []string{
+		"Title",
+		"Read ⟦0⟧the ⟦1⟧docs⟦/1⟧⟦/0⟧ and run ⟦2⟧.",
+		"One",
+		"Two",
+		"Nested",
+		"Loose text⟦0⟧more",
+	}
+	if strings.Join(sources, "|") != strings.Join(want, "|") {
+		t.Errorf("Unexpected blocks:\n got %q\nwant %q", sources, want)
+	}
+}
+
+func TestArticleDocument_Translate(t *testing.T) {
+	doc, _ := ParseArticle(`<p>Read <a href="https://example.com/x?a=1&amp;b=2">the docs</a> and run <code>go test</code>.</p><pre>keep me</pre><ul><li>Item</li></ul><blockquote><p>Quote</p></blockquote>Tail text`)
+
+	var requests []string
+	if err := doc.Translate(upperTranslator(&requests), "en", 0); err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("Expected the blocks to be batched into one request, got %d", len(requests))
+	}
+	if !doc.Complete() {
+		t.Error("Expected every block to be translated")
+	}
+
+	translated := doc.TranslatedHTML()
+	wantTranslated := `<p>READ <a href="https://example.com/x?a=1&amp;b=2">THE DOCS</a> AND RUN <code>go test</code>.</p><pre>keep me</pre><ul><li>ITEM</li></ul><blockquote><p>QUOTE</p></blockquote>TAIL TEXT`
+	if translated != wantTranslated {
+		t.Errorf("Unexpected translation:\n got %s\nwant %s", translated, wantTranslated)
+	}
+
+	bilingual := doc.BilingualHTML()
+	for _, want := range []string{
+		`<p>Read <a href="https://example.com/x?a=1&amp;b=2">the docs</a> and run <code>go test</code>.</p><div class="translation-text">READ `,
+		`<li>Item<div class="translation-text translation-inline">ITEM</div></li>`,
+		`<p>Quote</p><div class="translation-text translation-blockquote">QUOTE</div>`,
+		`Tail text<div class="translation-text">TAIL TEXT</div>`,
+		`<pre>keep me</pre>`,
+	} {
+		if !strings.Contains(bilingual, want) {
+			t.Errorf("Expected bilingual rendition to contain %s, got %s", want, bilingual)
+		}
+	}
+}
+
+func TestArticleDocument_ChunksAndFallbacks(t *testing.T) {
+	doc, _ := ParseArticle(`<p>First <b>bold</b></p><p>Second</p><p>Third</p>`)
+
+	// A translator that drops the separators and placeholders forces per-block requests
+	var requests []string
+	translator := &TestTranslator{TranslateFunc: func(text, targetLang string) (string, error) {
+		requests = append(requests, text)
+		return placeholderPattern.ReplaceAllString(separatorPattern.ReplaceAllString(text, " "), ""), nil
+	}}
+	if err := doc.Translate(translator, "en", 60); err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	// The first two blocks fit in one chunk, which is retried block by block
+	if len(requests) != 4 {
+		t.Errorf("Expected 4 requests, got %d: %q", len(requests), requests)
+	}
+	if got := doc.Translations()[0]; got != "First bold" {
+		t.Errorf("Expected lost markup to fall back to plain text, got %q", got)
+	}
+
+	// Failed requests keep earlier blocks and are resumed later
+	doc, _ = ParseArticle(`<p>One</p><p>Two</p>`)
+	calls := 0
+	failing := &TestTranslator{TranslateFunc: func(text, targetLang string) (string, error) {
+		calls++
+		if calls > 1 {
+			return "", errors.New("quota exceeded")
+		}
+		return "1", nil
+	}}
+	if err := doc.Translate(failing, "en", 5); err == nil {
+		t.Fatal("Expected the failure to be returned")
+	}
+	saved := doc.Translations()
+	if saved[0] != "1" || saved[1] != "" || doc.Complete() {
+		t.Errorf("Unexpected partial translation: %q", saved)
+	}
+
+	doc, _ = ParseArticle(`<p>One</p><p>Two</p>`)
+	doc.SetTranslations(saved)
+	requests = nil
+	if err := doc.Translate(upperTranslator(&requests), "en", 0); err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if len(requests) != 1 || requests[0] != "Two" || !doc.Complete() {
+		t.Errorf("Expected only the missing block to be translated, got %q", requests)
+	}
+}
@@ -2,7 +2,14 @@ package translation
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"MrRSS/internal/ai"
 )
@@ -19,93 +26,128 @@ type CacheProvider interface {
 	SetCachedTranslation(sourceTextHash, sourceText, targetLang, translatedText, provider string) error
 }
 
+// UsageStore records the characters translated by each provider per month, for budgets.
+// A CacheProvider that also implements UsageStore is used for it.
+type UsageStore interface {
+	AddTranslationUsage(provider, month string, characters int) error
+	GetTranslationUsage(provider, month string) (int64, error)
+}
+
 // DynamicTranslator is a translator that dynamically selects the translation provider
 // based on user settings. It uses the factory pattern to create provider instances.
+// The configured provider is followed by the fallback chain in translation_fallback_providers;
+// providers whose circuit breaker is open or whose monthly budget is spent are skipped.
 type DynamicTranslator struct {
-	factory            *Factory
-	cache              CacheProvider
-	mu                 sync.RWMutex
-	cachedProvider     Provider
-	cachedProviderName string
+	factory   *Factory
+	cache     CacheProvider
+	usage     UsageStore
+	health    *healthTracker
+	mu        sync.RWMutex
+	providers map[ProviderType]Provider
 }
 
 // NewDynamicTranslator creates a new dynamic translator that uses the given settings provider.
 func NewDynamicTranslator(settings SettingsProvider) *DynamicTranslator {
 	return &DynamicTranslator{
-		factory: NewFactory(settings),
+		factory:   NewFactory(settings),
+		health:    newHealthTracker(),
+		providers: make(map[ProviderType]Provider),
 	}
 }
 
 // NewDynamicTranslatorWithCache creates a new dynamic translator with translation caching.
 func NewDynamicTranslatorWithCache(settings SettingsProvider, cache CacheProvider) *DynamicTranslator {
-	return &DynamicTranslator{
-		factory: NewFactoryWithCache(settings, cache),
-		cache:   cache,
+	t := &DynamicTranslator{
+		factory:   NewFactoryWithCache(settings, cache),
+		cache:     cache,
+		health:    newHealthTracker(),
+		providers: make(map[ProviderType]Provider),
 	}
+	if usage, ok := cache.(UsageStore); ok {
+		t.usage = usage
+	}
+	return t
 }
 
-// Translate translates text using the currently configured translation provider.
+// Translate translates text with the first provider of the chain that succeeds.
+// The cache of each provider is checked when the chain reaches it, so a cached
+// translation of a fallback is only used once the providers before it have failed.
 func (t *DynamicTranslator) Translate(text, targetLang string) (string, error) {
 	if text == "" {
 		return "", nil
 	}
 
-	provider, err := t.getProvider()
-	if err != nil {
-		return "", err
-	}
-
 	ctx := context.Background()
+	chain := t.getProviderChain()
+	budgets := t.getBudgets()
+	month := usageMonth(time.Now())
 
-	// Wrap with caching if cache is available
-	if t.cache != nil {
-		return t.translateWithCache(ctx, provider, text, targetLang)
-	}
+	var lastErr error
+	for _, providerType := range chain {
+		provider, err := t.getProvider(providerType)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
-	result, err := provider.Translate(ctx, text, targetLang)
-	if err != nil {
-		return "", err
-	}
+		// 缓存命中不消耗配额，也不受熔断影响
+		if t.cache != nil {
+			if cached, found, _ := t.cache.GetCachedTranslation(hashText(text), targetLang, provider.Name()); found {
+				return cached, nil
+			}
+		}
 
-	return result.Translated, nil
-}
+		if budget := budgets[providerType]; budget > 0 && t.usage != nil {
+			used, _ := t.usage.GetTranslationUsage(providerType.String(), month)
+			if used+int64(utf8.RuneCountInString(text)) > budget {
+				lastErr = fmt.Errorf("%s monthly character budget exhausted", providerType)
+				continue
+			}
+		}
 
-// translateWithCache 使用缓存执行翻译
-func (t *DynamicTranslator) translateWithCache(ctx context.Context, provider Provider, text, targetLang string) (string, error) {
-	// 尝试从缓存获取
-	sourceHash := hashText(text)
-	if cached, found, _ := t.cache.GetCachedTranslation(sourceHash, targetLang, provider.Name()); found {
-		return cached, nil
-	}
+		if !t.health.allow(providerType) {
+			lastErr = fmt.Errorf("%s is temporarily disabled after repeated failures", providerType)
+			continue
+		}
 
-	// 执行翻译
-	result, err := provider.Translate(ctx, text, targetLang)
-	if err != nil {
-		return "", err
-	}
+		result, err := provider.Translate(ctx, text, targetLang)
+		if err != nil {
+			t.health.recordFailure(providerType, err)
+			if len(chain) > 1 {
+				log.Printf("Translation with %s failed, trying next provider: %v", providerType, err)
+			}
+			lastErr = err
+			continue
+		}
+		t.health.recordSuccess(providerType)
 
-	// 保存到缓存
-	t.cache.SetCachedTranslation(sourceHash, text, targetLang, result.Translated, provider.Name())
+		if t.usage != nil {
+			if err := t.usage.AddTranslationUsage(providerType.String(), month, utf8.RuneCountInString(text)); err != nil {
+				log.Printf("Warning: failed to record translation usage: %v", err)
+			}
+		}
+		if t.cache != nil {
+			t.cache.SetCachedTranslation(hashText(text), text, targetLang, result.Translated, provider.Name())
+		}
 
-	return result.Translated, nil
-}
+		return result.Translated, nil
+	}
 
-// getProvider 获取当前配置的翻译提供商
-func (t *DynamicTranslator) getProvider() (Provider, error) {
-	// 获取当前设置的提供商类型
-	providerType, err := t.getProviderType()
-	if err != nil {
-		return nil, err
+	if len(chain) > 1 {
+		return "", fmt.Errorf("all translation providers failed: %w", lastErr)
 	}
+	return "", lastErr
+}
 
+// getProvider 获取指定类型的翻译提供商实例（按类型缓存）
+func (t *DynamicTranslator) getProvider(providerType ProviderType) (Provider, error) {
 	// 检查是否可以重用缓存的提供商
 	t.mu.RLock()
-	if t.cachedProvider != nil && t.cachedProviderName == providerType.String() {
-		provider := t.cachedProvider
-		t.mu.RUnlock()
+	provider, ok := t.providers[providerType]
+	t.mu.RUnlock()
+	if ok {
 		return provider, nil
 	}
-	t.mu.RUnlock()
 
 	// 创建新的提供商实例
 	t.mu.Lock()
@@ -117,12 +159,55 @@ func (t *DynamicTranslator) getProvider() (Provider, error) {
 	}
 
 	// 缓存提供商实例
-	t.cachedProvider = provider
-	t.cachedProviderName = providerType.String()
+	t.providers[providerType] = provider
 
 	return provider, nil
 }
 
+// getProviderChain 返回按顺序尝试的提供商：当前配置的提供商，然后是备用提供商
+func (t *DynamicTranslator) getProviderChain() []ProviderType {
+	primary, _ := t.getProviderType()
+	chain := []ProviderType{primary}
+
+	fallbacks, _ := t.factory.settingsProvider.GetSetting("translation_fallback_providers")
+	for _, name := range strings.Split(fallbacks, ",") {
+		providerType, ok := parseProviderType(strings.TrimSpace(name))
+		if !ok || slices.Contains(chain, providerType) {
+			continue
+		}
+		chain = append(chain, providerType)
+	}
+
+	return chain
+}
+
+// getBudgets 从设置中读取每个提供商的每月字符预算，0 表示不限制
+func (t *DynamicTranslator) getBudgets() map[ProviderType]int64 {
+	budgets := make(map[ProviderType]int64)
+
+	budgetsJSON, _ := t.factory.settingsProvider.GetSetting("translation_monthly_char_budgets")
+	if budgetsJSON == "" {
+		return budgets
+	}
+
+	var raw map[string]int64
+	if err := json.Unmarshal([]byte(budgetsJSON), &raw); err != nil {
+		log.Printf("Invalid translation_monthly_char_budgets setting: %v", err)
+		return budgets
+	}
+	for name, budget := range raw {
+		if providerType, ok := parseProviderType(name); ok && budget > 0 {
+			budgets[providerType] = budget
+		}
+	}
+	return budgets
+}
+
+// usageMonth 返回用于统计字符用量的月份
+func usageMonth(now time.Time) string {
+	return now.Format("2006-01")
+}
+
 // getProviderType 从设置中获取当前配置的提供商类型
 func (t *DynamicTranslator) getProviderType() (ProviderType, error) {
 	providerStr, err := t.factory.settingsProvider.GetSetting("translation_provider")
@@ -130,19 +215,27 @@ func (t *DynamicTranslator) getProviderType() (ProviderType, error) {
 		return ProviderGoogle, nil // 默认使用 Google
 	}
 
-	switch providerStr {
+	if providerType, ok := parseProviderType(providerStr); ok {
+		return providerType, nil
+	}
+	return ProviderGoogle, nil
+}
+
+// parseProviderType 解析提供商类型名称
+func parseProviderType(name string) (ProviderType, bool) {
+	switch name {
 	case "google":
-		return ProviderGoogle, nil
+		return ProviderGoogle, true
 	case "deepl":
-		return ProviderDeepL, nil
+		return ProviderDeepL, true
 	case "baidu":
-		return ProviderBaidu, nil
+		return ProviderBaidu, true
 	case "ai":
-		return ProviderAI, nil
+		return ProviderAI, true
 	case "custom":
-		return ProviderCustom, nil
+		return ProviderCustom, true
 	default:
-		return ProviderGoogle, nil
+		return "", false
 	}
 }
 
@@ -150,8 +243,7 @@ func (t *DynamicTranslator) getProviderType() (ProviderType, error) {
 func (t *DynamicTranslator) InvalidateCache() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.cachedProvider = nil
-	t.cachedProviderName = ""
+	t.providers = make(map[ProviderType]Provider)
 }
 
 // SetProfileProvider sets the AI profile provider for translation
@@ -160,6 +252,39 @@ func (t *DynamicTranslator) SetProfileProvider(profileProvider *ai.ProfileProvid
 	defer t.mu.Unlock()
 	t.factory.SetProfileProvider(profileProvider)
 	// Clear cache to force re-creation with new profile
-	t.cachedProvider = nil
-	t.cachedProviderName = ""
+	t.providers = make(map[ProviderType]Provider)
+}
+
+// ProviderStatus returns the health, usage and budget of each provider in the fallback chain
+func (t *DynamicTranslator) ProviderStatus() []ProviderStatus {
+	budgets := t.getBudgets()
+	month := usageMonth(time.Now())
+
+	chain := t.getProviderChain()
+	statuses := make([]ProviderStatus, 0, len(chain))
+	for i, providerType := range chain {
+		status := ProviderStatus{
+			Provider:      providerType,
+			Name:          providerType.String(),
+			Position:      i,
+			MonthlyBudget: budgets[providerType],
+		}
+
+		if provider, err := t.getProvider(providerType); err != nil {
+			status.ConfigError = err.Error()
+		} else {
+			status.Configured = true
+			status.Name = provider.Name()
+		}
+
+		t.health.snapshot(providerType, &status)
+
+		if t.usage != nil {
+			status.CharactersThisMonth, _ = t.usage.GetTranslationUsage(providerType.String(), month)
+		}
+		status.BudgetExhausted = status.MonthlyBudget > 0 && status.CharactersThisMonth >= status.MonthlyBudget
+
+		statuses = append(statuses, status)
+	}
+	return statuses
 }
@@ -0,0 +1,165 @@
+package translation
+
+import (
+	"sync"
+	"time"
+)
+
+// Circuit breaker states reported in ProviderStatus
+const (
+	BreakerClosed   = "closed"    // Provider is used normally
+	BreakerOpen     = "open"      // Provider is skipped until the cooldown ends
+	BreakerHalfOpen = "half_open" // A single trial request decides whether the provider recovered
+)
+
+const (
+	// breakerFailureThreshold 连续失败多少次后熔断
+	breakerFailureThreshold = 3
+	// breakerBaseCooldown 首次熔断的冷却时间，之后每次再失败翻倍
+	breakerBaseCooldown = time.Minute
+	// breakerMaxCooldown 冷却时间上限
+	breakerMaxCooldown = 30 * time.Minute
+)
+
+// providerHealth 单个提供商的健康状态
+type providerHealth struct {
+	failures    int
+	cooldown    time.Duration
+	openUntil   time.Time
+	trialActive bool
+	lastError   string
+	lastFailure time.Time
+	lastSuccess time.Time
+}
+
+// healthTracker 为每个提供商维护一个熔断器
+type healthTracker struct {
+	mu        sync.Mutex
+	providers map[ProviderType]*providerHealth
+	now       func() time.Time
+}
+
+func newHealthTracker() *healthTracker {
+	return &healthTracker{
+		providers: make(map[ProviderType]*providerHealth),
+		now:       time.Now,
+	}
+}
+
+// get 返回提供商的健康状态（调用方需持有锁）
+func (h *healthTracker) get(provider ProviderType) *providerHealth {
+	ph, ok := h.providers[provider]
+	if !ok {
+		ph = &providerHealth{}
+		h.providers[provider] = ph
+	}
+	return ph
+}
+
+// state 返回熔断器状态（调用方需持有锁）
+func (h *healthTracker) state(ph *providerHealth) string {
+	if ph.failures < breakerFailureThreshold {
+		return BreakerClosed
+	}
+	if h.now().Before(ph.openUntil) {
+		return BreakerOpen
+	}
+	return BreakerHalfOpen
+}
+
+// allow 检查是否可以向提供商发送请求。半开状态下只放行一个试探请求。
+func (h *healthTracker) allow(provider ProviderType) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ph := h.get(provider)
+	switch h.state(ph) {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if ph.trialActive {
+			return false
+		}
+		ph.trialActive = true
+	}
+	return true
+}
+
+// recordSuccess 记录成功请求，关闭熔断器
+func (h *healthTracker) recordSuccess(provider ProviderType) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ph := h.get(provider)
+	ph.failures = 0
+	ph.cooldown = 0
+	ph.trialActive = false
+	ph.lastSuccess = h.now()
+}
+
+// recordFailure 记录失败请求，达到阈值或试探失败时打开熔断器
+func (h *healthTracker) recordFailure(provider ProviderType, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ph := h.get(provider)
+	ph.failures++
+	ph.trialActive = false
+	ph.lastFailure = h.now()
+	if err != nil {
+		ph.lastError = err.Error()
+	}
+
+	if ph.failures >= breakerFailureThreshold {
+		if ph.cooldown == 0 {
+			ph.cooldown = breakerBaseCooldown
+		} else {
+			ph.cooldown *= 2
+			if ph.cooldown > breakerMaxCooldown {
+				ph.cooldown = breakerMaxCooldown
+			}
+		}
+		ph.openUntil = ph.lastFailure.Add(ph.cooldown)
+	}
+}
+
+// snapshot 将提供商的健康状态写入 status
+func (h *healthTracker) snapshot(provider ProviderType, status *ProviderStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ph := h.get(provider)
+	status.State = h.state(ph)
+	status.ConsecutiveFailures = ph.failures
+	status.LastError = ph.lastError
+	if !ph.lastFailure.IsZero() {
+		t := ph.lastFailure
+		status.LastFailureAt = &t
+	}
+	if !ph.lastSuccess.IsZero() {
+		t := ph.lastSuccess
+		status.LastSuccessAt = &t
+	}
+	if status.State == BreakerOpen {
+		t := ph.openUntil
+		status.OpenUntil = &t
+	}
+}
+
+// ProviderStatus describes a provider of the fallback chain, for the status endpoint
+type ProviderStatus struct {
+	Provider            ProviderType `json:"provider"`
+	Name                string       `json:"name"`
+	Position            int          `json:"position"`
+	Configured          bool         `json:"configured"`
+	ConfigError         string       `json:"config_error,omitempty"`
+	State               string       `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	LastError           string       `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time   `json:"last_failure_at,omitempty"`
+	LastSuccessAt       *time.Time   `json:"last_success_at,omitempty"`
+	OpenUntil           *time.Time   `json:"open_until,omitempty"`
+	CharactersThisMonth int64        `json:"characters_this_month"`
+	MonthlyBudget       int64        `json:"monthly_budget"`
+	BudgetExhausted     bool         `json:"budget_exhausted"`
+}
@@ -0,0 +1,176 @@
+package translation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// stubProvider is a provider whose result is controlled by the test
+type stubProvider struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (s *stubProvider) Name() string { return s.name }
+
+func (s *stubProvider) Translate(ctx context.Context, text, targetLang string) (*TranslationResult, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &TranslationResult{Original: text, Translated: s.name + ":" + text, ToLang: targetLang}, nil
+}
+
+func (s *stubProvider) IsAvailable() bool { return true }
+
+func (s *stubProvider) SupportedLanguages() []string { return nil }
+
+// memoryUsage implements CacheProvider and UsageStore in memory
+type memoryUsage struct {
+	cache map[string]string
+	usage map[string]int64
+}
+
+func newMemoryUsage() *memoryUsage {
+	return &memoryUsage{cache: make(map[string]string), usage: make(map[string]int64)}
+}
+
+func (m *memoryUsage) GetCachedTranslation(sourceTextHash, targetLang, provider string) (string, bool, error) {
+	text, ok := m.cache[sourceTextHash+targetLang+provider]
+	return text, ok, nil
+}
+
+func (m *memoryUsage) SetCachedTranslation(sourceTextHash, sourceText, targetLang, translatedText, provider string) error {
+	m.cache[sourceTextHash+targetLang+provider] = translatedText
+	return nil
+}
+
+func (m *memoryUsage) AddTranslationUsage(provider, month string, characters int) error {
+	m.usage[provider+month] += int64(characters)
+	return nil
+}
+
+func (m *memoryUsage) GetTranslationUsage(provider, month string) (int64, error) {
+	return m.usage[provider+month], nil
+}
+
+func newChainTranslator(settings map[string]string, store *memoryUsage, providers map[ProviderType]Provider) *DynamicTranslator {
+	translator := NewDynamicTranslatorWithCache(&mockSettingsProvider{settings: settings}, store)
+	translator.providers = providers
+	return translator
+}
+
+func TestDynamicTranslator_FallbackChain(t *testing.T) {
+	deepl := &stubProvider{name: "deepl", err: errors.New("quota exceeded")}
+	google := &stubProvider{name: "google"}
+	store := newMemoryUsage()
+	translator := newChainTranslator(map[string]string{
+		"translation_provider":           "deepl",
+		"translation_fallback_providers": "ai, google, deepl, unknown",
+	}, store, map[ProviderType]Provider{ProviderDeepL: deepl, ProviderGoogle: google})
+
+	// AI is not configured, so the chain continues to Google
+	result, err := translator.Translate("Hello", "zh")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if result != "google:Hello" {
+		t.Errorf("Expected the Google translation, got %q", result)
+	}
+	if store.usage["google"+usageMonth(time.Now())] != 5 {
+		t.Errorf("Expected 5 characters of Google usage, got %v", store.usage)
+	}
+
+	statuses := translator.ProviderStatus()
+	if len(statuses) != 3 {
+		t.Fatalf("Expected deepl, ai and google in the chain, got %+v", statuses)
+	}
+	if statuses[0].ConsecutiveFailures != 1 || statuses[0].LastError != "quota exceeded" {
+		t.Errorf("Expected the DeepL failure to be recorded, got %+v", statuses[0])
+	}
+	if statuses[1].Configured || statuses[1].ConfigError == "" {
+		t.Errorf("Expected AI to be reported as not configured, got %+v", statuses[1])
+	}
+	if statuses[2].CharactersThisMonth != 5 || statuses[2].LastSuccessAt == nil {
+		t.Errorf("Unexpected Google status: %+v", statuses[2])
+	}
+
+	// The cached Google translation is only used once DeepL has been tried again
+	deepl.calls, google.calls = 0, 0
+	if result, _ := translator.Translate("Hello", "zh"); result != "google:Hello" || deepl.calls != 1 || google.calls != 0 {
+		t.Errorf("Expected a Google cache hit after DeepL, got %q with %d DeepL and %d Google calls", result, deepl.calls, google.calls)
+	}
+
+	// Once DeepL recovers its translation is preferred over the cached fallback
+	deepl.err = nil
+	if result, _ := translator.Translate("Hello", "zh"); result != "deepl:Hello" {
+		t.Errorf("Expected the DeepL translation, got %q", result)
+	}
+	deepl.calls = 0
+	if result, _ := translator.Translate("Hello", "zh"); result != "deepl:Hello" || deepl.calls != 0 {
+		t.Errorf("Expected a DeepL cache hit, got %q with %d calls", result, deepl.calls)
+	}
+}
+
+func TestDynamicTranslator_CircuitBreaker(t *testing.T) {
+	deepl := &stubProvider{name: "deepl", err: errors.New("unavailable")}
+	google := &stubProvider{name: "google"}
+	translator := newChainTranslator(map[string]string{
+		"translation_provider":           "deepl",
+		"translation_fallback_providers": "google",
+	}, newMemoryUsage(), map[ProviderType]Provider{ProviderDeepL: deepl, ProviderGoogle: google})
+
+	now := time.Now()
+	translator.health.now = func() time.Time { return now }
+
+	for i := 0; i < breakerFailureThreshold+2; i++ {
+		if _, err := translator.Translate(fmt.Sprintf("Text %d", i), "zh"); err != nil {
+			t.Fatalf("Translate failed: %v", err)
+		}
+	}
+	if deepl.calls != breakerFailureThreshold {
+		t.Errorf("Expected DeepL to be skipped once the breaker opened, got %d calls", deepl.calls)
+	}
+	if status := translator.ProviderStatus()[0]; status.State != BreakerOpen || status.OpenUntil == nil {
+		t.Errorf("Expected an open breaker, got %+v", status)
+	}
+
+	// After the cooldown a single trial is let through, and recovery closes the breaker
+	now = now.Add(breakerBaseCooldown + time.Second)
+	if translator.ProviderStatus()[0].State != BreakerHalfOpen {
+		t.Errorf("Expected a half-open breaker after the cooldown")
+	}
+	deepl.err = nil
+	if result, _ := translator.Translate("Recovered", "zh"); result != "deepl:Recovered" {
+		t.Errorf("Expected DeepL to be tried again, got %q", result)
+	}
+	if status := translator.ProviderStatus()[0]; status.State != BreakerClosed || status.ConsecutiveFailures != 0 {
+		t.Errorf("Expected a closed breaker, got %+v", status)
+	}
+}
+
+func TestDynamicTranslator_MonthlyBudget(t *testing.T) {
+	deepl := &stubProvider{name: "deepl"}
+	google := &stubProvider{name: "google"}
+	store := newMemoryUsage()
+	store.usage["deepl"+usageMonth(time.Now())] = 8
+	translator := newChainTranslator(map[string]string{
+		"translation_provider":             "deepl",
+		"translation_fallback_providers":   "google",
+		"translation_monthly_char_budgets": `{"deepl":10}`,
+	}, store, map[ProviderType]Provider{ProviderDeepL: deepl, ProviderGoogle: google})
+
+	if result, _ := translator.Translate("Hi", "zh"); result != "deepl:Hi" {
+		t.Errorf("Expected DeepL within budget, got %q", result)
+	}
+	if result, _ := translator.Translate("Hello", "zh"); result != "google:Hello" {
+		t.Errorf("Expected Google once the DeepL budget is spent, got %q", result)
+	}
+	if status := translator.ProviderStatus()[0]; !status.BudgetExhausted || status.MonthlyBudget != 10 {
+		t.Errorf("Expected the DeepL budget to be exhausted, got %+v", status)
+	}
+}
@@ -149,6 +149,20 @@ func GetMediaCacheDir() (string, error) {
 	return cacheDir, nil
 }
 
+// GetPodcastsDir returns the full path to the directory of downloaded podcast episodes.
+func GetPodcastsDir() (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	podcastsDir := filepath.Join(dataDir, "podcasts")
+	err = os.MkdirAll(podcastsDir, 0755)
+	if err != nil {
+		return "", err
+	}
+	return podcastsDir, nil
+}
+
 // GetScriptsDir returns the path to the scripts directory.
 func GetScriptsDir() (string, error) {
 	dataDir, err := GetDataDir()
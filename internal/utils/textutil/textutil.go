@@ -3,6 +3,7 @@
 package textutil
 
 import (
+	stdhtml "html"
 	"regexp"
 	"strings"
 
@@ -42,6 +43,12 @@ var (
 
 	// Matches <script> tags and their content
 	scriptTagRegex = regexp.MustCompile(`(?i)<script[^>]*>.*?</script>`)
+
+	// Matches any HTML tag or comment
+	anyTagRegex = regexp.MustCompile(`(?s)<!--.*?-->|<[^>]*>`)
+
+	// Matches runs of whitespace
+	whitespaceRegex = regexp.MustCompile(`\s+`)
 )
 
 // CleanHTML sanitizes HTML content by fixing common malformed patterns
@@ -153,3 +160,19 @@ func ConvertMarkdownToHTML(markdownText string) string {
 	htmlContent := RenderMarkdown(markdownText)
 	return SanitizeHTML(htmlContent)
 }
+
+// StripHTML converts HTML content to plain text by removing tags, scripts and styles,
+// decoding entities and collapsing whitespace.
+func StripHTML(htmlContent string) string {
+	if htmlContent == "" {
+		return ""
+	}
+
+	htmlContent = styleTagRegex.ReplaceAllString(htmlContent, " ")
+	htmlContent = scriptTagRegex.ReplaceAllString(htmlContent, " ")
+	htmlContent = anyTagRegex.ReplaceAllString(htmlContent, " ")
+	htmlContent = stdhtml.UnescapeString(htmlContent)
+	htmlContent = whitespaceRegex.ReplaceAllString(htmlContent, " ")
+
+	return strings.TrimSpace(htmlContent)
+}
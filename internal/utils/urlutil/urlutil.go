@@ -56,6 +56,52 @@ func URLsMatch(url1, url2 string) bool {
 	return normalizeURLForMatching(url1) == normalizeURLForMatching(url2)
 }
 
+// CanonicalArticleURL returns the canonical form of an article link, so that the same article
+// linked by different feeds compares equal: the scheme is https, the host is lowercased without
+// "www.", "m." or "amp." prefixes, tracking parameters, fragments, trailing slashes and AMP
+// suffixes are removed. It returns "" for links that are not http(s) URLs.
+func CanonicalArticleURL(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ""
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, prefix := range []string{"www.", "m.", "amp."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+
+	path := strings.TrimSuffix(parsed.EscapedPath(), "/")
+	for _, suffix := range []string{"/amp", "/index.html", "/index.htm"} {
+		path = strings.TrimSuffix(path, suffix)
+	}
+
+	result := "https://" + host + path
+	if query := parsed.Query(); len(query) > 0 {
+		importantParams := make(url.Values)
+		for key, values := range query {
+			if !strings.EqualFold(key, "amp") && isImportantParameter(key, values) {
+				importantParams[key] = values
+			}
+		}
+		if len(importantParams) > 0 {
+			result += "?" + importantParams.Encode()
+		}
+	}
+	return result
+}
+
+// IsYouTubeShortURL reports whether an article link points to a YouTube Short.
+// The YouTube source links Shorts as youtube.com/shorts/VIDEO_ID.
+func IsYouTubeShortURL(rawURL string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+	host := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www."), "m.")
+	return host == "youtube.com" && strings.HasPrefix(parsed.Path, "/shorts/")
+}
+
 func normalizeURLForMatching(rawURL string) string {
 	if rawURL == "" {
 		return ""
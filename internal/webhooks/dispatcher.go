@@ -0,0 +1,397 @@
+// Package webhooks delivers database events to user-configured HTTP endpoints.
+// Deliveries are queued in the database and retried with exponential backoff.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+// EventPing is sent by the test endpoint to check a webhook's configuration
+const EventPing = "ping"
+
+// Events lists the event types a webhook can subscribe to
+var Events = []string{
+	database.EventArticlesNew,
+	database.EventArticleStarred,
+	database.EventArticleReadLater,
+	database.EventFeedFetchFailed,
+}
+
+const (
+	eventBufferSize      = 256
+	maxArticlesPerEvent  = 100 // Larger batches of new articles are split across deliveries
+	deliveryBatchSize    = 20
+	pollInterval         = 30 * time.Second
+	cleanupInterval      = 24 * time.Hour
+	deliveryRetentionDay = 30
+	maxErrorLength       = 500
+)
+
+// retryDelays are the waits before each retry of a failed delivery.
+// A delivery is marked failed after len(retryDelays)+1 attempts.
+var retryDelays = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	12 * time.Hour,
+}
+
+// ArticleFilter returns the articles matching a webhook's saved-filter conditions (JSON)
+type ArticleFilter func(articles []models.Article, conditions string) ([]models.Article, error)
+
+// Payload is the JSON body of a webhook request
+type Payload struct {
+	Event     string           `json:"event"`
+	Timestamp time.Time        `json:"timestamp"`
+	Articles  []ArticlePayload `json:"articles,omitempty"`
+	Feed      *FeedPayload     `json:"feed,omitempty"`
+}
+
+// ArticlePayload describes an article in a webhook payload
+type ArticlePayload struct {
+	ID              int64     `json:"id"`
+	FeedID          int64     `json:"feed_id"`
+	FeedTitle       string    `json:"feed_title"`
+	Title           string    `json:"title"`
+	TranslatedTitle string    `json:"translated_title,omitempty"`
+	URL             string    `json:"url"`
+	Author          string    `json:"author,omitempty"`
+	ImageURL        string    `json:"image_url,omitempty"`
+	Summary         string    `json:"summary,omitempty"`
+	PublishedAt     time.Time `json:"published_at"`
+}
+
+// FeedPayload describes a feed in a webhook payload
+type FeedPayload struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Error string `json:"error,omitempty"`
+}
+
+// Dispatcher turns database events into queued webhook deliveries and sends them
+type Dispatcher struct {
+	db     *database.DB
+	client *http.Client
+	events chan database.Event
+	wake   chan struct{}
+
+	filterMu sync.RWMutex
+	filter   ArticleFilter
+}
+
+// NewDispatcher creates a dispatcher for the webhooks stored in db
+func NewDispatcher(db *database.DB) *Dispatcher {
+	return &Dispatcher{
+		db:     db,
+		client: &http.Client{Timeout: 15 * time.Second},
+		events: make(chan database.Event, eventBufferSize),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// SetFilter sets the function used to apply webhook conditions to article events
+func (d *Dispatcher) SetFilter(filter ArticleFilter) {
+	d.filterMu.Lock()
+	defer d.filterMu.Unlock()
+	d.filter = filter
+}
+
+// HandleEvent queues a database event. It never blocks; events are dropped when the buffer is full.
+func (d *Dispatcher) HandleEvent(event database.Event) {
+	select {
+	case d.events <- event:
+	default:
+		log.Printf("Webhook event buffer full, dropping %s event", event.Type)
+	}
+}
+
+// Wake triggers an immediate delivery of due webhooks
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run subscribes to database events and delivers webhooks until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.db.SetEventListener(d.HandleEvent)
+	defer d.db.SetEventListener(nil)
+
+	go d.deliveryLoop(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-d.events:
+			if d.enqueue(event) > 0 {
+				d.Wake()
+			}
+		}
+	}
+}
+
+// deliveryLoop sends due deliveries when woken or periodically, and prunes old ones
+func (d *Dispatcher) deliveryLoop(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	lastCleanup := time.Time{}
+
+	for {
+		d.deliverDue(ctx)
+
+		if time.Since(lastCleanup) > cleanupInterval {
+			if n, err := d.db.CleanupWebhookDeliveries(deliveryRetentionDay); err != nil {
+				log.Printf("Error cleaning up webhook deliveries: %v", err)
+			} else if n > 0 {
+				log.Printf("Removed %d old webhook deliveries", n)
+			}
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// enqueue creates deliveries of an event for the subscribed webhooks and returns how many were queued
+func (d *Dispatcher) enqueue(event database.Event) int {
+	webhooks, err := d.db.GetWebhooksForEvent(event.Type)
+	if err != nil {
+		log.Printf("Error loading webhooks for %s: %v", event.Type, err)
+		return 0
+	}
+	if len(webhooks) == 0 {
+		return 0
+	}
+
+	var articles []models.Article
+	var feed *FeedPayload
+	if len(event.ArticleIDs) > 0 {
+		articles, err = d.db.GetArticlesByIDs(event.ArticleIDs)
+		if err != nil {
+			log.Printf("Error loading articles for %s: %v", event.Type, err)
+			return 0
+		}
+		if len(articles) == 0 {
+			return 0
+		}
+	} else {
+		feed = d.feedPayload(event)
+	}
+
+	queued := 0
+	for _, webhook := range webhooks {
+		matched := articles
+		if articles != nil && webhook.Conditions != "" {
+			matched = d.applyFilter(webhook, articles)
+			if len(matched) == 0 {
+				continue
+			}
+		}
+
+		for _, payload := range buildPayloads(event.Type, matched, feed) {
+			body, err := json.Marshal(payload)
+			if err != nil {
+				log.Printf("Error encoding webhook payload: %v", err)
+				continue
+			}
+			if _, err := d.db.EnqueueWebhookDelivery(webhook.ID, event.Type, string(body)); err != nil {
+				log.Printf("Error queuing delivery for webhook %d: %v", webhook.ID, err)
+				continue
+			}
+			queued++
+		}
+	}
+	return queued
+}
+
+// applyFilter returns the articles matching a webhook's conditions, or none if they cannot be evaluated
+func (d *Dispatcher) applyFilter(webhook models.Webhook, articles []models.Article) []models.Article {
+	d.filterMu.RLock()
+	filter := d.filter
+	d.filterMu.RUnlock()
+
+	if filter == nil {
+		log.Printf("Skipping webhook %d: conditions are set but no filter is configured", webhook.ID)
+		return nil
+	}
+	matched, err := filter(articles, webhook.Conditions)
+	if err != nil {
+		log.Printf("Skipping webhook %d: invalid conditions: %v", webhook.ID, err)
+		return nil
+	}
+	return matched
+}
+
+// feedPayload describes the feed of a feed event
+func (d *Dispatcher) feedPayload(event database.Event) *FeedPayload {
+	payload := &FeedPayload{ID: event.FeedID, Error: event.Message}
+	if feed, err := d.db.GetFeedByID(event.FeedID); err == nil && feed != nil {
+		payload.Title = feed.Title
+		payload.URL = feed.URL
+	}
+	return payload
+}
+
+// buildPayloads builds the payloads of an event, splitting large article batches
+func buildPayloads(eventType string, articles []models.Article, feed *FeedPayload) []Payload {
+	now := time.Now().UTC()
+	if feed != nil {
+		return []Payload{{Event: eventType, Timestamp: now, Feed: feed}}
+	}
+
+	var payloads []Payload
+	for start := 0; start < len(articles); start += maxArticlesPerEvent {
+		end := min(start+maxArticlesPerEvent, len(articles))
+		items := make([]ArticlePayload, 0, end-start)
+		for _, a := range articles[start:end] {
+			items = append(items, ArticlePayload{
+				ID:              a.ID,
+				FeedID:          a.FeedID,
+				FeedTitle:       a.FeedTitle,
+				Title:           a.Title,
+				TranslatedTitle: a.TranslatedTitle,
+				URL:             a.URL,
+				Author:          a.Author,
+				ImageURL:        a.ImageURL,
+				Summary:         a.Summary,
+				PublishedAt:     a.PublishedAt,
+			})
+		}
+		payloads = append(payloads, Payload{Event: eventType, Timestamp: now, Articles: items})
+	}
+	return payloads
+}
+
+// deliverDue sends all deliveries whose next attempt is due
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := d.db.GetDueWebhookDeliveries(deliveryBatchSize)
+		if err != nil {
+			log.Printf("Error loading webhook deliveries: %v", err)
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+		for _, delivery := range deliveries {
+			if ctx.Err() != nil {
+				return
+			}
+			d.deliver(ctx, delivery)
+		}
+	}
+}
+
+// deliver sends one delivery and records the outcome
+func (d *Dispatcher) deliver(ctx context.Context, delivery models.WebhookDelivery) {
+	webhook, err := d.db.GetWebhook(delivery.WebhookID)
+	if err != nil || webhook == nil {
+		// The webhook was deleted or its secret cannot be read; give up on the delivery
+		msg := "webhook not found"
+		if err != nil {
+			msg = err.Error()
+		}
+		_ = d.db.MarkWebhookDeliveryAttemptFailed(delivery.ID, 0, msg, time.Time{})
+		return
+	}
+
+	status, err := d.send(ctx, webhook, delivery.ID, delivery.Event, []byte(delivery.Payload))
+	if err == nil {
+		if err := d.db.MarkWebhookDeliveryDelivered(delivery.ID, status); err != nil {
+			log.Printf("Error recording webhook delivery %d: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	var next time.Time
+	if delivery.Attempts < len(retryDelays) {
+		next = time.Now().Add(retryDelays[delivery.Attempts])
+	} else {
+		log.Printf("Webhook delivery %d to %s failed after %d attempts: %v", delivery.ID, webhook.URL, delivery.Attempts+1, err)
+	}
+	if err := d.db.MarkWebhookDeliveryAttemptFailed(delivery.ID, status, truncate(err.Error(), maxErrorLength), next); err != nil {
+		log.Printf("Error recording webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// Ping sends a ping event to a webhook without queuing it and returns the response status
+func (d *Dispatcher) Ping(ctx context.Context, webhook *models.Webhook) (int, error) {
+	body, err := json.Marshal(Payload{Event: EventPing, Timestamp: time.Now().UTC()})
+	if err != nil {
+		return 0, err
+	}
+	return d.send(ctx, webhook, 0, EventPing, body)
+}
+
+// send POSTs a payload to a webhook. Non-2xx responses are returned as errors with their status.
+func (d *Dispatcher) send(ctx context.Context, webhook *models.Webhook, deliveryID int64, eventType string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	// X-MrRSS-Signature signs "<X-MrRSS-Timestamp>.<body>", so receivers can reject old
+	// deliveries by their timestamp without them being replayed with a new one
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MrRSS")
+	req.Header.Set("X-MrRSS-Event", eventType)
+	req.Header.Set("X-MrRSS-Timestamp", timestamp)
+	if deliveryID > 0 {
+		req.Header.Set("X-MrRSS-Delivery", strconv.FormatInt(deliveryID, 10))
+	}
+	if webhook.Secret != "" {
+		req.Header.Set("X-MrRSS-Signature", Sign(webhook.Secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value of a delivery: "sha256=" followed by the
+// hex-encoded HMAC-SHA256 of the timestamp, a dot and the body, keyed with the webhook secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
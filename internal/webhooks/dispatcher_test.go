@@ -0,0 +1,211 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func setupTestDB(t *testing.T) (*database.DB, int64) {
+	t.Helper()
+
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.DB.Close() })
+
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	return db, feedID
+}
+
+func TestDispatcher_DeliversSignedEvents(t *testing.T) {
+	db, feedID := setupTestDB(t)
+
+	var mu sync.Mutex
+	var received []Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get("X-MrRSS-Timestamp")
+		if r.Header.Get("X-MrRSS-Signature") != Sign("s3cret", timestamp, body) {
+			t.Errorf("Invalid signature: %s", r.Header.Get("X-MrRSS-Signature"))
+		}
+		var p Payload
+		json.Unmarshal(body, &p)
+		if r.Header.Get("X-MrRSS-Event") != p.Event {
+			t.Errorf("Event header %q does not match payload %q", r.Header.Get("X-MrRSS-Event"), p.Event)
+		}
+		mu.Lock()
+		received = append(received, p)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	if _, err := db.AddWebhook(&models.Webhook{
+		Name:    "Hook",
+		URL:     server.URL,
+		Secret:  "s3cret",
+		Events:  []string{database.EventArticlesNew},
+		Enabled: true,
+	}); err != nil {
+		t.Fatalf("AddWebhook failed: %v", err)
+	}
+
+	d := NewDispatcher(db)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	// Wait for the dispatcher to subscribe to database events
+	time.Sleep(50 * time.Millisecond)
+
+	articles := []*models.Article{
+		{FeedID: feedID, Title: "First", URL: "https://example.com/1", PublishedAt: time.Now()},
+		{FeedID: feedID, Title: "Second", URL: "https://example.com/2", PublishedAt: time.Now()},
+	}
+	if err := db.SaveArticles(ctx, articles); err != nil {
+		t.Fatalf("SaveArticles failed: %v", err)
+	}
+	// Saving the same articles again does not fire an event
+	if err := db.SaveArticles(ctx, articles); err != nil {
+		t.Fatalf("SaveArticles failed: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(received)
+		mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 delivery, got %d", len(received))
+	}
+	if received[0].Event != database.EventArticlesNew || len(received[0].Articles) != 2 || received[0].Articles[0].FeedTitle != "Feed" {
+		t.Errorf("Unexpected payload: %+v", received[0])
+	}
+}
+
+func TestDispatcher_RetriesFailedDeliveries(t *testing.T) {
+	db, _ := setupTestDB(t)
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhookID, _ := db.AddWebhook(&models.Webhook{URL: server.URL, Events: []string{database.EventFeedFetchFailed}, Enabled: true})
+	deliveryID, err := db.EnqueueWebhookDelivery(webhookID, database.EventFeedFetchFailed, `{}`)
+	if err != nil {
+		t.Fatalf("EnqueueWebhookDelivery failed: %v", err)
+	}
+
+	d := NewDispatcher(db)
+	d.deliverDue(context.Background())
+
+	log, _ := db.GetWebhookDeliveries(webhookID, 10)
+	if len(log) != 1 || log[0].Status != database.WebhookDeliveryPending || log[0].ResponseStatus != 503 || log[0].Attempts != 1 {
+		t.Fatalf("Expected a pending retry after the failed attempt, got %+v", log)
+	}
+	if !log[0].NextAttemptAt.After(time.Now()) {
+		t.Errorf("Expected the retry to be scheduled with backoff, got %v", log[0].NextAttemptAt)
+	}
+
+	// The retry is not due yet
+	d.deliverDue(context.Background())
+	if attempts != 1 {
+		t.Fatalf("Expected no attempt before the backoff elapsed, got %d", attempts)
+	}
+
+	if err := db.RetryWebhookDelivery(deliveryID); err != nil {
+		t.Fatalf("RetryWebhookDelivery failed: %v", err)
+	}
+	d.deliverDue(context.Background())
+
+	log, _ = db.GetWebhookDeliveries(webhookID, 10)
+	if log[0].Status != database.WebhookDeliveryDelivered || log[0].Attempts != 2 {
+		t.Errorf("Expected the retry to be delivered, got %+v", log[0])
+	}
+}
+
+func TestDispatcher_AppliesConditions(t *testing.T) {
+	db, feedID := setupTestDB(t)
+
+	matching, _ := db.AddWebhook(&models.Webhook{URL: "https://example.com/a", Events: []string{database.EventArticleStarred}, Conditions: `[{"field":"article_title","value":"go"}]`, Enabled: true})
+	other, _ := db.AddWebhook(&models.Webhook{URL: "https://example.com/b", Events: []string{database.EventArticleStarred}, Conditions: `[{"field":"article_title","value":"rust"}]`, Enabled: true})
+
+	db.SaveArticles(context.Background(), []*models.Article{{FeedID: feedID, Title: "Learning Go", URL: "https://example.com/go", PublishedAt: time.Now()}})
+	var articleID int64
+	db.QueryRow(`SELECT id FROM articles`).Scan(&articleID)
+
+	d := NewDispatcher(db)
+	d.SetFilter(func(articles []models.Article, conditions string) ([]models.Article, error) {
+		var conds []struct {
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal([]byte(conditions), &conds); err != nil {
+			return nil, err
+		}
+		var matched []models.Article
+		for _, a := range articles {
+			if conds[0].Value == "go" && a.Title == "Learning Go" {
+				matched = append(matched, a)
+			}
+		}
+		return matched, nil
+	})
+
+	if n := d.enqueue(database.Event{Type: database.EventArticleStarred, ArticleIDs: []int64{articleID}}); n != 1 {
+		t.Fatalf("Expected 1 queued delivery, got %d", n)
+	}
+	if log, _ := db.GetWebhookDeliveries(matching, 10); len(log) != 1 {
+		t.Errorf("Expected a delivery for the matching webhook, got %d", len(log))
+	}
+	if log, _ := db.GetWebhookDeliveries(other, 10); len(log) != 0 {
+		t.Errorf("Expected no delivery for the non-matching webhook, got %d", len(log))
+	}
+}
+
+func TestSign_CoversTimestamp(t *testing.T) {
+	body := []byte(`{"event":"ping"}`)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(`1700000000.{"event":"ping"}`))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := Sign("s3cret", "1700000000", body); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if Sign("s3cret", "1700000001", body) == want {
+		t.Error("Expected a different timestamp to change the signature")
+	}
+}
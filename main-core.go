@@ -3,6 +3,7 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"embed"
 	"flag"
@@ -19,9 +20,15 @@ import (
 	"time"
 
 	"MrRSS/internal/ai"
+	"MrRSS/internal/crypto"
 	"MrRSS/internal/database"
 	"MrRSS/internal/feed"
+	aihandlers "MrRSS/internal/handlers/ai"
+	authhandlers "MrRSS/internal/handlers/auth"
 	handlers "MrRSS/internal/handlers/core"
+	digesthandlers "MrRSS/internal/handlers/digest"
+	rulehandlers "MrRSS/internal/handlers/rules"
+	webhookhandlers "MrRSS/internal/handlers/webhooks"
 	"MrRSS/internal/network"
 	"MrRSS/internal/routes"
 	"MrRSS/internal/translation"
@@ -61,18 +68,76 @@ func debugLog(format string, args ...interface{}) {
 var frontendFiles embed.FS
 
 type CombinedHandler struct {
-	apiMux     *http.ServeMux
+	apiHandler http.Handler
 	fileServer http.Handler
+	// syncHandler serves the Google Reader and Fever APIs, which do their own authentication
+	syncHandler http.Handler
+	// isAuthenticated reports whether a request carries a valid session
+	isAuthenticated func(r *http.Request) bool
 }
 
 func (h *CombinedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if strings.HasPrefix(r.URL.Path, "/api/") {
-		h.apiMux.ServeHTTP(w, r)
+		h.apiHandler.ServeHTTP(w, r)
+		return
+	}
+	for _, prefix := range routes.SyncPathPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			h.syncHandler.ServeHTTP(w, r)
+			return
+		}
+	}
+	if r.URL.Path == "/login" {
+		authhandlers.HandleLoginPage(w, r)
+		return
+	}
+	// Static assets are public, but send signed-out visitors of the app itself to the login page
+	if (r.URL.Path == "/" || r.URL.Path == "/index.html") && !h.isAuthenticated(r) {
+		http.Redirect(w, r, "/login", http.StatusFound)
 		return
 	}
 	h.fileServer.ServeHTTP(w, r)
 }
 
+// createAdminUser creates an admin account for the -create-admin flag. The password is
+// read from MRRSS_ADMIN_PASSWORD, or from the first line of stdin if that is unset.
+// An existing user with the same name is left untouched, so the flag is safe to keep
+// in a container command.
+func createAdminUser(db *database.DB, username string) error {
+	existing, err := db.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		log.Printf("User %q already exists, skipping admin creation", username)
+		return nil
+	}
+
+	password := os.Getenv("MRRSS_ADMIN_PASSWORD")
+	if password == "" {
+		fmt.Printf("Password for %s: ", username)
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("read password: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	hash, err := crypto.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	id, err := db.CreateUser(username, hash, true)
+	if err != nil {
+		return err
+	}
+	if err := db.SetUserFeverAPIKey(id, crypto.HashToken(crypto.FeverAPIKey(username, password))); err != nil {
+		return err
+	}
+	log.Printf("Created admin user %q", username)
+	return nil
+}
+
 func main() {
 	// Parse flags
 	flag.BoolFunc("server", "Run in headless server mode", func(s string) error {
@@ -85,6 +150,8 @@ func main() {
 	})
 	host := flag.String("host", "0.0.0.0", "Host to listen on in server mode")
 	port := flag.String("port", "1234", "Port to listen on in server mode")
+	createAdmin := flag.String("create-admin", "", "Create an admin user with this username (password from MRRSS_ADMIN_PASSWORD or stdin)")
+	trustProxy := flag.Bool("trust-proxy", false, "Trust the X-Forwarded-Host and X-Forwarded-Proto headers of a reverse proxy")
 	flag.Parse()
 
 	// Force server mode for this build
@@ -142,6 +209,15 @@ func main() {
 	}
 	log.Println("Database initialized successfully")
 
+	if *createAdmin != "" {
+		if err := createAdminUser(db, *createAdmin); err != nil {
+			log.Fatalf("Error creating admin user: %v", err)
+		}
+	}
+	if count, err := db.CountUsers(); err == nil && count == 0 {
+		log.Println("Warning: No users exist, so every API request will be rejected. Create one with -create-admin <username>.")
+	}
+
 	// Initialize AI profile provider
 	profileProvider := ai.NewProfileProvider(db)
 	translator := translation.NewDynamicTranslatorWithCache(db, db)
@@ -149,11 +225,19 @@ func main() {
 
 	fetcher := feed.NewFetcher(db)
 	h := handlers.NewHandler(db, fetcher, translator, profileProvider)
+	h.TrustProxy = *trustProxy
+	fetcher.SetRuleServices(rulehandlers.NewActionServices(h))
+	h.Webhooks.SetFilter(webhookhandlers.NewArticleFilter(h))
+	h.Embeddings.SetHTTPClient(aihandlers.NewHTTPClientFactory(h))
+	h.Digests.SetHTTPClient(aihandlers.NewHTTPClientFactory(h))
+	h.Digests.SetFilter(digesthandlers.NewArticleFilter(h))
+	h.Digests.SetVectors(h.Embeddings.Vectors)
 
 	// API Routes
 	log.Println("Setting up API routes...")
 	apiMux := http.NewServeMux()
 	routes.RegisterAPIRoutes(apiMux, h)
+	routes.RegisterAuthRoutes(apiMux, h)
 
 	// Swagger Documentation - Serve swagger.json file
 	apiMux.HandleFunc("/docs/SERVER_MODE/swagger.json", func(w http.ResponseWriter, r *http.Request) {
@@ -164,6 +248,10 @@ func main() {
 		httpSwagger.URL("/docs/SERVER_MODE/swagger.json"),
 	))
 
+	// Google Reader and Fever compatible APIs for third-party clients
+	syncMux := http.NewServeMux()
+	routes.RegisterSyncRoutes(syncMux, h)
+
 	// Static Files
 	log.Println("Setting up static files...")
 	frontendFS, err := fs.Sub(frontendFiles, "frontend/dist")
@@ -174,8 +262,12 @@ func main() {
 	fileServer := http.FileServer(http.FS(frontendFS))
 
 	combinedHandler := &CombinedHandler{
-		apiMux:     apiMux,
-		fileServer: fileServer,
+		apiHandler:  routes.WrapWithAuth(apiMux, h),
+		fileServer:  fileServer,
+		syncHandler: syncMux,
+		isAuthenticated: func(r *http.Request) bool {
+			return authhandlers.IsAuthenticated(h, r)
+		},
 	}
 
 	log.Printf("Starting in headless server mode on http://%s:%s", *host, *port)
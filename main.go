@@ -23,7 +23,11 @@ import (
 	"MrRSS/internal/ai"
 	"MrRSS/internal/database"
 	"MrRSS/internal/feed"
+	aihandlers "MrRSS/internal/handlers/ai"
 	handlers "MrRSS/internal/handlers/core"
+	digesthandlers "MrRSS/internal/handlers/digest"
+	rulehandlers "MrRSS/internal/handlers/rules"
+	webhookhandlers "MrRSS/internal/handlers/webhooks"
 	"MrRSS/internal/monitor"
 	"MrRSS/internal/network"
 	"MrRSS/internal/routes"
@@ -159,6 +163,12 @@ func main() {
 
 	fetcher := feed.NewFetcher(db)
 	h := handlers.NewHandler(db, fetcher, translator, profileProvider)
+	fetcher.SetRuleServices(rulehandlers.NewActionServices(h))
+	h.Webhooks.SetFilter(webhookhandlers.NewArticleFilter(h))
+	h.Embeddings.SetHTTPClient(aihandlers.NewHTTPClientFactory(h))
+	h.Digests.SetHTTPClient(aihandlers.NewHTTPClientFactory(h))
+	h.Digests.SetFilter(digesthandlers.NewArticleFilter(h))
+	h.Digests.SetVectors(h.Embeddings.Vectors)
 
 	var quitRequested atomic.Bool
 	var lastWindowState windowState